@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -12,6 +13,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,8 +31,11 @@ const (
 	LASTNETWORKNAMESPACE    = "operator.sriovnetwork.openshift.io/last-network-namespace"
 	NETATTDEFFINALIZERNAME  = "netattdef.finalizers.sriovnetwork.openshift.io"
 	POOLCONFIGFINALIZERNAME = "poolconfig.finalizers.sriovnetwork.openshift.io"
+	NODESTATEFINALIZERNAME  = "sriov.k8s.cni.cncf.io/cleanup"
 	ESwithModeLegacy        = "legacy"
 	ESwithModeSwitchDev     = "switchdev"
+	ESwitchEncapModeNone    = "none"
+	ESwitchEncapModeBasic   = "basic"
 
 	SriovCniStateEnable  = "enable"
 	SriovCniStateDisable = "disable"
@@ -43,6 +48,22 @@ const (
 
 const invalidVfIndex = -1
 
+const (
+	// VlanProto8021q is the 802.1Q VLAN protocol
+	VlanProto8021q = "802.1q"
+	// VlanProto8021ad is the 802.1ad (QinQ) VLAN protocol
+	VlanProto8021ad = "802.1ad"
+)
+
+const (
+	// VfGroupSortPolicyPciOrder configures VF groups in the order of the first VF index of their range
+	VfGroupSortPolicyPciOrder = "pci-order"
+	// VfGroupSortPolicyResourceName configures VF groups in the alphabetical order of their resource names
+	VfGroupSortPolicyResourceName = "resource-name"
+	// VfGroupSortPolicyFirstFit configures VF groups in the order they were added to the interface
+	VfGroupSortPolicyFirstFit = "first-fit"
+)
+
 var ManifestsPath = "./bindata/manifests/cni-config"
 var log = logf.Log.WithName("sriovnetwork")
 
@@ -248,6 +269,15 @@ func GetEswitchModeFromSpec(ifaceSpec *Interface) string {
 	return ifaceSpec.EswitchMode
 }
 
+// NeedToUpdateEswitchEncapMode returns true if the encapsulation mode of the eSwitch of the PF in switchdev mode
+// differs from the requested one, the mode is not checked if the spec doesn't set it
+func NeedToUpdateEswitchEncapMode(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	if ifaceSpec.EswitchEncapMode == "" || GetEswitchModeFromSpec(ifaceSpec) != ESwithModeSwitchDev {
+		return false
+	}
+	return ifaceSpec.EswitchEncapMode != ifaceStatus.EswitchEncapMode
+}
+
 // GetEswitchModeFromStatus returns ESwitchMode from the interface status, returns legacy if not set
 func GetEswitchModeFromStatus(ifaceStatus *InterfaceExt) string {
 	if ifaceStatus.EswitchMode == "" {
@@ -256,27 +286,324 @@ func GetEswitchModeFromStatus(ifaceStatus *InterfaceExt) string {
 	return ifaceStatus.EswitchMode
 }
 
+// NeedToUpdateSriov returns true if the configuration of the device differs from the desired one
 func NeedToUpdateSriov(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
-	if ifaceSpec.Mtu > 0 {
+	return NeedToDrainForSriovUpdate(ifaceSpec, ifaceStatus) || NeedToUpdateVfMtu(ifaceSpec, ifaceStatus) ||
+		NeedToUpdateVfTrustAndSpoofChk(ifaceSpec, ifaceStatus) || NeedToUpdateVfTxRate(ifaceSpec, ifaceStatus) ||
+		NeedToUpdateVfVlan(ifaceSpec, ifaceStatus) || NeedToUpdateVfRepresentors(ifaceSpec, ifaceStatus)
+}
+
+// GetVfRepresentorMtu returns the MTU of the representors of the VFs of the group, the representors carry the
+// offloaded traffic of the VFs so they use the MTU of the group or of the PF if the group has no MTU
+func GetVfRepresentorMtu(ifaceSpec *Interface, groupSpec *VfGroup) int {
+	if groupSpec.Mtu > 0 {
+		return groupSpec.Mtu
+	}
+	return ifaceSpec.Mtu
+}
+
+// NeedToUpdateVfRepresentors returns true if the representor of a VF in switchdev mode is down or its MTU differs
+// from the MTU of the VF group, the representors are created down with the default MTU
+func NeedToUpdateVfRepresentors(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	if ifaceSpec.NumVfs == 0 || GetEswitchModeFromSpec(ifaceSpec) != ESwithModeSwitchDev {
+		return false
+	}
+	for _, vfStatus := range ifaceStatus.VFs {
+		if vfStatus.RepresentorName == "" {
+			continue
+		}
+		for i := range ifaceSpec.VfGroups {
+			if !IndexInRange(vfStatus.VfID, ifaceSpec.VfGroups[i].VfRange) {
+				continue
+			}
+			if mtu := GetVfRepresentorMtu(ifaceSpec, &ifaceSpec.VfGroups[i]); mtu > 0 && mtu != vfStatus.RepresentorMtu {
+				log.V(2).Info("NeedToUpdateVfRepresentors(): VF representor MTU needs update",
+					"representor", vfStatus.RepresentorName, "desired", mtu, "current", vfStatus.RepresentorMtu)
+				return true
+			}
+			if vfStatus.RepresentorLinkAdminState != consts.LinkAdminStateUp {
+				log.V(2).Info("NeedToUpdateVfRepresentors(): VF representor link needs to be up",
+					"representor", vfStatus.RepresentorName, "current", vfStatus.RepresentorLinkAdminState)
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// NeedToUpdateVfVlan returns true if the VLAN programmed through the PF for a VF bound to vfio-pci differs from
+// the VLAN of its VF group, the VLAN is cleared if the group has no VLAN. VLAN of other VFs is managed by the CNI.
+func NeedToUpdateVfVlan(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	if ifaceSpec.NumVfs == 0 {
+		return false
+	}
+	for _, vfStatus := range ifaceStatus.VFs {
+		for _, groupSpec := range ifaceSpec.VfGroups {
+			if !IndexInRange(vfStatus.VfID, groupSpec.VfRange) {
+				continue
+			}
+			if groupSpec.DeviceType != consts.DeviceTypeVfioPci {
+				break
+			}
+			desiredQoS, desiredProto := 0, ""
+			if groupSpec.Vlan != 0 {
+				desiredQoS, desiredProto = groupSpec.VlanQoS, GetVlanProto(groupSpec.VlanProto)
+			}
+			if groupSpec.Vlan != vfStatus.Vlan || desiredQoS != vfStatus.VlanQoS ||
+				(desiredProto != "" && vfStatus.VlanProto != "" && desiredProto != vfStatus.VlanProto) {
+				log.V(2).Info("NeedToUpdateVfVlan(): VF VLAN needs update", "vf", vfStatus.VfID,
+					"desired", fmt.Sprintf("%d/%d/%s", groupSpec.Vlan, desiredQoS, desiredProto),
+					"current", fmt.Sprintf("%d/%d/%s", vfStatus.Vlan, vfStatus.VlanQoS, vfStatus.VlanProto))
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// GetVlanProto returns the VLAN protocol in the lower case format, 802.1q is returned if the protocol is not set
+func GetVlanProto(vlanProto string) string {
+	if vlanProto == "" {
+		return VlanProto8021q
+	}
+	return strings.ToLower(vlanProto)
+}
+
+// GetVfTrust returns the trust mode of the VFs, the VFs are not trusted if the trust mode is not set
+func GetVfTrust(trust string) string {
+	if trust == "" {
+		return SriovCniStateOff
+	}
+	return trust
+}
+
+// GetVfSpoofChk returns the spoof check setting of the VFs, the spoof check is enabled if it is not set
+func GetVfSpoofChk(spoofChk string) string {
+	if spoofChk == "" {
+		return SriovCniStateOn
+	}
+	return spoofChk
+}
+
+// NeedToUpdateVfTxRate returns true if the transmit rate limits of a VF differ from the ones requested by its
+// VF group, unset limits of the group must be reset to 0 (unlimited). The rates are changed without a drain.
+func NeedToUpdateVfTxRate(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	if ifaceSpec.NumVfs == 0 {
+		return false
+	}
+	for _, vfStatus := range ifaceStatus.VFs {
+		for _, groupSpec := range ifaceSpec.VfGroups {
+			if !IndexInRange(vfStatus.VfID, groupSpec.VfRange) {
+				continue
+			}
+			if groupSpec.MinTxRate != vfStatus.MinTxRate || groupSpec.MaxTxRate != vfStatus.MaxTxRate {
+				log.V(2).Info("NeedToUpdateVfTxRate(): VF tx rate needs update", "vf", vfStatus.VfID,
+					"desired", fmt.Sprintf("%d-%d", groupSpec.MinTxRate, groupSpec.MaxTxRate),
+					"current", fmt.Sprintf("%d-%d", vfStatus.MinTxRate, vfStatus.MaxTxRate))
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// NeedToUpdateVfTrustAndSpoofChk returns true if the trust mode or the spoof check setting of a VF differs
+// from the one requested by its VF group, unset settings must be reset to the kernel defaults (trust off, spoof check on).
+// The settings are changed through the PF without disrupting the workloads. Drivers reset the settings when the PF is reconfigured, so the check detects the drift as well.
+func NeedToUpdateVfTrustAndSpoofChk(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	if ifaceSpec.NumVfs == 0 {
+		return false
+	}
+	for _, vfStatus := range ifaceStatus.VFs {
+		for _, groupSpec := range ifaceSpec.VfGroups {
+			if !IndexInRange(vfStatus.VfID, groupSpec.VfRange) {
+				continue
+			}
+			if vfStatus.Trust != "" && GetVfTrust(groupSpec.Trust) != vfStatus.Trust {
+				log.V(2).Info("NeedToUpdateVfTrustAndSpoofChk(): VF trust needs update",
+					"vf", vfStatus.VfID, "desired", GetVfTrust(groupSpec.Trust), "current", vfStatus.Trust)
+				return true
+			}
+			if vfStatus.SpoofChk != "" && GetVfSpoofChk(groupSpec.SpoofChk) != vfStatus.SpoofChk {
+				log.V(2).Info("NeedToUpdateVfTrustAndSpoofChk(): VF spoof check needs update",
+					"vf", vfStatus.VfID, "desired", GetVfSpoofChk(groupSpec.SpoofChk), "current", vfStatus.SpoofChk)
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// NeedToUpdateVfMtu returns true if the MTU of a VF bound to a kernel driver differs from the MTU of its VF group,
+// the MTU of VFs can be changed without disrupting the workloads
+func NeedToUpdateVfMtu(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	if ifaceSpec.NumVfs == 0 {
+		return false
+	}
+	for _, vfStatus := range ifaceStatus.VFs {
+		if vfStatus.Mtu == 0 || StringInArray(vfStatus.Driver, vars.DpdkDrivers) {
+			continue
+		}
+		for _, groupSpec := range ifaceSpec.VfGroups {
+			if !IndexInRange(vfStatus.VfID, groupSpec.VfRange) {
+				continue
+			}
+			if groupSpec.Mtu != 0 && vfStatus.Mtu != groupSpec.Mtu &&
+				(groupSpec.DeviceType == "" || groupSpec.DeviceType == consts.DeviceTypeNetDevice) {
+				log.V(2).Info("NeedToUpdateVfMtu(): VF MTU needs update",
+					"vf", vfStatus.VfID, "desired", groupSpec.Mtu, "current", vfStatus.Mtu)
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// NeedToUpdateVfQoS returns true if the QoS configuration of the VF groups differs from the last applied one,
+// the QoS configuration of VFs can be changed without disrupting the workloads
+func NeedToUpdateVfQoS(ifaceSpec *Interface, lastApplied *Interface) bool {
+	qos := func(iface *Interface) map[string][2]int32 {
+		result := map[string][2]int32{}
+		for _, group := range iface.VfGroups {
+			if group.DSCP == nil && group.EgressBandwidthMbps == nil {
+				continue
+			}
+			dscp, egressBandwidth := int32(-1), int32(0)
+			if group.DSCP != nil {
+				dscp = *group.DSCP
+			}
+			if group.EgressBandwidthMbps != nil {
+				egressBandwidth = *group.EgressBandwidthMbps
+			}
+			result[group.PolicyName+"/"+group.VfRange] = [2]int32{dscp, egressBandwidth}
+		}
+		return result
+	}
+	desired, applied := qos(ifaceSpec), qos(lastApplied)
+	if !reflect.DeepEqual(desired, applied) {
+		log.V(2).Info("NeedToUpdateVfQoS(): VF QoS needs update", "desired", desired, "applied", applied)
+		return true
+	}
+	return false
+}
+
+// NeedToUpdateVfBridgeVLAN returns true if the VLAN filters of the VF groups differ from the last applied ones,
+// the VLAN filters of the bridge ports of the VFs are changed without disrupting the workloads
+func NeedToUpdateVfBridgeVLAN(ifaceSpec *Interface, lastApplied *Interface) bool {
+	filters := func(iface *Interface) map[string][]VLANFilterEntry {
+		result := map[string][]VLANFilterEntry{}
+		for _, group := range iface.VfGroups {
+			if len(group.VLANFilter) > 0 {
+				result[group.PolicyName+"/"+group.VfRange] = group.VLANFilter
+			}
+		}
+		return result
+	}
+	desired, applied := filters(ifaceSpec), filters(lastApplied)
+	if !reflect.DeepEqual(desired, applied) {
+		log.V(2).Info("NeedToUpdateVfBridgeVLAN(): VF bridge VLAN filters need update", "desired", desired, "applied", applied)
+		return true
+	}
+	return false
+}
+
+// NeedToUpdateVfQinQ returns true if the double VLAN of the VF groups differs from the last applied one,
+// the double VLAN is not reported in the status
+func NeedToUpdateVfQinQ(ifaceSpec *Interface, lastApplied *Interface) bool {
+	qinq := func(iface *Interface) map[string][2]uint16 {
+		result := map[string][2]uint16{}
+		for _, group := range iface.VfGroups {
+			if group.OuterVLAN != nil && group.InnerVLAN != nil {
+				result[group.PolicyName+"/"+group.VfRange] = [2]uint16{*group.OuterVLAN, *group.InnerVLAN}
+			}
+		}
+		return result
+	}
+	desired, applied := qinq(ifaceSpec), qinq(lastApplied)
+	if !reflect.DeepEqual(desired, applied) {
+		log.V(2).Info("NeedToUpdateVfQinQ(): VF double VLAN needs update", "desired", desired, "applied", applied)
+		return true
+	}
+	return false
+}
+
+// NeedToUpdateVfRSS returns true if the RSS configuration of the VF groups differs from the last applied one,
+// the RSS configuration is not reported in the status
+func NeedToUpdateVfRSS(ifaceSpec *Interface, lastApplied *Interface) bool {
+	type rss struct {
+		hashKey    string
+		hashFields string
+	}
+	vfRSS := func(iface *Interface) map[string]rss {
+		result := map[string]rss{}
+		for _, group := range iface.VfGroups {
+			if group.RSSHashKey == nil && len(group.RSSHashFields) == 0 {
+				continue
+			}
+			config := rss{hashFields: strings.Join(group.RSSHashFields, ",")}
+			if group.RSSHashKey != nil {
+				config.hashKey = *group.RSSHashKey
+			}
+			result[group.PolicyName+"/"+group.VfRange] = config
+		}
+		return result
+	}
+	desired, applied := vfRSS(ifaceSpec), vfRSS(lastApplied)
+	if !reflect.DeepEqual(desired, applied) {
+		log.V(2).Info("NeedToUpdateVfRSS(): VF RSS configuration needs update", "desired", desired, "applied", applied)
+		return true
+	}
+	return false
+}
+
+// NeedToUpdateDevlinkParams returns true if the devlink parameters of the PF differ from the last applied ones
+func NeedToUpdateDevlinkParams(ifaceSpec *Interface, lastApplied *Interface) bool {
+	if len(ifaceSpec.DevlinkParams) == 0 && len(lastApplied.DevlinkParams) == 0 {
+		return false
+	}
+	if !maps.Equal(ifaceSpec.DevlinkParams, lastApplied.DevlinkParams) {
+		log.V(2).Info("NeedToUpdateDevlinkParams(): devlink parameters need update",
+			"desired", ifaceSpec.DevlinkParams, "applied", lastApplied.DevlinkParams)
+		return true
+	}
+	return false
+}
+
+// NeedToDrainForSriovUpdate returns true if the configuration of the device differs from the desired one
+// and the change can't be applied without disrupting the workloads which use the VFs
+func NeedToDrainForSriovUpdate(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
+	// the number of VFs and the MTU of externally managed PFs are not changed by the operator,
+	// the PF configuration is validated when the VFs are configured
+	if ifaceSpec.Mtu > 0 && !ifaceSpec.ExternallyManaged {
 		mtu := ifaceSpec.Mtu
 		if mtu > ifaceStatus.Mtu {
-			log.V(2).Info("NeedToUpdateSriov(): MTU needs update", "desired", mtu, "current", ifaceStatus.Mtu)
+			log.V(2).Info("NeedToDrainForSriovUpdate(): MTU needs update", "desired", mtu, "current", ifaceStatus.Mtu)
 			return true
 		}
 	}
 	currentEswitchMode := GetEswitchModeFromStatus(ifaceStatus)
 	desiredEswitchMode := GetEswitchModeFromSpec(ifaceSpec)
 	if currentEswitchMode != desiredEswitchMode {
-		log.V(2).Info("NeedToUpdateSriov(): EswitchMode needs update", "desired", desiredEswitchMode, "current", currentEswitchMode)
+		log.V(2).Info("NeedToDrainForSriovUpdate(): EswitchMode needs update", "desired", desiredEswitchMode, "current", currentEswitchMode)
 		return true
 	}
-	if ifaceSpec.NumVfs != ifaceStatus.NumVfs {
-		log.V(2).Info("NeedToUpdateSriov(): NumVfs needs update", "desired", ifaceSpec.NumVfs, "current", ifaceStatus.NumVfs)
+	if NeedToUpdateEswitchEncapMode(ifaceSpec, ifaceStatus) {
+		log.V(2).Info("NeedToDrainForSriovUpdate(): EswitchEncapMode needs update",
+			"desired", ifaceSpec.EswitchEncapMode, "current", ifaceStatus.EswitchEncapMode)
+		return true
+	}
+	if ifaceSpec.NumVfs != ifaceStatus.NumVfs && !ifaceSpec.ExternallyManaged {
+		log.V(2).Info("NeedToDrainForSriovUpdate(): NumVfs needs update", "desired", ifaceSpec.NumVfs, "current", ifaceStatus.NumVfs)
 		return true
 	}
 
 	if ifaceStatus.LinkAdminState == consts.LinkAdminStateDown {
-		log.V(2).Info("NeedToUpdateSriov(): PF link status needs update", "desired to include", "up", "current", ifaceStatus.LinkAdminState)
+		log.V(2).Info("NeedToDrainForSriovUpdate(): PF link status needs update", "desired to include", "up", "current", ifaceStatus.LinkAdminState)
 		return true
 	}
 
@@ -285,47 +612,41 @@ func NeedToUpdateSriov(ifaceSpec *Interface, ifaceStatus *InterfaceExt) bool {
 			for _, groupSpec := range ifaceSpec.VfGroups {
 				if IndexInRange(vfStatus.VfID, groupSpec.VfRange) {
 					if vfStatus.Driver == "" {
-						log.V(2).Info("NeedToUpdateSriov(): Driver needs update - has no driver",
+						log.V(2).Info("NeedToDrainForSriovUpdate(): Driver needs update - has no driver",
 							"desired", groupSpec.DeviceType)
 						return true
 					}
 					if groupSpec.DeviceType != "" && groupSpec.DeviceType != consts.DeviceTypeNetDevice {
 						if groupSpec.DeviceType != vfStatus.Driver {
-							log.V(2).Info("NeedToUpdateSriov(): Driver needs update",
+							log.V(2).Info("NeedToDrainForSriovUpdate(): Driver needs update",
 								"desired", groupSpec.DeviceType, "current", vfStatus.Driver)
 							return true
 						}
 					} else {
 						if StringInArray(vfStatus.Driver, vars.DpdkDrivers) {
-							log.V(2).Info("NeedToUpdateSriov(): Driver needs update",
+							log.V(2).Info("NeedToDrainForSriovUpdate(): Driver needs update",
 								"desired", groupSpec.DeviceType, "current", vfStatus.Driver)
 							return true
 						}
-						if vfStatus.Mtu != 0 && groupSpec.Mtu != 0 && vfStatus.Mtu != groupSpec.Mtu {
-							log.V(2).Info("NeedToUpdateSriov(): VF MTU needs update",
-								"vf", vfStatus.VfID, "desired", groupSpec.Mtu, "current", vfStatus.Mtu)
-							return true
-						}
-
 						if (strings.EqualFold(ifaceStatus.LinkType, consts.LinkTypeETH) && groupSpec.IsRdma) || strings.EqualFold(ifaceStatus.LinkType, consts.LinkTypeIB) {
 							// We do this check only if a Node GUID is set to ensure that we were able to read the
 							// Node GUID. We intentionally skip empty Node GUID in vfStatus because this may happen
 							// when the VF is allocated to a workload.
 							if vfStatus.GUID == consts.UninitializedNodeGUID {
-								log.V(2).Info("NeedToUpdateSriov(): VF GUID needs update",
+								log.V(2).Info("NeedToDrainForSriovUpdate(): VF GUID needs update",
 									"vf", vfStatus.VfID, "current", vfStatus.GUID)
 								return true
 							}
 						}
 						// this is needed to be sure the admin mac address is configured as expected
 						if ifaceSpec.ExternallyManaged {
-							log.V(2).Info("NeedToUpdateSriov(): need to update the device as it's externally manage",
+							log.V(2).Info("NeedToDrainForSriovUpdate(): need to update the device as it's externally manage",
 								"device", ifaceStatus.PciAddress)
 							return true
 						}
 					}
 					if groupSpec.VdpaType != vfStatus.VdpaType {
-						log.V(2).Info("NeedToUpdateSriov(): VF VdpaType mismatch",
+						log.V(2).Info("NeedToDrainForSriovUpdate(): VF VdpaType mismatch",
 							"desired", groupSpec.VdpaType, "current", vfStatus.VdpaType)
 						return true
 					}
@@ -354,6 +675,14 @@ func (a ByPriority) Swap(i, j int) {
 	a[i], a[j] = a[j], a[i]
 }
 
+// GetVfMtu returns the MTU which should be configured for the VFs allocated by the policy
+func (s *SriovNetworkNodePolicySpec) GetVfMtu() int {
+	if s.VfMtu > 0 {
+		return s.VfMtu
+	}
+	return s.Mtu
+}
+
 // Match check if node is selected by NodeSelector
 func (p *SriovNetworkNodePolicy) Selected(node *corev1.Node) bool {
 	for k, v := range p.Spec.NodeSelector {
@@ -406,14 +735,32 @@ func (p *SriovNetworkNodePolicy) Apply(state *SriovNetworkNodeState, equalPriori
 	for _, iface := range state.Status.Interfaces {
 		if s.Selected(&iface) {
 			log.Info("Update interface", "name:", iface.Name)
+			if p.Spec.RdmaMode != "" {
+				// the mode is node-wide, the policy applied last has the highest priority
+				state.Spec.System.RdmaMode = p.Spec.RdmaMode
+			}
 			result := Interface{
-				PciAddress:        iface.PciAddress,
-				Mtu:               p.Spec.Mtu,
-				Name:              iface.Name,
-				LinkType:          p.Spec.LinkType,
-				EswitchMode:       p.Spec.EswitchMode,
-				NumVfs:            p.Spec.NumVfs,
-				ExternallyManaged: p.Spec.ExternallyManaged,
+				PciAddress:              iface.PciAddress,
+				Mtu:                     p.Spec.Mtu,
+				Name:                    iface.Name,
+				LinkType:                p.Spec.LinkType,
+				EswitchMode:             p.Spec.EswitchMode,
+				EswitchEncapMode:        p.Spec.EswitchEncapMode,
+				NumVfs:                  p.Spec.NumVfs,
+				ExternallyManaged:       p.Spec.ExternallyManaged,
+				VfGroupSortPolicy:       p.Spec.VfGroupSortPolicy,
+				Hugepages:               p.Spec.Hugepages.DeepCopy(),
+				DisablePfLinkManagement: p.Spec.DisablePfLinkManagement,
+				VxlanOffload:            p.Spec.VxlanOffload,
+				GeneveOffload:           p.Spec.GeneveOffload,
+				NumaNode:                s.NumaNode,
+				DevlinkParams:           maps.Clone(p.Spec.DevlinkParams),
+				DCBXAutoConfig:          p.Spec.DCBXAutoConfig,
+				AllowBondedPF:           p.Spec.AllowBondedPF,
+				IRQAffinity:             p.Spec.IRQAffinity,
+				OVSDPDKOffload:          p.Spec.OVSDPDKOffload,
+				RepresentorMapping:      maps.Clone(p.Spec.RepresentorMapping),
+				PfSettings:              p.Spec.PfSettings.DeepCopy(),
 			}
 			if p.Spec.NumVfs > 0 {
 				group, err := p.generatePfNameVfGroup(&iface)
@@ -421,6 +768,11 @@ func (p *SriovNetworkNodePolicy) Apply(state *SriovNetworkNodeState, equalPriori
 					return err
 				}
 				result.VfGroups = []VfGroup{*group}
+				if driver := p.Annotations[consts.DriverOverrideAnnotation]; driver != "" {
+					if err := setDriverOverride(state, p.Name, driver); err != nil {
+						return err
+					}
+				}
 				found := false
 				for i := range state.Spec.Interfaces {
 					if state.Spec.Interfaces[i].PciAddress == result.PciAddress {
@@ -439,6 +791,38 @@ func (p *SriovNetworkNodePolicy) Apply(state *SriovNetworkNodeState, equalPriori
 	return nil
 }
 
+// GetDriverOverrides returns the drivers which override the device types of the VF groups of the node state
+// by policy name
+func GetDriverOverrides(state *SriovNetworkNodeState) (map[string]string, error) {
+	overrides := map[string]string{}
+	value := state.Annotations[consts.NodeStateDriverOverridesAnnotation]
+	if value == "" {
+		return overrides, nil
+	}
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse annotation %s: %v", consts.NodeStateDriverOverridesAnnotation, err)
+	}
+	return overrides, nil
+}
+
+// setDriverOverride records the driver override of the policy in the annotations of the node state
+func setDriverOverride(state *SriovNetworkNodeState, policyName, driver string) error {
+	overrides, err := GetDriverOverrides(state)
+	if err != nil {
+		return err
+	}
+	overrides[policyName] = driver
+	value, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	if state.Annotations == nil {
+		state.Annotations = map[string]string{}
+	}
+	state.Annotations[consts.NodeStateDriverOverridesAnnotation] = string(value)
+	return nil
+}
+
 // ApplyBridgeConfig applies bridge configuration from the policy to the provided state
 func (p *SriovNetworkNodePolicy) ApplyBridgeConfig(state *SriovNetworkNodeState) error {
 	if p.Spec.NicSelector.IsEmpty() {
@@ -516,6 +900,62 @@ func (iface Interface) mergeConfigs(input *Interface, equalPriority bool) {
 		input.VfGroups = append(input.VfGroups, gr)
 	}
 
+	// keep the sort policy from the lower priority policy if the highest one doesn't set it
+	if input.VfGroupSortPolicy == "" {
+		input.VfGroupSortPolicy = iface.VfGroupSortPolicy
+	}
+	// keep the eSwitch encapsulation mode from the lower priority policy if the highest one doesn't set it
+	if input.EswitchEncapMode == "" {
+		input.EswitchEncapMode = iface.EswitchEncapMode
+	}
+	// keep the hugepages request from the lower priority policy if the highest one doesn't set it
+	if input.Hugepages == nil {
+		input.Hugepages = iface.Hugepages
+	}
+	// PF link state is not managed if any of the policies disables it
+	input.DisablePfLinkManagement = input.DisablePfLinkManagement || iface.DisablePfLinkManagement
+	// the traffic classes of the VFs are configured if any of the policies enables it
+	input.DCBXAutoConfig = input.DCBXAutoConfig || iface.DCBXAutoConfig
+	// the PF enslaved to a bond is configured if any of the policies allows it
+	input.AllowBondedPF = input.AllowBondedPF || iface.AllowBondedPF
+	// keep the encapsulation offload settings from the lower priority policy if the highest one doesn't set them
+	if input.VxlanOffload == nil {
+		input.VxlanOffload = iface.VxlanOffload
+	}
+	if input.GeneveOffload == nil {
+		input.GeneveOffload = iface.GeneveOffload
+	}
+	if input.NumaNode == nil {
+		input.NumaNode = iface.NumaNode
+	}
+	input.PfSettings = mergePfSettings(input.PfSettings, iface.PfSettings)
+	// keep the interrupt affinity from the lower priority policy if the highest one doesn't set it
+	if input.IRQAffinity == nil {
+		input.IRQAffinity = iface.IRQAffinity
+	}
+	// the VFs are configured for OVS-DPDK offload if any of the policies enables it, the OVS ports
+	// of the VFs which the highest priority policy doesn't map are kept from the lower priority one
+	input.OVSDPDKOffload = input.OVSDPDKOffload || iface.OVSDPDKOffload
+	for vfID, port := range iface.RepresentorMapping {
+		if _, ok := input.RepresentorMapping[vfID]; ok {
+			continue
+		}
+		if input.RepresentorMapping == nil {
+			input.RepresentorMapping = map[int]string{}
+		}
+		input.RepresentorMapping[vfID] = port
+	}
+	// keep the devlink parameters from the lower priority policy which the highest one doesn't set
+	for name, value := range iface.DevlinkParams {
+		if _, ok := input.DevlinkParams[name]; ok {
+			continue
+		}
+		if input.DevlinkParams == nil {
+			input.DevlinkParams = map[string]string{}
+		}
+		input.DevlinkParams[name] = value
+	}
+
 	if !equalPriority && !m {
 		return
 	}
@@ -570,16 +1010,89 @@ func (p *SriovNetworkNodePolicy) generatePfNameVfGroup(iface *InterfaceExt) (*Vf
 	}
 	rng := strconv.Itoa(rngStart) + "-" + strconv.Itoa(rngEnd)
 	return &VfGroup{
-		ResourceName: p.Spec.ResourceName,
-		DeviceType:   p.Spec.DeviceType,
-		VfRange:      rng,
-		PolicyName:   p.GetName(),
-		Mtu:          p.Spec.Mtu,
-		IsRdma:       p.Spec.IsRdma,
-		VdpaType:     p.Spec.VdpaType,
+		ResourceName:          p.Spec.ResourceName,
+		DeviceType:            p.Spec.DeviceType,
+		VfRange:               rng,
+		PolicyName:            p.GetName(),
+		Mtu:                   p.Spec.GetVfMtu(),
+		IsRdma:                p.Spec.IsRdma,
+		VdpaType:              p.Spec.VdpaType,
+		BlacklistKernelDriver: p.Spec.BlacklistKernelDriver,
+		NeedVhostNet:          p.Spec.NeedVhostNet,
+		DSCP:                  p.Spec.DSCP,
+		EgressBandwidthMbps:   p.Spec.EgressBandwidthMbps,
+		Trust:                 p.Spec.Trust,
+		SpoofChk:              p.Spec.SpoofChk,
+		MinTxRate:             p.Spec.MinTxRate,
+		MaxTxRate:             p.Spec.MaxTxRate,
+		Vlan:                  p.Spec.Vlan,
+		VlanQoS:               p.Spec.VlanQoS,
+		VlanProto:             p.Spec.VlanProto,
+		VLANFilter:            p.Spec.VLANFilter,
+		OuterVLAN:             p.Spec.OuterVLAN,
+		InnerVLAN:             p.Spec.InnerVLAN,
+		RSSHashKey:            p.Spec.RSSHashKey,
+		RSSHashFields:         p.Spec.RSSHashFields,
 	}, nil
 }
 
+// SortVfGroupsByPolicy returns a copy of the VF groups ordered according to the provided policy.
+// VF groups of the same PF are configured one by one and a VF is assigned to the first group which
+// contains its index, the order is important when groups are overlapping.
+// Unknown or empty policy is handled as VfGroupSortPolicyFirstFit which keeps the original order.
+func SortVfGroupsByPolicy(groups []VfGroup, policy string) []VfGroup {
+	sorted := make([]VfGroup, len(groups))
+	copy(sorted, groups)
+	switch policy {
+	case VfGroupSortPolicyPciOrder:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			rngStI, _, errI := parseRange(sorted[i].VfRange)
+			rngStJ, _, errJ := parseRange(sorted[j].VfRange)
+			if errI != nil || errJ != nil {
+				// groups with invalid range go last
+				return errI == nil && errJ != nil
+			}
+			return rngStI < rngStJ
+		})
+	case VfGroupSortPolicyResourceName:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].ResourceName < sorted[j].ResourceName
+		})
+	}
+	return sorted
+}
+
+// NormalizeInterfaceSpec returns a copy of the interfaces which can be compared with reflect.DeepEqual:
+// the interfaces are sorted by PCI address, the VF groups of each interface by resource name,
+// the VLAN filters and the RSS hash fields of the groups are sorted and the device and link types are lowercased.
+// The order of the VF groups matters only for overlapping groups which are rejected by the webhook.
+func NormalizeInterfaceSpec(ifaces Interfaces) Interfaces {
+	normalized := make(Interfaces, len(ifaces))
+	for i := range ifaces {
+		iface := ifaces[i].DeepCopy()
+		iface.LinkType = strings.ToLower(iface.LinkType)
+		for j := range iface.VfGroups {
+			group := &iface.VfGroups[j]
+			group.DeviceType = strings.ToLower(group.DeviceType)
+			sort.SliceStable(group.VLANFilter, func(a, b int) bool {
+				return group.VLANFilter[a].VID < group.VLANFilter[b].VID
+			})
+			slices.Sort(group.RSSHashFields)
+		}
+		sort.SliceStable(iface.VfGroups, func(a, b int) bool {
+			if iface.VfGroups[a].ResourceName != iface.VfGroups[b].ResourceName {
+				return iface.VfGroups[a].ResourceName < iface.VfGroups[b].ResourceName
+			}
+			return iface.VfGroups[a].PolicyName < iface.VfGroups[b].PolicyName
+		})
+		normalized[i] = *iface
+	}
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return normalized[i].PciAddress < normalized[j].PciAddress
+	})
+	return normalized
+}
+
 func IndexInRange(i int, r string) bool {
 	rngSt, rngEnd, err := parseRange(r)
 	if err != nil {
@@ -665,6 +1178,10 @@ func (selector *SriovNetworkNicSelector) Selected(iface *InterfaceExt) bool {
 	if selector.NetFilter != "" && !NetFilterMatch(selector.NetFilter, iface.NetFilter) {
 		return false
 	}
+	// PFs without NUMA affinity are not on any of the NUMA nodes
+	if selector.NumaNode != nil && (iface.NumaNode == nil || *iface.NumaNode != *selector.NumaNode) {
+		return false
+	}
 
 	return true
 }
@@ -994,6 +1511,61 @@ func (s *SriovNetworkPoolConfig) MaxUnavailable(numOfNodes int) (int, error) {
 	return maxunavail, nil
 }
 
+// PfSettingsFeatures are the ethtool features of the PFs which can be set by the pfSettings of the policies
+var PfSettingsFeatures = []string{"rx-ntuple-filter", "rx-vlan-filter", "rx-all", "rx-fcs", "rx-hashing"}
+
+// mergePfSettings returns the PF settings of the highest priority policy completed with the settings
+// of the lower priority policy which it doesn't set
+func mergePfSettings(high, low *PfSettings) *PfSettings {
+	if low == nil {
+		return high
+	}
+	if high == nil {
+		return low.DeepCopy()
+	}
+	merged := high.DeepCopy()
+	if merged.Promisc == nil {
+		merged.Promisc = low.Promisc
+	}
+	if merged.AllMulticast == nil {
+		merged.AllMulticast = low.AllMulticast
+	}
+	for name, enabled := range low.Features {
+		if _, ok := merged.Features[name]; ok {
+			continue
+		}
+		if merged.Features == nil {
+			merged.Features = map[string]bool{}
+		}
+		merged.Features[name] = enabled
+	}
+	return merged
+}
+
+// GetVfOvercommitRatio returns the maximum allowed VF overcommit ratio, zero is returned if the ratio is not set
+func (s *SriovOperatorConfigSpec) GetVfOvercommitRatio() (float64, error) {
+	if s.VfOvercommitRatio == "" {
+		return 0, nil
+	}
+	ratio, err := strconv.ParseFloat(s.VfOvercommitRatio, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid VF overcommit ratio %q: %v", s.VfOvercommitRatio, err)
+	}
+	if ratio < 1 {
+		return 0, fmt.Errorf("invalid VF overcommit ratio %q: the ratio can't be lower than 1", s.VfOvercommitRatio)
+	}
+	return ratio, nil
+}
+
+// GetVfAttributeReconcileInterval returns the interval of the periodic reconciliation of the VF attributes,
+// zero is returned if the reconciliation is disabled
+func (s *SriovOperatorConfigSpec) GetVfAttributeReconcileInterval() time.Duration {
+	if s.VfAttributeReconcileInterval == nil || s.VfAttributeReconcileInterval.Duration < 0 {
+		return 0
+	}
+	return s.VfAttributeReconcileInterval.Duration
+}
+
 // GenerateBridgeName generate predictable name for the software bridge
 // current format is: br-0000_00_03.0
 func GenerateBridgeName(iface *InterfaceExt) string {
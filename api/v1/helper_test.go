@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 
 	v1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
@@ -372,6 +373,76 @@ func TestSriovNetworkNodePolicyApply(t *testing.T) {
 				},
 			},
 		},
+		{
+			tname:        "policy with VF MTU",
+			currentState: newNodeState(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.Mtu = 9000
+				p.Spec.VfMtu = 1500
+				return p
+			}(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Mtu:        9000,
+					Name:       "ens803f1",
+					NumVfs:     2,
+					PciAddress: "0000:86:00.1",
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "0-1",
+							PolicyName:   "p1",
+							Mtu:          1500,
+						},
+					},
+				},
+			},
+		},
+		{
+			tname: "policy with NUMA node selects only PFs on the NUMA node",
+			currentState: func() *v1.SriovNetworkNodeState {
+				st := newNodeState()
+				st.Status.Interfaces[0].NumaNode = ptr.To(0)
+				st.Status.Interfaces[1].NumaNode = ptr.To(1)
+				return st
+			}(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.NicSelector = v1.SriovNetworkNicSelector{Vendor: "8086", DeviceID: "158b", NumaNode: ptr.To(1)}
+				return p
+			}(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Name:       "ens803f1",
+					NumVfs:     2,
+					PciAddress: "0000:86:00.1",
+					NumaNode:   ptr.To(1),
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "0-1",
+							PolicyName:   "p1",
+						},
+					},
+				},
+			},
+		},
+		{
+			tname:        "policy with NUMA node doesn't select PFs without NUMA affinity",
+			currentState: newNodeState(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.NicSelector.NumaNode = ptr.To(0)
+				return p
+			}(),
+			equalP:             false,
+			expectedInterfaces: nil,
+		},
 		{
 			tname: "one policy present different pf",
 			currentState: func() *v1.SriovNetworkNodeState {
@@ -738,6 +809,219 @@ func TestSriovNetworkNodePolicyApply(t *testing.T) {
 				},
 			},
 		},
+		{
+			tname: "devlink parameters merged from the lower priority policy",
+			currentState: func() *v1.SriovNetworkNodeState {
+				st := newNodeState()
+				st.Spec.Interfaces = []v1.Interface{
+					{
+						Name:          "ens803f1",
+						NumVfs:        4,
+						PciAddress:    "0000:86:00.1",
+						DevlinkParams: map[string]string{"enable_roce": "false", "flow_steering_mode": "smfs"},
+						VfGroups: []v1.VfGroup{
+							{
+								DeviceType:   consts.DeviceTypeVfioPci,
+								ResourceName: "p2res",
+								VfRange:      "2-3",
+								PolicyName:   "p2",
+							},
+						},
+					},
+				}
+				return st
+			}(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.DevlinkParams = map[string]string{"flow_steering_mode": "dmfs"}
+				return p
+			}(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Name:          "ens803f1",
+					NumVfs:        4,
+					PciAddress:    "0000:86:00.1",
+					DevlinkParams: map[string]string{"enable_roce": "false", "flow_steering_mode": "dmfs"},
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "0-1",
+							PolicyName:   "p1",
+						},
+						{
+							DeviceType:   consts.DeviceTypeVfioPci,
+							ResourceName: "p2res",
+							VfRange:      "2-3",
+							PolicyName:   "p2",
+						},
+					},
+				},
+			},
+		},
+		{
+			tname: "OVS-DPDK offload representor mapping merged from the lower priority policy",
+			currentState: func() *v1.SriovNetworkNodeState {
+				st := newNodeState()
+				st.Spec.Interfaces = []v1.Interface{
+					{
+						Name:               "ens803f1",
+						NumVfs:             4,
+						PciAddress:         "0000:86:00.1",
+						OVSDPDKOffload:     true,
+						RepresentorMapping: map[int]string{1: "vf1", 2: "vf2-rep"},
+						VfGroups: []v1.VfGroup{
+							{
+								DeviceType:   consts.DeviceTypeVfioPci,
+								ResourceName: "p2res",
+								VfRange:      "2-3",
+								PolicyName:   "p2",
+							},
+						},
+					},
+				}
+				return st
+			}(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.RepresentorMapping = map[int]string{0: "vf0-rep", 1: "vf1-rep"}
+				return p
+			}(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Name:               "ens803f1",
+					NumVfs:             4,
+					PciAddress:         "0000:86:00.1",
+					OVSDPDKOffload:     true,
+					RepresentorMapping: map[int]string{0: "vf0-rep", 1: "vf1-rep", 2: "vf2-rep"},
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "0-1",
+							PolicyName:   "p1",
+						},
+						{
+							DeviceType:   consts.DeviceTypeVfioPci,
+							ResourceName: "p2res",
+							VfRange:      "2-3",
+							PolicyName:   "p2",
+						},
+					},
+				},
+			},
+		},
+		{
+			tname: "PF settings merged from the lower priority policy",
+			currentState: func() *v1.SriovNetworkNodeState {
+				st := newNodeState()
+				st.Spec.Interfaces = []v1.Interface{
+					{
+						Name:       "ens803f1",
+						NumVfs:     4,
+						PciAddress: "0000:86:00.1",
+						PfSettings: &v1.PfSettings{
+							Promisc:  ptr.To(false),
+							Features: map[string]bool{"rx-all": true, "rx-fcs": true},
+						},
+						VfGroups: []v1.VfGroup{
+							{
+								DeviceType:   consts.DeviceTypeVfioPci,
+								ResourceName: "p2res",
+								VfRange:      "2-3",
+								PolicyName:   "p2",
+							},
+						},
+					},
+				}
+				return st
+			}(),
+			policy: func() *v1.SriovNetworkNodePolicy {
+				p := newNodePolicy()
+				p.Spec.PfSettings = &v1.PfSettings{
+					Promisc:      ptr.To(true),
+					AllMulticast: ptr.To(true),
+					Features:     map[string]bool{"rx-fcs": false},
+				}
+				return p
+			}(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Name:       "ens803f1",
+					NumVfs:     4,
+					PciAddress: "0000:86:00.1",
+					PfSettings: &v1.PfSettings{
+						Promisc:      ptr.To(true),
+						AllMulticast: ptr.To(true),
+						Features:     map[string]bool{"rx-all": true, "rx-fcs": false},
+					},
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "0-1",
+							PolicyName:   "p1",
+						},
+						{
+							DeviceType:   consts.DeviceTypeVfioPci,
+							ResourceName: "p2res",
+							VfRange:      "2-3",
+							PolicyName:   "p2",
+						},
+					},
+				},
+			},
+		},
+		{
+			tname: "DCBX auto configuration kept from the lower priority policy",
+			currentState: func() *v1.SriovNetworkNodeState {
+				st := newNodeState()
+				st.Spec.Interfaces = []v1.Interface{
+					{
+						Name:           "ens803f1",
+						NumVfs:         4,
+						PciAddress:     "0000:86:00.1",
+						DCBXAutoConfig: true,
+						VfGroups: []v1.VfGroup{
+							{
+								DeviceType:   consts.DeviceTypeVfioPci,
+								ResourceName: "p2res",
+								VfRange:      "2-3",
+								PolicyName:   "p2",
+							},
+						},
+					},
+				}
+				return st
+			}(),
+			policy: newNodePolicy(),
+			equalP: false,
+			expectedInterfaces: []v1.Interface{
+				{
+					Name:           "ens803f1",
+					NumVfs:         4,
+					PciAddress:     "0000:86:00.1",
+					DCBXAutoConfig: true,
+					VfGroups: []v1.VfGroup{
+						{
+							DeviceType:   consts.DeviceTypeNetDevice,
+							ResourceName: "p1res",
+							VfRange:      "0-1",
+							PolicyName:   "p1",
+						},
+						{
+							DeviceType:   consts.DeviceTypeVfioPci,
+							ResourceName: "p2res",
+							VfRange:      "2-3",
+							PolicyName:   "p2",
+						},
+					},
+				},
+			},
+		},
 		{
 			tname:        "no selectors",
 			currentState: newNodeState(),
@@ -961,6 +1245,51 @@ func TestGetEswitchModeFromStatus(t *testing.T) {
 	}
 }
 
+func TestNeedToUpdateEswitchEncapMode(t *testing.T) {
+	testtable := []struct {
+		tname          string
+		spec           *v1.Interface
+		status         *v1.InterfaceExt
+		expectedResult bool
+	}{
+		{
+			tname:          "not set",
+			spec:           &v1.Interface{EswitchMode: v1.ESwithModeSwitchDev},
+			status:         &v1.InterfaceExt{EswitchMode: v1.ESwithModeSwitchDev, EswitchEncapMode: v1.ESwitchEncapModeBasic},
+			expectedResult: false,
+		},
+		{
+			tname:          "same mode",
+			spec:           &v1.Interface{EswitchMode: v1.ESwithModeSwitchDev, EswitchEncapMode: v1.ESwitchEncapModeBasic},
+			status:         &v1.InterfaceExt{EswitchMode: v1.ESwithModeSwitchDev, EswitchEncapMode: v1.ESwitchEncapModeBasic},
+			expectedResult: false,
+		},
+		{
+			tname:          "mode changes",
+			spec:           &v1.Interface{EswitchMode: v1.ESwithModeSwitchDev, EswitchEncapMode: v1.ESwitchEncapModeNone},
+			status:         &v1.InterfaceExt{EswitchMode: v1.ESwithModeSwitchDev, EswitchEncapMode: v1.ESwitchEncapModeBasic},
+			expectedResult: true,
+		},
+		{
+			tname:          "legacy mode",
+			spec:           &v1.Interface{EswitchMode: v1.ESwithModeLegacy, EswitchEncapMode: v1.ESwitchEncapModeNone},
+			status:         &v1.InterfaceExt{EswitchMode: v1.ESwithModeLegacy, EswitchEncapMode: v1.ESwitchEncapModeBasic},
+			expectedResult: false,
+		},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			if result := v1.NeedToUpdateEswitchEncapMode(tc.spec, tc.status); result != tc.expectedResult {
+				t.Errorf("unexpected result: want %t, got %t", tc.expectedResult, result)
+			}
+			// the encapsulation mode follows the drain decision of the eSwitch mode
+			if result := v1.NeedToDrainForSriovUpdate(tc.spec, tc.status); result != tc.expectedResult {
+				t.Errorf("unexpected drain decision: want %t, got %t", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
 func TestSriovNetworkPoolConfig_MaxUnavailable(t *testing.T) {
 	testtable := []struct {
 		tname       string
@@ -1061,9 +1390,10 @@ func TestNeedToUpdateSriov(t *testing.T) {
 		ifaceStatus *v1.InterfaceExt
 	}
 	tests := []struct {
-		name string
-		args args
-		want bool
+		name      string
+		args      args
+		want      bool
+		wantDrain bool
 	}{
 		{
 			name: "number of VFs changed",
@@ -1071,7 +1401,8 @@ func TestNeedToUpdateSriov(t *testing.T) {
 				ifaceSpec:   &v1.Interface{NumVfs: 1},
 				ifaceStatus: &v1.InterfaceExt{NumVfs: 0},
 			},
-			want: true,
+			want:      true,
+			wantDrain: true,
 		},
 		{
 			name: "no update",
@@ -1081,6 +1412,14 @@ func TestNeedToUpdateSriov(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "number of VFs and MTU of externally managed PF differ",
+			args: args{
+				ifaceSpec:   &v1.Interface{NumVfs: 2, Mtu: 9000, ExternallyManaged: true},
+				ifaceStatus: &v1.InterfaceExt{NumVfs: 4, Mtu: 1500},
+			},
+			want: false,
+		},
 		{
 			name: "vfio-pci VF is not configured for any group",
 			args: args{
@@ -1113,19 +1452,346 @@ func TestNeedToUpdateSriov(t *testing.T) {
 			},
 			want: false,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := v1.NeedToUpdateSriov(tt.args.ifaceSpec, tt.args.ifaceStatus); got != tt.want {
-				t.Errorf("NeedToUpdateSriov() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestSriovNetworkNodePolicyApplyBridgeConfig(t *testing.T) {
-	testtable := []struct {
-		tname           string
+		{
+			name: "VF group MTU changed",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					Mtu:    9000,
+					NumVfs: 2,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeNetDevice,
+							Mtu:        1500,
+						},
+						{
+							VfRange:    "1-1",
+							DeviceType: consts.DeviceTypeNetDevice,
+							Mtu:        9000,
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					Mtu:    9000,
+					NumVfs: 2,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:   0,
+							Driver: "iavf",
+							Mtu:    9000,
+						},
+						{
+							VfID:   1,
+							Driver: "iavf",
+							Mtu:    9000,
+						},
+					},
+				},
+			},
+			want:      true,
+			wantDrain: false,
+		},
+		{
+			name: "VF trust mode reset by the driver",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					NumVfs: 1,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeVfioPci,
+							Trust:      "on",
+							SpoofChk:   "off",
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					NumVfs: 1,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:     0,
+							Driver:   "vfio-pci",
+							Trust:    "off",
+							SpoofChk: "off",
+						},
+					},
+				},
+			},
+			want:      true,
+			wantDrain: false,
+		},
+		{
+			name: "VF trust mode removed from the policy",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					NumVfs: 1,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeVfioPci,
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					NumVfs: 1,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:     0,
+							Driver:   "vfio-pci",
+							Trust:    "on",
+							SpoofChk: "on",
+						},
+					},
+				},
+			},
+			want:      true,
+			wantDrain: false,
+		},
+		{
+			name: "VF tx rate changed",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					NumVfs: 1,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeNetDevice,
+							MinTxRate:  1000,
+							MaxTxRate:  10000,
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					NumVfs: 1,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:      0,
+							Driver:    "iavf",
+							MaxTxRate: 10000,
+						},
+					},
+				},
+			},
+			want:      true,
+			wantDrain: false,
+		},
+		{
+			name: "VF tx rate cleared",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					NumVfs: 1,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeNetDevice,
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					NumVfs: 1,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:      0,
+							Driver:    "iavf",
+							MinTxRate: 1000,
+						},
+					},
+				},
+			},
+			want:      true,
+			wantDrain: false,
+		},
+		{
+			name: "VF VLAN changed",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					NumVfs: 1,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeVfioPci,
+							Vlan:       100,
+							VlanQoS:    3,
+							VlanProto:  "802.1ad",
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					NumVfs: 1,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:      0,
+							Driver:    "vfio-pci",
+							Vlan:      100,
+							VlanQoS:   3,
+							VlanProto: "802.1q",
+						},
+					},
+				},
+			},
+			want:      true,
+			wantDrain: false,
+		},
+		{
+			name: "VF VLAN cleared",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					NumVfs: 1,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeVfioPci,
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					NumVfs: 1,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:      0,
+							Driver:    "vfio-pci",
+							Vlan:      100,
+							VlanProto: "802.1q",
+						},
+					},
+				},
+			},
+			want:      true,
+			wantDrain: false,
+		},
+		{
+			name: "VLAN of netdevice VF is ignored",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					NumVfs: 1,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeNetDevice,
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					NumVfs: 1,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:      0,
+							Driver:    "iavf",
+							Vlan:      100,
+							VlanProto: "802.1q",
+						},
+					},
+				},
+			},
+			want:      false,
+			wantDrain: false,
+		},
+		{
+			name: "MTU of VF with DPDK driver is ignored",
+			args: args{
+				ifaceSpec: &v1.Interface{
+					NumVfs: 1,
+					VfGroups: []v1.VfGroup{
+						{
+							VfRange:    "0-0",
+							DeviceType: consts.DeviceTypeVfioPci,
+							Mtu:        1500,
+						},
+					},
+				},
+				ifaceStatus: &v1.InterfaceExt{
+					NumVfs: 1,
+					VFs: []v1.VirtualFunction{
+						{
+							VfID:   0,
+							Driver: "vfio-pci",
+							Mtu:    9000,
+						},
+					},
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v1.NeedToUpdateSriov(tt.args.ifaceSpec, tt.args.ifaceStatus); got != tt.want {
+				t.Errorf("NeedToUpdateSriov() = %v, want %v", got, tt.want)
+			}
+			if got := v1.NeedToDrainForSriovUpdate(tt.args.ifaceSpec, tt.args.ifaceStatus); got != tt.wantDrain {
+				t.Errorf("NeedToDrainForSriovUpdate() = %v, want %v", got, tt.wantDrain)
+			}
+		})
+	}
+}
+
+func TestSriovNetworkNodePolicyApplyRdmaMode(t *testing.T) {
+	state := newNodeState()
+	state.Spec.System.RdmaMode = consts.RdmaSubsystemModeShared
+
+	// policy without RDMA mode doesn't change the mode
+	policy := newNodePolicy()
+	if err := policy.Apply(state, false); err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if state.Spec.System.RdmaMode != consts.RdmaSubsystemModeShared {
+		t.Errorf("Apply() RdmaMode = %q, want %q", state.Spec.System.RdmaMode, consts.RdmaSubsystemModeShared)
+	}
+
+	// policy which doesn't select an interface of the node doesn't change the mode
+	policy.Spec.IsRdma = true
+	policy.Spec.RdmaMode = consts.RdmaSubsystemModeExclusive
+	policy.Spec.NicSelector.PfNames = []string{"ens1f0"}
+	policy.Spec.NicSelector.RootDevices = nil
+	if err := policy.Apply(state, false); err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if state.Spec.System.RdmaMode != consts.RdmaSubsystemModeShared {
+		t.Errorf("Apply() RdmaMode = %q, want %q", state.Spec.System.RdmaMode, consts.RdmaSubsystemModeShared)
+	}
+
+	policy.Spec.NicSelector = newNodePolicy().Spec.NicSelector
+	if err := policy.Apply(state, false); err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if state.Spec.System.RdmaMode != consts.RdmaSubsystemModeExclusive {
+		t.Errorf("Apply() RdmaMode = %q, want %q", state.Spec.System.RdmaMode, consts.RdmaSubsystemModeExclusive)
+	}
+}
+
+func TestSriovNetworkNodePolicyApplyDriverOverride(t *testing.T) {
+	state := newNodeState()
+
+	// policy without override doesn't record anything
+	if err := newNodePolicy().Apply(state, false); err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if _, ok := state.Annotations[consts.NodeStateDriverOverridesAnnotation]; ok {
+		t.Errorf("Apply() unexpected annotation %s", consts.NodeStateDriverOverridesAnnotation)
+	}
+
+	policy := newNodePolicy()
+	policy.Annotations = map[string]string{consts.DriverOverrideAnnotation: "vfio-pci"}
+	if err := policy.Apply(state, false); err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	overrides, err := v1.GetDriverOverrides(state)
+	if err != nil {
+		t.Fatalf("GetDriverOverrides() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"p1": "vfio-pci"}, overrides); diff != "" {
+		t.Errorf("GetDriverOverrides() mismatch (-want +got):\n%s", diff)
+	}
+
+	state.Annotations[consts.NodeStateDriverOverridesAnnotation] = "{"
+	if _, err := v1.GetDriverOverrides(state); err == nil {
+		t.Errorf("GetDriverOverrides() expected an error for an invalid annotation")
+	}
+}
+
+func TestSriovNetworkNodePolicyApplyBridgeConfig(t *testing.T) {
+	testtable := []struct {
+		tname           string
 		currentState    *v1.SriovNetworkNodeState
 		policy          *v1.SriovNetworkNodePolicy
 		expectedBridges v1.Bridges
@@ -1534,3 +2200,346 @@ func TestNeedToUpdateBridges(t *testing.T) {
 		})
 	}
 }
+
+func TestNeedToUpdateVfBridgeVLAN(t *testing.T) {
+	vfGroup := func(filter ...v1.VLANFilterEntry) v1.VfGroup {
+		return v1.VfGroup{PolicyName: "p1", VfRange: "0-3", DeviceType: consts.DeviceTypeNetDevice, VLANFilter: filter}
+	}
+	testtable := []struct {
+		tname          string
+		spec           v1.VfGroup
+		lastApplied    v1.VfGroup
+		expectedResult bool
+	}{
+		{
+			tname:          "no filters",
+			spec:           vfGroup(),
+			lastApplied:    vfGroup(),
+			expectedResult: false,
+		},
+		{
+			tname:          "same filters",
+			spec:           vfGroup(v1.VLANFilterEntry{VID: 100, EgressUntagged: true}),
+			lastApplied:    vfGroup(v1.VLANFilterEntry{VID: 100, EgressUntagged: true}),
+			expectedResult: false,
+		},
+		{
+			tname:          "filter added",
+			spec:           vfGroup(v1.VLANFilterEntry{VID: 100}, v1.VLANFilterEntry{VID: 200}),
+			lastApplied:    vfGroup(v1.VLANFilterEntry{VID: 100}),
+			expectedResult: true,
+		},
+		{
+			tname:          "filter flags changed",
+			spec:           vfGroup(v1.VLANFilterEntry{VID: 100, Ingress: true}),
+			lastApplied:    vfGroup(v1.VLANFilterEntry{VID: 100}),
+			expectedResult: true,
+		},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			spec := &v1.Interface{NumVfs: 4, VfGroups: []v1.VfGroup{tc.spec}}
+			lastApplied := &v1.Interface{NumVfs: 4, VfGroups: []v1.VfGroup{tc.lastApplied}}
+			result := v1.NeedToUpdateVfBridgeVLAN(spec, lastApplied)
+			if result != tc.expectedResult {
+				t.Errorf("unexpected result want: %t got: %t", tc.expectedResult, result)
+			}
+			// VLAN filters are configured on the bridge ports without draining the node
+			status := &v1.InterfaceExt{NumVfs: 4, VFs: []v1.VirtualFunction{{VfID: 0, Driver: "iavf"}}}
+			if v1.NeedToDrainForSriovUpdate(spec, status) {
+				t.Errorf("unexpected drain required for VLAN filter change")
+			}
+		})
+	}
+}
+
+func TestNeedToUpdateVfQinQ(t *testing.T) {
+	vlan := func(vid uint16) *uint16 { return &vid }
+	vfGroup := func(outer, inner *uint16) v1.VfGroup {
+		return v1.VfGroup{PolicyName: "p1", VfRange: "0-3", DeviceType: consts.DeviceTypeNetDevice, OuterVLAN: outer, InnerVLAN: inner}
+	}
+	testtable := []struct {
+		tname          string
+		spec           v1.VfGroup
+		lastApplied    v1.VfGroup
+		expectedResult bool
+	}{
+		{
+			tname:          "not set",
+			spec:           vfGroup(nil, nil),
+			lastApplied:    vfGroup(nil, nil),
+			expectedResult: false,
+		},
+		{
+			tname:          "same VLANs",
+			spec:           vfGroup(vlan(100), vlan(10)),
+			lastApplied:    vfGroup(vlan(100), vlan(10)),
+			expectedResult: false,
+		},
+		{
+			tname:          "double VLAN added",
+			spec:           vfGroup(vlan(100), vlan(10)),
+			lastApplied:    vfGroup(nil, nil),
+			expectedResult: true,
+		},
+		{
+			tname:          "inner VLAN changed",
+			spec:           vfGroup(vlan(100), vlan(20)),
+			lastApplied:    vfGroup(vlan(100), vlan(10)),
+			expectedResult: true,
+		},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			spec := &v1.Interface{NumVfs: 4, VfGroups: []v1.VfGroup{tc.spec}}
+			lastApplied := &v1.Interface{NumVfs: 4, VfGroups: []v1.VfGroup{tc.lastApplied}}
+			result := v1.NeedToUpdateVfQinQ(spec, lastApplied)
+			if result != tc.expectedResult {
+				t.Errorf("unexpected result want: %t got: %t", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestNeedToUpdateVfRSS(t *testing.T) {
+	key := "6d5a56da255b0ec24167253d43a38fb0d0ca2bcbae7b30b477cb2da38030f20c6a42b73bbeac01fa"
+	vfGroup := func(hashKey *string, fields []string) v1.VfGroup {
+		return v1.VfGroup{PolicyName: "p1", VfRange: "0-3", DeviceType: consts.DeviceTypeNetDevice, RSSHashKey: hashKey, RSSHashFields: fields}
+	}
+	testtable := []struct {
+		tname          string
+		spec           v1.VfGroup
+		lastApplied    v1.VfGroup
+		expectedResult bool
+	}{
+		{
+			tname:          "not set",
+			spec:           vfGroup(nil, nil),
+			lastApplied:    vfGroup(nil, nil),
+			expectedResult: false,
+		},
+		{
+			tname:          "same configuration",
+			spec:           vfGroup(&key, []string{"ipv4-tcp"}),
+			lastApplied:    vfGroup(&key, []string{"ipv4-tcp"}),
+			expectedResult: false,
+		},
+		{
+			tname:          "hash key added",
+			spec:           vfGroup(&key, nil),
+			lastApplied:    vfGroup(nil, nil),
+			expectedResult: true,
+		},
+		{
+			tname:          "hash fields changed",
+			spec:           vfGroup(&key, []string{"ipv4-tcp", "ipv4-udp"}),
+			lastApplied:    vfGroup(&key, []string{"ipv4-tcp"}),
+			expectedResult: true,
+		},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			spec := &v1.Interface{NumVfs: 4, VfGroups: []v1.VfGroup{tc.spec}}
+			lastApplied := &v1.Interface{NumVfs: 4, VfGroups: []v1.VfGroup{tc.lastApplied}}
+			result := v1.NeedToUpdateVfRSS(spec, lastApplied)
+			if result != tc.expectedResult {
+				t.Errorf("unexpected result want: %t got: %t", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestNeedToUpdateVfRepresentors(t *testing.T) {
+	vf := func(mtu int, state string) v1.VirtualFunction {
+		return v1.VirtualFunction{VfID: 0, RepresentorName: "enp216s0f0np0_0", RepresentorMtu: mtu, RepresentorLinkAdminState: state}
+	}
+	testtable := []struct {
+		tname          string
+		eswitchMode    string
+		groupMtu       int
+		vf             v1.VirtualFunction
+		expectedResult bool
+	}{
+		{
+			tname:          "representor configured",
+			eswitchMode:    v1.ESwithModeSwitchDev,
+			groupMtu:       9000,
+			vf:             vf(9000, consts.LinkAdminStateUp),
+			expectedResult: false,
+		},
+		{
+			tname:          "representor MTU differs",
+			eswitchMode:    v1.ESwithModeSwitchDev,
+			groupMtu:       9000,
+			vf:             vf(1500, consts.LinkAdminStateUp),
+			expectedResult: true,
+		},
+		{
+			tname:          "representor down",
+			eswitchMode:    v1.ESwithModeSwitchDev,
+			vf:             vf(1500, consts.LinkAdminStateDown),
+			expectedResult: true,
+		},
+		{
+			tname:          "no representor",
+			eswitchMode:    v1.ESwithModeSwitchDev,
+			groupMtu:       9000,
+			vf:             v1.VirtualFunction{VfID: 0},
+			expectedResult: false,
+		},
+		{
+			tname:          "legacy mode",
+			eswitchMode:    v1.ESwithModeLegacy,
+			groupMtu:       9000,
+			vf:             vf(1500, consts.LinkAdminStateDown),
+			expectedResult: false,
+		},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			spec := &v1.Interface{NumVfs: 1, EswitchMode: tc.eswitchMode,
+				VfGroups: []v1.VfGroup{{VfRange: "0-0", Mtu: tc.groupMtu}}}
+			status := &v1.InterfaceExt{NumVfs: 1, EswitchMode: tc.eswitchMode, VFs: []v1.VirtualFunction{tc.vf}}
+			result := v1.NeedToUpdateVfRepresentors(spec, status)
+			if result != tc.expectedResult {
+				t.Errorf("unexpected result want: %t got: %t", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestSortVfGroupsByPolicy(t *testing.T) {
+	groups := []v1.VfGroup{
+		{ResourceName: "resB", VfRange: "4-7"},
+		{ResourceName: "resC", VfRange: "0-3"},
+		{ResourceName: "resA", VfRange: "8-9"},
+	}
+	testtable := []struct {
+		tname          string
+		policy         string
+		expectedGroups []v1.VfGroup
+	}{
+		{
+			tname:          "first-fit keeps the order",
+			policy:         v1.VfGroupSortPolicyFirstFit,
+			expectedGroups: groups,
+		},
+		{
+			tname:          "empty policy keeps the order",
+			policy:         "",
+			expectedGroups: groups,
+		},
+		{
+			tname:  "pci-order",
+			policy: v1.VfGroupSortPolicyPciOrder,
+			expectedGroups: []v1.VfGroup{
+				{ResourceName: "resC", VfRange: "0-3"},
+				{ResourceName: "resB", VfRange: "4-7"},
+				{ResourceName: "resA", VfRange: "8-9"},
+			},
+		},
+		{
+			tname:  "resource-name",
+			policy: v1.VfGroupSortPolicyResourceName,
+			expectedGroups: []v1.VfGroup{
+				{ResourceName: "resA", VfRange: "8-9"},
+				{ResourceName: "resB", VfRange: "4-7"},
+				{ResourceName: "resC", VfRange: "0-3"},
+			},
+		},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			result := v1.SortVfGroupsByPolicy(groups, tc.policy)
+			if !cmp.Equal(tc.expectedGroups, result) {
+				t.Errorf("unexpected result: %s", cmp.Diff(tc.expectedGroups, result))
+			}
+			if groups[0].ResourceName != "resB" {
+				t.Errorf("input groups were modified")
+			}
+		})
+	}
+}
+
+func TestNormalizeInterfaceSpec(t *testing.T) {
+	ifaces := v1.Interfaces{
+		{
+			PciAddress: "0000:86:00.1",
+			LinkType:   "ETH",
+			VfGroups: []v1.VfGroup{
+				{ResourceName: "resB", DeviceType: "Netdevice", VfRange: "4-7", RSSHashFields: []string{"ipv6-tcp", "ipv4-tcp"}},
+				{ResourceName: "resA", DeviceType: "vfio-pci", VfRange: "0-3",
+					VLANFilter: []v1.VLANFilterEntry{{VID: 200}, {VID: 100, Ingress: true}}},
+			},
+		},
+		{PciAddress: "0000:86:00.0", NumVfs: 2},
+	}
+	expected := v1.Interfaces{
+		{PciAddress: "0000:86:00.0", NumVfs: 2},
+		{
+			PciAddress: "0000:86:00.1",
+			LinkType:   "eth",
+			VfGroups: []v1.VfGroup{
+				{ResourceName: "resA", DeviceType: "vfio-pci", VfRange: "0-3",
+					VLANFilter: []v1.VLANFilterEntry{{VID: 100, Ingress: true}, {VID: 200}}},
+				{ResourceName: "resB", DeviceType: "netdevice", VfRange: "4-7", RSSHashFields: []string{"ipv4-tcp", "ipv6-tcp"}},
+			},
+		},
+	}
+	result := v1.NormalizeInterfaceSpec(ifaces)
+	if !cmp.Equal(expected, result) {
+		t.Errorf("unexpected result: %s", cmp.Diff(expected, result))
+	}
+	if ifaces[0].PciAddress != "0000:86:00.1" || ifaces[0].VfGroups[0].ResourceName != "resB" ||
+		ifaces[0].VfGroups[0].RSSHashFields[0] != "ipv6-tcp" {
+		t.Errorf("input interfaces were modified")
+	}
+
+	reordered := v1.Interfaces{ifaces[1], ifaces[0]}
+	reordered[1].VfGroups = []v1.VfGroup{ifaces[0].VfGroups[1], ifaces[0].VfGroups[0]}
+	if !cmp.Equal(v1.NormalizeInterfaceSpec(reordered), result) {
+		t.Errorf("semantically equivalent interfaces are not equal after normalization")
+	}
+}
+
+func TestNeedToUpdateDevlinkParams(t *testing.T) {
+	testtable := []struct {
+		tname          string
+		spec           map[string]string
+		lastApplied    map[string]string
+		expectedResult bool
+	}{
+		{
+			tname:          "no parameters",
+			spec:           nil,
+			lastApplied:    map[string]string{},
+			expectedResult: false,
+		},
+		{
+			tname:          "same parameters",
+			spec:           map[string]string{"flow_steering_mode": "dmfs"},
+			lastApplied:    map[string]string{"flow_steering_mode": "dmfs"},
+			expectedResult: false,
+		},
+		{
+			tname:          "parameter changed",
+			spec:           map[string]string{"flow_steering_mode": "dmfs"},
+			lastApplied:    map[string]string{"flow_steering_mode": "smfs"},
+			expectedResult: true,
+		},
+		{
+			tname:          "parameter removed",
+			spec:           nil,
+			lastApplied:    map[string]string{"flow_steering_mode": "dmfs"},
+			expectedResult: true,
+		},
+	}
+	for _, tc := range testtable {
+		t.Run(tc.tname, func(t *testing.T) {
+			result := v1.NeedToUpdateDevlinkParams(&v1.Interface{DevlinkParams: tc.spec}, &v1.Interface{DevlinkParams: tc.lastApplied})
+			if result != tc.expectedResult {
+				t.Errorf("unexpected result want: %t got: %t", tc.expectedResult, result)
+			}
+		})
+	}
+}
@@ -34,8 +34,12 @@ type SriovNetworkNodePolicySpec struct {
 	// Priority of the policy, higher priority policies can override lower ones.
 	Priority int `json:"priority,omitempty"`
 	// +kubebuilder:validation:Minimum=1
-	// MTU of VF
+	// MTU of PF, also applied to the VFs when vfMtu is not set
 	Mtu int `json:"mtu,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// MTU of the VFs allocated by the policy, allows to use VF MTU lower than the PF MTU.
+	// Must not exceed the PF MTU. Defaults to mtu.
+	VfMtu int `json:"vfMtu,omitempty"`
 	// +kubebuilder:validation:Minimum=0
 	// Number of VFs for each PF
 	NumVfs int `json:"numVfs"`
@@ -47,6 +51,10 @@ type SriovNetworkNodePolicySpec struct {
 	DeviceType string `json:"deviceType,omitempty"`
 	// RDMA mode. Defaults to false.
 	IsRdma bool `json:"isRdma,omitempty"`
+	// +kubebuilder:validation:Enum=shared;exclusive
+	// RDMA subsystem network namespace mode of the selected nodes. Allowed value "shared", "exclusive".
+	// Requires isRdma, changing the mode drains the node.
+	RdmaMode string `json:"rdmaMode,omitempty"`
 	// mount vhost-net device. Defaults to false.
 	NeedVhostNet bool `json:"needVhostNet,omitempty"`
 	// +kubebuilder:validation:Enum=eth;ETH;ib;IB
@@ -55,6 +63,10 @@ type SriovNetworkNodePolicySpec struct {
 	// +kubebuilder:validation:Enum=legacy;switchdev
 	// NIC Device Mode. Allowed value "legacy","switchdev".
 	EswitchMode string `json:"eSwitchMode,omitempty"`
+	// +kubebuilder:validation:Enum=none;basic
+	// Encapsulation mode of the eSwitch, "basic" enables the offload of the tunnels. Allowed value "none", "basic".
+	// Valid only for eSwitchMode==switchdev, the encapsulation mode of the device is not changed if not set.
+	EswitchEncapMode string `json:"eSwitchEncapMode,omitempty"`
 	// +kubebuilder:validation:Enum=virtio;vhost
 	// VDPA device type. Allowed value "virtio", "vhost"
 	VdpaType string `json:"vdpaType,omitempty"`
@@ -62,11 +74,135 @@ type SriovNetworkNodePolicySpec struct {
 	ExcludeTopology bool `json:"excludeTopology,omitempty"`
 	// don't create the virtual function only allocated them to the device plugin. Defaults to false.
 	ExternallyManaged bool `json:"externallyManaged,omitempty"`
+	// Keep kernel drivers off the VFs across reboots by making vfio-pci claim the VF device ID early in the boot
+	// via modprobe configuration. Affects all the devices with the same device ID on the node.
+	// Valid only for deviceType vfio-pci. Defaults to false.
+	BlacklistKernelDriver bool `json:"blacklistKernelDriver,omitempty"`
+	// +kubebuilder:validation:Enum=pci-order;resource-name;first-fit
+	// The order in which VF groups on the same PF are configured. Allowed value "pci-order", "resource-name", "first-fit". Defaults to "first-fit".
+	VfGroupSortPolicy string `json:"vfGroupSortPolicy,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=63
+	// DSCP value to mark the IP traffic sent by the VFs with, valid only for deviceType netdevice
+	DSCP *int32 `json:"dscp,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// Egress bandwidth limit in Mbps of each VF, valid only for deviceType netdevice
+	EgressBandwidthMbps *int32 `json:"egressBandwidthMbps,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// Guaranteed minimum transmit rate in Mbps of each VF, the sum of the rates of all VFs can't exceed the PF link speed
+	MinTxRate int `json:"minTxRate,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// Maximum transmit rate in Mbps of each VF, 0 means unlimited
+	MaxTxRate int `json:"maxTxRate,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4095
+	// VLAN ID programmed on the VFs through the PF, valid only for deviceType vfio-pci
+	Vlan int `json:"vlan,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=7
+	// VLAN QoS programmed on the VFs with the VLAN ID
+	VlanQoS int `json:"vlanQoS,omitempty"`
+	// +kubebuilder:validation:Enum={"802.1q","802.1Q","802.1ad","802.1AD"}
+	// VLAN protocol programmed on the VFs with the VLAN ID. Defaults to 802.1q.
+	VlanProto string `json:"vlanProto,omitempty"`
+	// +kubebuilder:validation:Enum={"on","off"}
+	// VF trust mode (on|off), the setting is also used by the SriovNetworks of the resource which don't set it
+	Trust string `json:"trust,omitempty"`
+	// +kubebuilder:validation:Enum={"on","off"}
+	// VF spoof check (on|off), the setting is also used by the SriovNetworks of the resource which don't set it
+	SpoofChk string `json:"spoofChk,omitempty"`
+	// VLANs allowed on the bridge port of each VF, the port is the VF representor in switchdev mode
+	// and the VF netdevice otherwise. Valid only for deviceType netdevice.
+	VLANFilter []VLANFilterEntry `json:"vlanFilter,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	// outer VLAN (S-TAG) of the 802.1ad double VLAN of the VFs, programmed on the VFs through the PF.
+	// Requires innerVlan, valid only for deviceType netdevice.
+	OuterVLAN *uint16 `json:"outerVlan,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	// inner VLAN (C-TAG) of the 802.1ad double VLAN of the VFs, used by the workloads on the VF netdevices.
+	// Requires outerVlan.
+	InnerVLAN *uint16 `json:"innerVlan,omitempty"`
+	// RSS hash key of the VF netdevices as a hex string of 40 or 52 bytes, the bytes can be separated by colons.
+	// Valid only for deviceType netdevice.
+	RSSHashKey *string `json:"rssHashKey,omitempty"`
+	// +kubebuilder:validation:items:Enum=ipv4-tcp;ipv4-udp;ipv4-sctp;ipv6-tcp;ipv6-udp;ipv6-sctp
+	// flows which the RSS of the VF netdevices hashes on the L4 ports in addition to the IP addresses,
+	// the other flows are hashed on the IP addresses only. Valid only for deviceType netdevice.
+	RSSHashFields []string `json:"rssHashFields,omitempty"`
+	// hugepages to allocate at runtime for the workloads which use the VFs of matching PFs
+	Hugepages *Hugepages `json:"hugepages,omitempty"`
+	// don't manage the administrative link state of matching PFs. By default the PF is brought up before VFs are created
+	// and its initial link state is restored when the PF is reset. Defaults to false.
+	DisablePfLinkManagement bool `json:"disablePfLinkManagement,omitempty"`
+	// enable or disable the VXLAN encapsulation segmentation offload of matching PFs, the offload is not changed if not set
+	VxlanOffload *bool `json:"vxlanOffload,omitempty"`
+	// enable or disable the Geneve encapsulation segmentation offload of matching PFs, the offload is not changed if not set
+	GeneveOffload *bool `json:"geneveOffload,omitempty"`
+	// devlink parameters of matching PFs by name (e.g. flow_steering_mode: dmfs), the values are set before the VFs are created.
+	// Parameters set in the permanent configuration mode are applied by the firmware after a reboot of the node.
+	DevlinkParams map[string]string `json:"devlinkParams,omitempty"`
+	// configure the traffic classes of the VF netdevices of matching PFs with the ETS configuration received from the switch
+	// via LLDP/DCBX, requires lldpad running on the host. Defaults to false.
+	DCBXAutoConfig bool `json:"dcbxAutoConfig,omitempty"`
+	// allow the configuration of matching PFs enslaved to a bond, the bond must tolerate the changes of the PFs,
+	// for mlx5 VF-LAG all the ports of the bond must be selected with the same eSwitchMode. Defaults to false.
+	AllowBondedPF bool `json:"allowBondedPF,omitempty"`
+	// CPUs which handle the interrupts of the VFs of matching PFs in the cpuset list format, e.g. "0-3,8-11".
+	// The interrupt affinity of the VFs is not changed if not set.
+	IRQAffinity *CPUSet `json:"irqAffinity,omitempty"`
+	// configure the VFs of matching PFs for the hardware offload of Open vSwitch with DPDK: the VF representors
+	// of representorMapping are added as DPDK ports to the OVS bridge of the PF. Valid only for eSwitchMode==switchdev.
+	OVSDPDKOffload bool `json:"ovsDpdkOffload,omitempty"`
+	// name of the OVS port of the representor of each VF by VF index, used with ovsDpdkOffload
+	RepresentorMapping map[int]string `json:"representorMapping,omitempty"`
+	// settings of matching PFs required by the workloads of the VFs, e.g. the promiscuous mode for multicast-heavy
+	// workloads. The settings are restored to their original values when no policy requests them anymore.
+	PfSettings *PfSettings `json:"pfSettings,omitempty"`
 	// contains bridge configuration for matching PFs,
 	// valid only for eSwitchMode==switchdev
 	Bridge Bridge `json:"bridge,omitempty"`
 }
 
+// VLANFilterEntry contains a VLAN added to the VLAN filter of the bridge port of a VF
+type VLANFilterEntry struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	// VLAN ID
+	VID uint16 `json:"vid"`
+	// send the frames of the VLAN untagged to the VF
+	EgressUntagged bool `json:"egressUntagged,omitempty"`
+	// assign the untagged frames received from the VF to the VLAN (PVID)
+	Ingress bool `json:"ingress,omitempty"`
+}
+
+// Hugepages contains runtime hugepages allocation request
+type Hugepages struct {
+	// +kubebuilder:validation:Enum=2Mi;1Gi
+	// Size of the hugepages. Allowed value "2Mi", "1Gi".
+	Size string `json:"size"`
+	// +kubebuilder:validation:Minimum=0
+	// Number of hugepages to allocate
+	Count int `json:"count"`
+	// Allocate the hugepages on the NUMA node of the NIC, the hugepages are distributed by the kernel otherwise. Defaults to false.
+	SameNUMAAsNic bool `json:"sameNumaAsNic,omitempty"`
+}
+
+// PfSettings contains the settings of a PF required by the workloads of its VFs, the settings which are not set are not changed
+type PfSettings struct {
+	// enable or disable the promiscuous mode of the PF
+	Promisc *bool `json:"promisc,omitempty"`
+	// enable or disable the reception of all the multicast packets by the PF
+	AllMulticast *bool `json:"allMulticast,omitempty"`
+	// ethtool features of the PF by name, only rx-ntuple-filter, rx-vlan-filter, rx-all, rx-fcs and rx-hashing are accepted
+	Features map[string]bool `json:"features,omitempty"`
+}
+
+// CPUSet is a set of CPUs in the cpuset list format, e.g. "0-3,8-11"
+// +kubebuilder:validation:Pattern=`^[0-9]+(-[0-9]+)?(,[0-9]+(-[0-9]+)?)*$`
+type CPUSet string
+
 type SriovNetworkNicSelector struct {
 	// The vendor hex code of SR-IoV device. Allowed value "8086", "15b3".
 	Vendor string `json:"vendor,omitempty"`
@@ -78,6 +214,9 @@ type SriovNetworkNicSelector struct {
 	PfNames []string `json:"pfNames,omitempty"`
 	// Infrastructure Networking selection filter. Allowed value "openstack/NetworkID:xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
 	NetFilter string `json:"netFilter,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// NUMA node of SR-IoV PF, only the PFs on the NUMA node are selected. Can't be used as the only selector.
+	NumaNode *int `json:"numaNode,omitempty"`
 }
 
 // contains spec for the bridge
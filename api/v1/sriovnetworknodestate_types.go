@@ -27,48 +27,111 @@ import (
 type SriovNetworkNodeStateSpec struct {
 	Interfaces Interfaces `json:"interfaces,omitempty"`
 	Bridges    Bridges    `json:"bridges,omitempty"`
+	System     System     `json:"system,omitempty"`
+}
+
+// System contains the node-wide configuration
+type System struct {
+	// +kubebuilder:validation:Enum=shared;exclusive
+	// RdmaMode is the network namespace mode of the RDMA subsystem
+	RdmaMode string `json:"rdmaMode,omitempty"`
 }
 
 type Interfaces []Interface
 
 type Interface struct {
-	PciAddress        string    `json:"pciAddress"`
-	NumVfs            int       `json:"numVfs,omitempty"`
-	Mtu               int       `json:"mtu,omitempty"`
-	Name              string    `json:"name,omitempty"`
-	LinkType          string    `json:"linkType,omitempty"`
-	EswitchMode       string    `json:"eSwitchMode,omitempty"`
-	VfGroups          []VfGroup `json:"vfGroups,omitempty"`
-	ExternallyManaged bool      `json:"externallyManaged,omitempty"`
+	PciAddress        string     `json:"pciAddress"`
+	NumVfs            int        `json:"numVfs,omitempty"`
+	Mtu               int        `json:"mtu,omitempty"`
+	Name              string     `json:"name,omitempty"`
+	LinkType          string     `json:"linkType,omitempty"`
+	EswitchMode       string     `json:"eSwitchMode,omitempty"`
+	VfGroups          []VfGroup  `json:"vfGroups,omitempty"`
+	VfGroupSortPolicy string     `json:"vfGroupSortPolicy,omitempty"`
+	ExternallyManaged bool       `json:"externallyManaged,omitempty"`
+	Hugepages         *Hugepages `json:"hugepages,omitempty"`
+	// DisablePfLinkManagement disables management of the PF administrative link state
+	DisablePfLinkManagement bool `json:"disablePfLinkManagement,omitempty"`
+	// VxlanOffload sets the VXLAN segmentation offload of the PF, not changed if nil
+	VxlanOffload *bool `json:"vxlanOffload,omitempty"`
+	// GeneveOffload sets the Geneve segmentation offload of the PF, not changed if nil
+	GeneveOffload *bool `json:"geneveOffload,omitempty"`
+	// NumaNode is the NUMA node requested for the PF by the policies, the configuration of the PF fails
+	// if it is on another NUMA node
+	NumaNode *int `json:"numaNode,omitempty"`
+	// DevlinkParams contains the devlink parameters of the PF by name
+	DevlinkParams map[string]string `json:"devlinkParams,omitempty"`
+	// DCBXAutoConfig configures the traffic classes of the VFs with the ETS configuration received from the switch
+	DCBXAutoConfig bool `json:"dcbxAutoConfig,omitempty"`
+	// AllowBondedPF allows the configuration of the PF when it is enslaved to a bond
+	AllowBondedPF bool `json:"allowBondedPF,omitempty"`
+	// IRQAffinity contains the CPUs which handle the interrupts of the VFs, not changed if nil
+	IRQAffinity *CPUSet `json:"irqAffinity,omitempty"`
+	// EswitchEncapMode is the encapsulation mode of the eSwitch in switchdev mode, not changed if empty
+	EswitchEncapMode string `json:"eSwitchEncapMode,omitempty"`
+	// OVSDPDKOffload configures the VF representors for the hardware offload of OVS with DPDK
+	OVSDPDKOffload bool `json:"ovsDpdkOffload,omitempty"`
+	// RepresentorMapping contains the name of the OVS port of the representor of each VF by VF index
+	RepresentorMapping map[int]string `json:"representorMapping,omitempty"`
+	// PfSettings contains the promiscuous mode, the all-multicast mode and the ethtool features of the PF,
+	// the settings which are not set are not changed
+	PfSettings *PfSettings `json:"pfSettings,omitempty"`
 }
 
 type VfGroup struct {
-	ResourceName string `json:"resourceName,omitempty"`
-	DeviceType   string `json:"deviceType,omitempty"`
-	VfRange      string `json:"vfRange,omitempty"`
-	PolicyName   string `json:"policyName,omitempty"`
-	Mtu          int    `json:"mtu,omitempty"`
-	IsRdma       bool   `json:"isRdma,omitempty"`
-	VdpaType     string `json:"vdpaType,omitempty"`
+	ResourceName          string `json:"resourceName,omitempty"`
+	DeviceType            string `json:"deviceType,omitempty"`
+	VfRange               string `json:"vfRange,omitempty"`
+	PolicyName            string `json:"policyName,omitempty"`
+	Mtu                   int    `json:"mtu,omitempty"`
+	IsRdma                bool   `json:"isRdma,omitempty"`
+	VdpaType              string `json:"vdpaType,omitempty"`
+	BlacklistKernelDriver bool   `json:"blacklistKernelDriver,omitempty"`
+	NeedVhostNet          bool   `json:"needVhostNet,omitempty"`
+	DSCP                  *int32 `json:"dscp,omitempty"`
+	EgressBandwidthMbps   *int32 `json:"egressBandwidthMbps,omitempty"`
+	Trust                 string `json:"trust,omitempty"`
+	SpoofChk              string `json:"spoofChk,omitempty"`
+	MinTxRate             int    `json:"minTxRate,omitempty"`
+	MaxTxRate             int    `json:"maxTxRate,omitempty"`
+	Vlan                  int    `json:"vlan,omitempty"`
+	VlanQoS               int    `json:"vlanQoS,omitempty"`
+	VlanProto             string `json:"vlanProto,omitempty"`
+	// VLANFilter contains the VLANs added to the bridge port of each VF
+	VLANFilter []VLANFilterEntry `json:"vlanFilter,omitempty"`
+	// OuterVLAN and InnerVLAN are the VLANs of the 802.1ad double VLAN of each VF
+	OuterVLAN *uint16 `json:"outerVlan,omitempty"`
+	InnerVLAN *uint16 `json:"innerVlan,omitempty"`
+	// RSSHashKey and RSSHashFields are the RSS configuration of each VF netdevice
+	RSSHashKey    *string  `json:"rssHashKey,omitempty"`
+	RSSHashFields []string `json:"rssHashFields,omitempty"`
 }
 
 type InterfaceExt struct {
-	Name              string            `json:"name,omitempty"`
-	Mac               string            `json:"mac,omitempty"`
-	Driver            string            `json:"driver,omitempty"`
-	PciAddress        string            `json:"pciAddress"`
-	Vendor            string            `json:"vendor,omitempty"`
-	DeviceID          string            `json:"deviceID,omitempty"`
-	NetFilter         string            `json:"netFilter,omitempty"`
-	Mtu               int               `json:"mtu,omitempty"`
-	NumVfs            int               `json:"numVfs,omitempty"`
-	LinkSpeed         string            `json:"linkSpeed,omitempty"`
-	LinkType          string            `json:"linkType,omitempty"`
-	LinkAdminState    string            `json:"linkAdminState,omitempty"`
-	EswitchMode       string            `json:"eSwitchMode,omitempty"`
-	ExternallyManaged bool              `json:"externallyManaged,omitempty"`
-	TotalVfs          int               `json:"totalvfs,omitempty"`
-	VFs               []VirtualFunction `json:"Vfs,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Mac             string `json:"mac,omitempty"`
+	Driver          string `json:"driver,omitempty"`
+	PciAddress      string `json:"pciAddress"`
+	Vendor          string `json:"vendor,omitempty"`
+	DeviceID        string `json:"deviceID,omitempty"`
+	NetFilter       string `json:"netFilter,omitempty"`
+	Mtu             int    `json:"mtu,omitempty"`
+	NumVfs          int    `json:"numVfs,omitempty"`
+	LinkSpeed       string `json:"linkSpeed,omitempty"`
+	LinkType        string `json:"linkType,omitempty"`
+	LinkAdminState  string `json:"linkAdminState,omitempty"`
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
+	// BondMaster is the name of the bond the PF is enslaved to
+	BondMaster        string `json:"bondMaster,omitempty"`
+	EswitchMode       string `json:"eSwitchMode,omitempty"`
+	EswitchEncapMode  string `json:"eSwitchEncapMode,omitempty"`
+	ExternallyManaged bool   `json:"externallyManaged,omitempty"`
+	TotalVfs          int    `json:"totalvfs,omitempty"`
+	// SiblingPFs are the PCI addresses of the other PFs of the same device which share its firmware VF resources,
+	// the VFs requested for the PF and its siblings must fit in the VFs of the device
+	SiblingPFs []string          `json:"siblingPfs,omitempty"`
+	NumaNode   *int              `json:"numaNode,omitempty"`
+	VFs        []VirtualFunction `json:"Vfs,omitempty"`
 }
 type InterfaceExts []InterfaceExt
 
@@ -84,8 +147,22 @@ type VirtualFunction struct {
 	Mtu             int    `json:"mtu,omitempty"`
 	VfID            int    `json:"vfID"`
 	VdpaType        string `json:"vdpaType,omitempty"`
+	VdpaDevice      string `json:"vdpaDevice,omitempty"`
 	RepresentorName string `json:"representorName,omitempty"`
-	GUID            string `json:"guid,omitempty"`
+	// RepresentorMtu is the MTU of the representor of the VF in switchdev mode
+	RepresentorMtu int `json:"representorMtu,omitempty"`
+	// RepresentorLinkAdminState is the admin state of the representor of the VF in switchdev mode
+	RepresentorLinkAdminState string `json:"representorLinkAdminState,omitempty"`
+	GUID                      string `json:"guid,omitempty"`
+	MinTxRate                 int    `json:"minTxRate,omitempty"`
+	MaxTxRate                 int    `json:"maxTxRate,omitempty"`
+	VlanQoS                   int    `json:"vlanQoS,omitempty"`
+	VlanProto                 string `json:"vlanProto,omitempty"`
+	Trust                     string `json:"trust,omitempty"`
+	SpoofChk                  string `json:"spoofChk,omitempty"`
+	NumaNode                  *int   `json:"numaNode,omitempty"`
+	// IOMMUGroup is the IOMMU group of the VF bound to vfio-pci
+	IOMMUGroup *int `json:"iommuGroup,omitempty"`
 }
 
 // Bridges contains list of bridges
@@ -114,12 +191,65 @@ type OVSUplinkConfigExt struct {
 	Interface OVSInterfaceConfig `json:"interface,omitempty"`
 }
 
+// InterfaceSyncStatus contains the result of the last configuration of the PF
+type InterfaceSyncStatus struct {
+	// pci address of the PF
+	PciAddress string `json:"pciAddress"`
+	// LastSyncTime is the time when the state of the PF configuration changed
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+	// State of the PF configuration: Succeeded, Failed or InProgress
+	State string `json:"state,omitempty"`
+	// Message contains the error of the failed PF configuration
+	Message string `json:"message,omitempty"`
+	// DevlinkParamErrors contains the errors of the devlink parameters of the PF which failed to be set by name
+	DevlinkParamErrors map[string]string `json:"devlinkParamErrors,omitempty"`
+	// NumVfsShortfall is the number of requested VFs the device failed to create, the created VFs are configured
+	NumVfsShortfall int `json:"numVfsShortfall,omitempty"`
+	// Warnings contains the problems of the PF configuration which don't fail the sync,
+	// e.g. VFs bound to vfio-pci whose IOMMU group can't be used by the workloads
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type InterfaceSyncStatuses []InterfaceSyncStatus
+
 // SriovNetworkNodeStateStatus defines the observed state of SriovNetworkNodeState
 type SriovNetworkNodeStateStatus struct {
 	Interfaces    InterfaceExts `json:"interfaces,omitempty"`
 	Bridges       Bridges       `json:"bridges,omitempty"`
 	SyncStatus    string        `json:"syncStatus,omitempty"`
 	LastSyncError string        `json:"lastSyncError,omitempty"`
+	HostFacts     *HostFacts    `json:"hostFacts,omitempty"`
+	System        System        `json:"system,omitempty"`
+	// InterfaceSyncStatuses contains the result of the last configuration of each PF in the spec,
+	// SyncStatus is derived from these entries
+	InterfaceSyncStatuses InterfaceSyncStatuses `json:"interfaceSyncStatuses,omitempty"`
+	// Conditions contains the Ready, Progressing and Degraded conditions of the node configuration
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// condition types of the SriovNetworkNodeState
+const (
+	// NodeStateConditionReady is true when the last configuration of the node succeeded
+	NodeStateConditionReady = "Ready"
+	// NodeStateConditionProgressing is true while the node is configured
+	NodeStateConditionProgressing = "Progressing"
+	// NodeStateConditionDegraded is true when the last configuration of the node failed
+	NodeStateConditionDegraded = "Degraded"
+)
+
+// HostFacts contains hardware facts of the node
+type HostFacts struct {
+	// CPUVendor vendor of the node CPUs, e.g. GenuineIntel, AuthenticAMD
+	CPUVendor string `json:"cpuVendor,omitempty"`
+	// IOMMUEnabled is true if IOMMU is active on the node
+	IOMMUEnabled bool `json:"iommuEnabled,omitempty"`
+	// InterruptRemapping is true if interrupt remapping is enabled on the node
+	InterruptRemapping bool `json:"interruptRemapping,omitempty"`
+	// SecureBoot is true if the node was booted with UEFI Secure Boot enabled
+	SecureBoot bool `json:"secureBoot,omitempty"`
 }
 
 //+kubebuilder:object:root=true
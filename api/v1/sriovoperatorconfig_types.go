@@ -65,6 +65,15 @@ type SriovOperatorConfigSpec struct {
 	DisablePlugins PluginNameSlice `json:"disablePlugins,omitempty"`
 	// FeatureGates to enable experimental features
 	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// VfOvercommitRatio is the maximum ratio of the VFs requested for a PF by all the policies selecting it
+	// to the VFs supported by the PF, e.g. "1.5". Policies exceeding the ratio are rejected and a lower
+	// overcommit is reported as a warning. If not set, the overcommit is only reported as a warning.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	VfOvercommitRatio string `json:"vfOvercommitRatio,omitempty"`
+	// VfAttributeReconcileInterval is the interval at which the config daemon reads the trust, spoof check,
+	// tx rate and VLAN of the VFs and reapplies the ones which differ from the desired state, e.g. after
+	// a reset of the PF driver. The periodic reconciliation is disabled if not set or zero.
+	VfAttributeReconcileInterval *metav1.Duration `json:"vfAttributeReconcileInterval,omitempty"`
 }
 
 // SriovOperatorConfigStatus defines the observed state of SriovOperatorConfig
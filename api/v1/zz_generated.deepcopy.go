@@ -89,13 +89,89 @@ func (in ByPriority) DeepCopy() ByPriority {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostFacts) DeepCopyInto(out *HostFacts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostFacts.
+func (in *HostFacts) DeepCopy() *HostFacts {
+	if in == nil {
+		return nil
+	}
+	out := new(HostFacts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hugepages) DeepCopyInto(out *Hugepages) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hugepages.
+func (in *Hugepages) DeepCopy() *Hugepages {
+	if in == nil {
+		return nil
+	}
+	out := new(Hugepages)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Interface) DeepCopyInto(out *Interface) {
 	*out = *in
 	if in.VfGroups != nil {
 		in, out := &in.VfGroups, &out.VfGroups
 		*out = make([]VfGroup, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hugepages != nil {
+		in, out := &in.Hugepages, &out.Hugepages
+		*out = new(Hugepages)
+		**out = **in
+	}
+	if in.VxlanOffload != nil {
+		in, out := &in.VxlanOffload, &out.VxlanOffload
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GeneveOffload != nil {
+		in, out := &in.GeneveOffload, &out.GeneveOffload
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NumaNode != nil {
+		in, out := &in.NumaNode, &out.NumaNode
+		*out = new(int)
+		**out = **in
+	}
+	if in.DevlinkParams != nil {
+		in, out := &in.DevlinkParams, &out.DevlinkParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IRQAffinity != nil {
+		in, out := &in.IRQAffinity, &out.IRQAffinity
+		*out = new(CPUSet)
+		**out = **in
+	}
+	if in.RepresentorMapping != nil {
+		in, out := &in.RepresentorMapping, &out.RepresentorMapping
+		*out = make(map[int]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PfSettings != nil {
+		in, out := &in.PfSettings, &out.PfSettings
+		*out = new(PfSettings)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -112,10 +188,22 @@ func (in *Interface) DeepCopy() *Interface {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InterfaceExt) DeepCopyInto(out *InterfaceExt) {
 	*out = *in
+	if in.SiblingPFs != nil {
+		in, out := &in.SiblingPFs, &out.SiblingPFs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NumaNode != nil {
+		in, out := &in.NumaNode, &out.NumaNode
+		*out = new(int)
+		**out = **in
+	}
 	if in.VFs != nil {
 		in, out := &in.VFs, &out.VFs
 		*out = make([]VirtualFunction, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -150,6 +238,55 @@ func (in InterfaceExts) DeepCopy() InterfaceExts {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterfaceSyncStatus) DeepCopyInto(out *InterfaceSyncStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.DevlinkParamErrors != nil {
+		in, out := &in.DevlinkParamErrors, &out.DevlinkParamErrors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceSyncStatus.
+func (in *InterfaceSyncStatus) DeepCopy() *InterfaceSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceSyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in InterfaceSyncStatuses) DeepCopyInto(out *InterfaceSyncStatuses) {
+	{
+		in := &in
+		*out = make(InterfaceSyncStatuses, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceSyncStatuses.
+func (in InterfaceSyncStatuses) DeepCopy() InterfaceSyncStatuses {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceSyncStatuses)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Interfaces) DeepCopyInto(out *Interfaces) {
 	{
@@ -423,6 +560,38 @@ func (in *OvsHardwareOffloadConfig) DeepCopy() *OvsHardwareOffloadConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PfSettings) DeepCopyInto(out *PfSettings) {
+	*out = *in
+	if in.Promisc != nil {
+		in, out := &in.Promisc, &out.Promisc
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllMulticast != nil {
+		in, out := &in.AllMulticast, &out.AllMulticast
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PfSettings.
+func (in *PfSettings) DeepCopy() *PfSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(PfSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in PluginNameSlice) DeepCopyInto(out *PluginNameSlice) {
 	{
@@ -603,6 +772,11 @@ func (in *SriovNetworkNicSelector) DeepCopyInto(out *SriovNetworkNicSelector) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NumaNode != nil {
+		in, out := &in.NumaNode, &out.NumaNode
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNicSelector.
@@ -685,6 +859,80 @@ func (in *SriovNetworkNodePolicySpec) DeepCopyInto(out *SriovNetworkNodePolicySp
 		}
 	}
 	in.NicSelector.DeepCopyInto(&out.NicSelector)
+	if in.DSCP != nil {
+		in, out := &in.DSCP, &out.DSCP
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EgressBandwidthMbps != nil {
+		in, out := &in.EgressBandwidthMbps, &out.EgressBandwidthMbps
+		*out = new(int32)
+		**out = **in
+	}
+	if in.VLANFilter != nil {
+		in, out := &in.VLANFilter, &out.VLANFilter
+		*out = make([]VLANFilterEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.OuterVLAN != nil {
+		in, out := &in.OuterVLAN, &out.OuterVLAN
+		*out = new(uint16)
+		**out = **in
+	}
+	if in.InnerVLAN != nil {
+		in, out := &in.InnerVLAN, &out.InnerVLAN
+		*out = new(uint16)
+		**out = **in
+	}
+	if in.RSSHashKey != nil {
+		in, out := &in.RSSHashKey, &out.RSSHashKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.RSSHashFields != nil {
+		in, out := &in.RSSHashFields, &out.RSSHashFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hugepages != nil {
+		in, out := &in.Hugepages, &out.Hugepages
+		*out = new(Hugepages)
+		**out = **in
+	}
+	if in.VxlanOffload != nil {
+		in, out := &in.VxlanOffload, &out.VxlanOffload
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GeneveOffload != nil {
+		in, out := &in.GeneveOffload, &out.GeneveOffload
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DevlinkParams != nil {
+		in, out := &in.DevlinkParams, &out.DevlinkParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IRQAffinity != nil {
+		in, out := &in.IRQAffinity, &out.IRQAffinity
+		*out = new(CPUSet)
+		**out = **in
+	}
+	if in.RepresentorMapping != nil {
+		in, out := &in.RepresentorMapping, &out.RepresentorMapping
+		*out = make(map[int]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PfSettings != nil {
+		in, out := &in.PfSettings, &out.PfSettings
+		*out = new(PfSettings)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Bridge.DeepCopyInto(&out.Bridge)
 }
 
@@ -783,6 +1031,7 @@ func (in *SriovNetworkNodeStateSpec) DeepCopyInto(out *SriovNetworkNodeStateSpec
 		}
 	}
 	in.Bridges.DeepCopyInto(&out.Bridges)
+	out.System = in.System
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeStateSpec.
@@ -806,6 +1055,26 @@ func (in *SriovNetworkNodeStateStatus) DeepCopyInto(out *SriovNetworkNodeStateSt
 		}
 	}
 	in.Bridges.DeepCopyInto(&out.Bridges)
+	if in.HostFacts != nil {
+		in, out := &in.HostFacts, &out.HostFacts
+		*out = new(HostFacts)
+		**out = **in
+	}
+	out.System = in.System
+	if in.InterfaceSyncStatuses != nil {
+		in, out := &in.InterfaceSyncStatuses, &out.InterfaceSyncStatuses
+		*out = make(InterfaceSyncStatuses, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovNetworkNodeStateStatus.
@@ -1039,6 +1308,11 @@ func (in *SriovOperatorConfigSpec) DeepCopyInto(out *SriovOperatorConfigSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.VfAttributeReconcileInterval != nil {
+		in, out := &in.VfAttributeReconcileInterval, &out.VfAttributeReconcileInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovOperatorConfigSpec.
@@ -1066,6 +1340,21 @@ func (in *SriovOperatorConfigStatus) DeepCopy() *SriovOperatorConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *System) DeepCopyInto(out *System) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new System.
+func (in *System) DeepCopy() *System {
+	if in == nil {
+		return nil
+	}
+	out := new(System)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrunkConfig) DeepCopyInto(out *TrunkConfig) {
 	*out = *in
@@ -1096,9 +1385,59 @@ func (in *TrunkConfig) DeepCopy() *TrunkConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VLANFilterEntry) DeepCopyInto(out *VLANFilterEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLANFilterEntry.
+func (in *VLANFilterEntry) DeepCopy() *VLANFilterEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(VLANFilterEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VfGroup) DeepCopyInto(out *VfGroup) {
 	*out = *in
+	if in.DSCP != nil {
+		in, out := &in.DSCP, &out.DSCP
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EgressBandwidthMbps != nil {
+		in, out := &in.EgressBandwidthMbps, &out.EgressBandwidthMbps
+		*out = new(int32)
+		**out = **in
+	}
+	if in.VLANFilter != nil {
+		in, out := &in.VLANFilter, &out.VLANFilter
+		*out = make([]VLANFilterEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.OuterVLAN != nil {
+		in, out := &in.OuterVLAN, &out.OuterVLAN
+		*out = new(uint16)
+		**out = **in
+	}
+	if in.InnerVLAN != nil {
+		in, out := &in.InnerVLAN, &out.InnerVLAN
+		*out = new(uint16)
+		**out = **in
+	}
+	if in.RSSHashKey != nil {
+		in, out := &in.RSSHashKey, &out.RSSHashKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.RSSHashFields != nil {
+		in, out := &in.RSSHashFields, &out.RSSHashFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VfGroup.
@@ -1114,6 +1453,16 @@ func (in *VfGroup) DeepCopy() *VfGroup {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualFunction) DeepCopyInto(out *VirtualFunction) {
 	*out = *in
+	if in.NumaNode != nil {
+		in, out := &in.NumaNode, &out.NumaNode
+		*out = new(int)
+		**out = **in
+	}
+	if in.IOMMUGroup != nil {
+		in, out := &in.IOMMUGroup, &out.IOMMUGroup
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualFunction.
@@ -5,7 +5,18 @@ declare -a kargs=( "$@" )
 ret=0
 args=$(chroot /host/ cat /proc/cmdline)
 
-if chroot /host/ test -f /run/ostree-booted ; then
+# KARGS_BACKEND is set by the config daemon based on the detected host OS,
+# fallback to autodetection if it is not provided
+backend=${KARGS_BACKEND:-}
+if [ -z "$backend" ]; then
+    if chroot /host/ test -f /run/ostree-booted ; then
+        backend="rpm-ostree"
+    else
+        backend="grubby"
+    fi
+fi
+
+if [ "$backend" == "rpm-ostree" ]; then
     for t in "${kargs[@]}";do
         if [[ $args != *${t}* ]];then
             if chroot /host/ rpm-ostree kargs | grep -vq ${t}; then
@@ -14,6 +25,25 @@ if chroot /host/ test -f /run/ostree-booted ; then
             let ret++
         fi
     done
+elif [ "$backend" == "update-grub" ]; then
+    chroot /host/ which update-grub > /dev/null 2>&1
+    # if update-grub is not there, let's tell it
+    if [ $? -ne 0 ]; then
+        exit 127
+    fi
+    updated=0
+    for t in "${kargs[@]}";do
+        if [[ $args != *${t}* ]];then
+            if ! chroot /host/ grep -q "^GRUB_CMDLINE_LINUX=.*${t}" /etc/default/grub; then
+                chroot /host/ sed -i "s/^GRUB_CMDLINE_LINUX=\"\(.*\)\"/GRUB_CMDLINE_LINUX=\"\1 ${t}\"/" /etc/default/grub
+                updated=1
+            fi
+            let ret++
+        fi
+    done
+    if [ $updated -ne 0 ]; then
+        chroot /host/ update-grub > /dev/null 2>&1
+    fi
 else
     chroot /host/ which grubby > /dev/null 2>&1
     # if grubby is not there, let's tell it
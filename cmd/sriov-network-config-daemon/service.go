@@ -121,6 +121,15 @@ func readConf(setupLog logr.Logger) (*systemd.SriovConfig, error) {
 		if _, err := os.Stat(systemd.SriovSystemdConfigPath); !errors.Is(err, os.ErrNotExist) {
 			return nil, fmt.Errorf("failed to read the sriov configuration file in path %s: %v", systemd.SriovSystemdConfigPath, err)
 		}
+		// in daemon mode only the configuration of the PFs which must be ready before the daemon starts is persisted
+		nodeStateSpec, err = systemd.ReadPersistedConfFile()
+		if err == nil {
+			setupLog.Info("configuration file not found, use persisted config", "path", systemd.SriovPersistedConfigPath)
+			return nodeStateSpec, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read the persisted configuration file in path %s: %v", systemd.SriovPersistedConfigPath, err)
+		}
 		setupLog.Info("configuration file not found, use default config")
 		nodeStateSpec = &systemd.SriovConfig{
 			Spec:            sriovv1.SriovNetworkNodeStateSpec{},
@@ -210,9 +219,10 @@ func getPlugin(setupLog logr.Logger, phase string,
 		case PhasePre:
 			configPlugin, err = newGenericPluginFunc(hostHelpers,
 				generic.WithSkipVFConfiguration(),
-				generic.WithSkipBridgeConfiguration())
+				generic.WithSkipBridgeConfiguration(),
+				generic.WithKernelParamGracePeriod(0))
 		case PhasePost:
-			configPlugin, err = newGenericPluginFunc(hostHelpers)
+			configPlugin, err = newGenericPluginFunc(hostHelpers, generic.WithKernelParamGracePeriod(0))
 		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to create generic plugin for %v", err)
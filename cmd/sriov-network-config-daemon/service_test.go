@@ -173,6 +173,30 @@ var _ = Describe("Service", func() {
 			string(getTestResultFileContent("InProgress", "")))
 	})
 
+	It("Pre phase - persisted config of daemon mode", func() {
+		phaseArg = PhasePre
+		testHelpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/etc/sriov-operator"},
+			Files: map[string][]byte{
+				"/etc/sriov-operator/sriov-supported-nics-ids.yaml": []byte(testSriovSupportedNicIDs),
+				"/etc/sriov-operator/sriov-persisted-config.yaml":   getTestSriovInterfaceConfig(0),
+			},
+		})
+		hostHelpers.EXPECT().CheckRDMAEnabled().Return(true, nil)
+		hostHelpers.EXPECT().TryEnableTun().Return()
+		hostHelpers.EXPECT().TryEnableVhostNet().Return()
+		hostHelpers.EXPECT().DiscoverSriovDevices(hostHelpers).Return([]sriovnetworkv1.InterfaceExt{{
+			Name: "enp216s0f0np0",
+		}}, nil)
+		genericPlugin.EXPECT().OnNodeStateChange(newNodeStateContainsDeviceMatcher("enp216s0f0np0")).Return(true, false, nil)
+		genericPlugin.EXPECT().Apply().Return(nil)
+
+		Expect(runServiceCmd(&cobra.Command{}, []string{})).NotTo(HaveOccurred())
+
+		testHelpers.GinkgoAssertFileContentsEquals("/etc/sriov-operator/sriov-interface-result.yaml",
+			string(getTestResultFileContent("InProgress", "")))
+	})
+
 	It("Pre phase - virtual cluster", func() {
 		phaseArg = PhasePre
 		testHelpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
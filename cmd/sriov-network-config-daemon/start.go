@@ -27,6 +27,7 @@ import (
 	"github.com/spf13/cobra"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -44,8 +45,10 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/daemon"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/featuregate"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
 	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -86,8 +89,18 @@ var (
 		systemd               bool
 		disabledPlugins       stringList
 		parallelNicConfig     bool
+		parallelNicWorkers    int
 		manageSoftwareBridges bool
 		ovsSocketPath         string
+		vfReleaseTimeout      time.Duration
+		waitForVfRelease      bool
+		remediateGhostVFs     bool
+		ignoreBondedIfaces    bool
+		persistDriverLoad     bool
+		useTunedKernelParams  bool
+		watchdogInterval      time.Duration
+		hostMountPath         string
+		metricsBindAddress    string
 	}
 )
 
@@ -98,8 +111,18 @@ func init() {
 	startCmd.PersistentFlags().BoolVar(&startOpts.systemd, "use-systemd-service", false, "use config daemon in systemd mode")
 	startCmd.PersistentFlags().VarP(&startOpts.disabledPlugins, "disable-plugins", "", "comma-separated list of plugins to disable")
 	startCmd.PersistentFlags().BoolVar(&startOpts.parallelNicConfig, "parallel-nic-config", false, "perform NIC configuration in parallel")
+	startCmd.PersistentFlags().IntVar(&startOpts.parallelNicWorkers, "parallel-nic-workers", vars.ParallelNicConfigWorkers, "maximum number of NICs configured in parallel")
 	startCmd.PersistentFlags().BoolVar(&startOpts.manageSoftwareBridges, "manage-software-bridges", false, "enable management of software bridges")
 	startCmd.PersistentFlags().StringVar(&startOpts.ovsSocketPath, "ovs-socket-path", vars.OVSDBSocketPath, "path for OVSDB socket")
+	startCmd.PersistentFlags().DurationVar(&startOpts.vfReleaseTimeout, "vf-release-timeout", vars.VfReleaseTimeout, "maximum time to wait for the pods to release the VFs before the VFs are removed")
+	startCmd.PersistentFlags().BoolVar(&startOpts.waitForVfRelease, "wait-for-vf-release", false, "wait for the pods to release the VFs before the VFs are removed, the pods of DaemonSets must not use VFs")
+	startCmd.PersistentFlags().BoolVar(&startOpts.remediateGhostVFs, "remediate-ghost-vfs", false, "remove the VFs left by previous runs of the daemon which are not in the desired state")
+	startCmd.PersistentFlags().BoolVar(&startOpts.ignoreBondedIfaces, "ignore-bonded-interfaces", false, "configure the PFs enslaved to a bond or to a team without draining the node")
+	startCmd.PersistentFlags().BoolVar(&startOpts.persistDriverLoad, "persist-driver-load", false, "load the kernel drivers required by the node state on boot")
+	startCmd.PersistentFlags().BoolVar(&startOpts.useTunedKernelParams, "use-tuned-kernel-params", false, "add the kernel args to a tuned profile if tuned manages the host")
+	startCmd.PersistentFlags().DurationVar(&startOpts.watchdogInterval, "watchdog-interval", vars.PluginWatchdogInterval, "time without node state changes after which the node state is applied again, disabled if zero")
+	startCmd.PersistentFlags().StringVar(&startOpts.hostMountPath, "host-mount-path", vars.HostMountPath, "path where the host filesystem is mounted in the container")
+	startCmd.PersistentFlags().StringVar(&startOpts.metricsBindAddress, "metrics-bind-address", "", "address of the endpoint serving the metrics of the plugins at "+daemon.PluginMetricsPathPrefix+"<plugin>, disabled if empty")
 }
 
 func runStartCmd(cmd *cobra.Command, args []string) error {
@@ -114,8 +137,17 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 	}
 
 	vars.ParallelNicConfig = startOpts.parallelNicConfig
+	vars.ParallelNicConfigWorkers = startOpts.parallelNicWorkers
 	vars.ManageSoftwareBridges = startOpts.manageSoftwareBridges
 	vars.OVSDBSocketPath = startOpts.ovsSocketPath
+	vars.VfReleaseTimeout = startOpts.vfReleaseTimeout
+	vars.WaitForVfRelease = startOpts.waitForVfRelease
+	vars.RemediateGhostVFs = startOpts.remediateGhostVFs
+	vars.IgnoreBondedInterfaces = startOpts.ignoreBondedIfaces
+	vars.PersistDriverLoad = startOpts.persistDriverLoad
+	vars.UseTunedKernelParams = startOpts.useTunedKernelParams
+	vars.PluginWatchdogInterval = startOpts.watchdogInterval
+	vars.HostMountPath = startOpts.hostMountPath
 
 	if startOpts.nodeName == "" {
 		name, ok := os.LookupEnv("NODE_NAME")
@@ -234,6 +266,13 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// the commands run by the generic plugin are killed when the daemon stops
+	hostManagerV2, err := host.NewHostManagerV2(wait.ContextForChannel(stopCh), utils.New())
+	if err != nil {
+		setupLog.Error(err, "failed to create hostManagerV2")
+		return err
+	}
+
 	platformHelper, err := platforms.NewDefaultPlatformHelper()
 	if err != nil {
 		setupLog.Error(err, "failed to create platformHelper")
@@ -294,7 +333,7 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 	log.Log.Info("Enabled featureGates", "featureGates", featureGates.String())
 
 	setupLog.V(0).Info("Starting SriovNetworkConfigDaemon")
-	err = daemon.New(
+	dn := daemon.New(
 		kClient,
 		snclient,
 		kubeclient,
@@ -307,7 +346,10 @@ func runStartCmd(cmd *cobra.Command, args []string) error {
 		eventRecorder,
 		featureGates,
 		startOpts.disabledPlugins,
-	).Run(stopCh, exitCh)
+	)
+	dn.HostManagerV2 = hostManagerV2
+	go daemon.RunMetricsServer(startOpts.metricsBindAddress, dn.MetricsHandler(), stopCh)
+	err = dn.Run(stopCh, exitCh)
 	if err != nil {
 		setupLog.Error(err, "failed to run daemon")
 	}
@@ -243,6 +243,11 @@ func (dr *DrainReconcile) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			"DrainController",
 			"node drain completed")
 		return ctrl.Result{}, nil
+	} else if nodeDrainAnnotation == constants.NodeStatePaused {
+		// the reconciliation of the node is paused, a node paused during the drain stays cordoned
+		// and the drain is handled again once the pause annotation is removed
+		reqLogger.Info("node reconciliation is paused nothing todo")
+		return ctrl.Result{}, nil
 	}
 
 	reqLogger.Error(nil, "unexpected node drain annotation")
@@ -296,31 +301,14 @@ func (dr *DrainReconcile) tryDrainNode(ctx context.Context, node *corev1.Node) (
 		return nil, err
 	}
 
-	current := 0
-	snns := &sriovnetworkv1.SriovNetworkNodeState{}
-
-	var currentSnns *sriovnetworkv1.SriovNetworkNodeState
-	for _, nodeObj := range nodeList {
-		err = dr.Get(ctx, client.ObjectKey{Name: nodeObj.GetName(), Namespace: vars.Namespace}, snns)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				reqLogger.V(2).Info("node doesn't have a sriovNetworkNodePolicy")
-				continue
-			}
-			return nil, err
-		}
-
-		if snns.GetName() == node.GetName() {
-			currentSnns = snns.DeepCopy()
-		}
-
-		if utils.ObjectHasAnnotation(snns, constants.NodeStateDrainAnnotationCurrent, constants.Draining) ||
-			utils.ObjectHasAnnotation(snns, constants.NodeStateDrainAnnotationCurrent, constants.DrainComplete) {
-			current++
-		}
+	unavailable, currentSnns, err := dr.unavailableNodes(ctx, node, nodeList)
+	if err != nil {
+		reqLogger.Error(err, "failed to count the draining nodes of the pool")
+		return nil, err
 	}
+	current := len(unavailable)
 	reqLogger.Info("Max node allowed to be draining at the same time", "MaxParallelNodeConfiguration", maxUnv)
-	reqLogger.Info("Count of draining", "drainingNodes", current)
+	reqLogger.Info("Count of draining", "drainingNodes", current, "nodes", unavailable)
 
 	// if maxUnv is zero this means we drain all the nodes in parallel without a limit
 	if maxUnv == -1 {
@@ -345,6 +333,40 @@ func (dr *DrainReconcile) tryDrainNode(ctx context.Context, node *corev1.Node) (
 	return nil, nil
 }
 
+// unavailableNodes returns the nodes of the pool whose node state is draining or drained, and the node state of the
+// requested node. The node states are listed from the API server on every request, so the budget of the pool also
+// accounts for the drains started by previous requests or before a restart of the operator. The membership of the
+// pool is evaluated on every request: a node which joins the pool while it is draining is counted in its new pool,
+// and no other node of that pool starts to drain until the pool is back within its budget.
+func (dr *DrainReconcile) unavailableNodes(ctx context.Context, node *corev1.Node, nodeList []corev1.Node) ([]string, *sriovnetworkv1.SriovNetworkNodeState, error) {
+	snnsList := &sriovnetworkv1.SriovNetworkNodeStateList{}
+	if err := dr.List(ctx, snnsList, &client.ListOptions{Namespace: vars.Namespace}); err != nil {
+		return nil, nil, err
+	}
+
+	nodesInPool := make(map[string]bool, len(nodeList))
+	for _, nodeObj := range nodeList {
+		nodesInPool[nodeObj.GetName()] = true
+	}
+
+	var unavailable []string
+	var currentSnns *sriovnetworkv1.SriovNetworkNodeState
+	for i := range snnsList.Items {
+		snns := &snnsList.Items[i]
+		if !nodesInPool[snns.GetName()] {
+			continue
+		}
+		if snns.GetName() == node.GetName() {
+			currentSnns = snns
+		}
+		if utils.ObjectHasAnnotation(snns, constants.NodeStateDrainAnnotationCurrent, constants.Draining) ||
+			utils.ObjectHasAnnotation(snns, constants.NodeStateDrainAnnotationCurrent, constants.DrainComplete) {
+			unavailable = append(unavailable, snns.GetName())
+		}
+	}
+	return unavailable, currentSnns, nil
+}
+
 func (dr *DrainReconcile) findNodePoolConfig(ctx context.Context, node *corev1.Node) (*sriovnetworkv1.SriovNetworkPoolConfig, []corev1.Node, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("findNodePoolConfig():")
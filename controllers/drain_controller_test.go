@@ -246,6 +246,48 @@ var _ = Describe("Drain Controller", Ordered, func() {
 			ExpectDrainCompleteNodesHaveIsNotSchedule(nodeState1, nodeState2, nodeState3)
 		})
 
+		It("should count a draining node which joins the pool in the budget of the pool", func(ctx context.Context) {
+			node1, nodeState1 := createNodeWithLabel(ctx, "node1", "pool")
+			node2, nodeState2 := createNodeWithLabel(ctx, "node2", "pool")
+			node3, nodeState3 := createNode(ctx, "node3")
+
+			maxun := intstr.Parse("1")
+			poolConfig := &sriovnetworkv1.SriovNetworkPoolConfig{}
+			poolConfig.SetNamespace(testNamespace)
+			poolConfig.SetName("test-workers")
+			poolConfig.Spec = sriovnetworkv1.SriovNetworkPoolConfigSpec{MaxUnavailable: &maxun, NodeSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"pool": "",
+				},
+			}}
+			Expect(k8sClient.Create(context.TODO(), poolConfig)).Should(Succeed())
+
+			// node3 drains in the default pool
+			simulateDaemonSetAnnotation(node3, constants.DrainRequired)
+			expectNodeStateAnnotation(nodeState3, constants.DrainComplete)
+
+			// node3 joins the pool while it is drained
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: node3.Name}, node3)).ToNot(HaveOccurred())
+			node3.Labels["pool"] = ""
+			Expect(k8sClient.Update(ctx, node3)).ToNot(HaveOccurred())
+
+			// the budget of the pool is used by node3
+			simulateDaemonSetAnnotation(node1, constants.DrainRequired)
+			simulateDaemonSetAnnotation(node2, constants.DrainRequired)
+			Consistently(func(g Gomega) {
+				for _, nodeState := range []*sriovnetworkv1.SriovNetworkNodeState{nodeState1, nodeState2} {
+					g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: nodeState.Namespace, Name: nodeState.Name}, nodeState)).
+						ToNot(HaveOccurred())
+					g.Expect(utils.ObjectHasAnnotation(nodeState, constants.NodeStateDrainAnnotationCurrent, constants.DrainIdle)).
+						To(BeTrue())
+				}
+			}, "10s", "1s").Should(Succeed())
+
+			simulateDaemonSetAnnotation(node3, constants.DrainIdle)
+			expectNodeStateAnnotation(nodeState3, constants.DrainIdle)
+			expectNumberOfDrainingNodes(1, nodeState1, nodeState2)
+		})
+
 		It("should drain all nodes in parallel with a custom pool using nil in max unavailable", func(ctx context.Context) {
 			node1, nodeState1 := createNode(ctx, "node1")
 			node2, nodeState2 := createNode(ctx, "node2")
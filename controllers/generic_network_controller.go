@@ -60,6 +60,16 @@ type networkController interface {
 	Name() string
 }
 
+// optional interface which controller can implement to fill the network instance
+// with values derived from other objects before the NetAttDef is rendered
+type networkInstanceCompleter interface {
+	// CompleteInstance updates the in-memory copy of the network instance, the change is not stored
+	CompleteInstance(ctx context.Context, instance networkCRInstance) error
+	// CompletionSource returns type of the object the completion depends on and the function
+	// which maps the object to the network instances which should be reconciled when it changes
+	CompletionSource() (client.Object, handler.MapFunc)
+}
+
 func newGenericNetworkReconciler(c client.Client, s *runtime.Scheme, controller networkController) *genericNetworkReconciler {
 	return &genericNetworkReconciler{Client: c, Scheme: s, controller: controller}
 }
@@ -124,6 +134,11 @@ func (r *genericNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 		return reconcile.Result{}, err
 	}
+	if completer, ok := r.controller.(networkInstanceCompleter); ok {
+		if err := completer.CompleteInstance(ctx, instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
 	raw, err := instance.RenderNetAttDef()
 	if err != nil {
 		return reconcile.Result{}, err
@@ -200,11 +215,15 @@ func (r *genericNetworkReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	namespaceHandler := handler.Funcs{
 		CreateFunc: r.namespaceHandlerCreate,
 	}
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(r.controller.GetObject()).
 		Watches(&netattdefv1.NetworkAttachmentDefinition{}, &handler.EnqueueRequestForObject{}).
-		Watches(&corev1.Namespace{}, &namespaceHandler).
-		Complete(r.controller)
+		Watches(&corev1.Namespace{}, &namespaceHandler)
+	if completer, ok := r.controller.(networkInstanceCompleter); ok {
+		obj, mapFunc := completer.CompletionSource()
+		builder = builder.Watches(obj, handler.EnqueueRequestsFromMapFunc(mapFunc))
+	}
+	return builder.Complete(r.controller)
 }
 
 func (r *genericNetworkReconciler) namespaceHandlerCreate(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
@@ -59,6 +59,7 @@ const (
 	validatingWebhookConfigurationCRDName = "ValidatingWebhookConfiguration"
 	machineConfigCRDName                  = "MachineConfig"
 	trueString                            = "true"
+	configDaemonDaemonSetName             = "sriov-network-config-daemon"
 )
 
 type DrainAnnotationPredicate struct {
@@ -230,6 +231,45 @@ func syncPluginDaemonObjs(ctx context.Context,
 	return nil
 }
 
+// isConfigDaemonRunning returns false if the DaemonSet of the config daemon doesn't exist or is being deleted,
+// the config daemons can't clean up the nodes and remove the finalizer of the node states in that case
+func isConfigDaemonRunning(ctx context.Context, client k8sclient.Client) (bool, error) {
+	ds := &appsv1.DaemonSet{}
+	err := client.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: configDaemonDaemonSetName}, ds)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return ds.GetDeletionTimestamp().IsZero(), nil
+}
+
+// removeNodeStateFinalizers removes the finalizer of the node states which no config daemon will remove,
+// only the node states which are being deleted are released if deletingOnly is true
+func removeNodeStateFinalizers(ctx context.Context, client k8sclient.Client, deletingOnly bool) error {
+	logger := log.Log.WithName("removeNodeStateFinalizers")
+	nsList := &sriovnetworkv1.SriovNetworkNodeStateList{}
+	if err := client.List(ctx, nsList, k8sclient.InNamespace(vars.Namespace)); err != nil {
+		return err
+	}
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		if deletingOnly && ns.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if !controllerutil.RemoveFinalizer(ns, sriovnetworkv1.NODESTATEFINALIZERNAME) {
+			continue
+		}
+		logger.Info("Removing the finalizer of SriovNetworkNodeState without config daemon", "name", ns.Name)
+		if err := client.Update(ctx, ns); k8sclient.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Fail to remove finalizer", "SriovNetworkNodeState CR:", ns.Name)
+			return err
+		}
+	}
+	return nil
+}
+
 func deleteK8sResource(ctx context.Context, client k8sclient.Client, in *uns.Unstructured) error {
 	if err := apply.DeleteObject(ctx, client, in); err != nil {
 		return fmt.Errorf("failed to delete object %v with err: %v", in, err)
@@ -18,12 +18,18 @@ package controllers
 
 import (
 	"context"
+	"sort"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
 // SriovNetworkReconciler reconciles a SriovNetwork object
@@ -57,6 +63,58 @@ func (r *SriovNetworkReconciler) GetObjectList() client.ObjectList {
 	return &sriovnetworkv1.SriovNetworkList{}
 }
 
+// CompleteInstance uses the VF trust mode and spoof check settings of the SriovNetworkNodePolicies
+// for the network resource if they are not set in the SriovNetwork, so the CNI applies the same values
+// as the config daemon
+func (r *SriovNetworkReconciler) CompleteInstance(ctx context.Context, instance networkCRInstance) error {
+	network := instance.(*sriovnetworkv1.SriovNetwork)
+	if network.Spec.Trust != "" && network.Spec.SpoofChk != "" {
+		return nil
+	}
+	policyList := &sriovnetworkv1.SriovNetworkNodePolicyList{}
+	if err := r.List(ctx, policyList, client.InNamespace(vars.Namespace)); err != nil {
+		return err
+	}
+	// the policy with the highest priority wins
+	sort.Sort(sriovnetworkv1.ByPriority(policyList.Items))
+	for i := len(policyList.Items) - 1; i >= 0; i-- {
+		p := policyList.Items[i]
+		if p.Spec.ResourceName != network.Spec.ResourceName {
+			continue
+		}
+		if network.Spec.Trust == "" && p.Spec.Trust != "" {
+			network.Spec.Trust = p.Spec.Trust
+		}
+		if network.Spec.SpoofChk == "" && p.Spec.SpoofChk != "" {
+			network.Spec.SpoofChk = p.Spec.SpoofChk
+		}
+	}
+	return nil
+}
+
+// CompletionSource returns the function which triggers reconciliation of the SriovNetworks
+// when a SriovNetworkNodePolicy for the same resource changes
+func (r *SriovNetworkReconciler) CompletionSource() (client.Object, handler.MapFunc) {
+	return &sriovnetworkv1.SriovNetworkNodePolicy{}, func(ctx context.Context, obj client.Object) []reconcile.Request {
+		policy, ok := obj.(*sriovnetworkv1.SriovNetworkNodePolicy)
+		if !ok {
+			return nil
+		}
+		networkList := &sriovnetworkv1.SriovNetworkList{}
+		if err := r.List(ctx, networkList, client.InNamespace(vars.Namespace)); err != nil {
+			log.Log.WithName(r.Name()+" reconciler").Info("Can't list networks for policy", "policy", policy.Name, "error", err)
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, n := range networkList.Items {
+			if n.Spec.ResourceName == policy.Spec.ResourceName {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: n.Namespace, Name: n.Name}})
+			}
+		}
+		return requests
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *SriovNetworkReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.genericReconciler = newGenericNetworkReconciler(r.Client, r.Scheme, r)
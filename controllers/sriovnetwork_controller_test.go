@@ -342,6 +342,56 @@ var _ = Describe("SriovNetwork Controller", Ordered, func() {
 				MustPassRepeatedly(10).
 				Should(Succeed())
 		})
+
+		It("should use the trust and spoofChk settings of the SriovNetworkNodePolicy for the resource", func() {
+			policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-trust-policy", Namespace: testNamespace},
+				Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+					ResourceName: "resource_trust",
+					NumVfs:       2,
+					NodeSelector: map[string]string{"foo": "bar"},
+					NicSelector:  sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens1"}},
+					Trust:        on,
+				},
+			}
+			Expect(k8sClient.Create(ctx, policy)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, policy)
+
+			cr := sriovnetworkv1.SriovNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-policy-trust", Namespace: testNamespace},
+				Spec: sriovnetworkv1.SriovNetworkSpec{
+					ResourceName: "resource_trust",
+					IPAM:         `{"type":"dhcp"}`,
+					SpoofChk:     "off",
+				},
+			}
+			Expect(k8sClient.Create(ctx, &cr)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, &cr)
+
+			expected := cr.DeepCopy()
+			expected.Spec.Trust = on
+			Eventually(func(g Gomega) {
+				netAttDef := &netattdefv1.NetworkAttachmentDefinition{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: cr.GetName(), Namespace: testNamespace}, netAttDef)).To(Succeed())
+				g.Expect(strings.TrimSpace(netAttDef.Spec.Config)).To(Equal(generateExpectedNetConfig(expected)))
+			}, util.Timeout, util.RetryInterval).Should(Succeed())
+
+			By("update the policy")
+			Expect(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: policy.Name, Namespace: testNamespace}, policy); err != nil {
+					return err
+				}
+				policy.Spec.Trust = "off"
+				return k8sClient.Update(ctx, policy)
+			})).To(Succeed())
+
+			expected.Spec.Trust = "off"
+			Eventually(func(g Gomega) {
+				netAttDef := &netattdefv1.NetworkAttachmentDefinition{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: cr.GetName(), Namespace: testNamespace}, netAttDef)).To(Succeed())
+				g.Expect(strings.TrimSpace(netAttDef.Spec.Config)).To(Equal(generateExpectedNetConfig(expected)))
+			}, util.Timeout, util.RetryInterval).Should(Succeed())
+		})
 	})
 })
 
@@ -46,6 +46,7 @@ import (
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/featuregate"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -266,6 +267,17 @@ func (r *SriovNetworkNodePolicyReconciler) syncAllSriovNetworkNodeStates(ctx con
 	if err := r.Get(ctx, types.NamespacedName{Namespace: vars.Namespace, Name: constants.ConfigMapName}, found); err != nil {
 		logger.V(1).Info("Fail to get", "ConfigMap", constants.ConfigMapName)
 	}
+	configDaemonRunning, err := isConfigDaemonRunning(ctx, r.Client)
+	if err != nil {
+		logger.Error(err, "Fail to get the config daemon DaemonSet")
+		return err
+	}
+	if !configDaemonRunning {
+		// the deleted node states are not cleaned up without config daemon
+		if err := removeNodeStateFinalizers(ctx, r.Client, true); err != nil {
+			return err
+		}
+	}
 	for _, node := range nl.Items {
 		logger.V(1).Info("Sync SriovNetworkNodeState CR", "name", node.Name)
 		ns := &sriovnetworkv1.SriovNetworkNodeState{}
@@ -280,7 +292,7 @@ func (r *SriovNetworkNodePolicyReconciler) syncAllSriovNetworkNodeStates(ctx con
 	}
 	logger.V(1).Info("Remove SriovNetworkNodeState custom resource for unselected node")
 	nsList := &sriovnetworkv1.SriovNetworkNodeStateList{}
-	err := r.List(ctx, nsList, &client.ListOptions{})
+	err = r.List(ctx, nsList, &client.ListOptions{})
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			logger.Error(err, "Fail to list SriovNetworkNodeState CRs")
@@ -297,8 +309,15 @@ func (r *SriovNetworkNodePolicyReconciler) syncAllSriovNetworkNodeStates(ctx con
 			}
 			if !found {
 				logger.Info("Deleting SriovNetworkNodeState as node with that name doesn't exist", "nodeStateName", ns.Name)
+				// no config daemon runs on the node anymore to clean it up and remove the finalizer
+				if controllerutil.RemoveFinalizer(&ns, sriovnetworkv1.NODESTATEFINALIZERNAME) {
+					if err := r.Update(ctx, &ns); err != nil {
+						logger.Error(err, "Fail to remove finalizer", "SriovNetworkNodeState CR:", ns.GetName())
+						return client.IgnoreNotFound(err)
+					}
+				}
 				err := r.Delete(ctx, &ns, &client.DeleteOptions{})
-				if err != nil {
+				if client.IgnoreNotFound(err) != nil {
 					logger.Error(err, "Fail to Delete", "SriovNetworkNodeState CR:", ns.GetName())
 					return err
 				}
@@ -324,6 +343,8 @@ func (r *SriovNetworkNodePolicyReconciler) syncSriovNetworkNodeState(ctx context
 	if err != nil {
 		logger.Error(err, "Fail to get SriovNetworkNodeState", "namespace", ns.Namespace, "name", ns.Name)
 		if errors.IsNotFound(err) {
+			// the config daemon cleans up the node before the node state is removed
+			controllerutil.AddFinalizer(ns, sriovnetworkv1.NODESTATEFINALIZERNAME)
 			err = r.Create(ctx, ns)
 			if err != nil {
 				return fmt.Errorf("couldn't create SriovNetworkNodeState: %v", err)
@@ -333,6 +354,11 @@ func (r *SriovNetworkNodePolicyReconciler) syncSriovNetworkNodeState(ctx context
 			return fmt.Errorf("failed to get SriovNetworkNodeState: %v", err)
 		}
 	} else {
+		if !found.GetDeletionTimestamp().IsZero() {
+			logger.Info("SriovNetworkNodeState is being deleted, waiting for the cleanup of the node",
+				"namespace", ns.Namespace, "name", ns.Name)
+			return nil
+		}
 		if len(found.Status.Interfaces) == 0 {
 			logger.Info("SriovNetworkNodeState Status Interfaces are empty. Skip update of policies in spec",
 				"namespace", ns.Namespace, "name", ns.Name)
@@ -343,6 +369,10 @@ func (r *SriovNetworkNodePolicyReconciler) syncSriovNetworkNodeState(ctx context
 		newVersion := found.DeepCopy()
 		newVersion.Spec = ns.Spec
 		newVersion.OwnerReferences = ns.OwnerReferences
+		// the node states created by the previous versions of the operator don't have the finalizer
+		finalizerAdded := controllerutil.AddFinalizer(newVersion, sriovnetworkv1.NODESTATEFINALIZERNAME)
+		// the driver overrides are recorded again by the selected policies
+		delete(newVersion.Annotations, constants.NodeStateDriverOverridesAnnotation)
 
 		// Previous Policy Priority(ppp) records the priority of previous evaluated policy in node policy list.
 		// Since node policy list is already sorted with priority number, comparing current priority with ppp shall
@@ -374,12 +404,30 @@ func (r *SriovNetworkNodePolicyReconciler) syncSriovNetworkNodeState(ctx context
 				ppp = p.Spec.Priority
 			}
 		}
+		// the config daemon refuses to configure the interfaces with invalid VF groups
+		for i := range newVersion.Spec.Interfaces {
+			iface := &newVersion.Spec.Interfaces[i]
+			uncoveredVfs, errs := utils.ValidateVfGroups(iface)
+			for _, err := range errs {
+				logger.Error(err, "invalid VF groups, the interface will not be configured", "node", node.Name)
+			}
+			if len(errs) == 0 && len(uncoveredVfs) > 0 {
+				logger.V(1).Info("VFs are not in any VF group", "node", node.Name,
+					"interface", iface.PciAddress, "vfs", uncoveredVfs)
+			}
+		}
+		// the ports of a dual-port NIC share the VFs of the device
+		for _, err := range utils.ValidateSiblingVfBudgets(newVersion) {
+			logger.Error(err, "VFs of sibling PFs exceed the VFs of the device, the interfaces will not be configured", "node", node.Name)
+		}
 
 		// Note(adrianc): we check same ownerReferences since SriovNetworkNodeState
 		// was owned by a default SriovNetworkNodePolicy. if we encounter a descripancy
 		// we need to update.
-		if reflect.DeepEqual(newVersion.OwnerReferences, found.OwnerReferences) &&
-			equality.Semantic.DeepEqual(newVersion.Spec, found.Spec) {
+		if !finalizerAdded && reflect.DeepEqual(newVersion.OwnerReferences, found.OwnerReferences) &&
+			equality.Semantic.DeepEqual(newVersion.Spec, found.Spec) &&
+			newVersion.Annotations[constants.NodeStateDriverOverridesAnnotation] ==
+				found.Annotations[constants.NodeStateDriverOverridesAnnotation] {
 			logger.V(1).Info("SriovNetworkNodeState did not change, not updating")
 			return nil
 		}
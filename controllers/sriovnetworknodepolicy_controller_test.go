@@ -6,12 +6,16 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	dptypes "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/types"
 
@@ -126,3 +130,120 @@ func TestRenderDevicePluginConfigData(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncAllSriovNetworkNodeStatesFinalizer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(appsv1.AddToScheme(scheme))
+
+	dc := &sriovnetworkv1.SriovOperatorConfig{ObjectMeta: metav1.ObjectMeta{
+		Name: consts.DefaultConfigName, Namespace: vars.Namespace, UID: "config-uid"}}
+	// the node of the node state doesn't exist anymore, the config daemon can't remove the finalizer
+	orphan := &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{
+		Name: "node2", Namespace: vars.Namespace, Finalizers: []string{sriovnetworkv1.NODESTATEFINALIZERNAME}}}
+	reconciler := SriovNetworkNodePolicyReconciler{
+		Client:      fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc, orphan).Build(),
+		Scheme:      scheme,
+		FeatureGate: featuregate.New(),
+	}
+	nodeList := &corev1.NodeList{Items: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}}
+
+	err := reconciler.syncAllSriovNetworkNodeStates(context.TODO(), dc, &sriovnetworkv1.SriovNetworkNodePolicyList{}, nodeList)
+	if err != nil {
+		t.Fatalf("syncAllSriovNetworkNodeStates has failed: %v", err)
+	}
+
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	if err := reconciler.Get(context.TODO(), client.ObjectKey{Namespace: vars.Namespace, Name: "node1"}, nodeState); err != nil {
+		t.Fatalf("node state of node1 not created: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(nodeState, sriovnetworkv1.NODESTATEFINALIZERNAME) {
+		t.Errorf("node state of node1 created without finalizer: %v", nodeState.Finalizers)
+	}
+	err = reconciler.Get(context.TODO(), client.ObjectKey{Namespace: vars.Namespace, Name: "node2"}, nodeState)
+	if !errors.IsNotFound(err) {
+		t.Errorf("node state of the removed node2 not deleted: %v", err)
+	}
+}
+
+func TestSyncAllSriovNetworkNodeStatesWithoutConfigDaemon(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(appsv1.AddToScheme(scheme))
+
+	dc := &sriovnetworkv1.SriovOperatorConfig{ObjectMeta: metav1.ObjectMeta{
+		Name: consts.DefaultConfigName, Namespace: vars.Namespace, UID: "config-uid"}}
+	deletionTimestamp := metav1.Now()
+	deleted := &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{
+		Name: "node1", Namespace: vars.Namespace, DeletionTimestamp: &deletionTimestamp,
+		Finalizers: []string{sriovnetworkv1.NODESTATEFINALIZERNAME}}}
+	newReconciler := func(objs ...client.Object) SriovNetworkNodePolicyReconciler {
+		return SriovNetworkNodePolicyReconciler{
+			Client:      fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+			Scheme:      scheme,
+			FeatureGate: featuregate.New(),
+		}
+	}
+	nodeList := &corev1.NodeList{Items: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}}
+	key := client.ObjectKey{Namespace: vars.Namespace, Name: "node1"}
+
+	// the config daemon cleans up the node and removes the finalizer
+	daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: configDaemonDaemonSetName, Namespace: vars.Namespace}}
+	reconciler := newReconciler(dc, deleted.DeepCopy(), daemonSet)
+	err := reconciler.syncAllSriovNetworkNodeStates(context.TODO(), dc, &sriovnetworkv1.SriovNetworkNodePolicyList{}, nodeList)
+	if err != nil {
+		t.Fatalf("syncAllSriovNetworkNodeStates has failed: %v", err)
+	}
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	if err := reconciler.Get(context.TODO(), key, nodeState); err != nil {
+		t.Fatalf("deleted node state removed while the config daemon runs: %v", err)
+	}
+
+	// no config daemon removes the finalizer
+	reconciler = newReconciler(dc, deleted.DeepCopy())
+	err = reconciler.syncAllSriovNetworkNodeStates(context.TODO(), dc, &sriovnetworkv1.SriovNetworkNodePolicyList{}, nodeList)
+	if err != nil {
+		t.Fatalf("syncAllSriovNetworkNodeStates has failed: %v", err)
+	}
+	// the node state of the selected node is created again once the deleted one is removed
+	nodeState = &sriovnetworkv1.SriovNetworkNodeState{}
+	if err := reconciler.Get(context.TODO(), key, nodeState); err != nil {
+		t.Fatalf("node state of node1 not created: %v", err)
+	}
+	if !nodeState.GetDeletionTimestamp().IsZero() {
+		t.Errorf("deleted node state not removed without config daemon")
+	}
+}
+
+func TestRemoveNodeStateFinalizers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(sriovnetworkv1.AddToScheme(scheme))
+
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{
+		Name: "node1", Namespace: vars.Namespace, Finalizers: []string{sriovnetworkv1.NODESTATEFINALIZERNAME}}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nodeState).Build()
+	key := client.ObjectKey{Namespace: vars.Namespace, Name: "node1"}
+
+	if err := removeNodeStateFinalizers(context.TODO(), c, true); err != nil {
+		t.Fatalf("removeNodeStateFinalizers has failed: %v", err)
+	}
+	if err := c.Get(context.TODO(), key, nodeState); err != nil {
+		t.Fatalf("failed to get node state: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(nodeState, sriovnetworkv1.NODESTATEFINALIZERNAME) {
+		t.Errorf("finalizer removed from a node state which is not deleted")
+	}
+
+	// the default SriovOperatorConfig is deleted
+	if err := removeNodeStateFinalizers(context.TODO(), c, false); err != nil {
+		t.Fatalf("removeNodeStateFinalizers has failed: %v", err)
+	}
+	if err := c.Get(context.TODO(), key, nodeState); err != nil {
+		t.Fatalf("failed to get node state: %v", err)
+	}
+	if len(nodeState.Finalizers) != 0 {
+		t.Errorf("finalizer not removed: %v", nodeState.Finalizers)
+	}
+}
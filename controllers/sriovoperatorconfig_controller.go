@@ -84,7 +84,11 @@ func (r *SriovOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 		if apierrors.IsNotFound(err) {
 			logger.Info("default SriovOperatorConfig object not found. waiting for creation.")
 
-			err := r.deleteAllWebhooks(ctx)
+			if err := r.deleteAllWebhooks(ctx); err != nil {
+				return reconcile.Result{}, err
+			}
+			// the config daemons are removed with the default config, they can't clean up the node states
+			err := removeNodeStateFinalizers(ctx, r.Client, false)
 			return reconcile.Result{}, err
 		}
 		// Error reading the object - requeue the request.
@@ -92,6 +96,12 @@ func (r *SriovOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 		return reconcile.Result{}, err
 	}
 
+	if !defaultConfig.GetDeletionTimestamp().IsZero() {
+		logger.Info("default SriovOperatorConfig object is being deleted, releasing the node states")
+		err := removeNodeStateFinalizers(ctx, r.Client, false)
+		return reconcile.Result{}, err
+	}
+
 	snolog.SetLogLevel(defaultConfig.Spec.LogLevel)
 
 	r.FeatureGate.Init(defaultConfig.Spec.FeatureGates)
@@ -186,6 +196,7 @@ func (r *SriovOperatorConfigReconciler) syncConfigDaemonSet(ctx context.Context,
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASEVERSION")
 	data.Data["ClusterType"] = vars.ClusterType
 	data.Data["DevMode"] = os.Getenv("DEV_MODE")
+	data.Data["NetdevsimMode"] = os.Getenv("SRIOV_NETDEVSIM_MODE")
 	data.Data["ImagePullSecrets"] = GetImagePullSecrets()
 	if dc.Spec.ConfigurationMode == sriovnetworkv1.SystemdConfigurationMode {
 		data.Data["UsedSystemdMode"] = true
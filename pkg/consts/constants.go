@@ -30,6 +30,8 @@ const (
 	DaemonPath                         = "./bindata/manifests/daemon"
 	DefaultPolicyName                  = "default"
 	ConfigMapName                      = "device-plugin-config"
+	SkipDevicesConfigMapName           = "sriov-skip-devices"
+	KernelParamsConfigMapName          = "sriov-kernel-params"
 	DaemonSet                          = "DaemonSet"
 	Role                               = "Role"
 	RoleBinding                        = "RoleBinding"
@@ -44,11 +46,24 @@ const (
 	LinkTypeIB  = "IB"
 	LinkTypeETH = "ETH"
 
+	RdmaSubsystemModeShared    = "shared"
+	RdmaSubsystemModeExclusive = "exclusive"
+
 	LinkAdminStateUp   = "up"
 	LinkAdminStateDown = "down"
 
 	UninitializedNodeGUID = "0000:0000:0000:0000"
 
+	VendorMellanox   = "15b3"
+	VendorIntel      = "8086"
+	VendorSolarflare = "1924"
+	VendorAmazon     = "1d0f"
+	VendorPensando   = "1dd8"
+	// MlxMaxVFs is the maximum number of VFs of a Mellanox device, the PFs of a dual-port device share them
+	MlxMaxVFs = 128
+	// DeviceIDEna is the PCI device ID of the Elastic Network Adapter VFs of the AWS Nitro instances
+	DeviceIDEna = "ec20"
+
 	DeviceTypeVfioPci   = "vfio-pci"
 	DeviceTypeNetDevice = "netdevice"
 	VdpaTypeVirtio      = "virtio"
@@ -62,6 +77,7 @@ const (
 	SriovSwitchDevConfPath     = SriovConfBasePath + "/sriov_config.json"
 	SriovHostSwitchDevConfPath = Host + SriovSwitchDevConfPath
 	ManagedOVSBridgesPath      = SriovConfBasePath + "/managed-ovs-bridges.json"
+	PfOriginalSettingsPath     = SriovConfBasePath + "/pf-original-settings.json"
 
 	MachineConfigPoolPausedAnnotation       = "sriovnetwork.openshift.io/state"
 	MachineConfigPoolPausedAnnotationIdle   = "Idle"
@@ -71,14 +87,35 @@ const (
 	NodeStateDrainAnnotation        = "sriovnetwork.openshift.io/desired-state"
 	NodeStateDrainAnnotationCurrent = "sriovnetwork.openshift.io/current-state"
 	DrainIdle                       = "Idle"
-	DrainRequired                   = "Drain_Required"
-	RebootRequired                  = "Reboot_Required"
-	Draining                        = "Draining"
-	DrainComplete                   = "DrainComplete"
+
+	// NodeStatePauseAnnotation pauses the reconciliation of the node state when set to NodeStatePaused on the node
+	// or on its SriovNetworkNodeState, on the node it replaces the drain state until the pause is removed
+	NodeStatePauseAnnotation = NodeDrainAnnotation
+	NodeStatePaused          = "pause"
+	// NodeStateForceApplyAnnotation forces the plugins to apply the node state again when its value changes,
+	// e.g. after VFs were modified out-of-band
+	NodeStateForceApplyAnnotation = "sriovnetwork.openshift.io/force-apply"
+	// LastApplyTimeAnnotation contains UTC time of the last successful apply of the node state in RFC3339 format
+	LastApplyTimeAnnotation = "sriov.k8s.cni.cncf.io/last-apply-time"
+	// LastApplyDurationAnnotation contains duration of the last successful apply of the node state in milliseconds
+	LastApplyDurationAnnotation = "sriov.k8s.cni.cncf.io/last-apply-duration"
+	DrainRequired               = "Drain_Required"
+	RebootRequired              = "Reboot_Required"
+	Draining                    = "Draining"
+	DrainComplete               = "DrainComplete"
+
+	// DriverOverrideAnnotation forces the driver of the VFs of a SriovNetworkNodePolicy, for testing purposes only,
+	// the override is honored by the config daemon only if SRIOV_DRIVER_OVERRIDE_ALLOWED is "true"
+	DriverOverrideAnnotation = "sriov.k8s.cni.cncf.io/driver-override"
+	// NodeStateDriverOverridesAnnotation contains the driver overrides of the policies applied to the node state
+	// as a JSON map of the policy names to the drivers
+	NodeStateDriverOverridesAnnotation = "sriov.k8s.cni.cncf.io/driver-overrides"
 
 	SyncStatusSucceeded  = "Succeeded"
 	SyncStatusFailed     = "Failed"
 	SyncStatusInProgress = "InProgress"
+	// SyncStatusPaused is reported while the reconciliation is paused by the pause annotation
+	SyncStatusPaused = "Paused"
 
 	DrainDeleted = "Deleted"
 	DrainEvicted = "Evicted"
@@ -93,12 +130,28 @@ const (
 	SysBusPciDevices      = SysBus + "/pci/devices"
 	SysBusPciDrivers      = SysBus + "/pci/drivers"
 	SysBusPciDriversProbe = SysBus + "/pci/drivers_probe"
+	SysBusNetdevsim       = SysBus + "/netdevsim"
+	NetdevsimDebugfs      = "/sys/kernel/debug/netdevsim"
 	SysClassNet           = "/sys/class/net"
+	Proc                  = "/proc"
 	ProcKernelCmdLine     = "/proc/cmdline"
+	ProcKernelOSRelease   = "/proc/sys/kernel/osrelease"
+	ProcCPUInfo           = "/proc/cpuinfo"
+	ProcInterrupts        = "/proc/interrupts"
+	ProcIrq               = "/proc/irq"
+	ProcDevices           = "/proc/devices"
+	SysKernelIommuGroups  = "/sys/kernel/iommu_groups"
+	SysKernelMmHugepages  = "/sys/kernel/mm/hugepages"
+	SysDevicesSystemNode  = "/sys/devices/system/node"
+	EfiSecureBootVar      = "/sys/firmware/efi/efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c"
 	NetClass              = 0x02
 	NumVfsFile            = "sriov_numvfs"
 	BusPci                = "pci"
 	BusVdpa               = "vdpa"
+	BusNetdevsim          = "netdevsim"
+
+	// KubeletPodResourcesSocket is the socket of the kubelet API which lists the devices allocated to the pods
+	KubeletPodResourcesSocket = "/var/lib/kubelet/pod-resources/kubelet.sock"
 
 	UdevFolder          = "/etc/udev"
 	HostUdevFolder      = Host + UdevFolder
@@ -121,10 +174,49 @@ const (
 		`IMPORT{program}="/etc/udev/switchdev-vf-link-name.sh $attr{phys_port_name}", ` +
 		`NAME="%s_$env{NUMBER}"`
 
+	OsReleaseFile    = "/etc/os-release"
+	OsReleaseLibFile = "/usr/lib/os-release"
+	OstreeBootedFile = "/run/ostree-booted"
+
+	DistroFamilyRHEL    = "rhel"
+	DistroFamilyDebian  = "debian"
+	DistroFamilySUSE    = "suse"
+	DistroFamilyUnknown = "unknown"
+
+	KernelArgsBackendRpmOstree  = "rpm-ostree"
+	KernelArgsBackendGrubby     = "grubby"
+	KernelArgsBackendUpdateGrub = "update-grub"
+
+	ModulesLoadConfFile = "/etc/modules-load.d/sriov-operator.conf"
+
+	TunDevice      = "/dev/net/tun"
+	VhostNetDevice = "/dev/vhost-net"
+	// directory of the VFIO device nodes of the IOMMU groups used by the workloads
+	VfioDevDir = "/dev/vfio"
+	// device used by the Solarflare Onload stack to steer the traffic to the VFs
+	SfcAffinityDevice = "/dev/sfc_affinity"
+	// directory of the firmware files loaded by the kernel, the files flashed with devlink must be in it
+	FirmwareDir = "/lib/firmware"
+
+	ModprobeConfFolder    = "/etc/modprobe.d"
+	ModprobeBlacklistFile = ModprobeConfFolder + "/sriov-operator-blacklist.conf"
+
 	KernelArgPciRealloc = "pci=realloc"
 	KernelArgIntelIommu = "intel_iommu=on"
 	KernelArgIommuPt    = "iommu=pt"
 
+	KernelArgIommuPassthrough = "iommu.passthrough=0"
+	KernelArgSMMUBypass       = "arm-smmu.disable_bypass=1"
+
+	KernelArgEnaRss = "ena_rss=1"
+
+	CPUVendorIntel = "GenuineIntel"
+	CPUVendorAMD   = "AuthenticAMD"
+	CPUVendorARM   = "ARM"
+
+	ArchitectureAmd64 = "amd64"
+	ArchitectureArm64 = "arm64"
+
 	// Feature gates
 	// ParallelNicConfigFeatureGate: allow to configure nics in parallel
 	ParallelNicConfigFeatureGate = "parallelNicConfig"
@@ -146,6 +238,14 @@ const (
 	InfinibandGUIDConfigFilePath = SriovConfBasePath + "/infiniband/guids"
 )
 
+// layout of the resource file of the PCI devices in sysfs
+const (
+	// PciNumBars is the number of standard BARs of a PCI device and of its VFs
+	PciNumBars = 6
+	// PciIovResourcesStart is the index of the first BAR of the VFs, after the standard BARs and the expansion ROM
+	PciIovResourcesStart = PciNumBars + 1
+)
+
 const (
 	// Baremetal platform
 	Baremetal PlatformTypes = iota
@@ -4,20 +4,26 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os/exec"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
@@ -26,6 +32,7 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/featuregate"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
 	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
@@ -41,11 +48,16 @@ const (
 	// maxUpdateBackoff is the maximum time to react to a change as we back off
 	// in the face of errors.
 	maxUpdateBackoff = 60 * time.Second
+	// resyncWorkItem is the work item of the syncs which are not triggered by a change of the node state,
+	// the generations of the node state start at 1
+	resyncWorkItem int64 = 0
 )
 
 type Message struct {
 	syncStatus    string
 	lastSyncError string
+	// syncError is the error of the failed sync, used to find the PFs which failed
+	syncError error
 }
 
 type Daemon struct {
@@ -63,8 +75,33 @@ type Daemon struct {
 
 	loadedPlugins map[string]plugin.VendorPlugin
 
+	// metricsMux serves the metrics endpoints of the loaded plugins
+	metricsMux *http.ServeMux
+	// metricsPlugins contains the names of the plugins whose endpoints were added to metricsMux
+	metricsPlugins map[string]struct{}
+
+	// nodeName is the name of the node of the daemon, it is read once when the daemon is created
+	nodeName string
+	// nodeLister reads the node of the daemon from the informer cache, the node is watched for the pause annotation
+	nodeLister corelisters.NodeLister
+
+	// true if the plugins were paused by the pause annotation on the node state or on the node
+	pluginsPaused bool
+	// message reported in the status of the node state while the reconciliation is paused
+	pausedMessage string
+
+	// value of the force-apply annotation on the node state which was handled last
+	lastForceApply string
+
+	// true if a plugin watchdog requested to apply the node state again, see requestReapply
+	reapplyRequested atomic.Bool
+
 	HostHelpers helper.HostHelpersInterface
 
+	// HostManagerV2 runs the host operations of the generic plugin with the context of the daemon,
+	// the generic plugin uses the host helpers if nil
+	HostManagerV2 host.HostManagerV2Interface
+
 	platformHelpers platforms.Interface
 
 	// channel used by callbacks to signal Run() of an error
@@ -112,6 +149,7 @@ func New(
 		stopCh:           stopCh,
 		syncCh:           syncCh,
 		refreshCh:        refreshCh,
+		nodeName:         vars.NodeName,
 		desiredNodeState: &sriovnetworkv1.SriovNetworkNodeState{},
 		currentNodeState: &sriovnetworkv1.SriovNetworkNodeState{},
 		workqueue: workqueue.NewNamedRateLimitingQueue(workqueue.NewMaxOfRateLimiter(
@@ -120,6 +158,8 @@ func New(
 		eventRecorder:   er,
 		featureGate:     featureGates,
 		disabledPlugins: disabledPlugins,
+		metricsMux:      http.NewServeMux(),
+		metricsPlugins:  map[string]struct{}{},
 		mu:              &sync.Mutex{},
 	}
 }
@@ -145,6 +185,9 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 		}
 	} else {
 		log.Log.V(0).Info("Run(): daemon running in systemd mode")
+		if err := systemd.RemovePersistedConfFile(); err != nil {
+			log.Log.Error(err, "failed to remove the persisted configuration of daemon mode")
+		}
 	}
 
 	// Only watch own SriovNetworkNodeState CR
@@ -191,11 +234,35 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 		UpdateFunc: dn.operatorConfigChangeHandler,
 	})
 
+	nodeInformerFactory := informers.NewSharedInformerFactoryWithOptions(dn.kubeClient,
+		time.Second*30,
+		informers.WithTweakListOptions(func(lo *metav1.ListOptions) {
+			lo.FieldSelector = metadataKey + "=" + dn.nodeName
+		}),
+	)
+	nodeInformer := nodeInformerFactory.Core().V1().Nodes()
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: dn.nodeUpdateHandler,
+	})
+	dn.nodeLister = nodeInformer.Lister()
+
 	rand.Seed(time.Now().UnixNano())
 	go cfgInformer.Run(dn.stopCh)
-	time.Sleep(5 * time.Second)
+	go nodeInformer.Informer().Run(dn.stopCh)
+	select {
+	case <-stopCh:
+		log.Log.V(0).Info("Run(): daemon stopped before the informers started")
+		return nil
+	case <-time.After(5 * time.Second):
+	}
 	go informer.Run(dn.stopCh)
-	if ok := cache.WaitForCacheSync(stopCh, cfgInformer.HasSynced, informer.HasSynced); !ok {
+	if ok := cache.WaitForCacheSync(stopCh, cfgInformer.HasSynced, nodeInformer.Informer().HasSynced, informer.HasSynced); !ok {
+		select {
+		case <-stopCh:
+			log.Log.V(0).Info("Run(): daemon stopped before the caches synced")
+			return nil
+		default:
+		}
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
@@ -208,6 +275,7 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 		select {
 		case <-stopCh:
 			log.Log.V(0).Info("Run(): stop daemon")
+			dn.stopPluginWatchdogs()
 			return nil
 		case err, more := <-exitCh:
 			log.Log.Error(err, "got an error")
@@ -238,6 +306,22 @@ func (dn *Daemon) enqueueNodeState(obj interface{}) {
 	dn.workqueue.Add(key)
 }
 
+// nodeUpdateHandler syncs the node state when the pause annotation is added to the node or removed from it
+func (dn *Daemon) nodeUpdateHandler(old, new interface{}) {
+	oldNode, ok := old.(*corev1.Node)
+	if !ok {
+		return
+	}
+	newNode, ok := new.(*corev1.Node)
+	if !ok {
+		return
+	}
+	if isPauseRequested(oldNode) != isPauseRequested(newNode) {
+		log.Log.V(2).Info("nodeUpdateHandler(): pause annotation of the node changed", "paused", isPauseRequested(newNode))
+		dn.workqueue.Add(resyncWorkItem)
+	}
+}
+
 func (dn *Daemon) processNextWorkItem() bool {
 	log.Log.V(2).Info("processNextWorkItem", "worker-queue-size", dn.workqueue.Len())
 	obj, shutdown := dn.workqueue.Get()
@@ -268,6 +352,7 @@ func (dn *Daemon) processNextWorkItem() bool {
 			dn.refreshCh <- Message{
 				syncStatus:    consts.SyncStatusFailed,
 				lastSyncError: err.Error(),
+				syncError:     err,
 			}
 			<-dn.syncCh
 			dn.workqueue.AddRateLimited(key)
@@ -313,6 +398,20 @@ func (dn *Daemon) operatorConfigChangeHandler(old, new interface{}) {
 	}
 
 	vars.MlxPluginFwReset = dn.featureGate.IsEnabled(consts.MellanoxFirmwareResetFeatureGate)
+
+	if interval := newCfg.Spec.GetVfAttributeReconcileInterval(); interval != vars.VfAttributeReconcileInterval {
+		vars.VfAttributeReconcileInterval = interval
+		log.Log.Info("Set VF attribute reconcile interval", "interval", interval)
+		dn.setPluginVFAttributeReconcileIntervals()
+	}
+
+	ratio, err := newCfg.Spec.GetVfOvercommitRatio()
+	if err != nil {
+		log.Log.Error(err, "operatorConfigChangeHandler(): invalid VF overcommit ratio, keep the current value",
+			"value", vars.VfOvercommitRatio)
+		return
+	}
+	vars.VfOvercommitRatio = ratio
 }
 
 func (dn *Daemon) nodeStateSyncHandler() error {
@@ -329,11 +428,33 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 
 	// load plugins if it has not loaded
 	if len(dn.loadedPlugins) == 0 {
-		dn.loadedPlugins, err = loadPlugins(dn.desiredNodeState, dn.HostHelpers, dn.disabledPlugins)
+		dn.loadedPlugins, err = loadPlugins(dn.desiredNodeState, dn.HostHelpers, dn.HostManagerV2, dn.disabledPlugins, dn.client, dn.eventRecorder)
 		if err != nil {
 			log.Log.Error(err, "nodeStateSyncHandler(): failed to enable vendor plugins")
 			return err
 		}
+		dn.startPluginConfigWatchers()
+		dn.registerPluginMetrics()
+		if !vars.UsingSystemdMode {
+			dn.startPluginWatchdogs()
+		}
+	}
+
+	pausedBy, err := dn.pauseRequestedBy()
+	if err != nil {
+		return err
+	}
+	if pausedBy != "" {
+		return dn.pausePlugins(pausedStatusMessage(dn.desiredNodeState, pausedBy))
+	}
+	if dn.pluginsPaused {
+		if err := dn.resumePlugins(); err != nil {
+			return err
+		}
+	}
+
+	if !dn.desiredNodeState.GetDeletionTimestamp().IsZero() {
+		return dn.cleanupNodeState()
 	}
 
 	skipReconciliation := true
@@ -384,9 +505,17 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		}
 	}
 
+	if dn.reapplyRequested.Swap(false) {
+		log.Log.Info("nodeStateSyncHandler(): re-apply of the node state requested by a plugin watchdog")
+		skipReconciliation = false
+	}
+
 	// we are done with the configuration just return here
 	if dn.currentNodeState.GetGeneration() == dn.desiredNodeState.GetGeneration() &&
 		dn.desiredNodeState.Status.SyncStatus == consts.SyncStatusSucceeded && skipReconciliation {
+		if dn.forceApplyRequested() {
+			return dn.forceApplyPlugins()
+		}
 		log.Log.Info("Current state and desire state are equal together with sync status succeeded nothing to do")
 		return nil
 	}
@@ -482,7 +611,7 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 	for k, p := range dn.loadedPlugins {
 		// Skip both the general and virtual plugin apply them last
 		if k != GenericPluginName && k != VirtualPluginName {
-			err := p.Apply()
+			err := dn.applyPlugin(p)
 			if err != nil {
 				log.Log.Error(err, "nodeStateSyncHandler(): plugin Apply failed", "plugin-name", k)
 				return err
@@ -497,7 +626,7 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		selectedPlugin, ok := dn.loadedPlugins[GenericPluginName]
 		if ok {
 			// Apply generic plugin last
-			err = selectedPlugin.Apply()
+			err = dn.applyPlugin(selectedPlugin)
 			if err != nil {
 				log.Log.Error(err, "nodeStateSyncHandler(): generic plugin fail to apply")
 				return err
@@ -508,12 +637,16 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 		selectedPlugin, ok = dn.loadedPlugins[VirtualPluginName]
 		if ok {
 			// Apply virtual plugin last
-			err = selectedPlugin.Apply()
+			err = dn.applyPlugin(selectedPlugin)
 			if err != nil {
 				log.Log.Error(err, "nodeStateSyncHandler(): virtual plugin failed to apply")
 				return err
 			}
 		}
+
+		if err := dn.persistConfiguration(); err != nil {
+			return err
+		}
 	}
 
 	if reqReboot {
@@ -546,6 +679,8 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 
 	log.Log.Info("nodeStateSyncHandler(): sync succeeded")
 	dn.currentNodeState = dn.desiredNodeState.DeepCopy()
+	// the node state was applied, a pending force-apply request is fulfilled
+	dn.lastForceApply = dn.desiredNodeState.GetAnnotations()[consts.NodeStateForceApplyAnnotation]
 	if vars.UsingSystemdMode {
 		dn.refreshCh <- Message{
 			syncStatus:    sriovResult.SyncStatus,
@@ -562,6 +697,303 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 	return nil
 }
 
+// cleanupNodeState reverts the configuration of the node with the loaded plugins when the node state is deleted
+// and removes the finalizer of the node state, the vendor plugins are cleaned up before the generic plugin.
+// The configuration re-applied on boot is removed too, see cleanupBootConfiguration.
+func (dn *Daemon) cleanupNodeState() error {
+	if !controllerutil.ContainsFinalizer(dn.desiredNodeState, sriovnetworkv1.NODESTATEFINALIZERNAME) {
+		log.Log.V(2).Info("cleanupNodeState(): node state is being deleted, nothing to clean up")
+		return nil
+	}
+	log.Log.Info("cleanupNodeState(): node state deleted, clean up the node configuration")
+	for k, p := range dn.loadedPlugins {
+		if k != GenericPluginName && k != VirtualPluginName {
+			if err := cleanupPlugin(k, p); err != nil {
+				return err
+			}
+		}
+	}
+	for _, k := range []string{GenericPluginName, VirtualPluginName} {
+		p, ok := dn.loadedPlugins[k]
+		if !ok {
+			continue
+		}
+		if err := cleanupPlugin(k, p); err != nil {
+			return err
+		}
+	}
+	if err := cleanupBootConfiguration(); err != nil {
+		return err
+	}
+
+	nodeState := dn.desiredNodeState.DeepCopy()
+	controllerutil.RemoveFinalizer(nodeState, sriovnetworkv1.NODESTATEFINALIZERNAME)
+	patch := client.MergeFromWithOptions(dn.desiredNodeState, client.MergeFromWithOptimisticLock{})
+	if err := dn.client.Patch(context.Background(), nodeState, patch); err != nil {
+		log.Log.Error(err, "cleanupNodeState(): failed to remove the finalizer of the node state")
+		return err
+	}
+	dn.eventRecorder.SendEvent("NodeStateCleanup", "Configuration of the node has been cleaned up")
+	// the node state created again is applied from scratch
+	dn.currentNodeState = &sriovnetworkv1.SriovNetworkNodeState{}
+	return nil
+}
+
+// cleanupBootConfiguration removes the configuration applied by the sriov-config services on boot,
+// the services would configure the node again after a reboot otherwise. In systemd mode the configuration
+// file of the services is emptied, the service files are managed by the operator and are kept.
+func cleanupBootConfiguration() error {
+	if !vars.UsingSystemdMode {
+		return systemd.RemovePersistedConfFile()
+	}
+	if _, err := systemd.WriteConfFile(&sriovnetworkv1.SriovNetworkNodeState{}); err != nil {
+		log.Log.Error(err, "cleanupNodeState(): failed to clear the configuration file for systemd mode")
+		return err
+	}
+	if err := systemd.RemoveSriovResult(); err != nil {
+		log.Log.Error(err, "cleanupNodeState(): failed to remove the result file for systemd mode")
+		return err
+	}
+	return nil
+}
+
+// pauseRequestedBy returns the kind of the object which has the pause annotation, the node state or the node,
+// an empty string is returned if the reconciliation is not paused. The node is read from the informer cache,
+// the reconciliation is not paused by the node if the node is not in the cache.
+func (dn *Daemon) pauseRequestedBy() (string, error) {
+	if isPauseRequested(dn.desiredNodeState) {
+		return "SriovNetworkNodeState", nil
+	}
+	node, err := dn.nodeLister.Get(dn.nodeName)
+	if errors.IsNotFound(err) {
+		log.Log.V(2).Info("pauseRequestedBy(): node not found in the cache, not paused by the node", "name", dn.nodeName)
+		return "", nil
+	}
+	if err != nil {
+		log.Log.Error(err, "pauseRequestedBy(): failed to get node", "name", dn.nodeName)
+		return "", err
+	}
+	if isPauseRequested(node) {
+		return "Node", nil
+	}
+	return "", nil
+}
+
+// isPauseRequested returns true if the object has the pause annotation
+func isPauseRequested(obj metav1.Object) bool {
+	return utils.ObjectHasAnnotation(obj, consts.NodeStatePauseAnnotation, consts.NodeStatePaused)
+}
+
+// pausedStatusMessage returns the message reported in the status of the paused node state. The drain is not
+// reverted by the pause, a node paused during a drain stays cordoned until the reconciliation is resumed.
+func pausedStatusMessage(nodeState *sriovnetworkv1.SriovNetworkNodeState, pausedBy string) string {
+	msg := fmt.Sprintf("reconciliation is paused by the %s=%s annotation on the %s",
+		consts.NodeStatePauseAnnotation, consts.NodeStatePaused, pausedBy)
+	desired := nodeState.GetAnnotations()[consts.NodeStateDrainAnnotation]
+	current := nodeState.GetAnnotations()[consts.NodeStateDrainAnnotationCurrent]
+	if (desired != "" && desired != consts.DrainIdle) || (current != "" && current != consts.DrainIdle) {
+		msg += fmt.Sprintf(", paused during a drain (desired state %s, current state %s): "+
+			"the node stays cordoned until the reconciliation is resumed", desired, current)
+	}
+	return msg
+}
+
+// pausePlugins pauses all the loaded plugins and reports the Paused sync status with the message,
+// the node state is not reconciled until the pause annotation is removed
+func (dn *Daemon) pausePlugins(message string) error {
+	if !dn.pluginsPaused {
+		for k, p := range dn.loadedPlugins {
+			pauser, ok := p.(plugin.Pauser)
+			if !ok {
+				continue
+			}
+			if err := pauser.Pause(); err != nil {
+				log.Log.Error(err, "pausePlugins(): failed to pause plugin", "plugin-name", k)
+				return err
+			}
+		}
+		log.Log.Info("pausePlugins(): reconciliation paused", "reason", message)
+		dn.eventRecorder.SendEvent("ReconciliationPaused", "Reconciliation of the node state has been paused")
+		dn.pluginsPaused = true
+	}
+	if message == dn.pausedMessage {
+		log.Log.V(2).Info("pausePlugins(): reconciliation is paused")
+		return nil
+	}
+	dn.refreshCh <- Message{
+		syncStatus:    consts.SyncStatusPaused,
+		lastSyncError: message,
+	}
+	// wait for writer to refresh the status
+	<-dn.syncCh
+	dn.pausedMessage = message
+	return nil
+}
+
+// forceApplyRequested returns true if the value of the force-apply annotation changed since it was handled last
+func (dn *Daemon) forceApplyRequested() bool {
+	value := dn.desiredNodeState.GetAnnotations()[consts.NodeStateForceApplyAnnotation]
+	return value != "" && value != dn.lastForceApply
+}
+
+// applyPlugin applies the node state with the plugin, the plugins which implement plugin.ContextApplier
+// are applied with a context which is canceled when the daemon stops
+func (dn *Daemon) applyPlugin(p plugin.VendorPlugin) error {
+	if applier, ok := p.(plugin.ContextApplier); ok {
+		return applier.ApplyWithContext(wait.ContextForChannel(dn.stopCh))
+	}
+	return p.Apply()
+}
+
+// forceApplyPlugins applies the current node state again with the loaded plugins even if it didn't change,
+// the plugins are applied in the same order as in the node state sync
+func (dn *Daemon) forceApplyPlugins() error {
+	log.Log.Info("forceApplyPlugins(): force apply of the node state requested")
+	dn.eventRecorder.SendEvent("ForceApply", "Forced re-apply of the node state has been initiated")
+	for k, p := range dn.loadedPlugins {
+		if k != GenericPluginName && k != VirtualPluginName {
+			if err := p.ForceApply(); err != nil {
+				log.Log.Error(err, "forceApplyPlugins(): plugin ForceApply failed", "plugin-name", k)
+				return err
+			}
+		}
+	}
+	if !vars.UsingSystemdMode {
+		for _, k := range []string{GenericPluginName, VirtualPluginName} {
+			p, ok := dn.loadedPlugins[k]
+			if !ok {
+				continue
+			}
+			if err := p.ForceApply(); err != nil {
+				log.Log.Error(err, "forceApplyPlugins(): plugin ForceApply failed", "plugin-name", k)
+				return err
+			}
+		}
+	}
+	dn.lastForceApply = dn.desiredNodeState.GetAnnotations()[consts.NodeStateForceApplyAnnotation]
+	return nil
+}
+
+// cleanupPlugin reverts the configuration of the node done by the plugin,
+// nothing is done for the plugins which don't implement plugin.Cleaner
+func cleanupPlugin(name string, p plugin.VendorPlugin) error {
+	cleaner, ok := p.(plugin.Cleaner)
+	if !ok {
+		return nil
+	}
+	if err := cleaner.Cleanup(); err != nil {
+		log.Log.Error(err, "cleanupNodeState(): plugin Cleanup failed", "plugin-name", name)
+		return err
+	}
+	return nil
+}
+
+// startPluginWatchdogs starts the watchdogs of the loaded plugins, the node state is applied again
+// by a sync of the daemon when a watchdog requests it
+func (dn *Daemon) startPluginWatchdogs() {
+	for k, p := range dn.loadedPlugins {
+		watchdog, ok := p.(plugin.Watchdog)
+		if !ok {
+			continue
+		}
+		if err := watchdog.StartWatchdog(dn.requestReapply); err != nil {
+			log.Log.Error(err, "startPluginWatchdogs(): failed to start plugin watchdog", "plugin-name", k)
+		}
+	}
+}
+
+// requestReapply queues a sync which applies the node state again even if it didn't change
+func (dn *Daemon) requestReapply() {
+	dn.reapplyRequested.Store(true)
+	dn.workqueue.Add(resyncWorkItem)
+}
+
+// stopPluginWatchdogs stops the watchdogs of the loaded plugins
+func (dn *Daemon) stopPluginWatchdogs() {
+	for k, p := range dn.loadedPlugins {
+		watchdog, ok := p.(plugin.Watchdog)
+		if !ok {
+			continue
+		}
+		if err := watchdog.StopWatchdog(); err != nil {
+			log.Log.Error(err, "stopPluginWatchdogs(): failed to stop plugin watchdog", "plugin-name", k)
+		}
+	}
+}
+
+// persistConfiguration writes the applied configuration of the switchdev and vfio-pci PFs to the host,
+// the sriov-config services re-apply it at boot before the daemon starts
+func (dn *Daemon) persistConfiguration() error {
+	modified, err := systemd.WritePersistedConfFile(dn.desiredNodeState)
+	if err != nil {
+		log.Log.Error(err, "persistConfiguration(): failed to write persisted configuration file")
+		return err
+	}
+	if modified {
+		log.Log.Info("persistConfiguration(): persisted configuration updated")
+	}
+	// the supported nic ids file is removed on the daemon start, the services require it
+	if err := systemd.WriteSriovSupportedNics(); err != nil {
+		log.Log.Error(err, "persistConfiguration(): failed to write supported nic ids file")
+		return err
+	}
+	return nil
+}
+
+// pluginConfigWatcher is implemented by the plugins which reload their configuration from a ConfigMap
+type pluginConfigWatcher interface {
+	StartConfigWatcher(ctx context.Context, kubeClient client.Client, namespace, configMapName string) error
+}
+
+// vfAttributeReconciler is implemented by the plugins which reapply periodically the attributes of the VFs
+type vfAttributeReconciler interface {
+	SetVFAttributeReconcileInterval(interval time.Duration)
+}
+
+// setPluginVFAttributeReconcileIntervals applies the VF attribute reconcile interval of the SriovOperatorConfig
+// to the loaded plugins, the plugins loaded later read it on creation
+func (dn *Daemon) setPluginVFAttributeReconcileIntervals() {
+	for _, p := range dn.loadedPlugins {
+		if reconciler, ok := p.(vfAttributeReconciler); ok {
+			reconciler.SetVFAttributeReconcileInterval(vars.VfAttributeReconcileInterval)
+		}
+	}
+}
+
+// startPluginConfigWatchers starts the configuration hot-reload of the loaded plugins,
+// the plugins keep the configuration loaded on start if the watcher can't be started
+func (dn *Daemon) startPluginConfigWatchers() {
+	ctx := wait.ContextForChannel(dn.stopCh)
+	for k, p := range dn.loadedPlugins {
+		watcher, ok := p.(pluginConfigWatcher)
+		if !ok {
+			continue
+		}
+		if err := watcher.StartConfigWatcher(ctx, dn.client, vars.Namespace, consts.SkipDevicesConfigMapName); err != nil {
+			log.Log.Error(err, "startPluginConfigWatchers(): failed to start plugin config watcher", "plugin-name", k)
+		}
+	}
+}
+
+// resumePlugins resumes all the loaded plugins, the node state is applied by the sync which resumes the plugins
+func (dn *Daemon) resumePlugins() error {
+	for k, p := range dn.loadedPlugins {
+		pauser, ok := p.(plugin.Pauser)
+		if !ok {
+			continue
+		}
+		if err := pauser.Resume(); err != nil {
+			log.Log.Error(err, "resumePlugins(): failed to resume plugin", "plugin-name", k)
+			return err
+		}
+	}
+	log.Log.Info("resumePlugins(): reconciliation resumed")
+	dn.eventRecorder.SendEvent("ReconciliationResumed", "Reconciliation of the node state has been resumed")
+	dn.pluginsPaused = false
+	dn.pausedMessage = ""
+	return nil
+}
+
 func (dn *Daemon) shouldSkipReconciliation(latestState *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
 	log.Log.V(0).Info("shouldSkipReconciliation()")
 	var err error
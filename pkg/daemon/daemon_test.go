@@ -3,16 +3,24 @@ package daemon
 import (
 	"context"
 	"flag"
+	"fmt"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	fakek8s "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -28,6 +36,8 @@ import (
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/fake"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/generic"
+	mock_plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/systemd"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
 )
@@ -55,6 +65,8 @@ var _ = BeforeSuite(func() {
 
 var _ = Describe("Config Daemon", func() {
 	var stopCh chan struct{}
+	var runDone chan struct{}
+	var stopDaemon func()
 	var syncCh chan struct{}
 	var exitCh chan error
 	var refreshCh chan Message
@@ -159,8 +171,20 @@ var _ = Describe("Config Daemon", func() {
 
 		sut.loadedPlugins = map[string]plugin.VendorPlugin{generic.PluginName: &fake.FakePlugin{PluginName: "fake"}}
 
+		runDone = make(chan struct{})
+		stopped := false
+		// stopDaemon waits for the daemon to stop so the globals it reads can be set again
+		stopDaemon = func() {
+			if stopped {
+				return
+			}
+			stopped = true
+			close(stopCh)
+			Eventually(runDone, 10*time.Second).Should(BeClosed())
+		}
 		go func() {
 			defer GinkgoRecover()
+			defer close(runDone)
 			err := sut.Run(stopCh, exitCh)
 			Expect(err).ToNot(HaveOccurred())
 		}()
@@ -183,7 +207,7 @@ var _ = Describe("Config Daemon", func() {
 	})
 
 	AfterEach(func() {
-		close(stopCh)
+		stopDaemon()
 		close(syncCh)
 		close(exitCh)
 		close(refreshCh)
@@ -235,6 +259,7 @@ var _ = Describe("Config Daemon", func() {
 
 			Eventually(refreshCh, "30s").Should(Receive(&msg))
 			Expect(msg.syncStatus).To(Equal("Succeeded"))
+			Expect(filepath.Join(vars.FilesystemRoot, "/host", systemd.SriovPersistedConfigPath)).To(BeAnExistingFile())
 
 			Eventually(func() (int, error) {
 				podList, err := sut.kubeClient.CoreV1().Pods(vars.Namespace).List(context.Background(), metav1.ListOptions{
@@ -319,9 +344,255 @@ var _ = Describe("Config Daemon", func() {
 				return len(podList.Items), nil
 			}, "1s").Should(BeZero())
 		})
+
+		It("force apply the plugins once per force-apply annotation value", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			vendorPlugin := mock_plugin.NewMockVendorPlugin(mockCtrl)
+			genericPlugin := mock_plugin.NewMockVendorPlugin(mockCtrl)
+			gomock.InOrder(
+				vendorPlugin.EXPECT().ForceApply().Return(nil),
+				genericPlugin.EXPECT().ForceApply().Return(nil),
+			)
+			sut.loadedPlugins = map[string]plugin.VendorPlugin{"mellanox": vendorPlugin, GenericPluginName: genericPlugin}
+			sut.desiredNodeState = &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-node",
+				Annotations: map[string]string{consts.NodeStateForceApplyAnnotation: "2024-07-01T10:00:00Z"},
+			}}
+
+			Expect(sut.forceApplyRequested()).To(BeTrue())
+			Expect(sut.forceApplyPlugins()).To(Succeed())
+			Expect(sut.forceApplyRequested()).To(BeFalse())
+		})
+
+		It("pause the reconciliation with the annotation on the node state or on the node", func() {
+			nodes := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+			Expect(nodes.Add(node)).To(Succeed())
+			dn := &Daemon{
+				desiredNodeState: &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-node",
+					Annotations: map[string]string{consts.NodeStatePauseAnnotation: consts.NodeStatePaused},
+				}},
+				nodeName:   "test-node",
+				nodeLister: corelisters.NewNodeLister(nodes),
+			}
+			Expect(dn.pauseRequestedBy()).To(Equal("SriovNetworkNodeState"))
+
+			dn.desiredNodeState.Annotations = map[string]string{consts.NodeStatePauseAnnotation: consts.DrainIdle}
+			Expect(dn.pauseRequestedBy()).To(BeEmpty())
+
+			pausedNode := node.DeepCopy()
+			pausedNode.Annotations = map[string]string{consts.NodeStatePauseAnnotation: consts.NodeStatePaused}
+			Expect(nodes.Update(pausedNode)).To(Succeed())
+			Expect(dn.pauseRequestedBy()).To(Equal("Node"))
+		})
+
+		It("not pause the reconciliation if the node is not in the cache", func() {
+			dn := &Daemon{
+				desiredNodeState: &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+				nodeName:         "test-node",
+				nodeLister:       corelisters.NewNodeLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+			}
+			Expect(dn.pauseRequestedBy()).To(BeEmpty())
+		})
+
+		It("sync the node state when the pause annotation of the node changes", func() {
+			dn := &Daemon{workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+			defer dn.workqueue.ShutDown()
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node",
+				Annotations: map[string]string{consts.NodeDrainAnnotation: consts.DrainIdle}}}
+			pausedNode := node.DeepCopy()
+			pausedNode.Annotations[consts.NodeStatePauseAnnotation] = consts.NodeStatePaused
+
+			dn.nodeUpdateHandler(node, node.DeepCopy())
+			Expect(dn.workqueue.Len()).To(BeZero())
+			dn.nodeUpdateHandler(node, pausedNode)
+			Expect(dn.workqueue.Len()).To(Equal(1))
+			item, _ := dn.workqueue.Get()
+			Expect(item).To(Equal(resyncWorkItem))
+			dn.workqueue.Done(item)
+			dn.nodeUpdateHandler(pausedNode, node)
+			Expect(dn.workqueue.Len()).To(Equal(1))
+		})
+
+		It("queue a sync which applies the node state again when a plugin watchdog requests it", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			watchdog := mock_plugin.NewMockWatchdog(mockCtrl)
+			var trigger func()
+			watchdog.EXPECT().StartWatchdog(gomock.Any()).DoAndReturn(func(t func()) error {
+				trigger = t
+				return nil
+			})
+			dn := &Daemon{
+				workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+				loadedPlugins: map[string]plugin.VendorPlugin{
+					GenericPluginName: &watchdogPlugin{mock_plugin.NewMockVendorPlugin(mockCtrl), watchdog},
+					// plugins which don't implement the watchdog are skipped
+					"intel": &fake.FakePlugin{PluginName: "intel"},
+				},
+			}
+			defer dn.workqueue.ShutDown()
+
+			dn.startPluginWatchdogs()
+			Expect(dn.workqueue.Len()).To(BeZero())
+			trigger()
+			Expect(dn.workqueue.Len()).To(Equal(1))
+			item, _ := dn.workqueue.Get()
+			Expect(item).To(Equal(resyncWorkItem))
+			dn.workqueue.Done(item)
+			Expect(dn.reapplyRequested.Load()).To(BeTrue())
+		})
+
+		It("apply the plugins with a context which is canceled when the daemon stops", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			applier := mock_plugin.NewMockContextApplier(mockCtrl)
+			var applyCtx context.Context
+			applier.EXPECT().ApplyWithContext(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+				applyCtx = ctx
+				return nil
+			})
+			stopCh := make(chan struct{})
+			dn := &Daemon{stopCh: stopCh}
+			Expect(dn.applyPlugin(&contextApplierPlugin{mock_plugin.NewMockVendorPlugin(mockCtrl), applier})).To(Succeed())
+			Expect(applyCtx.Err()).ToNot(HaveOccurred())
+			close(stopCh)
+			Eventually(applyCtx.Done()).Should(BeClosed())
+
+			// plugins which don't implement ContextApplier are applied without a context
+			Expect(dn.applyPlugin(&fake.FakePlugin{PluginName: "intel"})).To(Succeed())
+		})
+
+		It("report a node paused during a drain as cordoned", func() {
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node",
+				Annotations: map[string]string{
+					consts.NodeStateDrainAnnotation:        consts.DrainRequired,
+					consts.NodeStateDrainAnnotationCurrent: consts.Draining,
+				},
+			}}
+			Expect(pausedStatusMessage(nodeState, "Node")).To(And(
+				ContainSubstring("paused by the sriovnetwork.openshift.io/state=pause annotation on the Node"),
+				ContainSubstring("the node stays cordoned")))
+
+			nodeState.Annotations = map[string]string{
+				consts.NodeStateDrainAnnotation:        consts.DrainIdle,
+				consts.NodeStateDrainAnnotationCurrent: consts.DrainIdle,
+			}
+			Expect(pausedStatusMessage(nodeState, "Node")).ToNot(ContainSubstring("cordoned"))
+		})
+
+		It("clean up the node with the plugins before removing the finalizer of the deleted node state", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			vendorCleaner := mock_plugin.NewMockCleaner(mockCtrl)
+			genericCleaner := mock_plugin.NewMockCleaner(mockCtrl)
+			gomock.InOrder(
+				vendorCleaner.EXPECT().Cleanup().Return(nil),
+				genericCleaner.EXPECT().Cleanup().Return(nil),
+			)
+			sut.loadedPlugins = map[string]plugin.VendorPlugin{
+				"mellanox":        &cleanerPlugin{mock_plugin.NewMockVendorPlugin(mockCtrl), vendorCleaner},
+				GenericPluginName: &cleanerPlugin{mock_plugin.NewMockVendorPlugin(mockCtrl), genericCleaner},
+				// plugins which don't implement Cleanup are skipped
+				"intel": &fake.FakePlugin{PluginName: "intel"},
+			}
+
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+			key := client.ObjectKey{Namespace: vars.Namespace, Name: "test-node"}
+			Expect(sut.client.Get(context.Background(), key, nodeState)).To(Succeed())
+			nodeState.Finalizers = []string{sriovnetworkv1.NODESTATEFINALIZERNAME}
+			Expect(sut.client.Update(context.Background(), nodeState)).To(Succeed())
+			Expect(sut.client.Delete(context.Background(), nodeState)).To(Succeed())
+			Expect(sut.client.Get(context.Background(), key, nodeState)).To(Succeed())
+			Expect(nodeState.DeletionTimestamp).ToNot(BeNil())
+
+			sut.desiredNodeState = nodeState
+			Expect(sut.cleanupNodeState()).To(Succeed())
+			err := sut.client.Get(context.Background(), key, &sriovnetworkv1.SriovNetworkNodeState{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("clean up the node and the configuration of the systemd service in systemd mode", func() {
+			stopDaemon()
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+			_, err := systemd.WriteConfFile(&sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+				Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:d8:00.0", NumVfs: 4}}}})
+			Expect(err).ToNot(HaveOccurred())
+
+			mockCtrl := gomock.NewController(GinkgoT())
+			genericCleaner := mock_plugin.NewMockCleaner(mockCtrl)
+			genericCleaner.EXPECT().Cleanup().Return(nil)
+			sut.loadedPlugins = map[string]plugin.VendorPlugin{
+				GenericPluginName: &cleanerPlugin{mock_plugin.NewMockVendorPlugin(mockCtrl), genericCleaner},
+			}
+
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+			key := client.ObjectKey{Namespace: vars.Namespace, Name: "test-node"}
+			Expect(sut.client.Get(context.Background(), key, nodeState)).To(Succeed())
+			nodeState.Finalizers = []string{sriovnetworkv1.NODESTATEFINALIZERNAME}
+			Expect(sut.client.Update(context.Background(), nodeState)).To(Succeed())
+			Expect(sut.client.Delete(context.Background(), nodeState)).To(Succeed())
+			Expect(sut.client.Get(context.Background(), key, nodeState)).To(Succeed())
+
+			sut.desiredNodeState = nodeState
+			Expect(sut.cleanupNodeState()).To(Succeed())
+			conf, err := systemd.ReadConfFile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conf.Spec.Interfaces).To(BeEmpty())
+			err = sut.client.Get(context.Background(), key, &sriovnetworkv1.SriovNetworkNodeState{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("keep the finalizer of the deleted node state if a plugin fails to clean up", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			genericCleaner := mock_plugin.NewMockCleaner(mockCtrl)
+			genericCleaner.EXPECT().Cleanup().Return(fmt.Errorf("test"))
+			sut.loadedPlugins = map[string]plugin.VendorPlugin{
+				GenericPluginName: &cleanerPlugin{mock_plugin.NewMockVendorPlugin(mockCtrl), genericCleaner},
+			}
+			sut.desiredNodeState = &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-node",
+				Finalizers: []string{sriovnetworkv1.NODESTATEFINALIZERNAME},
+			}}
+
+			Expect(sut.cleanupNodeState()).To(MatchError("test"))
+		})
+
+		It("request the force apply again if a plugin fails", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			genericPlugin := mock_plugin.NewMockVendorPlugin(mockCtrl)
+			genericPlugin.EXPECT().ForceApply().Return(fmt.Errorf("test"))
+			sut.loadedPlugins = map[string]plugin.VendorPlugin{GenericPluginName: genericPlugin}
+			sut.desiredNodeState = &sriovnetworkv1.SriovNetworkNodeState{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-node",
+				Annotations: map[string]string{consts.NodeStateForceApplyAnnotation: "1"},
+			}}
+
+			Expect(sut.forceApplyPlugins()).To(MatchError("test"))
+			Expect(sut.forceApplyRequested()).To(BeTrue())
+		})
 	})
 })
 
+// cleanerPlugin is a plugin which reverts the configuration of the node when the node state is deleted
+type cleanerPlugin struct {
+	*mock_plugin.MockVendorPlugin
+	*mock_plugin.MockCleaner
+}
+
+// contextApplierPlugin is a plugin which cancels its host operations when the daemon stops
+type contextApplierPlugin struct {
+	*mock_plugin.MockVendorPlugin
+	*mock_plugin.MockContextApplier
+}
+
+// watchdogPlugin is a plugin which requests to apply the node state again in the background
+type watchdogPlugin struct {
+	*mock_plugin.MockVendorPlugin
+	*mock_plugin.MockWatchdog
+}
+
 func createSriovNetworkNodeState(c snclient.Interface, nodeState *sriovnetworkv1.SriovNetworkNodeState) error {
 	_, err := c.SriovnetworkV1().
 		SriovNetworkNodeStates(vars.Namespace).
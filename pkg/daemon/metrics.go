@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+)
+
+// PluginMetricsPathPrefix is the path prefix of the metrics endpoints of the plugins,
+// the metrics of each plugin are served at the prefix followed by the name of the plugin
+const PluginMetricsPathPrefix = "/metrics/plugins/"
+
+// PluginDebugPathPrefix is the path prefix of the debug endpoints of the plugins,
+// the desired state of each plugin is served at the prefix followed by the name of the plugin and /desired-state
+const PluginDebugPathPrefix = "/debug/plugins/"
+
+// metricsServerShutdownTimeout is the time given to the metrics server to finish the running scrapes on exit
+const metricsServerShutdownTimeout = 5 * time.Second
+
+// MetricsHandler returns the HTTP handler of the daemon which serves the metrics and debug endpoints of the loaded plugins,
+// the endpoints are added when the plugins are loaded
+func (dn *Daemon) MetricsHandler() http.Handler {
+	return dn.metricsMux
+}
+
+// registerPluginMetrics adds the metrics and debug endpoints of the loaded plugins which expose them to the mux,
+// the endpoints of a plugin are added only once
+func (dn *Daemon) registerPluginMetrics() {
+	for name, p := range dn.loadedPlugins {
+		if _, ok := dn.metricsPlugins[name]; ok {
+			continue
+		}
+		dn.metricsPlugins[name] = struct{}{}
+		if exporter, ok := p.(plugin.MetricsExporter); ok {
+			if handler := exporter.MetricsHandler(); handler != nil {
+				dn.metricsMux.Handle(PluginMetricsPathPrefix+name, handler)
+				log.Log.V(2).Info("registerPluginMetrics(): serving plugin metrics", "plugin-name", name, "path", PluginMetricsPathPrefix+name)
+			}
+		}
+		if exporter, ok := p.(plugin.DesiredStateExporter); ok {
+			path := PluginDebugPathPrefix + name + "/desired-state"
+			dn.metricsMux.Handle(path, desiredStateHandler(name, exporter))
+			log.Log.V(2).Info("registerPluginMetrics(): serving plugin desired state", "plugin-name", name, "path", path)
+		}
+	}
+}
+
+// desiredStateHandler returns the handler which serves the desired state of the plugin as YAML,
+// the handler answers with 503 while the plugin has no desired state
+func desiredStateHandler(name string, exporter plugin.DesiredStateExporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		data, err := exporter.ExportDesiredState()
+		if err != nil {
+			log.Log.V(2).Info("desiredStateHandler(): failed to export the desired state", "plugin-name", name, "error", err.Error())
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(data)
+	})
+}
+
+// RunMetricsServer serves the handler on the address until the stop channel is closed,
+// nothing is served if the address is empty
+func RunMetricsServer(addr string, handler http.Handler, stopCh <-chan struct{}) {
+	if addr == "" {
+		return
+	}
+	server := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), metricsServerShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Log.Error(err, "RunMetricsServer(): failed to shut down the metrics server")
+		}
+	}()
+	log.Log.Info("RunMetricsServer(): serving metrics", "address", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Log.Error(err, "RunMetricsServer(): metrics server failed", "address", addr)
+	}
+}
@@ -3,11 +3,13 @@ package daemon
 import (
 	"fmt"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	genericplugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/generic"
 	intelplugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/intel"
@@ -30,7 +32,8 @@ var (
 	K8sPlugin         = k8splugin.NewK8sPlugin
 )
 
-func loadPlugins(ns *sriovnetworkv1.SriovNetworkNodeState, helpers helper.HostHelpersInterface, disabledPlugins []string) (map[string]plugin.VendorPlugin, error) {
+func loadPlugins(ns *sriovnetworkv1.SriovNetworkNodeState, helpers helper.HostHelpersInterface,
+	hostManagerV2 host.HostManagerV2Interface, disabledPlugins []string, kubeClient client.Client, eventRecorder *EventRecorder) (map[string]plugin.VendorPlugin, error) {
 	log.Log.Info("loadPlugins(): loading plugins")
 	loadedPlugins := map[string]plugin.VendorPlugin{}
 
@@ -63,7 +66,44 @@ func loadPlugins(ns *sriovnetworkv1.SriovNetworkNodeState, helpers helper.HostHe
 				loadedPlugins[pluginName] = k8sPlugin
 			}
 		}
-		genericPlugin, err := GenericPlugin(helpers)
+		var pfSkippers []plugin.PFSkipper
+		var totalVfsRaisers []plugin.TotalVfsRaiser
+		for _, vendorPlugin := range loadedVendorPlugins {
+			if skipper, ok := vendorPlugin.(plugin.PFSkipper); ok {
+				pfSkippers = append(pfSkippers, skipper)
+			}
+			if raiser, ok := vendorPlugin.(plugin.TotalVfsRaiser); ok {
+				totalVfsRaisers = append(totalVfsRaisers, raiser)
+			}
+		}
+		genericPluginOptions := []genericplugin.Option{
+			genericplugin.WithSkipDevices(consts.SkipDevicesConfigMapName),
+			genericplugin.WithPFSkippers(pfSkippers...),
+			genericplugin.WithTotalVfsRaisers(totalVfsRaisers...),
+			genericplugin.WithKernelParamSource(genericplugin.NewConfigMapKernelParamSource(consts.KernelParamsConfigMapName)),
+			genericplugin.WithKubeClient(kubeClient),
+			genericplugin.WithWatchdogInterval(vars.PluginWatchdogInterval),
+			genericplugin.WithHostMountPath(vars.HostMountPath),
+		}
+		if hostManagerV2 != nil {
+			genericPluginOptions = append(genericPluginOptions, genericplugin.WithHostManagerV2(hostManagerV2))
+		}
+		if eventRecorder != nil {
+			genericPluginOptions = append(genericPluginOptions, genericplugin.WithEventSender(eventRecorder))
+		}
+		if vars.RemediateGhostVFs {
+			genericPluginOptions = append(genericPluginOptions, genericplugin.WithRemediateGhostVFs())
+		}
+		if vars.IgnoreBondedInterfaces {
+			genericPluginOptions = append(genericPluginOptions, genericplugin.WithIgnoreBondedInterfaces())
+		}
+		if vars.PersistDriverLoad {
+			genericPluginOptions = append(genericPluginOptions, genericplugin.WithPersistDriverLoad())
+		}
+		if vars.UseTunedKernelParams {
+			genericPluginOptions = append(genericPluginOptions, genericplugin.WithTunedKernelParams())
+		}
+		genericPlugin, err := GenericPlugin(helpers, genericPluginOptions...)
 		if err != nil {
 			log.Log.Error(err, "loadPlugins(): failed to load the generic plugin")
 			return nil, err
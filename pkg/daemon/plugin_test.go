@@ -1,6 +1,10 @@
 package daemon
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
 	gomock "github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -11,6 +15,7 @@ import (
 	helperMocks "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	fakePlugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/fake"
+	mock_plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/mock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -41,6 +46,7 @@ var _ = Describe("config daemon plugin loading tests", func() {
 			vars.ClusterType = consts.ClusterTypeKubernetes
 			gmockController = gomock.NewController(GinkgoT())
 			helperMock = helperMocks.NewMockHostHelpersInterface(gmockController)
+			helperMock.EXPECT().GetKernelVersion().Return("5.14.0-427.13.1.el9_4.x86_64", nil).AnyTimes()
 			// k8s plugin is ATM the only plugin which require mocking/faking, as its New method performs additional logic
 			// other than simple plugin struct initialization
 			K8sPlugin = func(_ helper.HostHelpersInterface) (plugin.VendorPlugin, error) {
@@ -56,7 +62,7 @@ var _ = Describe("config daemon plugin loading tests", func() {
 						v1.InterfaceExt{Vendor: "8086"}},
 				},
 			}
-			vendorPlugins, err := loadPlugins(ns, helperMock, nil)
+			vendorPlugins, err := loadPlugins(ns, helperMock, nil, nil, nil, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			validateVendorPlugins(vendorPlugins, []string{"mellanox", "intel", "generic", "k8s"})
@@ -71,7 +77,7 @@ var _ = Describe("config daemon plugin loading tests", func() {
 						v1.InterfaceExt{Vendor: "8086"}},
 				},
 			}
-			vendorPlugins, err := loadPlugins(ns, helperMock, nil)
+			vendorPlugins, err := loadPlugins(ns, helperMock, nil, nil, nil, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			validateVendorPlugins(vendorPlugins, []string{"mellanox", "intel", "generic"})
@@ -86,7 +92,7 @@ var _ = Describe("config daemon plugin loading tests", func() {
 						v1.InterfaceExt{Vendor: "8086"}},
 				},
 			}
-			vendorPlugins, err := loadPlugins(ns, helperMock, nil)
+			vendorPlugins, err := loadPlugins(ns, helperMock, nil, nil, nil, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			validateVendorPlugins(vendorPlugins, []string{"virtual"})
@@ -99,7 +105,7 @@ var _ = Describe("config daemon plugin loading tests", func() {
 						v1.InterfaceExt{Vendor: "8086"}},
 				},
 			}
-			vendorPlugins, err := loadPlugins(ns, helperMock, nil)
+			vendorPlugins, err := loadPlugins(ns, helperMock, nil, nil, nil, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			validateVendorPlugins(vendorPlugins, []string{"intel", "generic", "k8s"})
@@ -113,7 +119,7 @@ var _ = Describe("config daemon plugin loading tests", func() {
 						v1.InterfaceExt{Vendor: "8086"}},
 				},
 			}
-			vendorPlugins, err := loadPlugins(ns, helperMock, []string{"mellanox"})
+			vendorPlugins, err := loadPlugins(ns, helperMock, nil, []string{"mellanox"}, nil, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			validateVendorPlugins(vendorPlugins, []string{"intel", "generic", "k8s"})
@@ -127,10 +133,77 @@ var _ = Describe("config daemon plugin loading tests", func() {
 						v1.InterfaceExt{Vendor: "8086"}},
 				},
 			}
-			vendorPlugins, err := loadPlugins(ns, helperMock, []string{"generic"})
+			vendorPlugins, err := loadPlugins(ns, helperMock, nil, []string{"generic"}, nil, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			validateVendorPlugins(vendorPlugins, []string{"intel", "k8s", "mellanox"})
 		})
 	})
+
+	Context("plugin metrics", func() {
+		get := func(dn *Daemon, path string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			dn.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			return recorder
+		}
+
+		It("serves the metrics of each plugin at its own path", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			exporter := mock_plugin.NewMockMetricsExporter(mockCtrl)
+			exporter.EXPECT().MetricsHandler().Return(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("generic_metric 1"))
+			})).Times(1)
+			mockPlugin := &struct {
+				*mock_plugin.MockVendorPlugin
+				*mock_plugin.MockMetricsExporter
+			}{mock_plugin.NewMockVendorPlugin(mockCtrl), exporter}
+
+			dn := New(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+			dn.loadedPlugins = map[string]plugin.VendorPlugin{
+				"generic": mockPlugin,
+				"intel":   &fakePlugin.FakePlugin{PluginName: "intel"},
+			}
+			dn.registerPluginMetrics()
+			// the endpoints are added only once
+			dn.registerPluginMetrics()
+
+			recorder := get(dn, PluginMetricsPathPrefix+"generic")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal("generic_metric 1"))
+			// plugins without metrics have no endpoint
+			Expect(get(dn, PluginMetricsPathPrefix+"intel").Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("serves the desired state of the plugins which export it", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			exporter := mock_plugin.NewMockDesiredStateExporter(mockCtrl)
+			gomock.InOrder(
+				exporter.EXPECT().ExportDesiredState().Return(nil, fmt.Errorf("desired state is not set")),
+				exporter.EXPECT().ExportDesiredState().Return([]byte("kind: SriovNetworkNodeState\n"), nil),
+			)
+			mockPlugin := &struct {
+				*mock_plugin.MockVendorPlugin
+				*mock_plugin.MockDesiredStateExporter
+			}{mock_plugin.NewMockVendorPlugin(mockCtrl), exporter}
+
+			dn := New(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+			dn.loadedPlugins = map[string]plugin.VendorPlugin{
+				"generic": mockPlugin,
+				"intel":   &fakePlugin.FakePlugin{PluginName: "intel"},
+			}
+			dn.registerPluginMetrics()
+
+			path := PluginDebugPathPrefix + "generic/desired-state"
+			Expect(get(dn, path).Code).To(Equal(http.StatusServiceUnavailable))
+			recorder := get(dn, path)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/yaml"))
+			Expect(recorder.Body.String()).To(Equal("kind: SriovNetworkNodeState\n"))
+
+			recorder = httptest.NewRecorder()
+			dn.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(get(dn, PluginDebugPathPrefix+"intel/desired-state").Code).To(Equal(http.StatusNotFound))
+		})
+	})
 })
@@ -18,7 +18,10 @@ import (
 	snclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/fileutil"
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/platforms"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -118,6 +121,7 @@ func (w *NodeStateStatusWriter) pollNicStatus() error {
 	log.Log.V(2).Info("pollNicStatus()")
 	var iface []sriovnetworkv1.InterfaceExt
 	var bridges sriovnetworkv1.Bridges
+	var system sriovnetworkv1.System
 	var err error
 
 	if vars.PlatformType == consts.VirtualOpenStack {
@@ -136,10 +140,21 @@ func (w *NodeStateStatusWriter) pollNicStatus() error {
 				return err
 			}
 		}
+		system.RdmaMode, err = w.hostHelper.GetRDMASubsystemNetnsMode()
+		if err != nil {
+			log.Log.Error(err, "pollNicStatus(): failed to get RDMA subsystem netns mode")
+		}
+	}
+
+	hostFacts, err := w.hostHelper.GetHostFacts()
+	if err != nil {
+		log.Log.Error(err, "pollNicStatus(): failed to get host facts")
 	}
 
 	w.status.Interfaces = iface
 	w.status.Bridges = bridges
+	w.status.HostFacts = hostFacts
+	w.status.System = system
 
 	return nil
 }
@@ -182,11 +197,19 @@ func (w *NodeStateStatusWriter) setNodeStateStatus(msg Message) (*sriovnetworkv1
 	nodeState, err := w.updateNodeStateStatusRetry(func(nodeState *sriovnetworkv1.SriovNetworkNodeState) {
 		nodeState.Status.Interfaces = w.status.Interfaces
 		nodeState.Status.Bridges = w.status.Bridges
+		nodeState.Status.HostFacts = w.status.HostFacts
+		nodeState.Status.System = w.status.System
 		if msg.lastSyncError != "" || msg.syncStatus == consts.SyncStatusSucceeded {
 			// clear lastSyncError when sync Succeeded
 			nodeState.Status.LastSyncError = msg.lastSyncError
 		}
 		nodeState.Status.SyncStatus = msg.syncStatus
+		if msg.syncStatus != "" {
+			nodeState.Status.InterfaceSyncStatuses = interfaceSyncStatuses(nodeState, msg)
+			if len(nodeState.Status.InterfaceSyncStatuses) > 0 {
+				nodeState.Status.SyncStatus = aggregateSyncStatus(nodeState.Status.InterfaceSyncStatuses)
+			}
+		}
 
 		log.Log.V(0).Info("setNodeStateStatus(): status",
 			"sync-status", nodeState.Status.SyncStatus,
@@ -198,6 +221,104 @@ func (w *NodeStateStatusWriter) setNodeStateStatus(msg Message) (*sriovnetworkv1
 	return nodeState, nil
 }
 
+// interfaceSyncStatuses returns the sync status of each PF in the spec of the node state. If the sync failed,
+// the PFs which failed are found by the PCI address in the sync error and the other PFs succeeded,
+// all the PFs failed if the error is not related to a PF. LastSyncTime is kept for the PFs which state didn't change.
+func interfaceSyncStatuses(nodeState *sriovnetworkv1.SriovNetworkNodeState, msg Message) sriovnetworkv1.InterfaceSyncStatuses {
+	failedPFs := map[string]error{}
+	for _, syncErr := range hostTypes.GetInterfaceSyncErrors(msg.syncError) {
+		failedPFs[syncErr.PciAddress] = syncErr.Err
+	}
+	previous := map[string]sriovnetworkv1.InterfaceSyncStatus{}
+	for _, ifaceStatus := range nodeState.Status.InterfaceSyncStatuses {
+		previous[ifaceStatus.PciAddress] = ifaceStatus
+	}
+
+	now := metav1.Now()
+	statuses := make(sriovnetworkv1.InterfaceSyncStatuses, 0, len(nodeState.Spec.Interfaces))
+	for _, iface := range nodeState.Spec.Interfaces {
+		ifaceStatus := sriovnetworkv1.InterfaceSyncStatus{PciAddress: iface.PciAddress, State: msg.syncStatus}
+		if msg.syncStatus == consts.SyncStatusFailed {
+			if ifaceErr, failed := failedPFs[iface.PciAddress]; failed {
+				ifaceStatus.Message = ifaceErr.Error()
+				ifaceStatus.DevlinkParamErrors = devlinkParamErrors(ifaceErr)
+				ifaceStatus.NumVfsShortfall = numVfsShortfall(ifaceErr)
+			} else if len(failedPFs) > 0 {
+				ifaceStatus.State = consts.SyncStatusSucceeded
+			} else {
+				ifaceStatus.Message = msg.lastSyncError
+			}
+		}
+		ifaceStatus.Warnings = vfioGroupWarnings(nodeState.Status.Interfaces, iface.PciAddress)
+		ifaceStatus.LastSyncTime = now
+		if prev, ok := previous[iface.PciAddress]; ok && prev.State == ifaceStatus.State && prev.Message == ifaceStatus.Message {
+			ifaceStatus.LastSyncTime = prev.LastSyncTime
+		}
+		statuses = append(statuses, ifaceStatus)
+	}
+	return statuses
+}
+
+// checkVFIOGroup verifies the IOMMU group of a VF bound to vfio-pci, overridden in unit-tests
+var checkVFIOGroup = utils.CheckVFIOGroup
+
+// vfioGroupWarnings returns a warning for each VF of the PF bound to vfio-pci whose IOMMU group can't be used
+// by the workloads, e.g. because other devices of the group are bound to host drivers
+func vfioGroupWarnings(interfaces sriovnetworkv1.InterfaceExts, pciAddress string) []string {
+	var warnings []string
+	for _, iface := range interfaces {
+		if iface.PciAddress != pciAddress {
+			continue
+		}
+		for _, vf := range iface.VFs {
+			if vf.Driver != consts.DeviceTypeVfioPci {
+				continue
+			}
+			if _, err := checkVFIOGroup(vf.PciAddress); err != nil {
+				warnings = append(warnings, err.Error())
+			}
+		}
+	}
+	return warnings
+}
+
+// devlinkParamErrors returns the errors of the devlink parameters of the PF contained in err by parameter name
+func devlinkParamErrors(err error) map[string]string {
+	paramErrs := hostTypes.GetDevlinkParamErrors(err)
+	if len(paramErrs) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(paramErrs))
+	for _, paramErr := range paramErrs {
+		result[paramErr.Param] = paramErr.Err.Error()
+	}
+	return result
+}
+
+// numVfsShortfall returns the number of requested VFs the device of the PF failed to create, 0 if err
+// is not caused by a VF shortfall
+func numVfsShortfall(err error) int {
+	var shortfallErr *hostTypes.NumVfsShortfallError
+	if !errors.As(err, &shortfallErr) {
+		return 0
+	}
+	return shortfallErr.NumVfs - shortfallErr.CreatedVfs
+}
+
+// aggregateSyncStatus returns the sync status of the node derived from the sync status of the PFs
+func aggregateSyncStatus(statuses sriovnetworkv1.InterfaceSyncStatuses) string {
+	result := consts.SyncStatusSucceeded
+	for _, ifaceStatus := range statuses {
+		switch ifaceStatus.State {
+		case consts.SyncStatusFailed:
+			return consts.SyncStatusFailed
+		case consts.SyncStatusInProgress, consts.SyncStatusPaused:
+			result = ifaceStatus.State
+		}
+	}
+	return result
+}
+
 // recordStatusChangeEvent sends event in case oldStatus differs from newStatus
 func (w *NodeStateStatusWriter) recordStatusChangeEvent(oldStatus, newStatus, lastError string) {
 	if oldStatus != newStatus {
@@ -240,7 +361,7 @@ func (w *NodeStateStatusWriter) getNodeState() (*sriovnetworkv1.SriovNetworkNode
 
 func (w *NodeStateStatusWriter) writeCheckpointFile(ns *sriovnetworkv1.SriovNetworkNodeState) error {
 	configdir := filepath.Join(vars.Destdir, CheckpointFileName)
-	file, err := os.OpenFile(configdir, os.O_RDWR|os.O_CREATE, 0644)
+	file, err := os.OpenFile(configdir, os.O_RDONLY|os.O_CREATE, 0644)
 	if err != nil {
 		return err
 	}
@@ -249,13 +370,11 @@ func (w *NodeStateStatusWriter) writeCheckpointFile(ns *sriovnetworkv1.SriovNetw
 	if err = json.NewDecoder(file).Decode(&sriovnetworkv1.InitialState); err != nil {
 		log.Log.V(2).Error(err, "writeCheckpointFile(): fail to decode, writing new file instead")
 		log.Log.Info("writeCheckpointFile(): write checkpoint file")
-		if err = file.Truncate(0); err != nil {
-			return err
-		}
-		if _, err = file.Seek(0, 0); err != nil {
+		data, err := json.Marshal(*ns)
+		if err != nil {
 			return err
 		}
-		if err = json.NewEncoder(file).Encode(*ns); err != nil {
+		if err = fileutil.WriteFileAtomic(configdir, append(data, '\n'), 0644); err != nil {
 			return err
 		}
 		sriovnetworkv1.InitialState = *ns
@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+var _ = Describe("NodeStateStatusWriter", func() {
+	Context("interfaceSyncStatuses", func() {
+		var nodeState *sriovnetworkv1.SriovNetworkNodeState
+
+		BeforeEach(func() {
+			nodeState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:d8:00.0"}, {PciAddress: "0000:d8:00.1"}},
+				},
+			}
+		})
+
+		states := func(statuses sriovnetworkv1.InterfaceSyncStatuses) map[string]string {
+			result := map[string]string{}
+			for _, s := range statuses {
+				result[s.PciAddress] = s.State
+			}
+			return result
+		}
+
+		It("should report the failed PFs", func() {
+			syncErr := fmt.Errorf("cannot configure sriov interfaces: %w",
+				&hostTypes.InterfaceSyncError{PciAddress: "0000:d8:00.1", Err: fmt.Errorf("test error")})
+			statuses := interfaceSyncStatuses(nodeState, Message{
+				syncStatus: consts.SyncStatusFailed, lastSyncError: syncErr.Error(), syncError: syncErr})
+			Expect(states(statuses)).To(Equal(map[string]string{
+				"0000:d8:00.0": consts.SyncStatusSucceeded,
+				"0000:d8:00.1": consts.SyncStatusFailed,
+			}))
+			Expect(statuses[0].Message).To(BeEmpty())
+			Expect(statuses[1].Message).To(Equal("test error"))
+			Expect(aggregateSyncStatus(statuses)).To(Equal(consts.SyncStatusFailed))
+		})
+
+		It("should report the devlink parameters which failed to be set", func() {
+			syncErr := fmt.Errorf("cannot configure sriov interfaces: %w",
+				&hostTypes.InterfaceSyncError{PciAddress: "0000:d8:00.0", Err: errors.Join(
+					&hostTypes.DevlinkParamError{Param: "enable_roce", Value: "false", Err: fmt.Errorf("operation not supported")},
+					&hostTypes.DevlinkParamError{Param: "flow_steering_mode", Value: "dmfs", Err: fmt.Errorf("the device reports value \"smfs\" after the update")},
+				)})
+			statuses := interfaceSyncStatuses(nodeState, Message{
+				syncStatus: consts.SyncStatusFailed, lastSyncError: syncErr.Error(), syncError: syncErr})
+			Expect(statuses[0].State).To(Equal(consts.SyncStatusFailed))
+			Expect(statuses[0].DevlinkParamErrors).To(Equal(map[string]string{
+				"enable_roce":        "operation not supported",
+				"flow_steering_mode": "the device reports value \"smfs\" after the update",
+			}))
+			Expect(statuses[1].DevlinkParamErrors).To(BeNil())
+		})
+
+		It("should report the VFs the device failed to create", func() {
+			syncErr := fmt.Errorf("cannot configure sriov interfaces: %w",
+				&hostTypes.InterfaceSyncError{PciAddress: "0000:d8:00.1", Err: &hostTypes.NumVfsShortfallError{
+					PciAddress: "0000:d8:00.1", NumVfs: 64, CreatedVfs: 40, Err: fmt.Errorf("input/output error")}})
+			statuses := interfaceSyncStatuses(nodeState, Message{
+				syncStatus: consts.SyncStatusFailed, lastSyncError: syncErr.Error(), syncError: syncErr})
+			Expect(statuses[0].NumVfsShortfall).To(BeZero())
+			Expect(statuses[1].State).To(Equal(consts.SyncStatusFailed))
+			Expect(statuses[1].NumVfsShortfall).To(Equal(24))
+			Expect(statuses[1].Message).To(Equal("created 40 VFs out of the 64 requested VFs: input/output error"))
+		})
+
+		It("should fail all the PFs if the error is not related to a PF", func() {
+			statuses := interfaceSyncStatuses(nodeState, Message{
+				syncStatus: consts.SyncStatusFailed, lastSyncError: "test error", syncError: fmt.Errorf("test error")})
+			Expect(states(statuses)).To(Equal(map[string]string{
+				"0000:d8:00.0": consts.SyncStatusFailed,
+				"0000:d8:00.1": consts.SyncStatusFailed,
+			}))
+			Expect(statuses[0].Message).To(Equal("test error"))
+		})
+
+		It("should keep the sync time of the PFs which state didn't change", func() {
+			lastSyncTime := metav1.NewTime(time.Now().Add(-time.Hour))
+			nodeState.Status.InterfaceSyncStatuses = sriovnetworkv1.InterfaceSyncStatuses{
+				{PciAddress: "0000:d8:00.0", State: consts.SyncStatusSucceeded, LastSyncTime: lastSyncTime},
+				{PciAddress: "0000:d8:00.1", State: consts.SyncStatusInProgress, LastSyncTime: lastSyncTime},
+			}
+			statuses := interfaceSyncStatuses(nodeState, Message{syncStatus: consts.SyncStatusSucceeded})
+			Expect(states(statuses)).To(Equal(map[string]string{
+				"0000:d8:00.0": consts.SyncStatusSucceeded,
+				"0000:d8:00.1": consts.SyncStatusSucceeded,
+			}))
+			Expect(statuses[0].LastSyncTime).To(Equal(lastSyncTime))
+			Expect(statuses[1].LastSyncTime.After(lastSyncTime.Time)).To(BeTrue())
+			Expect(aggregateSyncStatus(statuses)).To(Equal(consts.SyncStatusSucceeded))
+		})
+
+		It("should report the VFs bound to vfio-pci whose IOMMU group is not usable", func() {
+			origCheck := checkVFIOGroup
+			DeferCleanup(func() { checkVFIOGroup = origCheck })
+			checkVFIOGroup = func(pciAddr string) (int, error) {
+				if pciAddr == "0000:d8:02.1" {
+					return 42, &utils.VFIOGroupError{PciAddress: pciAddr, Group: 42, Conflicts: []string{"0000:d8:00.0"}}
+				}
+				return 43, nil
+			}
+			nodeState.Status.Interfaces = sriovnetworkv1.InterfaceExts{{
+				PciAddress: "0000:d8:00.0",
+				VFs: []sriovnetworkv1.VirtualFunction{
+					{PciAddress: "0000:d8:02.0", Driver: "vfio-pci"},
+					{PciAddress: "0000:d8:02.1", Driver: "vfio-pci"},
+					{PciAddress: "0000:d8:02.2", Driver: "iavf"},
+				},
+			}}
+			statuses := interfaceSyncStatuses(nodeState, Message{syncStatus: consts.SyncStatusSucceeded})
+			Expect(statuses[0].State).To(Equal(consts.SyncStatusSucceeded))
+			Expect(statuses[0].Warnings).To(Equal([]string{"IOMMU group 42 of device 0000:d8:02.1 is not usable, " +
+				"devices 0000:d8:00.0 of the group are not bound to vfio-pci or pci-stub"}))
+			Expect(statuses[1].Warnings).To(BeEmpty())
+		})
+
+		It("should derive the node sync status", func() {
+			Expect(aggregateSyncStatus(sriovnetworkv1.InterfaceSyncStatuses{
+				{State: consts.SyncStatusSucceeded}, {State: consts.SyncStatusInProgress},
+			})).To(Equal(consts.SyncStatusInProgress))
+		})
+
+		It("should report the paused PFs", func() {
+			statuses := interfaceSyncStatuses(nodeState, Message{
+				syncStatus: consts.SyncStatusPaused, lastSyncError: "reconciliation is paused"})
+			Expect(states(statuses)).To(Equal(map[string]string{
+				"0000:d8:00.0": consts.SyncStatusPaused,
+				"0000:d8:00.1": consts.SyncStatusPaused,
+			}))
+			Expect(aggregateSyncStatus(statuses)).To(Equal(consts.SyncStatusPaused))
+		})
+	})
+})
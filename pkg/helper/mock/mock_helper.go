@@ -223,6 +223,34 @@ func (mr *MockHostHelpersInterfaceMockRecorder) ConfigureBridges(bridgesSpec, br
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureBridges", reflect.TypeOf((*MockHostHelpersInterface)(nil).ConfigureBridges), bridgesSpec, bridgesStatus)
 }
 
+// ConfigureEncapOffload mocks base method.
+func (m *MockHostHelpersInterface) ConfigureEncapOffload(ifaceName string, vxlan, geneve bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureEncapOffload", ifaceName, vxlan, geneve)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureEncapOffload indicates an expected call of ConfigureEncapOffload.
+func (mr *MockHostHelpersInterfaceMockRecorder) ConfigureEncapOffload(ifaceName, vxlan, geneve interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureEncapOffload", reflect.TypeOf((*MockHostHelpersInterface)(nil).ConfigureEncapOffload), ifaceName, vxlan, geneve)
+}
+
+// ConfigureModprobeBlacklist mocks base method.
+func (m *MockHostHelpersInterface) ConfigureModprobeBlacklist(vfioDeviceIDs, pfDrivers []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureModprobeBlacklist", vfioDeviceIDs, pfDrivers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureModprobeBlacklist indicates an expected call of ConfigureModprobeBlacklist.
+func (mr *MockHostHelpersInterfaceMockRecorder) ConfigureModprobeBlacklist(vfioDeviceIDs, pfDrivers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureModprobeBlacklist", reflect.TypeOf((*MockHostHelpersInterface)(nil).ConfigureModprobeBlacklist), vfioDeviceIDs, pfDrivers)
+}
+
 // ConfigureVfGUID mocks base method.
 func (m *MockHostHelpersInterface) ConfigureVfGUID(vfAddr, pfAddr string, vfID int, pfLink netlink.Link) error {
 	m.ctrl.T.Helper()
@@ -237,6 +265,34 @@ func (mr *MockHostHelpersInterfaceMockRecorder) ConfigureVfGUID(vfAddr, pfAddr,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureVfGUID", reflect.TypeOf((*MockHostHelpersInterface)(nil).ConfigureVfGUID), vfAddr, pfAddr, vfID, pfLink)
 }
 
+// ConfigureVfQoS mocks base method.
+func (m *MockHostHelpersInterface) ConfigureVfQoS(pfName string, vfID int, dscp, egressBandwidthMbps int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureVfQoS", pfName, vfID, dscp, egressBandwidthMbps)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureVfQoS indicates an expected call of ConfigureVfQoS.
+func (mr *MockHostHelpersInterfaceMockRecorder) ConfigureVfQoS(pfName, vfID, dscp, egressBandwidthMbps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureVfQoS", reflect.TypeOf((*MockHostHelpersInterface)(nil).ConfigureVfQoS), pfName, vfID, dscp, egressBandwidthMbps)
+}
+
+// CreateCharDevice mocks base method.
+func (m *MockHostHelpersInterface) CreateCharDevice(major, minor uint32, path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCharDevice", major, minor, path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCharDevice indicates an expected call of CreateCharDevice.
+func (mr *MockHostHelpersInterfaceMockRecorder) CreateCharDevice(major, minor, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCharDevice", reflect.TypeOf((*MockHostHelpersInterface)(nil).CreateCharDevice), major, minor, path)
+}
+
 // CreateVDPADevice mocks base method.
 func (m *MockHostHelpersInterface) CreateVDPADevice(pciAddr, vdpaType string) error {
 	m.ctrl.T.Helper()
@@ -279,6 +335,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) DetachInterfaceFromManagedBridge
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachInterfaceFromManagedBridge", reflect.TypeOf((*MockHostHelpersInterface)(nil).DetachInterfaceFromManagedBridge), pciAddr)
 }
 
+// DisableService mocks base method.
+func (m *MockHostHelpersInterface) DisableService(service *types.Service) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableService", service)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableService indicates an expected call of DisableService.
+func (mr *MockHostHelpersInterfaceMockRecorder) DisableService(service interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableService", reflect.TypeOf((*MockHostHelpersInterface)(nil).DisableService), service)
+}
+
 // DiscoverBridges mocks base method.
 func (m *MockHostHelpersInterface) DiscoverBridges() (v1.Bridges, error) {
 	m.ctrl.T.Helper()
@@ -351,6 +421,34 @@ func (mr *MockHostHelpersInterfaceMockRecorder) EnableService(service interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableService", reflect.TypeOf((*MockHostHelpersInterface)(nil).EnableService), service)
 }
 
+// EnsureVhostNet mocks base method.
+func (m *MockHostHelpersInterface) EnsureVhostNet() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureVhostNet")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureVhostNet indicates an expected call of EnsureVhostNet.
+func (mr *MockHostHelpersInterfaceMockRecorder) EnsureVhostNet() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureVhostNet", reflect.TypeOf((*MockHostHelpersInterface)(nil).EnsureVhostNet))
+}
+
+// GetArchitecture mocks base method.
+func (m *MockHostHelpersInterface) GetArchitecture() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchitecture")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetArchitecture indicates an expected call of GetArchitecture.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetArchitecture() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchitecture", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetArchitecture))
+}
+
 // GetCheckPointNodeState mocks base method.
 func (m *MockHostHelpersInterface) GetCheckPointNodeState() (*v1.SriovNetworkNodeState, error) {
 	m.ctrl.T.Helper()
@@ -381,6 +479,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetCurrentKernelArgs() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentKernelArgs", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetCurrentKernelArgs))
 }
 
+// GetDeviceNumaNode mocks base method.
+func (m *MockHostHelpersInterface) GetDeviceNumaNode(pciAddr string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceNumaNode", pciAddr)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceNumaNode indicates an expected call of GetDeviceNumaNode.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetDeviceNumaNode(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceNumaNode", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetDeviceNumaNode), pciAddr)
+}
+
 // GetDevlinkDeviceParam mocks base method.
 func (m *MockHostHelpersInterface) GetDevlinkDeviceParam(pciAddr, paramName string) (string, error) {
 	m.ctrl.T.Helper()
@@ -396,6 +509,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetDevlinkDeviceParam(pciAddr, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDevlinkDeviceParam", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetDevlinkDeviceParam), pciAddr, paramName)
 }
 
+// GetDistroInfo mocks base method.
+func (m *MockHostHelpersInterface) GetDistroInfo() (*types.DistroInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDistroInfo")
+	ret0, _ := ret[0].(*types.DistroInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDistroInfo indicates an expected call of GetDistroInfo.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetDistroInfo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDistroInfo", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetDistroInfo))
+}
+
 // GetDriverByBusAndDevice mocks base method.
 func (m *MockHostHelpersInterface) GetDriverByBusAndDevice(bus, device string) (string, error) {
 	m.ctrl.T.Helper()
@@ -411,6 +539,37 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetDriverByBusAndDevice(bus, dev
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverByBusAndDevice", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetDriverByBusAndDevice), bus, device)
 }
 
+// GetEncapOffloadState mocks base method.
+func (m *MockHostHelpersInterface) GetEncapOffloadState(ifaceName string) (bool, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEncapOffloadState", ifaceName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEncapOffloadState indicates an expected call of GetEncapOffloadState.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetEncapOffloadState(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEncapOffloadState", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetEncapOffloadState), ifaceName)
+}
+
+// GetHostFacts mocks base method.
+func (m *MockHostHelpersInterface) GetHostFacts() (*v1.HostFacts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHostFacts")
+	ret0, _ := ret[0].(*v1.HostFacts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHostFacts indicates an expected call of GetHostFacts.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetHostFacts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostFacts", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetHostFacts))
+}
+
 // GetInterfaceIndex mocks base method.
 func (m *MockHostHelpersInterface) GetInterfaceIndex(pciAddr string) (int, error) {
 	m.ctrl.T.Helper()
@@ -426,6 +585,36 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetInterfaceIndex(pciAddr interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterfaceIndex", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetInterfaceIndex), pciAddr)
 }
 
+// GetKernelArgsBackend mocks base method.
+func (m *MockHostHelpersInterface) GetKernelArgsBackend() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKernelArgsBackend")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKernelArgsBackend indicates an expected call of GetKernelArgsBackend.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetKernelArgsBackend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKernelArgsBackend", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetKernelArgsBackend))
+}
+
+// GetKernelVersion mocks base method.
+func (m *MockHostHelpersInterface) GetKernelVersion() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKernelVersion")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKernelVersion indicates an expected call of GetKernelVersion.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetKernelVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKernelVersion", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetKernelVersion))
+}
+
 // GetLinkType mocks base method.
 func (m *MockHostHelpersInterface) GetLinkType(name string) string {
 	m.ctrl.T.Helper()
@@ -471,6 +660,34 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetMlxNicFwData(pciAddress inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMlxNicFwData", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetMlxNicFwData), pciAddress)
 }
 
+// GetNetDevBondMaster mocks base method.
+func (m *MockHostHelpersInterface) GetNetDevBondMaster(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevBondMaster", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevBondMaster indicates an expected call of GetNetDevBondMaster.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevBondMaster(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevBondMaster", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevBondMaster), name)
+}
+
+// GetNetDevFirmwareVersion mocks base method.
+func (m *MockHostHelpersInterface) GetNetDevFirmwareVersion(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevFirmwareVersion", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevFirmwareVersion indicates an expected call of GetNetDevFirmwareVersion.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetNetDevFirmwareVersion(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevFirmwareVersion", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetNetDevFirmwareVersion), name)
+}
+
 // GetNetDevLinkAdminState mocks base method.
 func (m *MockHostHelpersInterface) GetNetDevLinkAdminState(ifaceName string) string {
 	m.ctrl.T.Helper()
@@ -570,6 +787,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetPciAddressFromInterfaceName(i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPciAddressFromInterfaceName", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetPciAddressFromInterfaceName), interfaceName)
 }
 
+// GetPfSettings mocks base method.
+func (m *MockHostHelpersInterface) GetPfSettings(ifaceName string) (*v1.PfSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPfSettings", ifaceName)
+	ret0, _ := ret[0].(*v1.PfSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPfSettings indicates an expected call of GetPfSettings.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetPfSettings(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPfSettings", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetPfSettings), ifaceName)
+}
+
 // GetPhysPortName mocks base method.
 func (m *MockHostHelpersInterface) GetPhysPortName(name string) (string, error) {
 	m.ctrl.T.Helper()
@@ -600,6 +832,50 @@ func (mr *MockHostHelpersInterfaceMockRecorder) GetPhysSwitchID(name interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhysSwitchID", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetPhysSwitchID), name)
 }
 
+// GetRDMASubsystemNetnsMode mocks base method.
+func (m *MockHostHelpersInterface) GetRDMASubsystemNetnsMode() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRDMASubsystemNetnsMode")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRDMASubsystemNetnsMode indicates an expected call of GetRDMASubsystemNetnsMode.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetRDMASubsystemNetnsMode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRDMASubsystemNetnsMode", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetRDMASubsystemNetnsMode))
+}
+
+// GetVFRSSConfig mocks base method.
+func (m *MockHostHelpersInterface) GetVFRSSConfig(pfName string, vfID int) (*types.RSSConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVFRSSConfig", pfName, vfID)
+	ret0, _ := ret[0].(*types.RSSConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVFRSSConfig indicates an expected call of GetVFRSSConfig.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetVFRSSConfig(pfName, vfID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVFRSSConfig", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetVFRSSConfig), pfName, vfID)
+}
+
+// GetVDPADeviceName mocks base method.
+func (m *MockHostHelpersInterface) GetVDPADeviceName(pciAddr string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVDPADeviceName", pciAddr)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetVDPADeviceName indicates an expected call of GetVDPADeviceName.
+func (mr *MockHostHelpersInterfaceMockRecorder) GetVDPADeviceName(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVDPADeviceName", reflect.TypeOf((*MockHostHelpersInterface)(nil).GetVDPADeviceName), pciAddr)
+}
+
 // HasDriver mocks base method.
 func (m *MockHostHelpersInterface) HasDriver(pciAddr string) (bool, string) {
 	m.ctrl.T.Helper()
@@ -615,6 +891,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) HasDriver(pciAddr interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDriver", reflect.TypeOf((*MockHostHelpersInterface)(nil).HasDriver), pciAddr)
 }
 
+// IsDevlinkDeviceParamPermanent mocks base method.
+func (m *MockHostHelpersInterface) IsDevlinkDeviceParamPermanent(pciAddr, paramName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDevlinkDeviceParamPermanent", pciAddr, paramName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDevlinkDeviceParamPermanent indicates an expected call of IsDevlinkDeviceParamPermanent.
+func (mr *MockHostHelpersInterfaceMockRecorder) IsDevlinkDeviceParamPermanent(pciAddr, paramName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDevlinkDeviceParamPermanent", reflect.TypeOf((*MockHostHelpersInterface)(nil).IsDevlinkDeviceParamPermanent), pciAddr, paramName)
+}
+
 // IsKernelArgsSet mocks base method.
 func (m *MockHostHelpersInterface) IsKernelArgsSet(cmdLine, karg string) bool {
 	m.ctrl.T.Helper()
@@ -658,6 +949,22 @@ func (mr *MockHostHelpersInterfaceMockRecorder) IsKernelModuleLoaded(name interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsKernelModuleLoaded", reflect.TypeOf((*MockHostHelpersInterface)(nil).IsKernelModuleLoaded), name)
 }
 
+// IsPartOfBond mocks base method.
+func (m *MockHostHelpersInterface) IsPartOfBond(pciAddress string) (bool, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPartOfBond", pciAddress)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IsPartOfBond indicates an expected call of IsPartOfBond.
+func (mr *MockHostHelpersInterfaceMockRecorder) IsPartOfBond(pciAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPartOfBond", reflect.TypeOf((*MockHostHelpersInterface)(nil).IsPartOfBond), pciAddress)
+}
+
 // IsServiceEnabled mocks base method.
 func (m *MockHostHelpersInterface) IsServiceEnabled(servicePath string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -702,6 +1009,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) IsSwitchdev(name interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSwitchdev", reflect.TypeOf((*MockHostHelpersInterface)(nil).IsSwitchdev), name)
 }
 
+// ListVFInterrupts mocks base method.
+func (m *MockHostHelpersInterface) ListVFInterrupts(pciAddress string) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVFInterrupts", pciAddress)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVFInterrupts indicates an expected call of ListVFInterrupts.
+func (mr *MockHostHelpersInterfaceMockRecorder) ListVFInterrupts(pciAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVFInterrupts", reflect.TypeOf((*MockHostHelpersInterface)(nil).ListVFInterrupts), pciAddress)
+}
+
 // LoadKernelModule mocks base method.
 func (m *MockHostHelpersInterface) LoadKernelModule(name string, args ...string) error {
 	m.ctrl.T.Helper()
@@ -882,6 +1204,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) RebindVfToDefaultDriver(pciAddr
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebindVfToDefaultDriver", reflect.TypeOf((*MockHostHelpersInterface)(nil).RebindVfToDefaultDriver), pciAddr)
 }
 
+// ReconcileVfAttributes mocks base method.
+func (m *MockHostHelpersInterface) ReconcileVfAttributes(iface *v1.Interface) ([]types.VfAttributeCorrection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileVfAttributes", iface)
+	ret0, _ := ret[0].([]types.VfAttributeCorrection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileVfAttributes indicates an expected call of ReconcileVfAttributes.
+func (mr *MockHostHelpersInterfaceMockRecorder) ReconcileVfAttributes(iface interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileVfAttributes", reflect.TypeOf((*MockHostHelpersInterface)(nil).ReconcileVfAttributes), iface)
+}
+
 // RemoveDisableNMUdevRule mocks base method.
 func (m *MockHostHelpersInterface) RemoveDisableNMUdevRule(pfPciAddress string) error {
 	m.ctrl.T.Helper()
@@ -938,6 +1275,21 @@ func (mr *MockHostHelpersInterfaceMockRecorder) RemoveVfRepresentorUdevRule(pfPc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveVfRepresentorUdevRule", reflect.TypeOf((*MockHostHelpersInterface)(nil).RemoveVfRepresentorUdevRule), pfPciAddress)
 }
 
+// RenderSriovConfigServices mocks base method.
+func (m *MockHostHelpersInterface) RenderSriovConfigServices(logLevel int) ([]*types.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderSriovConfigServices", logLevel)
+	ret0, _ := ret[0].([]*types.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderSriovConfigServices indicates an expected call of RenderSriovConfigServices.
+func (mr *MockHostHelpersInterfaceMockRecorder) RenderSriovConfigServices(logLevel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderSriovConfigServices", reflect.TypeOf((*MockHostHelpersInterface)(nil).RenderSriovConfigServices), logLevel)
+}
+
 // ResetSriovDevice mocks base method.
 func (m *MockHostHelpersInterface) ResetSriovDevice(ifaceStatus v1.InterfaceExt) error {
 	m.ctrl.T.Helper()
@@ -1001,6 +1353,35 @@ func (mr *MockHostHelpersInterfaceMockRecorder) SetDevlinkDeviceParam(pciAddr, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDevlinkDeviceParam", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetDevlinkDeviceParam), pciAddr, paramName, value)
 }
 
+// SetHugepages mocks base method.
+func (m *MockHostHelpersInterface) SetHugepages(numaNode int, size string, count int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHugepages", numaNode, size, count)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetHugepages indicates an expected call of SetHugepages.
+func (mr *MockHostHelpersInterfaceMockRecorder) SetHugepages(numaNode, size, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHugepages", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetHugepages), numaNode, size, count)
+}
+
+// SetNetDevLinkAdminState mocks base method.
+func (m *MockHostHelpersInterface) SetNetDevLinkAdminState(ifaceName, state string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNetDevLinkAdminState", ifaceName, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNetDevLinkAdminState indicates an expected call of SetNetDevLinkAdminState.
+func (mr *MockHostHelpersInterfaceMockRecorder) SetNetDevLinkAdminState(ifaceName, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNetDevLinkAdminState", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetNetDevLinkAdminState), ifaceName, state)
+}
+
 // SetNetdevMTU mocks base method.
 func (m *MockHostHelpersInterface) SetNetdevMTU(pciAddr string, mtu int) error {
 	m.ctrl.T.Helper()
@@ -1029,6 +1410,34 @@ func (mr *MockHostHelpersInterfaceMockRecorder) SetNicSriovMode(pciAddr, mode in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNicSriovMode", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetNicSriovMode), pciAddr, mode)
 }
 
+// SetPfSettings mocks base method.
+func (m *MockHostHelpersInterface) SetPfSettings(ifaceName string, settings *v1.PfSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPfSettings", ifaceName, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPfSettings indicates an expected call of SetPfSettings.
+func (mr *MockHostHelpersInterfaceMockRecorder) SetPfSettings(ifaceName, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPfSettings", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetPfSettings), ifaceName, settings)
+}
+
+// SetRDMASubsystemNetnsMode mocks base method.
+func (m *MockHostHelpersInterface) SetRDMASubsystemNetnsMode(mode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRDMASubsystemNetnsMode", mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRDMASubsystemNetnsMode indicates an expected call of SetRDMASubsystemNetnsMode.
+func (mr *MockHostHelpersInterfaceMockRecorder) SetRDMASubsystemNetnsMode(mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRDMASubsystemNetnsMode", reflect.TypeOf((*MockHostHelpersInterface)(nil).SetRDMASubsystemNetnsMode), mode)
+}
+
 // SetSriovNumVfs mocks base method.
 func (m *MockHostHelpersInterface) SetSriovNumVfs(pciAddr string, numVfs int) error {
 	m.ctrl.T.Helper()
@@ -1151,6 +1560,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) UnbindDriverIfNeeded(pciAddr, is
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbindDriverIfNeeded", reflect.TypeOf((*MockHostHelpersInterface)(nil).UnbindDriverIfNeeded), pciAddr, isRdma)
 }
 
+// UnloadKernelModule mocks base method.
+func (m *MockHostHelpersInterface) UnloadKernelModule(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnloadKernelModule", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnloadKernelModule indicates an expected call of UnloadKernelModule.
+func (mr *MockHostHelpersInterfaceMockRecorder) UnloadKernelModule(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnloadKernelModule", reflect.TypeOf((*MockHostHelpersInterface)(nil).UnloadKernelModule), name)
+}
+
 // UpdateSystemService mocks base method.
 func (m *MockHostHelpersInterface) UpdateSystemService(serviceObj *types.Service) error {
 	m.ctrl.T.Helper()
@@ -1165,6 +1588,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) UpdateSystemService(serviceObj i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSystemService", reflect.TypeOf((*MockHostHelpersInterface)(nil).UpdateSystemService), serviceObj)
 }
 
+// UploadFirmware mocks base method.
+func (m *MockHostHelpersInterface) UploadFirmware(pciAddress, fwPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadFirmware", pciAddress, fwPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadFirmware indicates an expected call of UploadFirmware.
+func (mr *MockHostHelpersInterfaceMockRecorder) UploadFirmware(pciAddress, fwPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFirmware", reflect.TypeOf((*MockHostHelpersInterface)(nil).UploadFirmware), pciAddress, fwPath)
+}
+
 // VFIsReady mocks base method.
 func (m *MockHostHelpersInterface) VFIsReady(pciAddr string) (netlink.Link, error) {
 	m.ctrl.T.Helper()
@@ -1180,6 +1617,20 @@ func (mr *MockHostHelpersInterfaceMockRecorder) VFIsReady(pciAddr interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VFIsReady", reflect.TypeOf((*MockHostHelpersInterface)(nil).VFIsReady), pciAddr)
 }
 
+// VerifyDriverBinding mocks base method.
+func (m *MockHostHelpersInterface) VerifyDriverBinding(pciAddress, expectedDriver string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyDriverBinding", pciAddress, expectedDriver)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyDriverBinding indicates an expected call of VerifyDriverBinding.
+func (mr *MockHostHelpersInterfaceMockRecorder) VerifyDriverBinding(pciAddress, expectedDriver interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyDriverBinding", reflect.TypeOf((*MockHostHelpersInterface)(nil).VerifyDriverBinding), pciAddress, expectedDriver)
+}
+
 // WriteCheckpointFile mocks base method.
 func (m *MockHostHelpersInterface) WriteCheckpointFile(arg0 *v1.SriovNetworkNodeState) error {
 	m.ctrl.T.Helper()
@@ -1193,3 +1644,17 @@ func (mr *MockHostHelpersInterfaceMockRecorder) WriteCheckpointFile(arg0 interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteCheckpointFile", reflect.TypeOf((*MockHostHelpersInterface)(nil).WriteCheckpointFile), arg0)
 }
+
+// WriteModulesLoadConf mocks base method.
+func (m *MockHostHelpersInterface) WriteModulesLoadConf(path string, modules []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteModulesLoadConf", path, modules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteModulesLoadConf indicates an expected call of WriteModulesLoadConf.
+func (mr *MockHostHelpersInterfaceMockRecorder) WriteModulesLoadConf(path, modules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteModulesLoadConf", reflect.TypeOf((*MockHostHelpersInterface)(nil).WriteModulesLoadConf), path, modules)
+}
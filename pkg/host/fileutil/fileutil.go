@@ -0,0 +1,69 @@
+package fileutil
+
+import (
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// syncFile flushes content of the file to the disk, overridden in unit-tests to simulate interruptions
+var syncFile = func(f *os.File) error {
+	return f.Sync()
+}
+
+// WriteFileAtomic writes data to the file at path, the content is first written and flushed to
+// a temporary file in the same directory which then replaces the target file. This way the file at path
+// contains either the old or the new content even if the node crashes in the middle of the write.
+// The permissions are applied the same way as by os.WriteFile.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := createTemp(dir, filepath.Base(path), perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = syncFile(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// creates a temporary file next to the target file, os.CreateTemp is not used
+// because it ignores the requested permissions
+func createTemp(dir, name string, perm os.FileMode) (*os.File, error) {
+	for i := 0; i < 10000; i++ {
+		tmpPath := filepath.Join(dir, "."+name+".tmp"+strconv.FormatUint(uint64(rand.Uint32()), 10))
+		f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &os.PathError{Op: "createtemp", Path: filepath.Join(dir, name), Err: os.ErrExist}
+}
+
+// syncDir flushes the directory entry of the renamed file to the disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,82 @@
+package fileutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriteFileAtomic", func() {
+	var (
+		dir  string
+		path string
+	)
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		path = filepath.Join(dir, "test.conf")
+	})
+
+	It("should create the file", func() {
+		Expect(WriteFileAtomic(path, []byte("content"), 0644)).To(Succeed())
+		Expect(os.ReadFile(path)).To(Equal([]byte("content")))
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm() &^ 0644).To(BeZero())
+	})
+
+	It("should replace the file", func() {
+		Expect(os.WriteFile(path, []byte("old content"), 0644)).To(Succeed())
+		Expect(WriteFileAtomic(path, []byte("new"), 0644)).To(Succeed())
+		Expect(os.ReadFile(path)).To(Equal([]byte("new")))
+		Expect(os.ReadDir(dir)).To(HaveLen(1))
+	})
+
+	It("should keep the old content if the write is interrupted", func() {
+		origSyncFile := syncFile
+		DeferCleanup(func() { syncFile = origSyncFile })
+		syncFile = func(f *os.File) error { return fmt.Errorf("interrupted") }
+
+		Expect(os.WriteFile(path, []byte("old content"), 0644)).To(Succeed())
+		Expect(WriteFileAtomic(path, []byte("new content"), 0644)).To(MatchError("interrupted"))
+		Expect(os.ReadFile(path)).To(Equal([]byte("old content")))
+		// temporary file is removed
+		Expect(os.ReadDir(dir)).To(HaveLen(1))
+	})
+
+	It("should fail if the directory doesn't exist", func() {
+		Expect(WriteFileAtomic(filepath.Join(dir, "missing", "test.conf"), []byte("content"), 0644)).To(HaveOccurred())
+	})
+
+	It("should never expose partial content", func() {
+		contents := [][]byte{bytes.Repeat([]byte("a"), 1<<20), bytes.Repeat([]byte("b"), 1<<19)}
+		Expect(WriteFileAtomic(path, contents[0], 0644)).To(Succeed())
+
+		done := make(chan struct{})
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				Expect(WriteFileAtomic(path, contents[i%2], 0644)).To(Succeed())
+			}
+			close(done)
+		}()
+		for {
+			select {
+			case <-done:
+				wg.Wait()
+				return
+			default:
+			}
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Or(Equal(contents[0]), Equal(contents[1])))
+		}
+	})
+})
@@ -0,0 +1,118 @@
+package distro
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// known distribution IDs for each family
+var distroFamilies = map[string][]string{
+	consts.DistroFamilyRHEL:   {"rhel", "rhcos", "centos", "fedora", "rocky", "almalinux", "ol"},
+	consts.DistroFamilyDebian: {"debian", "ubuntu"},
+	consts.DistroFamilySUSE:   {"suse", "sles", "opensuse", "opensuse-leap", "opensuse-tumbleweed"},
+}
+
+type distro struct{}
+
+func New() types.DistroInterface {
+	return &distro{}
+}
+
+// GetDistroInfo parses os-release file of the host
+func (d *distro) GetDistroInfo() (*types.DistroInfo, error) {
+	var (
+		data []byte
+		err  error
+	)
+	for _, path := range []string{consts.OsReleaseFile, consts.OsReleaseLibFile} {
+		data, err = os.ReadFile(utils.GetHostExtensionPath(path))
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Log.Error(err, "GetDistroInfo(): failed to read os-release file", "path", path)
+			return nil, err
+		}
+	}
+	if err != nil {
+		log.Log.Error(err, "GetDistroInfo(): os-release file not found")
+		return nil, err
+	}
+	info := parseOsRelease(data)
+	log.Log.V(2).Info("GetDistroInfo()", "id", info.ID, "idLike", info.IDLike,
+		"version", info.VersionID, "family", info.Family)
+	return info, nil
+}
+
+// GetKernelArgsBackend returns the tool which should be used to manage kernel arguments on the host,
+// grubby is used if the distribution is not known or can't be detected
+func (d *distro) GetKernelArgsBackend() (string, error) {
+	if _, err := os.Stat(utils.GetHostExtensionPath(consts.OstreeBootedFile)); err == nil {
+		return consts.KernelArgsBackendRpmOstree, nil
+	}
+	info, err := d.GetDistroInfo()
+	if err != nil {
+		log.Log.Info("GetKernelArgsBackend(): failed to detect the distribution, fallback to grubby", "error", err.Error())
+		return consts.KernelArgsBackendGrubby, nil
+	}
+	switch info.Family {
+	case consts.DistroFamilyDebian:
+		return consts.KernelArgsBackendUpdateGrub, nil
+	case consts.DistroFamilyRHEL:
+		return consts.KernelArgsBackendGrubby, nil
+	default:
+		log.Log.Info("GetKernelArgsBackend(): no kernel args backend known for the distribution, fallback to grubby",
+			"id", info.ID, "family", info.Family)
+		return consts.KernelArgsBackendGrubby, nil
+	}
+}
+
+// parses content of the os-release file, see os-release(5)
+func parseOsRelease(data []byte) *types.DistroInfo {
+	info := &types.DistroInfo{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			info.ID = value
+		case "ID_LIKE":
+			info.IDLike = strings.Fields(value)
+		case "VERSION_ID":
+			info.VersionID = value
+		}
+	}
+	info.Family = getDistroFamily(info.ID, info.IDLike)
+	return info
+}
+
+// returns family of the distribution, ID_LIKE is checked if ID is not known
+func getDistroFamily(id string, idLike []string) string {
+	for _, candidate := range append([]string{id}, idLike...) {
+		for family, ids := range distroFamilies {
+			for _, knownID := range ids {
+				if candidate == knownID {
+					return family
+				}
+			}
+		}
+	}
+	return consts.DistroFamilyUnknown
+}
@@ -0,0 +1,113 @@
+package distro
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+const (
+	testRHCOSOsRelease = `NAME="Red Hat Enterprise Linux CoreOS"
+ID="rhcos"
+ID_LIKE="rhel fedora"
+VERSION_ID="4.16"
+`
+	testUbuntuOsRelease = `NAME="Ubuntu"
+ID=ubuntu
+ID_LIKE=debian
+VERSION_ID="22.04"
+`
+	testSLESOsRelease = `NAME="SLES"
+ID="sles"
+ID_LIKE="suse"
+VERSION_ID="15.5"
+`
+	testArchOsRelease = `NAME="Arch Linux"
+ID=arch
+`
+)
+
+var _ = Describe("Distro", func() {
+	var (
+		d types.DistroInterface
+	)
+	BeforeEach(func() {
+		d = New()
+	})
+	Context("GetDistroInfo", func() {
+		It("should parse os-release", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/etc"},
+				Files: map[string][]byte{"/host/etc/os-release": []byte(testRHCOSOsRelease)},
+			})
+			info, err := d.GetDistroInfo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*info).To(Equal(types.DistroInfo{
+				ID:        "rhcos",
+				IDLike:    []string{"rhel", "fedora"},
+				VersionID: "4.16",
+				Family:    consts.DistroFamilyRHEL,
+			}))
+		})
+		It("should fallback to /usr/lib/os-release", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/usr/lib"},
+				Files: map[string][]byte{"/host/usr/lib/os-release": []byte(testUbuntuOsRelease)},
+			})
+			info, err := d.GetDistroInfo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.ID).To(Equal("ubuntu"))
+			Expect(info.Family).To(Equal(consts.DistroFamilyDebian))
+		})
+		It("should fail if os-release not found", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/host/etc"}})
+			_, err := d.GetDistroInfo()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("GetKernelArgsBackend", func() {
+		It("rpm-ostree", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/run"},
+				Files: map[string][]byte{"/host/run/ostree-booted": {}},
+			})
+			Expect(d.GetKernelArgsBackend()).To(Equal(consts.KernelArgsBackendRpmOstree))
+		})
+		It("grubby", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/etc"},
+				Files: map[string][]byte{"/host/etc/os-release": []byte(testRHCOSOsRelease)},
+			})
+			Expect(d.GetKernelArgsBackend()).To(Equal(consts.KernelArgsBackendGrubby))
+		})
+		It("update-grub", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/etc"},
+				Files: map[string][]byte{"/host/etc/os-release": []byte(testUbuntuOsRelease)},
+			})
+			Expect(d.GetKernelArgsBackend()).To(Equal(consts.KernelArgsBackendUpdateGrub))
+		})
+		It("fallback to grubby for SUSE", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/etc"},
+				Files: map[string][]byte{"/host/etc/os-release": []byte(testSLESOsRelease)},
+			})
+			Expect(d.GetKernelArgsBackend()).To(Equal(consts.KernelArgsBackendGrubby))
+		})
+		It("fallback to grubby for unknown distribution", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/etc"},
+				Files: map[string][]byte{"/host/etc/os-release": []byte(testArchOsRelease)},
+			})
+			Expect(d.GetKernelArgsBackend()).To(Equal(consts.KernelArgsBackendGrubby))
+		})
+		It("fallback to grubby if os-release not found", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/host/etc"}})
+			Expect(d.GetKernelArgsBackend()).To(Equal(consts.KernelArgsBackendGrubby))
+		})
+	})
+})
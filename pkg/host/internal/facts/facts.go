@@ -0,0 +1,108 @@
+package facts
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+type facts struct {
+	lock  sync.Mutex
+	cache *sriovnetworkv1.HostFacts
+}
+
+func New() types.FactsInterface {
+	return &facts{}
+}
+
+// GetHostFacts returns hardware facts of the host, the facts are collected once per daemon start.
+// The facts can change only after reboot of the node which also restarts the daemon.
+func (f *facts) GetHostFacts() (*sriovnetworkv1.HostFacts, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.cache != nil {
+		return f.cache.DeepCopy(), nil
+	}
+	cpuVendor, err := getCPUVendor()
+	if err != nil {
+		log.Log.Error(err, "GetHostFacts(): failed to read CPU vendor")
+		return nil, err
+	}
+	hostFacts := &sriovnetworkv1.HostFacts{
+		CPUVendor:          cpuVendor,
+		IOMMUEnabled:       isIOMMUEnabled(),
+		InterruptRemapping: isInterruptRemappingEnabled(),
+		SecureBoot:         isSecureBootEnabled(),
+	}
+	log.Log.V(2).Info("GetHostFacts()", "cpuVendor", hostFacts.CPUVendor, "iommu", hostFacts.IOMMUEnabled,
+		"interruptRemapping", hostFacts.InterruptRemapping, "secureBoot", hostFacts.SecureBoot)
+	f.cache = hostFacts
+	return f.cache.DeepCopy(), nil
+}
+
+// returns vendor_id of the x86 CPUs or ARM if the CPUs report ARM implementer
+func getCPUVendor() (string, error) {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.ProcCPUInfo))
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "vendor_id":
+			return strings.TrimSpace(value), nil
+		case "CPU implementer":
+			return consts.CPUVendorARM, nil
+		}
+	}
+	return "", nil
+}
+
+// IOMMU is active if the kernel created at least one IOMMU group
+func isIOMMUEnabled() bool {
+	groups, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysKernelIommuGroups))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Log.Error(err, "isIOMMUEnabled(): failed to read IOMMU groups")
+		}
+		return false
+	}
+	return len(groups) > 0
+}
+
+// interrupts delivered through the interrupt remapping unit are prefixed with IR- in /proc/interrupts
+func isInterruptRemappingEnabled() bool {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.ProcInterrupts))
+	if err != nil {
+		log.Log.Error(err, "isInterruptRemappingEnabled(): failed to read interrupts")
+		return false
+	}
+	return bytes.Contains(data, []byte(" IR-"))
+}
+
+// the SecureBoot EFI variable contains 4 bytes of attributes followed by the value
+func isSecureBootEnabled() bool {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.EfiSecureBootVar))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Log.Error(err, "isSecureBootEnabled(): failed to read SecureBoot EFI variable")
+		}
+		return false
+	}
+	return len(data) == 5 && data[4] == 1
+}
@@ -0,0 +1,88 @@
+package facts
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+const (
+	testIntelCPUInfo = `processor	: 0
+vendor_id	: GenuineIntel
+cpu family	: 6
+`
+	testArmCPUInfo = `processor	: 0
+BogoMIPS	: 50.00
+CPU implementer	: 0x41
+`
+	testIRInterrupts = `           CPU0       CPU1
+  0:         14          0  IR-IO-APIC    2-edge      timer
+ 24:          0          0  IR-PCI-MSI 65536-edge      ice-0000:d8:00.0:misc
+`
+	testInterrupts = `           CPU0       CPU1
+  0:         14          0   IO-APIC    2-edge      timer
+`
+)
+
+var _ = Describe("Facts", func() {
+	var (
+		f types.FactsInterface
+	)
+	BeforeEach(func() {
+		f = New()
+	})
+	Context("GetHostFacts", func() {
+		It("should collect facts", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/proc", "/sys/kernel/iommu_groups/0", "/sys/firmware/efi/efivars"},
+				Files: map[string][]byte{
+					"/proc/cpuinfo":         []byte(testIntelCPUInfo),
+					"/proc/interrupts":      []byte(testIRInterrupts),
+					consts.EfiSecureBootVar: {0x06, 0x00, 0x00, 0x00, 0x01},
+				},
+			})
+			hostFacts, err := f.GetHostFacts()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*hostFacts).To(Equal(sriovnetworkv1.HostFacts{
+				CPUVendor:          consts.CPUVendorIntel,
+				IOMMUEnabled:       true,
+				InterruptRemapping: true,
+				SecureBoot:         true,
+			}))
+		})
+		It("should report disabled features", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/proc", "/sys/kernel/iommu_groups"},
+				Files: map[string][]byte{
+					"/proc/cpuinfo":    []byte(testArmCPUInfo),
+					"/proc/interrupts": []byte(testInterrupts),
+				},
+			})
+			hostFacts, err := f.GetHostFacts()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*hostFacts).To(Equal(sriovnetworkv1.HostFacts{CPUVendor: consts.CPUVendorARM}))
+		})
+		It("should cache facts", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/proc"},
+				Files: map[string][]byte{"/proc/cpuinfo": []byte(testIntelCPUInfo)},
+			})
+			_, err := f.GetHostFacts()
+			Expect(err).NotTo(HaveOccurred())
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{})
+			hostFacts, err := f.GetHostFacts()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hostFacts.CPUVendor).To(Equal(consts.CPUVendorIntel))
+		})
+		It("should fail if cpuinfo is not readable", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{})
+			_, err := f.GetHostFacts()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
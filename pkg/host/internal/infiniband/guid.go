@@ -48,6 +48,22 @@ func (g GUID) HardwareAddr() net.HardwareAddr {
 	return ha
 }
 
+const (
+	// locallyAdministeredBit marks the derived VF GUIDs as not assigned by the vendor, like the U/L bit of MAC addresses
+	locallyAdministeredBit = GUID(0x02) << (byteBitLen * (guidLength - 1))
+	// vfIndexShift is the position of the 16 bits of the VF index in the derived VF GUIDs, the bytes 3 and 4
+	vfIndexShift = byteBitLen * 3
+	vfIndexMask  = GUID(0xffff) << vfIndexShift
+)
+
+// deriveVFGUID returns the GUID of the VF derived from the GUID of its PF: the vendor OUI and the 3 lowest bytes,
+// which identify the port, are kept, the bytes 3 and 4 are replaced by the VF index + 1 and the locally administered
+// bit is set. The GUID of a VF is the same each time the VF is created and it doesn't conflict with the GUIDs
+// of the VFs of the other PFs nor with the vendor assigned GUIDs.
+func deriveVFGUID(pfGUID GUID, vfID int) GUID {
+	return (pfGUID &^ vfIndexMask) | locallyAdministeredBit | (GUID(vfID+1) << vfIndexShift & vfIndexMask)
+}
+
 func generateRandomGUID() net.HardwareAddr {
 	guid := make(net.HardwareAddr, 8)
 
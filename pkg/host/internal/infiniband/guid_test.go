@@ -26,4 +26,15 @@ var _ = Describe("GUID", func() {
 
 		Expect(guid.HardwareAddr()).To(Equal(net.HardwareAddr{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x08}))
 	})
+	It("should derive distinct VF GUIDs from the PF GUIDs", func() {
+		pf0, err := ParseGUID("0c:42:a1:03:00:16:05:4c")
+		Expect(err).NotTo(HaveOccurred())
+		pf1, err := ParseGUID("0c:42:a1:03:00:16:05:4d")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(deriveVFGUID(pf0, 0).String()).To(Equal("0e:42:a1:00:01:16:05:4c"))
+		Expect(deriveVFGUID(pf0, 255).String()).To(Equal("0e:42:a1:01:00:16:05:4c"))
+		Expect(deriveVFGUID(pf1, 0).String()).To(Equal("0e:42:a1:00:01:16:05:4d"))
+		Expect(deriveVFGUID(pf0, 0)).To(Equal(deriveVFGUID(pf0, 0)))
+	})
 })
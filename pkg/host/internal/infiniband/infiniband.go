@@ -21,27 +21,29 @@ func New(netlinkLib netlinkLibPkg.NetlinkLib, kernelHelper types.KernelInterface
 		// if config file doesn't exist, fallback to the random GUID generation
 		if errors.Is(err, fs.ErrNotExist) {
 			log.Log.Info("infiniband.New(): ib guid config doesn't exist, continuing without it", "config path", consts.InfinibandGUIDConfigFilePath)
-			return &infiniband{guidPool: nil, netlinkLib: netlinkLib, kernelHelper: kernelHelper}, nil
+			return &infiniband{guidPool: nil, netlinkLib: netlinkLib, kernelHelper: kernelHelper, networkHelper: networkHelper}, nil
 		}
 
 		return nil, fmt.Errorf("failed to create the ib guid pool: %w", err)
 	}
 
-	return &infiniband{guidPool: guidPool, netlinkLib: netlinkLib, kernelHelper: kernelHelper}, nil
+	return &infiniband{guidPool: guidPool, netlinkLib: netlinkLib, kernelHelper: kernelHelper, networkHelper: networkHelper}, nil
 }
 
 type infiniband struct {
-	guidPool     ibGUIDPool
-	netlinkLib   netlinkLibPkg.NetlinkLib
-	kernelHelper types.KernelInterface
+	guidPool      ibGUIDPool
+	netlinkLib    netlinkLibPkg.NetlinkLib
+	kernelHelper  types.KernelInterface
+	networkHelper types.NetworkInterface
 }
 
-// ConfigureVfGUID configures and sets a GUID for an IB VF device
+// ConfigureVfGUID configures and sets a GUID for an IB VF device. The GUID is taken from the IB GUID pool
+// if configured, otherwise it is derived from the GUID of the PF so that the VF gets the same GUID when it is
+// created again. A random GUID is used if the GUID of the PF is unknown.
 func (i *infiniband) ConfigureVfGUID(vfAddr string, pfAddr string, vfID int, pfLink netlink.Link) error {
 	log.Log.Info("ConfigureVfGUID(): configure vf guid", "vfAddr", vfAddr, "pfAddr", pfAddr, "vfID", vfID)
 
-	guid := generateRandomGUID()
-
+	var guid net.HardwareAddr
 	if i.guidPool != nil {
 		guidFromPool, err := i.guidPool.GetVFGUID(pfAddr, vfID)
 		if err != nil {
@@ -49,12 +51,30 @@ func (i *infiniband) ConfigureVfGUID(vfAddr string, pfAddr string, vfID int, pfL
 			return err
 		}
 		guid = guidFromPool
+	} else {
+		guid = i.getDerivedVFGUID(pfAddr, vfID)
 	}
 	log.Log.Info("ConfigureVfGUID(): set vf guid", "address", vfAddr, "guid", guid)
 
 	return i.applyVfGUIDToInterface(guid, vfAddr, vfID, pfLink)
 }
 
+// getDerivedVFGUID returns the GUID of the VF derived from the node GUID of the PF, a random GUID if
+// the node GUID of the PF can't be read
+func (i *infiniband) getDerivedVFGUID(pfAddr string, vfID int) net.HardwareAddr {
+	pfGUIDStr := i.networkHelper.GetNetDevNodeGUID(pfAddr)
+	if pfGUIDStr == "" {
+		log.Log.V(2).Info("getDerivedVFGUID(): PF has no node GUID, use a random GUID", "address", pfAddr)
+		return generateRandomGUID()
+	}
+	pfGUID, err := ParseGUID(pfGUIDStr)
+	if err != nil || pfGUID == 0 {
+		log.Log.Info("getDerivedVFGUID(): invalid PF node GUID, use a random GUID", "address", pfAddr, "guid", pfGUIDStr)
+		return generateRandomGUID()
+	}
+	return deriveVFGUID(pfGUID, vfID).HardwareAddr()
+}
+
 func (i *infiniband) applyVfGUIDToInterface(guid net.HardwareAddr, vfAddr string, vfID int, pfLink netlink.Link) error {
 	if err := i.netlinkLib.LinkSetVfNodeGUID(pfLink, vfID, guid); err != nil {
 		return err
@@ -36,6 +36,7 @@ var _ = Describe("infiniband interface implementation", func() {
 	})
 	It("should assign guids if guid pool is nil", func() {
 		netlinkLibMock.EXPECT().LinkList().Return([]netlinkLibPkg.Link{}, nil)
+		hostMock.EXPECT().GetNetDevNodeGUID("0000:d8:00.0").Return("")
 		var generatedGUID string
 		pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
 		netlinkLibMock.EXPECT().LinkSetVfNodeGUID(pfLinkMock, 0, gomock.Any()).DoAndReturn(
@@ -53,6 +54,19 @@ var _ = Describe("infiniband interface implementation", func() {
 		_, err = ParseGUID(generatedGUID)
 		Expect(err).NotTo(HaveOccurred())
 	})
+	It("should derive the guids from the guid of the PF if guid pool is nil", func() {
+		netlinkLibMock.EXPECT().LinkList().Return([]netlinkLibPkg.Link{}, nil)
+		hostMock.EXPECT().GetNetDevNodeGUID("0000:d8:00.0").Return("0c:42:a1:03:00:16:05:4c").Times(2)
+		pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+		expectedGUID := net.HardwareAddr{0x0e, 0x42, 0xa1, 0x00, 0x06, 0x16, 0x05, 0x4c}
+		netlinkLibMock.EXPECT().LinkSetVfNodeGUID(pfLinkMock, 5, expectedGUID).Return(nil).Times(2)
+		netlinkLibMock.EXPECT().LinkSetVfPortGUID(pfLinkMock, 5, expectedGUID).Return(nil).Times(2)
+		ib, err := New(netlinkLibMock, hostMock, hostMock)
+		Expect(err).NotTo(HaveOccurred())
+		// the VF gets the same GUID when it is created again
+		Expect(ib.ConfigureVfGUID("0000:d8:00.7", "0000:d8:00.0", 5, pfLinkMock)).To(Succeed())
+		Expect(ib.ConfigureVfGUID("0000:d8:00.7", "0000:d8:00.0", 5, pfLinkMock)).To(Succeed())
+	})
 	It("should assign guids if guid pool is not nil", func() {
 		var assignedGUID string
 		pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
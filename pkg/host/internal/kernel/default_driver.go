@@ -0,0 +1,105 @@
+package kernel
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// vfDriverByPFDriver contains the kernel driver of the VFs by the driver of their PF
+var vfDriverByPFDriver = map[string]string{
+	"i40e":      "iavf",
+	"ice":       "iavf",
+	"ixgbe":     "ixgbevf",
+	"igb":       "igbvf",
+	"mlx5_core": "mlx5_core",
+	"bnxt_en":   "bnxt_en",
+	"qede":      "qede",
+}
+
+// vfDriverByDeviceID contains the kernel driver of the VFs by their "vendor:device" ID, it is used when the PF
+// of the VF is not visible or its driver is unknown, e.g. when the VF is passed through to a virtual machine
+var vfDriverByDeviceID = map[string]string{
+	"8086:154c": "iavf",
+	"8086:1889": "iavf",
+	"8086:10ed": "ixgbevf",
+	"8086:1515": "ixgbevf",
+	"8086:1565": "ixgbevf",
+	"8086:10ca": "igbvf",
+	"8086:1520": "igbvf",
+	"15b3:1014": "mlx5_core",
+	"15b3:1016": "mlx5_core",
+	"15b3:1018": "mlx5_core",
+	"15b3:101a": "mlx5_core",
+	"15b3:101c": "mlx5_core",
+	"15b3:101e": "mlx5_core",
+	"14e4:16dc": "bnxt_en",
+	"14e4:1807": "bnxt_en",
+	"1077:8090": "qede",
+}
+
+// bindFallbackDriver binds the VF to its expected kernel driver when drivers_probe didn't attach any driver,
+// e.g. because the kernel module of the driver is not loaded. The expected driver is found from the driver
+// of the PF or from the device ID of the VF, its kernel module is loaded if needed before the explicit bind.
+// probeErr is the error of the binding with drivers_probe.
+func (k *kernel) bindFallbackDriver(pciAddr string, probeErr error) error {
+	driver := expectedVFDriver(pciAddr)
+	if driver == "" {
+		return &types.MissingKernelModuleError{PciAddress: pciAddr,
+			Err: fmt.Errorf("the kernel driver of the device is unknown: %w", probeErr)}
+	}
+	log.Log.Info("bindFallbackDriver(): no driver attached by drivers_probe, bind the expected driver",
+		"device", pciAddr, "driver", driver)
+	if err := k.LoadKernelModule(driver); err != nil {
+		return &types.MissingKernelModuleError{PciAddress: pciAddr, Module: driver, Err: err}
+	}
+	if err := k.BindDriverByBusAndDevice(consts.BusPci, pciAddr, driver); err != nil {
+		if errors.Is(err, types.ErrDriverNotFound) {
+			return &types.MissingKernelModuleError{PciAddress: pciAddr, Module: driver, Err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+// expectedVFDriver returns the kernel driver expected for the VF, empty if unknown
+func expectedVFDriver(pciAddr string) string {
+	deviceDir := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr)
+	if pfLink, err := os.Readlink(filepath.Join(deviceDir, "physfn")); err == nil {
+		pfDriver, err := getDriverByBusAndDevice(consts.BusPci, filepath.Base(pfLink))
+		if err == nil {
+			if driver, ok := vfDriverByPFDriver[pfDriver]; ok {
+				return driver
+			}
+		}
+		log.Log.V(2).Info("expectedVFDriver(): no VF driver known for the PF driver",
+			"device", pciAddr, "pfDriver", pfDriver)
+	}
+	vendor, err := readPCIID(deviceDir, "vendor")
+	if err != nil {
+		return ""
+	}
+	device, err := readPCIID(deviceDir, "device")
+	if err != nil {
+		return ""
+	}
+	return vfDriverByDeviceID[vendor+":"+device]
+}
+
+// readPCIID reads the vendor or the device ID of the PCI device from sysfs without the 0x prefix
+func readPCIID(deviceDir, file string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(deviceDir, file))
+	if err != nil {
+		log.Log.V(2).Info("readPCIID(): failed to read the PCI ID of the device", "path", deviceDir, "file", file, "error", err.Error())
+		return "", err
+	}
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")), nil
+}
@@ -5,17 +5,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/fileutil"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
+// number of attempts and the initial backoff of the driver binding of a device,
+// the backoff is doubled after each failed attempt
+var (
+	driverBindAttempts = 5
+	driverBindBackoff  = 100 * time.Millisecond
+)
+
+// writeSysfsFile writes the driver binding files of sysfs, overridden in unit-tests to emulate the kernel
+var writeSysfsFile = os.WriteFile
+
+// errDriverNotAttached is returned when the device is not bound to the expected driver after the binding,
+// the binding is retried because the probe of some drivers completes asynchronously
+var errDriverNotAttached = errors.New("driver not attached")
+
 type kernel struct {
 	utilsHelper utils.CmdInterface
 }
@@ -47,6 +69,27 @@ func (k *kernel) LoadKernelModule(name string, args ...string) error {
 	return nil
 }
 
+// UnloadKernelModule unloads the kernel module with modprobe, the modules it depends on are unloaded
+// as well if they are not used anymore. It fails if the module is in use.
+func (k *kernel) UnloadKernelModule(name string) error {
+	isLoaded, err := k.IsKernelModuleLoaded(name)
+	if err != nil {
+		log.Log.Error(err, "UnloadKernelModule(): failed to check if kernel module is loaded", "name", name)
+		return err
+	}
+	if !isLoaded {
+		log.Log.V(2).Info("UnloadKernelModule(): kernel module is not loaded", "name", name)
+		return nil
+	}
+	log.Log.Info("UnloadKernelModule(): unload kernel module", "name", name)
+	_, stderr, err := k.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s modprobe -r %s", utils.GetChrootExtension(), name))
+	if err != nil {
+		log.Log.Error(err, "UnloadKernelModule(): failed to unload kernel module", "name", name, "stderr", stderr)
+		return fmt.Errorf("failed to unload kernel module %s: %v: %s", name, err, stderr)
+	}
+	return nil
+}
+
 func (k *kernel) IsKernelModuleLoaded(kernelModuleName string) (bool, error) {
 	log.Log.Info("IsKernelModuleLoaded(): check if kernel module is loaded", "name", kernelModuleName)
 	chrootDefinition := utils.GetChrootExtension()
@@ -83,6 +126,24 @@ func (k *kernel) TryEnableVhostNet() {
 	}
 }
 
+// EnsureVhostNet loads the tun and vhost_net kernel modules and checks that
+// the /dev/net/tun and /dev/vhost-net device nodes are available on the host
+func (k *kernel) EnsureVhostNet() error {
+	for _, module := range []string{"tun", "vhost_net"} {
+		if err := k.LoadKernelModule(module); err != nil {
+			log.Log.Error(err, "EnsureVhostNet(): failed to load kernel module", "name", module)
+			return fmt.Errorf("failed to load kernel module %s: %v", module, err)
+		}
+	}
+	for _, device := range []string{consts.TunDevice, consts.VhostNetDevice} {
+		if _, err := os.Stat(utils.GetHostExtensionPath(device)); err != nil {
+			log.Log.Error(err, "EnsureVhostNet(): device node not found", "device", device)
+			return fmt.Errorf("device node %s not found: %v", device, err)
+		}
+	}
+	return nil
+}
+
 // GetCurrentKernelArgs This retrieves the kernel cmd line arguments
 func (k *kernel) GetCurrentKernelArgs() (string, error) {
 	path := consts.ProcKernelCmdLine
@@ -98,16 +159,26 @@ func (k *kernel) GetCurrentKernelArgs() (string, error) {
 	return string(cmdLine), nil
 }
 
+// GetKernelVersion returns the release of the running kernel, e.g. 5.14.0-427.13.1.el9_4.x86_64
+func (k *kernel) GetKernelVersion() (string, error) {
+	path := consts.ProcKernelOSRelease
+	if !vars.UsingSystemdMode {
+		path = filepath.Join(consts.Host, path)
+	}
+
+	path = filepath.Join(vars.FilesystemRoot, path)
+	release, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("GetKernelVersion(): Error reading %s: %v", path, err)
+	}
+	return strings.TrimSpace(string(release)), nil
+}
+
 // IsKernelArgsSet This checks if the kernel cmd line is set properly. Please note that the same key could be repeated
 // several times in the kernel cmd line. We can only ensure that the kernel cmd line has the key/val kernel arg that we set.
+// The key and the value of the kernel arg must match exactly, quoted values are supported.
 func (k *kernel) IsKernelArgsSet(cmdLine string, karg string) bool {
-	elements := strings.Fields(cmdLine)
-	for _, element := range elements {
-		if element == karg {
-			return true
-		}
-	}
-	return false
+	return utils.ParseKernelCmdLine(cmdLine).Contains(utils.ParseKernelParam(karg))
 }
 
 // Unbind unbind driver for one device
@@ -122,6 +193,9 @@ func (k *kernel) BindDpdkDriver(pciAddr, driver string) error {
 	log.Log.V(2).Info("BindDpdkDriver(): bind device to driver",
 		"device", pciAddr, "driver", driver)
 	if err := k.BindDriverByBusAndDevice(consts.BusPci, pciAddr, driver); err != nil {
+		if errors.Is(err, types.ErrDriverNotFound) {
+			return err
+		}
 		_, innerErr := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "iommu_group"))
 		if innerErr != nil {
 			log.Log.Error(err, "Could not read IOMMU group for device", "device", pciAddr)
@@ -134,10 +208,24 @@ func (k *kernel) BindDpdkDriver(pciAddr, driver string) error {
 }
 
 // BindDefaultDriver bind driver for one device
-// Bind the device given by "pciAddr" to the default driver
+// Bind the device given by "pciAddr" to the default driver, the device is bound explicitly to its expected
+// kernel driver if drivers_probe doesn't attach any driver
 func (k *kernel) BindDefaultDriver(pciAddr string) error {
 	log.Log.V(2).Info("BindDefaultDriver(): bind device to default driver", "device", pciAddr)
+	err := retryDriverBinding(pciAddr, func() error {
+		return k.bindDefaultDriver(pciAddr)
+	})
+	if err == nil || !errors.Is(err, errDriverNotAttached) {
+		return err
+	}
+	curDriver, driverErr := getDriverByBusAndDevice(consts.BusPci, pciAddr)
+	if driverErr != nil || curDriver != "" {
+		return err
+	}
+	return k.bindFallbackDriver(pciAddr, err)
+}
 
+func (k *kernel) bindDefaultDriver(pciAddr string) error {
 	curDriver, err := getDriverByBusAndDevice(consts.BusPci, pciAddr)
 	if err != nil {
 		return err
@@ -158,6 +246,14 @@ func (k *kernel) BindDefaultDriver(pciAddr string) error {
 	if err := probeDriver(consts.BusPci, pciAddr); err != nil {
 		return err
 	}
+	curDriver, err = getDriverByBusAndDevice(consts.BusPci, pciAddr)
+	if err != nil {
+		return err
+	}
+	if curDriver == "" || sriovnetworkv1.StringInArray(curDriver, vars.DpdkDrivers) {
+		return fmt.Errorf("%w: device %s is bound to %q after the probe, expected its default driver",
+			errDriverNotAttached, pciAddr, curDriver)
+	}
 	return nil
 }
 
@@ -168,7 +264,12 @@ func (k *kernel) BindDefaultDriver(pciAddr string) error {
 func (k *kernel) BindDriverByBusAndDevice(bus, device, driver string) error {
 	log.Log.V(2).Info("BindDriverByBusAndDevice(): bind device to driver",
 		"bus", bus, "device", device, "driver", driver)
+	return retryDriverBinding(device, func() error {
+		return k.bindDriverByBusAndDevice(bus, device, driver)
+	})
+}
 
+func (k *kernel) bindDriverByBusAndDevice(bus, device, driver string) error {
 	curDriver, err := getDriverByBusAndDevice(bus, device)
 	if err != nil {
 		return err
@@ -187,9 +288,39 @@ func (k *kernel) BindDriverByBusAndDevice(bus, device, driver string) error {
 		return err
 	}
 	if err := bindDriver(bus, device, driver); err != nil {
+		// the override would prevent the default driver from reclaiming the device
+		if resetErr := setDriverOverride(bus, device, ""); resetErr != nil {
+			log.Log.Error(resetErr, "BindDriverByBusAndDevice(): failed to reset driver override after bind failure",
+				"bus", bus, "device", device)
+		}
 		return err
 	}
-	return setDriverOverride(bus, device, "")
+	if err := setDriverOverride(bus, device, ""); err != nil {
+		return err
+	}
+	curDriver, err = getDriverByBusAndDevice(bus, device)
+	if err != nil {
+		return err
+	}
+	if curDriver != driver {
+		return fmt.Errorf("%w: device %s is bound to %q, expected %s", errDriverNotAttached, device, curDriver, driver)
+	}
+	return nil
+}
+
+// VerifyDriverBinding returns an error if the PCI device is not bound to the expected driver
+func (k *kernel) VerifyDriverBinding(pciAddress, expectedDriver string) error {
+	curDriver, err := getDriverByBusAndDevice(consts.BusPci, pciAddress)
+	if err != nil {
+		return err
+	}
+	if curDriver == "" {
+		return fmt.Errorf("device %s is not bound to any driver, expected %s", pciAddress, expectedDriver)
+	}
+	if curDriver != expectedDriver {
+		return fmt.Errorf("device %s is bound to driver %s, expected %s", pciAddress, curDriver, expectedDriver)
+	}
+	return nil
 }
 
 // Workaround function to handle a case where the vf default driver is stuck and not able to create the vf kernel interface.
@@ -233,7 +364,12 @@ func (k *kernel) UnbindDriverByBusAndDevice(bus, device string) error {
 		log.Log.V(2).Info("UnbindDriverByBusAndDevice(): device has no driver", "bus", bus, "device", device)
 		return nil
 	}
-	return unbindDriver(bus, device, driver)
+	if err := unbindDriver(bus, device, driver); err != nil {
+		return err
+	}
+	// a driver override left by a previous binding (e.g. to vfio-pci) would prevent the default driver
+	// from reclaiming the device on the next probe
+	return setDriverOverride(bus, device, "")
 }
 
 func (k *kernel) HasDriver(pciAddr string) (bool, string) {
@@ -310,6 +446,151 @@ func (k *kernel) IsKernelLockdownMode() bool {
 	return strings.Contains(stdout, "[integrity]") || strings.Contains(stdout, "[confidentiality]")
 }
 
+// WriteModulesLoadConf writes the kernel modules one per line to the modules-load.d configuration file,
+// this way systemd-modules-load loads them during the boot. The file is removed if no modules provided.
+func (k *kernel) WriteModulesLoadConf(path string, modules []string) error {
+	path = utils.GetHostExtensionPath(path)
+	funcLog := log.Log.WithValues("path", path)
+	funcLog.V(2).Info("WriteModulesLoadConf()", "modules", modules)
+
+	if len(modules) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			funcLog.Error(err, "WriteModulesLoadConf(): failed to remove modules-load configuration")
+			return err
+		}
+		return nil
+	}
+
+	sorted := slices.Clone(modules)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	expected := strings.Join(sorted, "\n") + "\n"
+
+	current, err := os.ReadFile(path)
+	if err == nil && string(current) == expected {
+		funcLog.V(2).Info("WriteModulesLoadConf(): modules-load configuration is up to date")
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		funcLog.Error(err, "WriteModulesLoadConf(): failed to create modules-load configuration folder")
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(path, []byte(expected), 0644); err != nil {
+		funcLog.Error(err, "WriteModulesLoadConf(): failed to write modules-load configuration")
+		return err
+	}
+	return nil
+}
+
+// ConfigureModprobeBlacklist makes vfio-pci claim the devices with the provided IDs during the boot.
+// The kernel drivers of the PFs are configured to load vfio-pci first, this way VFs created by the
+// kernel drivers are never bound to them. Initramfs is regenerated when the configuration changes
+// because the drivers may be loaded from it.
+func (k *kernel) ConfigureModprobeBlacklist(vfioDeviceIDs, pfDrivers []string) error {
+	path := utils.GetHostExtensionPath(consts.ModprobeBlacklistFile)
+	funcLog := log.Log.WithValues("path", path)
+	funcLog.V(2).Info("ConfigureModprobeBlacklist()", "deviceIDs", vfioDeviceIDs, "drivers", pfDrivers)
+
+	exist := true
+	current, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			funcLog.Error(err, "ConfigureModprobeBlacklist(): failed to read modprobe configuration")
+			return err
+		}
+		exist = false
+	}
+
+	if len(vfioDeviceIDs) == 0 {
+		if !exist {
+			return nil
+		}
+		funcLog.Info("ConfigureModprobeBlacklist(): remove modprobe configuration")
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			funcLog.Error(err, "ConfigureModprobeBlacklist(): failed to remove modprobe configuration")
+			return err
+		}
+		if err := k.regenerateInitramfs(); err != nil {
+			// restore the configuration to retry on the next sync
+			_ = fileutil.WriteFileAtomic(path, current, 0644)
+			return err
+		}
+		return nil
+	}
+
+	expected := generateModprobeBlacklist(vfioDeviceIDs, pfDrivers)
+	if exist && string(current) == expected {
+		funcLog.V(2).Info("ConfigureModprobeBlacklist(): modprobe configuration is up to date")
+		return nil
+	}
+
+	funcLog.Info("ConfigureModprobeBlacklist(): update modprobe configuration")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		funcLog.Error(err, "ConfigureModprobeBlacklist(): failed to create modprobe configuration folder")
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(path, []byte(expected), 0644); err != nil {
+		funcLog.Error(err, "ConfigureModprobeBlacklist(): failed to write modprobe configuration")
+		return err
+	}
+	if err := k.regenerateInitramfs(); err != nil {
+		// restore the previous configuration to retry on the next sync
+		if exist {
+			_ = fileutil.WriteFileAtomic(path, current, 0644)
+		} else {
+			_ = os.Remove(path)
+		}
+		return err
+	}
+	return nil
+}
+
+// regenerateInitramfs rebuilds initramfs for the current kernel with the first available tool
+func (k *kernel) regenerateInitramfs() error {
+	chrootDefinition := utils.GetChrootExtension()
+	for _, cmd := range []string{"dracut -f", "update-initramfs -u"} {
+		_, stderr, err := k.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s %s", chrootDefinition, cmd))
+		if err == nil {
+			log.Log.Info("regenerateInitramfs(): initramfs regenerated", "command", cmd)
+			return nil
+		}
+		if !utils.IsCommandNotFound(err) {
+			log.Log.Error(err, "regenerateInitramfs(): failed to regenerate initramfs", "command", cmd, "stderr", stderr)
+			return err
+		}
+	}
+	log.Log.Info("regenerateInitramfs(): no tool to regenerate initramfs found, skip")
+	return nil
+}
+
+// generates content for the modprobe configuration file
+func generateModprobeBlacklist(vfioDeviceIDs, pfDrivers []string) string {
+	ids := slices.Clone(vfioDeviceIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	drivers := slices.Clone(pfDrivers)
+	slices.Sort(drivers)
+	drivers = slices.Compact(drivers)
+
+	var sb strings.Builder
+	sb.WriteString("# This file is managed by the sriov-network-operator, do not edit\n")
+	sb.WriteString(fmt.Sprintf("options vfio-pci ids=%s\n", strings.Join(ids, ",")))
+	for _, driver := range drivers {
+		if driver == "" || sriovnetworkv1.StringInArray(driver, vars.DpdkDrivers) {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("softdep %s pre: vfio-pci\n", driver))
+	}
+	return sb.String()
+}
+
+// GetArchitecture returns the CPU architecture of the host.
+// The config daemon always runs on the same architecture as the host it manages,
+// so the architecture the binary was built for is reported.
+func (k *kernel) GetArchitecture() string {
+	return runtime.GOARCH
+}
+
 // returns driver for device on the bus
 func getDriverByBusAndDevice(bus, device string) (string, error) {
 	driverLink := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "devices", device, "driver")
@@ -330,19 +611,49 @@ func getDriverByBusAndDevice(bus, device string) (string, error) {
 func bindDriver(bus, device, driver string) error {
 	log.Log.V(2).Info("bindDriver(): bind to driver", "bus", bus, "device", device, "driver", driver)
 	bindPath := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "drivers", driver, "bind")
-	err := os.WriteFile(bindPath, []byte(device), os.ModeAppend)
+	err := writeSysfsFile(bindPath, []byte(device), os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "bindDriver(): failed to bind driver", "bus", bus, "device", device, "driver", driver)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", types.ErrDriverNotFound, driver)
+		}
 		return err
 	}
 	return nil
 }
 
+// isTransientBindError returns true if the driver binding failed because the device is busy,
+// e.g. the kernel is still probing the neighbor devices, or the driver is not attached yet,
+// and the binding can be retried
+func isTransientBindError(err error) bool {
+	return errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.ENODEV) || errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, errDriverNotAttached)
+}
+
+// retryDriverBinding runs the driver binding of the device until it succeeds, fails with a permanent error
+// or driverBindAttempts are exhausted
+func retryDriverBinding(device string, bind func() error) error {
+	backoff := driverBindBackoff
+	for attempt := 1; ; attempt++ {
+		err := bind()
+		if err == nil || !isTransientBindError(err) {
+			return err
+		}
+		if attempt >= driverBindAttempts {
+			return fmt.Errorf("driver binding of device %s failed after %d attempts: %w", device, attempt, err)
+		}
+		log.Log.V(2).Info("retryDriverBinding(): device is busy, retry driver binding",
+			"device", device, "attempt", attempt, "backoff", backoff, "error", err.Error())
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
 // unbind device from the driver
 func unbindDriver(bus, device, driver string) error {
 	log.Log.V(2).Info("unbindDriver(): unbind from driver", "bus", bus, "device", device, "driver", driver)
 	unbindPath := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "drivers", driver, "unbind")
-	err := os.WriteFile(unbindPath, []byte(device), os.ModeAppend)
+	err := writeSysfsFile(unbindPath, []byte(device), os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "unbindDriver(): failed to unbind driver", "bus", bus, "device", device, "driver", driver)
 		return err
@@ -354,7 +665,7 @@ func unbindDriver(bus, device, driver string) error {
 func probeDriver(bus, device string) error {
 	log.Log.V(2).Info("probeDriver(): drivers probe", "bus", bus, "device", device)
 	probePath := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "drivers_probe")
-	err := os.WriteFile(probePath, []byte(device), os.ModeAppend)
+	err := writeSysfsFile(probePath, []byte(device), os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "probeDriver(): failed to trigger driver probe", "bus", bus, "device", device)
 		return err
@@ -363,7 +674,7 @@ func probeDriver(bus, device string) error {
 }
 
 // set driver override for the bus/device,
-// resets override if override arg is "",
+// resets override if override arg is "", "\n" is written to clear it,
 // if device doesn't support overriding (has no driver_override path), does nothing
 func setDriverOverride(bus, device, override string) error {
 	driverOverridePath := filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "devices", device, "driver_override")
@@ -380,9 +691,9 @@ func setDriverOverride(bus, device, override string) error {
 		overrideData = []byte(override)
 	} else {
 		log.Log.V(2).Info("setDriverOverride(): reset driver override for device", "bus", bus, "device", device)
-		overrideData = []byte("\x00")
+		overrideData = []byte("\n")
 	}
-	err := os.WriteFile(driverOverridePath, overrideData, os.ModeAppend)
+	err := writeSysfsFile(driverOverridePath, overrideData, os.ModeAppend)
 	if err != nil {
 		log.Log.Error(err, "setDriverOverride(): fail to write driver_override for device",
 			"bus", bus, "device", device, "driver", override)
@@ -390,3 +701,154 @@ func setDriverOverride(bus, device, override string) error {
 	}
 	return nil
 }
+
+// GetDeviceNumaNode returns the NUMA node of the PCI device, -1 is returned if the device has no NUMA affinity
+func (k *kernel) GetDeviceNumaNode(pciAddr string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "numa_node"))
+	if err != nil {
+		log.Log.Error(err, "GetDeviceNumaNode(): failed to read numa_node", "device", pciAddr)
+		return -1, err
+	}
+	numaNode, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		log.Log.Error(err, "GetDeviceNumaNode(): failed to parse numa_node", "device", pciAddr)
+		return -1, err
+	}
+	return numaNode, nil
+}
+
+// ListVFInterrupts returns the IRQs of the VF listed in /proc/interrupts, an IRQ belongs to the VF if it is
+// one of the MSI/MSI-X vectors of the device in sysfs or if its name contains the PCI address of the VF
+// (e.g. mlx5_comp0@pci:0000:3b:00.2)
+func (k *kernel) ListVFInterrupts(pciAddress string) ([]int, error) {
+	msiIrqs := map[string]bool{}
+	entries, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddress, "msi_irqs"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Log.Error(err, "ListVFInterrupts(): failed to read MSI IRQs", "device", pciAddress)
+		return nil, err
+	}
+	for _, entry := range entries {
+		msiIrqs[entry.Name()] = true
+	}
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.ProcInterrupts))
+	if err != nil {
+		log.Log.Error(err, "ListVFInterrupts(): failed to read interrupts")
+		return nil, err
+	}
+	irqs := []int{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// the lines of the IRQs start with the IRQ number, the other lines (the CPU header, NMI, LOC, ...) are skipped
+		if !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+		irq, err := strconv.Atoi(strings.TrimSuffix(fields[0], ":"))
+		if err != nil {
+			continue
+		}
+		if msiIrqs[strconv.Itoa(irq)] || strings.Contains(line, pciAddress) {
+			irqs = append(irqs, irq)
+		}
+	}
+	return irqs, nil
+}
+
+// SetHugepages allocates hugepages of the provided size on the NUMA node (or without NUMA affinity
+// if numaNode is negative) and returns the number of hugepages available after the allocation.
+// The number of hugepages is never decreased to avoid breaking running workloads.
+func (k *kernel) SetHugepages(numaNode int, size string, count int) (int, error) {
+	funcLog := log.Log.WithValues("numaNode", numaNode, "size", size, "count", count)
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hugepages size %s: %v", size, err)
+	}
+	hugepagesDir := fmt.Sprintf("hugepages-%dkB", quantity.Value()/1024)
+	path := filepath.Join(vars.FilesystemRoot, consts.SysKernelMmHugepages, hugepagesDir, "nr_hugepages")
+	if numaNode >= 0 {
+		path = filepath.Join(vars.FilesystemRoot, consts.SysDevicesSystemNode,
+			fmt.Sprintf("node%d", numaNode), "hugepages", hugepagesDir, "nr_hugepages")
+	}
+	current, err := readHugepagesCount(path)
+	if err != nil {
+		funcLog.Error(err, "SetHugepages(): failed to read number of hugepages", "path", path)
+		return 0, err
+	}
+	if current >= count {
+		funcLog.V(2).Info("SetHugepages(): hugepages already allocated", "current", current)
+		return current, nil
+	}
+	funcLog.Info("SetHugepages(): allocate hugepages", "current", current)
+	if err := os.WriteFile(path, []byte(strconv.Itoa(count)), 0644); err != nil {
+		funcLog.Error(err, "SetHugepages(): failed to allocate hugepages", "path", path)
+		return current, err
+	}
+	// the kernel allocates as many hugepages as possible, read back the actual number
+	allocated, err := readHugepagesCount(path)
+	if err != nil {
+		funcLog.Error(err, "SetHugepages(): failed to read number of hugepages", "path", path)
+		return 0, err
+	}
+	return allocated, nil
+}
+
+func readHugepagesCount(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// CreateCharDevice creates the character device node with the provided major and minor numbers on the host,
+// an existing node is kept if it has the same device numbers and replaced otherwise
+func (k *kernel) CreateCharDevice(major, minor uint32, path string) error {
+	funcLog := log.Log.WithValues("path", path, "major", major, "minor", minor)
+	hostPath := utils.GetHostExtensionPath(path)
+	dev := unix.Mkdev(major, minor)
+	info, err := os.Stat(hostPath)
+	if err == nil {
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && info.Mode()&os.ModeCharDevice != 0 && uint64(stat.Rdev) == dev {
+			funcLog.V(2).Info("CreateCharDevice(): device node already exists")
+			return nil
+		}
+		funcLog.Info("CreateCharDevice(): replace device node with wrong device numbers")
+		if err := os.Remove(hostPath); err != nil {
+			funcLog.Error(err, "CreateCharDevice(): failed to remove device node")
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		funcLog.Error(err, "CreateCharDevice(): failed to check device node")
+		return err
+	}
+	funcLog.Info("CreateCharDevice(): create device node")
+	if err := unix.Mknod(hostPath, unix.S_IFCHR|0600, int(dev)); err != nil {
+		funcLog.Error(err, "CreateCharDevice(): failed to create device node")
+		return fmt.Errorf("failed to create device node %s: %v", path, err)
+	}
+	return nil
+}
+
+// UploadFirmware flashes the firmware file to the PCI device with devlink, the kernel loads the file
+// from the firmware directory of the host so the path must be relative to it
+func (k *kernel) UploadFirmware(pciAddress, fwPath string) error {
+	funcLog := log.Log.WithValues("device", pciAddress, "firmware", fwPath)
+	fwPath = filepath.Clean(fwPath)
+	if filepath.IsAbs(fwPath) || fwPath == "." || strings.HasPrefix(fwPath, "..") {
+		return fmt.Errorf("firmware path %q must be relative to %s", fwPath, consts.FirmwareDir)
+	}
+	if _, err := os.Stat(utils.GetHostExtensionPath(filepath.Join(consts.FirmwareDir, fwPath))); err != nil {
+		funcLog.Error(err, "UploadFirmware(): firmware file not found")
+		return fmt.Errorf("firmware file %s not found: %v", fwPath, err)
+	}
+	funcLog.Info("UploadFirmware(): flash firmware")
+	_, stderr, err := k.utilsHelper.RunCommand("/bin/sh", "-c",
+		fmt.Sprintf("%s devlink dev flash pci/%s file %s", utils.GetChrootExtension(), pciAddress, fwPath))
+	if err != nil {
+		funcLog.Error(err, "UploadFirmware(): failed to flash firmware", "stderr", stderr)
+		return fmt.Errorf("failed to flash firmware %s to device %s: %v: %s", fwPath, pciAddress, err, stderr)
+	}
+	return nil
+}
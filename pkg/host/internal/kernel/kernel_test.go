@@ -1,12 +1,23 @@
 package kernel
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	mock_utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
 )
@@ -14,10 +25,20 @@ import (
 var _ = Describe("Kernel", func() {
 	Context("Drivers", func() {
 		var (
-			k types.KernelInterface
+			k          types.KernelInterface
+			fakeKernel *fakeDriverBinding
 		)
 		BeforeEach(func() {
 			k = New(utils.New())
+			fakeKernel = &fakeDriverBinding{defaultDrivers: map[string]string{}}
+			origWriteSysfsFile := writeSysfsFile
+			origBackoff := driverBindBackoff
+			DeferCleanup(func() {
+				writeSysfsFile = origWriteSysfsFile
+				driverBindBackoff = origBackoff
+			})
+			writeSysfsFile = fakeKernel.writeFile
+			driverBindBackoff = time.Millisecond
 		})
 		Context("Unbind, UnbindDriverByBusAndDevice", func() {
 			It("unknown device", func() {
@@ -87,6 +108,7 @@ var _ = Describe("Kernel", func() {
 					Files: map[string][]byte{
 						"/sys/bus/pci/drivers_probe": {}, "/sys/bus/pci/devices/0000:d8:00.0/driver_override": {}},
 				})
+				fakeKernel.defaultDrivers["0000:d8:00.0"] = "iavf"
 				Expect(k.BindDefaultDriver("0000:d8:00.0")).NotTo(HaveOccurred())
 				// should probe driver for dev
 				helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/drivers_probe", "0000:d8:00.0")
@@ -111,6 +133,7 @@ var _ = Describe("Kernel", func() {
 						"/sys/bus/pci/drivers_probe":           {},
 						"/sys/bus/pci/drivers/vfio-pci/unbind": {}},
 				})
+				fakeKernel.defaultDrivers["0000:d8:00.0"] = "iavf"
 				Expect(k.BindDefaultDriver("0000:d8:00.0")).NotTo(HaveOccurred())
 				// should unbind from dpdk driver
 				helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/drivers/vfio-pci/unbind", "0000:d8:00.0")
@@ -132,7 +155,7 @@ var _ = Describe("Kernel", func() {
 				})
 				Expect(k.BindDpdkDriver("0000:d8:00.0", "vfio-pci")).NotTo(HaveOccurred())
 				// should reset driver override
-				helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/driver_override", "\x00")
+				helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/driver_override", "\n")
 			})
 			It("already bind to required driver", func() {
 				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
@@ -173,7 +196,153 @@ var _ = Describe("Kernel", func() {
 						"/sys/bus/pci/drivers/test-driver/unbind":           {},
 						"/sys/bus/pci/devices/0000:d8:00.0/driver_override": {}},
 				})
-				Expect(k.BindDpdkDriver("0000:d8:00.0", "vfio-pci")).To(HaveOccurred())
+				Expect(k.BindDpdkDriver("0000:d8:00.0", "vfio-pci")).To(MatchError(types.ErrDriverNotFound))
+				// should not leave the driver override set
+				helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/driver_override", "\n")
+			})
+		})
+		Context("fake sysfs", func() {
+			var (
+				pf  = "0000:d8:00.0"
+				vf0 = fakefilesystem.VfPciAddress("0000:d8:00.0", 0)
+				vf1 = fakefilesystem.VfPciAddress("0000:d8:00.0", 1)
+			)
+			BeforeEach(func() {
+				fs := fakefilesystem.Sysfs(fakefilesystem.PF{
+					PciAddress: pf, Name: "ens1f0", Driver: "ice", TotalVfs: 4, NumVfs: 2, VfDriver: "iavf"})
+				fs.Dirs = append(fs.Dirs, "/sys/bus/pci/drivers/vfio-pci")
+				helpers.GinkgoConfigureFakeFS(fs)
+				fakeKernel.defaultDrivers[vf0] = "iavf"
+				fakeKernel.defaultDrivers[vf1] = "iavf"
+			})
+			It("should bind a VF to vfio-pci and back to its default driver", func() {
+				Expect(k.BindDpdkDriver(vf1, "vfio-pci")).To(Succeed())
+				Expect(k.GetDriverByBusAndDevice(consts.BusPci, vf1)).To(Equal("vfio-pci"))
+				Expect(k.GetDriverByBusAndDevice(consts.BusPci, vf0)).To(Equal("iavf"))
+				Expect(k.BindDefaultDriver(vf1)).To(Succeed())
+				Expect(k.GetDriverByBusAndDevice(consts.BusPci, vf1)).To(Equal("iavf"))
+				Expect(fakeKernel.driverOverride(consts.BusPci, vf1)).To(BeEmpty())
+			})
+			It("should retry the bind while the device is busy", func() {
+				busyAttempts := 2
+				writeSysfsFile = func(name string, data []byte, perm os.FileMode) error {
+					if filepath.Base(name) == "bind" && busyAttempts > 0 {
+						busyAttempts--
+						return &os.PathError{Op: "write", Path: name, Err: syscall.EBUSY}
+					}
+					return fakeKernel.writeFile(name, data, perm)
+				}
+				Expect(k.BindDpdkDriver(vf0, "vfio-pci")).To(Succeed())
+				Expect(busyAttempts).To(BeZero())
+				Expect(k.GetDriverByBusAndDevice(consts.BusPci, vf0)).To(Equal("vfio-pci"))
+			})
+			It("should fail if the device stays busy", func() {
+				writeSysfsFile = func(name string, data []byte, perm os.FileMode) error {
+					if filepath.Base(name) == "bind" {
+						return &os.PathError{Op: "write", Path: name, Err: syscall.EBUSY}
+					}
+					return fakeKernel.writeFile(name, data, perm)
+				}
+				err := k.BindDriverByBusAndDevice(consts.BusPci, vf0, "vfio-pci")
+				Expect(err).To(MatchError(syscall.EBUSY))
+				Expect(err).To(MatchError(ContainSubstring("failed after 5 attempts")))
+				// the VF is left unbound without driver override, the default driver can reclaim it
+				Expect(k.GetDriverByBusAndDevice(consts.BusPci, vf0)).To(BeEmpty())
+				Expect(fakeKernel.driverOverride(consts.BusPci, vf0)).To(BeEmpty())
+			})
+		})
+		Context("driver_override cleanup", func() {
+			BeforeEach(func() {
+				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+					Dirs: []string{
+						"/sys/bus/pci/devices/0000:d8:00.2",
+						"/sys/bus/pci/drivers/iavf",
+						"/sys/bus/pci/drivers/vfio-pci"},
+					Files: map[string][]byte{
+						"/sys/bus/pci/drivers_probe":                        {},
+						"/sys/bus/pci/devices/0000:d8:00.2/driver_override": {}},
+				})
+				fakeKernel.defaultDrivers["0000:d8:00.2"] = "iavf"
+			})
+			assertBinding := func(driver string) {
+				GinkgoHelper()
+				Expect(k.GetDriverByBusAndDevice(consts.BusPci, "0000:d8:00.2")).To(Equal(driver))
+				Expect(fakeKernel.driverOverride(consts.BusPci, "0000:d8:00.2")).To(BeEmpty())
+			}
+			It("should move a VF from netdevice to vfio-pci and back", func() {
+				Expect(k.BindDefaultDriver("0000:d8:00.2")).To(Succeed())
+				assertBinding("iavf")
+				Expect(k.BindDpdkDriver("0000:d8:00.2", "vfio-pci")).To(Succeed())
+				assertBinding("vfio-pci")
+				Expect(k.BindDefaultDriver("0000:d8:00.2")).To(Succeed())
+				assertBinding("iavf")
+			})
+			It("should move a VF from vfio-pci to netdevice and back", func() {
+				Expect(k.BindDpdkDriver("0000:d8:00.2", "vfio-pci")).To(Succeed())
+				assertBinding("vfio-pci")
+				Expect(k.BindDefaultDriver("0000:d8:00.2")).To(Succeed())
+				assertBinding("iavf")
+				Expect(k.BindDpdkDriver("0000:d8:00.2", "vfio-pci")).To(Succeed())
+				assertBinding("vfio-pci")
+			})
+			It("should clear the driver override left on a vfio-pci VF", func() {
+				Expect(writeSysfsFile(filepath.Join(vars.FilesystemRoot, "/sys/bus/pci/devices/0000:d8:00.2/driver_override"), []byte("vfio-pci"), os.ModeAppend)).To(Succeed())
+				Expect(writeSysfsFile(filepath.Join(vars.FilesystemRoot, "/sys/bus/pci/drivers_probe"), []byte("0000:d8:00.2"), os.ModeAppend)).To(Succeed())
+				Expect(k.GetDriverByBusAndDevice(consts.BusPci, "0000:d8:00.2")).To(Equal("vfio-pci"))
+
+				Expect(k.Unbind("0000:d8:00.2")).To(Succeed())
+				assertBinding("")
+				Expect(writeSysfsFile(filepath.Join(vars.FilesystemRoot, "/sys/bus/pci/drivers_probe"), []byte("0000:d8:00.2"), os.ModeAppend)).To(Succeed())
+				assertBinding("iavf")
+			})
+			It("should fail if the default driver doesn't attach", func() {
+				delete(fakeKernel.defaultDrivers, "0000:d8:00.2")
+				err := k.BindDefaultDriver("0000:d8:00.2")
+				Expect(err).To(MatchError(errDriverNotAttached))
+				Expect(err).To(MatchError(ContainSubstring("failed after 5 attempts")))
+			})
+			It("should fail if the device is not bound to the requested driver", func() {
+				// the binding is not emulated
+				writeSysfsFile = os.WriteFile
+				Expect(k.BindDriverByBusAndDevice(consts.BusPci, "0000:d8:00.2", "vfio-pci")).To(MatchError(errDriverNotAttached))
+				Expect(fakeKernel.driverOverride(consts.BusPci, "0000:d8:00.2")).To(BeEmpty())
+			})
+		})
+		Context("retryDriverBinding", func() {
+			BeforeEach(func() {
+				origBackoff := driverBindBackoff
+				DeferCleanup(func() { driverBindBackoff = origBackoff })
+				driverBindBackoff = time.Millisecond
+			})
+			It("should retry while the device is busy", func() {
+				attempts := 0
+				Expect(retryDriverBinding("0000:d8:00.0", func() error {
+					attempts++
+					if attempts < 3 {
+						return &os.PathError{Op: "write", Path: "/sys/bus/pci/drivers/vfio-pci/bind", Err: syscall.EBUSY}
+					}
+					return nil
+				})).To(Succeed())
+				Expect(attempts).To(Equal(3))
+			})
+			It("should give up after the configured number of attempts", func() {
+				attempts := 0
+				err := retryDriverBinding("0000:d8:00.0", func() error {
+					attempts++
+					return syscall.ENODEV
+				})
+				Expect(err).To(MatchError(syscall.ENODEV))
+				Expect(err).To(MatchError(ContainSubstring("device 0000:d8:00.0 failed after 5 attempts")))
+				Expect(attempts).To(Equal(driverBindAttempts))
+			})
+			It("should not retry permanent errors", func() {
+				attempts := 0
+				err := retryDriverBinding("0000:d8:00.0", func() error {
+					attempts++
+					return fmt.Errorf("%w: vfio-pci", types.ErrDriverNotFound)
+				})
+				Expect(err).To(MatchError(types.ErrDriverNotFound))
+				Expect(attempts).To(Equal(1))
 			})
 		})
 		Context("BindDriverByBusAndDevice", func() {
@@ -219,6 +388,32 @@ var _ = Describe("Kernel", func() {
 			})
 		})
 
+		Context("VerifyDriverBinding", func() {
+			It("device bound to expected driver", func() {
+				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+					Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.2"},
+					Symlinks: map[string]string{
+						"/sys/bus/pci/devices/0000:d8:00.2/driver": "../../../../bus/pci/drivers/vfio-pci"},
+				})
+				Expect(k.VerifyDriverBinding("0000:d8:00.2", "vfio-pci")).NotTo(HaveOccurred())
+			})
+			It("device bound to other driver", func() {
+				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+					Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.2"},
+					Symlinks: map[string]string{
+						"/sys/bus/pci/devices/0000:d8:00.2/driver": "../../../../bus/pci/drivers/iavf"},
+				})
+				Expect(k.VerifyDriverBinding("0000:d8:00.2", "vfio-pci")).To(
+					MatchError("device 0000:d8:00.2 is bound to driver iavf, expected vfio-pci"))
+			})
+			It("device has no driver", func() {
+				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+					Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.2"},
+				})
+				Expect(k.VerifyDriverBinding("0000:d8:00.2", "vfio-pci")).To(HaveOccurred())
+			})
+		})
+
 		Context("IsKernelLockdownMode", func() {
 			It("should return true when kernel boots in lockdown integrity", func() {
 				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
@@ -241,4 +436,437 @@ var _ = Describe("Kernel", func() {
 			})
 		})
 	})
+	Context("GetKernelVersion", func() {
+		var k types.KernelInterface
+		BeforeEach(func() {
+			k = New(utils.New())
+		})
+		It("should return the kernel release", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/proc/sys/kernel"},
+				Files: map[string][]byte{"/host/proc/sys/kernel/osrelease": []byte("5.14.0-427.13.1.el9_4.x86_64\n")},
+			})
+			Expect(k.GetKernelVersion()).To(Equal("5.14.0-427.13.1.el9_4.x86_64"))
+		})
+		It("should fail if the release can't be read", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{})
+			_, err := k.GetKernelVersion()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("ConfigureModprobeBlacklist", func() {
+		var (
+			k         types.KernelInterface
+			utilsMock *mock_utils.MockCmdInterface
+			confPath  string
+		)
+		BeforeEach(func() {
+			utilsMock = mock_utils.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+			k = New(utilsMock)
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/host/etc"}})
+			confPath = filepath.Join("/host", consts.ModprobeBlacklistFile)
+		})
+		It("should write configuration and regenerate initramfs", func() {
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "", nil)
+			Expect(k.ConfigureModprobeBlacklist(
+				[]string{"8086:1889", "15b3:101e", "8086:1889"}, []string{"ice", "mlx5_core", "ice"})).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals(confPath,
+				"# This file is managed by the sriov-network-operator, do not edit\n"+
+					"options vfio-pci ids=15b3:101e,8086:1889\n"+
+					"softdep ice pre: vfio-pci\n"+
+					"softdep mlx5_core pre: vfio-pci\n")
+		})
+		It("should not regenerate initramfs if configuration is up to date", func() {
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "", nil).Times(1)
+			Expect(k.ConfigureModprobeBlacklist([]string{"8086:1889"}, []string{"ice"})).NotTo(HaveOccurred())
+			Expect(k.ConfigureModprobeBlacklist([]string{"8086:1889"}, []string{"ice"})).NotTo(HaveOccurred())
+		})
+		It("should remove configuration when no devices requested", func() {
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "", nil).Times(2)
+			Expect(k.ConfigureModprobeBlacklist([]string{"8086:1889"}, []string{"ice"})).NotTo(HaveOccurred())
+			Expect(k.ConfigureModprobeBlacklist(nil, nil)).NotTo(HaveOccurred())
+			_, err := os.Stat(filepath.Join(vars.FilesystemRoot, confPath))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+		It("should do nothing when no devices requested and no configuration exist", func() {
+			Expect(k.ConfigureModprobeBlacklist(nil, nil)).NotTo(HaveOccurred())
+		})
+		It("should restore previous state when failed to regenerate initramfs", func() {
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "error", os.ErrPermission)
+			Expect(k.ConfigureModprobeBlacklist([]string{"8086:1889"}, []string{"ice"})).To(HaveOccurred())
+			_, err := os.Stat(filepath.Join(vars.FilesystemRoot, confPath))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+	Context("WriteModulesLoadConf", func() {
+		var (
+			k types.KernelInterface
+		)
+		BeforeEach(func() {
+			k = New(utils.New())
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/host/etc"}})
+		})
+		It("should write modules one per line", func() {
+			Expect(k.WriteModulesLoadConf(consts.ModulesLoadConfFile,
+				[]string{"vhost_vdpa", "vfio_pci", "vfio_pci"})).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals(filepath.Join("/host", consts.ModulesLoadConfFile),
+				"vfio_pci\nvhost_vdpa\n")
+		})
+		It("should remove the file if no modules required", func() {
+			Expect(k.WriteModulesLoadConf(consts.ModulesLoadConfFile, []string{"vfio_pci"})).NotTo(HaveOccurred())
+			Expect(k.WriteModulesLoadConf(consts.ModulesLoadConfFile, nil)).NotTo(HaveOccurred())
+			_, err := os.Stat(filepath.Join(vars.FilesystemRoot, "/host", consts.ModulesLoadConfFile))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+	Context("EnsureVhostNet", func() {
+		var (
+			k         types.KernelInterface
+			utilsMock *mock_utils.MockCmdInterface
+		)
+		BeforeEach(func() {
+			utilsMock = mock_utils.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+			k = New(utilsMock)
+			// modules are reported as already loaded
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("loaded", "", nil).Times(2)
+		})
+		It("should succeed when device nodes exist", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/dev/net"},
+				Files: map[string][]byte{"/host/dev/net/tun": {}, "/host/dev/vhost-net": {}},
+			})
+			Expect(k.EnsureVhostNet()).NotTo(HaveOccurred())
+		})
+		It("should fail when device node is missing", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/dev/net"},
+				Files: map[string][]byte{"/host/dev/net/tun": {}},
+			})
+			Expect(k.EnsureVhostNet()).To(MatchError(ContainSubstring(consts.VhostNetDevice)))
+		})
+	})
+	Context("Hugepages", func() {
+		var (
+			k types.KernelInterface
+		)
+		BeforeEach(func() {
+			k = New(utils.New())
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{
+					"/sys/bus/pci/devices/0000:d8:00.0",
+					"/sys/devices/system/node/node1/hugepages/hugepages-1048576kB",
+					"/sys/kernel/mm/hugepages/hugepages-2048kB",
+				},
+				Files: map[string][]byte{
+					"/sys/bus/pci/devices/0000:d8:00.0/numa_node":                               []byte("1\n"),
+					"/sys/devices/system/node/node1/hugepages/hugepages-1048576kB/nr_hugepages": []byte("2\n"),
+					"/sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages":                    []byte("0\n"),
+				},
+			})
+		})
+		It("should return NUMA node of the device", func() {
+			numaNode, err := k.GetDeviceNumaNode("0000:d8:00.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(numaNode).To(Equal(1))
+		})
+		It("should allocate hugepages on NUMA node", func() {
+			allocated, err := k.SetHugepages(1, "1Gi", 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allocated).To(Equal(4))
+			helpers.GinkgoAssertFileContentsEquals(
+				"/sys/devices/system/node/node1/hugepages/hugepages-1048576kB/nr_hugepages", "4")
+		})
+		It("should allocate hugepages without NUMA affinity", func() {
+			allocated, err := k.SetHugepages(-1, "2Mi", 512)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allocated).To(Equal(512))
+			helpers.GinkgoAssertFileContentsEquals("/sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages", "512")
+		})
+		It("should not release allocated hugepages", func() {
+			allocated, err := k.SetHugepages(1, "1Gi", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allocated).To(Equal(2))
+			helpers.GinkgoAssertFileContentsEquals(
+				"/sys/devices/system/node/node1/hugepages/hugepages-1048576kB/nr_hugepages", "2\n")
+		})
+		It("should fail for unsupported size", func() {
+			_, err := k.SetHugepages(1, "2Mi", 1)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("ListVFInterrupts", func() {
+		var (
+			k types.KernelInterface
+		)
+		BeforeEach(func() {
+			k = New(utils.New())
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{
+					"/sys/bus/pci/devices/0000:d8:02.0/msi_irqs/151",
+					"/sys/bus/pci/devices/0000:d8:02.0/msi_irqs/152",
+					"/sys/bus/pci/devices/0000:3b:00.2",
+					"/proc",
+				},
+				Files: map[string][]byte{
+					"/proc/interrupts": []byte(`           CPU0       CPU1
+  0:         22          0   IO-APIC   2-edge      timer
+150:          0          0  IR-PCI-MSIX-0000:d8:00.0    0-edge      i40e-0000:d8:00.0:misc
+151:          3          0  IR-PCI-MSIX-0000:d8:02.0    0-edge      iavf-0000:d8:02.0:mbx
+152:        120          4  IR-PCI-MSIX-0000:d8:02.0    1-edge      iavf-ens803f0v0-TxRx-0
+160:          0          5  IR-PCI-MSIX-0000:3b:00.2    0-edge      mlx5_async0@pci:0000:3b:00.2
+161:        512          0  IR-PCI-MSIX-0000:3b:00.2    1-edge      mlx5_comp0@pci:0000:3b:00.2
+NMI:          0          0   Non-maskable interrupts
+`),
+				},
+			})
+		})
+		It("should return the MSI-X vectors of the VF", func() {
+			Expect(k.ListVFInterrupts("0000:d8:02.0")).To(Equal([]int{151, 152}))
+		})
+		It("should return the IRQs named after the VF", func() {
+			Expect(k.ListVFInterrupts("0000:3b:00.2")).To(Equal([]int{160, 161}))
+		})
+		It("should return no IRQs if the VF has none", func() {
+			Expect(k.ListVFInterrupts("0000:3b:00.3")).To(BeEmpty())
+		})
+	})
+	Context("CreateCharDevice", func() {
+		var (
+			k types.KernelInterface
+		)
+		BeforeEach(func() {
+			k = New(utils.New())
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/dev"},
+				Files: map[string][]byte{"/host/dev/sfc_affinity": []byte("not a device")},
+			})
+		})
+		It("should replace the file with the device node", func() {
+			if os.Getuid() != 0 {
+				Skip("creating device nodes requires root")
+			}
+			Expect(k.CreateCharDevice(237, 0, "/dev/sfc_affinity")).To(Succeed())
+			info, err := os.Stat(filepath.Join(vars.FilesystemRoot, "/host/dev/sfc_affinity"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode() & os.ModeCharDevice).NotTo(BeZero())
+			// already exists
+			Expect(k.CreateCharDevice(237, 0, "/dev/sfc_affinity")).To(Succeed())
+		})
+		It("should fail if the directory doesn't exist", func() {
+			Expect(k.CreateCharDevice(237, 0, "/dev/missing/sfc_affinity")).To(HaveOccurred())
+		})
+	})
+	Context("UnloadKernelModule", func() {
+		var (
+			k         types.KernelInterface
+			utilsMock *mock_utils.MockCmdInterface
+		)
+		BeforeEach(func() {
+			utilsMock = mock_utils.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+			k = New(utilsMock)
+		})
+		It("should unload the loaded module", func() {
+			gomock.InOrder(
+				utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("qat_4xxx 28672 0", "", nil),
+				utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).DoAndReturn(
+					func(_ string, args ...string) (string, string, error) {
+						Expect(args[1]).To(HaveSuffix("modprobe -r qat_4xxx"))
+						return "", "", nil
+					}),
+			)
+			Expect(k.UnloadKernelModule("qat_4xxx")).To(Succeed())
+		})
+		It("should do nothing if the module is not loaded", func() {
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "", nil)
+			Expect(k.UnloadKernelModule("qat_4xxx")).To(Succeed())
+		})
+		It("should fail if the module is in use", func() {
+			gomock.InOrder(
+				utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("vfio_pci 16384 2", "", nil),
+				utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).
+					Return("", "modprobe: FATAL: Module vfio_pci is in use.", fmt.Errorf("exit status 1")),
+			)
+			Expect(k.UnloadKernelModule("vfio_pci")).To(MatchError(ContainSubstring("is in use")))
+		})
+	})
+	Context("UploadFirmware", func() {
+		var (
+			k         types.KernelInterface
+			utilsMock *mock_utils.MockCmdInterface
+		)
+		BeforeEach(func() {
+			utilsMock = mock_utils.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+			k = New(utilsMock)
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/lib/firmware/pensando"},
+				Files: map[string][]byte{"/host/lib/firmware/pensando/dsc_fw.tar": []byte("firmware")},
+			})
+		})
+		It("should flash the firmware with devlink", func() {
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).DoAndReturn(
+				func(_ string, args ...string) (string, string, error) {
+					Expect(args[1]).To(HaveSuffix("devlink dev flash pci/0000:b5:00.0 file pensando/dsc_fw.tar"))
+					return "", "", nil
+				})
+			Expect(k.UploadFirmware("0000:b5:00.0", "pensando/dsc_fw.tar")).To(Succeed())
+		})
+		It("should fail if devlink fails", func() {
+			utilsMock.EXPECT().RunCommand("/bin/sh", "-c", gomock.Any()).Return("", "flash not supported", os.ErrInvalid)
+			Expect(k.UploadFirmware("0000:b5:00.0", "pensando/dsc_fw.tar")).To(MatchError(ContainSubstring("flash not supported")))
+		})
+		It("should reject a path outside of the firmware directory", func() {
+			Expect(k.UploadFirmware("0000:b5:00.0", "/lib/firmware/pensando/dsc_fw.tar")).To(HaveOccurred())
+			Expect(k.UploadFirmware("0000:b5:00.0", "../etc/passwd")).To(HaveOccurred())
+		})
+		It("should fail if the firmware file doesn't exist", func() {
+			Expect(k.UploadFirmware("0000:b5:00.0", "pensando/missing.tar")).To(MatchError(ContainSubstring("not found")))
+		})
+	})
+	Context("BindDefaultDriver fallback", func() {
+		var (
+			k          types.KernelInterface
+			utilsMock  *mock_utils.MockCmdInterface
+			fakeKernel *fakeDriverBinding
+			pf         = "0000:d8:00.0"
+			vf         = fakefilesystem.VfPciAddress("0000:d8:00.0", 0)
+		)
+		BeforeEach(func() {
+			utilsMock = mock_utils.NewMockCmdInterface(gomock.NewController(GinkgoT()))
+			k = New(utilsMock)
+			// drivers_probe doesn't attach any driver to the VF
+			fakeKernel = &fakeDriverBinding{defaultDrivers: map[string]string{}}
+			origWriteSysfsFile := writeSysfsFile
+			origBackoff := driverBindBackoff
+			DeferCleanup(func() {
+				writeSysfsFile = origWriteSysfsFile
+				driverBindBackoff = origBackoff
+			})
+			writeSysfsFile = fakeKernel.writeFile
+			driverBindBackoff = time.Millisecond
+		})
+		// expectModprobe expects the check of the kernel module followed by its loading,
+		// the driver is registered in sysfs when modprobe succeeds
+		expectModprobe := func(module string, modprobeErr error) {
+			gomock.InOrder(
+				utilsMock.EXPECT().RunCommand("/bin/sh", "-c", fmt.Sprintf("%s lsmod | grep \"^%s\"", utils.GetChrootExtension(), module)).
+					Return("", "", nil),
+				utilsMock.EXPECT().RunCommand("/bin/sh", "-c", fmt.Sprintf("%s modprobe %s ", utils.GetChrootExtension(), module)).DoAndReturn(
+					func(_ string, _ ...string) (string, string, error) {
+						if modprobeErr != nil {
+							return "", "modprobe: FATAL: Module " + module + " not found", modprobeErr
+						}
+						driverDir := filepath.Join(vars.FilesystemRoot, "/sys/bus/pci/drivers", module)
+						Expect(os.MkdirAll(driverDir, 0755)).To(Succeed())
+						Expect(os.WriteFile(filepath.Join(driverDir, "bind"), []byte{}, 0644)).To(Succeed())
+						return "", "", nil
+					}),
+			)
+		}
+		It("should load the VF driver of the PF driver and bind the VF", func() {
+			helpers.GinkgoConfigureFakeFS(fakefilesystem.Sysfs(fakefilesystem.PF{
+				PciAddress: pf, Name: "ens1f0", Driver: "ice", NumVfs: 1}))
+			expectModprobe("iavf", nil)
+
+			Expect(k.BindDefaultDriver(vf)).To(Succeed())
+			Expect(k.GetDriverByBusAndDevice(consts.BusPci, vf)).To(Equal("iavf"))
+			Expect(fakeKernel.driverOverride(consts.BusPci, vf)).To(BeEmpty())
+		})
+		It("should find the driver of the VF from its device ID", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:00:05.0"},
+				Files: map[string][]byte{
+					"/sys/bus/pci/drivers_probe":                        {},
+					"/sys/bus/pci/devices/0000:00:05.0/driver_override": {},
+					"/sys/bus/pci/devices/0000:00:05.0/vendor":          []byte("0x15b3\n"),
+					"/sys/bus/pci/devices/0000:00:05.0/device":          []byte("0x101e\n")},
+			})
+			expectModprobe("mlx5_core", nil)
+
+			Expect(k.BindDefaultDriver("0000:00:05.0")).To(Succeed())
+			Expect(k.GetDriverByBusAndDevice(consts.BusPci, "0000:00:05.0")).To(Equal("mlx5_core"))
+		})
+		It("should return the missing module if it can't be loaded", func() {
+			helpers.GinkgoConfigureFakeFS(fakefilesystem.Sysfs(fakefilesystem.PF{
+				PciAddress: pf, Name: "ens1f0", Driver: "ixgbe", NumVfs: 1}))
+			expectModprobe("ixgbevf", fmt.Errorf("exit status 1"))
+
+			err := k.BindDefaultDriver(vf)
+			Expect(err).To(MatchError(types.ErrDriverNotFound))
+			missingErr := &types.MissingKernelModuleError{}
+			Expect(errors.As(err, &missingErr)).To(BeTrue())
+			Expect(missingErr.PciAddress).To(Equal(vf))
+			Expect(missingErr.Module).To(Equal("ixgbevf"))
+			Expect(k.GetDriverByBusAndDevice(consts.BusPci, vf)).To(BeEmpty())
+		})
+		It("should fail if the driver of the VF is unknown", func() {
+			helpers.GinkgoConfigureFakeFS(fakefilesystem.Sysfs(fakefilesystem.PF{
+				PciAddress: pf, Name: "ens1f0", Driver: "unknown", NumVfs: 1}))
+
+			err := k.BindDefaultDriver(vf)
+			Expect(err).To(MatchError(types.ErrDriverNotFound))
+			missingErr := &types.MissingKernelModuleError{}
+			Expect(errors.As(err, &missingErr)).To(BeTrue())
+			Expect(missingErr.Module).To(BeEmpty())
+		})
+	})
 })
+
+// fakeDriverBinding emulates the driver binding of the kernel on the fake sysfs, the writes to the bind,
+// unbind and drivers_probe files update the driver links of the devices according to their driver override
+type fakeDriverBinding struct {
+	// defaultDrivers contains the drivers attached by drivers_probe to the devices without driver override
+	defaultDrivers map[string]string
+}
+
+func (f *fakeDriverBinding) writeFile(name string, data []byte, perm os.FileMode) error {
+	if err := os.WriteFile(name, data, perm); err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(filepath.Join(vars.FilesystemRoot, consts.SysBus), name)
+	if err != nil {
+		return nil
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	device := string(data)
+	switch {
+	case len(parts) == 4 && parts[1] == "drivers" && parts[3] == "bind":
+		return f.bind(parts[0], device, parts[2])
+	case len(parts) == 4 && parts[1] == "drivers" && parts[3] == "unbind":
+		return os.Remove(f.driverLink(parts[0], device))
+	case len(parts) == 2 && parts[1] == "drivers_probe":
+		driver := f.driverOverride(parts[0], device)
+		if driver == "" {
+			driver = f.defaultDrivers[device]
+		}
+		if driver == "" {
+			return nil
+		}
+		return f.bind(parts[0], device, driver)
+	}
+	return nil
+}
+
+// bind attaches the driver to the device, the kernel doesn't bind the devices already bound or with
+// a driver override for another driver
+func (f *fakeDriverBinding) bind(bus, device, driver string) error {
+	if override := f.driverOverride(bus, device); override != "" && override != driver {
+		return syscall.ENODEV
+	}
+	if _, err := os.Readlink(f.driverLink(bus, device)); err == nil {
+		return syscall.ENODEV
+	}
+	return os.Symlink(filepath.Join("../../../../bus", bus, "drivers", driver), f.driverLink(bus, device))
+}
+
+func (f *fakeDriverBinding) driverLink(bus, device string) string {
+	return filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "devices", device, "driver")
+}
+
+// driverOverride returns the driver override of the device, empty if the override is not set
+func (f *fakeDriverBinding) driverOverride(bus, device string) string {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "devices", device, "driver_override"))
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(string(data), "\x00\n")
+}
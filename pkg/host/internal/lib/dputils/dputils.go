@@ -1,9 +1,5 @@
 package dputils
 
-import (
-	dputils "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
-)
-
 func New() DPUtilsLib {
 	return &libWrapper{}
 }
@@ -31,48 +27,3 @@ type DPUtilsLib interface {
 }
 
 type libWrapper struct{}
-
-// GetNetNames returns host net interface names as string for a PCI device from its pci address
-func (w *libWrapper) GetNetNames(pciAddr string) ([]string, error) {
-	return dputils.GetNetNames(pciAddr)
-}
-
-// GetDriverName returns current driver attached to a pci device from its pci address
-func (w *libWrapper) GetDriverName(pciAddr string) (string, error) {
-	return dputils.GetDriverName(pciAddr)
-}
-
-// GetVFID returns VF ID index (within specific PF) based on PCI address
-func (w *libWrapper) GetVFID(pciAddr string) (vfID int, err error) {
-	return dputils.GetVFID(pciAddr)
-}
-
-// IsSriovVF check if a pci device has link to a PF
-func (w *libWrapper) IsSriovVF(pciAddr string) bool {
-	return dputils.IsSriovVF(pciAddr)
-}
-
-// IsSriovPF check if a pci device SRIOV capable given its pci address
-func (w *libWrapper) IsSriovPF(pciAddr string) bool {
-	return dputils.IsSriovPF(pciAddr)
-}
-
-// GetSriovVFcapacity returns SRIOV VF capacity
-func (w *libWrapper) GetSriovVFcapacity(pf string) int {
-	return dputils.GetSriovVFcapacity(pf)
-}
-
-// GetVFconfigured returns number of VF configured for a PF
-func (w *libWrapper) GetVFconfigured(pf string) int {
-	return dputils.GetVFconfigured(pf)
-}
-
-// SriovConfigured returns true if sriov_numvfs reads > 0 else false
-func (w *libWrapper) SriovConfigured(addr string) bool {
-	return dputils.SriovConfigured(addr)
-}
-
-// GetVFList returns a List containing PCI addr for all VF discovered in a given PF
-func (w *libWrapper) GetVFList(pf string) (vfList []string, err error) {
-	return dputils.GetVFList(pf)
-}
@@ -0,0 +1,56 @@
+//go:build !linux
+
+package dputils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// errNotSupported is returned by the wrapper on the platforms without SR-IOV support
+var errNotSupported = fmt.Errorf("SR-IOV devices are not supported on %s", runtime.GOOS)
+
+// GetNetNames returns host net interface names as string for a PCI device from its pci address
+func (w *libWrapper) GetNetNames(pciAddr string) ([]string, error) {
+	return nil, errNotSupported
+}
+
+// GetDriverName returns current driver attached to a pci device from its pci address
+func (w *libWrapper) GetDriverName(pciAddr string) (string, error) {
+	return "", errNotSupported
+}
+
+// GetVFID returns VF ID index (within specific PF) based on PCI address
+func (w *libWrapper) GetVFID(pciAddr string) (vfID int, err error) {
+	return 0, errNotSupported
+}
+
+// IsSriovVF check if a pci device has link to a PF
+func (w *libWrapper) IsSriovVF(pciAddr string) bool {
+	return false
+}
+
+// IsSriovPF check if a pci device SRIOV capable given its pci address
+func (w *libWrapper) IsSriovPF(pciAddr string) bool {
+	return false
+}
+
+// GetSriovVFcapacity returns SRIOV VF capacity
+func (w *libWrapper) GetSriovVFcapacity(pf string) int {
+	return 0
+}
+
+// GetVFconfigured returns number of VF configured for a PF
+func (w *libWrapper) GetVFconfigured(pf string) int {
+	return 0
+}
+
+// SriovConfigured returns true if sriov_numvfs reads > 0 else false
+func (w *libWrapper) SriovConfigured(addr string) bool {
+	return false
+}
+
+// GetVFList returns a List containing PCI addr for all VF discovered in a given PF
+func (w *libWrapper) GetVFList(pf string) (vfList []string, err error) {
+	return nil, errNotSupported
+}
@@ -16,6 +16,8 @@ type EthtoolLib interface {
 	FeatureNames(ifaceName string) (map[string]uint, error)
 	// Change requests a change in the given device's features.
 	Change(ifaceName string, config map[string]bool) error
+	// DriverInfo returns driver information of the given interface name.
+	DriverInfo(ifaceName string) (ethtool.DrvInfo, error)
 }
 
 type libWrapper struct{}
@@ -49,3 +51,13 @@ func (w *libWrapper) Change(ifaceName string, config map[string]bool) error {
 	defer e.Close()
 	return e.Change(ifaceName, config)
 }
+
+// DriverInfo returns driver information of the given interface name.
+func (w *libWrapper) DriverInfo(ifaceName string) (ethtool.DrvInfo, error) {
+	e, err := ethtool.NewEthtool()
+	if err != nil {
+		return ethtool.DrvInfo{}, err
+	}
+	defer e.Close()
+	return e.DriverInfo(ifaceName)
+}
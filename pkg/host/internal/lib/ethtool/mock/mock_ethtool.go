@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	ethtool "github.com/safchain/ethtool"
 )
 
 // MockEthtoolLib is a mock of EthtoolLib interface.
@@ -47,6 +48,21 @@ func (mr *MockEthtoolLibMockRecorder) Change(ifaceName, config interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockEthtoolLib)(nil).Change), ifaceName, config)
 }
 
+// DriverInfo mocks base method.
+func (m *MockEthtoolLib) DriverInfo(ifaceName string) (ethtool.DrvInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DriverInfo", ifaceName)
+	ret0, _ := ret[0].(ethtool.DrvInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DriverInfo indicates an expected call of DriverInfo.
+func (mr *MockEthtoolLibMockRecorder) DriverInfo(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DriverInfo", reflect.TypeOf((*MockEthtoolLib)(nil).DriverInfo), ifaceName)
+}
+
 // FeatureNames mocks base method.
 func (m *MockEthtoolLib) FeatureNames(ifaceName string) (map[string]uint, error) {
 	m.ctrl.T.Helper()
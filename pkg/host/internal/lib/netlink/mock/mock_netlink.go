@@ -88,10 +88,10 @@ func (m *MockNetlinkLib) EXPECT() *MockNetlinkLibMockRecorder {
 }
 
 // DevLinkGetDeviceByName mocks base method.
-func (m *MockNetlinkLib) DevLinkGetDeviceByName(bus, device string) (*netlink0.DevlinkDevice, error) {
+func (m *MockNetlinkLib) DevLinkGetDeviceByName(bus, device string) (*netlink.DevlinkDevice, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DevLinkGetDeviceByName", bus, device)
-	ret0, _ := ret[0].(*netlink0.DevlinkDevice)
+	ret0, _ := ret[0].(*netlink.DevlinkDevice)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -103,7 +103,7 @@ func (mr *MockNetlinkLibMockRecorder) DevLinkGetDeviceByName(bus, device interfa
 }
 
 // DevLinkSetEswitchMode mocks base method.
-func (m *MockNetlinkLib) DevLinkSetEswitchMode(dev *netlink0.DevlinkDevice, newMode string) error {
+func (m *MockNetlinkLib) DevLinkSetEswitchMode(dev *netlink.DevlinkDevice, newMode string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DevLinkSetEswitchMode", dev, newMode)
 	ret0, _ := ret[0].(error)
@@ -116,11 +116,25 @@ func (mr *MockNetlinkLibMockRecorder) DevLinkSetEswitchMode(dev, newMode interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DevLinkSetEswitchMode", reflect.TypeOf((*MockNetlinkLib)(nil).DevLinkSetEswitchMode), dev, newMode)
 }
 
+// DevLinkSetEswitchEncapMode mocks base method.
+func (m *MockNetlinkLib) DevLinkSetEswitchEncapMode(dev *netlink.DevlinkDevice, newMode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DevLinkSetEswitchEncapMode", dev, newMode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DevLinkSetEswitchEncapMode indicates an expected call of DevLinkSetEswitchEncapMode.
+func (mr *MockNetlinkLibMockRecorder) DevLinkSetEswitchEncapMode(dev, newMode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DevLinkSetEswitchEncapMode", reflect.TypeOf((*MockNetlinkLib)(nil).DevLinkSetEswitchEncapMode), dev, newMode)
+}
+
 // DevlinkGetDeviceParamByName mocks base method.
-func (m *MockNetlinkLib) DevlinkGetDeviceParamByName(bus, device, param string) (*netlink0.DevlinkParam, error) {
+func (m *MockNetlinkLib) DevlinkGetDeviceParamByName(bus, device, param string) (*netlink.DevlinkParam, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DevlinkGetDeviceParamByName", bus, device, param)
-	ret0, _ := ret[0].(*netlink0.DevlinkParam)
+	ret0, _ := ret[0].(*netlink.DevlinkParam)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -204,6 +218,62 @@ func (mr *MockNetlinkLibMockRecorder) LinkList() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkList", reflect.TypeOf((*MockNetlinkLib)(nil).LinkList))
 }
 
+// LinkSetAllmulticastOff mocks base method.
+func (m *MockNetlinkLib) LinkSetAllmulticastOff(link netlink.Link) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetAllmulticastOff", link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetAllmulticastOff indicates an expected call of LinkSetAllmulticastOff.
+func (mr *MockNetlinkLibMockRecorder) LinkSetAllmulticastOff(link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetAllmulticastOff", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetAllmulticastOff), link)
+}
+
+// LinkSetAllmulticastOn mocks base method.
+func (m *MockNetlinkLib) LinkSetAllmulticastOn(link netlink.Link) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetAllmulticastOn", link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetAllmulticastOn indicates an expected call of LinkSetAllmulticastOn.
+func (mr *MockNetlinkLibMockRecorder) LinkSetAllmulticastOn(link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetAllmulticastOn", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetAllmulticastOn), link)
+}
+
+// LinkSetDown mocks base method.
+func (m *MockNetlinkLib) LinkSetDown(link netlink.Link) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetDown", link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetDown indicates an expected call of LinkSetDown.
+func (mr *MockNetlinkLibMockRecorder) LinkSetDown(link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetDown", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetDown), link)
+}
+
+// LinkSetHardwareAddr mocks base method.
+func (m *MockNetlinkLib) LinkSetHardwareAddr(link netlink.Link, hwaddr net.HardwareAddr) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetHardwareAddr", link, hwaddr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetHardwareAddr indicates an expected call of LinkSetHardwareAddr.
+func (mr *MockNetlinkLibMockRecorder) LinkSetHardwareAddr(link, hwaddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetHardwareAddr", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetHardwareAddr), link, hwaddr)
+}
+
 // LinkSetMTU mocks base method.
 func (m *MockNetlinkLib) LinkSetMTU(link netlink.Link, mtu int) error {
 	m.ctrl.T.Helper()
@@ -218,6 +288,34 @@ func (mr *MockNetlinkLibMockRecorder) LinkSetMTU(link, mtu interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetMTU", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetMTU), link, mtu)
 }
 
+// LinkSetPromiscOff mocks base method.
+func (m *MockNetlinkLib) LinkSetPromiscOff(link netlink.Link) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetPromiscOff", link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetPromiscOff indicates an expected call of LinkSetPromiscOff.
+func (mr *MockNetlinkLibMockRecorder) LinkSetPromiscOff(link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetPromiscOff", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetPromiscOff), link)
+}
+
+// LinkSetPromiscOn mocks base method.
+func (m *MockNetlinkLib) LinkSetPromiscOn(link netlink.Link) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetPromiscOn", link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetPromiscOn indicates an expected call of LinkSetPromiscOn.
+func (mr *MockNetlinkLibMockRecorder) LinkSetPromiscOn(link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetPromiscOn", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetPromiscOn), link)
+}
+
 // LinkSetUp mocks base method.
 func (m *MockNetlinkLib) LinkSetUp(link netlink.Link) error {
 	m.ctrl.T.Helper()
@@ -274,11 +372,67 @@ func (mr *MockNetlinkLibMockRecorder) LinkSetVfPortGUID(link, vf, portguid inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetVfPortGUID", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetVfPortGUID), link, vf, portguid)
 }
 
+// LinkSetVfRate mocks base method.
+func (m *MockNetlinkLib) LinkSetVfRate(link netlink.Link, vf, minRate, maxRate int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetVfRate", link, vf, minRate, maxRate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetVfRate indicates an expected call of LinkSetVfRate.
+func (mr *MockNetlinkLibMockRecorder) LinkSetVfRate(link, vf, minRate, maxRate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetVfRate", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetVfRate), link, vf, minRate, maxRate)
+}
+
+// LinkSetVfSpoofchk mocks base method.
+func (m *MockNetlinkLib) LinkSetVfSpoofchk(link netlink.Link, vf int, check bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetVfSpoofchk", link, vf, check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetVfSpoofchk indicates an expected call of LinkSetVfSpoofchk.
+func (mr *MockNetlinkLibMockRecorder) LinkSetVfSpoofchk(link, vf, check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetVfSpoofchk", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetVfSpoofchk), link, vf, check)
+}
+
+// LinkSetVfTrust mocks base method.
+func (m *MockNetlinkLib) LinkSetVfTrust(link netlink.Link, vf int, state bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetVfTrust", link, vf, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetVfTrust indicates an expected call of LinkSetVfTrust.
+func (mr *MockNetlinkLibMockRecorder) LinkSetVfTrust(link, vf, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetVfTrust", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetVfTrust), link, vf, state)
+}
+
+// LinkSetVfVlanQosProto mocks base method.
+func (m *MockNetlinkLib) LinkSetVfVlanQosProto(link netlink.Link, vf, vlan, qos, proto int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkSetVfVlanQosProto", link, vf, vlan, qos, proto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkSetVfVlanQosProto indicates an expected call of LinkSetVfVlanQosProto.
+func (mr *MockNetlinkLibMockRecorder) LinkSetVfVlanQosProto(link, vf, vlan, qos, proto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkSetVfVlanQosProto", reflect.TypeOf((*MockNetlinkLib)(nil).LinkSetVfVlanQosProto), link, vf, vlan, qos, proto)
+}
+
 // RdmaLinkByName mocks base method.
-func (m *MockNetlinkLib) RdmaLinkByName(name string) (*netlink0.RdmaLink, error) {
+func (m *MockNetlinkLib) RdmaLinkByName(name string) (*netlink.RdmaLink, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RdmaLinkByName", name)
-	ret0, _ := ret[0].(*netlink0.RdmaLink)
+	ret0, _ := ret[0].(*netlink.RdmaLink)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -289,6 +443,35 @@ func (mr *MockNetlinkLibMockRecorder) RdmaLinkByName(name interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RdmaLinkByName", reflect.TypeOf((*MockNetlinkLib)(nil).RdmaLinkByName), name)
 }
 
+// RdmaSystemGetNetnsMode mocks base method.
+func (m *MockNetlinkLib) RdmaSystemGetNetnsMode() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RdmaSystemGetNetnsMode")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RdmaSystemGetNetnsMode indicates an expected call of RdmaSystemGetNetnsMode.
+func (mr *MockNetlinkLibMockRecorder) RdmaSystemGetNetnsMode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RdmaSystemGetNetnsMode", reflect.TypeOf((*MockNetlinkLib)(nil).RdmaSystemGetNetnsMode))
+}
+
+// RdmaSystemSetNetnsMode mocks base method.
+func (m *MockNetlinkLib) RdmaSystemSetNetnsMode(newMode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RdmaSystemSetNetnsMode", newMode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RdmaSystemSetNetnsMode indicates an expected call of RdmaSystemSetNetnsMode.
+func (mr *MockNetlinkLibMockRecorder) RdmaSystemSetNetnsMode(newMode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RdmaSystemSetNetnsMode", reflect.TypeOf((*MockNetlinkLib)(nil).RdmaSystemSetNetnsMode), newMode)
+}
+
 // VDPADelDev mocks base method.
 func (m *MockNetlinkLib) VDPADelDev(name string) error {
 	m.ctrl.T.Helper()
@@ -304,10 +487,10 @@ func (mr *MockNetlinkLibMockRecorder) VDPADelDev(name interface{}) *gomock.Call
 }
 
 // VDPAGetDevByName mocks base method.
-func (m *MockNetlinkLib) VDPAGetDevByName(name string) (*netlink0.VDPADev, error) {
+func (m *MockNetlinkLib) VDPAGetDevByName(name string) (*netlink.VDPADev, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "VDPAGetDevByName", name)
-	ret0, _ := ret[0].(*netlink0.VDPADev)
+	ret0, _ := ret[0].(*netlink.VDPADev)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -319,7 +502,7 @@ func (mr *MockNetlinkLibMockRecorder) VDPAGetDevByName(name interface{}) *gomock
 }
 
 // VDPANewDev mocks base method.
-func (m *MockNetlinkLib) VDPANewDev(name, mgmtBus, mgmtName string, params netlink0.VDPANewDevParams) error {
+func (m *MockNetlinkLib) VDPANewDev(name, mgmtBus, mgmtName string, params netlink.VDPANewDevParams) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "VDPANewDev", name, mgmtBus, mgmtName, params)
 	ret0, _ := ret[0].(error)
@@ -0,0 +1,249 @@
+//go:build linux
+
+package netlink
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// the devlink, vDPA and RDMA types of the netlink library are defined only on linux
+type (
+	DevlinkDevice         = netlink.DevlinkDevice
+	DevlinkDevAttrs       = netlink.DevlinkDevAttrs
+	DevlinkDevEswitchAttr = netlink.DevlinkDevEswitchAttr
+	DevlinkParam          = netlink.DevlinkParam
+	DevlinkParamValue     = netlink.DevlinkParamValue
+	VDPADev               = netlink.VDPADev
+	VDPANewDevParams      = netlink.VDPANewDevParams
+	RdmaLink              = netlink.RdmaLink
+	RdmaLinkAttrs         = netlink.RdmaLinkAttrs
+)
+
+// types of the devlink parameter values
+const (
+	DevlinkParamTypeU8     = nl.DEVLINK_PARAM_TYPE_U8
+	DevlinkParamTypeU16    = nl.DEVLINK_PARAM_TYPE_U16
+	DevlinkParamTypeU32    = nl.DEVLINK_PARAM_TYPE_U32
+	DevlinkParamTypeString = nl.DEVLINK_PARAM_TYPE_STRING
+	DevlinkParamTypeBool   = nl.DEVLINK_PARAM_TYPE_BOOL
+)
+
+// configuration modes of the devlink parameters
+const (
+	DevlinkParamCmodeRuntime    = nl.DEVLINK_PARAM_CMODE_RUNTIME
+	DevlinkParamCmodeDriverinit = nl.DEVLINK_PARAM_CMODE_DRIVERINIT
+	DevlinkParamCmodePermanent  = nl.DEVLINK_PARAM_CMODE_PERMANENT
+)
+
+// LinkSetVfNodeGUID sets the node GUID of a vf for the link.
+// Equivalent to: `ip link set dev $link vf $vf node_guid $nodeguid`
+func (w *libWrapper) LinkSetVfNodeGUID(link Link, vf int, nodeguid net.HardwareAddr) error {
+	return netlink.LinkSetVfNodeGUID(link, vf, nodeguid)
+}
+
+// LinkSetVfPortGUID sets the port GUID of a vf for the link.
+// Equivalent to: `ip link set dev $link vf $vf port_guid $portguid`
+func (w *libWrapper) LinkSetVfPortGUID(link Link, vf int, portguid net.HardwareAddr) error {
+	return netlink.LinkSetVfPortGUID(link, vf, portguid)
+}
+
+// LinkByName finds a link by name and returns a pointer to the object.
+func (w *libWrapper) LinkByName(name string) (Link, error) {
+	return netlink.LinkByName(name)
+}
+
+// LinkByIndex finds a link by index and returns a pointer to the object.
+func (w *libWrapper) LinkByIndex(index int) (Link, error) {
+	return netlink.LinkByIndex(index)
+}
+
+// LinkList gets a list of link devices.
+// Equivalent to: `ip link show`
+func (w *libWrapper) LinkList() ([]Link, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert each netlink.Link to the custom Link interface
+	customLinks := make([]Link, len(links))
+	for i, link := range links {
+		customLinks[i] = link
+	}
+
+	return customLinks, nil
+}
+
+// LinkSetVfHardwareAddr sets the hardware address of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf mac $hwaddr`
+func (w *libWrapper) LinkSetVfHardwareAddr(link Link, vf int, hwaddr net.HardwareAddr) error {
+	return netlink.LinkSetVfHardwareAddr(link, vf, hwaddr)
+}
+
+// LinkSetVfTrust enables or disables trust mode of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf trust $state`
+func (w *libWrapper) LinkSetVfTrust(link Link, vf int, state bool) error {
+	return netlink.LinkSetVfTrust(link, vf, state)
+}
+
+// LinkSetVfSpoofchk enables or disables spoof check of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf spoofchk $check`
+func (w *libWrapper) LinkSetVfSpoofchk(link Link, vf int, check bool) error {
+	return netlink.LinkSetVfSpoofchk(link, vf, check)
+}
+
+// LinkSetVfRate sets the min and max tx rate in Mbps of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf min_tx_rate $minRate max_tx_rate $maxRate`
+func (w *libWrapper) LinkSetVfRate(link Link, vf int, minRate int, maxRate int) error {
+	return netlink.LinkSetVfRate(link, vf, minRate, maxRate)
+}
+
+// LinkSetVfVlanQosProto sets the vlan, qos and protocol of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf vlan $vlan qos $qos proto $proto`
+func (w *libWrapper) LinkSetVfVlanQosProto(link Link, vf, vlan, qos, proto int) error {
+	return netlink.LinkSetVfVlanQosProto(link, vf, vlan, qos, proto)
+}
+
+// LinkSetUp enables the link device.
+// Equivalent to: `ip link set $link up`
+func (w *libWrapper) LinkSetUp(link Link) error {
+	return netlink.LinkSetUp(link)
+}
+
+// LinkSetDown disables the link device.
+// Equivalent to: `ip link set $link down`
+func (w *libWrapper) LinkSetDown(link Link) error {
+	return netlink.LinkSetDown(link)
+}
+
+// LinkSetMTU sets the mtu of the link device.
+// Equivalent to: `ip link set $link mtu $mtu`
+func (w *libWrapper) LinkSetMTU(link Link, mtu int) error {
+	return netlink.LinkSetMTU(link, mtu)
+}
+
+// LinkSetHardwareAddr sets the hardware address of the link device.
+// Equivalent to: `ip link set $link address $hwaddr`
+func (w *libWrapper) LinkSetHardwareAddr(link Link, hwaddr net.HardwareAddr) error {
+	return netlink.LinkSetHardwareAddr(link, hwaddr)
+}
+
+// LinkSetPromiscOn enables the promiscuous mode of the link device.
+// Equivalent to: `ip link set $link promisc on`
+func (w *libWrapper) LinkSetPromiscOn(link Link) error {
+	return netlink.SetPromiscOn(link)
+}
+
+// LinkSetPromiscOff disables the promiscuous mode of the link device.
+// Equivalent to: `ip link set $link promisc off`
+func (w *libWrapper) LinkSetPromiscOff(link Link) error {
+	return netlink.SetPromiscOff(link)
+}
+
+// LinkSetAllmulticastOn enables the reception of all multicast packets by the link device.
+// Equivalent to: `ip link set $link allmulticast on`
+func (w *libWrapper) LinkSetAllmulticastOn(link Link) error {
+	return netlink.LinkSetAllmulticastOn(link)
+}
+
+// LinkSetAllmulticastOff disables the reception of all multicast packets by the link device.
+// Equivalent to: `ip link set $link allmulticast off`
+func (w *libWrapper) LinkSetAllmulticastOff(link Link) error {
+	return netlink.LinkSetAllmulticastOff(link)
+}
+
+// DevlinkGetDeviceByName provides a pointer to devlink device and nil error,
+// otherwise returns an error code.
+func (w *libWrapper) DevLinkGetDeviceByName(bus string, device string) (*DevlinkDevice, error) {
+	return netlink.DevLinkGetDeviceByName(bus, device)
+}
+
+// DevLinkSetEswitchMode sets eswitch mode if able to set successfully or
+// returns an error code.
+// Equivalent to: `devlink dev eswitch set $dev mode switchdev`
+// Equivalent to: `devlink dev eswitch set $dev mode legacy`
+func (w *libWrapper) DevLinkSetEswitchMode(dev *DevlinkDevice, newMode string) error {
+	return netlink.DevLinkSetEswitchMode(dev, newMode)
+}
+
+// DevLinkSetEswitchEncapMode sets the encapsulation mode of the eswitch
+// Equivalent to: `devlink dev eswitch set $dev encap-mode basic`
+// Equivalent to: `devlink dev eswitch set $dev encap-mode none`
+func (w *libWrapper) DevLinkSetEswitchEncapMode(dev *DevlinkDevice, newMode string) error {
+	var mode uint8
+	switch newMode {
+	case "none":
+		mode = nl.DEVLINK_ESWITCH_ENCAP_MODE_NONE
+	case "basic":
+		mode = nl.DEVLINK_ESWITCH_ENCAP_MODE_BASIC
+	default:
+		return fmt.Errorf("invalid eswitch encap mode %q", newMode)
+	}
+	// the netlink library doesn't support the encap mode, the request is built like DevLinkSetEswitchMode does
+	family, err := netlink.GenlFamilyGet(nl.GENL_DEVLINK_NAME)
+	if err != nil {
+		return err
+	}
+	req := nl.NewNetlinkRequest(int(family.ID), unix.NLM_F_REQUEST|unix.NLM_F_ACK)
+	req.AddData(&nl.Genlmsg{Command: nl.DEVLINK_CMD_ESWITCH_SET, Version: nl.GENL_DEVLINK_VERSION})
+	req.AddData(nl.NewRtAttr(nl.DEVLINK_ATTR_BUS_NAME, nl.ZeroTerminated(dev.BusName)))
+	req.AddData(nl.NewRtAttr(nl.DEVLINK_ATTR_DEV_NAME, nl.ZeroTerminated(dev.DeviceName)))
+	req.AddData(nl.NewRtAttr(nl.DEVLINK_ATTR_ESWITCH_ENCAP_MODE, nl.Uint8Attr(mode)))
+	_, err = req.Execute(unix.NETLINK_GENERIC, 0)
+	return err
+}
+
+// VDPAGetDevByName returns VDPA device selected by name
+// Equivalent to: `vdpa dev show <name>`
+func (w *libWrapper) VDPAGetDevByName(name string) (*VDPADev, error) {
+	return netlink.VDPAGetDevByName(name)
+}
+
+// VDPADelDev removes VDPA device
+// Equivalent to: `vdpa dev del <name>`
+func (w *libWrapper) VDPADelDev(name string) error {
+	return netlink.VDPADelDev(name)
+}
+
+// VDPANewDev adds new VDPA device
+// Equivalent to: `vdpa dev add name <name> mgmtdev <mgmtBus>/mgmtName [params]`
+func (w *libWrapper) VDPANewDev(name, mgmtBus, mgmtName string, params VDPANewDevParams) error {
+	return netlink.VDPANewDev(name, mgmtBus, mgmtName, params)
+}
+
+// DevlinkGetDeviceParamByName returns specific parameter for devlink device
+// Equivalent to: `devlink dev param show <bus>/<device> name <param>`
+func (w *libWrapper) DevlinkGetDeviceParamByName(bus string, device string, param string) (*DevlinkParam, error) {
+	return netlink.DevlinkGetDeviceParamByName(bus, device, param)
+}
+
+// DevlinkSetDeviceParam set specific parameter for devlink device
+// Equivalent to: `devlink dev param set <bus>/<device> name <param> cmode <cmode> value <value>`
+// cmode argument should contain valid cmode value as uint8, modes are define in nl.DEVLINK_PARAM_CMODE_* constants
+// value argument should have one of the following types: uint8, uint16, uint32, string, bool
+func (w *libWrapper) DevlinkSetDeviceParam(bus string, device string, param string, cmode uint8, value interface{}) error {
+	return netlink.DevlinkSetDeviceParam(bus, device, param, cmode, value)
+}
+
+// RdmaLinkByName finds a link by name and returns a pointer to the object if
+// found and nil error, otherwise returns error code.
+func (w *libWrapper) RdmaLinkByName(name string) (*RdmaLink, error) {
+	return netlink.RdmaLinkByName(name)
+}
+
+// RdmaSystemGetNetnsMode returns the network namespace mode of the RDMA subsystem
+// Equivalent to: `rdma system show netns`
+func (w *libWrapper) RdmaSystemGetNetnsMode() (string, error) {
+	return netlink.RdmaSystemGetNetnsMode()
+}
+
+// RdmaSystemSetNetnsMode sets the network namespace mode of the RDMA subsystem
+// Equivalent to: `rdma system set netns { shared | exclusive }`
+func (w *libWrapper) RdmaSystemSetNetnsMode(newMode string) error {
+	return netlink.RdmaSystemSetNetnsMode(newMode)
+}
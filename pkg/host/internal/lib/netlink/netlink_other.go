@@ -0,0 +1,267 @@
+//go:build !linux
+
+package netlink
+
+import (
+	"net"
+
+	"github.com/vishvananda/netlink"
+)
+
+// DevlinkDevEswitchAttr represents device's eswitch attributes
+type DevlinkDevEswitchAttr struct {
+	Mode       string
+	InlineMode string
+	EncapMode  string
+}
+
+// DevlinkDevAttrs represents device attributes
+type DevlinkDevAttrs struct {
+	Eswitch DevlinkDevEswitchAttr
+}
+
+// DevlinkDevice represents device and its attributes
+type DevlinkDevice struct {
+	BusName    string
+	DeviceName string
+	Attrs      DevlinkDevAttrs
+}
+
+// DevlinkParam represents parameter of the device
+type DevlinkParam struct {
+	Name      string
+	IsGeneric bool
+	Type      uint8
+	Values    []DevlinkParamValue
+}
+
+// DevlinkParamValue contains values of the parameter
+type DevlinkParamValue struct {
+	Data  interface{}
+	CMODE uint8
+}
+
+// VDPADev contains info about VDPA device
+type VDPADev struct {
+	Name      string
+	ID        uint32
+	VendorID  uint32
+	MaxVQS    uint32
+	MaxVQSize uint16
+	MinVQSize uint16
+}
+
+// VDPANewDevParams contains parameters for new VDPA device
+type VDPANewDevParams struct {
+	MACAddr  net.HardwareAddr
+	MaxVQP   uint16
+	MTU      uint16
+	Features uint64
+}
+
+// RdmaLinkAttrs has link configuration
+type RdmaLinkAttrs struct {
+	Index           uint32
+	Name            string
+	FirmwareVersion string
+	NodeGuid        string
+	SysImageGuid    string
+}
+
+// RdmaLink represents a rdma device
+type RdmaLink struct {
+	Attrs RdmaLinkAttrs
+}
+
+// types of the devlink parameter values
+const (
+	DevlinkParamTypeU8     = 1
+	DevlinkParamTypeU16    = 2
+	DevlinkParamTypeU32    = 3
+	DevlinkParamTypeString = 5
+	DevlinkParamTypeBool   = 6
+)
+
+// configuration modes of the devlink parameters
+const (
+	DevlinkParamCmodeRuntime = iota
+	DevlinkParamCmodeDriverinit
+	DevlinkParamCmodePermanent
+)
+
+// LinkSetVfNodeGUID sets the node GUID of a vf for the link.
+// Equivalent to: `ip link set dev $link vf $vf node_guid $nodeguid`
+func (w *libWrapper) LinkSetVfNodeGUID(link Link, vf int, nodeguid net.HardwareAddr) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetVfPortGUID sets the port GUID of a vf for the link.
+// Equivalent to: `ip link set dev $link vf $vf port_guid $portguid`
+func (w *libWrapper) LinkSetVfPortGUID(link Link, vf int, portguid net.HardwareAddr) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkByName finds a link by name and returns a pointer to the object.
+func (w *libWrapper) LinkByName(name string) (Link, error) {
+	return nil, netlink.ErrNotImplemented
+}
+
+// LinkByIndex finds a link by index and returns a pointer to the object.
+func (w *libWrapper) LinkByIndex(index int) (Link, error) {
+	return nil, netlink.ErrNotImplemented
+}
+
+// LinkList gets a list of link devices.
+// Equivalent to: `ip link show`
+func (w *libWrapper) LinkList() ([]Link, error) {
+	return nil, netlink.ErrNotImplemented
+}
+
+// LinkSetVfHardwareAddr sets the hardware address of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf mac $hwaddr`
+func (w *libWrapper) LinkSetVfHardwareAddr(link Link, vf int, hwaddr net.HardwareAddr) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetVfTrust enables or disables trust mode of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf trust $state`
+func (w *libWrapper) LinkSetVfTrust(link Link, vf int, state bool) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetVfSpoofchk enables or disables spoof check of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf spoofchk $check`
+func (w *libWrapper) LinkSetVfSpoofchk(link Link, vf int, check bool) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetVfRate sets the min and max tx rate in Mbps of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf min_tx_rate $minRate max_tx_rate $maxRate`
+func (w *libWrapper) LinkSetVfRate(link Link, vf int, minRate int, maxRate int) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetVfVlanQosProto sets the vlan, qos and protocol of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf vlan $vlan qos $qos proto $proto`
+func (w *libWrapper) LinkSetVfVlanQosProto(link Link, vf, vlan, qos, proto int) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetUp enables the link device.
+// Equivalent to: `ip link set $link up`
+func (w *libWrapper) LinkSetUp(link Link) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetDown disables the link device.
+// Equivalent to: `ip link set $link down`
+func (w *libWrapper) LinkSetDown(link Link) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetMTU sets the mtu of the link device.
+// Equivalent to: `ip link set $link mtu $mtu`
+func (w *libWrapper) LinkSetMTU(link Link, mtu int) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetHardwareAddr sets the hardware address of the link device.
+// Equivalent to: `ip link set $link address $hwaddr`
+func (w *libWrapper) LinkSetHardwareAddr(link Link, hwaddr net.HardwareAddr) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetPromiscOn enables the promiscuous mode of the link device.
+// Equivalent to: `ip link set $link promisc on`
+func (w *libWrapper) LinkSetPromiscOn(link Link) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetPromiscOff disables the promiscuous mode of the link device.
+// Equivalent to: `ip link set $link promisc off`
+func (w *libWrapper) LinkSetPromiscOff(link Link) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetAllmulticastOn enables the reception of all multicast packets by the link device.
+// Equivalent to: `ip link set $link allmulticast on`
+func (w *libWrapper) LinkSetAllmulticastOn(link Link) error {
+	return netlink.ErrNotImplemented
+}
+
+// LinkSetAllmulticastOff disables the reception of all multicast packets by the link device.
+// Equivalent to: `ip link set $link allmulticast off`
+func (w *libWrapper) LinkSetAllmulticastOff(link Link) error {
+	return netlink.ErrNotImplemented
+}
+
+// DevlinkGetDeviceByName provides a pointer to devlink device and nil error,
+// otherwise returns an error code.
+func (w *libWrapper) DevLinkGetDeviceByName(bus string, device string) (*DevlinkDevice, error) {
+	return nil, netlink.ErrNotImplemented
+}
+
+// DevLinkSetEswitchMode sets eswitch mode if able to set successfully or
+// returns an error code.
+// Equivalent to: `devlink dev eswitch set $dev mode switchdev`
+// Equivalent to: `devlink dev eswitch set $dev mode legacy`
+func (w *libWrapper) DevLinkSetEswitchMode(dev *DevlinkDevice, newMode string) error {
+	return netlink.ErrNotImplemented
+}
+
+// DevLinkSetEswitchEncapMode sets the encapsulation mode of the eswitch
+// Equivalent to: `devlink dev eswitch set $dev encap-mode basic`
+// Equivalent to: `devlink dev eswitch set $dev encap-mode none`
+func (w *libWrapper) DevLinkSetEswitchEncapMode(dev *DevlinkDevice, newMode string) error {
+	return netlink.ErrNotImplemented
+}
+
+// VDPAGetDevByName returns VDPA device selected by name
+// Equivalent to: `vdpa dev show <name>`
+func (w *libWrapper) VDPAGetDevByName(name string) (*VDPADev, error) {
+	return nil, netlink.ErrNotImplemented
+}
+
+// VDPADelDev removes VDPA device
+// Equivalent to: `vdpa dev del <name>`
+func (w *libWrapper) VDPADelDev(name string) error {
+	return netlink.ErrNotImplemented
+}
+
+// VDPANewDev adds new VDPA device
+// Equivalent to: `vdpa dev add name <name> mgmtdev <mgmtBus>/mgmtName [params]`
+func (w *libWrapper) VDPANewDev(name, mgmtBus, mgmtName string, params VDPANewDevParams) error {
+	return netlink.ErrNotImplemented
+}
+
+// DevlinkGetDeviceParamByName returns specific parameter for devlink device
+// Equivalent to: `devlink dev param show <bus>/<device> name <param>`
+func (w *libWrapper) DevlinkGetDeviceParamByName(bus string, device string, param string) (*DevlinkParam, error) {
+	return nil, netlink.ErrNotImplemented
+}
+
+// DevlinkSetDeviceParam set specific parameter for devlink device
+// Equivalent to: `devlink dev param set <bus>/<device> name <param> cmode <cmode> value <value>`
+// cmode argument should contain valid cmode value as uint8, modes are define in nl.DEVLINK_PARAM_CMODE_* constants
+// value argument should have one of the following types: uint8, uint16, uint32, string, bool
+func (w *libWrapper) DevlinkSetDeviceParam(bus string, device string, param string, cmode uint8, value interface{}) error {
+	return netlink.ErrNotImplemented
+}
+
+// RdmaLinkByName finds a link by name and returns a pointer to the object if
+// found and nil error, otherwise returns error code.
+func (w *libWrapper) RdmaLinkByName(name string) (*RdmaLink, error) {
+	return nil, netlink.ErrNotImplemented
+}
+
+// RdmaSystemGetNetnsMode returns the network namespace mode of the RDMA subsystem
+// Equivalent to: `rdma system show netns`
+func (w *libWrapper) RdmaSystemGetNetnsMode() (string, error) {
+	return "", netlink.ErrNotImplemented
+}
+
+// RdmaSystemSetNetnsMode sets the network namespace mode of the RDMA subsystem
+// Equivalent to: `rdma system set netns { shared | exclusive }`
+func (w *libWrapper) RdmaSystemSetNetnsMode(newMode string) error {
+	return netlink.ErrNotImplemented
+}
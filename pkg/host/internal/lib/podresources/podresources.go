@@ -0,0 +1,76 @@
+package podresources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// maximum time to wait for the response of the kubelet
+const requestTimeout = 10 * time.Second
+
+// ErrKubeletUnavailable is returned if the pod resources API of the kubelet can't be reached,
+// e.g. the kubelet is not started yet
+var ErrKubeletUnavailable = errors.New("kubelet pod resources API is not available")
+
+func New() PodResourcesLib {
+	return &libWrapper{}
+}
+
+//go:generate ../../../../../bin/mockgen -destination mock/mock_podresources.go -source podresources.go
+type PodResourcesLib interface {
+	// GetAllocatedDevices returns the devices allocated to the running pods by the device plugins,
+	// the key is the device ID and the value is the namespace/name of the pod which uses the device
+	GetAllocatedDevices() (map[string]string, error)
+}
+
+type libWrapper struct{}
+
+// GetAllocatedDevices returns the devices allocated to the running pods by the device plugins
+func (w *libWrapper) GetAllocatedDevices() (map[string]string, error) {
+	socketPath := filepath.Join(vars.FilesystemRoot, consts.KubeletPodResourcesSocket)
+	if _, err := os.Stat(socketPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %v", ErrKubeletUnavailable, err)
+		}
+		return nil, err
+	}
+	conn, err := grpc.Dial("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	resp, err := podresourcesapi.NewPodResourcesListerClient(conn).List(ctx, &podresourcesapi.ListPodResourcesRequest{})
+	if err != nil {
+		if status.Code(err) == codes.Unavailable {
+			return nil, fmt.Errorf("%w: %v", ErrKubeletUnavailable, err)
+		}
+		return nil, err
+	}
+	devices := map[string]string{}
+	for _, pod := range resp.GetPodResources() {
+		for _, container := range pod.GetContainers() {
+			for _, device := range container.GetDevices() {
+				for _, id := range device.GetDeviceIds() {
+					devices[id] = pod.GetNamespace() + "/" + pod.GetName()
+				}
+			}
+		}
+	}
+	return devices, nil
+}
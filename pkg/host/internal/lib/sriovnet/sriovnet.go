@@ -1,9 +1,5 @@
 package sriovnet
 
-import (
-	"github.com/k8snetworkplumbingwg/sriovnet"
-)
-
 func New() SriovnetLib {
 	return &libWrapper{}
 }
@@ -15,8 +11,3 @@ type SriovnetLib interface {
 }
 
 type libWrapper struct{}
-
-// GetVfRepresentor returns representor name for VF device
-func (w *libWrapper) GetVfRepresentor(pfName string, vfIndex int) (string, error) {
-	return sriovnet.GetVfRepresentor(pfName, vfIndex)
-}
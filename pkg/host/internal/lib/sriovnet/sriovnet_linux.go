@@ -0,0 +1,12 @@
+//go:build linux
+
+package sriovnet
+
+import (
+	"github.com/k8snetworkplumbingwg/sriovnet"
+)
+
+// GetVfRepresentor returns representor name for VF device
+func (w *libWrapper) GetVfRepresentor(pfName string, vfIndex int) (string, error) {
+	return sriovnet.GetVfRepresentor(pfName, vfIndex)
+}
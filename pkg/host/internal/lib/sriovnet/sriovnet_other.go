@@ -0,0 +1,13 @@
+//go:build !linux
+
+package sriovnet
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// GetVfRepresentor returns representor name for VF device
+func (w *libWrapper) GetVfRepresentor(pfName string, vfIndex int) (string, error) {
+	return "", fmt.Errorf("VF representors are not supported on %s", runtime.GOOS)
+}
@@ -6,14 +6,15 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff"
-	"github.com/vishvananda/netlink/nl"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	dputilsPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
 	ethtoolPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ethtool"
@@ -254,6 +255,17 @@ func (n *network) GetNetDevLinkSpeed(ifaceName string) string {
 	return fmt.Sprintf("%s Mb/s", strings.TrimSpace(string(data)))
 }
 
+// GetNetDevFirmwareVersion returns the firmware version of the network interface
+func (n *network) GetNetDevFirmwareVersion(ifaceName string) string {
+	log.Log.V(2).Info("GetNetDevFirmwareVersion(): get firmware version", "device", ifaceName)
+	info, err := n.ethtoolLib.DriverInfo(ifaceName)
+	if err != nil {
+		log.Log.Error(err, "GetNetDevFirmwareVersion(): fail to get driver info", "device", ifaceName)
+		return ""
+	}
+	return strings.TrimSpace(info.FwVersion)
+}
+
 // GetDevlinkDeviceParam returns devlink parameter for the device as a string, if the parameter has multiple values
 // then the function will return only first one from the list.
 func (n *network) GetDevlinkDeviceParam(pciAddr, paramName string) (string, error) {
@@ -271,7 +283,7 @@ func (n *network) GetDevlinkDeviceParam(pciAddr, paramName string) (string, erro
 	var value string
 	var ok bool
 	switch param.Type {
-	case nl.DEVLINK_PARAM_TYPE_U8, nl.DEVLINK_PARAM_TYPE_U16, nl.DEVLINK_PARAM_TYPE_U32:
+	case netlinkPkg.DevlinkParamTypeU8, netlinkPkg.DevlinkParamTypeU16, netlinkPkg.DevlinkParamTypeU32:
 		var valData uint64
 		switch v := param.Values[0].Data.(type) {
 		case uint8:
@@ -285,12 +297,12 @@ func (n *network) GetDevlinkDeviceParam(pciAddr, paramName string) (string, erro
 		}
 		value = strconv.FormatUint(valData, 10)
 
-	case nl.DEVLINK_PARAM_TYPE_STRING:
+	case netlinkPkg.DevlinkParamTypeString:
 		value, ok = param.Values[0].Data.(string)
 		if !ok {
 			return "", fmt.Errorf("value is not a string")
 		}
-	case nl.DEVLINK_PARAM_TYPE_BOOL:
+	case netlinkPkg.DevlinkParamTypeBool:
 		var boolValue bool
 		boolValue, ok = param.Values[0].Data.(bool)
 		if !ok {
@@ -324,19 +336,19 @@ func (n *network) SetDevlinkDeviceParam(pciAddr, paramName, value string) error
 	var typedValue interface{}
 	var v uint64
 	switch param.Type {
-	case nl.DEVLINK_PARAM_TYPE_U8:
+	case netlinkPkg.DevlinkParamTypeU8:
 		v, err = strconv.ParseUint(value, 10, 8)
 		typedValue = uint8(v)
-	case nl.DEVLINK_PARAM_TYPE_U16:
+	case netlinkPkg.DevlinkParamTypeU16:
 		v, err = strconv.ParseUint(value, 10, 16)
 		typedValue = uint16(v)
-	case nl.DEVLINK_PARAM_TYPE_U32:
+	case netlinkPkg.DevlinkParamTypeU32:
 		v, err = strconv.ParseUint(value, 10, 32)
 		typedValue = uint32(v)
-	case nl.DEVLINK_PARAM_TYPE_STRING:
+	case netlinkPkg.DevlinkParamTypeString:
 		err = nil
 		typedValue = value
-	case nl.DEVLINK_PARAM_TYPE_BOOL:
+	case netlinkPkg.DevlinkParamTypeBool:
 		typedValue, err = strconv.ParseBool(value)
 	default:
 		return fmt.Errorf("parameter has unknown value type: %d", param.Type)
@@ -353,6 +365,48 @@ func (n *network) SetDevlinkDeviceParam(pciAddr, paramName, value string) error
 	return nil
 }
 
+// IsDevlinkDeviceParamPermanent returns true if the devlink parameter of the device is set in the permanent
+// configuration mode, the value is stored by the firmware and applied after a firmware reset
+func (n *network) IsDevlinkDeviceParamPermanent(pciAddr, paramName string) (bool, error) {
+	param, err := n.netlinkLib.DevlinkGetDeviceParamByName(consts.BusPci, pciAddr, paramName)
+	if err != nil {
+		log.Log.Error(err, "IsDevlinkDeviceParamPermanent(): fail to get devlink device param", "device", pciAddr, "param", paramName)
+		return false, err
+	}
+	if len(param.Values) == 0 {
+		return false, fmt.Errorf("param %s has no value", paramName)
+	}
+	return param.Values[0].CMODE == netlinkPkg.DevlinkParamCmodePermanent, nil
+}
+
+// GetRDMASubsystemNetnsMode returns the network namespace mode of the RDMA subsystem, shared or exclusive
+func (n *network) GetRDMASubsystemNetnsMode() (string, error) {
+	mode, err := n.netlinkLib.RdmaSystemGetNetnsMode()
+	if err != nil {
+		log.Log.Error(err, "GetRDMASubsystemNetnsMode(): failed to get RDMA subsystem netns mode")
+		return "", err
+	}
+	return mode, nil
+}
+
+// SetRDMASubsystemNetnsMode sets the network namespace mode of the RDMA subsystem and verifies that
+// the mode was applied, the mode can't be changed while RDMA devices are used in other network namespaces
+func (n *network) SetRDMASubsystemNetnsMode(mode string) error {
+	log.Log.Info("SetRDMASubsystemNetnsMode(): set RDMA subsystem netns mode", "mode", mode)
+	if err := n.netlinkLib.RdmaSystemSetNetnsMode(mode); err != nil {
+		log.Log.Error(err, "SetRDMASubsystemNetnsMode(): failed to set RDMA subsystem netns mode", "mode", mode)
+		return fmt.Errorf("failed to set RDMA subsystem netns mode to %s: %v", mode, err)
+	}
+	current, err := n.GetRDMASubsystemNetnsMode()
+	if err != nil {
+		return err
+	}
+	if current != mode {
+		return fmt.Errorf("RDMA subsystem netns mode is %s after setting it to %s", current, mode)
+	}
+	return nil
+}
+
 // EnableHwTcOffload makes sure that hw-tc-offload feature is enabled if device supports it
 func (n *network) EnableHwTcOffload(ifaceName string) error {
 	log.Log.V(2).Info("EnableHwTcOffload(): enable offloading", "device", ifaceName)
@@ -393,6 +447,216 @@ func (n *network) EnableHwTcOffload(ifaceName string) error {
 	return nil
 }
 
+// udpTunnelSegmentationFeature is the ethtool feature of the UDP tunnel segmentation offload,
+// the same offload is used for VXLAN and Geneve encapsulation
+const udpTunnelSegmentationFeature = "tx-udp_tnl-segmentation"
+
+// ConfigureEncapOffload enables or disables the VXLAN and Geneve segmentation offload of the interface,
+// the kernel has a single feature for both encapsulations so the offload is enabled if any of them requires it
+func (n *network) ConfigureEncapOffload(ifaceName string, vxlan, geneve bool) error {
+	enable := vxlan || geneve
+	funcLog := log.Log.WithValues("device", ifaceName, "feature", udpTunnelSegmentationFeature, "enable", enable)
+	funcLog.V(2).Info("ConfigureEncapOffload(): configure encapsulation offload", "vxlan", vxlan, "geneve", geneve)
+
+	knownFeatures, err := n.ethtoolLib.FeatureNames(ifaceName)
+	if err != nil {
+		funcLog.Error(err, "ConfigureEncapOffload(): can't list supported features")
+		return err
+	}
+	if _, isKnown := knownFeatures[udpTunnelSegmentationFeature]; !isKnown {
+		if !enable {
+			return nil
+		}
+		return fmt.Errorf("encapsulation offload is not supported by device %s", ifaceName)
+	}
+	if err := n.ethtoolLib.Change(ifaceName, map[string]bool{udpTunnelSegmentationFeature: enable}); err != nil {
+		funcLog.Error(err, "ConfigureEncapOffload(): can't set feature for device")
+		return err
+	}
+	// the change request succeeds for the features which are fixed by the driver
+	updatedFeaturesState, err := n.ethtoolLib.Features(ifaceName)
+	if err != nil {
+		funcLog.Error(err, "ConfigureEncapOffload(): can't read features state for device")
+		return err
+	}
+	if updatedFeaturesState[udpTunnelSegmentationFeature] != enable {
+		return fmt.Errorf("encapsulation offload of device %s can't be changed to %t", ifaceName, enable)
+	}
+	return nil
+}
+
+// GetEncapOffloadState returns the VXLAN and Geneve segmentation offload state of the interface,
+// both encapsulations use the same offload so the returned states are always equal
+func (n *network) GetEncapOffloadState(ifaceName string) (vxlan, geneve bool, err error) {
+	features, err := n.ethtoolLib.Features(ifaceName)
+	if err != nil {
+		log.Log.Error(err, "GetEncapOffloadState(): can't read features state for device", "device", ifaceName)
+		return false, false, err
+	}
+	enabled := features[udpTunnelSegmentationFeature]
+	return enabled, enabled, nil
+}
+
+// GetPfSettings returns the promiscuous mode, the all-multicast mode and the state of the whitelisted
+// ethtool features supported by the interface, the features unknown to the driver are not returned
+func (n *network) GetPfSettings(ifaceName string) (*sriovnetworkv1.PfSettings, error) {
+	link, err := n.netlinkLib.LinkByName(ifaceName)
+	if err != nil {
+		log.Log.Error(err, "GetPfSettings(): failed to get link", "device", ifaceName)
+		return nil, err
+	}
+	features, err := n.ethtoolLib.Features(ifaceName)
+	if err != nil {
+		log.Log.Error(err, "GetPfSettings(): can't read features state for device", "device", ifaceName)
+		return nil, err
+	}
+	promisc := link.Attrs().Promisc != 0
+	allMulticast := link.Attrs().Allmulti != 0
+	settings := &sriovnetworkv1.PfSettings{Promisc: &promisc, AllMulticast: &allMulticast}
+	for _, feature := range sriovnetworkv1.PfSettingsFeatures {
+		if enabled, found := features[feature]; found {
+			if settings.Features == nil {
+				settings.Features = map[string]bool{}
+			}
+			settings.Features[feature] = enabled
+		}
+	}
+	return settings, nil
+}
+
+// SetPfSettings applies the promiscuous mode, the all-multicast mode and the ethtool features which are set,
+// the settings are read again after the change because the drivers may ignore the requests they don't support
+func (n *network) SetPfSettings(ifaceName string, settings *sriovnetworkv1.PfSettings) error {
+	if settings == nil {
+		return nil
+	}
+	funcLog := log.Log.WithValues("device", ifaceName)
+	funcLog.V(2).Info("SetPfSettings(): configure PF settings", "settings", settings)
+	link, err := n.netlinkLib.LinkByName(ifaceName)
+	if err != nil {
+		funcLog.Error(err, "SetPfSettings(): failed to get link")
+		return err
+	}
+	if settings.Promisc != nil {
+		setPromisc := n.netlinkLib.LinkSetPromiscOff
+		if *settings.Promisc {
+			setPromisc = n.netlinkLib.LinkSetPromiscOn
+		}
+		if err := setPromisc(link); err != nil {
+			funcLog.Error(err, "SetPfSettings(): failed to set promiscuous mode", "promisc", *settings.Promisc)
+			return err
+		}
+	}
+	if settings.AllMulticast != nil {
+		setAllMulticast := n.netlinkLib.LinkSetAllmulticastOff
+		if *settings.AllMulticast {
+			setAllMulticast = n.netlinkLib.LinkSetAllmulticastOn
+		}
+		if err := setAllMulticast(link); err != nil {
+			funcLog.Error(err, "SetPfSettings(): failed to set all-multicast mode", "allMulticast", *settings.AllMulticast)
+			return err
+		}
+	}
+	if len(settings.Features) > 0 {
+		knownFeatures, err := n.ethtoolLib.FeatureNames(ifaceName)
+		if err != nil {
+			funcLog.Error(err, "SetPfSettings(): can't list supported features")
+			return err
+		}
+		for feature := range settings.Features {
+			if _, isKnown := knownFeatures[feature]; !isKnown {
+				return fmt.Errorf("feature %s is not supported by device %s", feature, ifaceName)
+			}
+		}
+		if err := n.ethtoolLib.Change(ifaceName, settings.Features); err != nil {
+			funcLog.Error(err, "SetPfSettings(): can't set features for device")
+			return err
+		}
+	}
+	current, err := n.GetPfSettings(ifaceName)
+	if err != nil {
+		return err
+	}
+	if mismatch := pfSettingsMismatch(settings, current); mismatch != "" {
+		return fmt.Errorf("%s of device %s can't be changed", mismatch, ifaceName)
+	}
+	return nil
+}
+
+// pfSettingsMismatch returns the name of the first setting of desired which differs from current,
+// empty string if the interface uses all the desired settings
+func pfSettingsMismatch(desired, current *sriovnetworkv1.PfSettings) string {
+	if desired.Promisc != nil && (current.Promisc == nil || *current.Promisc != *desired.Promisc) {
+		return "promiscuous mode"
+	}
+	if desired.AllMulticast != nil && (current.AllMulticast == nil || *current.AllMulticast != *desired.AllMulticast) {
+		return "all-multicast mode"
+	}
+	for _, feature := range sriovnetworkv1.PfSettingsFeatures {
+		desiredEnabled, requested := desired.Features[feature]
+		if !requested {
+			continue
+		}
+		if enabled, found := current.Features[feature]; !found || enabled != desiredEnabled {
+			return "feature " + feature
+		}
+	}
+	return ""
+}
+
+// GetNetDevBondMaster returns the name of the bond the network interface is enslaved to,
+// empty string if the interface has no master or if its master is not a bond
+func (n *network) GetNetDevBondMaster(ifaceName string) string {
+	log.Log.V(2).Info("GetNetDevBondMaster(): get bond master", "device", ifaceName)
+	if len(ifaceName) == 0 {
+		return ""
+	}
+	masterLink, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, ifaceName, "master"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Log.Error(err, "GetNetDevBondMaster(): failed to read master link", "device", ifaceName)
+		}
+		return ""
+	}
+	master := filepath.Base(masterLink)
+	if _, err := os.Stat(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, master, "bonding")); err != nil {
+		// the interface is enslaved to another kind of device, e.g. a bridge
+		return ""
+	}
+	return master
+}
+
+// IsPartOfBond returns true and the name of the master if the netdevice of the PF is enslaved to a bond or
+// to a team, the master is read from the /sys/class/net/<ifname>/master symlink. The masters of the other
+// kinds, e.g. bridges or OVS, are ignored.
+func (n *network) IsPartOfBond(pciAddress string) (bool, string, error) {
+	names, err := n.dputilsLib.GetNetNames(pciAddress)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get the netdevice of %s: %v", pciAddress, err)
+	}
+	if len(names) == 0 {
+		// a PF without netdevice, e.g. bound to vfio-pci, can't be enslaved
+		return false, "", nil
+	}
+	masterLink, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, names[0], "master"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("failed to read the master of %s: %v", names[0], err)
+	}
+	master := filepath.Base(masterLink)
+	if _, err := os.Stat(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, master, "bonding")); err == nil {
+		return true, master, nil
+	}
+	// team devices don't have a dedicated sysfs directory, they are identified by their device type
+	uevent, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, master, "uevent"))
+	if err == nil && slices.Contains(strings.Split(string(uevent), "\n"), "DEVTYPE=team") {
+		return true, master, nil
+	}
+	return false, "", nil
+}
+
 // GetNetDevLinkAdminState returns the admin state of the interface.
 func (n *network) GetNetDevLinkAdminState(ifaceName string) string {
 	log.Log.V(2).Info("GetNetDevLinkAdminState(): get LinkAdminState", "device", ifaceName)
@@ -413,6 +677,31 @@ func (n *network) GetNetDevLinkAdminState(ifaceName string) string {
 	return consts.LinkAdminStateDown
 }
 
+// SetNetDevLinkAdminState sets the admin state of the interface to "up" or "down"
+func (n *network) SetNetDevLinkAdminState(ifaceName string, state string) error {
+	log.Log.V(2).Info("SetNetDevLinkAdminState(): set LinkAdminState", "device", ifaceName, "state", state)
+	if state != consts.LinkAdminStateUp && state != consts.LinkAdminStateDown {
+		return fmt.Errorf("unknown link admin state %q", state)
+	}
+	link, err := n.netlinkLib.LinkByName(ifaceName)
+	if err != nil {
+		log.Log.Error(err, "SetNetDevLinkAdminState(): failed to get link", "device", ifaceName)
+		return err
+	}
+	isUp := n.netlinkLib.IsLinkAdminStateUp(link)
+	switch {
+	case state == consts.LinkAdminStateUp && !isUp:
+		err = n.netlinkLib.LinkSetUp(link)
+	case state == consts.LinkAdminStateDown && isUp:
+		err = n.netlinkLib.LinkSetDown(link)
+	}
+	if err != nil {
+		log.Log.Error(err, "SetNetDevLinkAdminState(): failed to set link admin state", "device", ifaceName, "state", state)
+		return err
+	}
+	return nil
+}
+
 // GetPciAddressFromInterfaceName parses sysfs to get pci address of an interface by name
 func (n *network) GetPciAddressFromInterfaceName(interfaceName string) (string, error) {
 	log.Log.V(2).Info("GetPciAddressFromInterfaceName(): get pci address", "interface", interfaceName)
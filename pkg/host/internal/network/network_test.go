@@ -5,26 +5,28 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/safchain/ethtool"
 	"github.com/vishvananda/netlink"
-	"github.com/vishvananda/netlink/nl"
 
 	"github.com/golang/mock/gomock"
 
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	hostMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
 	dputilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils/mock"
 	ethtoolMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ethtool/mock"
+	netlinkPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
 	netlinkMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink/mock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
 )
 
-func getDevlinkParam(t uint8, value interface{}) *netlink.DevlinkParam {
-	return &netlink.DevlinkParam{
+func getDevlinkParam(t uint8, value interface{}) *netlinkPkg.DevlinkParam {
+	return &netlinkPkg.DevlinkParam{
 		Name: "test_param",
 		Type: t,
-		Values: []netlink.DevlinkParamValue{
-			{Data: value, CMODE: nl.DEVLINK_PARAM_CMODE_DRIVERINIT}},
+		Values: []netlinkPkg.DevlinkParamValue{
+			{Data: value, CMODE: netlinkPkg.DevlinkParamCmodeDriverinit}},
 	}
 }
 
@@ -55,35 +57,35 @@ var _ = Describe("Network", func() {
 	Context("GetDevlinkDeviceParam", func() {
 		It("get - string", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_STRING, "test_value"), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeString, "test_value"), nil)
 			result, err := n.GetDevlinkDeviceParam("0000:d8:00.1", "param_name")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal("test_value"))
 		})
 		It("get - uint8", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_U8, uint8(8)), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeU8, uint8(8)), nil)
 			result, err := n.GetDevlinkDeviceParam("0000:d8:00.1", "param_name")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal("8"))
 		})
 		It("get - uint16", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_U16, uint16(16)), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeU16, uint16(16)), nil)
 			result, err := n.GetDevlinkDeviceParam("0000:d8:00.1", "param_name")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal("16"))
 		})
 		It("get - uint32", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_U32, uint32(32)), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeU32, uint32(32)), nil)
 			result, err := n.GetDevlinkDeviceParam("0000:d8:00.1", "param_name")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal("32"))
 		})
 		It("get - bool", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_BOOL, false), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeBool, false), nil)
 			result, err := n.GetDevlinkDeviceParam("0000:d8:00.1", "param_name")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal("false"))
@@ -97,41 +99,41 @@ var _ = Describe("Network", func() {
 	Context("SetDevlinkDeviceParam", func() {
 		It("set - string", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_STRING, "test_value"), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeString, "test_value"), nil)
 			netlinkLibMock.EXPECT().DevlinkSetDeviceParam("pci", "0000:d8:00.1", "param_name",
-				uint8(nl.DEVLINK_PARAM_CMODE_DRIVERINIT), "test_value").Return(nil)
+				uint8(netlinkPkg.DevlinkParamCmodeDriverinit), "test_value").Return(nil)
 			err := n.SetDevlinkDeviceParam("0000:d8:00.1", "param_name", "test_value")
 			Expect(err).NotTo(HaveOccurred())
 		})
 		It("set - uint8", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_U8, uint8(8)), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeU8, uint8(8)), nil)
 			netlinkLibMock.EXPECT().DevlinkSetDeviceParam("pci", "0000:d8:00.1", "param_name",
-				uint8(nl.DEVLINK_PARAM_CMODE_DRIVERINIT), uint8(100)).Return(nil)
+				uint8(netlinkPkg.DevlinkParamCmodeDriverinit), uint8(100)).Return(nil)
 			err := n.SetDevlinkDeviceParam("0000:d8:00.1", "param_name", "100")
 			Expect(err).NotTo(HaveOccurred())
 		})
 		It("set - uint16", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_U16, uint16(16)), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeU16, uint16(16)), nil)
 			netlinkLibMock.EXPECT().DevlinkSetDeviceParam("pci", "0000:d8:00.1", "param_name",
-				uint8(nl.DEVLINK_PARAM_CMODE_DRIVERINIT), uint16(100)).Return(nil)
+				uint8(netlinkPkg.DevlinkParamCmodeDriverinit), uint16(100)).Return(nil)
 			err := n.SetDevlinkDeviceParam("0000:d8:00.1", "param_name", "100")
 			Expect(err).NotTo(HaveOccurred())
 		})
 		It("set - uint32", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_U32, uint32(32)), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeU32, uint32(32)), nil)
 			netlinkLibMock.EXPECT().DevlinkSetDeviceParam("pci", "0000:d8:00.1", "param_name",
-				uint8(nl.DEVLINK_PARAM_CMODE_DRIVERINIT), uint32(100)).Return(nil)
+				uint8(netlinkPkg.DevlinkParamCmodeDriverinit), uint32(100)).Return(nil)
 			err := n.SetDevlinkDeviceParam("0000:d8:00.1", "param_name", "100")
 			Expect(err).NotTo(HaveOccurred())
 		})
 		It("set - bool", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_BOOL, false), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeBool, false), nil)
 			netlinkLibMock.EXPECT().DevlinkSetDeviceParam("pci", "0000:d8:00.1", "param_name",
-				uint8(nl.DEVLINK_PARAM_CMODE_DRIVERINIT), true).Return(nil)
+				uint8(netlinkPkg.DevlinkParamCmodeDriverinit), true).Return(nil)
 			err := n.SetDevlinkDeviceParam("0000:d8:00.1", "param_name", "true")
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -143,20 +145,77 @@ var _ = Describe("Network", func() {
 		})
 		It("failed to set", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_BOOL, false), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeBool, false), nil)
 			netlinkLibMock.EXPECT().DevlinkSetDeviceParam("pci", "0000:d8:00.1", "param_name",
-				uint8(nl.DEVLINK_PARAM_CMODE_DRIVERINIT), true).Return(testErr)
+				uint8(netlinkPkg.DevlinkParamCmodeDriverinit), true).Return(testErr)
 			err := n.SetDevlinkDeviceParam("0000:d8:00.1", "param_name", "true")
 			Expect(err).To(HaveOccurred())
 		})
 		It("failed to convert type on set", func() {
 			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
-				getDevlinkParam(nl.DEVLINK_PARAM_TYPE_U8, 10), nil)
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeU8, 10), nil)
 			// uint8 overflow
 			err := n.SetDevlinkDeviceParam("0000:d8:00.1", "param_name", "10000")
 			Expect(err).To(HaveOccurred())
 		})
 	})
+	Context("IsDevlinkDeviceParamPermanent", func() {
+		It("permanent", func() {
+			param := getDevlinkParam(netlinkPkg.DevlinkParamTypeBool, true)
+			param.Values[0].CMODE = netlinkPkg.DevlinkParamCmodePermanent
+			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(param, nil)
+			Expect(n.IsDevlinkDeviceParamPermanent("0000:d8:00.1", "param_name")).To(BeTrue())
+		})
+		It("driverinit", func() {
+			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(
+				getDevlinkParam(netlinkPkg.DevlinkParamTypeBool, true), nil)
+			Expect(n.IsDevlinkDeviceParamPermanent("0000:d8:00.1", "param_name")).To(BeFalse())
+		})
+		It("failed", func() {
+			netlinkLibMock.EXPECT().DevlinkGetDeviceParamByName("pci", "0000:d8:00.1", "param_name").Return(nil, testErr)
+			_, err := n.IsDevlinkDeviceParamPermanent("0000:d8:00.1", "param_name")
+			Expect(err).To(MatchError(testErr))
+		})
+	})
+	Context("GetNetDevFirmwareVersion", func() {
+		It("Succeed", func() {
+			ethtoolLibMock.EXPECT().DriverInfo("enp216s0f0np0").Return(ethtool.DrvInfo{FwVersion: "22.31.1014 (MT_0000000359)"}, nil)
+			Expect(n.GetNetDevFirmwareVersion("enp216s0f0np0")).To(Equal("22.31.1014 (MT_0000000359)"))
+		})
+		It("Failed", func() {
+			ethtoolLibMock.EXPECT().DriverInfo("enp216s0f0np0").Return(ethtool.DrvInfo{}, testErr)
+			Expect(n.GetNetDevFirmwareVersion("enp216s0f0np0")).To(BeEmpty())
+		})
+	})
+	Context("SetNetDevLinkAdminState", func() {
+		It("should set link up", func() {
+			linkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(linkMock, nil)
+			netlinkLibMock.EXPECT().IsLinkAdminStateUp(linkMock).Return(false)
+			netlinkLibMock.EXPECT().LinkSetUp(linkMock).Return(nil)
+			Expect(n.SetNetDevLinkAdminState("enp216s0f0np0", "up")).NotTo(HaveOccurred())
+		})
+		It("should set link down", func() {
+			linkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(linkMock, nil)
+			netlinkLibMock.EXPECT().IsLinkAdminStateUp(linkMock).Return(true)
+			netlinkLibMock.EXPECT().LinkSetDown(linkMock).Return(nil)
+			Expect(n.SetNetDevLinkAdminState("enp216s0f0np0", "down")).NotTo(HaveOccurred())
+		})
+		It("should do nothing if link is already in the requested state", func() {
+			linkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(linkMock, nil)
+			netlinkLibMock.EXPECT().IsLinkAdminStateUp(linkMock).Return(true)
+			Expect(n.SetNetDevLinkAdminState("enp216s0f0np0", "up")).NotTo(HaveOccurred())
+		})
+		It("should fail for unknown state", func() {
+			Expect(n.SetNetDevLinkAdminState("enp216s0f0np0", "unknown")).To(HaveOccurred())
+		})
+		It("should fail if link not found", func() {
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(nil, testErr)
+			Expect(n.SetNetDevLinkAdminState("enp216s0f0np0", "up")).To(MatchError(testErr))
+		})
+	})
 	Context("EnableHwTcOffload", func() {
 		It("Enabled", func() {
 			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{"hw-tc-offload": 42}, nil)
@@ -204,6 +263,113 @@ var _ = Describe("Network", func() {
 			Expect(n.EnableHwTcOffload("enp216s0f0np0")).To(MatchError(testErr))
 		})
 	})
+	Context("ConfigureEncapOffload", func() {
+		It("Enabled", func() {
+			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{"tx-udp_tnl-segmentation": 42}, nil)
+			ethtoolLibMock.EXPECT().Change("enp216s0f0np0", map[string]bool{"tx-udp_tnl-segmentation": true}).Return(nil)
+			ethtoolLibMock.EXPECT().Features("enp216s0f0np0").Return(map[string]bool{"tx-udp_tnl-segmentation": true}, nil)
+			Expect(n.ConfigureEncapOffload("enp216s0f0np0", false, true)).NotTo(HaveOccurred())
+		})
+		It("Disabled", func() {
+			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{"tx-udp_tnl-segmentation": 42}, nil)
+			ethtoolLibMock.EXPECT().Change("enp216s0f0np0", map[string]bool{"tx-udp_tnl-segmentation": false}).Return(nil)
+			ethtoolLibMock.EXPECT().Features("enp216s0f0np0").Return(map[string]bool{"tx-udp_tnl-segmentation": false}, nil)
+			Expect(n.ConfigureEncapOffload("enp216s0f0np0", false, false)).NotTo(HaveOccurred())
+		})
+		It("Feature unknown - disable", func() {
+			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{}, nil)
+			Expect(n.ConfigureEncapOffload("enp216s0f0np0", false, false)).NotTo(HaveOccurred())
+		})
+		It("fail - feature unknown", func() {
+			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{}, nil)
+			Expect(n.ConfigureEncapOffload("enp216s0f0np0", true, false)).To(
+				MatchError("encapsulation offload is not supported by device enp216s0f0np0"))
+		})
+		It("fail - fixed feature", func() {
+			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{"tx-udp_tnl-segmentation": 42}, nil)
+			ethtoolLibMock.EXPECT().Change("enp216s0f0np0", map[string]bool{"tx-udp_tnl-segmentation": true}).Return(nil)
+			ethtoolLibMock.EXPECT().Features("enp216s0f0np0").Return(map[string]bool{"tx-udp_tnl-segmentation": false}, nil)
+			Expect(n.ConfigureEncapOffload("enp216s0f0np0", true, true)).To(
+				MatchError("encapsulation offload of device enp216s0f0np0 can't be changed to true"))
+		})
+		It("fail - can't change features", func() {
+			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{"tx-udp_tnl-segmentation": 42}, nil)
+			ethtoolLibMock.EXPECT().Change("enp216s0f0np0", map[string]bool{"tx-udp_tnl-segmentation": true}).Return(testErr)
+			Expect(n.ConfigureEncapOffload("enp216s0f0np0", true, false)).To(MatchError(testErr))
+		})
+	})
+	Context("GetEncapOffloadState", func() {
+		It("Enabled", func() {
+			ethtoolLibMock.EXPECT().Features("enp216s0f0np0").Return(map[string]bool{"tx-udp_tnl-segmentation": true}, nil)
+			vxlan, geneve, err := n.GetEncapOffloadState("enp216s0f0np0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vxlan).To(BeTrue())
+			Expect(geneve).To(BeTrue())
+		})
+		It("fail - can't get features", func() {
+			ethtoolLibMock.EXPECT().Features("enp216s0f0np0").Return(nil, testErr)
+			_, _, err := n.GetEncapOffloadState("enp216s0f0np0")
+			Expect(err).To(MatchError(testErr))
+		})
+	})
+	Context("GetPfSettings", func() {
+		It("returns the settings and the supported whitelisted features", func() {
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(
+				&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0", Promisc: 1}}, nil)
+			ethtoolLibMock.EXPECT().Features("enp216s0f0np0").Return(
+				map[string]bool{"rx-all": false, "rx-vlan-filter": true, "hw-tc-offload": true}, nil)
+			settings, err := n.GetPfSettings("enp216s0f0np0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*settings.Promisc).To(BeTrue())
+			Expect(*settings.AllMulticast).To(BeFalse())
+			Expect(settings.Features).To(Equal(map[string]bool{"rx-all": false, "rx-vlan-filter": true}))
+		})
+		It("fail - can't get link", func() {
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(nil, testErr)
+			_, err := n.GetPfSettings("enp216s0f0np0")
+			Expect(err).To(MatchError(testErr))
+		})
+	})
+	Context("SetPfSettings", func() {
+		var (
+			enabled  = true
+			disabled = false
+		)
+		It("applies the settings", func() {
+			link := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0"}}
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(link, nil)
+			netlinkLibMock.EXPECT().LinkSetPromiscOn(link).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetAllmulticastOff(link).Return(nil)
+			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{"rx-all": 42}, nil)
+			ethtoolLibMock.EXPECT().Change("enp216s0f0np0", map[string]bool{"rx-all": true}).Return(nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(
+				&netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0", Promisc: 1}}, nil)
+			ethtoolLibMock.EXPECT().Features("enp216s0f0np0").Return(map[string]bool{"rx-all": true}, nil)
+			Expect(n.SetPfSettings("enp216s0f0np0", &sriovnetworkv1.PfSettings{
+				Promisc: &enabled, AllMulticast: &disabled, Features: map[string]bool{"rx-all": true}})).NotTo(HaveOccurred())
+		})
+		It("fail - feature unknown", func() {
+			link := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0"}}
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(link, nil)
+			ethtoolLibMock.EXPECT().FeatureNames("enp216s0f0np0").Return(map[string]uint{}, nil)
+			Expect(n.SetPfSettings("enp216s0f0np0", &sriovnetworkv1.PfSettings{Features: map[string]bool{"rx-fcs": true}})).To(
+				MatchError("feature rx-fcs is not supported by device enp216s0f0np0"))
+		})
+		It("fail - promiscuous mode not applied", func() {
+			link := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0"}}
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(link, nil).Times(2)
+			netlinkLibMock.EXPECT().LinkSetPromiscOn(link).Return(nil)
+			ethtoolLibMock.EXPECT().Features("enp216s0f0np0").Return(map[string]bool{}, nil)
+			Expect(n.SetPfSettings("enp216s0f0np0", &sriovnetworkv1.PfSettings{Promisc: &enabled})).To(
+				MatchError("promiscuous mode of device enp216s0f0np0 can't be changed"))
+		})
+		It("fail - can't set all-multicast mode", func() {
+			link := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "enp216s0f0np0"}}
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(link, nil)
+			netlinkLibMock.EXPECT().LinkSetAllmulticastOn(link).Return(testErr)
+			Expect(n.SetPfSettings("enp216s0f0np0", &sriovnetworkv1.PfSettings{AllMulticast: &enabled})).To(MatchError(testErr))
+		})
+	})
 	Context("GetNetDevNodeGUID", func() {
 		It("Returns empty when pciAddr is empty", func() {
 			Expect(n.GetNetDevNodeGUID("")).To(Equal(""))
@@ -234,7 +400,7 @@ var _ = Describe("Network", func() {
 			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
 				Dirs: []string{"/sys/bus/pci/devices/0000:4b:00.3/infiniband/mlx5_2"},
 			})
-			netlinkLibMock.EXPECT().RdmaLinkByName("mlx5_2").Return(&netlink.RdmaLink{Attrs: netlink.RdmaLinkAttrs{NodeGuid: "1122:3344:5566:7788"}}, nil)
+			netlinkLibMock.EXPECT().RdmaLinkByName("mlx5_2").Return(&netlinkPkg.RdmaLink{Attrs: netlinkPkg.RdmaLinkAttrs{NodeGuid: "1122:3344:5566:7788"}}, nil)
 			Expect(n.GetNetDevNodeGUID("0000:4b:00.3")).To(Equal("1122:3344:5566:7788"))
 		})
 	})
@@ -271,6 +437,89 @@ var _ = Describe("Network", func() {
 			Expect(index).To(Equal(-1))
 		})
 	})
+	Context("SetRDMASubsystemNetnsMode", func() {
+		It("Should set the mode", func() {
+			netlinkLibMock.EXPECT().RdmaSystemSetNetnsMode("exclusive").Return(nil)
+			netlinkLibMock.EXPECT().RdmaSystemGetNetnsMode().Return("exclusive", nil)
+			Expect(n.SetRDMASubsystemNetnsMode("exclusive")).NotTo(HaveOccurred())
+		})
+		It("Should fail if the mode is not applied", func() {
+			netlinkLibMock.EXPECT().RdmaSystemSetNetnsMode("exclusive").Return(nil)
+			netlinkLibMock.EXPECT().RdmaSystemGetNetnsMode().Return("shared", nil)
+			Expect(n.SetRDMASubsystemNetnsMode("exclusive")).To(MatchError(ContainSubstring("RDMA subsystem netns mode is shared")))
+		})
+		It("Should fail if the mode can't be set", func() {
+			netlinkLibMock.EXPECT().RdmaSystemSetNetnsMode("exclusive").Return(testErr)
+			Expect(n.SetRDMASubsystemNetnsMode("exclusive")).To(MatchError(ContainSubstring("failed to set RDMA subsystem netns mode")))
+		})
+	})
+	Context("GetNetDevBondMaster", func() {
+		It("Should return the bond master", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:     []string{"/sys/class/net/enp216s0f0np0", "/sys/class/net/bond0/bonding"},
+				Symlinks: map[string]string{"/sys/class/net/enp216s0f0np0/master": "../bond0"},
+			})
+			Expect(n.GetNetDevBondMaster("enp216s0f0np0")).To(Equal("bond0"))
+		})
+		It("Should ignore a master which is not a bond", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:     []string{"/sys/class/net/enp216s0f0np0", "/sys/class/net/br0/bridge"},
+				Symlinks: map[string]string{"/sys/class/net/enp216s0f0np0/master": "../br0"},
+			})
+			Expect(n.GetNetDevBondMaster("enp216s0f0np0")).To(BeEmpty())
+		})
+		It("Should return empty string if the interface has no master", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/class/net/enp216s0f0np0"},
+			})
+			Expect(n.GetNetDevBondMaster("enp216s0f0np0")).To(BeEmpty())
+		})
+	})
+	Context("IsPartOfBond", func() {
+		It("Should return the bond master", func() {
+			dputilsLibMock.EXPECT().GetNetNames("0000:d8:00.0").Return([]string{"enp216s0f0np0"}, nil)
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:     []string{"/sys/class/net/enp216s0f0np0", "/sys/class/net/bond0/bonding"},
+				Symlinks: map[string]string{"/sys/class/net/enp216s0f0np0/master": "../bond0"},
+			})
+			bonded, master, err := n.IsPartOfBond("0000:d8:00.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bonded).To(BeTrue())
+			Expect(master).To(Equal("bond0"))
+		})
+		It("Should return the team master", func() {
+			dputilsLibMock.EXPECT().GetNetNames("0000:d8:00.0").Return([]string{"enp216s0f0np0"}, nil)
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:     []string{"/sys/class/net/enp216s0f0np0", "/sys/class/net/team0"},
+				Symlinks: map[string]string{"/sys/class/net/enp216s0f0np0/master": "../team0"},
+				Files:    map[string][]byte{"/sys/class/net/team0/uevent": []byte("DEVTYPE=team\nINTERFACE=team0\nIFINDEX=12\n")},
+			})
+			bonded, master, err := n.IsPartOfBond("0000:d8:00.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bonded).To(BeTrue())
+			Expect(master).To(Equal("team0"))
+		})
+		It("Should ignore a master which is not a bond", func() {
+			dputilsLibMock.EXPECT().GetNetNames("0000:d8:00.0").Return([]string{"enp216s0f0np0"}, nil)
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:     []string{"/sys/class/net/enp216s0f0np0", "/sys/class/net/br0/bridge"},
+				Symlinks: map[string]string{"/sys/class/net/enp216s0f0np0/master": "../br0"},
+				Files:    map[string][]byte{"/sys/class/net/br0/uevent": []byte("DEVTYPE=bridge\nINTERFACE=br0\n")},
+			})
+			bonded, _, err := n.IsPartOfBond("0000:d8:00.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bonded).To(BeFalse())
+		})
+		It("Should return false if the PF has no master", func() {
+			dputilsLibMock.EXPECT().GetNetNames("0000:d8:00.0").Return([]string{"enp216s0f0np0"}, nil)
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/class/net/enp216s0f0np0"},
+			})
+			bonded, _, err := n.IsPartOfBond("0000:d8:00.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bonded).To(BeFalse())
+		})
+	})
 	Context("GetPciAddressFromInterfaceName", func() {
 		It("Should get PCI address from sys fs", func() {
 			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
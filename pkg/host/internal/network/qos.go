@@ -0,0 +1,205 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	// maximum time the packets can wait in the token bucket filter queue before they are dropped
+	vfEgressShapingLatency = "50ms"
+	// minimal size of the token bucket, must be big enough to fit a packet with the maximum MTU
+	vfEgressShapingMinBurst = 64 * 1024
+)
+
+// tc filters which mark the traffic sent by the VF with the DSCP value,
+// filters are identified by the priority and the configured value is kept in the action cookie
+var vfDscpFilters = []struct {
+	pref     string
+	protocol string
+	// pedit arguments which set the DSCP bits of the IP header
+	pedit []string
+}{
+	{pref: "49100", protocol: "ip", pedit: []string{"munge", "ip", "dsfield"}},
+	{pref: "49101", protocol: "ipv6", pedit: []string{"munge", "ip6", "traffic_class"}},
+}
+
+// tcQdisc contains the fields of "tc -j qdisc show" output used by the operator
+type tcQdisc struct {
+	Kind    string `json:"kind"`
+	Root    bool   `json:"root"`
+	Options struct {
+		// rate in bytes per second
+		Rate uint64 `json:"rate"`
+	} `json:"options"`
+}
+
+// ConfigureVfQoS configures DSCP marking and egress shaping of the traffic sent by the VF netdevice,
+// negative dscp disables DSCP marking and zero egressBandwidthMbps disables egress shaping.
+// The current tc configuration of the VF is checked first and only the settings which differ are updated.
+func (n *network) ConfigureVfQoS(pfName string, vfID int, dscp int32, egressBandwidthMbps int32) error {
+	funcLog := log.Log.WithValues("pf", pfName, "vfID", vfID, "dscp", dscp, "egressBandwidthMbps", egressBandwidthMbps)
+	funcLog.V(2).Info("ConfigureVfQoS(): configure VF QoS")
+	qosRequested := dscp >= 0 || egressBandwidthMbps > 0
+
+	vfName, err := getVfNetdevName(pfName, vfID)
+	if err != nil {
+		funcLog.Error(err, "ConfigureVfQoS(): failed to get VF netdevice name")
+		return err
+	}
+	if vfName == "" {
+		if qosRequested {
+			// tc configuration moves with the netdevice, the VF will be configured when it's returned to the host
+			funcLog.Info("ConfigureVfQoS(): VF netdevice not found in the host network namespace, skip QoS configuration")
+		}
+		return nil
+	}
+	funcLog = funcLog.WithValues("vf", vfName)
+
+	qdiscs, err := n.getTcQdiscs(vfName)
+	if err != nil {
+		if !qosRequested && errors.Is(err, exec.ErrNotFound) {
+			// QoS can't be configured without tc, nothing to remove
+			return nil
+		}
+		funcLog.Error(err, "ConfigureVfQoS(): failed to read qdiscs of the VF")
+		return err
+	}
+	if err := n.configureVfEgressShaping(vfName, qdiscs, egressBandwidthMbps); err != nil {
+		funcLog.Error(err, "ConfigureVfQoS(): failed to configure egress shaping")
+		return err
+	}
+	if err := n.configureVfDscpMarking(vfName, qdiscs, dscp); err != nil {
+		funcLog.Error(err, "ConfigureVfQoS(): failed to configure DSCP marking")
+		return err
+	}
+	return nil
+}
+
+// getVfNetdevName returns name of the VF netdevice, empty name is returned if the VF has no netdevice
+// in the current network namespace
+func getVfNetdevName(pfName string, vfID int) (string, error) {
+	netDir := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, pfName, "device", "virtfn"+strconv.Itoa(vfID), "net")
+	entries, err := os.ReadDir(netDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+	return entries[0].Name(), nil
+}
+
+func (n *network) getTcQdiscs(ifaceName string) ([]tcQdisc, error) {
+	stdout, stderr, err := n.utilsHelper.RunCommand("tc", "-j", "qdisc", "show", "dev", ifaceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list qdiscs: %w: %s", err, stderr)
+	}
+	qdiscs := []tcQdisc{}
+	if err := json.Unmarshal([]byte(stdout), &qdiscs); err != nil {
+		return nil, fmt.Errorf("failed to parse qdiscs: %v", err)
+	}
+	return qdiscs, nil
+}
+
+func (n *network) configureVfEgressShaping(vfName string, qdiscs []tcQdisc, egressBandwidthMbps int32) error {
+	var current *tcQdisc
+	for i := range qdiscs {
+		if qdiscs[i].Root && qdiscs[i].Kind == "tbf" {
+			current = &qdiscs[i]
+		}
+	}
+	if egressBandwidthMbps <= 0 {
+		if current == nil {
+			return nil
+		}
+		log.Log.V(2).Info("configureVfEgressShaping(): remove egress shaping", "vf", vfName)
+		return n.runTc("qdisc", "del", "dev", vfName, "root")
+	}
+
+	rate := uint64(egressBandwidthMbps) * 1000 * 1000 / 8
+	if current != nil && current.Options.Rate == rate {
+		log.Log.V(2).Info("configureVfEgressShaping(): egress shaping is already configured", "vf", vfName)
+		return nil
+	}
+	// the bucket should contain at least the amount of data sent during 1ms
+	burst := max(rate/1000, vfEgressShapingMinBurst)
+	log.Log.V(2).Info("configureVfEgressShaping(): configure egress shaping", "vf", vfName, "rate", egressBandwidthMbps)
+	return n.runTc("qdisc", "replace", "dev", vfName, "root", "tbf",
+		"rate", strconv.Itoa(int(egressBandwidthMbps))+"mbit",
+		"burst", strconv.FormatUint(burst, 10),
+		"latency", vfEgressShapingLatency)
+}
+
+func (n *network) configureVfDscpMarking(vfName string, qdiscs []tcQdisc, dscp int32) error {
+	hasClsact := false
+	for _, q := range qdiscs {
+		if q.Kind == "clsact" {
+			hasClsact = true
+		}
+	}
+	if !hasClsact {
+		if dscp < 0 {
+			return nil
+		}
+		if err := n.runTc("qdisc", "add", "dev", vfName, "clsact"); err != nil {
+			return err
+		}
+	}
+
+	cookie := fmt.Sprintf("%02x", dscp)
+	for _, f := range vfDscpFilters {
+		stdout, stderr, err := n.utilsHelper.RunCommand("tc", "filter", "show", "dev", vfName, "egress", "pref", f.pref)
+		if err != nil {
+			return fmt.Errorf("failed to list filters: %w: %s", err, stderr)
+		}
+		if strings.TrimSpace(stdout) != "" {
+			if dscp >= 0 && strings.Contains(stdout, "cookie "+cookie) {
+				log.Log.V(2).Info("configureVfDscpMarking(): DSCP marking is already configured",
+					"vf", vfName, "protocol", f.protocol)
+				continue
+			}
+			if err := n.runTc("filter", "del", "dev", vfName, "egress", "pref", f.pref); err != nil {
+				return err
+			}
+		}
+		if dscp < 0 {
+			continue
+		}
+		log.Log.V(2).Info("configureVfDscpMarking(): configure DSCP marking", "vf", vfName, "protocol", f.protocol)
+		args := []string{"filter", "add", "dev", vfName, "egress", "pref", f.pref, "protocol", f.protocol,
+			"matchall", "action", "pedit", "ex"}
+		args = append(args, f.pedit...)
+		// DSCP is stored in the 6 most significant bits of the field, ECN bits are kept
+		args = append(args, "set", fmt.Sprintf("0x%x", dscp<<2), "retain", "0xfc", "pipe", "cookie", cookie)
+		if f.protocol == "ip" {
+			args = append(args, "action", "csum", "ip")
+		}
+		if err := n.runTc(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *network) runTc(args ...string) error {
+	_, stderr, err := n.utilsHelper.RunCommand("tc", args...)
+	if err != nil {
+		return fmt.Errorf("tc %s failed: %w: %s", strings.Join(args, " "), err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package network
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hostMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("VF QoS", func() {
+	var (
+		n        types.NetworkInterface
+		hostMock *hostMockPkg.MockHostHelpersInterface
+		testCtrl *gomock.Controller
+	)
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		hostMock = hostMockPkg.NewMockHostHelpersInterface(testCtrl)
+		n = New(hostMock, nil, nil, nil)
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/sys/class/net/enp216s0f0np0/device/virtfn0/net/enp216s0f0v0",
+				"/sys/class/net/enp216s0f0np0/device/virtfn1/net"},
+		})
+	})
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	expectQdiscs := func(qdiscs string) {
+		hostMock.EXPECT().RunCommand("tc", "-j", "qdisc", "show", "dev", "enp216s0f0v0").Return(qdiscs, "", nil)
+	}
+	expectFilter := func(pref, filter string) {
+		hostMock.EXPECT().RunCommand("tc", "filter", "show", "dev", "enp216s0f0v0", "egress", "pref", pref).Return(filter, "", nil)
+	}
+
+	It("should configure egress shaping and DSCP marking", func() {
+		expectQdiscs(`[{"kind":"mq","handle":"0:","root":true,"options":{}}]`)
+		hostMock.EXPECT().RunCommand("tc", "qdisc", "replace", "dev", "enp216s0f0v0", "root", "tbf",
+			"rate", "1000mbit", "burst", "125000", "latency", "50ms").Return("", "", nil)
+		hostMock.EXPECT().RunCommand("tc", "qdisc", "add", "dev", "enp216s0f0v0", "clsact").Return("", "", nil)
+		expectFilter("49100", "")
+		hostMock.EXPECT().RunCommand("tc", "filter", "add", "dev", "enp216s0f0v0", "egress", "pref", "49100",
+			"protocol", "ip", "matchall", "action", "pedit", "ex", "munge", "ip", "dsfield", "set", "0xb8",
+			"retain", "0xfc", "pipe", "cookie", "2e", "action", "csum", "ip").Return("", "", nil)
+		expectFilter("49101", "")
+		hostMock.EXPECT().RunCommand("tc", "filter", "add", "dev", "enp216s0f0v0", "egress", "pref", "49101",
+			"protocol", "ipv6", "matchall", "action", "pedit", "ex", "munge", "ip6", "traffic_class", "set", "0xb8",
+			"retain", "0xfc", "pipe", "cookie", "2e").Return("", "", nil)
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 0, 46, 1000)).To(Succeed())
+	})
+
+	It("should not change configuration which is already applied", func() {
+		expectQdiscs(`[{"kind":"tbf","handle":"8001:","root":true,"options":{"rate":12500000,"burst":65536}},` +
+			`{"kind":"clsact","handle":"ffff:","parent":"ffff:fff1","options":{}}]`)
+		expectFilter("49100", "filter protocol ip pref 49100 matchall chain 0\n\taction order 1: pedit action pipe keys 1\n\tcookie 2e\n")
+		expectFilter("49101", "filter protocol ipv6 pref 49101 matchall chain 0\n\taction order 1: pedit action pipe keys 1\n\tcookie 2e\n")
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 0, 46, 100)).To(Succeed())
+	})
+
+	It("should update changed DSCP value", func() {
+		expectQdiscs(`[{"kind":"clsact","handle":"ffff:","parent":"ffff:fff1","options":{}}]`)
+		expectFilter("49100", "filter protocol ip pref 49100 matchall chain 0\n\tcookie 2e\n")
+		hostMock.EXPECT().RunCommand("tc", "filter", "del", "dev", "enp216s0f0v0", "egress", "pref", "49100").Return("", "", nil)
+		hostMock.EXPECT().RunCommand("tc", gomock.Any()).Return("", "", nil)
+		expectFilter("49101", "filter protocol ipv6 pref 49101 matchall chain 0\n\tcookie 2e\n")
+		hostMock.EXPECT().RunCommand("tc", "filter", "del", "dev", "enp216s0f0v0", "egress", "pref", "49101").Return("", "", nil)
+		hostMock.EXPECT().RunCommand("tc", gomock.Any()).Return("", "", nil)
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 0, 10, 0)).To(Succeed())
+	})
+
+	It("should remove QoS configuration", func() {
+		expectQdiscs(`[{"kind":"tbf","handle":"8001:","root":true,"options":{"rate":12500000}},` +
+			`{"kind":"clsact","handle":"ffff:","parent":"ffff:fff1","options":{}}]`)
+		hostMock.EXPECT().RunCommand("tc", "qdisc", "del", "dev", "enp216s0f0v0", "root").Return("", "", nil)
+		expectFilter("49100", "filter protocol ip pref 49100 matchall chain 0\n\tcookie 2e\n")
+		hostMock.EXPECT().RunCommand("tc", "filter", "del", "dev", "enp216s0f0v0", "egress", "pref", "49100").Return("", "", nil)
+		expectFilter("49101", "")
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 0, -1, 0)).To(Succeed())
+	})
+
+	It("should do nothing if QoS is not configured", func() {
+		expectQdiscs(`[{"kind":"mq","handle":"0:","root":true,"options":{}}]`)
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 0, -1, 0)).To(Succeed())
+	})
+
+	It("should not require tc if QoS is not requested", func() {
+		hostMock.EXPECT().RunCommand("tc", "-j", "qdisc", "show", "dev", "enp216s0f0v0").Return("", "",
+			&exec.Error{Name: "tc", Err: exec.ErrNotFound})
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 0, -1, 0)).To(Succeed())
+	})
+
+	It("should fail if tc fails", func() {
+		expectQdiscs(`[]`)
+		hostMock.EXPECT().RunCommand("tc", "qdisc", "replace", "dev", "enp216s0f0v0", "root", "tbf",
+			"rate", "100mbit", "burst", "65536", "latency", "50ms").Return("", "invalid argument", fmt.Errorf("exit status 2"))
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 0, -1, 100)).To(MatchError(ContainSubstring("invalid argument")))
+	})
+
+	It("should skip VF without netdevice in the host network namespace", func() {
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 1, 46, 100)).To(Succeed())
+		Expect(n.ConfigureVfQoS("enp216s0f0np0", 2, 46, 100)).To(Succeed())
+	})
+})
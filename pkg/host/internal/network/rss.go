@@ -0,0 +1,63 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// GetVFRSSConfig returns the RSS hash key and hash fields of the VF netdevice read with ethtool,
+// nil if the VF has no netdevice in the host network namespace
+func (n *network) GetVFRSSConfig(pfName string, vfID int) (*types.RSSConfig, error) {
+	funcLog := log.Log.WithValues("pf", pfName, "vfID", vfID)
+	vfName, err := getVfNetdevName(pfName, vfID)
+	if err != nil {
+		funcLog.Error(err, "GetVFRSSConfig(): failed to get VF netdevice name")
+		return nil, err
+	}
+	if vfName == "" {
+		funcLog.V(2).Info("GetVFRSSConfig(): VF netdevice not found in the host network namespace")
+		return nil, nil
+	}
+
+	stdout, stderr, err := n.utilsHelper.RunCommand("ethtool", "-x", vfName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the RSS configuration of %s: %w: %s", vfName, err, stderr)
+	}
+	config := &types.RSSConfig{HashKey: parseRSSHashKey(stdout)}
+	for _, f := range utils.RSSHashFields {
+		stdout, stderr, err := n.utilsHelper.RunCommand("ethtool", "-n", vfName, "rx-flow-hash", f.FlowType)
+		if err != nil {
+			// the drivers don't support the hashing of all the flow types, e.g. SCTP
+			funcLog.V(2).Info("GetVFRSSConfig(): failed to read the RSS hash of the flow type",
+				"flowType", f.FlowType, "error", err.Error(), "stderr", stderr)
+			continue
+		}
+		// the L4 ports are reported as "L4 bytes 0 & 1 [TCP/UDP src port]" and "L4 bytes 2 & 3 [TCP/UDP dst port]"
+		if strings.Contains(stdout, "L4 bytes") {
+			config.HashFields = append(config.HashFields, f.Name)
+		}
+	}
+	return config, nil
+}
+
+// parseRSSHashKey returns the hash key from "ethtool -x" output, the key is on the line
+// which follows the "RSS hash key:" header. An empty string is returned if there is no key.
+func parseRSSHashKey(output string) string {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "RSS hash key:" {
+			continue
+		}
+		if !scanner.Scan() {
+			break
+		}
+		return strings.ToLower(strings.TrimSpace(scanner.Text()))
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hostMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("VF RSS", func() {
+	var (
+		n        types.NetworkInterface
+		hostMock *hostMockPkg.MockHostHelpersInterface
+		testCtrl *gomock.Controller
+	)
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		hostMock = hostMockPkg.NewMockHostHelpersInterface(testCtrl)
+		n = New(hostMock, nil, nil, nil)
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/sys/class/net/enp216s0f0np0/device/virtfn0/net/enp216s0f0v0",
+				"/sys/class/net/enp216s0f0np0/device/virtfn1/net"},
+		})
+	})
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	const ethtoolX = `RX flow hash indirection table for enp216s0f0v0 with 4 RX ring(s):
+    0:      0     1     2     3     0     1     2     3
+RSS hash key:
+6D:5A:56:DA:25:5B:0E:C2:41:67:25:3D:43:A3:8F:B0:D0:CA:2B:CB:AE:7B:30:B4:77:CB:2D:A3:80:30:F2:0C:6A:42:B7:3B:BE:AC:01:FA
+RSS hash function:
+    toeplitz: on
+    xor: off
+`
+	l4Hash := func(flows string) string {
+		return flows + " flows use these fields for computing Hash flow key:\nIP SA\nIP DA\n" +
+			"L4 bytes 0 & 1 [TCP/UDP src port]\nL4 bytes 2 & 3 [TCP/UDP dst port]\n\n"
+	}
+	l3Hash := func(flows string) string {
+		return flows + " flows use these fields for computing Hash flow key:\nIP SA\nIP DA\n\n"
+	}
+	expectFlowHash := func(flowType string) *gomock.Call {
+		return hostMock.EXPECT().RunCommand("ethtool", "-n", "enp216s0f0v0", "rx-flow-hash", flowType)
+	}
+
+	It("should return the hash key and the flow types hashed on the L4 ports", func() {
+		hostMock.EXPECT().RunCommand("ethtool", "-x", "enp216s0f0v0").Return(ethtoolX, "", nil)
+		expectFlowHash("tcp4").Return(l4Hash("TCP over IPV4"), "", nil)
+		expectFlowHash("udp4").Return(l3Hash("UDP over IPV4"), "", nil)
+		expectFlowHash("sctp4").Return("", "Cannot get RX network flow hashing options: Operation not supported", fmt.Errorf("exit status 1"))
+		expectFlowHash("tcp6").Return(l4Hash("TCP over IPV6"), "", nil)
+		expectFlowHash("udp6").Return(l3Hash("UDP over IPV6"), "", nil)
+		expectFlowHash("sctp6").Return("", "Cannot get RX network flow hashing options: Operation not supported", fmt.Errorf("exit status 1"))
+		Expect(n.GetVFRSSConfig("enp216s0f0np0", 0)).To(Equal(&types.RSSConfig{
+			HashKey: "6d:5a:56:da:25:5b:0e:c2:41:67:25:3d:43:a3:8f:b0:d0:ca:2b:cb:" +
+				"ae:7b:30:b4:77:cb:2d:a3:80:30:f2:0c:6a:42:b7:3b:be:ac:01:fa",
+			HashFields: []string{"ipv4-tcp", "ipv6-tcp"},
+		}))
+	})
+
+	It("should return nil if the VF has no netdevice", func() {
+		Expect(n.GetVFRSSConfig("enp216s0f0np0", 1)).To(BeNil())
+	})
+
+	It("should fail if the RSS configuration can't be read", func() {
+		hostMock.EXPECT().RunCommand("ethtool", "-x", "enp216s0f0v0").Return("", "", fmt.Errorf("exit status 1"))
+		_, err := n.GetVFRSSConfig("enp216s0f0np0", 0)
+		Expect(err).To(MatchError(ContainSubstring("failed to read the RSS configuration of enp216s0f0v0")))
+	})
+})
@@ -1,18 +1,22 @@
 package service
 
 import (
+	"bytes"
+	"embed"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/coreos/go-systemd/v22/unit"
 	"gopkg.in/yaml.v3"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/fileutil"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 )
@@ -20,6 +24,17 @@ import (
 // TODO: handle this to support unit-tests
 const systemdDir = "/usr/lib/systemd/system/"
 
+const (
+	sriovConfigServiceDir = "/etc/systemd/system/"
+	sriovConfigBinaryPath = "/var/lib/sriov/sriov-network-config-daemon"
+)
+
+// sriov-config services in the order they are started
+var sriovConfigServices = []string{"sriov-config.service", "sriov-config-post-network.service"}
+
+//go:embed templates
+var templates embed.FS
+
 type service struct {
 	utilsHelper utils.CmdInterface
 }
@@ -85,7 +100,7 @@ func (s *service) ReadService(servicePath string) (*types.Service, error) {
 // EnableService creates service file and enables it with systemctl enable
 func (s *service) EnableService(service *types.Service) error {
 	// Write service file
-	err := os.WriteFile(path.Join(consts.Chroot, service.Path), []byte(service.Content), 0644)
+	err := fileutil.WriteFileAtomic(path.Join(consts.Chroot, service.Path), []byte(service.Content), 0644)
 	if err != nil {
 		return err
 	}
@@ -105,6 +120,31 @@ func (s *service) EnableService(service *types.Service) error {
 	return err
 }
 
+// DisableService disables the service with systemctl disable and removes the service file,
+// nothing is done if the service doesn't exist
+func (s *service) DisableService(service *types.Service) error {
+	exist, err := s.IsServiceExist(service.Path)
+	if err != nil || !exist {
+		return err
+	}
+	if err := s.disableService(service.Name); err != nil {
+		return err
+	}
+	return os.Remove(path.Join(consts.Chroot, service.Path))
+}
+
+func (s *service) disableService(serviceName string) error {
+	// Change root dir
+	exit, err := s.utilsHelper.Chroot(consts.Chroot)
+	if err != nil {
+		return err
+	}
+	defer exit()
+
+	_, _, err = s.utilsHelper.RunCommand("systemctl", "disable", serviceName)
+	return err
+}
+
 // CompareServices returns true if serviceA needs update(doesn't contain all fields from service B)
 func (s *service) CompareServices(serviceA, serviceB *types.Service) (bool, error) {
 	optsA, err := unit.Deserialize(strings.NewReader(serviceA.Content))
@@ -189,6 +229,34 @@ func (s *service) UpdateSystemService(serviceObj *types.Service) error {
 	return s.EnableService(updatedService)
 }
 
+// RenderSriovConfigServices renders the pre and post network sriov-config systemd services
+func (s *service) RenderSriovConfigServices(logLevel int) ([]*types.Service, error) {
+	data := struct {
+		BinaryPath string
+		LogLevel   int
+	}{
+		BinaryPath: sriovConfigBinaryPath,
+		LogLevel:   logLevel,
+	}
+	services := make([]*types.Service, 0, len(sriovConfigServices))
+	for _, name := range sriovConfigServices {
+		tmpl, err := template.ParseFS(templates, path.Join("templates", name+".tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template for service %s: %v", name, err)
+		}
+		var content bytes.Buffer
+		if err := tmpl.Execute(&content, data); err != nil {
+			return nil, fmt.Errorf("failed to render service %s: %v", name, err)
+		}
+		services = append(services, &types.Service{
+			Name:    name,
+			Path:    sriovConfigServiceDir + name,
+			Content: content.String(),
+		})
+	}
+	return services, nil
+}
+
 // appendToService appends given fields to service
 func appendToService(service *types.Service, options ...*unit.UnitOption) (*types.Service, error) {
 	serviceOptions, err := unit.Deserialize(strings.NewReader(service.Content))
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+var _ = Describe("Service", func() {
+	Context("RenderSriovConfigServices", func() {
+		It("should render pre and post network services", func() {
+			services, err := New(nil).RenderSriovConfigServices(2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(services).To(HaveLen(2))
+			for i, name := range []string{"sriov-config.service", "sriov-config-post-network.service"} {
+				expected, err := os.ReadFile(filepath.Join("testdata", name))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(services[i]).To(Equal(&types.Service{
+					Name:    name,
+					Path:    "/etc/systemd/system/" + name,
+					Content: string(expected),
+				}))
+			}
+		})
+		It("should set log level", func() {
+			services, err := New(nil).RenderSriovConfigServices(5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(services[0].Content).To(ContainSubstring("-v 5 --zap-log-level 5 service --phase pre"))
+			Expect(services[1].Content).To(ContainSubstring("-v 5 --zap-log-level 5 service --phase post"))
+		})
+	})
+})
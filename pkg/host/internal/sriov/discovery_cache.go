@@ -0,0 +1,79 @@
+package sriov
+
+import (
+	"sync"
+
+	"github.com/jaypipes/ghw"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ghwPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ghw"
+)
+
+// deviceFacts contains the facts of a PCI device which don't change while the device exists
+type deviceFacts struct {
+	vendor   string
+	deviceID string
+	numaNode *int
+}
+
+// discoveryCache keeps the data which is expensive to collect across the discoveries of the devices:
+// the PCI info of ghw, which loads the pci.ids database when it is created, and the immutable facts
+// of the devices by PCI address. The mutable data (driver, netdev name, MTU, ...) is read on each discovery.
+type discoveryCache struct {
+	lock    sync.Mutex
+	pciInfo ghwPkg.Info
+	devices map[string]deviceFacts
+}
+
+func newDiscoveryCache() *discoveryCache {
+	return &discoveryCache{devices: make(map[string]deviceFacts)}
+}
+
+// getPCIInfo returns the cached PCI info, the devices are listed again by each ListDevices call
+func (s *sriov) getPCIInfo() (ghwPkg.Info, error) {
+	s.cache.lock.Lock()
+	defer s.cache.lock.Unlock()
+	if s.cache.pciInfo != nil {
+		return s.cache.pciInfo, nil
+	}
+	pci, err := s.ghwLib.PCI()
+	if err != nil {
+		return nil, err
+	}
+	s.cache.pciInfo = pci
+	return pci, nil
+}
+
+// getDeviceFacts returns the immutable facts of the device, the facts are read only if the device
+// is not cached or if the address is reused by another kind of device (e.g. a VF of a PF in another mode)
+func (s *sriov) getDeviceFacts(device *ghw.PCIDevice) deviceFacts {
+	s.cache.lock.Lock()
+	defer s.cache.lock.Unlock()
+	if facts, ok := s.cache.devices[device.Address]; ok &&
+		facts.vendor == device.Vendor.ID && facts.deviceID == device.Product.ID {
+		return facts
+	}
+	facts := deviceFacts{vendor: device.Vendor.ID, deviceID: device.Product.ID}
+	numaNode, err := s.kernelHelper.GetDeviceNumaNode(device.Address)
+	if err != nil {
+		// not cached, the NUMA node is read again by the next discovery
+		log.Log.V(2).Info("getDeviceFacts(): failed to read NUMA node", "device", device.Address, "error", err)
+		return facts
+	}
+	if numaNode >= 0 {
+		facts.numaNode = &numaNode
+	}
+	s.cache.devices[device.Address] = facts
+	return facts
+}
+
+// pruneDeviceFacts removes the cached facts of the devices which don't exist anymore, e.g. the removed VFs
+func (s *sriov) pruneDeviceFacts(devices map[string]*ghw.PCIDevice) {
+	s.cache.lock.Lock()
+	defer s.cache.lock.Unlock()
+	for address := range s.cache.devices {
+		if _, ok := devices[address]; !ok {
+			delete(s.cache.devices, address)
+		}
+	}
+}
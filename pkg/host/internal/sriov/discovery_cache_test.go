@@ -0,0 +1,179 @@
+package sriov
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/pcidb"
+	"github.com/vishvananda/netlink"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	dputilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils/mock"
+	ghwMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ghw/mock"
+	netlinkPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
+	netlinkMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink/mock"
+	sriovnetMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/sriovnet/mock"
+	hostMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/mock"
+	hostStoreMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const discoveryTestPF = "0000:d8:00.0"
+
+// discoveryCounters contains the number of expensive reads done by the discoveries
+type discoveryCounters struct {
+	pciInfoLoads atomic.Int64
+	numaReads    atomic.Int64
+}
+
+// newDiscoveryTestDevices returns a mlx5 PF with numVFs VFs
+func newDiscoveryTestDevices(numVFs int) ([]*ghw.PCIDevice, []string) {
+	newDevice := func(address, deviceID string) *ghw.PCIDevice {
+		return &ghw.PCIDevice{
+			Address: address,
+			Vendor:  &pcidb.Vendor{ID: "15b3"},
+			Product: &pcidb.Product{ID: deviceID},
+			Class:   &pcidb.Class{ID: "02"},
+		}
+	}
+	devices := []*ghw.PCIDevice{newDevice(discoveryTestPF, "101d")}
+	vfs := make([]string, 0, numVFs)
+	for i := 0; i < numVFs; i++ {
+		address := fmt.Sprintf("0000:d9:%02x.%d", i/8, i%8)
+		devices = append(devices, newDevice(address, "101e"))
+		vfs = append(vfs, address)
+	}
+	return devices, vfs
+}
+
+// newDiscoveryTestSriov returns a sriov helper which discovers a PF with numVFs VFs with mocked host libraries,
+// the expensive reads are counted
+func newDiscoveryTestSriov(ctrl *gomock.Controller, numVFs int) (*sriov, *hostStoreMockPkg.MockManagerInterface, *discoveryCounters) {
+	devices, vfs := newDiscoveryTestDevices(numVFs)
+	counters := &discoveryCounters{}
+
+	ghwLibMock := ghwMockPkg.NewMockGHWLib(ctrl)
+	ghwInfoMock := ghwMockPkg.NewMockInfo(ctrl)
+	ghwLibMock.EXPECT().PCI().DoAndReturn(func() (*ghwMockPkg.MockInfo, error) {
+		counters.pciInfoLoads.Add(1)
+		return ghwInfoMock, nil
+	}).AnyTimes()
+	ghwInfoMock.EXPECT().ListDevices().Return(devices).AnyTimes()
+
+	dputilsLibMock := dputilsMockPkg.NewMockDPUtilsLib(ctrl)
+	dputilsLibMock.EXPECT().IsSriovVF(gomock.Any()).DoAndReturn(func(address string) bool {
+		return address != discoveryTestPF
+	}).AnyTimes()
+	dputilsLibMock.EXPECT().GetDriverName(gomock.Any()).Return("mlx5_core", nil).AnyTimes()
+	dputilsLibMock.EXPECT().IsSriovPF(discoveryTestPF).Return(true).AnyTimes()
+	dputilsLibMock.EXPECT().GetSriovVFcapacity(discoveryTestPF).Return(numVFs).AnyTimes()
+	dputilsLibMock.EXPECT().GetVFconfigured(discoveryTestPF).Return(numVFs).AnyTimes()
+	dputilsLibMock.EXPECT().SriovConfigured(discoveryTestPF).Return(true).AnyTimes()
+	dputilsLibMock.EXPECT().GetVFList(discoveryTestPF).Return(vfs, nil).AnyTimes()
+	dputilsLibMock.EXPECT().GetVFID(gomock.Any()).Return(0, nil).AnyTimes()
+
+	hostMock := hostMockPkg.NewMockHostManagerInterface(ctrl)
+	hostMock.EXPECT().TryGetInterfaceName(gomock.Any()).DoAndReturn(func(address string) string {
+		if address == discoveryTestPF {
+			return "enp216s0f0np0"
+		}
+		return ""
+	}).AnyTimes()
+	hostMock.EXPECT().GetNetDevLinkSpeed(gomock.Any()).Return("100000 Mb/s").AnyTimes()
+	hostMock.EXPECT().GetNetDevLinkAdminState(gomock.Any()).Return("up").AnyTimes()
+	hostMock.EXPECT().GetNetDevFirmwareVersion(gomock.Any()).Return("22.31.1014").AnyTimes()
+	hostMock.EXPECT().GetNetDevBondMaster(gomock.Any()).Return("").AnyTimes()
+	hostMock.EXPECT().GetNetDevNodeGUID(gomock.Any()).Return("").AnyTimes()
+	hostMock.EXPECT().DiscoverVDPAType(gomock.Any()).Return("").AnyTimes()
+	hostMock.EXPECT().GetDeviceNumaNode(gomock.Any()).DoAndReturn(func(string) (int, error) {
+		counters.numaReads.Add(1)
+		return 0, nil
+	}).AnyTimes()
+
+	netlinkLibMock := netlinkMockPkg.NewMockNetlinkLib(ctrl)
+	pfLinkMock := netlinkMockPkg.NewMockLink(ctrl)
+	pfLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{MTU: 1500, EncapType: "ether"}).AnyTimes()
+	netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil).AnyTimes()
+	netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", discoveryTestPF).Return(
+		&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil).AnyTimes()
+
+	storeManagerMock := hostStoreMockPkg.NewMockManagerInterface(ctrl)
+	storeManagerMock.EXPECT().LoadPfsStatus(discoveryTestPF).Return(nil, false, nil).AnyTimes()
+
+	s := New(nil, hostMock, hostMock, hostMock, hostMock, hostMock, netlinkLibMock, dputilsLibMock,
+		sriovnetMockPkg.NewMockSriovnetLib(ctrl), ghwLibMock, nil, hostMock).(*sriov)
+	return s, storeManagerMock, counters
+}
+
+var _ = Describe("Discovery cache", func() {
+	BeforeEach(func() {
+		origDevMode := vars.DevMode
+		DeferCleanup(func() { vars.DevMode = origDevMode })
+		vars.DevMode = true
+	})
+
+	It("should load the PCI info and read the immutable facts once", func() {
+		s, storeManager, counters := newDiscoveryTestSriov(gomock.NewController(GinkgoT()), 4)
+		for i := 0; i < 3; i++ {
+			pfs, err := s.DiscoverSriovDevices(storeManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pfs).To(HaveLen(1))
+			Expect(pfs[0].VFs).To(HaveLen(4))
+			Expect(*pfs[0].VFs[3].NumaNode).To(Equal(0))
+			Expect(pfs[0].VFs[3].DeviceID).To(Equal("101e"))
+		}
+		Expect(counters.pciInfoLoads.Load()).To(Equal(int64(1)))
+		Expect(counters.numaReads.Load()).To(Equal(int64(5)))
+	})
+
+	It("should read the facts again if the device at the address changed", func() {
+		s, _, _ := newDiscoveryTestSriov(gomock.NewController(GinkgoT()), 0)
+		device := &ghw.PCIDevice{Address: "0000:d9:00.0", Vendor: &pcidb.Vendor{ID: "15b3"}, Product: &pcidb.Product{ID: "101e"}}
+		s.cache.devices[device.Address] = deviceFacts{vendor: "8086", deviceID: "154c"}
+		Expect(*s.getDeviceFacts(device).numaNode).To(Equal(0))
+		Expect(s.cache.devices[device.Address].deviceID).To(Equal("101e"))
+	})
+
+	It("should remove the facts of the removed devices", func() {
+		s, _, _ := newDiscoveryTestSriov(gomock.NewController(GinkgoT()), 0)
+		s.cache.devices["0000:d9:00.0"] = deviceFacts{vendor: "15b3", deviceID: "101e"}
+		s.cache.devices[discoveryTestPF] = deviceFacts{vendor: "15b3", deviceID: "101d"}
+		s.pruneDeviceFacts(map[string]*ghw.PCIDevice{discoveryTestPF: {}})
+		Expect(s.cache.devices).To(HaveLen(1))
+		Expect(s.cache.devices).To(HaveKey(discoveryTestPF))
+	})
+})
+
+// BenchmarkDiscoverSriovDevices compares the discovery of a PF with 256 VFs with and without the cache
+// of the previous discoveries, the reads of the PCI info and of the NUMA nodes are reported per discovery
+func BenchmarkDiscoverSriovDevices(b *testing.B) {
+	origDevMode := vars.DevMode
+	defer func() { vars.DevMode = origDevMode }()
+	vars.DevMode = true
+
+	for _, cached := range []bool{false, true} {
+		name := "uncached"
+		if cached {
+			name = "cached"
+		}
+		b.Run(name, func(b *testing.B) {
+			s, storeManager, counters := newDiscoveryTestSriov(gomock.NewController(b), 256)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !cached {
+					s.cache = newDiscoveryCache()
+				}
+				if _, err := s.DiscoverSriovDevices(storeManager); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(counters.pciInfoLoads.Load())/float64(b.N), "pci-info-loads/op")
+			b.ReportMetric(float64(counters.numaReads.Load())/float64(b.N), "numa-reads/op")
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package sriov
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// netdevsim devices simulate SR-IOV capable NICs in the kernel, they are used to test the operator without
+// hardware (e.g. in kind-based CI) when vars.NetdevsimMode is set. The devices are on the netdevsim bus,
+// the name of the bus device (netdevsim<ID>) is used as the PCI address of the PF and the VFs are addressed
+// as <PF address>-vf<VF ID>.
+const (
+	netdevsimDriver = "netdevsim"
+	// default maximum number of VFs of a netdevsim device, the maximum is configurable with debugfs
+	netdevsimDefaultMaxVfs = 4
+)
+
+// netdevsimVFAddress returns the address used for the VF of the netdevsim device
+func netdevsimVFAddress(device string, vfID int) string {
+	return fmt.Sprintf("%s-vf%d", device, vfID)
+}
+
+// discoverNetdevsimDevices returns the netdevsim devices which have a port netdevice as PFs
+func (s *sriov) discoverNetdevsimDevices(storeManager store.ManagerInterface) ([]sriovnetworkv1.InterfaceExt, error) {
+	log.Log.V(2).Info("discoverNetdevsimDevices()")
+	pfList := []sriovnetworkv1.InterfaceExt{}
+	devicesDir := filepath.Join(vars.FilesystemRoot, consts.SysBusNetdevsim, "devices")
+	devices, err := os.ReadDir(devicesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pfList, nil
+		}
+		return nil, fmt.Errorf("discoverNetdevsimDevices(): failed to list netdevsim devices: %v", err)
+	}
+	for _, device := range devices {
+		name := device.Name()
+		netdevs, err := os.ReadDir(filepath.Join(devicesDir, name, "net"))
+		if err != nil || len(netdevs) == 0 {
+			log.Log.V(2).Info("discoverNetdevsimDevices(): device has no netdevice, skipping", "device", name)
+			continue
+		}
+		// the first port is the PF, the other ports of a device in switchdev mode are the representors
+		pfName := netdevs[0].Name()
+		link, err := s.netlinkLib.LinkByName(pfName)
+		if err != nil {
+			log.Log.Error(err, "discoverNetdevsimDevices(): unable to get Link for device, skipping", "device", name)
+			continue
+		}
+		numVfs, err := readNetdevsimAttr(filepath.Join(devicesDir, name, consts.NumVfsFile))
+		if err != nil {
+			log.Log.Error(err, "discoverNetdevsimDevices(): unable to read the number of VFs, skipping", "device", name)
+			continue
+		}
+		iface := sriovnetworkv1.InterfaceExt{
+			Name:           pfName,
+			PciAddress:     name,
+			Driver:         netdevsimDriver,
+			Mtu:            link.Attrs().MTU,
+			Mac:            link.Attrs().HardwareAddr.String(),
+			LinkType:       s.encapTypeToLinkType(link.Attrs().EncapType),
+			LinkAdminState: s.networkHelper.GetNetDevLinkAdminState(pfName),
+			NumVfs:         numVfs,
+			TotalVfs:       getNetdevsimMaxVfs(name),
+			EswitchMode:    s.getNetdevsimEswitchMode(name),
+		}
+		pfStatus, exist, err := storeManager.LoadPfsStatus(iface.PciAddress)
+		if err != nil {
+			log.Log.Error(err, "discoverNetdevsimDevices(): failed to load PF status from disk")
+		} else if exist {
+			iface.ExternallyManaged = pfStatus.ExternallyManaged
+		}
+		for vfID := 0; vfID < numVfs; vfID++ {
+			vf := sriovnetworkv1.VirtualFunction{
+				PciAddress: netdevsimVFAddress(name, vfID),
+				Driver:     netdevsimDriver,
+				VfID:       vfID,
+			}
+			setVfAdminConfig(&vf, link.Attrs().Vfs)
+			iface.VFs = append(iface.VFs, vf)
+		}
+		pfList = append(pfList, iface)
+	}
+	return pfList, nil
+}
+
+// configNetdevsimInterfaces sets the number of VFs and the PF and VF settings of the netdevsim devices,
+// the VFs of the devices which are not in the spec anymore are removed
+func (s *sriov) configNetdevsimInterfaces(storeManager store.ManagerInterface,
+	interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt) error {
+	var errs []error
+	configured := make(map[string]bool, len(interfaces))
+	for i := range interfaces {
+		iface := &interfaces[i]
+		configured[iface.PciAddress] = true
+		ifaceStatus := findInterfaceStatus(ifaceStatuses, iface.PciAddress)
+		if ifaceStatus == nil {
+			log.Log.Info("configNetdevsimInterfaces(): device not found, skipping", "device", iface.PciAddress)
+			continue
+		}
+		if !sriovnetworkv1.NeedToUpdateSriov(iface, ifaceStatus) {
+			continue
+		}
+		if err := s.configNetdevsimDevice(iface, ifaceStatus); err != nil {
+			errs = append(errs, &types.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err})
+			continue
+		}
+		if err := storeManager.SaveLastPfAppliedStatus(iface); err != nil {
+			errs = append(errs, &types.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err})
+		}
+	}
+	for _, ifaceStatus := range ifaceStatuses {
+		if configured[ifaceStatus.PciAddress] || ifaceStatus.NumVfs == 0 {
+			continue
+		}
+		pfStatus, exist, err := storeManager.LoadPfsStatus(ifaceStatus.PciAddress)
+		if err != nil || !exist || pfStatus.ExternallyManaged {
+			// the VFs were not created by the operator
+			continue
+		}
+		log.Log.Info("configNetdevsimInterfaces(): remove the VFs", "device", ifaceStatus.PciAddress)
+		if err := setNetdevsimNumVfs(ifaceStatus.PciAddress, 0); err != nil {
+			errs = append(errs, &types.InterfaceSyncError{PciAddress: ifaceStatus.PciAddress, Err: err})
+			continue
+		}
+		if err := storeManager.RemovePfAppliedStatus(ifaceStatus.PciAddress); err != nil {
+			errs = append(errs, &types.InterfaceSyncError{PciAddress: ifaceStatus.PciAddress, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// configNetdevsimDevice configures the netdevsim device like configSriovDevice configures a PCI device,
+// netdevsim supports the VF settings done through the PF but it has no VF netdevices to configure
+func (s *sriov) configNetdevsimDevice(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error {
+	log.Log.V(2).Info("configNetdevsimDevice(): configure netdevsim device", "device", iface.PciAddress)
+	if !iface.ExternallyManaged && iface.NumVfs != ifaceStatus.NumVfs {
+		if iface.NumVfs > ifaceStatus.TotalVfs {
+			return fmt.Errorf("cannot config netdevsim device: NumVfs (%d) is larger than TotalVfs (%d)",
+				iface.NumVfs, ifaceStatus.TotalVfs)
+		}
+		// the number of VFs can only be changed from 0
+		if err := setNetdevsimNumVfs(iface.PciAddress, 0); err != nil {
+			return err
+		}
+		if err := setNetdevsimNumVfs(iface.PciAddress, iface.NumVfs); err != nil {
+			return err
+		}
+	}
+	if !iface.DisablePfLinkManagement {
+		if err := s.networkHelper.SetNetDevLinkAdminState(ifaceStatus.Name, consts.LinkAdminStateUp); err != nil {
+			return err
+		}
+	}
+	pfLink, err := s.netlinkLib.LinkByName(ifaceStatus.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get the link of %s: %v", ifaceStatus.Name, err)
+	}
+	if iface.Mtu > 0 && !iface.ExternallyManaged && pfLink.Attrs().MTU != iface.Mtu {
+		if err := s.netlinkLib.LinkSetMTU(pfLink, iface.Mtu); err != nil {
+			return fmt.Errorf("failed to set the MTU of %s: %v", ifaceStatus.Name, err)
+		}
+	}
+	for vfID := 0; vfID < iface.NumVfs; vfID++ {
+		for i := range iface.VfGroups {
+			group := &iface.VfGroups[i]
+			if !sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+				continue
+			}
+			if err := s.configSriovVFTrustAndSpoofChk(pfLink, vfID, group); err != nil {
+				return err
+			}
+			if err := s.configSriovVFTxRate(pfLink, vfID, group); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// findInterfaceStatus returns the status of the interface with the PCI address, nil if not found
+func findInterfaceStatus(ifaceStatuses []sriovnetworkv1.InterfaceExt, pciAddress string) *sriovnetworkv1.InterfaceExt {
+	for i := range ifaceStatuses {
+		if ifaceStatuses[i].PciAddress == pciAddress {
+			return &ifaceStatuses[i]
+		}
+	}
+	return nil
+}
+
+// getNetdevsimEswitchMode returns the eSwitch mode of the netdevsim device, legacy if devlink doesn't report it
+func (s *sriov) getNetdevsimEswitchMode(device string) string {
+	devLink, err := s.netlinkLib.DevLinkGetDeviceByName(consts.BusNetdevsim, device)
+	if err != nil || devLink.Attrs.Eswitch.Mode == "" {
+		return sriovnetworkv1.ESwithModeLegacy
+	}
+	return devLink.Attrs.Eswitch.Mode
+}
+
+// getNetdevsimMaxVfs returns the maximum number of VFs of the netdevsim device
+func getNetdevsimMaxVfs(device string) int {
+	maxVfs, err := readNetdevsimAttr(filepath.Join(vars.FilesystemRoot, consts.NetdevsimDebugfs, device, "max_vfs"))
+	if err != nil {
+		return netdevsimDefaultMaxVfs
+	}
+	return maxVfs
+}
+
+// setNetdevsimNumVfs writes the number of VFs of the netdevsim device
+func setNetdevsimNumVfs(device string, numVfs int) error {
+	numVfsPath := filepath.Join(vars.FilesystemRoot, consts.SysBusNetdevsim, "devices", device, consts.NumVfsFile)
+	if err := os.WriteFile(numVfsPath, []byte(strconv.Itoa(numVfs)), os.ModeAppend); err != nil {
+		return fmt.Errorf("failed to set the number of VFs of %s to %d: %v", device, numVfs, err)
+	}
+	return nil
+}
+
+func readNetdevsimAttr(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
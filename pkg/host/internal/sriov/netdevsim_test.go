@@ -0,0 +1,131 @@
+package sriov
+
+import (
+	"net"
+
+	"github.com/golang/mock/gomock"
+	"github.com/vishvananda/netlink"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	netlinkPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
+	netlinkMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink/mock"
+	hostMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/mock"
+	hostStoreMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("netdevsim", func() {
+	var (
+		s              *sriov
+		netlinkLibMock *netlinkMockPkg.MockNetlinkLib
+		hostMock       *hostMockPkg.MockHostManagerInterface
+		storeManager   *hostStoreMockPkg.MockManagerInterface
+		pfLinkMock     *netlinkMockPkg.MockLink
+	)
+
+	BeforeEach(func() {
+		origNetdevsimMode := vars.NetdevsimMode
+		DeferCleanup(func() { vars.NetdevsimMode = origNetdevsimMode })
+		vars.NetdevsimMode = true
+
+		testCtrl := gomock.NewController(GinkgoT())
+		netlinkLibMock = netlinkMockPkg.NewMockNetlinkLib(testCtrl)
+		hostMock = hostMockPkg.NewMockHostManagerInterface(testCtrl)
+		storeManager = hostStoreMockPkg.NewMockManagerInterface(testCtrl)
+		pfLinkMock = netlinkMockPkg.NewMockLink(testCtrl)
+		s = New(nil, hostMock, hostMock, hostMock, hostMock, hostMock, netlinkLibMock, nil, nil, nil, nil, hostMock).(*sriov)
+
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{
+				"/sys/bus/netdevsim/devices/netdevsim1/net/eni1np1",
+				"/sys/bus/netdevsim/devices/netdevsim2",
+				"/sys/kernel/debug/netdevsim/netdevsim1",
+			},
+			Files: map[string][]byte{
+				"/sys/bus/netdevsim/devices/netdevsim1/sriov_numvfs": []byte("2"),
+				"/sys/kernel/debug/netdevsim/netdevsim1/max_vfs":     []byte("8"),
+			},
+		})
+	})
+
+	It("should discover the netdevsim devices with a netdevice", func() {
+		mac, _ := net.ParseMAC("26:f3:8c:a4:02:f1")
+		pfLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{
+			MTU: 1500, HardwareAddr: mac, EncapType: "ether",
+			Vfs: []netlink.VfInfo{{ID: 1, Trust: 1, Spoofchk: true}},
+		}).AnyTimes()
+		netlinkLibMock.EXPECT().LinkByName("eni1np1").Return(pfLinkMock, nil)
+		netlinkLibMock.EXPECT().DevLinkGetDeviceByName("netdevsim", "netdevsim1").Return(
+			&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil)
+		hostMock.EXPECT().GetNetDevLinkAdminState("eni1np1").Return("up")
+		storeManager.EXPECT().LoadPfsStatus("netdevsim1").Return(nil, false, nil)
+
+		pfs, err := s.DiscoverSriovDevices(storeManager)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pfs).To(HaveLen(1))
+		Expect(pfs[0].Name).To(Equal("eni1np1"))
+		Expect(pfs[0].PciAddress).To(Equal("netdevsim1"))
+		Expect(pfs[0].Driver).To(Equal("netdevsim"))
+		Expect(pfs[0].LinkType).To(Equal("ETH"))
+		Expect(pfs[0].NumVfs).To(Equal(2))
+		Expect(pfs[0].TotalVfs).To(Equal(8))
+		Expect(pfs[0].EswitchMode).To(Equal("legacy"))
+		Expect(pfs[0].VFs).To(HaveLen(2))
+		Expect(pfs[0].VFs[1].PciAddress).To(Equal("netdevsim1-vf1"))
+		Expect(pfs[0].VFs[1].Trust).To(Equal("on"))
+		Expect(pfs[0].VFs[1].SpoofChk).To(Equal("on"))
+	})
+
+	It("should set the number of VFs of the netdevsim device", func() {
+		pfLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{MTU: 1500}).AnyTimes()
+		hostMock.EXPECT().SetNetDevLinkAdminState("eni1np1", "up").Return(nil)
+		netlinkLibMock.EXPECT().LinkByName("eni1np1").Return(pfLinkMock, nil)
+		netlinkLibMock.EXPECT().LinkSetMTU(pfLinkMock, 9000).Return(nil)
+		netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, gomock.Any(), false).Return(nil).Times(4)
+		netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, gomock.Any(), true).Return(nil).Times(4)
+		netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, gomock.Any(), 0, 0).Return(nil).Times(4)
+		storeManager.EXPECT().SaveLastPfAppliedStatus(gomock.Any()).Return(nil)
+
+		Expect(s.ConfigSriovInterfaces(storeManager,
+			[]sriovnetworkv1.Interface{{
+				Name:       "eni1np1",
+				PciAddress: "netdevsim1",
+				NumVfs:     4,
+				Mtu:        9000,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-3", DeviceType: "netdevice"}},
+			}},
+			[]sriovnetworkv1.InterfaceExt{{
+				Name:       "eni1np1",
+				PciAddress: "netdevsim1",
+				NumVfs:     2,
+				TotalVfs:   8,
+				Mtu:        1500,
+			}},
+			false)).To(Succeed())
+		helpers.GinkgoAssertFileContentsEquals("/sys/bus/netdevsim/devices/netdevsim1/sriov_numvfs", "4")
+	})
+
+	It("should refuse more VFs than supported by the device", func() {
+		err := s.ConfigSriovInterfaces(storeManager,
+			[]sriovnetworkv1.Interface{{Name: "eni1np1", PciAddress: "netdevsim1", NumVfs: 16}},
+			[]sriovnetworkv1.InterfaceExt{{Name: "eni1np1", PciAddress: "netdevsim1", TotalVfs: 8}},
+			false)
+		Expect(types.GetInterfaceSyncErrors(err)).To(HaveLen(1))
+		Expect(err).To(MatchError(ContainSubstring("NumVfs (16) is larger than TotalVfs (8)")))
+	})
+
+	It("should remove the VFs of the devices which are not in the spec", func() {
+		storeManager.EXPECT().LoadPfsStatus("netdevsim1").Return(&sriovnetworkv1.Interface{PciAddress: "netdevsim1"}, true, nil)
+		storeManager.EXPECT().RemovePfAppliedStatus("netdevsim1").Return(nil)
+		Expect(s.ConfigSriovInterfaces(storeManager, nil,
+			[]sriovnetworkv1.InterfaceExt{{Name: "eni1np1", PciAddress: "netdevsim1", NumVfs: 2}},
+			false)).To(Succeed())
+		helpers.GinkgoAssertFileContentsEquals("/sys/bus/netdevsim/devices/netdevsim1/sriov_numvfs", "0")
+	})
+})
@@ -1,12 +1,17 @@
 package sriov
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"math/bits"
+	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -20,6 +25,7 @@ import (
 	dputilsPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
 	ghwPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ghw"
 	netlinkPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
+	podresourcesPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/podresources"
 	sriovnetPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/sriovnet"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
@@ -27,6 +33,21 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
+// name of the devlink parameter which selects the flow steering mode of the device
+const flowSteeringModeParam = "flow_steering_mode"
+
+var (
+	// interval and timeout of the eSwitch mode readback after the mode change, overridden in unit-tests
+	eswitchModePollInterval = 100 * time.Millisecond
+	eswitchModePollTimeout  = 10 * time.Second
+	// interval of the checks if the VFs are still allocated to the pods, overridden in unit-tests
+	vfReleasePollInterval = 5 * time.Second
+	// writes the number of VFs to the sriov_numvfs file of the PF, overridden in unit-tests
+	writeNumVfsFile = func(path string, numVfs int) error {
+		return os.WriteFile(path, []byte(strconv.Itoa(numVfs)), os.ModeAppend)
+	}
+)
+
 type interfaceToConfigure struct {
 	iface       sriovnetworkv1.Interface
 	ifaceStatus sriovnetworkv1.InterfaceExt
@@ -43,7 +64,9 @@ type sriov struct {
 	dputilsLib       dputilsPkg.DPUtilsLib
 	sriovnetLib      sriovnetPkg.SriovnetLib
 	ghwLib           ghwPkg.GHWLib
+	podResourcesLib  podresourcesPkg.PodResourcesLib
 	bridgeHelper     types.BridgeInterface
+	cache            *discoveryCache
 }
 
 func New(utilsHelper utils.CmdInterface,
@@ -56,6 +79,7 @@ func New(utilsHelper utils.CmdInterface,
 	dputilsLib dputilsPkg.DPUtilsLib,
 	sriovnetLib sriovnetPkg.SriovnetLib,
 	ghwLib ghwPkg.GHWLib,
+	podResourcesLib podresourcesPkg.PodResourcesLib,
 	bridgeHelper types.BridgeInterface) types.SriovInterface {
 	return &sriov{utilsHelper: utilsHelper,
 		kernelHelper:     kernelHelper,
@@ -67,15 +91,16 @@ func New(utilsHelper utils.CmdInterface,
 		dputilsLib:       dputilsLib,
 		sriovnetLib:      sriovnetLib,
 		ghwLib:           ghwLib,
+		podResourcesLib:  podResourcesLib,
 		bridgeHelper:     bridgeHelper,
+		cache:            newDiscoveryCache(),
 	}
 }
 
 func (s *sriov) SetSriovNumVfs(pciAddr string, numVfs int) error {
 	log.Log.V(2).Info("SetSriovNumVfs(): set NumVfs", "device", pciAddr, "numVfs", numVfs)
 	numVfsFilePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, consts.NumVfsFile)
-	bs := []byte(strconv.Itoa(numVfs))
-	err := os.WriteFile(numVfsFilePath, []byte("0"), os.ModeAppend)
+	err := writeNumVfsFile(numVfsFilePath, 0)
 	if err != nil {
 		log.Log.Error(err, "SetSriovNumVfs(): fail to reset NumVfs file", "path", numVfsFilePath)
 		return err
@@ -83,14 +108,103 @@ func (s *sriov) SetSriovNumVfs(pciAddr string, numVfs int) error {
 	if numVfs == 0 {
 		return nil
 	}
-	err = os.WriteFile(numVfsFilePath, bs, os.ModeAppend)
+	err = writeNumVfsFile(numVfsFilePath, numVfs)
 	if err != nil {
 		log.Log.Error(err, "SetSriovNumVfs(): fail to set NumVfs file", "path", numVfsFilePath)
-		return err
+		if errors.Is(err, syscall.ENOMEM) {
+			// the kernel failed to allocate the MMIO space of the VF BARs
+			return &types.VFBarAllocationError{PciAddress: pciAddr, NumVfs: numVfs, Bars: readVFBars(pciAddr), Err: err}
+		}
+		return recoverPartialNumVfs(pciAddr, numVfs, err)
 	}
 	return nil
 }
 
+// recoverPartialNumVfs handles a failed write of sriov_numvfs after which the PF may have created only a part
+// of the VFs, e.g. when the firmware runs out of resources. The VFs are removed and the write is retried once,
+// if it fails again the PF is configured with the largest number of VFs it was able to create
+// and a NumVfsShortfallError is returned.
+func recoverPartialNumVfs(pciAddr string, numVfs int, writeErr error) error {
+	funcLog := log.Log.WithValues("device", pciAddr, "numVfs", numVfs)
+	numVfsFilePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, consts.NumVfsFile)
+	created := readCreatedVfs(pciAddr)
+	funcLog.Info("recoverPartialNumVfs(): failed to create the VFs, retry", "createdVfs", created, "error", writeErr)
+	if err := writeNumVfsFile(numVfsFilePath, 0); err != nil {
+		funcLog.Error(err, "recoverPartialNumVfs(): fail to reset NumVfs file", "path", numVfsFilePath)
+		return err
+	}
+	err := writeNumVfsFile(numVfsFilePath, numVfs)
+	if err == nil {
+		return nil
+	}
+	created = max(created, readCreatedVfs(pciAddr))
+	funcLog.Error(err, "recoverPartialNumVfs(): failed to create the VFs again", "createdVfs", created)
+	if resetErr := writeNumVfsFile(numVfsFilePath, 0); resetErr != nil {
+		funcLog.Error(resetErr, "recoverPartialNumVfs(): fail to reset NumVfs file", "path", numVfsFilePath)
+		return resetErr
+	}
+	if created == 0 || created >= numVfs {
+		return err
+	}
+	funcLog.Info("recoverPartialNumVfs(): fall back to the number of VFs the device created", "createdVfs", created)
+	if fallbackErr := writeNumVfsFile(numVfsFilePath, created); fallbackErr != nil {
+		funcLog.Error(fallbackErr, "recoverPartialNumVfs(): failed to create the VFs", "createdVfs", created)
+		return fmt.Errorf("failed to create %d VFs (%v), fallback to %d VFs failed: %w", numVfs, err, created, fallbackErr)
+	}
+	return &types.NumVfsShortfallError{PciAddress: pciAddr, NumVfs: numVfs, CreatedVfs: created, Err: err}
+}
+
+// readCreatedVfs returns the number of VFs of the PF, the largest of the value of sriov_numvfs
+// and of the number of the VFs listed in sysfs as they may differ after a failed write
+func readCreatedVfs(pciAddr string) int {
+	created := 0
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, consts.NumVfsFile))
+	if err == nil {
+		created, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+	if vfAddrs, err := getVFPciAddresses(pciAddr); err == nil {
+		created = max(created, len(vfAddrs))
+	}
+	return created
+}
+
+// isNumVfsShortfall returns true if the error is caused by the device creating less VFs than requested
+func isNumVfsShortfall(err error) bool {
+	var shortfallErr *types.NumVfsShortfallError
+	return errors.As(err, &shortfallErr)
+}
+
+// readVFBars returns the BARs of the VFs of the PF from the resource file of the PF, the entries 7 to 12 of the file
+// are the SR-IOV BARs. The BARs are not returned if the file can't be read.
+func readVFBars(pciAddr string) []types.VFBar {
+	resourcePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "resource")
+	data, err := os.ReadFile(resourcePath)
+	if err != nil {
+		log.Log.Error(err, "readVFBars(): fail to read the resources of the device", "path", resourcePath)
+		return nil
+	}
+	var bars []types.VFBar
+	for i, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		index := i - consts.PciIovResourcesStart
+		if index < 0 || index >= consts.PciNumBars {
+			continue
+		}
+		// each line contains the start address, the end address and the flags of the resource
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		start, startErr := strconv.ParseUint(fields[0], 0, 64)
+		end, endErr := strconv.ParseUint(fields[1], 0, 64)
+		if startErr != nil || endErr != nil || end == 0 {
+			// the BAR is not implemented by the device
+			continue
+		}
+		bars = append(bars, types.VFBar{Index: index, Start: start, Size: end - start + 1})
+	}
+	return bars
+}
+
 func (s *sriov) ResetSriovDevice(ifaceStatus sriovnetworkv1.InterfaceExt) error {
 	log.Log.V(2).Info("ResetSriovDevice(): reset SRIOV device", "address", ifaceStatus.PciAddress)
 	if ifaceStatus.LinkType == consts.LinkTypeETH {
@@ -108,10 +222,13 @@ func (s *sriov) ResetSriovDevice(ifaceStatus sriovnetworkv1.InterfaceExt) error
 			return err
 		}
 		log.Log.V(2).Info("ResetSriovDevice(): reset eswitch mode and number of VFs", "mode", eswitchMode)
-		if err := s.setEswitchModeAndNumVFs(ifaceStatus.PciAddress, eswitchMode, 0); err != nil {
+		if err := s.setEswitchModeAndNumVFs(ifaceStatus.PciAddress, eswitchMode, "", 0); err != nil {
 			return err
 		}
 	} else if ifaceStatus.LinkType == consts.LinkTypeIB {
+		if err := s.waitForVFsRelease(ifaceStatus.PciAddress); err != nil {
+			return err
+		}
 		if err := s.SetSriovNumVfs(ifaceStatus.PciAddress, 0); err != nil {
 			return err
 		}
@@ -119,10 +236,18 @@ func (s *sriov) ResetSriovDevice(ifaceStatus sriovnetworkv1.InterfaceExt) error
 			return err
 		}
 	}
+	// restore the PF link state if the PF was down before the operator configured it
+	is := sriovnetworkv1.InitialState.GetInterfaceStateByPciAddress(ifaceStatus.PciAddress)
+	if is != nil && is.LinkAdminState == consts.LinkAdminStateDown && ifaceStatus.Name != "" {
+		log.Log.V(2).Info("ResetSriovDevice(): restore link admin state", "value", is.LinkAdminState)
+		if err := s.networkHelper.SetNetDevLinkAdminState(ifaceStatus.Name, consts.LinkAdminStateDown); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (s *sriov) getVfInfo(vfAddr string, pfName string, eswitchMode string, devices []*ghw.PCIDevice) sriovnetworkv1.VirtualFunction {
+func (s *sriov) getVfInfo(vfAddr string, pfName string, eswitchMode string, devices map[string]*ghw.PCIDevice) sriovnetworkv1.VirtualFunction {
 	driver, err := s.dputilsLib.GetDriverName(vfAddr)
 	if err != nil {
 		log.Log.Error(err, "getVfInfo(): unable to parse device driver", "device", vfAddr)
@@ -137,6 +262,16 @@ func (s *sriov) getVfInfo(vfAddr string, pfName string, eswitchMode string, devi
 		VfID:       id,
 		VdpaType:   s.vdpaHelper.DiscoverVDPAType(vfAddr),
 	}
+	if vf.VdpaType != "" {
+		vf.VdpaDevice = s.vdpaHelper.GetVDPADeviceName(vfAddr)
+	}
+	if driver == consts.DeviceTypeVfioPci {
+		if group, err := utils.GetIOMMUGroup(vfAddr); err != nil {
+			log.Log.Error(err, "getVfInfo(): unable to get IOMMU group of VF bound to vfio-pci", "device", vfAddr)
+		} else {
+			vf.IOMMUGroup = &group
+		}
+	}
 
 	if eswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
 		repName, err := s.sriovnetLib.GetVfRepresentor(pfName, id)
@@ -144,6 +279,15 @@ func (s *sriov) getVfInfo(vfAddr string, pfName string, eswitchMode string, devi
 			log.Log.Error(err, "getVfInfo(): failed to get VF representor name", "device", vfAddr)
 		} else {
 			vf.RepresentorName = repName
+			if repLink, err := s.netlinkLib.LinkByName(repName); err != nil {
+				log.Log.Error(err, "getVfInfo(): unable to get VF representor link", "representor", repName)
+			} else {
+				vf.RepresentorMtu = repLink.Attrs().MTU
+				vf.RepresentorLinkAdminState = consts.LinkAdminStateDown
+				if s.netlinkLib.IsLinkAdminStateUp(repLink) {
+					vf.RepresentorLinkAdminState = consts.LinkAdminStateUp
+				}
+			}
 		}
 	}
 
@@ -159,16 +303,49 @@ func (s *sriov) getVfInfo(vfAddr string, pfName string, eswitchMode string, devi
 	}
 	vf.GUID = s.networkHelper.GetNetDevNodeGUID(vfAddr)
 
-	for _, device := range devices {
-		if vfAddr == device.Address {
-			vf.Vendor = device.Vendor.ID
-			vf.DeviceID = device.Product.ID
-			break
-		}
+	if device, ok := devices[vfAddr]; ok {
+		vf.Vendor = device.Vendor.ID
+		vf.DeviceID = device.Product.ID
+		vf.NumaNode = s.getDeviceFacts(device).numaNode
 	}
 	return vf
 }
 
+// setVfAdminConfig reports the trust mode, the spoof check setting, the tx rate limits and the VLAN of the VF,
+// which are configured through the PF
+func setVfAdminConfig(vf *sriovnetworkv1.VirtualFunction, vfsInfo []netlink.VfInfo) {
+	onOff := func(enabled bool) string {
+		if enabled {
+			return sriovnetworkv1.SriovCniStateOn
+		}
+		return sriovnetworkv1.SriovCniStateOff
+	}
+	for _, info := range vfsInfo {
+		if info.ID == vf.VfID {
+			vf.Trust = onOff(info.Trust != 0)
+			vf.SpoofChk = onOff(info.Spoofchk)
+			vf.MinTxRate = int(info.MinTxRate)
+			vf.MaxTxRate = int(info.MaxTxRate)
+			vf.Vlan = info.Vlan
+			vf.VlanQoS = info.Qos
+			vf.VlanProto = vlanProtoToString(info.VlanProto)
+			return
+		}
+	}
+}
+
+// vlanProtoToString converts the VLAN protocol reported by the netlink library to the format used in the API,
+// the library doesn't convert the value from the network byte order
+func vlanProtoToString(proto int) string {
+	switch uint16(proto) {
+	case uint16(netlink.VLAN_PROTOCOL_8021AD), bits.ReverseBytes16(uint16(netlink.VLAN_PROTOCOL_8021AD)):
+		return sriovnetworkv1.VlanProto8021ad
+	case uint16(netlink.VLAN_PROTOCOL_8021Q), bits.ReverseBytes16(uint16(netlink.VLAN_PROTOCOL_8021Q)):
+		return sriovnetworkv1.VlanProto8021q
+	}
+	return ""
+}
+
 func (s *sriov) VFIsReady(pciAddr string) (netlink.Link, error) {
 	log.Log.Info("VFIsReady()", "device", pciAddr)
 	var err error
@@ -201,18 +378,73 @@ func (s *sriov) SetVfAdminMac(vfAddr string, pfLink, vfLink netlink.Link) error
 		return err
 	}
 
-	if err := s.netlinkLib.LinkSetVfHardwareAddr(pfLink, vfID, vfLink.Attrs().HardwareAddr); err != nil {
+	mac := vfLink.Attrs().HardwareAddr
+	if err := s.netlinkLib.LinkSetVfHardwareAddr(pfLink, vfID, mac); err != nil {
 		return err
 	}
 
+	return s.setVfEffectiveMac(vfAddr, vfLink, mac)
+}
+
+// vfDriversApplyingAdminMacOnLoad are the VF drivers which don't allow the VF to change the MAC of its netdevice
+// once the administrative MAC is set, the administrative MAC is used by the netdevice when the driver is loaded
+var vfDriversApplyingAdminMacOnLoad = []string{"mlx5_core", "ixgbevf"}
+
+// setVfEffectiveMac sets the hardware address of the netdevice of the VF to the administrative MAC, some drivers
+// keep using the previous MAC until they are reloaded. The VF is rebound to its driver if the driver doesn't allow
+// to change the MAC. VFs bound to a userspace driver like vfio-pci have no netdevice and are skipped.
+func (s *sriov) setVfEffectiveMac(vfAddr string, vfLink netlink.Link, mac net.HardwareAddr) error {
+	if vfLink == nil {
+		return nil
+	}
+	err := s.netlinkLib.LinkSetHardwareAddr(vfLink, mac)
+	if err == nil {
+		return nil
+	}
+	name := vfLink.Attrs().Name
+	if link, linkErr := s.netlinkLib.LinkByName(name); linkErr == nil && bytes.Equal(link.Attrs().HardwareAddr, mac) {
+		log.Log.V(2).Info("setVfEffectiveMac(): VF netdevice already uses the administrative MAC", "vf", vfAddr,
+			"netdev", name, "error", err)
+		return nil
+	}
+	_, driver := s.kernelHelper.HasDriver(vfAddr)
+	if !sriovnetworkv1.StringInArray(driver, vfDriversApplyingAdminMacOnLoad) {
+		log.Log.Error(err, "setVfEffectiveMac(): failed to set the MAC of the VF netdevice", "vf", vfAddr, "netdev", name)
+		return fmt.Errorf("failed to set MAC %s on netdevice %s of VF %s: %v", mac, name, vfAddr, err)
+	}
+	log.Log.Info("setVfEffectiveMac(): rebind the VF to apply the administrative MAC", "vf", vfAddr, "driver", driver)
+	if err := s.kernelHelper.RebindVfToDefaultDriver(vfAddr); err != nil {
+		log.Log.Error(err, "setVfEffectiveMac(): failed to rebind VF", "vf", vfAddr)
+		return err
+	}
+	vfLink, err = s.VFIsReady(vfAddr)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(vfLink.Attrs().HardwareAddr, mac) {
+		return fmt.Errorf("netdevice %s of VF %s uses MAC %s instead of the administrative MAC %s after the rebind",
+			vfLink.Attrs().Name, vfAddr, vfLink.Attrs().HardwareAddr, mac)
+	}
 	return nil
 }
 
+// getDeviceNumaNode returns the NUMA node of the PCI device, nil is returned if the device has no NUMA affinity
+func (s *sriov) getDeviceNumaNode(pciAddr string) *int {
+	numaNode, err := s.kernelHelper.GetDeviceNumaNode(pciAddr)
+	if err != nil || numaNode < 0 {
+		return nil
+	}
+	return &numaNode
+}
+
 func (s *sriov) DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sriovnetworkv1.InterfaceExt, error) {
 	log.Log.V(2).Info("DiscoverSriovDevices")
+	if vars.NetdevsimMode {
+		return s.discoverNetdevsimDevices(storeManager)
+	}
 	pfList := []sriovnetworkv1.InterfaceExt{}
 
-	pci, err := s.ghwLib.PCI()
+	pci, err := s.getPCIInfo()
 	if err != nil {
 		return nil, fmt.Errorf("DiscoverSriovDevices(): error getting PCI info: %v", err)
 	}
@@ -221,6 +453,11 @@ func (s *sriov) DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sri
 	if len(devices) == 0 {
 		return nil, fmt.Errorf("DiscoverSriovDevices(): could not retrieve PCI devices")
 	}
+	devicesByAddress := make(map[string]*ghw.PCIDevice, len(devices))
+	for _, device := range devices {
+		devicesByAddress[device.Address] = device
+	}
+	defer s.pruneDeviceFacts(devicesByAddress)
 
 	for _, device := range devices {
 		devClass, err := strconv.ParseInt(device.Class.ID, 16, 64)
@@ -267,16 +504,19 @@ func (s *sriov) DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sri
 		}
 
 		iface := sriovnetworkv1.InterfaceExt{
-			Name:           pfNetName,
-			PciAddress:     device.Address,
-			Driver:         driver,
-			Vendor:         device.Vendor.ID,
-			DeviceID:       device.Product.ID,
-			Mtu:            link.Attrs().MTU,
-			Mac:            link.Attrs().HardwareAddr.String(),
-			LinkType:       s.encapTypeToLinkType(link.Attrs().EncapType),
-			LinkSpeed:      s.networkHelper.GetNetDevLinkSpeed(pfNetName),
-			LinkAdminState: s.networkHelper.GetNetDevLinkAdminState(pfNetName),
+			Name:            pfNetName,
+			PciAddress:      device.Address,
+			Driver:          driver,
+			Vendor:          device.Vendor.ID,
+			DeviceID:        device.Product.ID,
+			Mtu:             link.Attrs().MTU,
+			Mac:             link.Attrs().HardwareAddr.String(),
+			LinkType:        s.encapTypeToLinkType(link.Attrs().EncapType),
+			LinkSpeed:       s.networkHelper.GetNetDevLinkSpeed(pfNetName),
+			LinkAdminState:  s.networkHelper.GetNetDevLinkAdminState(pfNetName),
+			FirmwareVersion: s.networkHelper.GetNetDevFirmwareVersion(pfNetName),
+			BondMaster:      s.networkHelper.GetNetDevBondMaster(pfNetName),
+			NumaNode:        s.getDeviceFacts(device).numaNode,
 		}
 
 		pfStatus, exist, err := storeManager.LoadPfsStatus(iface.PciAddress)
@@ -291,7 +531,7 @@ func (s *sriov) DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sri
 		if s.dputilsLib.IsSriovPF(device.Address) {
 			iface.TotalVfs = s.dputilsLib.GetSriovVFcapacity(device.Address)
 			iface.NumVfs = s.dputilsLib.GetVFconfigured(device.Address)
-			iface.EswitchMode = s.GetNicSriovMode(device.Address)
+			iface.EswitchMode, iface.EswitchEncapMode = s.getNicEswitchModes(device.Address)
 			if s.dputilsLib.SriovConfigured(device.Address) {
 				vfs, err := s.dputilsLib.GetVFList(device.Address)
 				if err != nil {
@@ -300,17 +540,38 @@ func (s *sriov) DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sri
 					continue
 				}
 				for _, vf := range vfs {
-					instance := s.getVfInfo(vf, pfNetName, iface.EswitchMode, devices)
+					instance := s.getVfInfo(vf, pfNetName, iface.EswitchMode, devicesByAddress)
+					setVfAdminConfig(&instance, link.Attrs().Vfs)
 					iface.VFs = append(iface.VFs, instance)
 				}
 			}
 		}
 		pfList = append(pfList, iface)
 	}
+	setSiblingPFs(pfList)
 
 	return pfList, nil
 }
 
+// setSiblingPFs records the other PFs of the same device for each Mellanox PF, the ports of a dual-port NIC
+// are the functions of the same PCI domain:bus:device and share the VF resources of the firmware
+func setSiblingPFs(pfList []sriovnetworkv1.InterfaceExt) {
+	for i := range pfList {
+		if pfList[i].Vendor != consts.VendorMellanox {
+			continue
+		}
+		device, _, _ := strings.Cut(pfList[i].PciAddress, ".")
+		for j := range pfList {
+			if i == j || pfList[j].Vendor != consts.VendorMellanox {
+				continue
+			}
+			if otherDevice, _, _ := strings.Cut(pfList[j].PciAddress, "."); otherDevice == device {
+				pfList[i].SiblingPFs = append(pfList[i].SiblingPFs, pfList[j].PciAddress)
+			}
+		}
+	}
+}
+
 func (s *sriov) configSriovPFDevice(iface *sriovnetworkv1.Interface) error {
 	log.Log.V(2).Info("configSriovPFDevice(): configure PF sriov device",
 		"device", iface.PciAddress)
@@ -323,6 +584,11 @@ func (s *sriov) configSriovPFDevice(iface *sriovnetworkv1.Interface) error {
 	if err := s.configureHWOptionsForSwitchdev(iface); err != nil {
 		return err
 	}
+	// devlink parameters are set before the VFs are created, some of them (e.g. flow_steering_mode)
+	// can't be changed while the PF has VFs
+	if err := s.configureDevlinkParams(iface); err != nil {
+		return err
+	}
 	// remove all UDEV rules for the PF before adding new rules to
 	// make sure that rules are always in a consistent state, e.g. there is no
 	// switchdev-related rules for PF in legacy mode
@@ -335,10 +601,20 @@ func (s *sriov) configSriovPFDevice(iface *sriovnetworkv1.Interface) error {
 		log.Log.Error(err, "configSriovPFDevice(): fail to add udev rules", "device", iface.PciAddress)
 		return err
 	}
-	err = s.createVFs(iface)
-	if err != nil {
-		log.Log.Error(err, "configSriovPFDevice(): fail to set NumVfs for device", "device", iface.PciAddress)
-		return err
+	// some NICs create VFs with no link if the PF is administratively down
+	if !iface.DisablePfLinkManagement {
+		if err := s.networkHelper.SetNetDevLinkAdminState(iface.Name, consts.LinkAdminStateUp); err != nil {
+			log.Log.Error(err, "configSriovPFDevice(): fail to set PF link up", "device", iface.PciAddress)
+			return err
+		}
+	}
+	// the PF is configured with the created VFs if the device created less VFs than requested
+	shortfallErr := s.createVFs(iface)
+	if shortfallErr != nil {
+		log.Log.Error(shortfallErr, "configSriovPFDevice(): fail to set NumVfs for device", "device", iface.PciAddress)
+		if !isNumVfsShortfall(shortfallErr) {
+			return shortfallErr
+		}
 	}
 	if err := s.addVfRepresentorUdevRule(iface); err != nil {
 		log.Log.Error(err, "configSriovPFDevice(): fail to add VR representor udev rule", "device", iface.PciAddress)
@@ -352,7 +628,7 @@ func (s *sriov) configSriovPFDevice(iface *sriovnetworkv1.Interface) error {
 			return err
 		}
 	}
-	return nil
+	return shortfallErr
 }
 
 func (s *sriov) configureHWOptionsForSwitchdev(iface *sriovnetworkv1.Interface) error {
@@ -366,7 +642,10 @@ func (s *sriov) configureHWOptionsForSwitchdev(iface *sriovnetworkv1.Interface)
 		return err
 	}
 	desiredFlowSteeringMode := "smfs"
-	currentFlowSteeringMode, err := s.networkHelper.GetDevlinkDeviceParam(iface.PciAddress, "flow_steering_mode")
+	if mode, ok := iface.DevlinkParams[flowSteeringModeParam]; ok {
+		desiredFlowSteeringMode = mode
+	}
+	currentFlowSteeringMode, err := s.networkHelper.GetDevlinkDeviceParam(iface.PciAddress, flowSteeringModeParam)
 	if err != nil {
 		if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENODEV) {
 			log.Log.V(2).Info("configureHWOptionsForSwitchdev(): device has no flow_steering_mode parameter, skip",
@@ -386,9 +665,9 @@ func (s *sriov) configureHWOptionsForSwitchdev(iface *sriovnetworkv1.Interface)
 	}
 	// flow steering mode can be changed only when NIC is in legacy mode
 	if s.GetNicSriovMode(iface.PciAddress) != sriovnetworkv1.ESwithModeLegacy {
-		s.setEswitchModeAndNumVFs(iface.PciAddress, sriovnetworkv1.ESwithModeLegacy, 0)
+		s.setEswitchModeAndNumVFs(iface.PciAddress, sriovnetworkv1.ESwithModeLegacy, "", 0)
 	}
-	if err := s.networkHelper.SetDevlinkDeviceParam(iface.PciAddress, "flow_steering_mode", desiredFlowSteeringMode); err != nil {
+	if err := s.networkHelper.SetDevlinkDeviceParam(iface.PciAddress, flowSteeringModeParam, desiredFlowSteeringMode); err != nil {
 		if errors.Is(err, syscall.ENOTSUP) {
 			log.Log.V(2).Info("configureHWOptionsForSwitchdev(): device doesn't support changing of flow_steering_mode, skip", "device", iface.PciAddress)
 			return nil
@@ -399,13 +678,70 @@ func (s *sriov) configureHWOptionsForSwitchdev(iface *sriovnetworkv1.Interface)
 	return nil
 }
 
+// configureDevlinkParams sets the devlink parameters of the PF requested in the spec, the parameters which
+// already have the requested value are not changed and the value of the changed ones is read back to verify
+// that the device accepted it. The errors of all the parameters are returned as DevlinkParamErrors.
+func (s *sriov) configureDevlinkParams(iface *sriovnetworkv1.Interface) error {
+	names := make([]string, 0, len(iface.DevlinkParams))
+	for name := range iface.DevlinkParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		value := iface.DevlinkParams[name]
+		if err := s.setDevlinkParam(iface.PciAddress, name, value); err != nil {
+			log.Log.Error(err, "configureDevlinkParams(): fail to set devlink parameter",
+				"device", iface.PciAddress, "param", name, "value", value)
+			errs = append(errs, &types.DevlinkParamError{Param: name, Value: value, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *sriov) setDevlinkParam(pciAddr, name, value string) error {
+	current, err := s.networkHelper.GetDevlinkDeviceParam(pciAddr, name)
+	if err != nil {
+		return fmt.Errorf("failed to read the current value: %v", err)
+	}
+	if devlinkParamValueEqual(current, value) {
+		return nil
+	}
+	log.Log.Info("setDevlinkParam(): set devlink parameter", "device", pciAddr, "param", name, "current", current, "value", value)
+	if err := s.networkHelper.SetDevlinkDeviceParam(pciAddr, name, value); err != nil {
+		return err
+	}
+	current, err = s.networkHelper.GetDevlinkDeviceParam(pciAddr, name)
+	if err != nil {
+		return fmt.Errorf("failed to read back the value: %v", err)
+	}
+	if !devlinkParamValueEqual(current, value) {
+		return fmt.Errorf("the device reports value %q after the update", current)
+	}
+	return nil
+}
+
+// devlinkParamValueEqual compares the value of a devlink parameter read from the device with the requested one,
+// the boolean values are read as true or false but can be requested in any format accepted by strconv.ParseBool
+func devlinkParamValueEqual(current, requested string) bool {
+	if current == requested {
+		return true
+	}
+	currentBool, err := strconv.ParseBool(current)
+	if err != nil {
+		return false
+	}
+	requestedBool, err := strconv.ParseBool(requested)
+	return err == nil && currentBool == requestedBool
+}
+
 func (s *sriov) checkExternallyManagedPF(iface *sriovnetworkv1.Interface) error {
 	log.Log.V(2).Info("checkExternallyManagedPF(): configure PF sriov device",
 		"device", iface.PciAddress)
 	currentNumVfs := s.dputilsLib.GetVFconfigured(iface.PciAddress)
 	if iface.NumVfs > currentNumVfs {
-		errMsg := fmt.Sprintf("checkExternallyManagedPF(): number of request virtual functions %d is not equal to configured virtual "+
-			"functions %d but the policy is configured as ExternallyManaged for device %s",
+		errMsg := fmt.Sprintf("checkExternallyManagedPF(): number of requested virtual functions %d is higher than the %d virtual "+
+			"functions created on the device %s, the VFs must be created externally as the policy is configured as ExternallyManaged",
 			iface.NumVfs, currentNumVfs, iface.PciAddress)
 		log.Log.Error(nil, errMsg)
 		return fmt.Errorf(errMsg)
@@ -442,12 +778,29 @@ func (s *sriov) configSriovVFDevices(iface *sriovnetworkv1.Interface) error {
 			return err
 		}
 
+		// VFs which failed to bind to the driver are reported together after the other VFs are configured,
+		// a single busy VF shouldn't prevent the configuration of the whole PF
+		var bindFailedVFs []string
+		var bindErrs []error
+		bindFailed := func(addr string, err error) error {
+			if errors.Is(err, types.ErrDriverNotFound) {
+				// all the VFs will fail the same way
+				return err
+			}
+			bindFailedVFs = append(bindFailedVFs, addr)
+			bindErrs = append(bindErrs, err)
+			return nil
+		}
+
 		for _, addr := range vfAddrs {
 			hasDriver, _ := s.kernelHelper.HasDriver(addr)
 			if !hasDriver {
 				if err := s.kernelHelper.BindDefaultDriver(addr); err != nil {
 					log.Log.Error(err, "configSriovVFDevices(): fail to bind default driver for device", "device", addr)
-					return err
+					if err := bindFailed(addr, err); err != nil {
+						return err
+					}
+					continue
 				}
 			}
 			var group *sriovnetworkv1.VfGroup
@@ -467,9 +820,45 @@ func (s *sriov) configSriovVFDevices(iface *sriovnetworkv1.Interface) error {
 
 			// VF group not found.
 			if group == nil {
+				// the VF is not managed by any policy anymore, make sure that VDPA device
+				// created for it by a previous policy is removed
+				if sriovnetworkv1.GetEswitchModeFromSpec(iface) == sriovnetworkv1.ESwithModeSwitchDev {
+					if err := s.vdpaHelper.DeleteVDPADevice(addr); err != nil {
+						log.Log.Error(err, "configSriovVFDevices(): fail to delete VDPA device",
+							"device", addr)
+						return err
+					}
+				}
 				continue
 			}
 
+			if sriovnetworkv1.GetEswitchModeFromSpec(iface) == sriovnetworkv1.ESwithModeSwitchDev {
+				if err := s.configVFRepresentor(iface.Name, vfID, sriovnetworkv1.GetVfRepresentorMtu(iface, group)); err != nil {
+					log.Log.Error(err, "configSriovVFDevices(): fail to configure VF representor", "device", addr)
+					return err
+				}
+			}
+
+			if err := s.configSriovVFTrustAndSpoofChk(pfLink, vfID, group); err != nil {
+				log.Log.Error(err, "configSriovVFDevices(): fail to configure trust and spoof check for VF", "device", addr)
+				return err
+			}
+			if err := s.configSriovVFTxRate(pfLink, vfID, group); err != nil {
+				log.Log.Error(err, "configSriovVFDevices(): fail to set tx rate for VF", "device", addr,
+					"minTxRate", group.MinTxRate, "maxTxRate", group.MaxTxRate)
+				return err
+			}
+
+			// the VLAN of VFs used by DPDK applications has to be programmed before the VF is handed to the application,
+			// the VLAN of the other VFs is set by the CNI
+			if sriovnetworkv1.StringInArray(group.DeviceType, vars.DpdkDrivers) {
+				if err := s.configSriovVFVlan(pfLink, vfID, group); err != nil {
+					log.Log.Error(err, "configSriovVFDevices(): fail to set VLAN for VF", "device", addr,
+						"vlan", group.Vlan, "vlanQoS", group.VlanQoS, "vlanProto", group.VlanProto)
+					return err
+				}
+			}
+
 			// only set GUID and MAC for VF with default driver
 			// for userspace drivers like vfio we configure the vf mac using the kernel nic mac address
 			// before we switch to the userspace driver
@@ -527,7 +916,10 @@ func (s *sriov) configSriovVFDevices(iface *sriovnetworkv1.Interface) error {
 			if !sriovnetworkv1.StringInArray(group.DeviceType, vars.DpdkDrivers) {
 				if err := s.kernelHelper.BindDefaultDriver(addr); err != nil {
 					log.Log.Error(err, "configSriovVFDevices(): fail to bind default driver for device", "device", addr)
-					return err
+					if err := bindFailed(addr, err); err != nil {
+						return err
+					}
+					continue
 				}
 				// only set MTU for VF with default driver
 				if group.Mtu > 0 {
@@ -536,6 +928,10 @@ func (s *sriov) configSriovVFDevices(iface *sriovnetworkv1.Interface) error {
 						return err
 					}
 				}
+				if err := s.configSriovVFQoS(iface.Name, vfID, group); err != nil {
+					log.Log.Error(err, "configSriovVFDevices(): fail to configure QoS for VF", "address", addr)
+					return err
+				}
 				if sriovnetworkv1.GetEswitchModeFromSpec(iface) == sriovnetworkv1.ESwithModeSwitchDev && group.VdpaType != "" {
 					if err := s.vdpaHelper.CreateVDPADevice(addr, group.VdpaType); err != nil {
 						log.Log.Error(err, "configSriovVFDevices(): fail to create VDPA device",
@@ -547,10 +943,123 @@ func (s *sriov) configSriovVFDevices(iface *sriovnetworkv1.Interface) error {
 				if err := s.kernelHelper.BindDpdkDriver(addr, group.DeviceType); err != nil {
 					log.Log.Error(err, "configSriovVFDevices(): fail to bind driver for device",
 						"driver", group.DeviceType, "device", addr)
-					return err
+					if err := bindFailed(addr, err); err != nil {
+						return err
+					}
+					continue
+				}
+				if group.DeviceType == consts.DeviceTypeVfioPci {
+					// the VF is bound but the workloads fail to open it if its IOMMU group is not usable,
+					// the problem is reported as a warning in the sync status of the PF
+					if _, err := utils.CheckVFIOGroup(addr); err != nil {
+						log.Log.Error(err, "configSriovVFDevices(): WARNING: VF bound to vfio-pci can't be used by the workloads",
+							"device", addr)
+					}
 				}
 			}
 		}
+		if len(bindFailedVFs) > 0 {
+			return fmt.Errorf("failed to bind VFs %s of device %s to the driver: %w",
+				strings.Join(bindFailedVFs, ", "), iface.PciAddress, errors.Join(bindErrs...))
+		}
+	}
+	return nil
+}
+
+// configVFRepresentor sets the MTU of the representor of the VF and brings it up, the representors are created
+// down with the default MTU and the offloaded traffic of the VF would be dropped otherwise
+func (s *sriov) configVFRepresentor(pfName string, vfID int, mtu int) error {
+	repName, err := s.sriovnetLib.GetVfRepresentor(pfName, vfID)
+	if err != nil {
+		return fmt.Errorf("failed to get the representor of VF %d of %s: %v", vfID, pfName, err)
+	}
+	repLink, err := s.netlinkLib.LinkByName(repName)
+	if err != nil {
+		return fmt.Errorf("failed to get the representor link %s: %v", repName, err)
+	}
+	if mtu > 0 && repLink.Attrs().MTU != mtu {
+		log.Log.V(2).Info("configVFRepresentor(): set MTU", "representor", repName, "mtu", mtu)
+		if err := s.netlinkLib.LinkSetMTU(repLink, mtu); err != nil {
+			return fmt.Errorf("failed to set the MTU of the representor %s: %v", repName, err)
+		}
+	}
+	if !s.netlinkLib.IsLinkAdminStateUp(repLink) {
+		log.Log.V(2).Info("configVFRepresentor(): set link up", "representor", repName)
+		if err := s.netlinkLib.LinkSetUp(repLink); err != nil {
+			return fmt.Errorf("failed to set the representor %s up: %v", repName, err)
+		}
+	}
+	return nil
+}
+
+// configSriovVFTrustAndSpoofChk applies the trust mode and the spoof check setting requested by the VF group,
+// settings which are not set by the group are reset to the kernel defaults (trust off, spoof check on)
+func (s *sriov) configSriovVFTrustAndSpoofChk(pfLink netlink.Link, vfID int, group *sriovnetworkv1.VfGroup) error {
+	trust := sriovnetworkv1.GetVfTrust(group.Trust) == sriovnetworkv1.SriovCniStateOn
+	if err := s.netlinkLib.LinkSetVfTrust(pfLink, vfID, trust); err != nil {
+		if group.Trust != "" {
+			return err
+		}
+		// not all drivers support the trust mode, nothing to reset in that case
+		log.Log.V(2).Info("configSriovVFTrustAndSpoofChk(): failed to reset trust of VF, ignoring", "vf", vfID, "error", err)
+	}
+	spoofChk := sriovnetworkv1.GetVfSpoofChk(group.SpoofChk) == sriovnetworkv1.SriovCniStateOn
+	if err := s.netlinkLib.LinkSetVfSpoofchk(pfLink, vfID, spoofChk); err != nil {
+		if group.SpoofChk != "" {
+			return err
+		}
+		log.Log.V(2).Info("configSriovVFTrustAndSpoofChk(): failed to reset spoof check of VF, ignoring", "vf", vfID, "error", err)
+	}
+	return nil
+}
+
+// configSriovVFTxRate sets the tx rate limits of the VF group to the VF, rates which are not set
+// by the group are reset to 0 (unlimited)
+func (s *sriov) configSriovVFTxRate(pfLink netlink.Link, vfID int, group *sriovnetworkv1.VfGroup) error {
+	err := s.netlinkLib.LinkSetVfRate(pfLink, vfID, group.MinTxRate, group.MaxTxRate)
+	if err != nil && group.MinTxRate == 0 && group.MaxTxRate == 0 {
+		// not all drivers support rate limiting, nothing to reset in that case
+		log.Log.V(2).Info("configSriovVFTxRate(): failed to reset tx rate of VF, ignoring", "vf", vfID, "error", err)
+		return nil
+	}
+	return err
+}
+
+// configSriovVFVlan programs the VLAN of the VF group to the VF, the VLAN is cleared if the group has no VLAN
+func (s *sriov) configSriovVFVlan(pfLink netlink.Link, vfID int, group *sriovnetworkv1.VfGroup) error {
+	vlan, qos, proto := 0, 0, netlink.VLAN_PROTOCOL_8021Q
+	if group.Vlan != 0 {
+		vlan, qos = group.Vlan, group.VlanQoS
+		proto = netlink.StringToVlanProtocol(sriovnetworkv1.GetVlanProto(group.VlanProto))
+	}
+	return s.netlinkLib.LinkSetVfVlanQosProto(pfLink, vfID, vlan, qos, int(proto))
+}
+
+// configSriovVFQoS applies QoS configuration of the VF group to the VF,
+// QoS configuration which is not requested by the group is removed from the VF
+func (s *sriov) configSriovVFQoS(pfName string, vfID int, group *sriovnetworkv1.VfGroup) error {
+	dscp, egressBandwidthMbps := int32(-1), int32(0)
+	if group.DSCP != nil {
+		dscp = *group.DSCP
+	}
+	if group.EgressBandwidthMbps != nil {
+		egressBandwidthMbps = *group.EgressBandwidthMbps
+	}
+	return s.networkHelper.ConfigureVfQoS(pfName, vfID, dscp, egressBandwidthMbps)
+}
+
+// checkNumaNode returns an error if the policies requested a NUMA node for the PF
+// and the PF is on another NUMA node or has no NUMA affinity
+func (s *sriov) checkNumaNode(iface *sriovnetworkv1.Interface) error {
+	if iface.NumaNode == nil {
+		return nil
+	}
+	numaNode := s.getDeviceNumaNode(iface.PciAddress)
+	if numaNode == nil {
+		return fmt.Errorf("NUMA node %d was requested for PF %s which has no NUMA affinity", *iface.NumaNode, iface.PciAddress)
+	}
+	if *numaNode != *iface.NumaNode {
+		return fmt.Errorf("NUMA node %d was requested for PF %s which is on NUMA node %d", *iface.NumaNode, iface.PciAddress, *numaNode)
 	}
 	return nil
 }
@@ -558,9 +1067,19 @@ func (s *sriov) configSriovVFDevices(iface *sriovnetworkv1.Interface) error {
 func (s *sriov) configSriovDevice(iface *sriovnetworkv1.Interface, skipVFConfiguration bool) error {
 	log.Log.V(2).Info("configSriovDevice(): configure sriov device",
 		"device", iface.PciAddress, "config", iface, "skipVFConfiguration", skipVFConfiguration)
+	start := time.Now()
+	defer func() {
+		log.Log.V(2).Info("configSriovDevice(): sriov device configuration finished",
+			"device", iface.PciAddress, "duration", time.Since(start).String())
+	}()
+	// the VFs created by the device are configured if it created less VFs than requested
+	var shortfallErr error
 	if !iface.ExternallyManaged {
 		if err := s.configSriovPFDevice(iface); err != nil {
-			return err
+			if !isNumVfsShortfall(err) {
+				return err
+			}
+			shortfallErr = err
 		}
 	}
 	if skipVFConfiguration {
@@ -569,7 +1088,10 @@ func (s *sriov) configSriovDevice(iface *sriovnetworkv1.Interface, skipVFConfigu
 		}
 		log.Log.V(2).Info("configSriovDevice(): skipVFConfiguration is true, unbind all VFs from drivers",
 			"device", iface.PciAddress)
-		return s.unbindAllVFsOnPF(iface.PciAddress)
+		if err := s.unbindAllVFsOnPF(iface.PciAddress); err != nil {
+			return err
+		}
+		return shortfallErr
 	}
 	// we don't need to validate externally managed PFs when skipVFConfiguration is true.
 	// The function usually called with skipVFConfiguration true when running in the systemd mode and configuration is
@@ -583,27 +1105,30 @@ func (s *sriov) configSriovDevice(iface *sriovnetworkv1.Interface, skipVFConfigu
 	if err := s.configSriovVFDevices(iface); err != nil {
 		return err
 	}
-	// Set PF link up
-	pfLink, err := s.netlinkLib.LinkByName(iface.Name)
-	if err != nil {
-		return err
-	}
-	if !s.netlinkLib.IsLinkAdminStateUp(pfLink) {
-		err = s.netlinkLib.LinkSetUp(pfLink)
-		if err != nil {
+	// PFs configured by the operator are brought up before the VFs are created
+	if iface.ExternallyManaged && !iface.DisablePfLinkManagement {
+		if err := s.networkHelper.SetNetDevLinkAdminState(iface.Name, consts.LinkAdminStateUp); err != nil {
 			return err
 		}
 	}
-	return nil
+	return shortfallErr
 }
 
 func (s *sriov) ConfigSriovInterfaces(storeManager store.ManagerInterface,
 	interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt, skipVFConfiguration bool) error {
+	if vars.NetdevsimMode {
+		return s.configNetdevsimInterfaces(storeManager, interfaces, ifaceStatuses)
+	}
 	toBeConfigured, toBeResetted, err := s.getConfigureAndReset(storeManager, interfaces, ifaceStatuses)
 	if err != nil {
 		log.Log.Error(err, "cannot get a list of interfaces to configure")
 		return fmt.Errorf("cannot get a list of interfaces to configure")
 	}
+	// interfaces with invalid VF groups are not configured, the other interfaces are
+	toBeConfigured, invalidErr := rejectInvalidVfGroups(toBeConfigured)
+	// interfaces which are not on the NUMA node requested by the policies are not configured as well
+	toBeConfigured, numaErr := s.rejectNumaNodeMismatch(toBeConfigured)
+	invalidErr = errors.Join(invalidErr, numaErr)
 
 	if vars.ParallelNicConfig {
 		err = s.configSriovInterfacesInParallel(storeManager, toBeConfigured, skipVFConfiguration)
@@ -612,7 +1137,7 @@ func (s *sriov) ConfigSriovInterfaces(storeManager store.ManagerInterface,
 	}
 	if err != nil {
 		log.Log.Error(err, "cannot configure sriov interfaces")
-		return fmt.Errorf("cannot configure sriov interfaces")
+		return fmt.Errorf("cannot configure sriov interfaces: %w", errors.Join(err, invalidErr))
 	}
 	if sriovnetworkv1.ContainsSwitchdevInterface(interfaces) && len(toBeConfigured) > 0 {
 		// for switchdev devices we create udev rule that renames VF representors
@@ -632,9 +1157,51 @@ func (s *sriov) ConfigSriovInterfaces(storeManager store.ManagerInterface,
 		log.Log.Error(err, "cannot reset sriov interfaces")
 		return fmt.Errorf("cannot reset sriov interfaces")
 	}
+	if invalidErr != nil {
+		return fmt.Errorf("cannot configure sriov interfaces: %w", invalidErr)
+	}
 	return nil
 }
 
+// rejectInvalidVfGroups removes the interfaces with overlapping or out of range VF groups from the interfaces
+// to configure, the errors of the removed interfaces are aggregated as InterfaceSyncErrors
+func rejectInvalidVfGroups(interfaces []interfaceToConfigure) ([]interfaceToConfigure, error) {
+	valid := make([]interfaceToConfigure, 0, len(interfaces))
+	var errs []error
+	for _, iface := range interfaces {
+		uncoveredVfs, vfGroupErrs := utils.ValidateVfGroups(&iface.iface)
+		if len(vfGroupErrs) > 0 {
+			err := errors.Join(vfGroupErrs...)
+			log.Log.Error(err, "rejectInvalidVfGroups(): invalid VF groups, skip interface configuration",
+				"address", iface.iface.PciAddress)
+			errs = append(errs, &types.InterfaceSyncError{PciAddress: iface.iface.PciAddress, Err: err})
+			continue
+		}
+		if len(uncoveredVfs) > 0 && len(iface.iface.VfGroups) > 0 {
+			log.Log.V(2).Info("rejectInvalidVfGroups(): VFs are not in any VF group",
+				"address", iface.iface.PciAddress, "vfs", uncoveredVfs)
+		}
+		valid = append(valid, iface)
+	}
+	return valid, errors.Join(errs...)
+}
+
+// rejectNumaNodeMismatch removes the interfaces which are not on the NUMA node requested by the policies
+// from the interfaces to configure, the errors of the removed interfaces are aggregated as InterfaceSyncErrors
+func (s *sriov) rejectNumaNodeMismatch(interfaces []interfaceToConfigure) ([]interfaceToConfigure, error) {
+	valid := make([]interfaceToConfigure, 0, len(interfaces))
+	var errs []error
+	for _, iface := range interfaces {
+		if err := s.checkNumaNode(&iface.iface); err != nil {
+			log.Log.Error(err, "rejectNumaNodeMismatch(): skip interface configuration", "address", iface.iface.PciAddress)
+			errs = append(errs, &types.InterfaceSyncError{PciAddress: iface.iface.PciAddress, Err: err})
+			continue
+		}
+		valid = append(valid, iface)
+	}
+	return valid, errors.Join(errs...)
+}
+
 func (s *sriov) getConfigureAndReset(storeManager store.ManagerInterface, interfaces []sriovnetworkv1.Interface,
 	ifaceStatuses []sriovnetworkv1.InterfaceExt) ([]interfaceToConfigure, []sriovnetworkv1.InterfaceExt, error) {
 	toBeConfigured := []interfaceToConfigure{}
@@ -665,41 +1232,63 @@ func (s *sriov) getConfigureAndReset(storeManager store.ManagerInterface, interf
 	return toBeConfigured, toBeResetted, nil
 }
 
+// forEachPFInParallel calls fn for each of the PFs, at most vars.ParallelNicConfigWorkers PFs are processed
+// at the same time. Errors of all PFs are aggregated as InterfaceSyncErrors.
+// Chroot is process wide, so all the goroutines run in the chroot established by the caller which
+// exits it only after this function returns.
+func forEachPFInParallel(pciAddresses []string, fn func(i int) error) error {
+	workers := vars.ParallelNicConfigWorkers
+	if workers <= 0 {
+		workers = len(pciAddresses)
+	}
+	sem := make(chan struct{}, max(workers, 1))
+	errs := make([]error, len(pciAddresses))
+	wg := sync.WaitGroup{}
+	for i := range pciAddresses {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(i); err != nil {
+				errs[i] = &types.InterfaceSyncError{PciAddress: pciAddresses[i], Err: err}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
 func (s *sriov) configSriovInterfacesInParallel(storeManager store.ManagerInterface, interfaces []interfaceToConfigure, skipVFConfiguration bool) error {
-	log.Log.V(2).Info("configSriovInterfacesInParallel(): start sriov configuration")
-
-	var result error
-	errChannel := make(chan error)
-	interfacesToConfigure := 0
-	for ifaceIndex, iface := range interfaces {
-		interfacesToConfigure += 1
-		go func(iface *interfaceToConfigure) {
-			var err error
-			if err = s.configSriovDevice(&iface.iface, skipVFConfiguration); err != nil {
-				log.Log.Error(err, "configSriovInterfacesInParallel(): fail to configure sriov interface. resetting interface.", "address", iface.iface.PciAddress)
-				if iface.iface.ExternallyManaged {
-					log.Log.V(2).Info("configSriovInterfacesInParallel(): skipping device reset as the nic is marked as externally created")
-				} else {
-					if resetErr := s.ResetSriovDevice(iface.ifaceStatus); resetErr != nil {
-						log.Log.Error(resetErr, "configSriovInterfacesInParallel(): failed to reset on error SR-IOV interface")
-						err = resetErr
-					}
+	log.Log.V(2).Info("configSriovInterfacesInParallel(): start sriov configuration", "workers", vars.ParallelNicConfigWorkers)
+
+	pciAddresses := make([]string, 0, len(interfaces))
+	for _, iface := range interfaces {
+		pciAddresses = append(pciAddresses, iface.iface.PciAddress)
+	}
+	result := forEachPFInParallel(pciAddresses, func(i int) error {
+		iface := &interfaces[i]
+		if err := s.configSriovDevice(&iface.iface, skipVFConfiguration); err != nil {
+			log.Log.Error(err, "configSriovInterfacesInParallel(): fail to configure sriov interface. resetting interface.", "address", iface.iface.PciAddress)
+			if iface.iface.ExternallyManaged {
+				log.Log.V(2).Info("configSriovInterfacesInParallel(): skipping device reset as the nic is marked as externally created")
+			} else if isNumVfsShortfall(err) {
+				log.Log.V(2).Info("configSriovInterfacesInParallel(): skipping device reset to keep the VFs created by the device")
+			} else {
+				if resetErr := s.ResetSriovDevice(iface.ifaceStatus); resetErr != nil {
+					log.Log.Error(resetErr, "configSriovInterfacesInParallel(): failed to reset on error SR-IOV interface")
+					return resetErr
 				}
 			}
-			errChannel <- err
-		}(&interfaces[ifaceIndex])
+			return err
+		}
 		// Save the PF status to the host
-		err := storeManager.SaveLastPfAppliedStatus(&iface.iface)
-		if err != nil {
+		if err := storeManager.SaveLastPfAppliedStatus(&iface.iface); err != nil {
 			log.Log.Error(err, "configSriovInterfacesInParallel(): failed to save PF applied config to host")
 			return err
 		}
-	}
-
-	for i := 0; i < interfacesToConfigure; i++ {
-		errMsg := <-errChannel
-		result = errors.Join(result, errMsg)
-	}
+		return nil
+	})
 	if result != nil {
 		log.Log.Error(result, "configSriovInterfacesInParallel(): fail to configure sriov interfaces")
 		return result
@@ -709,24 +1298,17 @@ func (s *sriov) configSriovInterfacesInParallel(storeManager store.ManagerInterf
 }
 
 func (s *sriov) resetSriovInterfacesInParallel(storeManager store.ManagerInterface, interfaces []sriovnetworkv1.InterfaceExt) error {
-	var result error
-	errChannel := make(chan error, len(interfaces))
-	interfacesToReset := 0
-	for ifaceIndex := range interfaces {
-		interfacesToReset += 1
-		go func(iface *sriovnetworkv1.InterfaceExt) {
-			var err error
-			if err = s.checkForConfigAndReset(*iface, storeManager); err != nil {
-				log.Log.Error(err, "resetSriovInterfacesInParallel(): fail to reset sriov interface. resetting interface.", "address", iface.PciAddress)
-			}
-			errChannel <- err
-		}(&interfaces[ifaceIndex])
-	}
-
-	for i := 0; i < interfacesToReset; i++ {
-		errMsg := <-errChannel
-		result = errors.Join(result, errMsg)
+	pciAddresses := make([]string, 0, len(interfaces))
+	for _, iface := range interfaces {
+		pciAddresses = append(pciAddresses, iface.PciAddress)
 	}
+	result := forEachPFInParallel(pciAddresses, func(i int) error {
+		if err := s.checkForConfigAndReset(interfaces[i], storeManager); err != nil {
+			log.Log.Error(err, "resetSriovInterfacesInParallel(): fail to reset sriov interface. resetting interface.", "address", interfaces[i].PciAddress)
+			return err
+		}
+		return nil
+	})
 	if result != nil {
 		log.Log.Error(result, "resetSriovInterfacesInParallel(): fail to reset sriov interface")
 		return result
@@ -736,28 +1318,37 @@ func (s *sriov) resetSriovInterfacesInParallel(storeManager store.ManagerInterfa
 	return nil
 }
 
+// configSriovInterfaces configures the PFs one by one, the failure of a PF doesn't stop the configuration
+// of the next PFs, the errors of all PFs are aggregated as InterfaceSyncErrors
 func (s *sriov) configSriovInterfaces(storeManager store.ManagerInterface, interfaces []interfaceToConfigure, skipVFConfiguration bool) error {
 	log.Log.V(2).Info("configSriovInterfaces(): start sriov configuration")
+	var errs []error
 	for _, iface := range interfaces {
 		if err := s.configSriovDevice(&iface.iface, skipVFConfiguration); err != nil {
 			log.Log.Error(err, "configSriovInterfaces(): fail to configure sriov interface. resetting interface.", "address", iface.iface.PciAddress)
 			if iface.iface.ExternallyManaged {
 				log.Log.V(2).Info("configSriovInterfaces(): skipping device reset as the nic is marked as externally created")
+			} else if isNumVfsShortfall(err) {
+				log.Log.V(2).Info("configSriovInterfaces(): skipping device reset to keep the VFs created by the device")
 			} else {
 				if resetErr := s.ResetSriovDevice(iface.ifaceStatus); resetErr != nil {
 					log.Log.Error(resetErr, "configSriovInterfaces(): failed to reset on error SR-IOV interface")
 				}
 			}
-			return err
+			errs = append(errs, &types.InterfaceSyncError{PciAddress: iface.iface.PciAddress, Err: err})
+			continue
 		}
 
 		// Save the PF status to the host
 		err := storeManager.SaveLastPfAppliedStatus(&iface.iface)
 		if err != nil {
 			log.Log.Error(err, "configSriovInterfaces(): failed to save PF applied config to host")
-			return err
+			errs = append(errs, &types.InterfaceSyncError{PciAddress: iface.iface.PciAddress, Err: err})
 		}
 	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
 	log.Log.V(2).Info("configSriovInterfaces(): sriov configuration finished")
 	return nil
 }
@@ -775,11 +1366,31 @@ func (s *sriov) resetSriovInterfaces(storeManager store.ManagerInterface, interf
 
 // / skipSriovConfig checks if we need to apply SR-IOV configuration specified specific interface
 func skipSriovConfig(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt, storeManager store.ManagerInterface) (bool, error) {
+	// the number of VFs and the MTU of externally managed PFs don't trigger an update,
+	// don't skip the PF so that the configuration fails if the PF was not prepared as expected
+	if iface.ExternallyManaged && (iface.NumVfs > ifaceStatus.NumVfs || iface.Mtu > ifaceStatus.Mtu) {
+		return false, nil
+	}
 	if !sriovnetworkv1.NeedToUpdateSriov(iface, ifaceStatus) {
+		// QoS configuration, bridge VLAN filters, double VLAN and RSS of VFs and devlink parameters of the PF
+		// are not reported in the status, compare them with the last applied configuration
+		lastApplied, exist, err := storeManager.LoadPfsStatus(iface.PciAddress)
+		if err != nil {
+			log.Log.Error(err, "ConfigSriovInterfaces(): failed to load PF applied status config from host")
+			return false, err
+		}
+		if !exist {
+			lastApplied = &sriovnetworkv1.Interface{}
+		}
+		if sriovnetworkv1.NeedToUpdateVfQoS(iface, lastApplied) || sriovnetworkv1.NeedToUpdateVfBridgeVLAN(iface, lastApplied) ||
+			sriovnetworkv1.NeedToUpdateVfQinQ(iface, lastApplied) || sriovnetworkv1.NeedToUpdateVfRSS(iface, lastApplied) ||
+			sriovnetworkv1.NeedToUpdateDevlinkParams(iface, lastApplied) {
+			return false, nil
+		}
 		log.Log.V(2).Info("ConfigSriovInterfaces(): no need update interface", "address", iface.PciAddress)
 
 		// Save the PF status to the host
-		err := storeManager.SaveLastPfAppliedStatus(iface)
+		err = storeManager.SaveLastPfAppliedStatus(iface)
 		if err != nil {
 			log.Log.Error(err, "ConfigSriovInterfaces(): failed to save PF applied status config to host")
 			return false, err
@@ -883,7 +1494,83 @@ func (s *sriov) ConfigSriovDeviceVirtual(iface *sriovnetworkv1.Interface) error
 	return nil
 }
 
+// ReconcileVfAttributes reapplies the trust mode, the spoof check, the tx rate and the VLAN of the VFs which differ
+// from their VF group, e.g. after a reset of the PF driver. Only the attributes requested by the group are
+// checked: the attributes left empty by the group and the VLAN of the VFs which are not used by DPDK
+// applications, which is set by the CNI, are not changed. A VF which fails doesn't prevent the others.
+func (s *sriov) ReconcileVfAttributes(iface *sriovnetworkv1.Interface) ([]types.VfAttributeCorrection, error) {
+	if iface.NumVfs == 0 || len(iface.VfGroups) == 0 {
+		return nil, nil
+	}
+	pfLink, err := s.netlinkLib.LinkByName(iface.Name)
+	if err != nil {
+		log.Log.Error(err, "ReconcileVfAttributes(): unable to get PF link for device", "device", iface.PciAddress)
+		return nil, err
+	}
+	var corrections []types.VfAttributeCorrection
+	var errs []error
+	reapply := func(info netlink.VfInfo, attribute string, apply func() error) {
+		funcLog := log.Log.WithValues("device", iface.PciAddress, "vf", info.ID, "attribute", attribute)
+		if err := apply(); err != nil {
+			funcLog.Error(err, "ReconcileVfAttributes(): failed to reapply VF attribute")
+			errs = append(errs, fmt.Errorf("failed to reapply %s of VF %d of %s: %v", attribute, info.ID, iface.PciAddress, err))
+			return
+		}
+		funcLog.Info("ReconcileVfAttributes(): VF attribute differed from the desired state, reapplied")
+		corrections = append(corrections, types.VfAttributeCorrection{VfID: info.ID, Attribute: attribute})
+	}
+	for _, info := range pfLink.Attrs().Vfs {
+		if info.ID >= iface.NumVfs {
+			continue
+		}
+		var group *sriovnetworkv1.VfGroup
+		for i := range iface.VfGroups {
+			if sriovnetworkv1.IndexInRange(info.ID, iface.VfGroups[i].VfRange) {
+				group = &iface.VfGroups[i]
+				break
+			}
+		}
+		if group == nil {
+			continue
+		}
+		// unset trust and spoof check settings are reset to the kernel defaults
+		trust := sriovnetworkv1.GetVfTrust(group.Trust) == sriovnetworkv1.SriovCniStateOn
+		if (info.Trust != 0) != trust {
+			reapply(info, types.VfAttributeTrust, func() error {
+				return s.netlinkLib.LinkSetVfTrust(pfLink, info.ID, trust)
+			})
+		}
+		spoofChk := sriovnetworkv1.GetVfSpoofChk(group.SpoofChk) == sriovnetworkv1.SriovCniStateOn
+		if info.Spoofchk != spoofChk {
+			reapply(info, types.VfAttributeSpoofChk, func() error {
+				return s.netlinkLib.LinkSetVfSpoofchk(pfLink, info.ID, spoofChk)
+			})
+		}
+		if (group.MinTxRate != 0 || group.MaxTxRate != 0) &&
+			(int(info.MinTxRate) != group.MinTxRate || int(info.MaxTxRate) != group.MaxTxRate) {
+			reapply(info, types.VfAttributeTxRate, func() error {
+				return s.netlinkLib.LinkSetVfRate(pfLink, info.ID, group.MinTxRate, group.MaxTxRate)
+			})
+		}
+		if group.Vlan != 0 && sriovnetworkv1.StringInArray(group.DeviceType, vars.DpdkDrivers) &&
+			(info.Vlan != group.Vlan || info.Qos != group.VlanQoS ||
+				vlanProtoToString(info.VlanProto) != sriovnetworkv1.GetVlanProto(group.VlanProto)) {
+			reapply(info, types.VfAttributeVlan, func() error {
+				return s.configSriovVFVlan(pfLink, info.ID, group)
+			})
+		}
+	}
+	return corrections, errors.Join(errs...)
+}
+
 func (s *sriov) GetNicSriovMode(pciAddress string) string {
+	mode, _ := s.getNicEswitchModes(pciAddress)
+	return mode
+}
+
+// getNicEswitchModes returns the eSwitch mode and the encapsulation mode of the eSwitch of the device,
+// legacy and an empty encapsulation mode if devlink doesn't report them
+func (s *sriov) getNicEswitchModes(pciAddress string) (string, string) {
 	log.Log.V(2).Info("GetNicSriovMode()", "device", pciAddress)
 	devLink, err := s.netlinkLib.DevLinkGetDeviceByName("pci", pciAddress)
 	if err != nil {
@@ -891,11 +1578,52 @@ func (s *sriov) GetNicSriovMode(pciAddress string) string {
 			log.Log.Error(err, "GetNicSriovMode(): failed to get eswitch mode, assume legacy", "device", pciAddress)
 		}
 	}
-	if devLink != nil && devLink.Attrs.Eswitch.Mode != "" {
-		return devLink.Attrs.Eswitch.Mode
+	if devLink == nil {
+		return sriovnetworkv1.ESwithModeLegacy, ""
+	}
+	mode := devLink.Attrs.Eswitch.Mode
+	if mode == "" {
+		mode = sriovnetworkv1.ESwithModeLegacy
+	}
+	// the netlink library reports the encapsulation mode as enable or disable
+	var encapMode string
+	switch devLink.Attrs.Eswitch.EncapMode {
+	case "enable":
+		encapMode = sriovnetworkv1.ESwitchEncapModeBasic
+	case "disable":
+		encapMode = sriovnetworkv1.ESwitchEncapModeNone
+	}
+	return mode, encapMode
+}
+
+// configureEswitchEncapMode sets the encapsulation mode of the eSwitch of the device before it is switched
+// to switchdev mode, the mode is not changed if the encapsulation mode is not requested
+func (s *sriov) configureEswitchEncapMode(pciAddress, desiredEswitchMode, encapMode string) error {
+	if !s.needEswitchEncapModeUpdate(pciAddress, desiredEswitchMode, encapMode) {
+		return nil
+	}
+	log.Log.V(2).Info("configureEswitchEncapMode(): set eSwitch encapsulation mode", "device", pciAddress, "encapMode", encapMode)
+	dev, err := s.netlinkLib.DevLinkGetDeviceByName("pci", pciAddress)
+	if err != nil {
+		return fmt.Errorf("can't get devlink device [%s] to set eSwitch encapsulation mode to [%s]: %w", pciAddress, encapMode, err)
+	}
+	if err := s.netlinkLib.DevLinkSetEswitchEncapMode(dev, encapMode); err != nil {
+		if errors.Is(err, syscall.EOPNOTSUPP) {
+			err = fmt.Errorf("%w: %w", types.ErrEswitchModeNotSupported, err)
+		}
+		return fmt.Errorf("can't set eSwitch encapsulation mode to [%s] on device [%s]: %w", encapMode, pciAddress, err)
 	}
+	return nil
+}
 
-	return sriovnetworkv1.ESwithModeLegacy
+// needEswitchEncapModeUpdate returns true if the encapsulation mode is requested for the switchdev mode
+// and differs from the encapsulation mode of the eSwitch of the device
+func (s *sriov) needEswitchEncapModeUpdate(pciAddress, desiredEswitchMode, encapMode string) bool {
+	if encapMode == "" || desiredEswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+		return false
+	}
+	_, currentEncapMode := s.getNicEswitchModes(pciAddress)
+	return currentEncapMode != encapMode
 }
 
 func (s *sriov) SetNicSriovMode(pciAddress string, mode string) error {
@@ -908,8 +1636,29 @@ func (s *sriov) SetNicSriovMode(pciAddress string, mode string) error {
 
 	err = s.netlinkLib.DevLinkSetEswitchMode(dev, mode)
 	if err != nil {
+		switch {
+		case errors.Is(err, syscall.EOPNOTSUPP):
+			err = fmt.Errorf("%w: %w", types.ErrEswitchModeNotSupported, err)
+		case errors.Is(err, syscall.EBUSY):
+			err = fmt.Errorf("%w: %w", types.ErrEswitchModeVFsBound, err)
+		}
 		return fmt.Errorf("can't set eSwitch mode to [%s] on device [%s]: %w", mode, pciAddress, err)
 	}
+
+	// the driver may complete the transition asynchronously, read the mode back before proceeding
+	var currentMode string
+	err = wait.PollImmediate(eswitchModePollInterval, eswitchModePollTimeout, func() (bool, error) {
+		dev, err := s.netlinkLib.DevLinkGetDeviceByName("pci", pciAddress)
+		if err != nil {
+			log.Log.Error(err, "SetNicSriovMode(): failed to read eSwitch mode", "device", pciAddress)
+			return false, nil
+		}
+		currentMode = dev.Attrs.Eswitch.Mode
+		return currentMode == mode, nil
+	})
+	if err != nil {
+		return fmt.Errorf("eSwitch mode of device [%s] is [%s] after the change to [%s]: %w", pciAddress, currentMode, mode, err)
+	}
 	return nil
 }
 
@@ -990,18 +1739,82 @@ func (s *sriov) createVFs(iface *sriovnetworkv1.Interface) error {
 		"device", iface.PciAddress, "count", iface.NumVfs, "mode", expectedEswitchMode)
 
 	if s.dputilsLib.GetVFconfigured(iface.PciAddress) == iface.NumVfs {
-		if s.GetNicSriovMode(iface.PciAddress) == expectedEswitchMode {
+		if s.GetNicSriovMode(iface.PciAddress) == expectedEswitchMode &&
+			!s.needEswitchEncapModeUpdate(iface.PciAddress, expectedEswitchMode, iface.EswitchEncapMode) {
 			log.Log.V(2).Info("createVFs(): device is already configured",
 				"device", iface.PciAddress, "count", iface.NumVfs, "mode", expectedEswitchMode)
 			return nil
 		}
 	}
-	return s.setEswitchModeAndNumVFs(iface.PciAddress, expectedEswitchMode, iface.NumVfs)
+	return s.setEswitchModeAndNumVFs(iface.PciAddress, expectedEswitchMode, iface.EswitchEncapMode, iface.NumVfs)
 }
 
-type setEswitchModeAndNumVFsFn func(string, string, int) error
+type setEswitchModeAndNumVFsFn func(string, string, string, int) error
+
+// waitForVFsRelease waits until no VF of the PF is allocated to a pod by the device plugin,
+// the pods may still run after the node is drained, e.g. if they have a long termination grace period.
+// The wait is enabled with vars.WaitForVfRelease, the VFs are not checked if the kubelet is not running.
+func (s *sriov) waitForVFsRelease(pciAddr string) error {
+	if !vars.WaitForVfRelease {
+		return nil
+	}
+	vfAddrs, err := getVFPciAddresses(pciAddr)
+	if err != nil {
+		log.Log.Error(err, "waitForVFsRelease(): failed to list VFs of the device", "device", pciAddr)
+		return err
+	}
+	if len(vfAddrs) == 0 {
+		return nil
+	}
+	var blockingPods []string
+	err = wait.PollImmediate(vfReleasePollInterval, vars.VfReleaseTimeout, func() (bool, error) {
+		devices, err := s.podResourcesLib.GetAllocatedDevices()
+		if err != nil {
+			return false, err
+		}
+		blockingPods = nil
+		for _, vfAddr := range vfAddrs {
+			if pod, ok := devices[vfAddr]; ok {
+				blockingPods = append(blockingPods, fmt.Sprintf("%s (VF %s)", pod, vfAddr))
+			}
+		}
+		if len(blockingPods) == 0 {
+			return true, nil
+		}
+		log.Log.Info("waitForVFsRelease(): VFs are still allocated to pods, wait",
+			"device", pciAddr, "pods", blockingPods)
+		return false, nil
+	})
+	if errors.Is(err, podresourcesPkg.ErrKubeletUnavailable) {
+		log.Log.Info("waitForVFsRelease(): can't check if VFs are allocated to pods, continue",
+			"device", pciAddr, "reason", err.Error())
+		return nil
+	}
+	if wait.Interrupted(err) {
+		return fmt.Errorf("VFs of device %s are still allocated to pods after %s: %s",
+			pciAddr, vars.VfReleaseTimeout, strings.Join(blockingPods, ", "))
+	}
+	return err
+}
 
-func (s *sriov) setEswitchModeAndNumVFs(pciAddr string, desiredEswitchMode string, numVFs int) error {
+// getVFPciAddresses returns PCI addresses of the VFs of the PF
+func getVFPciAddresses(pfPciAddr string) ([]string, error) {
+	links, err := filepath.Glob(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pfPciAddr, "virtfn*"))
+	if err != nil {
+		return nil, err
+	}
+	vfAddrs := make([]string, 0, len(links))
+	for _, link := range links {
+		target, err := os.Readlink(link)
+		if err != nil {
+			return nil, err
+		}
+		vfAddrs = append(vfAddrs, filepath.Base(target))
+	}
+	return vfAddrs, nil
+}
+
+func (s *sriov) setEswitchModeAndNumVFs(pciAddr string, desiredEswitchMode, encapMode string, numVFs int) error {
 	pfDriverName, err := s.dputilsLib.GetDriverName(pciAddr)
 	if err != nil {
 		return err
@@ -1010,6 +1823,11 @@ func (s *sriov) setEswitchModeAndNumVFs(pciAddr string, desiredEswitchMode strin
 	log.Log.V(2).Info("setEswitchModeAndNumVFs(): configure VFs for device",
 		"device", pciAddr, "count", numVFs, "mode", desiredEswitchMode, "driver", pfDriverName)
 
+	// the existing VFs are always removed before the eSwitch mode or the number of VFs is changed
+	if err := s.waitForVFsRelease(pciAddr); err != nil {
+		return err
+	}
+
 	setEswitchModeAndNumVFsByDriverName := map[string]setEswitchModeAndNumVFsFn{
 		"ice":       s.setEswitchModeAndNumVFsIce,
 		"mlx5_core": s.setEswitchModeAndNumVFsMlx,
@@ -1024,15 +1842,15 @@ func (s *sriov) setEswitchModeAndNumVFs(pciAddr string, desiredEswitchMode strin
 		fn = s.setEswitchModeAndNumVFsMlx
 	}
 
-	return fn(pciAddr, desiredEswitchMode, numVFs)
+	return fn(pciAddr, desiredEswitchMode, encapMode, numVFs)
 }
 
 // setEswitchModeAndNumVFsMlx configures PF eSwitch and sriov_numvfs in the following order:
 // a. set eSwitchMode to legacy
 // b. set the desired number of Virtual Functions
 // c. unbind driver of all VFs
-// d. set eSwitchMode to `switchdev` if requested
-func (s *sriov) setEswitchModeAndNumVFsMlx(pciAddr string, desiredEswitchMode string, numVFs int) error {
+// d. set the eSwitch encapsulation mode and set eSwitchMode to `switchdev` if requested
+func (s *sriov) setEswitchModeAndNumVFsMlx(pciAddr string, desiredEswitchMode, encapMode string, numVFs int) error {
 	log.Log.V(2).Info("setEswitchModeAndNumVFsMlx(): configure VFs for device",
 		"device", pciAddr, "count", numVFs, "mode", desiredEswitchMode)
 
@@ -1052,8 +1870,10 @@ func (s *sriov) setEswitchModeAndNumVFsMlx(pciAddr string, desiredEswitchMode st
 			return err
 		}
 	}
-	if err := s.SetSriovNumVfs(pciAddr, numVFs); err != nil {
-		return err
+	// the eSwitch mode is configured with the created VFs if the device created less VFs than requested
+	shortfallErr := s.SetSriovNumVfs(pciAddr, numVFs)
+	if shortfallErr != nil && !isNumVfsShortfall(shortfallErr) {
+		return shortfallErr
 	}
 
 	if desiredEswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
@@ -1061,36 +1881,40 @@ func (s *sriov) setEswitchModeAndNumVFsMlx(pciAddr string, desiredEswitchMode st
 			log.Log.Error(err, "setEswitchModeAndNumVFsMlx(): failed to unbind VFs", "device", pciAddr, "mode", desiredEswitchMode)
 			return err
 		}
+		if err := s.configureEswitchEncapMode(pciAddr, desiredEswitchMode, encapMode); err != nil {
+			return err
+		}
 		if err := s.SetNicSriovMode(pciAddr, desiredEswitchMode); err != nil {
 			return err
 		}
 	}
-	return nil
+	return shortfallErr
 }
 
 // setEswitchModeAndNumVFsIce configures PF eSwitch and sriov_numvfs in the following order:
 // a. set eSwitchMode to the desired mode if needed
-// a1. set sriov_numvfs to 0 before updating the eSwitchMode
+// a1. set sriov_numvfs to 0 before updating the eSwitchMode or the eSwitch encapsulation mode
+// a2. set the eSwitch encapsulation mode if requested
 // b. set sriov_numvfs to the desired number of VFs
-func (s *sriov) setEswitchModeAndNumVFsIce(pciAddr string, desiredEswitchMode string, numVFs int) error {
+func (s *sriov) setEswitchModeAndNumVFsIce(pciAddr string, desiredEswitchMode, encapMode string, numVFs int) error {
 	log.Log.V(2).Info("setEswitchModeAndNumVFsIce(): configure VFs for device",
 		"device", pciAddr, "count", numVFs, "mode", desiredEswitchMode)
 
-	if s.GetNicSriovMode(pciAddr) != desiredEswitchMode {
+	if s.GetNicSriovMode(pciAddr) != desiredEswitchMode || s.needEswitchEncapModeUpdate(pciAddr, desiredEswitchMode, encapMode) {
 		if err := s.SetSriovNumVfs(pciAddr, 0); err != nil {
 			return err
 		}
 
-		if err := s.SetNicSriovMode(pciAddr, desiredEswitchMode); err != nil {
+		if err := s.configureEswitchEncapMode(pciAddr, desiredEswitchMode, encapMode); err != nil {
 			return err
 		}
-	}
 
-	if err := s.SetSriovNumVfs(pciAddr, numVFs); err != nil {
-		return err
+		if err := s.SetNicSriovMode(pciAddr, desiredEswitchMode); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return s.SetSriovNumVfs(pciAddr, numVFs)
 }
 
 // detach PF from the managed bridge
@@ -1,15 +1,20 @@
 package sriov
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jaypipes/ghw"
 	"github.com/jaypipes/pcidb"
 	"github.com/vishvananda/netlink"
+	"k8s.io/utils/pointer"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -17,11 +22,15 @@ import (
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	dputilsMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils/mock"
 	ghwMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ghw/mock"
+	netlinkPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
 	netlinkMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink/mock"
+	podresourcesPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/podresources"
+	podresourcesMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/podresources/mock"
 	sriovnetMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/sriovnet/mock"
 	hostMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/mock"
 	hostStoreMockPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store/mock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
 )
@@ -33,6 +42,7 @@ var _ = Describe("SRIOV", func() {
 		dputilsLibMock   *dputilsMockPkg.MockDPUtilsLib
 		sriovnetLibMock  *sriovnetMockPkg.MockSriovnetLib
 		ghwLibMock       *ghwMockPkg.MockGHWLib
+		podResourcesMock *podresourcesMockPkg.MockPodResourcesLib
 		hostMock         *hostMockPkg.MockHostManagerInterface
 		storeManagerMode *hostStoreMockPkg.MockManagerInterface
 		testCtrl         *gomock.Controller
@@ -45,11 +55,13 @@ var _ = Describe("SRIOV", func() {
 		dputilsLibMock = dputilsMockPkg.NewMockDPUtilsLib(testCtrl)
 		sriovnetLibMock = sriovnetMockPkg.NewMockSriovnetLib(testCtrl)
 		ghwLibMock = ghwMockPkg.NewMockGHWLib(testCtrl)
+		podResourcesMock = podresourcesMockPkg.NewMockPodResourcesLib(testCtrl)
 
 		hostMock = hostMockPkg.NewMockHostManagerInterface(testCtrl)
 		storeManagerMode = hostStoreMockPkg.NewMockManagerInterface(testCtrl)
 
-		s = New(nil, hostMock, hostMock, hostMock, hostMock, hostMock, netlinkLibMock, dputilsLibMock, sriovnetLibMock, ghwLibMock, hostMock)
+		s = New(nil, hostMock, hostMock, hostMock, hostMock, hostMock, netlinkLibMock, dputilsLibMock, sriovnetLibMock, ghwLibMock,
+			podResourcesMock, hostMock)
 	})
 
 	AfterEach(func() {
@@ -88,17 +100,26 @@ var _ = Describe("SRIOV", func() {
 				MTU:          1500,
 				HardwareAddr: mac,
 				EncapType:    "ether",
+				Vfs: []netlink.VfInfo{{
+					ID: 0, Trust: 1, Spoofchk: false, MinTxRate: 100, MaxTxRate: 2000,
+					// the VLAN protocol is reported in the network byte order
+					Vlan: 10, Qos: 2, VlanProto: 0x0081,
+				}},
 			}).MinTimes(1)
 			hostMock.EXPECT().GetNetDevLinkSpeed("enp216s0f0np0").Return("100000 Mb/s")
 			hostMock.EXPECT().GetNetDevLinkAdminState("enp216s0f0np0").Return("up")
+			hostMock.EXPECT().GetNetDevFirmwareVersion("enp216s0f0np0").Return("22.31.1014")
+			hostMock.EXPECT().GetNetDevBondMaster("enp216s0f0np0").Return("bond0")
 			hostMock.EXPECT().GetNetDevNodeGUID("0000:d8:00.2").Return("guid1")
+			hostMock.EXPECT().GetDeviceNumaNode("0000:d8:00.0").Return(1, nil)
+			hostMock.EXPECT().GetDeviceNumaNode("0000:d8:00.2").Return(1, nil)
 			storeManagerMode.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(nil, false, nil)
 
 			dputilsLibMock.EXPECT().IsSriovPF("0000:d8:00.0").Return(true)
 			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:d8:00.0").Return(1)
 			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(1)
 			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(
-				&netlink.DevlinkDevice{Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "switchdev"}}}, nil)
+				&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "switchdev"}}}, nil)
 			dputilsLibMock.EXPECT().SriovConfigured("0000:d8:00.0").Return(true)
 			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil)
 			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.2").Return("mlx5_core", nil)
@@ -116,6 +137,10 @@ var _ = Describe("SRIOV", func() {
 			}).MinTimes(1)
 
 			sriovnetLibMock.EXPECT().GetVfRepresentor("enp216s0f0np0", 0).Return("enp216s0f0np0_0", nil)
+			repLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			repLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0np0_0", MTU: 9000})
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0_0").Return(repLinkMock, nil)
+			netlinkLibMock.EXPECT().IsLinkAdminStateUp(repLinkMock).Return(true)
 
 			ret, err := s.DiscoverSriovDevices(storeManagerMode)
 			Expect(err).NotTo(HaveOccurred())
@@ -132,20 +157,33 @@ var _ = Describe("SRIOV", func() {
 				LinkSpeed:         "100000 Mb/s",
 				LinkType:          "ETH",
 				LinkAdminState:    "up",
+				FirmwareVersion:   "22.31.1014",
+				BondMaster:        "bond0",
 				EswitchMode:       "switchdev",
 				ExternallyManaged: false,
 				TotalVfs:          1,
+				NumaNode:          pointer.Int(1),
 				VFs: []sriovnetworkv1.VirtualFunction{{
-					Name:            "enp216s0f0v0",
-					Mac:             "4e:fd:3d:08:59:b1",
-					Driver:          "mlx5_core",
-					PciAddress:      "0000:d8:00.2",
-					Vendor:          "15b3",
-					DeviceID:        "101e",
-					Mtu:             1500,
-					VfID:            0,
-					RepresentorName: "enp216s0f0np0_0",
-					GUID:            "guid1",
+					Name:                      "enp216s0f0v0",
+					Mac:                       "4e:fd:3d:08:59:b1",
+					Driver:                    "mlx5_core",
+					PciAddress:                "0000:d8:00.2",
+					Vendor:                    "15b3",
+					DeviceID:                  "101e",
+					Mtu:                       1500,
+					VfID:                      0,
+					RepresentorName:           "enp216s0f0np0_0",
+					RepresentorMtu:            9000,
+					RepresentorLinkAdminState: "up",
+					GUID:                      "guid1",
+					Trust:                     "on",
+					SpoofChk:                  "off",
+					MinTxRate:                 100,
+					MaxTxRate:                 2000,
+					Vlan:                      10,
+					VlanQoS:                   2,
+					VlanProto:                 "802.1q",
+					NumaNode:                  pointer.Int(1),
 				}},
 			}))
 		})
@@ -163,12 +201,97 @@ var _ = Describe("SRIOV", func() {
 		It("fail - no such device", func() {
 			Expect(s.SetSriovNumVfs("0000:d8:00.0", 5)).To(HaveOccurred())
 		})
+		Context("partially created VFs", func() {
+			var (
+				origWriteNumVfsFile func(string, int) error
+				writes              []int
+				maxVfs              int
+				failures            int
+			)
+			BeforeEach(func() {
+				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+					Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+					Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+				})
+				writes = nil
+				origWriteNumVfsFile = writeNumVfsFile
+				// the device creates at most maxVfs VFs before the write fails, the first failures writes fail
+				// even if the device can create the VFs
+				writeNumVfsFile = func(path string, numVfs int) error {
+					writes = append(writes, numVfs)
+					if numVfs > maxVfs || (numVfs > 0 && failures > 0) {
+						failures--
+						Expect(origWriteNumVfsFile(path, min(numVfs, maxVfs))).To(Succeed())
+						return syscall.EIO
+					}
+					return origWriteNumVfsFile(path, numVfs)
+				}
+				DeferCleanup(func() {
+					writeNumVfsFile = origWriteNumVfsFile
+				})
+			})
+
+			It("should retry to create the VFs", func() {
+				maxVfs = 64
+				failures = 1
+				Expect(s.SetSriovNumVfs("0000:d8:00.0", 64)).To(Succeed())
+				Expect(writes).To(Equal([]int{0, 64, 0, 64}))
+				helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "64")
+			})
+
+			It("should fall back to the number of VFs the device created", func() {
+				maxVfs = 40
+				failures = 0
+				err := s.SetSriovNumVfs("0000:d8:00.0", 64)
+				Expect(writes).To(Equal([]int{0, 64, 0, 64, 0, 40}))
+				helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "40")
+				shortfallErr := &types.NumVfsShortfallError{}
+				Expect(errors.As(err, &shortfallErr)).To(BeTrue())
+				Expect(shortfallErr.NumVfs).To(Equal(64))
+				Expect(shortfallErr.CreatedVfs).To(Equal(40))
+				Expect(shortfallErr).To(MatchError(syscall.EIO))
+			})
+
+			It("should fail if the device didn't create any VF", func() {
+				maxVfs = 0
+				failures = 0
+				Expect(s.SetSriovNumVfs("0000:d8:00.0", 64)).To(MatchError(syscall.EIO))
+				Expect(writes).To(Equal([]int{0, 64, 0, 64, 0}))
+			})
+		})
+		It("read VF BARs", func() {
+			resources := []string{
+				"0x00000000c6000000 0x00000000c7ffffff 0x000000000014220c",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+				"0x00000000c5e00000 0x00000000c5efffff 0x0000000000046200",
+				"0x0000000000000000 0x00000000007fffff 0x000000000014220c",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+				"0x00000000c8000000 0x00000000c80fffff 0x000000000014220c",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+				"0x0000000000000000 0x0000000000000000 0x0000000000000000",
+			}
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{
+					"/sys/bus/pci/devices/0000:d8:00.0/resource": []byte(strings.Join(resources, "\n") + "\n"),
+				},
+			})
+			Expect(readVFBars("0000:d8:00.0")).To(Equal([]types.VFBar{
+				{Index: 0, Start: 0, Size: 0x800000},
+				{Index: 2, Start: 0xc8000000, Size: 0x100000},
+			}))
+		})
 	})
 
 	Context("GetNicSriovMode", func() {
 		It("devlink returns info", func() {
 			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(
-				&netlink.DevlinkDevice{Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "switchdev"}}},
+				&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "switchdev"}}},
 				nil)
 			mode := s.GetNicSriovMode("0000:d8:00.0")
 			Expect(mode).To(Equal("switchdev"))
@@ -187,19 +310,54 @@ var _ = Describe("SRIOV", func() {
 	})
 
 	Context("SetNicSriovMode", func() {
+		BeforeEach(func() {
+			origInterval, origTimeout := eswitchModePollInterval, eswitchModePollTimeout
+			DeferCleanup(func() { eswitchModePollInterval, eswitchModePollTimeout = origInterval, origTimeout })
+			eswitchModePollInterval, eswitchModePollTimeout = time.Millisecond, 10*time.Millisecond
+		})
+		legacyDev := &netlinkPkg.DevlinkDevice{
+			Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}
 		It("set", func() {
-			testDev := &netlink.DevlinkDevice{}
-			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlink.DevlinkDevice{}, nil)
+			testDev := &netlinkPkg.DevlinkDevice{}
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{}, nil)
 			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(testDev, "legacy").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(legacyDev, nil)
+			Expect(s.SetNicSriovMode("0000:d8:00.0", "legacy")).NotTo(HaveOccurred())
+		})
+		It("wait for the mode change to complete", func() {
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{}, nil)
+			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(gomock.Any(), "legacy").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(nil, testError)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "switchdev"}}}, nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(legacyDev, nil)
 			Expect(s.SetNicSriovMode("0000:d8:00.0", "legacy")).NotTo(HaveOccurred())
 		})
+		It("fail if the mode is not changed", func() {
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(legacyDev, nil).MinTimes(2)
+			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(gomock.Any(), "switchdev").Return(nil)
+			Expect(s.SetNicSriovMode("0000:d8:00.0", "switchdev")).To(MatchError(
+				ContainSubstring("eSwitch mode of device [0000:d8:00.0] is [legacy] after the change to [switchdev]")))
+		})
+		It("not supported", func() {
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(legacyDev, nil)
+			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(gomock.Any(), "switchdev").Return(syscall.EOPNOTSUPP)
+			err := s.SetNicSriovMode("0000:d8:00.0", "switchdev")
+			Expect(err).To(MatchError(types.ErrEswitchModeNotSupported))
+			Expect(err).To(MatchError(syscall.EOPNOTSUPP))
+		})
+		It("VFs are bound", func() {
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(legacyDev, nil)
+			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(gomock.Any(), "switchdev").Return(syscall.EBUSY)
+			Expect(s.SetNicSriovMode("0000:d8:00.0", "switchdev")).To(MatchError(types.ErrEswitchModeVFsBound))
+		})
 		It("fail to get dev", func() {
 			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(nil, testError)
 			Expect(s.SetNicSriovMode("0000:d8:00.0", "legacy")).To(MatchError(testError))
 		})
 		It("fail to set mode", func() {
-			testDev := &netlink.DevlinkDevice{}
-			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlink.DevlinkDevice{}, nil)
+			testDev := &netlinkPkg.DevlinkDevice{}
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{}, nil)
 			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(testDev, "legacy").Return(testError)
 			Expect(s.SetNicSriovMode("0000:d8:00.0", "legacy")).To(MatchError(testError))
 		})
@@ -215,34 +373,42 @@ var _ = Describe("SRIOV", func() {
 			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:d8:00.0").Return(2)
 			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
 			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("mlx5_core", nil)
-			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlink.DevlinkDevice{
-				Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil)
 			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().AddDisableNMUdevRule("0000:d8:00.0").Return(nil)
 			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil)
 			pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
-			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil).Times(3)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil).Times(2)
 			pfLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Flags: 0, EncapType: "ether"})
-			netlinkLibMock.EXPECT().IsLinkAdminStateUp(pfLinkMock).Return(false)
-			netlinkLibMock.EXPECT().LinkSetUp(pfLinkMock).Return(nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
 
 			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil).Times(2)
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 0, 1000, 5000).Return(nil)
 			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(false, "")
 			hostMock.EXPECT().BindDefaultDriver("0000:d8:00.2").Return(nil)
 			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "test")
 			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.2", true).Return(nil)
 			hostMock.EXPECT().BindDefaultDriver("0000:d8:00.2").Return(nil)
 			hostMock.EXPECT().SetNetdevMTU("0000:d8:00.2", 2000).Return(nil)
+			hostMock.EXPECT().ConfigureVfQoS("enp216s0f0np0", 0, int32(46), int32(1000)).Return(nil)
 			hostMock.EXPECT().GetInterfaceIndex("0000:d8:00.2").Return(42, nil)
 			vf0LinkMock := netlinkMockPkg.NewMockLink(testCtrl)
 			vf0Mac, _ := net.ParseMAC("02:42:19:51:2f:af")
 			vf0LinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0_0", HardwareAddr: vf0Mac}).AnyTimes()
 			netlinkLibMock.EXPECT().LinkByIndex(42).Return(vf0LinkMock, nil)
 			netlinkLibMock.EXPECT().LinkSetVfHardwareAddr(vf0LinkMock, 0, vf0Mac).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vf0LinkMock, vf0Mac).Return(nil)
 
 			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.3").Return(1, nil)
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 1, false).Return(syscall.EOPNOTSUPP)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 1, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 1, 0, 0).Return(syscall.EOPNOTSUPP)
+			netlinkLibMock.EXPECT().LinkSetVfVlanQosProto(pfLinkMock, 1, 100, 3, int(netlink.VLAN_PROTOCOL_8021AD)).Return(nil)
 			hostMock.EXPECT().HasDriver("0000:d8:00.3").Return(true, "vfio-pci").Times(2)
 			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.3", false).Return(nil)
 			hostMock.EXPECT().BindDpdkDriver("0000:d8:00.3", "vfio-pci").Return(nil)
@@ -256,11 +422,17 @@ var _ = Describe("SRIOV", func() {
 					NumVfs:     2,
 					VfGroups: []sriovnetworkv1.VfGroup{
 						{
-							VfRange:      "0-0",
-							ResourceName: "test-resource0",
-							PolicyName:   "test-policy0",
-							Mtu:          2000,
-							IsRdma:       true,
+							VfRange:             "0-0",
+							ResourceName:        "test-resource0",
+							PolicyName:          "test-policy0",
+							Mtu:                 2000,
+							IsRdma:              true,
+							DSCP:                pointer.Int32(46),
+							EgressBandwidthMbps: pointer.Int32(1000),
+							Trust:               "on",
+							SpoofChk:            "off",
+							MinTxRate:           1000,
+							MaxTxRate:           5000,
 						},
 						{
 							VfRange:      "1-1",
@@ -269,12 +441,111 @@ var _ = Describe("SRIOV", func() {
 							Mtu:          1600,
 							IsRdma:       false,
 							DeviceType:   "vfio-pci",
+							Vlan:         100,
+							VlanQoS:      3,
+							VlanProto:    "802.1AD",
 						}},
 				}},
 				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}, {PciAddress: "0000:d8:00.1"}},
 				false)).NotTo(HaveOccurred())
 			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "2")
 		})
+		It("should configure the other VFs if a VF fails to bind", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+
+			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:d8:00.0").Return(2)
+			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
+			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("ice", nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil)
+			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().AddDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil)
+			pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
+
+			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(false, "")
+			hostMock.EXPECT().BindDefaultDriver("0000:d8:00.2").Return(
+				fmt.Errorf("driver binding of device 0000:d8:00.2 failed after 5 attempts: %w", syscall.EBUSY))
+
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.3").Return(1, nil)
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 1, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 1, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 1, 0, 0).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfVlanQosProto(pfLinkMock, 1, 0, 0, int(netlink.VLAN_PROTOCOL_8021Q)).Return(nil)
+			hostMock.EXPECT().HasDriver("0000:d8:00.3").Return(true, "vfio-pci").Times(2)
+			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.3", false).Return(nil)
+			hostMock.EXPECT().BindDpdkDriver("0000:d8:00.3", "vfio-pci").Return(nil)
+
+			err := s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{{
+					Name:       "enp216s0f0np0",
+					PciAddress: "0000:d8:00.0",
+					NumVfs:     2,
+					VfGroups: []sriovnetworkv1.VfGroup{{
+						VfRange:      "0-1",
+						ResourceName: "test-resource0",
+						PolicyName:   "test-policy0",
+						DeviceType:   "vfio-pci",
+					}},
+				}},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}},
+				false)
+			Expect(err).To(MatchError(syscall.EBUSY))
+			Expect(err).To(MatchError(ContainSubstring("failed to bind VFs 0000:d8:00.2 of device 0000:d8:00.0")))
+		})
+		It("should stop if the driver is not loaded", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+
+			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:d8:00.0").Return(2)
+			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
+			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("ice", nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil)
+			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().AddDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil)
+			pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
+
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil)
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 0, 0, 0).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfVlanQosProto(pfLinkMock, 0, 0, 0, int(netlink.VLAN_PROTOCOL_8021Q)).Return(nil)
+			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "vfio-pci").Times(2)
+			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.2", false).Return(nil)
+			hostMock.EXPECT().BindDpdkDriver("0000:d8:00.2", "vfio-pci").Return(
+				fmt.Errorf("%w: %s", types.ErrDriverNotFound, "vfio-pci"))
+
+			err := s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{{
+					Name:       "enp216s0f0np0",
+					PciAddress: "0000:d8:00.0",
+					NumVfs:     2,
+					VfGroups: []sriovnetworkv1.VfGroup{{
+						VfRange:      "0-1",
+						ResourceName: "test-resource0",
+						PolicyName:   "test-policy0",
+						DeviceType:   "vfio-pci",
+					}},
+				}},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}},
+				false)
+			Expect(err).To(MatchError(types.ErrDriverNotFound))
+		})
 		It("should configure IB", func() {
 			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
 				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
@@ -284,23 +555,26 @@ var _ = Describe("SRIOV", func() {
 			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:d8:00.0").Return(1)
 			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
 			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("mlx5_core", nil)
-			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlink.DevlinkDevice{
-				Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil)
 			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().AddDisableNMUdevRule("0000:d8:00.0").Return(nil)
 			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil)
 			pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
-			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil).Times(2)
-			netlinkLibMock.EXPECT().IsLinkAdminStateUp(pfLinkMock).Return(false)
-			netlinkLibMock.EXPECT().LinkSetUp(pfLinkMock).Return(nil)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
 
 			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil).Times(1)
 			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "test").Times(2)
 			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.2", true).Return(nil)
 			hostMock.EXPECT().BindDefaultDriver("0000:d8:00.2").Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 0, 0, 0).Return(nil)
 			hostMock.EXPECT().SetNetdevMTU("0000:d8:00.2", 2000).Return(nil)
+			hostMock.EXPECT().ConfigureVfQoS("enp216s0f0np0", 0, int32(-1), int32(0)).Return(nil)
 			hostMock.EXPECT().ConfigureVfGUID(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 			hostMock.EXPECT().Unbind(gomock.Any()).Return(nil).Times(1)
@@ -345,12 +619,13 @@ var _ = Describe("SRIOV", func() {
 			hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode").Return("", syscall.EINVAL)
 			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil).Times(2)
 			pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
-			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil).Times(2)
-			netlinkLibMock.EXPECT().IsLinkAdminStateUp(pfLinkMock).Return(false)
-			netlinkLibMock.EXPECT().LinkSetUp(pfLinkMock).Return(nil)
-			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlink.DevlinkDevice{
-				Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil).Times(2)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil).Times(2)
 			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(gomock.Any(), "switchdev").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "switchdev"}}}, nil)
 
 			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil).Times(2)
 			hostMock.EXPECT().Unbind("0000:d8:00.2").Return(nil)
@@ -359,13 +634,25 @@ var _ = Describe("SRIOV", func() {
 			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "test")
 			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.2", true).Return(nil)
 			hostMock.EXPECT().BindDefaultDriver("0000:d8:00.2").Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 0, 0, 0).Return(nil)
 			hostMock.EXPECT().SetNetdevMTU("0000:d8:00.2", 2000).Return(nil)
+			hostMock.EXPECT().ConfigureVfQoS("enp216s0f0np0", 0, int32(-1), int32(0)).Return(nil)
 			hostMock.EXPECT().GetInterfaceIndex("0000:d8:00.2").Return(42, nil).AnyTimes()
 			vf0LinkMock := netlinkMockPkg.NewMockLink(testCtrl)
 			vf0Mac, _ := net.ParseMAC("02:42:19:51:2f:af")
 			vf0LinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0_0", HardwareAddr: vf0Mac})
 			netlinkLibMock.EXPECT().LinkByIndex(42).Return(vf0LinkMock, nil).AnyTimes()
 			netlinkLibMock.EXPECT().LinkSetVfHardwareAddr(vf0LinkMock, 0, vf0Mac).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vf0LinkMock, vf0Mac).Return(nil)
+			sriovnetLibMock.EXPECT().GetVfRepresentor("enp216s0f0np0", 0).Return("enp216s0f0np0_0", nil)
+			repLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			repLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0np0_0", MTU: 1500})
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0_0").Return(repLinkMock, nil)
+			netlinkLibMock.EXPECT().LinkSetMTU(repLinkMock, 2000).Return(nil)
+			netlinkLibMock.EXPECT().IsLinkAdminStateUp(repLinkMock).Return(false)
+			netlinkLibMock.EXPECT().LinkSetUp(repLinkMock).Return(nil)
 			hostMock.EXPECT().GetPhysPortName("enp216s0f0np0").Return("p0", nil)
 			hostMock.EXPECT().GetPhysSwitchID("enp216s0f0np0").Return("7cfe90ff2cc0", nil)
 			hostMock.EXPECT().AddVfRepresentorUdevRule("0000:d8:00.0", "enp216s0f0np0", "7cfe90ff2cc0", "p0").Return(nil)
@@ -396,6 +683,93 @@ var _ = Describe("SRIOV", func() {
 			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "1")
 		})
 
+		It("should delete the VDPA device of the VFs which are not in any VF group", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+
+			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:d8:00.0").Return(2)
+			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
+			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("mlx5_core", nil)
+			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().AddDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().AddPersistPFNameUdevRule("0000:d8:00.0", "enp216s0f0np0").Return(nil)
+			hostMock.EXPECT().EnableHwTcOffload("enp216s0f0np0").Return(nil)
+			hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode").Return("", syscall.EINVAL)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil).Times(2)
+			pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil).Times(2)
+			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(gomock.Any(), "switchdev").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "switchdev"}}}, nil)
+
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil).Times(2)
+			hostMock.EXPECT().Unbind("0000:d8:00.2").Return(nil)
+			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(false, "")
+			hostMock.EXPECT().BindDefaultDriver("0000:d8:00.2").Return(nil)
+			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "test")
+			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.2", true).Return(nil)
+			hostMock.EXPECT().BindDefaultDriver("0000:d8:00.2").Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 0, 0, 0).Return(nil)
+			hostMock.EXPECT().SetNetdevMTU("0000:d8:00.2", 2000).Return(nil)
+			hostMock.EXPECT().ConfigureVfQoS("enp216s0f0np0", 0, int32(-1), int32(0)).Return(nil)
+			hostMock.EXPECT().GetInterfaceIndex("0000:d8:00.2").Return(42, nil).AnyTimes()
+			vf0LinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			vf0Mac, _ := net.ParseMAC("02:42:19:51:2f:af")
+			vf0LinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0_0", HardwareAddr: vf0Mac})
+			netlinkLibMock.EXPECT().LinkByIndex(42).Return(vf0LinkMock, nil).AnyTimes()
+			netlinkLibMock.EXPECT().LinkSetVfHardwareAddr(vf0LinkMock, 0, vf0Mac).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vf0LinkMock, vf0Mac).Return(nil)
+			sriovnetLibMock.EXPECT().GetVfRepresentor("enp216s0f0np0", 0).Return("enp216s0f0np0_0", nil)
+			repLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			repLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0np0_0", MTU: 1500})
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0_0").Return(repLinkMock, nil)
+			netlinkLibMock.EXPECT().LinkSetMTU(repLinkMock, 2000).Return(nil)
+			netlinkLibMock.EXPECT().IsLinkAdminStateUp(repLinkMock).Return(false)
+			netlinkLibMock.EXPECT().LinkSetUp(repLinkMock).Return(nil)
+			hostMock.EXPECT().GetPhysPortName("enp216s0f0np0").Return("p0", nil)
+			hostMock.EXPECT().GetPhysSwitchID("enp216s0f0np0").Return("7cfe90ff2cc0", nil)
+			hostMock.EXPECT().AddVfRepresentorUdevRule("0000:d8:00.0", "enp216s0f0np0", "7cfe90ff2cc0", "p0").Return(nil)
+			hostMock.EXPECT().CreateVDPADevice("0000:d8:00.2", "vhost_vdpa")
+			// VF 1 is not in any VF group, the VDPA device created for it by a previous policy is deleted
+			hostMock.EXPECT().Unbind("0000:d8:00.3").Return(nil)
+			hostMock.EXPECT().HasDriver("0000:d8:00.3").Return(true, "mlx5_core")
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.3").Return(1, nil)
+			hostMock.EXPECT().DeleteVDPADevice("0000:d8:00.3").Return(nil)
+			hostMock.EXPECT().LoadUdevRules().Return(nil)
+
+			storeManagerMode.EXPECT().SaveLastPfAppliedStatus(gomock.Any()).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{{
+					Name:        "enp216s0f0np0",
+					PciAddress:  "0000:d8:00.0",
+					NumVfs:      2,
+					LinkType:    "ETH",
+					EswitchMode: "switchdev",
+					VfGroups: []sriovnetworkv1.VfGroup{
+						{
+							VfRange:      "0-0",
+							ResourceName: "test-resource0",
+							PolicyName:   "test-policy0",
+							Mtu:          2000,
+							IsRdma:       true,
+							VdpaType:     "vhost_vdpa",
+						}},
+				}},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}},
+				false)).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "2")
+		})
+
 		It("should configure switchdev on ice driver", func() {
 			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
 				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
@@ -414,12 +788,13 @@ var _ = Describe("SRIOV", func() {
 			hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode").Return("", syscall.EINVAL)
 			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil).Times(1)
 			pfLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
-			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil).Times(2)
-			netlinkLibMock.EXPECT().IsLinkAdminStateUp(pfLinkMock).Return(false)
-			netlinkLibMock.EXPECT().LinkSetUp(pfLinkMock).Return(nil)
-			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlink.DevlinkDevice{
-				Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil).Times(2)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}}, nil).Times(2)
 			netlinkLibMock.EXPECT().DevLinkSetEswitchMode(gomock.Any(), "switchdev").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(&netlinkPkg.DevlinkDevice{
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "switchdev"}}}, nil)
 
 			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil).Times(2)
 			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(false, "")
@@ -427,13 +802,25 @@ var _ = Describe("SRIOV", func() {
 			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "test")
 			hostMock.EXPECT().UnbindDriverIfNeeded("0000:d8:00.2", true).Return(nil)
 			hostMock.EXPECT().BindDefaultDriver("0000:d8:00.2").Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 0, 0, 0).Return(nil)
 			hostMock.EXPECT().SetNetdevMTU("0000:d8:00.2", 2000).Return(nil)
+			hostMock.EXPECT().ConfigureVfQoS("enp216s0f0np0", 0, int32(-1), int32(0)).Return(nil)
 			hostMock.EXPECT().GetInterfaceIndex("0000:d8:00.2").Return(42, nil).AnyTimes()
 			vf0LinkMock := netlinkMockPkg.NewMockLink(testCtrl)
 			vf0Mac, _ := net.ParseMAC("02:42:19:51:2f:af")
 			vf0LinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0_0", HardwareAddr: vf0Mac})
 			netlinkLibMock.EXPECT().LinkByIndex(42).Return(vf0LinkMock, nil).AnyTimes()
 			netlinkLibMock.EXPECT().LinkSetVfHardwareAddr(vf0LinkMock, 0, vf0Mac).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vf0LinkMock, vf0Mac).Return(nil)
+			sriovnetLibMock.EXPECT().GetVfRepresentor("enp216s0f0np0", 0).Return("enp216s0f0np0_0", nil)
+			repLinkMock := netlinkMockPkg.NewMockLink(testCtrl)
+			repLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0np0_0", MTU: 1500})
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0_0").Return(repLinkMock, nil)
+			netlinkLibMock.EXPECT().LinkSetMTU(repLinkMock, 2000).Return(nil)
+			netlinkLibMock.EXPECT().IsLinkAdminStateUp(repLinkMock).Return(false)
+			netlinkLibMock.EXPECT().LinkSetUp(repLinkMock).Return(nil)
 			hostMock.EXPECT().GetPhysPortName("enp216s0f0np0").Return("p0", nil)
 			hostMock.EXPECT().GetPhysSwitchID("enp216s0f0np0").Return("7cfe90ff2cc0", nil)
 			hostMock.EXPECT().AddVfRepresentorUdevRule("0000:d8:00.0", "enp216s0f0np0", "7cfe90ff2cc0", "p0").Return(nil)
@@ -486,10 +873,69 @@ var _ = Describe("SRIOV", func() {
 				false)).To(HaveOccurred())
 		})
 
+		It("should not configure the PF on another NUMA node", func() {
+			hostMock.EXPECT().GetDeviceNumaNode("0000:d8:00.0").Return(1, nil)
+			hostMock.EXPECT().GetDeviceNumaNode("0000:d8:00.1").Return(-1, nil)
+
+			err := s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{
+					{Name: "enp216s0f0np0", PciAddress: "0000:d8:00.0", NumVfs: 1, NumaNode: pointer.Int(0)},
+					{Name: "enp216s0f0np1", PciAddress: "0000:d8:00.1", NumVfs: 1, NumaNode: pointer.Int(0)},
+				},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}, {PciAddress: "0000:d8:00.1"}},
+				false)
+			syncErrs := types.GetInterfaceSyncErrors(err)
+			Expect(syncErrs).To(HaveLen(2))
+			Expect(syncErrs[0].Err).To(MatchError("NUMA node 0 was requested for PF 0000:d8:00.0 which is on NUMA node 1"))
+			Expect(syncErrs[1].Err).To(MatchError("NUMA node 0 was requested for PF 0000:d8:00.1 which has no NUMA affinity"))
+		})
+
+		It("should configure the next PFs if a PF fails", func() {
+			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
+			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.1").Return(0)
+
+			err := s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{
+					{Name: "enp216s0f0np0", PciAddress: "0000:d8:00.0", NumVfs: 1, ExternallyManaged: true},
+					{Name: "enp216s0f0np1", PciAddress: "0000:d8:00.1", NumVfs: 2, ExternallyManaged: true},
+				},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}, {PciAddress: "0000:d8:00.1"}},
+				false)
+			Expect(err).To(HaveOccurred())
+			syncErrs := types.GetInterfaceSyncErrors(err)
+			Expect(syncErrs).To(HaveLen(2))
+			Expect(syncErrs[0].PciAddress).To(Equal("0000:d8:00.0"))
+			Expect(syncErrs[1].PciAddress).To(Equal("0000:d8:00.1"))
+			Expect(syncErrs[1].Err).To(MatchError(ContainSubstring("number of requested virtual functions 2")))
+		})
+
+		It("should not configure the PF with overlapping VF groups", func() {
+			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.1").Return(0)
+
+			err := s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{
+					{Name: "enp216s0f0np0", PciAddress: "0000:d8:00.0", NumVfs: 4, VfGroups: []sriovnetworkv1.VfGroup{
+						{PolicyName: "test-policy0", VfRange: "0-1"},
+						{PolicyName: "test-policy1", VfRange: "1-3"},
+					}},
+					{Name: "enp216s0f0np1", PciAddress: "0000:d8:00.1", NumVfs: 2, ExternallyManaged: true},
+				},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}, {PciAddress: "0000:d8:00.1"}},
+				false)
+			Expect(err).To(HaveOccurred())
+			syncErrs := types.GetInterfaceSyncErrors(err)
+			Expect(syncErrs).To(HaveLen(2))
+			// the PF with invalid VF groups is reported after the configured PFs
+			Expect(syncErrs[0].PciAddress).To(Equal("0000:d8:00.1"))
+			Expect(syncErrs[1].PciAddress).To(Equal("0000:d8:00.0"))
+			Expect(syncErrs[1].Err).To(MatchError(ContainSubstring(
+				"VF range 1-3 of policy test-policy1 overlaps with VF range 0-1 of policy test-policy0")))
+		})
+
 		It("externally managed - wrong MTU", func() {
 			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(1)
 			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(
-				&netlink.DevlinkDevice{Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "legacy"}}},
+				&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}},
 				nil)
 
 			hostMock.EXPECT().GetNetdevMTU("0000:d8:00.0")
@@ -525,7 +971,7 @@ var _ = Describe("SRIOV", func() {
 			}, true, nil)
 			storeManagerMode.EXPECT().RemovePfAppliedStatus("0000:d8:00.0").Return(nil)
 			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(
-				&netlink.DevlinkDevice{Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "legacy"}}},
+				&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}},
 				nil)
 			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
@@ -545,6 +991,51 @@ var _ = Describe("SRIOV", func() {
 					}}, false)).NotTo(HaveOccurred())
 			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "0")
 		})
+		It("reset device - restore link admin state", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+			origInitialState := sriovnetworkv1.InitialState
+			DeferCleanup(func() { sriovnetworkv1.InitialState = origInitialState })
+			sriovnetworkv1.InitialState = sriovnetworkv1.SriovNetworkNodeState{
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{
+						PciAddress:     "0000:d8:00.0",
+						Mtu:            1500,
+						LinkAdminState: "down",
+					}},
+				},
+			}
+
+			storeManagerMode.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(&sriovnetworkv1.Interface{
+				Name:       "enp216s0f0np0",
+				PciAddress: "0000:d8:00.0",
+				NumVfs:     2,
+			}, true, nil)
+			storeManagerMode.EXPECT().RemovePfAppliedStatus("0000:d8:00.0").Return(nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(
+				&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}},
+				nil)
+			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
+			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("mlx5_core", nil)
+			hostMock.EXPECT().SetNetdevMTU("0000:d8:00.0", 1500).Return(nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "down").Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{},
+				[]sriovnetworkv1.InterfaceExt{
+					{
+						Name:       "enp216s0f0np0",
+						PciAddress: "0000:d8:00.0",
+						LinkType:   "ETH",
+						NumVfs:     2,
+						TotalVfs:   2,
+					}}, false)).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "0")
+		})
 		It("reset device - skip external", func() {
 			storeManagerMode.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(&sriovnetworkv1.Interface{
 				Name:              "enp216s0f0np0",
@@ -573,12 +1064,13 @@ var _ = Describe("SRIOV", func() {
 			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
 			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("mlx5_core", nil)
 			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(
-				&netlink.DevlinkDevice{Attrs: netlink.DevlinkDevAttrs{Eswitch: netlink.DevlinkDevEswitchAttr{Mode: "legacy"}}},
+				&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}},
 				nil)
 			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
 			hostMock.EXPECT().AddDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
 			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil)
 			hostMock.EXPECT().Unbind("0000:d8:00.2").Return(nil)
 			hostMock.EXPECT().Unbind("0000:d8:00.3").Return(nil)
@@ -611,6 +1103,269 @@ var _ = Describe("SRIOV", func() {
 				true)).NotTo(HaveOccurred())
 			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "2")
 		})
+		It("should not change PF link state if link management is disabled", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+
+			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:d8:00.0").Return(2)
+			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
+			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("mlx5_core", nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(
+				&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}},
+				nil)
+			hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().AddDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2", "0000:d8:00.3"}, nil)
+			hostMock.EXPECT().Unbind("0000:d8:00.2").Return(nil)
+			hostMock.EXPECT().Unbind("0000:d8:00.3").Return(nil)
+
+			storeManagerMode.EXPECT().SaveLastPfAppliedStatus(gomock.Any()).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{{
+					Name:                    "enp216s0f0np0",
+					PciAddress:              "0000:d8:00.0",
+					NumVfs:                  2,
+					DisablePfLinkManagement: true,
+					VfGroups: []sriovnetworkv1.VfGroup{
+						{
+							VfRange:      "0-0",
+							ResourceName: "test-resource0",
+							PolicyName:   "test-policy0",
+							Mtu:          2000,
+							IsRdma:       true,
+						},
+						{
+							VfRange:      "1-1",
+							ResourceName: "test-resource1",
+							PolicyName:   "test-policy1",
+							Mtu:          1600,
+							IsRdma:       false,
+							DeviceType:   "vfio-pci",
+						}},
+				}},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}},
+				true)).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "2")
+		})
+	})
+
+	Context("devlink parameters", func() {
+		It("should set the devlink parameters before the VFs are created", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+
+			dputilsLibMock.EXPECT().GetSriovVFcapacity("0000:d8:00.0").Return(2)
+			gomock.InOrder(
+				hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "enable_roce").Return("false", nil),
+				hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode").Return("smfs", nil),
+				hostMock.EXPECT().SetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode", "dmfs").Return(nil),
+				hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode").Return("dmfs", nil),
+				hostMock.EXPECT().RemoveDisableNMUdevRule("0000:d8:00.0").Return(nil),
+			)
+			dputilsLibMock.EXPECT().GetVFconfigured("0000:d8:00.0").Return(0)
+			dputilsLibMock.EXPECT().GetDriverName("0000:d8:00.0").Return("mlx5_core", nil)
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(
+				&netlinkPkg.DevlinkDevice{Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: "legacy"}}},
+				nil)
+			hostMock.EXPECT().RemovePersistPFNameUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().RemoveVfRepresentorUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().AddDisableNMUdevRule("0000:d8:00.0").Return(nil)
+			hostMock.EXPECT().SetNetDevLinkAdminState("enp216s0f0np0", "up").Return(nil)
+			dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{"0000:d8:00.2"}, nil)
+			hostMock.EXPECT().Unbind("0000:d8:00.2").Return(nil)
+
+			storeManagerMode.EXPECT().SaveLastPfAppliedStatus(gomock.Any()).Return(nil)
+
+			Expect(s.ConfigSriovInterfaces(storeManagerMode,
+				[]sriovnetworkv1.Interface{{
+					Name:          "enp216s0f0np0",
+					PciAddress:    "0000:d8:00.0",
+					NumVfs:        1,
+					DevlinkParams: map[string]string{"flow_steering_mode": "dmfs", "enable_roce": "false"},
+					VfGroups: []sriovnetworkv1.VfGroup{{
+						VfRange:      "0-0",
+						ResourceName: "test-resource0",
+						PolicyName:   "test-policy0",
+					}},
+				}},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:d8:00.0"}},
+				true)).NotTo(HaveOccurred())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "1")
+		})
+
+		It("should report each parameter which is not applied", func() {
+			hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "enable_roce").Return("true", nil)
+			hostMock.EXPECT().SetDevlinkDeviceParam("0000:d8:00.0", "enable_roce", "false").Return(syscall.EOPNOTSUPP)
+			hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode").Return("smfs", nil)
+			hostMock.EXPECT().SetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode", "dmfs").Return(nil)
+			hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "flow_steering_mode").Return("smfs", nil)
+			hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "max_macs").Return("", syscall.EINVAL)
+
+			err := s.(*sriov).configureDevlinkParams(&sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				DevlinkParams: map[string]string{
+					"enable_roce": "false", "flow_steering_mode": "dmfs", "max_macs": "128",
+				},
+			})
+			paramErrs := types.GetDevlinkParamErrors(err)
+			Expect(paramErrs).To(HaveLen(3))
+			Expect(paramErrs[0].Param).To(Equal("enable_roce"))
+			Expect(paramErrs[0].Err).To(MatchError(syscall.EOPNOTSUPP))
+			Expect(paramErrs[1].Param).To(Equal("flow_steering_mode"))
+			Expect(paramErrs[1].Err).To(MatchError(ContainSubstring(`the device reports value "smfs" after the update`)))
+			Expect(paramErrs[2].Param).To(Equal("max_macs"))
+			Expect(paramErrs[2].Err).To(MatchError(ContainSubstring("failed to read the current value")))
+		})
+
+		It("should accept any format of the boolean values", func() {
+			hostMock.EXPECT().GetDevlinkDeviceParam("0000:d8:00.0", "enable_roce").Return("true", nil)
+			Expect(s.(*sriov).configureDevlinkParams(&sriovnetworkv1.Interface{
+				PciAddress:    "0000:d8:00.0",
+				DevlinkParams: map[string]string{"enable_roce": "True"},
+			})).To(Succeed())
+		})
+	})
+
+	Context("waitForVFsRelease", func() {
+		var sriovImpl *sriov
+		BeforeEach(func() {
+			sriovImpl = s.(*sriov)
+			origPollInterval := vfReleasePollInterval
+			origTimeout := vars.VfReleaseTimeout
+			origWaitForVfRelease := vars.WaitForVfRelease
+			DeferCleanup(func() {
+				vfReleasePollInterval = origPollInterval
+				vars.VfReleaseTimeout = origTimeout
+				vars.WaitForVfRelease = origWaitForVfRelease
+			})
+			vars.WaitForVfRelease = true
+			vfReleasePollInterval = time.Millisecond
+			vars.VfReleaseTimeout = 50 * time.Millisecond
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.0", "/sys/bus/pci/devices/0000:d8:00.1"},
+				Symlinks: map[string]string{
+					"/sys/bus/pci/devices/0000:d8:00.0/virtfn0": "../0000:d8:00.2",
+					"/sys/bus/pci/devices/0000:d8:00.0/virtfn1": "../0000:d8:00.3"},
+			})
+		})
+		It("should not check PF without VFs", func() {
+			Expect(sriovImpl.waitForVFsRelease("0000:d8:00.1")).To(Succeed())
+		})
+		It("should continue if no VF is allocated", func() {
+			podResourcesMock.EXPECT().GetAllocatedDevices().Return(map[string]string{"0000:d9:00.2": "default/test"}, nil)
+			Expect(sriovImpl.waitForVFsRelease("0000:d8:00.0")).To(Succeed())
+		})
+		It("should wait until the VFs are released", func() {
+			gomock.InOrder(
+				podResourcesMock.EXPECT().GetAllocatedDevices().Return(map[string]string{"0000:d8:00.3": "default/test"}, nil).Times(2),
+				podResourcesMock.EXPECT().GetAllocatedDevices().Return(map[string]string{}, nil),
+			)
+			Expect(sriovImpl.waitForVFsRelease("0000:d8:00.0")).To(Succeed())
+		})
+		It("should report the pods which don't release the VFs", func() {
+			podResourcesMock.EXPECT().GetAllocatedDevices().Return(map[string]string{
+				"0000:d8:00.2": "default/test1", "0000:d8:00.3": "default/test2"}, nil).MinTimes(1)
+			Expect(sriovImpl.waitForVFsRelease("0000:d8:00.0")).To(MatchError(
+				"VFs of device 0000:d8:00.0 are still allocated to pods after 50ms: " +
+					"default/test1 (VF 0000:d8:00.2), default/test2 (VF 0000:d8:00.3)"))
+		})
+		It("should continue if the kubelet is not available", func() {
+			podResourcesMock.EXPECT().GetAllocatedDevices().Return(nil,
+				fmt.Errorf("%w: connection refused", podresourcesPkg.ErrKubeletUnavailable))
+			Expect(sriovImpl.waitForVFsRelease("0000:d8:00.0")).To(Succeed())
+		})
+		It("should fail if the allocated devices can't be listed", func() {
+			podResourcesMock.EXPECT().GetAllocatedDevices().Return(nil, testError)
+			Expect(sriovImpl.waitForVFsRelease("0000:d8:00.0")).To(MatchError(testError))
+		})
+		It("should not wait if the wait is not enabled", func() {
+			vars.WaitForVfRelease = false
+			Expect(sriovImpl.waitForVFsRelease("0000:d8:00.0")).To(Succeed())
+		})
+	})
+
+	Context("skipSriovConfig", func() {
+		var (
+			iface       *sriovnetworkv1.Interface
+			ifaceStatus *sriovnetworkv1.InterfaceExt
+		)
+		BeforeEach(func() {
+			iface = &sriovnetworkv1.Interface{
+				PciAddress: "0000:d8:00.0",
+				NumVfs:     1,
+				VfGroups: []sriovnetworkv1.VfGroup{{
+					VfRange:    "0-0",
+					PolicyName: "test-policy0",
+					DeviceType: "netdevice",
+					DSCP:       pointer.Int32(46),
+				}},
+			}
+			ifaceStatus = &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:d8:00.0",
+				NumVfs:     1,
+				VFs:        []sriovnetworkv1.VirtualFunction{{VfID: 0, Driver: "mlx5_core"}},
+			}
+		})
+		It("should skip device with applied QoS configuration", func() {
+			storeManagerMode.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(iface.DeepCopy(), true, nil)
+			storeManagerMode.EXPECT().SaveLastPfAppliedStatus(iface).Return(nil)
+			Expect(skipSriovConfig(iface, ifaceStatus, storeManagerMode)).To(BeTrue())
+		})
+		It("should not skip device if QoS configuration changed", func() {
+			lastApplied := iface.DeepCopy()
+			lastApplied.VfGroups[0].DSCP = pointer.Int32(10)
+			storeManagerMode.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(lastApplied, true, nil)
+			Expect(skipSriovConfig(iface, ifaceStatus, storeManagerMode)).To(BeFalse())
+		})
+		It("should not skip device if QoS configuration was never applied", func() {
+			storeManagerMode.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(nil, false, nil)
+			Expect(skipSriovConfig(iface, ifaceStatus, storeManagerMode)).To(BeFalse())
+		})
+	})
+
+	Context("forEachPFInParallel", func() {
+		It("should limit number of PFs configured at the same time", func() {
+			origWorkers := vars.ParallelNicConfigWorkers
+			DeferCleanup(func() { vars.ParallelNicConfigWorkers = origWorkers })
+			vars.ParallelNicConfigWorkers = 2
+
+			var running, maxRunning atomic.Int32
+			pfs := []string{"0000:d8:00.0", "0000:d8:00.1", "0000:d9:00.0", "0000:d9:00.1", "0000:da:00.0"}
+			Expect(forEachPFInParallel(pfs, func(i int) error {
+				cur := running.Add(1)
+				defer running.Add(-1)
+				for {
+					prev := maxRunning.Load()
+					if cur <= prev || maxRunning.CompareAndSwap(prev, cur) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})).NotTo(HaveOccurred())
+			Expect(maxRunning.Load()).To(BeNumerically("<=", 2))
+		})
+		It("should aggregate errors of all PFs", func() {
+			pfs := []string{"0000:d8:00.0", "0000:d8:00.1", "0000:d9:00.0"}
+			err := forEachPFInParallel(pfs, func(i int) error {
+				if i == 1 {
+					return nil
+				}
+				return fmt.Errorf("test error")
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("0000:d8:00.0: test error"))
+			Expect(err.Error()).To(ContainSubstring("0000:d9:00.0: test error"))
+			Expect(err.Error()).NotTo(ContainSubstring("0000:d8:00.1"))
+			Expect(types.GetInterfaceSyncErrors(fmt.Errorf("wrapped: %w", err))).To(HaveLen(2))
+		})
 	})
 
 	Context("VfIsReady", func() {
@@ -626,6 +1381,263 @@ var _ = Describe("SRIOV", func() {
 			Expect(vfLink.Attrs().HardwareAddr).To(Equal(vf0Mac))
 		})
 	})
+
+	Context("eSwitch encapsulation mode", func() {
+		devlinkDev := func(mode, encapMode string) *netlinkPkg.DevlinkDevice {
+			return &netlinkPkg.DevlinkDevice{BusName: "pci", DeviceName: "0000:d8:00.0",
+				Attrs: netlinkPkg.DevlinkDevAttrs{Eswitch: netlinkPkg.DevlinkDevEswitchAttr{Mode: mode, EncapMode: encapMode}}}
+		}
+
+		It("should report the encapsulation mode of the eSwitch", func() {
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(devlinkDev("switchdev", "enable"), nil)
+			mode, encapMode := s.(*sriov).getNicEswitchModes("0000:d8:00.0")
+			Expect(mode).To(Equal("switchdev"))
+			Expect(encapMode).To(Equal("basic"))
+		})
+
+		It("should not report the encapsulation mode if devlink doesn't support it", func() {
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(nil, syscall.ENODEV)
+			mode, encapMode := s.(*sriov).getNicEswitchModes("0000:d8:00.0")
+			Expect(mode).To(Equal("legacy"))
+			Expect(encapMode).To(BeEmpty())
+		})
+
+		It("should set the encapsulation mode", func() {
+			dev := devlinkDev("legacy", "enable")
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(dev, nil).Times(2)
+			netlinkLibMock.EXPECT().DevLinkSetEswitchEncapMode(dev, "none").Return(nil)
+			Expect(s.(*sriov).configureEswitchEncapMode("0000:d8:00.0", "switchdev", "none")).To(Succeed())
+		})
+
+		It("should not set the encapsulation mode if the device already uses it", func() {
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(devlinkDev("legacy", "disable"), nil)
+			Expect(s.(*sriov).configureEswitchEncapMode("0000:d8:00.0", "switchdev", "none")).To(Succeed())
+		})
+
+		It("should not set the encapsulation mode if it is not requested", func() {
+			Expect(s.(*sriov).configureEswitchEncapMode("0000:d8:00.0", "switchdev", "")).To(Succeed())
+			Expect(s.(*sriov).configureEswitchEncapMode("0000:d8:00.0", "legacy", "basic")).To(Succeed())
+		})
+
+		It("should report the devices which don't support the encapsulation mode", func() {
+			dev := devlinkDev("legacy", "")
+			netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(dev, nil).Times(2)
+			netlinkLibMock.EXPECT().DevLinkSetEswitchEncapMode(dev, "basic").Return(syscall.EOPNOTSUPP)
+			Expect(s.(*sriov).configureEswitchEncapMode("0000:d8:00.0", "switchdev", "basic")).To(
+				MatchError(types.ErrEswitchModeNotSupported))
+		})
+
+		It("should set the encapsulation mode before the switchdev mode", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs": {}},
+			})
+			legacyDev := devlinkDev("legacy", "enable")
+			gomock.InOrder(
+				netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(legacyDev, nil),
+				dputilsLibMock.EXPECT().GetVFList("0000:d8:00.0").Return([]string{}, nil),
+				netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(legacyDev, nil).Times(2),
+				netlinkLibMock.EXPECT().DevLinkSetEswitchEncapMode(legacyDev, "none").Return(nil),
+				netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(legacyDev, nil),
+				netlinkLibMock.EXPECT().DevLinkSetEswitchMode(legacyDev, "switchdev").Return(nil),
+				netlinkLibMock.EXPECT().DevLinkGetDeviceByName("pci", "0000:d8:00.0").Return(devlinkDev("switchdev", "disable"), nil),
+			)
+			Expect(s.(*sriov).setEswitchModeAndNumVFsMlx("0000:d8:00.0", "switchdev", "none", 1)).To(Succeed())
+			helpers.GinkgoAssertFileContentsEquals("/sys/bus/pci/devices/0000:d8:00.0/sriov_numvfs", "1")
+		})
+	})
+
+	Context("SetVfAdminMac", func() {
+		var (
+			pfLinkMock *netlinkMockPkg.MockLink
+			vfLinkMock *netlinkMockPkg.MockLink
+			oldLink    *netlinkMockPkg.MockLink
+			vfMac      net.HardwareAddr
+		)
+
+		BeforeEach(func() {
+			pfLinkMock = netlinkMockPkg.NewMockLink(testCtrl)
+			vfLinkMock = netlinkMockPkg.NewMockLink(testCtrl)
+			vfMac, _ = net.ParseMAC("02:42:19:51:2f:af")
+			vfLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0v0", HardwareAddr: vfMac}).AnyTimes()
+			// the netdevice still uses the random MAC set when the VF was created
+			oldLink = netlinkMockPkg.NewMockLink(testCtrl)
+			oldMac, _ := net.ParseMAC("b2:1f:8e:00:11:22")
+			oldLink.EXPECT().Attrs().Return(&netlink.LinkAttrs{Name: "enp216s0f0v0", HardwareAddr: oldMac}).AnyTimes()
+		})
+
+		expectAdminMac := func() {
+			dputilsLibMock.EXPECT().GetVFID("0000:d8:00.2").Return(0, nil)
+			netlinkLibMock.EXPECT().LinkSetVfHardwareAddr(pfLinkMock, 0, vfMac).Return(nil)
+		}
+
+		It("should set the administrative MAC and the MAC of the VF netdevice", func() {
+			expectAdminMac()
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vfLinkMock, vfMac).Return(nil)
+			Expect(s.SetVfAdminMac("0000:d8:00.2", pfLinkMock, vfLinkMock)).To(Succeed())
+		})
+
+		It("should skip the VFs without netdevice", func() {
+			Expect(s.(*sriov).setVfEffectiveMac("0000:d8:00.2", nil, vfMac)).To(Succeed())
+		})
+
+		It("should ignore the error if the VF netdevice already uses the MAC", func() {
+			expectAdminMac()
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vfLinkMock, vfMac).Return(syscall.EPERM)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0v0").Return(vfLinkMock, nil)
+			Expect(s.SetVfAdminMac("0000:d8:00.2", pfLinkMock, vfLinkMock)).To(Succeed())
+		})
+
+		It("should rebind the VF if the driver applies the administrative MAC when it is loaded", func() {
+			expectAdminMac()
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vfLinkMock, vfMac).Return(syscall.EPERM)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0v0").Return(oldLink, nil)
+			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "mlx5_core")
+			hostMock.EXPECT().RebindVfToDefaultDriver("0000:d8:00.2").Return(nil)
+			hostMock.EXPECT().GetInterfaceIndex("0000:d8:00.2").Return(42, nil)
+			netlinkLibMock.EXPECT().LinkByIndex(42).Return(vfLinkMock, nil)
+			Expect(s.SetVfAdminMac("0000:d8:00.2", pfLinkMock, vfLinkMock)).To(Succeed())
+		})
+
+		It("should fail if the netdevice doesn't use the administrative MAC after the rebind", func() {
+			expectAdminMac()
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vfLinkMock, vfMac).Return(syscall.EPERM)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0v0").Return(oldLink, nil)
+			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "ixgbevf")
+			hostMock.EXPECT().RebindVfToDefaultDriver("0000:d8:00.2").Return(nil)
+			hostMock.EXPECT().GetInterfaceIndex("0000:d8:00.2").Return(42, nil)
+			netlinkLibMock.EXPECT().LinkByIndex(42).Return(oldLink, nil)
+			Expect(s.SetVfAdminMac("0000:d8:00.2", pfLinkMock, vfLinkMock)).To(
+				MatchError(ContainSubstring("uses MAC b2:1f:8e:00:11:22 instead of the administrative MAC 02:42:19:51:2f:af")))
+		})
+
+		It("should fail if the driver doesn't allow to change the MAC of the VF netdevice", func() {
+			expectAdminMac()
+			netlinkLibMock.EXPECT().LinkSetHardwareAddr(vfLinkMock, vfMac).Return(syscall.EPERM)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0v0").Return(oldLink, nil)
+			hostMock.EXPECT().HasDriver("0000:d8:00.2").Return(true, "iavf")
+			Expect(s.SetVfAdminMac("0000:d8:00.2", pfLinkMock, vfLinkMock)).To(
+				MatchError(ContainSubstring("failed to set MAC 02:42:19:51:2f:af on netdevice enp216s0f0v0 of VF 0000:d8:00.2")))
+		})
+	})
+
+	Context("setSiblingPFs", func() {
+		It("should record the other Mellanox PFs of the same device", func() {
+			pfList := []sriovnetworkv1.InterfaceExt{
+				{PciAddress: "0000:3b:00.0", Vendor: "15b3"},
+				{PciAddress: "0000:3b:00.1", Vendor: "15b3"},
+				{PciAddress: "0000:d8:00.0", Vendor: "15b3"},
+				{PciAddress: "0000:86:00.0", Vendor: "8086"},
+				{PciAddress: "0000:86:00.1", Vendor: "8086"},
+			}
+			setSiblingPFs(pfList)
+			Expect(pfList[0].SiblingPFs).To(Equal([]string{"0000:3b:00.1"}))
+			Expect(pfList[1].SiblingPFs).To(Equal([]string{"0000:3b:00.0"}))
+			Expect(pfList[2].SiblingPFs).To(BeEmpty())
+			Expect(pfList[3].SiblingPFs).To(BeEmpty())
+			Expect(pfList[4].SiblingPFs).To(BeEmpty())
+		})
+	})
+
+	Context("configSriovVFTrustAndSpoofChk", func() {
+		var (
+			sriovImpl  *sriov
+			pfLinkMock *netlinkMockPkg.MockLink
+		)
+		BeforeEach(func() {
+			sriovImpl = s.(*sriov)
+			pfLinkMock = netlinkMockPkg.NewMockLink(testCtrl)
+		})
+		It("should apply the settings of the VF group", func() {
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, false).Return(nil)
+			Expect(sriovImpl.configSriovVFTrustAndSpoofChk(pfLinkMock, 0,
+				&sriovnetworkv1.VfGroup{Trust: "on", SpoofChk: "off"})).To(Succeed())
+		})
+		It("should reset the settings removed from the VF group to the defaults", func() {
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(nil)
+			Expect(sriovImpl.configSriovVFTrustAndSpoofChk(pfLinkMock, 0, &sriovnetworkv1.VfGroup{})).To(Succeed())
+		})
+		It("should ignore the drivers which don't support the reset of the settings", func() {
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, false).Return(syscall.EOPNOTSUPP)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(syscall.EOPNOTSUPP)
+			Expect(sriovImpl.configSriovVFTrustAndSpoofChk(pfLinkMock, 0, &sriovnetworkv1.VfGroup{})).To(Succeed())
+		})
+		It("should fail if the requested setting can't be applied", func() {
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, true).Return(syscall.EOPNOTSUPP)
+			Expect(sriovImpl.configSriovVFTrustAndSpoofChk(pfLinkMock, 0,
+				&sriovnetworkv1.VfGroup{Trust: "on"})).To(MatchError(syscall.EOPNOTSUPP))
+		})
+	})
+
+	Context("ReconcileVfAttributes", func() {
+		var pfLinkMock *netlinkMockPkg.MockLink
+		BeforeEach(func() {
+			pfLinkMock = netlinkMockPkg.NewMockLink(testCtrl)
+			netlinkLibMock.EXPECT().LinkByName("enp216s0f0np0").Return(pfLinkMock, nil)
+		})
+		It("should reapply only the attributes requested by the VF group", func() {
+			pfLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Vfs: []netlink.VfInfo{
+				// trust and tx rate reset by the driver
+				{ID: 0, Trust: 0, Spoofchk: true, MinTxRate: 0, MaxTxRate: 0, Vlan: 100},
+				// VLAN of the DPDK VF reset
+				{ID: 1, Trust: 0, Spoofchk: false, VlanProto: 0x0081},
+				// VF without group
+				{ID: 2, Trust: 0, Spoofchk: true},
+			}}).AnyTimes()
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, true).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfRate(pfLinkMock, 0, 0, 1000).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfVlanQosProto(pfLinkMock, 1, 10, 2, int(netlink.VLAN_PROTOCOL_8021Q)).Return(nil)
+			corrections, err := s.ReconcileVfAttributes(&sriovnetworkv1.Interface{
+				Name: "enp216s0f0np0", PciAddress: "0000:d8:00.0", NumVfs: 3,
+				VfGroups: []sriovnetworkv1.VfGroup{
+					// the VLAN of the kernel VFs is set by the CNI
+					{VfRange: "0-0", DeviceType: "netdevice", Trust: "on", SpoofChk: "on", MaxTxRate: 1000, Vlan: 20},
+					{VfRange: "1-1", DeviceType: "vfio-pci", SpoofChk: "off", Vlan: 10, VlanQoS: 2},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(corrections).To(Equal([]types.VfAttributeCorrection{
+				{VfID: 0, Attribute: types.VfAttributeTrust},
+				{VfID: 0, Attribute: types.VfAttributeTxRate},
+				{VfID: 1, Attribute: types.VfAttributeVlan},
+			}))
+		})
+		It("should reapply the attributes of the other VFs if a VF fails", func() {
+			pfLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Vfs: []netlink.VfInfo{
+				{ID: 0, Spoofchk: false},
+				{ID: 1, Spoofchk: false},
+			}}).AnyTimes()
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(testError)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 1, true).Return(nil)
+			corrections, err := s.ReconcileVfAttributes(&sriovnetworkv1.Interface{
+				Name: "enp216s0f0np0", PciAddress: "0000:d8:00.0", NumVfs: 2,
+				VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "netdevice", SpoofChk: "on"}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("failed to reapply spoofChk of VF 0 of 0000:d8:00.0")))
+			Expect(corrections).To(Equal([]types.VfAttributeCorrection{{VfID: 1, Attribute: types.VfAttributeSpoofChk}}))
+		})
+		It("should reset the trust and the spoof check of the VFs to the defaults if they are not set by the VF group", func() {
+			pfLinkMock.EXPECT().Attrs().Return(&netlink.LinkAttrs{Vfs: []netlink.VfInfo{
+				// trust and spoof check set by a previous policy
+				{ID: 0, Trust: 1, Spoofchk: false},
+				// already in the defaults
+				{ID: 1, Trust: 0, Spoofchk: true},
+			}}).AnyTimes()
+			netlinkLibMock.EXPECT().LinkSetVfTrust(pfLinkMock, 0, false).Return(nil)
+			netlinkLibMock.EXPECT().LinkSetVfSpoofchk(pfLinkMock, 0, true).Return(nil)
+			corrections, err := s.ReconcileVfAttributes(&sriovnetworkv1.Interface{
+				Name: "enp216s0f0np0", PciAddress: "0000:d8:00.0", NumVfs: 2,
+				VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-1", DeviceType: "netdevice"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(corrections).To(Equal([]types.VfAttributeCorrection{
+				{VfID: 0, Attribute: types.VfAttributeTrust},
+				{VfID: 0, Attribute: types.VfAttributeSpoofChk},
+			}))
+		})
+	})
 })
 
 func getTestPCIDevices() []*ghw.PCIDevice {
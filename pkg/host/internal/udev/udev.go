@@ -10,6 +10,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/fileutil"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
@@ -58,7 +59,7 @@ func (u *udev) PrepareVFRepUdevRule() error {
 		log.Log.Error(err, "PrepareVFRepUdevRule(): failed to read source for representor name UDEV script")
 		return err
 	}
-	if err := os.WriteFile(targetPath, data, 0755); err != nil {
+	if err := fileutil.WriteFileAtomic(targetPath, data, 0755); err != nil {
 		log.Log.Error(err, "PrepareVFRepUdevRule(): failed to write representor name UDEV script")
 		return err
 	}
@@ -135,7 +136,7 @@ func (u *udev) addUdevRule(pfPciAddress, ruleName, ruleContent string) error {
 		return err
 	}
 	filePath := u.getRulePathForPF(ruleName, pfPciAddress)
-	if err := os.WriteFile(filePath, []byte(ruleContent), 0666); err != nil {
+	if err := fileutil.WriteFileAtomic(filePath, []byte(ruleContent), 0666); err != nil {
 		log.Log.Error(err, "addUdevRule(): fail to write file", "path", filePath)
 		return err
 	}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"syscall"
 
-	"github.com/vishvananda/netlink"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
@@ -46,13 +45,13 @@ func (v *vdpa) CreateVDPADevice(pciAddr, vdpaType string) error {
 		}
 		// first try to create VDPA device with MaxVQP parameter set to 32 to exactly match HW offloading use-case with the
 		// old swtichdev implementation. Create device without MaxVQP parameter if it is not supported.
-		if err := v.netlinkLib.VDPANewDev(expectedVDPAName, constants.BusPci, pciAddr, netlink.VDPANewDevParams{MaxVQP: 32}); err != nil {
+		if err := v.netlinkLib.VDPANewDev(expectedVDPAName, constants.BusPci, pciAddr, netlinkLibPkg.VDPANewDevParams{MaxVQP: 32}); err != nil {
 			if !errors.Is(err, syscall.ENOTSUP) {
 				funcLog.Error(err, "CreateVDPADevice(): fail to create VDPA device with MaxVQP parameter")
 				return err
 			}
 			funcLog.V(2).Info("failed to create VDPA device with MaxVQP parameter, try without it")
-			if err := v.netlinkLib.VDPANewDev(expectedVDPAName, constants.BusPci, pciAddr, netlink.VDPANewDevParams{}); err != nil {
+			if err := v.netlinkLib.VDPANewDev(expectedVDPAName, constants.BusPci, pciAddr, netlinkLibPkg.VDPANewDevParams{}); err != nil {
 				funcLog.Error(err, "CreateVDPADevice(): fail to create VDPA device without MaxVQP parameter")
 				return err
 			}
@@ -124,6 +123,13 @@ func (v *vdpa) DiscoverVDPAType(pciAddr string) string {
 	return vdpaType
 }
 
+// GetVDPADeviceName returns the name of the VDPA device for the VF,
+// the name is generated deterministically from the PCI address of the VF
+// pciAddr - PCI address of the VF
+func (v *vdpa) GetVDPADeviceName(pciAddr string) string {
+	return generateVDPADevName(pciAddr)
+}
+
 // generates predictable name for VDPA device, example: vpda:0000:03:00.1
 func generateVDPADevName(pciAddr string) string {
 	return "vdpa:" + pciAddr
@@ -5,13 +5,13 @@ import (
 	"syscall"
 
 	"github.com/golang/mock/gomock"
-	"github.com/vishvananda/netlink"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	netlinkPkg "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
 	netlinkMock "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink/mock"
 	hostMock "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/mock"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
@@ -41,19 +41,19 @@ var _ = Describe("VDPA", func() {
 		}
 		It("Created", func() {
 			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(nil, syscall.ENODEV)
-			libMock.EXPECT().VDPANewDev("vdpa:0000:d8:00.2", "pci", "0000:d8:00.2", netlink.VDPANewDevParams{MaxVQP: 32}).Return(nil)
+			libMock.EXPECT().VDPANewDev("vdpa:0000:d8:00.2", "pci", "0000:d8:00.2", netlinkPkg.VDPANewDevParams{MaxVQP: 32}).Return(nil)
 			kernelMock.EXPECT().BindDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2", "vhost_vdpa").Return(nil)
 			Expect(callFunc()).NotTo(HaveOccurred())
 		})
 		It("Created without MaxVQP", func() {
 			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(nil, syscall.ENODEV)
-			libMock.EXPECT().VDPANewDev("vdpa:0000:d8:00.2", "pci", "0000:d8:00.2", netlink.VDPANewDevParams{MaxVQP: 32}).Return(syscall.ENOTSUP)
-			libMock.EXPECT().VDPANewDev("vdpa:0000:d8:00.2", "pci", "0000:d8:00.2", netlink.VDPANewDevParams{}).Return(nil)
+			libMock.EXPECT().VDPANewDev("vdpa:0000:d8:00.2", "pci", "0000:d8:00.2", netlinkPkg.VDPANewDevParams{MaxVQP: 32}).Return(syscall.ENOTSUP)
+			libMock.EXPECT().VDPANewDev("vdpa:0000:d8:00.2", "pci", "0000:d8:00.2", netlinkPkg.VDPANewDevParams{}).Return(nil)
 			kernelMock.EXPECT().BindDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2", "vhost_vdpa").Return(nil)
 			Expect(callFunc()).NotTo(HaveOccurred())
 		})
 		It("Already exist", func() {
-			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlink.VDPADev{}, nil)
+			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlinkPkg.VDPADev{}, nil)
 			kernelMock.EXPECT().BindDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2", "vhost_vdpa").Return(nil)
 			Expect(callFunc()).NotTo(HaveOccurred())
 		})
@@ -63,11 +63,11 @@ var _ = Describe("VDPA", func() {
 		})
 		It("Fail to Create device", func() {
 			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(nil, syscall.ENODEV)
-			libMock.EXPECT().VDPANewDev("vdpa:0000:d8:00.2", "pci", "0000:d8:00.2", netlink.VDPANewDevParams{MaxVQP: 32}).Return(testErr)
+			libMock.EXPECT().VDPANewDev("vdpa:0000:d8:00.2", "pci", "0000:d8:00.2", netlinkPkg.VDPANewDevParams{MaxVQP: 32}).Return(testErr)
 			Expect(callFunc()).To(MatchError(testErr))
 		})
 		It("Fail to Bind device", func() {
-			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlink.VDPADev{}, nil)
+			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlinkPkg.VDPADev{}, nil)
 			kernelMock.EXPECT().BindDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2", "vhost_vdpa").Return(testErr)
 			Expect(callFunc()).To(MatchError(testErr))
 		})
@@ -84,6 +84,10 @@ var _ = Describe("VDPA", func() {
 			libMock.EXPECT().VDPADelDev("vdpa:0000:d8:00.2").Return(syscall.ENODEV)
 			Expect(callFunc()).NotTo(HaveOccurred())
 		})
+		It("Module not loaded", func() {
+			libMock.EXPECT().VDPADelDev("vdpa:0000:d8:00.2").Return(syscall.ENOENT)
+			Expect(callFunc()).NotTo(HaveOccurred())
+		})
 		It("Fail to delete device", func() {
 			libMock.EXPECT().VDPADelDev("vdpa:0000:d8:00.2").Return(testErr)
 			Expect(callFunc()).To(MatchError(testErr))
@@ -102,29 +106,34 @@ var _ = Describe("VDPA", func() {
 			Expect(callFunc()).To(BeEmpty())
 		})
 		It("No driver", func() {
-			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlink.VDPADev{}, nil)
+			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlinkPkg.VDPADev{}, nil)
 			kernelMock.EXPECT().GetDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2").Return("", nil)
 			Expect(callFunc()).To(BeEmpty())
 		})
 		It("Unknown driver", func() {
-			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlink.VDPADev{}, nil)
+			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlinkPkg.VDPADev{}, nil)
 			kernelMock.EXPECT().GetDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2").Return("something", nil)
 			Expect(callFunc()).To(BeEmpty())
 		})
 		It("Vhost driver", func() {
-			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlink.VDPADev{}, nil)
+			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlinkPkg.VDPADev{}, nil)
 			kernelMock.EXPECT().GetDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2").Return("vhost_vdpa", nil)
 			Expect(callFunc()).To(Equal("vhost"))
 		})
 		It("Virtio driver", func() {
-			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlink.VDPADev{}, nil)
+			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlinkPkg.VDPADev{}, nil)
 			kernelMock.EXPECT().GetDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2").Return("virtio_vdpa", nil)
 			Expect(callFunc()).To(Equal("virtio"))
 		})
 		It("Fail to read driver", func() {
-			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlink.VDPADev{}, nil)
+			libMock.EXPECT().VDPAGetDevByName("vdpa:0000:d8:00.2").Return(&netlinkPkg.VDPADev{}, nil)
 			kernelMock.EXPECT().GetDriverByBusAndDevice(consts.BusVdpa, "vdpa:0000:d8:00.2").Return("", testErr)
 			Expect(callFunc()).To(BeEmpty())
 		})
 	})
+	Context("GetVDPADeviceName", func() {
+		It("Name", func() {
+			Expect(v.GetVDPADeviceName("0000:d8:00.2")).To(Equal("vdpa:0000:d8:00.2"))
+		})
+	})
 })
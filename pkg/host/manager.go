@@ -2,12 +2,15 @@ package host
 
 import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/bridge"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/distro"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/facts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/infiniband"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/kernel"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ethtool"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/ghw"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/netlink"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/podresources"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/sriovnet"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/network"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/service"
@@ -30,6 +33,8 @@ type HostManagerInterface interface {
 	types.VdpaInterface
 	types.InfinibandInterface
 	types.BridgeInterface
+	types.DistroInterface
+	types.FactsInterface
 }
 
 type hostManager struct {
@@ -42,6 +47,8 @@ type hostManager struct {
 	types.VdpaInterface
 	types.InfinibandInterface
 	types.BridgeInterface
+	types.DistroInterface
+	types.FactsInterface
 }
 
 func NewHostManager(utilsInterface utils.CmdInterface) (HostManagerInterface, error) {
@@ -50,6 +57,7 @@ func NewHostManager(utilsInterface utils.CmdInterface) (HostManagerInterface, er
 	ethtoolLib := ethtool.New()
 	sriovnetLib := sriovnet.New()
 	ghwLib := ghw.New()
+	podResourcesLib := podresources.New()
 	k := kernel.New(utilsInterface)
 	n := network.New(utilsInterface, dpUtils, netlinkLib, ethtoolLib)
 	sv := service.New(utilsInterface)
@@ -60,7 +68,9 @@ func NewHostManager(utilsInterface utils.CmdInterface) (HostManagerInterface, er
 		return nil, err
 	}
 	br := bridge.New()
-	sr := sriov.New(utilsInterface, k, n, u, v, ib, netlinkLib, dpUtils, sriovnetLib, ghwLib, br)
+	sr := sriov.New(utilsInterface, k, n, u, v, ib, netlinkLib, dpUtils, sriovnetLib, ghwLib, podResourcesLib, br)
+	d := distro.New()
+	f := facts.New()
 	return &hostManager{
 		utilsInterface,
 		k,
@@ -71,5 +81,7 @@ func NewHostManager(utilsInterface utils.CmdInterface) (HostManagerInterface, er
 		v,
 		ib,
 		br,
+		d,
+		f,
 	}, nil
 }
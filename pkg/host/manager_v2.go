@@ -0,0 +1,1003 @@
+package host
+
+import (
+	"context"
+
+	"github.com/vishvananda/netlink"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// HostManagerV2Interface contains the methods of HostManagerInterface with a context as first parameter.
+// The methods return the error of the context without doing anything if the context is done, the methods
+// which don't return an error return zero values. The commands run by the host manager created with
+// NewHostManagerV2 are killed when the context of the host manager is done.
+type HostManagerV2Interface interface {
+	// kernel modules, drivers and kernel arguments
+	// TryEnableTun load the tun kernel module
+	TryEnableTun(ctx context.Context)
+	// TryEnableVhostNet load the vhost-net kernel module
+	TryEnableVhostNet(ctx context.Context)
+	// EnsureVhostNet loads the tun and vhost_net kernel modules and checks that the device nodes exist
+	EnsureVhostNet(ctx context.Context) error
+	// CheckRDMAEnabled returns true if RDMA modules are loaded on host
+	CheckRDMAEnabled(ctx context.Context) (bool, error)
+	// GetCurrentKernelArgs reads the /proc/cmdline to check the current kernel arguments
+	GetCurrentKernelArgs(ctx context.Context) (string, error)
+	// GetKernelVersion returns the release of the running kernel, e.g. 5.14.0-427.13.1.el9_4.x86_64
+	GetKernelVersion(ctx context.Context) (string, error)
+	// IsKernelArgsSet check is the requested kernel arguments are set
+	IsKernelArgsSet(ctx context.Context, cmdLine, karg string) bool
+	// Unbind unbinds a virtual function from is current driver
+	Unbind(ctx context.Context, pciAddr string) error
+	// BindDpdkDriver binds the virtual function to a DPDK driver
+	BindDpdkDriver(ctx context.Context, pciAddr, driver string) error
+	// BindDefaultDriver binds the virtual function to is default driver
+	BindDefaultDriver(ctx context.Context, pciAddr string) error
+	// BindDriverByBusAndDevice binds device to the provided driver
+	// bus - the bus path in the sysfs, e.g. "pci" or "vdpa"
+	// device - the name of the device on the bus, e.g. 0000:85:1e.5 for PCI or vpda1 for VDPA
+	// driver - the name of the driver, e.g. vfio-pci or vhost_vdpa.
+	BindDriverByBusAndDevice(ctx context.Context, bus, device, driver string) error
+	// HasDriver returns try if the virtual function is bind to a driver
+	HasDriver(ctx context.Context, pciAddr string) (bool, string)
+	// GetDriverByBusAndDevice returns driver for the device or error.
+	// returns "", nil if the device has no driver.
+	// bus - the bus path in the sysfs, e.g. "pci" or "vdpa"
+	// device - the name of the device on the bus, e.g. 0000:85:1e.5 for PCI or vpda1 for VDPA
+	GetDriverByBusAndDevice(ctx context.Context, bus, device string) (string, error)
+	// VerifyDriverBinding returns an error if the PCI device is not bound to the expected driver
+	VerifyDriverBinding(ctx context.Context, pciAddress, expectedDriver string) error
+	// RebindVfToDefaultDriver rebinds the virtual function to is default driver
+	RebindVfToDefaultDriver(ctx context.Context, pciAddr string) error
+	// UnbindDriverByBusAndDevice unbind device identified by bus and device ID from the driver
+	// bus - the bus path in the sysfs, e.g. "pci" or "vdpa"
+	// device - the name of the device on the bus, e.g. 0000:85:1e.5 for PCI or vpda1 for VDPA
+	UnbindDriverByBusAndDevice(ctx context.Context, bus, device string) error
+	// UnbindDriverIfNeeded unbinds the virtual function from a driver if needed
+	UnbindDriverIfNeeded(ctx context.Context, pciAddr string, isRdma bool) error
+	// LoadKernelModule loads a kernel module to the host
+	LoadKernelModule(ctx context.Context, name string, args ...string) error
+	// UnloadKernelModule unloads a kernel module from the host, nothing is done if the module is not loaded
+	UnloadKernelModule(ctx context.Context, name string) error
+	// IsKernelModuleLoaded returns try if the requested kernel module is loaded
+	IsKernelModuleLoaded(ctx context.Context, name string) (bool, error)
+	// IsKernelLockdownMode returns true if the kernel is in lockdown mode
+	IsKernelLockdownMode(ctx context.Context) bool
+	// WriteModulesLoadConf writes the list of kernel modules to the modules-load.d configuration file
+	// on the host, the file is removed if the list is empty
+	WriteModulesLoadConf(ctx context.Context, path string, modules []string) error
+	// ConfigureModprobeBlacklist configures modprobe on the host to make vfio-pci claim the devices
+	// with the provided IDs (in vendor:device format) before the kernel drivers of the PFs are loaded.
+	// The configuration is removed if no device IDs are provided. Initramfs is regenerated on changes.
+	ConfigureModprobeBlacklist(ctx context.Context, vfioDeviceIDs, pfDrivers []string) error
+	// GetArchitecture returns the CPU architecture of the host, e.g. amd64 or arm64
+	GetArchitecture(ctx context.Context) string
+	// GetDeviceNumaNode returns the NUMA node of the PCI device, -1 is returned if the device has no NUMA affinity
+	GetDeviceNumaNode(ctx context.Context, pciAddr string) (int, error)
+	// ListVFInterrupts returns the numbers of the IRQs of the VF from /proc/interrupts, the VF has no IRQs
+	// if its driver didn't request them (e.g. vfio-pci VFs not used by an application)
+	ListVFInterrupts(ctx context.Context, pciAddress string) ([]int, error)
+	// SetHugepages allocates hugepages of the provided size (e.g. 1Gi) on the NUMA node, the hugepages are
+	// allocated without NUMA affinity if numaNode is negative. Allocated hugepages are never released.
+	// Returns the number of hugepages available after the allocation.
+	SetHugepages(ctx context.Context, numaNode int, size string, count int) (int, error)
+	// CreateCharDevice creates the character device node with the provided major and minor numbers
+	// on the host, an existing node with other device numbers is replaced
+	CreateCharDevice(ctx context.Context, major, minor uint32, path string) error
+	// UploadFirmware flashes the firmware file to the PCI device with devlink, the path is relative
+	// to the firmware directory of the host
+	UploadFirmware(ctx context.Context, pciAddress, fwPath string) error
+	// network
+	// TryToGetVirtualInterfaceName tries to find the virtio interface name base on pci address
+	// used for virtual environment where we pass SR-IOV virtual function into the system
+	// supported platform openstack
+	TryToGetVirtualInterfaceName(ctx context.Context, pciAddr string) string
+	// TryGetInterfaceName tries to find the SR-IOV virtual interface name base on pci address
+	TryGetInterfaceName(ctx context.Context, pciAddr string) string
+	// GetInterfaceIndex returns network interface index base on pci address or error if occurred
+	GetInterfaceIndex(ctx context.Context, pciAddr string) (int, error)
+	// GetPhysSwitchID returns the physical switch ID for a specific pci address
+	GetPhysSwitchID(ctx context.Context, name string) (string, error)
+	// GetPhysPortName returns the physical port name for a specific pci address
+	GetPhysPortName(ctx context.Context, name string) (string, error)
+	// IsSwitchdev returns true of the pci address is on switchdev mode
+	IsSwitchdev(ctx context.Context, name string) bool
+	// GetNetdevMTU returns the interface MTU for devices attached to kernel drivers
+	GetNetdevMTU(ctx context.Context, pciAddr string) int
+	// SetNetdevMTU sets the MTU for a request interface
+	SetNetdevMTU(ctx context.Context, pciAddr string, mtu int) error
+	// GetNetDevMac returns the network interface mac address
+	GetNetDevMac(ctx context.Context, name string) string
+	// GetNetDevNodeGUID returns the network interface node GUID if device is RDMA capable otherwise returns empty string
+	GetNetDevNodeGUID(ctx context.Context, pciAddr string) string
+	// GetNetDevLinkSpeed returns the network interface link speed
+	GetNetDevLinkSpeed(ctx context.Context, name string) string
+	// GetNetDevFirmwareVersion returns the firmware version of the network interface
+	GetNetDevFirmwareVersion(ctx context.Context, name string) string
+	// GetNetDevBondMaster returns the name of the bond the network interface is enslaved to,
+	// empty string if the interface has no master or if its master is not a bond
+	GetNetDevBondMaster(ctx context.Context, name string) string
+	// IsPartOfBond returns true and the name of the master if the netdevice of the PF with the PCI address
+	// is enslaved to a bond or to a team
+	IsPartOfBond(ctx context.Context, pciAddress string) (bool, string, error)
+	// GetDevlinkDeviceParam returns devlink parameter for the device as a string, if the parameter has multiple values
+	// then the function will return only first one from the list.
+	GetDevlinkDeviceParam(ctx context.Context, pciAddr, paramName string) (string, error)
+	// SetDevlinkDeviceParam set devlink parameter for the device, accepts paramName and value
+	// as a string. Automatically set CMODE for the parameter and converts the value to the right
+	// type before submitting it.
+	SetDevlinkDeviceParam(ctx context.Context, pciAddr, paramName, value string) error
+	// IsDevlinkDeviceParamPermanent returns true if the devlink parameter of the device is set in the permanent
+	// configuration mode, the value is stored by the firmware and applied after a firmware reset
+	IsDevlinkDeviceParamPermanent(ctx context.Context, pciAddr, paramName string) (bool, error)
+	// GetRDMASubsystemNetnsMode returns the network namespace mode of the RDMA subsystem, shared or exclusive
+	GetRDMASubsystemNetnsMode(ctx context.Context) (string, error)
+	// SetRDMASubsystemNetnsMode sets the network namespace mode of the RDMA subsystem and verifies that
+	// the mode was applied, the mode can't be changed while RDMA devices are used in other network namespaces
+	SetRDMASubsystemNetnsMode(ctx context.Context, mode string) error
+	// EnableHwTcOffload make sure that hw-tc-offload feature is enabled if device supports it
+	EnableHwTcOffload(ctx context.Context, ifaceName string) error
+	// ConfigureEncapOffload enables or disables the VXLAN and Geneve segmentation offload of the interface
+	ConfigureEncapOffload(ctx context.Context, ifaceName string, vxlan, geneve bool) error
+	// GetEncapOffloadState returns the VXLAN and Geneve segmentation offload state of the interface
+	GetEncapOffloadState(ctx context.Context, ifaceName string) (vxlan, geneve bool, err error)
+	// GetPfSettings returns the promiscuous mode, the all-multicast mode and the state of the whitelisted
+	// ethtool features supported by the interface
+	GetPfSettings(ctx context.Context, ifaceName string) (*sriovnetworkv1.PfSettings, error)
+	// SetPfSettings applies the settings which are set and verifies that the interface uses them
+	SetPfSettings(ctx context.Context, ifaceName string, settings *sriovnetworkv1.PfSettings) error
+	// GetNetDevLinkAdminState returns the admin state of the interface.
+	GetNetDevLinkAdminState(ctx context.Context, ifaceName string) string
+	// SetNetDevLinkAdminState sets the admin state of the interface to "up" or "down"
+	SetNetDevLinkAdminState(ctx context.Context, ifaceName string, state string) error
+	// GetPciAddressFromInterfaceName parses sysfs to get pci address of an interface by name
+	GetPciAddressFromInterfaceName(ctx context.Context, interfaceName string) (string, error)
+	// ConfigureVfQoS configures DSCP marking and egress shaping of the traffic sent by the VF netdevice,
+	// negative dscp disables DSCP marking and zero egressBandwidthMbps disables egress shaping
+	ConfigureVfQoS(ctx context.Context, pfName string, vfID int, dscp int32, egressBandwidthMbps int32) error
+	// GetVFRSSConfig returns the RSS hash key and hash fields of the VF netdevice,
+	// nil if the VF has no netdevice in the host network namespace
+	GetVFRSSConfig(ctx context.Context, pfName string, vfID int) (*types.RSSConfig, error)
+	// systemd services
+	// IsServiceExist checks if the requested systemd service exist on the system
+	IsServiceExist(ctx context.Context, servicePath string) (bool, error)
+	// IsServiceEnabled checks if the requested systemd service is enabled on the system
+	IsServiceEnabled(ctx context.Context, servicePath string) (bool, error)
+	// ReadService reads a systemd servers and return it as a struct
+	ReadService(ctx context.Context, servicePath string) (*types.Service, error)
+	// EnableService enables a systemd server on the host
+	EnableService(ctx context.Context, service *types.Service) error
+	// DisableService disables a systemd service on the host and removes its file, nothing is done
+	// if the service doesn't exist
+	DisableService(ctx context.Context, service *types.Service) error
+	// ReadServiceManifestFile reads the systemd manifest for a specific service
+	ReadServiceManifestFile(ctx context.Context, path string) (*types.Service, error)
+	// ReadServiceInjectionManifestFile reads the injection manifest file for the systemd service
+	ReadServiceInjectionManifestFile(ctx context.Context, path string) (*types.Service, error)
+	// CompareServices returns true if serviceA needs update(doesn't contain all fields from service B)
+	CompareServices(ctx context.Context, serviceA, serviceB *types.Service) (bool, error)
+	// UpdateSystemService updates a system service on the host
+	UpdateSystemService(ctx context.Context, serviceObj *types.Service) error
+	// RenderSriovConfigServices renders the pre and post network sriov-config systemd services
+	RenderSriovConfigServices(ctx context.Context, logLevel int) ([]*types.Service, error)
+	// udev rules
+	// PrepareNMUdevRule creates the needed udev rules to disable NetworkManager from
+	// our managed SR-IOV virtual functions
+	PrepareNMUdevRule(ctx context.Context, supportedVfIds []string) error
+	// PrepareVFRepUdevRule creates a script which helps to configure representor name for the VF
+	PrepareVFRepUdevRule(ctx context.Context) error
+	// AddDisableNMUdevRule adds udev rule that disables NetworkManager for VFs on the concrete PF:
+	AddDisableNMUdevRule(ctx context.Context, pfPciAddress string) error
+	// RemoveDisableNMUdevRule removes udev rule that disables NetworkManager for VFs on the concrete PF
+	RemoveDisableNMUdevRule(ctx context.Context, pfPciAddress string) error
+	// AddPersistPFNameUdevRule add udev rule that preserves PF name after switching to switchdev mode
+	AddPersistPFNameUdevRule(ctx context.Context, pfPciAddress, pfName string) error
+	// RemovePersistPFNameUdevRule removes udev rule that preserves PF name after switching to switchdev mode
+	RemovePersistPFNameUdevRule(ctx context.Context, pfPciAddress string) error
+	// AddVfRepresentorUdevRule adds udev rule that renames VF representors on the concrete PF
+	AddVfRepresentorUdevRule(ctx context.Context, pfPciAddress, pfName, pfSwitchID, pfSwitchPort string) error
+	// RemoveVfRepresentorUdevRule removes udev rule that renames VF representors on the concrete PF
+	RemoveVfRepresentorUdevRule(ctx context.Context, pfPciAddress string) error
+	// LoadUdevRules triggers udev rules for network subsystem
+	LoadUdevRules(ctx context.Context) error
+	// SR-IOV devices
+	// SetSriovNumVfs changes the number of virtual functions allocated for a specific
+	// physical function base on pci address
+	SetSriovNumVfs(ctx context.Context, pciAddr string, numVfs int) error
+	// VFIsReady returns the interface virtual function if the device is ready
+	VFIsReady(ctx context.Context, pciAddr string) (netlink.Link, error)
+	// SetVfAdminMac sets the virtual function administrative mac address via the physical function
+	// and the hardware address of the virtual function netdevice
+	SetVfAdminMac(ctx context.Context, vfAddr string, pfLink netlink.Link, vfLink netlink.Link) error
+	// GetNicSriovMode returns the interface mode
+	// supported modes SR-IOV legacy and switchdev
+	GetNicSriovMode(ctx context.Context, pciAddr string) string
+	// SetNicSriovMode configure the interface mode
+	// supported modes SR-IOV legacy and switchdev
+	SetNicSriovMode(ctx context.Context, pciAddr, mode string) error
+	// GetLinkType return the link type
+	// supported types are ethernet and infiniband
+	GetLinkType(ctx context.Context, name string) string
+	// ResetSriovDevice resets the number of virtual function for the specific physical function to zero
+	ResetSriovDevice(ctx context.Context, ifaceStatus sriovnetworkv1.InterfaceExt) error
+	// DiscoverSriovDevices returns a list of all the available SR-IOV capable network interfaces on the system
+	DiscoverSriovDevices(ctx context.Context, storeManager store.ManagerInterface) ([]sriovnetworkv1.InterfaceExt, error)
+	// ConfigSriovInterfaces configure multiple SR-IOV devices with the desired configuration
+	// if skipVFConfiguration flag is set, the function will configure PF and create VFs on it, but will skip VFs configuration
+	ConfigSriovInterfaces(ctx context.Context, storeManager store.ManagerInterface, interfaces []sriovnetworkv1.Interface,
+		ifaceStatuses []sriovnetworkv1.InterfaceExt, skipVFConfiguration bool) error
+	// ConfigSriovInterfaces configure virtual functions for virtual environments with the desired configuration
+	ConfigSriovDeviceVirtual(ctx context.Context, iface *sriovnetworkv1.Interface) error
+	// ReconcileVfAttributes reapplies the trust mode, the spoof check, the tx rate and the VLAN requested by
+	// the VF groups of the interface to the VFs on which they differ, the reapplied attributes are returned
+	ReconcileVfAttributes(ctx context.Context, iface *sriovnetworkv1.Interface) ([]types.VfAttributeCorrection, error)
+	// vDPA devices
+	// CreateVDPADevice creates VDPA device for VF with required type
+	CreateVDPADevice(ctx context.Context, pciAddr, vdpaType string) error
+	// DeleteVDPADevice removes VDPA device for provided pci address
+	DeleteVDPADevice(ctx context.Context, pciAddr string) error
+	// DiscoverVDPAType returns type of existing VDPA device for VF,
+	// returns empty string if VDPA device not found or unknown driver is in use
+	DiscoverVDPAType(ctx context.Context, pciAddr string) string
+	// GetVDPADeviceName returns the name of the VDPA device which is (or will be) created for the VF
+	GetVDPADeviceName(ctx context.Context, pciAddr string) string
+	// InfiniBand VFs
+	// ConfigureVfGUID configures and sets a GUID for an IB VF device
+	ConfigureVfGUID(ctx context.Context, vfAddr string, pfAddr string, vfID int, pfLink netlink.Link) error
+	// software bridges
+	// DiscoverBridges returns information about managed bridges on the host
+	DiscoverBridges(ctx context.Context) (sriovnetworkv1.Bridges, error)
+	// ConfigureBridge configure managed bridges for the host
+	ConfigureBridges(ctx context.Context, bridgesSpec sriovnetworkv1.Bridges, bridgesStatus sriovnetworkv1.Bridges) error
+	// DetachInterfaceFromManagedBridge detach interface from a managed bridge,
+	// this step is required before applying some configurations to PF, e.g. changing of eSwitch mode.
+	// The function detach interface from managed bridges only.
+	DetachInterfaceFromManagedBridge(ctx context.Context, pciAddr string) error
+	// OS distribution
+	// GetDistroInfo returns information about the OS distribution of the host
+	GetDistroInfo(ctx context.Context) (*types.DistroInfo, error)
+	// GetKernelArgsBackend returns the tool which should be used to manage kernel arguments on the host,
+	// grubby is used if the distribution is not known or can't be detected
+	GetKernelArgsBackend(ctx context.Context) (string, error)
+	// host facts
+	// GetHostFacts returns hardware facts of the host, the facts are collected once and cached
+	GetHostFacts(ctx context.Context) (*sriovnetworkv1.HostFacts, error)
+}
+
+// NewHostManagerV2 returns a host manager which runs its commands with the provided context, the commands
+// are killed when the context is done, e.g. when the config daemon stops. The base must implement
+// utils.ContextCmdInterface for the commands to be bound to the context, the commands run until completion
+// otherwise. The context of each call is checked before the call.
+func NewHostManagerV2(ctx context.Context, base utils.CmdInterface) (HostManagerV2Interface, error) {
+	hostManager, err := NewHostManager(&contextCmd{CmdInterface: base, ctx: ctx})
+	if err != nil {
+		return nil, err
+	}
+	return &hostManagerV2{host: hostManager}, nil
+}
+
+// AdaptToV2 returns a HostManagerV2Interface which calls the methods of the HostManagerInterface,
+// the context is only checked before each call
+func AdaptToV2(hostManager HostManagerInterface) HostManagerV2Interface {
+	return &hostManagerV2{host: hostManager}
+}
+
+// contextCmd runs the commands with the context of the host manager
+type contextCmd struct {
+	utils.CmdInterface
+	ctx context.Context
+}
+
+// RunCommand runs the command with the context of the host manager
+func (c *contextCmd) RunCommand(command string, args ...string) (string, string, error) {
+	if runner, ok := c.CmdInterface.(utils.ContextCmdInterface); ok {
+		return runner.RunCommandContext(c.ctx, command, args...)
+	}
+	return c.CmdInterface.RunCommand(command, args...)
+}
+
+// hostManagerV2 implements HostManagerV2Interface with a HostManagerInterface
+type hostManagerV2 struct {
+	host HostManagerInterface
+}
+
+func (h *hostManagerV2) TryEnableTun(ctx context.Context) {
+	if err := ctx.Err(); err != nil {
+		return
+	}
+	h.host.TryEnableTun()
+}
+
+func (h *hostManagerV2) TryEnableVhostNet(ctx context.Context) {
+	if err := ctx.Err(); err != nil {
+		return
+	}
+	h.host.TryEnableVhostNet()
+}
+
+func (h *hostManagerV2) EnsureVhostNet(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.EnsureVhostNet()
+}
+
+func (h *hostManagerV2) CheckRDMAEnabled(ctx context.Context) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return h.host.CheckRDMAEnabled()
+}
+
+func (h *hostManagerV2) GetCurrentKernelArgs(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetCurrentKernelArgs()
+}
+
+func (h *hostManagerV2) GetKernelVersion(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetKernelVersion()
+}
+
+func (h *hostManagerV2) IsKernelArgsSet(ctx context.Context, cmdLine, karg string) bool {
+	if err := ctx.Err(); err != nil {
+		return false
+	}
+	return h.host.IsKernelArgsSet(cmdLine, karg)
+}
+
+func (h *hostManagerV2) Unbind(ctx context.Context, pciAddr string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.Unbind(pciAddr)
+}
+
+func (h *hostManagerV2) BindDpdkDriver(ctx context.Context, pciAddr, driver string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.BindDpdkDriver(pciAddr, driver)
+}
+
+func (h *hostManagerV2) BindDefaultDriver(ctx context.Context, pciAddr string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.BindDefaultDriver(pciAddr)
+}
+
+func (h *hostManagerV2) BindDriverByBusAndDevice(ctx context.Context, bus, device, driver string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.BindDriverByBusAndDevice(bus, device, driver)
+}
+
+func (h *hostManagerV2) HasDriver(ctx context.Context, pciAddr string) (bool, string) {
+	if err := ctx.Err(); err != nil {
+		return false, ""
+	}
+	return h.host.HasDriver(pciAddr)
+}
+
+func (h *hostManagerV2) GetDriverByBusAndDevice(ctx context.Context, bus, device string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetDriverByBusAndDevice(bus, device)
+}
+
+func (h *hostManagerV2) VerifyDriverBinding(ctx context.Context, pciAddress, expectedDriver string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.VerifyDriverBinding(pciAddress, expectedDriver)
+}
+
+func (h *hostManagerV2) RebindVfToDefaultDriver(ctx context.Context, pciAddr string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.RebindVfToDefaultDriver(pciAddr)
+}
+
+func (h *hostManagerV2) UnbindDriverByBusAndDevice(ctx context.Context, bus, device string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.UnbindDriverByBusAndDevice(bus, device)
+}
+
+func (h *hostManagerV2) UnbindDriverIfNeeded(ctx context.Context, pciAddr string, isRdma bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.UnbindDriverIfNeeded(pciAddr, isRdma)
+}
+
+func (h *hostManagerV2) LoadKernelModule(ctx context.Context, name string, args ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.LoadKernelModule(name, args...)
+}
+
+func (h *hostManagerV2) UnloadKernelModule(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.UnloadKernelModule(name)
+}
+
+func (h *hostManagerV2) IsKernelModuleLoaded(ctx context.Context, name string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return h.host.IsKernelModuleLoaded(name)
+}
+
+func (h *hostManagerV2) IsKernelLockdownMode(ctx context.Context) bool {
+	if err := ctx.Err(); err != nil {
+		return false
+	}
+	return h.host.IsKernelLockdownMode()
+}
+
+func (h *hostManagerV2) WriteModulesLoadConf(ctx context.Context, path string, modules []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.WriteModulesLoadConf(path, modules)
+}
+
+func (h *hostManagerV2) ConfigureModprobeBlacklist(ctx context.Context, vfioDeviceIDs, pfDrivers []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.ConfigureModprobeBlacklist(vfioDeviceIDs, pfDrivers)
+}
+
+func (h *hostManagerV2) GetArchitecture(ctx context.Context) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetArchitecture()
+}
+
+func (h *hostManagerV2) GetDeviceNumaNode(ctx context.Context, pciAddr string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return h.host.GetDeviceNumaNode(pciAddr)
+}
+
+func (h *hostManagerV2) ListVFInterrupts(ctx context.Context, pciAddress string) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.ListVFInterrupts(pciAddress)
+}
+
+func (h *hostManagerV2) SetHugepages(ctx context.Context, numaNode int, size string, count int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return h.host.SetHugepages(numaNode, size, count)
+}
+
+func (h *hostManagerV2) CreateCharDevice(ctx context.Context, major, minor uint32, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.CreateCharDevice(major, minor, path)
+}
+
+func (h *hostManagerV2) UploadFirmware(ctx context.Context, pciAddress, fwPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.UploadFirmware(pciAddress, fwPath)
+}
+
+func (h *hostManagerV2) TryToGetVirtualInterfaceName(ctx context.Context, pciAddr string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.TryToGetVirtualInterfaceName(pciAddr)
+}
+
+func (h *hostManagerV2) TryGetInterfaceName(ctx context.Context, pciAddr string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.TryGetInterfaceName(pciAddr)
+}
+
+func (h *hostManagerV2) GetInterfaceIndex(ctx context.Context, pciAddr string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return h.host.GetInterfaceIndex(pciAddr)
+}
+
+func (h *hostManagerV2) GetPhysSwitchID(ctx context.Context, name string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetPhysSwitchID(name)
+}
+
+func (h *hostManagerV2) GetPhysPortName(ctx context.Context, name string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetPhysPortName(name)
+}
+
+func (h *hostManagerV2) IsSwitchdev(ctx context.Context, name string) bool {
+	if err := ctx.Err(); err != nil {
+		return false
+	}
+	return h.host.IsSwitchdev(name)
+}
+
+func (h *hostManagerV2) GetNetdevMTU(ctx context.Context, pciAddr string) int {
+	if err := ctx.Err(); err != nil {
+		return 0
+	}
+	return h.host.GetNetdevMTU(pciAddr)
+}
+
+func (h *hostManagerV2) SetNetdevMTU(ctx context.Context, pciAddr string, mtu int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.SetNetdevMTU(pciAddr, mtu)
+}
+
+func (h *hostManagerV2) GetNetDevMac(ctx context.Context, name string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetNetDevMac(name)
+}
+
+func (h *hostManagerV2) GetNetDevNodeGUID(ctx context.Context, pciAddr string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetNetDevNodeGUID(pciAddr)
+}
+
+func (h *hostManagerV2) GetNetDevLinkSpeed(ctx context.Context, name string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetNetDevLinkSpeed(name)
+}
+
+func (h *hostManagerV2) GetNetDevFirmwareVersion(ctx context.Context, name string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetNetDevFirmwareVersion(name)
+}
+
+func (h *hostManagerV2) GetNetDevBondMaster(ctx context.Context, name string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetNetDevBondMaster(name)
+}
+
+func (h *hostManagerV2) IsPartOfBond(ctx context.Context, pciAddress string) (bool, string, error) {
+	if err := ctx.Err(); err != nil {
+		return false, "", err
+	}
+	return h.host.IsPartOfBond(pciAddress)
+}
+
+func (h *hostManagerV2) GetDevlinkDeviceParam(ctx context.Context, pciAddr, paramName string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetDevlinkDeviceParam(pciAddr, paramName)
+}
+
+func (h *hostManagerV2) SetDevlinkDeviceParam(ctx context.Context, pciAddr, paramName, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.SetDevlinkDeviceParam(pciAddr, paramName, value)
+}
+
+func (h *hostManagerV2) IsDevlinkDeviceParamPermanent(ctx context.Context, pciAddr, paramName string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return h.host.IsDevlinkDeviceParamPermanent(pciAddr, paramName)
+}
+
+func (h *hostManagerV2) GetRDMASubsystemNetnsMode(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetRDMASubsystemNetnsMode()
+}
+
+func (h *hostManagerV2) SetRDMASubsystemNetnsMode(ctx context.Context, mode string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.SetRDMASubsystemNetnsMode(mode)
+}
+
+func (h *hostManagerV2) EnableHwTcOffload(ctx context.Context, ifaceName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.EnableHwTcOffload(ifaceName)
+}
+
+func (h *hostManagerV2) ConfigureEncapOffload(ctx context.Context, ifaceName string, vxlan, geneve bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.ConfigureEncapOffload(ifaceName, vxlan, geneve)
+}
+
+func (h *hostManagerV2) GetEncapOffloadState(ctx context.Context, ifaceName string) (vxlan, geneve bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, false, err
+	}
+	return h.host.GetEncapOffloadState(ifaceName)
+}
+
+func (h *hostManagerV2) GetPfSettings(ctx context.Context, ifaceName string) (*sriovnetworkv1.PfSettings, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.GetPfSettings(ifaceName)
+}
+
+func (h *hostManagerV2) SetPfSettings(ctx context.Context, ifaceName string, settings *sriovnetworkv1.PfSettings) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.SetPfSettings(ifaceName, settings)
+}
+
+func (h *hostManagerV2) GetNetDevLinkAdminState(ctx context.Context, ifaceName string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetNetDevLinkAdminState(ifaceName)
+}
+
+func (h *hostManagerV2) SetNetDevLinkAdminState(ctx context.Context, ifaceName string, state string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.SetNetDevLinkAdminState(ifaceName, state)
+}
+
+func (h *hostManagerV2) GetPciAddressFromInterfaceName(ctx context.Context, interfaceName string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetPciAddressFromInterfaceName(interfaceName)
+}
+
+func (h *hostManagerV2) ConfigureVfQoS(ctx context.Context, pfName string, vfID int, dscp int32, egressBandwidthMbps int32) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.ConfigureVfQoS(pfName, vfID, dscp, egressBandwidthMbps)
+}
+
+func (h *hostManagerV2) GetVFRSSConfig(ctx context.Context, pfName string, vfID int) (*types.RSSConfig, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.GetVFRSSConfig(pfName, vfID)
+}
+
+func (h *hostManagerV2) IsServiceExist(ctx context.Context, servicePath string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return h.host.IsServiceExist(servicePath)
+}
+
+func (h *hostManagerV2) IsServiceEnabled(ctx context.Context, servicePath string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return h.host.IsServiceEnabled(servicePath)
+}
+
+func (h *hostManagerV2) ReadService(ctx context.Context, servicePath string) (*types.Service, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.ReadService(servicePath)
+}
+
+func (h *hostManagerV2) EnableService(ctx context.Context, service *types.Service) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.EnableService(service)
+}
+
+func (h *hostManagerV2) DisableService(ctx context.Context, service *types.Service) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.DisableService(service)
+}
+
+func (h *hostManagerV2) ReadServiceManifestFile(ctx context.Context, path string) (*types.Service, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.ReadServiceManifestFile(path)
+}
+
+func (h *hostManagerV2) ReadServiceInjectionManifestFile(ctx context.Context, path string) (*types.Service, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.ReadServiceInjectionManifestFile(path)
+}
+
+func (h *hostManagerV2) CompareServices(ctx context.Context, serviceA, serviceB *types.Service) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return h.host.CompareServices(serviceA, serviceB)
+}
+
+func (h *hostManagerV2) UpdateSystemService(ctx context.Context, serviceObj *types.Service) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.UpdateSystemService(serviceObj)
+}
+
+func (h *hostManagerV2) RenderSriovConfigServices(ctx context.Context, logLevel int) ([]*types.Service, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.RenderSriovConfigServices(logLevel)
+}
+
+func (h *hostManagerV2) PrepareNMUdevRule(ctx context.Context, supportedVfIds []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.PrepareNMUdevRule(supportedVfIds)
+}
+
+func (h *hostManagerV2) PrepareVFRepUdevRule(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.PrepareVFRepUdevRule()
+}
+
+func (h *hostManagerV2) AddDisableNMUdevRule(ctx context.Context, pfPciAddress string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.AddDisableNMUdevRule(pfPciAddress)
+}
+
+func (h *hostManagerV2) RemoveDisableNMUdevRule(ctx context.Context, pfPciAddress string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.RemoveDisableNMUdevRule(pfPciAddress)
+}
+
+func (h *hostManagerV2) AddPersistPFNameUdevRule(ctx context.Context, pfPciAddress, pfName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.AddPersistPFNameUdevRule(pfPciAddress, pfName)
+}
+
+func (h *hostManagerV2) RemovePersistPFNameUdevRule(ctx context.Context, pfPciAddress string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.RemovePersistPFNameUdevRule(pfPciAddress)
+}
+
+func (h *hostManagerV2) AddVfRepresentorUdevRule(ctx context.Context, pfPciAddress, pfName, pfSwitchID, pfSwitchPort string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.AddVfRepresentorUdevRule(pfPciAddress, pfName, pfSwitchID, pfSwitchPort)
+}
+
+func (h *hostManagerV2) RemoveVfRepresentorUdevRule(ctx context.Context, pfPciAddress string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.RemoveVfRepresentorUdevRule(pfPciAddress)
+}
+
+func (h *hostManagerV2) LoadUdevRules(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.LoadUdevRules()
+}
+
+func (h *hostManagerV2) SetSriovNumVfs(ctx context.Context, pciAddr string, numVfs int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.SetSriovNumVfs(pciAddr, numVfs)
+}
+
+func (h *hostManagerV2) VFIsReady(ctx context.Context, pciAddr string) (netlink.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.VFIsReady(pciAddr)
+}
+
+func (h *hostManagerV2) SetVfAdminMac(ctx context.Context, vfAddr string, pfLink netlink.Link, vfLink netlink.Link) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.SetVfAdminMac(vfAddr, pfLink, vfLink)
+}
+
+func (h *hostManagerV2) GetNicSriovMode(ctx context.Context, pciAddr string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetNicSriovMode(pciAddr)
+}
+
+func (h *hostManagerV2) SetNicSriovMode(ctx context.Context, pciAddr, mode string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.SetNicSriovMode(pciAddr, mode)
+}
+
+func (h *hostManagerV2) GetLinkType(ctx context.Context, name string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetLinkType(name)
+}
+
+func (h *hostManagerV2) ResetSriovDevice(ctx context.Context, ifaceStatus sriovnetworkv1.InterfaceExt) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.ResetSriovDevice(ifaceStatus)
+}
+
+func (h *hostManagerV2) DiscoverSriovDevices(ctx context.Context,
+	storeManager store.ManagerInterface) ([]sriovnetworkv1.InterfaceExt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.DiscoverSriovDevices(storeManager)
+}
+
+func (h *hostManagerV2) ConfigSriovInterfaces(ctx context.Context, storeManager store.ManagerInterface,
+	interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt, skipVFConfiguration bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.ConfigSriovInterfaces(storeManager, interfaces, ifaceStatuses, skipVFConfiguration)
+}
+
+func (h *hostManagerV2) ConfigSriovDeviceVirtual(ctx context.Context, iface *sriovnetworkv1.Interface) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.ConfigSriovDeviceVirtual(iface)
+}
+
+func (h *hostManagerV2) ReconcileVfAttributes(ctx context.Context,
+	iface *sriovnetworkv1.Interface) ([]types.VfAttributeCorrection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.ReconcileVfAttributes(iface)
+}
+
+func (h *hostManagerV2) CreateVDPADevice(ctx context.Context, pciAddr, vdpaType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.CreateVDPADevice(pciAddr, vdpaType)
+}
+
+func (h *hostManagerV2) DeleteVDPADevice(ctx context.Context, pciAddr string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.DeleteVDPADevice(pciAddr)
+}
+
+func (h *hostManagerV2) DiscoverVDPAType(ctx context.Context, pciAddr string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.DiscoverVDPAType(pciAddr)
+}
+
+func (h *hostManagerV2) GetVDPADeviceName(ctx context.Context, pciAddr string) string {
+	if err := ctx.Err(); err != nil {
+		return ""
+	}
+	return h.host.GetVDPADeviceName(pciAddr)
+}
+
+func (h *hostManagerV2) ConfigureVfGUID(ctx context.Context, vfAddr string, pfAddr string, vfID int, pfLink netlink.Link) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.ConfigureVfGUID(vfAddr, pfAddr, vfID, pfLink)
+}
+
+func (h *hostManagerV2) DiscoverBridges(ctx context.Context) (sriovnetworkv1.Bridges, error) {
+	if err := ctx.Err(); err != nil {
+		return sriovnetworkv1.Bridges{}, err
+	}
+	return h.host.DiscoverBridges()
+}
+
+func (h *hostManagerV2) ConfigureBridges(ctx context.Context,
+	bridgesSpec sriovnetworkv1.Bridges, bridgesStatus sriovnetworkv1.Bridges) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.ConfigureBridges(bridgesSpec, bridgesStatus)
+}
+
+func (h *hostManagerV2) DetachInterfaceFromManagedBridge(ctx context.Context, pciAddr string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.host.DetachInterfaceFromManagedBridge(pciAddr)
+}
+
+func (h *hostManagerV2) GetDistroInfo(ctx context.Context) (*types.DistroInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.GetDistroInfo()
+}
+
+func (h *hostManagerV2) GetKernelArgsBackend(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return h.host.GetKernelArgsBackend()
+}
+
+func (h *hostManagerV2) GetHostFacts(ctx context.Context) (*sriovnetworkv1.HostFacts, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return h.host.GetHostFacts()
+}
@@ -0,0 +1,74 @@
+package host
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	mock_host "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// fakeContextCmd records the context of the commands
+type fakeContextCmd struct {
+	utils.CmdInterface
+	contexts []context.Context
+}
+
+func (f *fakeContextCmd) RunCommand(command string, args ...string) (string, string, error) {
+	return "", "", fmt.Errorf("RunCommand must not be called")
+}
+
+func (f *fakeContextCmd) RunCommandContext(ctx context.Context, command string, args ...string) (string, string, error) {
+	f.contexts = append(f.contexts, ctx)
+	return "out", "", nil
+}
+
+var _ = Describe("HostManagerV2", func() {
+	var (
+		testCtrl *gomock.Controller
+		hostMock *mock_host.MockHostManagerInterface
+	)
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		hostMock = mock_host.NewMockHostManagerInterface(testCtrl)
+	})
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+	Context("AdaptToV2", func() {
+		It("should call the host manager", func() {
+			hostMock.EXPECT().SetSriovNumVfs("0000:d8:00.0", 4).Return(nil)
+			hostMock.EXPECT().GetCurrentKernelArgs().Return("intel_iommu=on", nil)
+			h := AdaptToV2(hostMock)
+			Expect(h.SetSriovNumVfs(context.Background(), "0000:d8:00.0", 4)).NotTo(HaveOccurred())
+			Expect(h.GetCurrentKernelArgs(context.Background())).To(Equal("intel_iommu=on"))
+		})
+		It("should not call the host manager if the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			h := AdaptToV2(hostMock)
+			Expect(h.SetSriovNumVfs(ctx, "0000:d8:00.0", 4)).To(MatchError(context.Canceled))
+			numaNode, err := h.GetDeviceNumaNode(ctx, "0000:d8:00.0")
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(numaNode).To(Equal(0))
+			Expect(h.IsKernelArgsSet(ctx, "intel_iommu=on", "intel_iommu=on")).To(BeFalse())
+		})
+	})
+	Context("contextCmd", func() {
+		It("should run the commands with the context of the host manager", func() {
+			base := &fakeContextCmd{}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cmd := &contextCmd{CmdInterface: base, ctx: ctx}
+			stdout, _, err := cmd.RunCommand("cat", "/proc/cmdline")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("out"))
+			Expect(base.contexts).To(HaveLen(1))
+			Expect(base.contexts[0]).To(BeIdenticalTo(ctx))
+		})
+	})
+})
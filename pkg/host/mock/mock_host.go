@@ -193,6 +193,34 @@ func (mr *MockHostManagerInterfaceMockRecorder) ConfigureBridges(bridgesSpec, br
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureBridges", reflect.TypeOf((*MockHostManagerInterface)(nil).ConfigureBridges), bridgesSpec, bridgesStatus)
 }
 
+// ConfigureEncapOffload mocks base method.
+func (m *MockHostManagerInterface) ConfigureEncapOffload(ifaceName string, vxlan, geneve bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureEncapOffload", ifaceName, vxlan, geneve)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureEncapOffload indicates an expected call of ConfigureEncapOffload.
+func (mr *MockHostManagerInterfaceMockRecorder) ConfigureEncapOffload(ifaceName, vxlan, geneve interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureEncapOffload", reflect.TypeOf((*MockHostManagerInterface)(nil).ConfigureEncapOffload), ifaceName, vxlan, geneve)
+}
+
+// ConfigureModprobeBlacklist mocks base method.
+func (m *MockHostManagerInterface) ConfigureModprobeBlacklist(vfioDeviceIDs, pfDrivers []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureModprobeBlacklist", vfioDeviceIDs, pfDrivers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureModprobeBlacklist indicates an expected call of ConfigureModprobeBlacklist.
+func (mr *MockHostManagerInterfaceMockRecorder) ConfigureModprobeBlacklist(vfioDeviceIDs, pfDrivers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureModprobeBlacklist", reflect.TypeOf((*MockHostManagerInterface)(nil).ConfigureModprobeBlacklist), vfioDeviceIDs, pfDrivers)
+}
+
 // ConfigureVfGUID mocks base method.
 func (m *MockHostManagerInterface) ConfigureVfGUID(vfAddr, pfAddr string, vfID int, pfLink netlink.Link) error {
 	m.ctrl.T.Helper()
@@ -207,6 +235,34 @@ func (mr *MockHostManagerInterfaceMockRecorder) ConfigureVfGUID(vfAddr, pfAddr,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureVfGUID", reflect.TypeOf((*MockHostManagerInterface)(nil).ConfigureVfGUID), vfAddr, pfAddr, vfID, pfLink)
 }
 
+// ConfigureVfQoS mocks base method.
+func (m *MockHostManagerInterface) ConfigureVfQoS(pfName string, vfID int, dscp, egressBandwidthMbps int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureVfQoS", pfName, vfID, dscp, egressBandwidthMbps)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureVfQoS indicates an expected call of ConfigureVfQoS.
+func (mr *MockHostManagerInterfaceMockRecorder) ConfigureVfQoS(pfName, vfID, dscp, egressBandwidthMbps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureVfQoS", reflect.TypeOf((*MockHostManagerInterface)(nil).ConfigureVfQoS), pfName, vfID, dscp, egressBandwidthMbps)
+}
+
+// CreateCharDevice mocks base method.
+func (m *MockHostManagerInterface) CreateCharDevice(major, minor uint32, path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCharDevice", major, minor, path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCharDevice indicates an expected call of CreateCharDevice.
+func (mr *MockHostManagerInterfaceMockRecorder) CreateCharDevice(major, minor, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCharDevice", reflect.TypeOf((*MockHostManagerInterface)(nil).CreateCharDevice), major, minor, path)
+}
+
 // CreateVDPADevice mocks base method.
 func (m *MockHostManagerInterface) CreateVDPADevice(pciAddr, vdpaType string) error {
 	m.ctrl.T.Helper()
@@ -249,6 +305,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) DetachInterfaceFromManagedBridge
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachInterfaceFromManagedBridge", reflect.TypeOf((*MockHostManagerInterface)(nil).DetachInterfaceFromManagedBridge), pciAddr)
 }
 
+// DisableService mocks base method.
+func (m *MockHostManagerInterface) DisableService(service *types.Service) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableService", service)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableService indicates an expected call of DisableService.
+func (mr *MockHostManagerInterfaceMockRecorder) DisableService(service interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableService", reflect.TypeOf((*MockHostManagerInterface)(nil).DisableService), service)
+}
+
 // DiscoverBridges mocks base method.
 func (m *MockHostManagerInterface) DiscoverBridges() (v1.Bridges, error) {
 	m.ctrl.T.Helper()
@@ -321,6 +391,34 @@ func (mr *MockHostManagerInterfaceMockRecorder) EnableService(service interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableService", reflect.TypeOf((*MockHostManagerInterface)(nil).EnableService), service)
 }
 
+// EnsureVhostNet mocks base method.
+func (m *MockHostManagerInterface) EnsureVhostNet() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureVhostNet")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureVhostNet indicates an expected call of EnsureVhostNet.
+func (mr *MockHostManagerInterfaceMockRecorder) EnsureVhostNet() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureVhostNet", reflect.TypeOf((*MockHostManagerInterface)(nil).EnsureVhostNet))
+}
+
+// GetArchitecture mocks base method.
+func (m *MockHostManagerInterface) GetArchitecture() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchitecture")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetArchitecture indicates an expected call of GetArchitecture.
+func (mr *MockHostManagerInterfaceMockRecorder) GetArchitecture() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchitecture", reflect.TypeOf((*MockHostManagerInterface)(nil).GetArchitecture))
+}
+
 // GetCurrentKernelArgs mocks base method.
 func (m *MockHostManagerInterface) GetCurrentKernelArgs() (string, error) {
 	m.ctrl.T.Helper()
@@ -336,6 +434,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetCurrentKernelArgs() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentKernelArgs", reflect.TypeOf((*MockHostManagerInterface)(nil).GetCurrentKernelArgs))
 }
 
+// GetDeviceNumaNode mocks base method.
+func (m *MockHostManagerInterface) GetDeviceNumaNode(pciAddr string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceNumaNode", pciAddr)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceNumaNode indicates an expected call of GetDeviceNumaNode.
+func (mr *MockHostManagerInterfaceMockRecorder) GetDeviceNumaNode(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceNumaNode", reflect.TypeOf((*MockHostManagerInterface)(nil).GetDeviceNumaNode), pciAddr)
+}
+
 // GetDevlinkDeviceParam mocks base method.
 func (m *MockHostManagerInterface) GetDevlinkDeviceParam(pciAddr, paramName string) (string, error) {
 	m.ctrl.T.Helper()
@@ -351,6 +464,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetDevlinkDeviceParam(pciAddr, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDevlinkDeviceParam", reflect.TypeOf((*MockHostManagerInterface)(nil).GetDevlinkDeviceParam), pciAddr, paramName)
 }
 
+// GetDistroInfo mocks base method.
+func (m *MockHostManagerInterface) GetDistroInfo() (*types.DistroInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDistroInfo")
+	ret0, _ := ret[0].(*types.DistroInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDistroInfo indicates an expected call of GetDistroInfo.
+func (mr *MockHostManagerInterfaceMockRecorder) GetDistroInfo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDistroInfo", reflect.TypeOf((*MockHostManagerInterface)(nil).GetDistroInfo))
+}
+
 // GetDriverByBusAndDevice mocks base method.
 func (m *MockHostManagerInterface) GetDriverByBusAndDevice(bus, device string) (string, error) {
 	m.ctrl.T.Helper()
@@ -366,6 +494,37 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetDriverByBusAndDevice(bus, dev
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverByBusAndDevice", reflect.TypeOf((*MockHostManagerInterface)(nil).GetDriverByBusAndDevice), bus, device)
 }
 
+// GetEncapOffloadState mocks base method.
+func (m *MockHostManagerInterface) GetEncapOffloadState(ifaceName string) (bool, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEncapOffloadState", ifaceName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEncapOffloadState indicates an expected call of GetEncapOffloadState.
+func (mr *MockHostManagerInterfaceMockRecorder) GetEncapOffloadState(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEncapOffloadState", reflect.TypeOf((*MockHostManagerInterface)(nil).GetEncapOffloadState), ifaceName)
+}
+
+// GetHostFacts mocks base method.
+func (m *MockHostManagerInterface) GetHostFacts() (*v1.HostFacts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHostFacts")
+	ret0, _ := ret[0].(*v1.HostFacts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHostFacts indicates an expected call of GetHostFacts.
+func (mr *MockHostManagerInterfaceMockRecorder) GetHostFacts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostFacts", reflect.TypeOf((*MockHostManagerInterface)(nil).GetHostFacts))
+}
+
 // GetInterfaceIndex mocks base method.
 func (m *MockHostManagerInterface) GetInterfaceIndex(pciAddr string) (int, error) {
 	m.ctrl.T.Helper()
@@ -381,6 +540,36 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetInterfaceIndex(pciAddr interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterfaceIndex", reflect.TypeOf((*MockHostManagerInterface)(nil).GetInterfaceIndex), pciAddr)
 }
 
+// GetKernelArgsBackend mocks base method.
+func (m *MockHostManagerInterface) GetKernelArgsBackend() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKernelArgsBackend")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKernelArgsBackend indicates an expected call of GetKernelArgsBackend.
+func (mr *MockHostManagerInterfaceMockRecorder) GetKernelArgsBackend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKernelArgsBackend", reflect.TypeOf((*MockHostManagerInterface)(nil).GetKernelArgsBackend))
+}
+
+// GetKernelVersion mocks base method.
+func (m *MockHostManagerInterface) GetKernelVersion() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKernelVersion")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKernelVersion indicates an expected call of GetKernelVersion.
+func (mr *MockHostManagerInterfaceMockRecorder) GetKernelVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKernelVersion", reflect.TypeOf((*MockHostManagerInterface)(nil).GetKernelVersion))
+}
+
 // GetLinkType mocks base method.
 func (m *MockHostManagerInterface) GetLinkType(name string) string {
 	m.ctrl.T.Helper()
@@ -395,6 +584,34 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetLinkType(name interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkType", reflect.TypeOf((*MockHostManagerInterface)(nil).GetLinkType), name)
 }
 
+// GetNetDevBondMaster mocks base method.
+func (m *MockHostManagerInterface) GetNetDevBondMaster(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevBondMaster", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevBondMaster indicates an expected call of GetNetDevBondMaster.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevBondMaster(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevBondMaster", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevBondMaster), name)
+}
+
+// GetNetDevFirmwareVersion mocks base method.
+func (m *MockHostManagerInterface) GetNetDevFirmwareVersion(name string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetDevFirmwareVersion", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetNetDevFirmwareVersion indicates an expected call of GetNetDevFirmwareVersion.
+func (mr *MockHostManagerInterfaceMockRecorder) GetNetDevFirmwareVersion(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetDevFirmwareVersion", reflect.TypeOf((*MockHostManagerInterface)(nil).GetNetDevFirmwareVersion), name)
+}
+
 // GetNetDevLinkAdminState mocks base method.
 func (m *MockHostManagerInterface) GetNetDevLinkAdminState(ifaceName string) string {
 	m.ctrl.T.Helper()
@@ -494,6 +711,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetPciAddressFromInterfaceName(i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPciAddressFromInterfaceName", reflect.TypeOf((*MockHostManagerInterface)(nil).GetPciAddressFromInterfaceName), interfaceName)
 }
 
+// GetPfSettings mocks base method.
+func (m *MockHostManagerInterface) GetPfSettings(ifaceName string) (*v1.PfSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPfSettings", ifaceName)
+	ret0, _ := ret[0].(*v1.PfSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPfSettings indicates an expected call of GetPfSettings.
+func (mr *MockHostManagerInterfaceMockRecorder) GetPfSettings(ifaceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPfSettings", reflect.TypeOf((*MockHostManagerInterface)(nil).GetPfSettings), ifaceName)
+}
+
 // GetPhysPortName mocks base method.
 func (m *MockHostManagerInterface) GetPhysPortName(name string) (string, error) {
 	m.ctrl.T.Helper()
@@ -524,6 +756,50 @@ func (mr *MockHostManagerInterfaceMockRecorder) GetPhysSwitchID(name interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhysSwitchID", reflect.TypeOf((*MockHostManagerInterface)(nil).GetPhysSwitchID), name)
 }
 
+// GetRDMASubsystemNetnsMode mocks base method.
+func (m *MockHostManagerInterface) GetRDMASubsystemNetnsMode() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRDMASubsystemNetnsMode")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRDMASubsystemNetnsMode indicates an expected call of GetRDMASubsystemNetnsMode.
+func (mr *MockHostManagerInterfaceMockRecorder) GetRDMASubsystemNetnsMode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRDMASubsystemNetnsMode", reflect.TypeOf((*MockHostManagerInterface)(nil).GetRDMASubsystemNetnsMode))
+}
+
+// GetVFRSSConfig mocks base method.
+func (m *MockHostManagerInterface) GetVFRSSConfig(pfName string, vfID int) (*types.RSSConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVFRSSConfig", pfName, vfID)
+	ret0, _ := ret[0].(*types.RSSConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVFRSSConfig indicates an expected call of GetVFRSSConfig.
+func (mr *MockHostManagerInterfaceMockRecorder) GetVFRSSConfig(pfName, vfID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVFRSSConfig", reflect.TypeOf((*MockHostManagerInterface)(nil).GetVFRSSConfig), pfName, vfID)
+}
+
+// GetVDPADeviceName mocks base method.
+func (m *MockHostManagerInterface) GetVDPADeviceName(pciAddr string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVDPADeviceName", pciAddr)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetVDPADeviceName indicates an expected call of GetVDPADeviceName.
+func (mr *MockHostManagerInterfaceMockRecorder) GetVDPADeviceName(pciAddr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVDPADeviceName", reflect.TypeOf((*MockHostManagerInterface)(nil).GetVDPADeviceName), pciAddr)
+}
+
 // HasDriver mocks base method.
 func (m *MockHostManagerInterface) HasDriver(pciAddr string) (bool, string) {
 	m.ctrl.T.Helper()
@@ -539,6 +815,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) HasDriver(pciAddr interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDriver", reflect.TypeOf((*MockHostManagerInterface)(nil).HasDriver), pciAddr)
 }
 
+// IsDevlinkDeviceParamPermanent mocks base method.
+func (m *MockHostManagerInterface) IsDevlinkDeviceParamPermanent(pciAddr, paramName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDevlinkDeviceParamPermanent", pciAddr, paramName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDevlinkDeviceParamPermanent indicates an expected call of IsDevlinkDeviceParamPermanent.
+func (mr *MockHostManagerInterfaceMockRecorder) IsDevlinkDeviceParamPermanent(pciAddr, paramName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDevlinkDeviceParamPermanent", reflect.TypeOf((*MockHostManagerInterface)(nil).IsDevlinkDeviceParamPermanent), pciAddr, paramName)
+}
+
 // IsKernelArgsSet mocks base method.
 func (m *MockHostManagerInterface) IsKernelArgsSet(cmdLine, karg string) bool {
 	m.ctrl.T.Helper()
@@ -582,6 +873,22 @@ func (mr *MockHostManagerInterfaceMockRecorder) IsKernelModuleLoaded(name interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsKernelModuleLoaded", reflect.TypeOf((*MockHostManagerInterface)(nil).IsKernelModuleLoaded), name)
 }
 
+// IsPartOfBond mocks base method.
+func (m *MockHostManagerInterface) IsPartOfBond(pciAddress string) (bool, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPartOfBond", pciAddress)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IsPartOfBond indicates an expected call of IsPartOfBond.
+func (mr *MockHostManagerInterfaceMockRecorder) IsPartOfBond(pciAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPartOfBond", reflect.TypeOf((*MockHostManagerInterface)(nil).IsPartOfBond), pciAddress)
+}
+
 // IsServiceEnabled mocks base method.
 func (m *MockHostManagerInterface) IsServiceEnabled(servicePath string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -626,6 +933,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) IsSwitchdev(name interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSwitchdev", reflect.TypeOf((*MockHostManagerInterface)(nil).IsSwitchdev), name)
 }
 
+// ListVFInterrupts mocks base method.
+func (m *MockHostManagerInterface) ListVFInterrupts(pciAddress string) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVFInterrupts", pciAddress)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVFInterrupts indicates an expected call of ListVFInterrupts.
+func (mr *MockHostManagerInterfaceMockRecorder) ListVFInterrupts(pciAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVFInterrupts", reflect.TypeOf((*MockHostManagerInterface)(nil).ListVFInterrupts), pciAddress)
+}
+
 // LoadKernelModule mocks base method.
 func (m *MockHostManagerInterface) LoadKernelModule(name string, args ...string) error {
 	m.ctrl.T.Helper()
@@ -746,6 +1068,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) RebindVfToDefaultDriver(pciAddr
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebindVfToDefaultDriver", reflect.TypeOf((*MockHostManagerInterface)(nil).RebindVfToDefaultDriver), pciAddr)
 }
 
+// ReconcileVfAttributes mocks base method.
+func (m *MockHostManagerInterface) ReconcileVfAttributes(iface *v1.Interface) ([]types.VfAttributeCorrection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileVfAttributes", iface)
+	ret0, _ := ret[0].([]types.VfAttributeCorrection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileVfAttributes indicates an expected call of ReconcileVfAttributes.
+func (mr *MockHostManagerInterfaceMockRecorder) ReconcileVfAttributes(iface interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileVfAttributes", reflect.TypeOf((*MockHostManagerInterface)(nil).ReconcileVfAttributes), iface)
+}
+
 // RemoveDisableNMUdevRule mocks base method.
 func (m *MockHostManagerInterface) RemoveDisableNMUdevRule(pfPciAddress string) error {
 	m.ctrl.T.Helper()
@@ -788,6 +1125,21 @@ func (mr *MockHostManagerInterfaceMockRecorder) RemoveVfRepresentorUdevRule(pfPc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveVfRepresentorUdevRule", reflect.TypeOf((*MockHostManagerInterface)(nil).RemoveVfRepresentorUdevRule), pfPciAddress)
 }
 
+// RenderSriovConfigServices mocks base method.
+func (m *MockHostManagerInterface) RenderSriovConfigServices(logLevel int) ([]*types.Service, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderSriovConfigServices", logLevel)
+	ret0, _ := ret[0].([]*types.Service)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderSriovConfigServices indicates an expected call of RenderSriovConfigServices.
+func (mr *MockHostManagerInterfaceMockRecorder) RenderSriovConfigServices(logLevel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderSriovConfigServices", reflect.TypeOf((*MockHostManagerInterface)(nil).RenderSriovConfigServices), logLevel)
+}
+
 // ResetSriovDevice mocks base method.
 func (m *MockHostManagerInterface) ResetSriovDevice(ifaceStatus v1.InterfaceExt) error {
 	m.ctrl.T.Helper()
@@ -816,6 +1168,35 @@ func (mr *MockHostManagerInterfaceMockRecorder) SetDevlinkDeviceParam(pciAddr, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDevlinkDeviceParam", reflect.TypeOf((*MockHostManagerInterface)(nil).SetDevlinkDeviceParam), pciAddr, paramName, value)
 }
 
+// SetHugepages mocks base method.
+func (m *MockHostManagerInterface) SetHugepages(numaNode int, size string, count int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHugepages", numaNode, size, count)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetHugepages indicates an expected call of SetHugepages.
+func (mr *MockHostManagerInterfaceMockRecorder) SetHugepages(numaNode, size, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHugepages", reflect.TypeOf((*MockHostManagerInterface)(nil).SetHugepages), numaNode, size, count)
+}
+
+// SetNetDevLinkAdminState mocks base method.
+func (m *MockHostManagerInterface) SetNetDevLinkAdminState(ifaceName, state string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNetDevLinkAdminState", ifaceName, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNetDevLinkAdminState indicates an expected call of SetNetDevLinkAdminState.
+func (mr *MockHostManagerInterfaceMockRecorder) SetNetDevLinkAdminState(ifaceName, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNetDevLinkAdminState", reflect.TypeOf((*MockHostManagerInterface)(nil).SetNetDevLinkAdminState), ifaceName, state)
+}
+
 // SetNetdevMTU mocks base method.
 func (m *MockHostManagerInterface) SetNetdevMTU(pciAddr string, mtu int) error {
 	m.ctrl.T.Helper()
@@ -844,6 +1225,34 @@ func (mr *MockHostManagerInterfaceMockRecorder) SetNicSriovMode(pciAddr, mode in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNicSriovMode", reflect.TypeOf((*MockHostManagerInterface)(nil).SetNicSriovMode), pciAddr, mode)
 }
 
+// SetPfSettings mocks base method.
+func (m *MockHostManagerInterface) SetPfSettings(ifaceName string, settings *v1.PfSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPfSettings", ifaceName, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPfSettings indicates an expected call of SetPfSettings.
+func (mr *MockHostManagerInterfaceMockRecorder) SetPfSettings(ifaceName, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPfSettings", reflect.TypeOf((*MockHostManagerInterface)(nil).SetPfSettings), ifaceName, settings)
+}
+
+// SetRDMASubsystemNetnsMode mocks base method.
+func (m *MockHostManagerInterface) SetRDMASubsystemNetnsMode(mode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRDMASubsystemNetnsMode", mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRDMASubsystemNetnsMode indicates an expected call of SetRDMASubsystemNetnsMode.
+func (mr *MockHostManagerInterfaceMockRecorder) SetRDMASubsystemNetnsMode(mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRDMASubsystemNetnsMode", reflect.TypeOf((*MockHostManagerInterface)(nil).SetRDMASubsystemNetnsMode), mode)
+}
+
 // SetSriovNumVfs mocks base method.
 func (m *MockHostManagerInterface) SetSriovNumVfs(pciAddr string, numVfs int) error {
 	m.ctrl.T.Helper()
@@ -966,6 +1375,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) UnbindDriverIfNeeded(pciAddr, is
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbindDriverIfNeeded", reflect.TypeOf((*MockHostManagerInterface)(nil).UnbindDriverIfNeeded), pciAddr, isRdma)
 }
 
+// UnloadKernelModule mocks base method.
+func (m *MockHostManagerInterface) UnloadKernelModule(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnloadKernelModule", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnloadKernelModule indicates an expected call of UnloadKernelModule.
+func (mr *MockHostManagerInterfaceMockRecorder) UnloadKernelModule(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnloadKernelModule", reflect.TypeOf((*MockHostManagerInterface)(nil).UnloadKernelModule), name)
+}
+
 // UpdateSystemService mocks base method.
 func (m *MockHostManagerInterface) UpdateSystemService(serviceObj *types.Service) error {
 	m.ctrl.T.Helper()
@@ -980,6 +1403,20 @@ func (mr *MockHostManagerInterfaceMockRecorder) UpdateSystemService(serviceObj i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSystemService", reflect.TypeOf((*MockHostManagerInterface)(nil).UpdateSystemService), serviceObj)
 }
 
+// UploadFirmware mocks base method.
+func (m *MockHostManagerInterface) UploadFirmware(pciAddress, fwPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadFirmware", pciAddress, fwPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadFirmware indicates an expected call of UploadFirmware.
+func (mr *MockHostManagerInterfaceMockRecorder) UploadFirmware(pciAddress, fwPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFirmware", reflect.TypeOf((*MockHostManagerInterface)(nil).UploadFirmware), pciAddress, fwPath)
+}
+
 // VFIsReady mocks base method.
 func (m *MockHostManagerInterface) VFIsReady(pciAddr string) (netlink.Link, error) {
 	m.ctrl.T.Helper()
@@ -994,3 +1431,31 @@ func (mr *MockHostManagerInterfaceMockRecorder) VFIsReady(pciAddr interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VFIsReady", reflect.TypeOf((*MockHostManagerInterface)(nil).VFIsReady), pciAddr)
 }
+
+// VerifyDriverBinding mocks base method.
+func (m *MockHostManagerInterface) VerifyDriverBinding(pciAddress, expectedDriver string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyDriverBinding", pciAddress, expectedDriver)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyDriverBinding indicates an expected call of VerifyDriverBinding.
+func (mr *MockHostManagerInterfaceMockRecorder) VerifyDriverBinding(pciAddress, expectedDriver interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyDriverBinding", reflect.TypeOf((*MockHostManagerInterface)(nil).VerifyDriverBinding), pciAddress, expectedDriver)
+}
+
+// WriteModulesLoadConf mocks base method.
+func (m *MockHostManagerInterface) WriteModulesLoadConf(path string, modules []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteModulesLoadConf", path, modules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteModulesLoadConf indicates an expected call of WriteModulesLoadConf.
+func (mr *MockHostManagerInterfaceMockRecorder) WriteModulesLoadConf(path, modules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteModulesLoadConf", reflect.TypeOf((*MockHostManagerInterface)(nil).WriteModulesLoadConf), path, modules)
+}
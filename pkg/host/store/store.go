@@ -10,6 +10,7 @@ import (
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/fileutil"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
@@ -108,7 +109,7 @@ func (s *manager) SaveLastPfAppliedStatus(PfInfo *sriovnetworkv1.Interface) erro
 
 	hostExtension := utils.GetHostExtension()
 	pathFile := filepath.Join(hostExtension, consts.PfAppliedConfig, PfInfo.PciAddress)
-	err = os.WriteFile(pathFile, data, 0644)
+	err = fileutil.WriteFileAtomic(pathFile, data, 0644)
 	return err
 }
 
@@ -167,7 +168,7 @@ func (s *manager) GetCheckPointNodeState() (*sriovnetworkv1.SriovNetworkNodeStat
 
 func (s *manager) WriteCheckpointFile(ns *sriovnetworkv1.SriovNetworkNodeState) error {
 	configdir := filepath.Join(vars.Destdir, consts.CheckpointFileName)
-	file, err := os.OpenFile(configdir, os.O_RDWR|os.O_CREATE, 0644)
+	file, err := os.OpenFile(configdir, os.O_RDONLY|os.O_CREATE, 0644)
 	if err != nil {
 		return err
 	}
@@ -176,13 +177,11 @@ func (s *manager) WriteCheckpointFile(ns *sriovnetworkv1.SriovNetworkNodeState)
 	if err = json.NewDecoder(file).Decode(&sriovnetworkv1.InitialState); err != nil {
 		log.Log.V(2).Error(err, "WriteCheckpointFile(): fail to decode, writing new file instead")
 		log.Log.Info("WriteCheckpointFile(): write checkpoint file")
-		if err = file.Truncate(0); err != nil {
+		data, err := json.Marshal(*ns)
+		if err != nil {
 			return err
 		}
-		if _, err = file.Seek(0, 0); err != nil {
-			return err
-		}
-		if err = json.NewEncoder(file).Encode(*ns); err != nil {
+		if err = fileutil.WriteFileAtomic(configdir, append(data, '\n'), 0644); err != nil {
 			return err
 		}
 		sriovnetworkv1.InitialState = *ns
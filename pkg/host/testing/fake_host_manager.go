@@ -0,0 +1,705 @@
+package testing
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+// ErrInjected is returned by the methods of FakeHostManager which are configured to fail
+var ErrInjected = errors.New("injected failure")
+
+// HostManagerCall contains arguments and return values of a FakeHostManager method call
+type HostManagerCall struct {
+	Method  string
+	Args    []interface{}
+	Returns []interface{}
+}
+
+// FakeHostManager is a HostManagerInterface implementation for unit-tests which doesn't touch the host.
+// All the methods return zero values, methods which return an error can be configured to fail
+// after a number of successful calls.
+type FakeHostManager struct {
+	// FailAfterNth contains names of the methods which succeed for the first N calls and fail afterwards
+	FailAfterNth map[string]int
+	// Err is returned by the failing methods, ErrInjected is used if not set
+	Err error
+	// CallLog contains all the calls of the methods in the order they were made
+	CallLog []HostManagerCall
+
+	lock  sync.Mutex
+	calls map[string]int
+}
+
+var _ host.HostManagerInterface = &FakeHostManager{}
+
+// NewFakeHostManager returns a FakeHostManager which fails the methods from failAfterNth
+func NewFakeHostManager(failAfterNth map[string]int) *FakeHostManager {
+	return &FakeHostManager{FailAfterNth: failAfterNth}
+}
+
+// Calls returns the calls of the method in the order they were made
+func (f *FakeHostManager) Calls(method string) []HostManagerCall {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	calls := []HostManagerCall{}
+	for _, c := range f.CallLog {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// returns an error if the method has already been called the configured number of times
+func (f *FakeHostManager) injectError(method string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[method]++
+	n, ok := f.FailAfterNth[method]
+	if !ok || f.calls[method] <= n {
+		return nil
+	}
+	if f.Err != nil {
+		return f.Err
+	}
+	return ErrInjected
+}
+
+func (f *FakeHostManager) record(method string, args []interface{}, returns ...interface{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.CallLog = append(f.CallLog, HostManagerCall{Method: method, Args: args, Returns: returns})
+}
+
+func (f *FakeHostManager) AddDisableNMUdevRule(pfPciAddress string) error {
+	err := f.injectError("AddDisableNMUdevRule")
+	f.record("AddDisableNMUdevRule", []interface{}{pfPciAddress}, err)
+	return err
+}
+
+func (f *FakeHostManager) AddPersistPFNameUdevRule(pfPciAddress, pfName string) error {
+	err := f.injectError("AddPersistPFNameUdevRule")
+	f.record("AddPersistPFNameUdevRule", []interface{}{pfPciAddress, pfName}, err)
+	return err
+}
+
+func (f *FakeHostManager) AddVfRepresentorUdevRule(pfPciAddress, pfName, pfSwitchID, pfSwitchPort string) error {
+	err := f.injectError("AddVfRepresentorUdevRule")
+	f.record("AddVfRepresentorUdevRule", []interface{}{pfPciAddress, pfName, pfSwitchID, pfSwitchPort}, err)
+	return err
+}
+
+func (f *FakeHostManager) BindDefaultDriver(pciAddr string) error {
+	err := f.injectError("BindDefaultDriver")
+	f.record("BindDefaultDriver", []interface{}{pciAddr}, err)
+	return err
+}
+
+func (f *FakeHostManager) BindDpdkDriver(pciAddr, driver string) error {
+	err := f.injectError("BindDpdkDriver")
+	f.record("BindDpdkDriver", []interface{}{pciAddr, driver}, err)
+	return err
+}
+
+func (f *FakeHostManager) BindDriverByBusAndDevice(bus, device, driver string) error {
+	err := f.injectError("BindDriverByBusAndDevice")
+	f.record("BindDriverByBusAndDevice", []interface{}{bus, device, driver}, err)
+	return err
+}
+
+func (f *FakeHostManager) CheckRDMAEnabled() (bool, error) {
+	var r bool
+	err := f.injectError("CheckRDMAEnabled")
+	f.record("CheckRDMAEnabled", nil, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) CompareServices(serviceA, serviceB *types.Service) (bool, error) {
+	var r bool
+	err := f.injectError("CompareServices")
+	f.record("CompareServices", []interface{}{serviceA, serviceB}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) ConfigSriovDeviceVirtual(iface *sriovnetworkv1.Interface) error {
+	err := f.injectError("ConfigSriovDeviceVirtual")
+	f.record("ConfigSriovDeviceVirtual", []interface{}{iface}, err)
+	return err
+}
+
+func (f *FakeHostManager) ConfigSriovInterfaces(storeManager store.ManagerInterface, interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt, skipVFConfiguration bool) error {
+	err := f.injectError("ConfigSriovInterfaces")
+	f.record("ConfigSriovInterfaces", []interface{}{storeManager, interfaces, ifaceStatuses, skipVFConfiguration}, err)
+	return err
+}
+
+func (f *FakeHostManager) ConfigureBridges(bridgesSpec, bridgesStatus sriovnetworkv1.Bridges) error {
+	err := f.injectError("ConfigureBridges")
+	f.record("ConfigureBridges", []interface{}{bridgesSpec, bridgesStatus}, err)
+	return err
+}
+
+func (f *FakeHostManager) ConfigureEncapOffload(ifaceName string, vxlan, geneve bool) error {
+	err := f.injectError("ConfigureEncapOffload")
+	f.record("ConfigureEncapOffload", []interface{}{ifaceName, vxlan, geneve}, err)
+	return err
+}
+
+func (f *FakeHostManager) ConfigureModprobeBlacklist(vfioDeviceIDs, pfDrivers []string) error {
+	err := f.injectError("ConfigureModprobeBlacklist")
+	f.record("ConfigureModprobeBlacklist", []interface{}{vfioDeviceIDs, pfDrivers}, err)
+	return err
+}
+
+func (f *FakeHostManager) ConfigureVfGUID(vfAddr, pfAddr string, vfID int, pfLink netlink.Link) error {
+	err := f.injectError("ConfigureVfGUID")
+	f.record("ConfigureVfGUID", []interface{}{vfAddr, pfAddr, vfID, pfLink}, err)
+	return err
+}
+
+func (f *FakeHostManager) ConfigureVfQoS(pfName string, vfID int, dscp, egressBandwidthMbps int32) error {
+	err := f.injectError("ConfigureVfQoS")
+	f.record("ConfigureVfQoS", []interface{}{pfName, vfID, dscp, egressBandwidthMbps}, err)
+	return err
+}
+
+func (f *FakeHostManager) CreateCharDevice(major, minor uint32, path string) error {
+	err := f.injectError("CreateCharDevice")
+	f.record("CreateCharDevice", []interface{}{major, minor, path}, err)
+	return err
+}
+
+func (f *FakeHostManager) CreateVDPADevice(pciAddr, vdpaType string) error {
+	err := f.injectError("CreateVDPADevice")
+	f.record("CreateVDPADevice", []interface{}{pciAddr, vdpaType}, err)
+	return err
+}
+
+func (f *FakeHostManager) DeleteVDPADevice(pciAddr string) error {
+	err := f.injectError("DeleteVDPADevice")
+	f.record("DeleteVDPADevice", []interface{}{pciAddr}, err)
+	return err
+}
+
+func (f *FakeHostManager) DetachInterfaceFromManagedBridge(pciAddr string) error {
+	err := f.injectError("DetachInterfaceFromManagedBridge")
+	f.record("DetachInterfaceFromManagedBridge", []interface{}{pciAddr}, err)
+	return err
+}
+
+func (f *FakeHostManager) DiscoverBridges() (sriovnetworkv1.Bridges, error) {
+	var r sriovnetworkv1.Bridges
+	err := f.injectError("DiscoverBridges")
+	f.record("DiscoverBridges", nil, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) DiscoverSriovDevices(storeManager store.ManagerInterface) ([]sriovnetworkv1.InterfaceExt, error) {
+	var r []sriovnetworkv1.InterfaceExt
+	err := f.injectError("DiscoverSriovDevices")
+	f.record("DiscoverSriovDevices", []interface{}{storeManager}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) DiscoverVDPAType(pciAddr string) string {
+	var r string
+	f.record("DiscoverVDPAType", []interface{}{pciAddr}, r)
+	return r
+}
+
+func (f *FakeHostManager) EnableHwTcOffload(ifaceName string) error {
+	err := f.injectError("EnableHwTcOffload")
+	f.record("EnableHwTcOffload", []interface{}{ifaceName}, err)
+	return err
+}
+
+func (f *FakeHostManager) EnableService(service *types.Service) error {
+	err := f.injectError("EnableService")
+	f.record("EnableService", []interface{}{service}, err)
+	return err
+}
+
+func (f *FakeHostManager) DisableService(service *types.Service) error {
+	err := f.injectError("DisableService")
+	f.record("DisableService", []interface{}{service}, err)
+	return err
+}
+
+func (f *FakeHostManager) EnsureVhostNet() error {
+	err := f.injectError("EnsureVhostNet")
+	f.record("EnsureVhostNet", nil, err)
+	return err
+}
+
+func (f *FakeHostManager) GetArchitecture() string {
+	var r string
+	f.record("GetArchitecture", nil, r)
+	return r
+}
+
+func (f *FakeHostManager) GetCurrentKernelArgs() (string, error) {
+	var r string
+	err := f.injectError("GetCurrentKernelArgs")
+	f.record("GetCurrentKernelArgs", nil, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetDeviceNumaNode(pciAddr string) (int, error) {
+	var r int
+	err := f.injectError("GetDeviceNumaNode")
+	f.record("GetDeviceNumaNode", []interface{}{pciAddr}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetDevlinkDeviceParam(pciAddr, paramName string) (string, error) {
+	var r string
+	err := f.injectError("GetDevlinkDeviceParam")
+	f.record("GetDevlinkDeviceParam", []interface{}{pciAddr, paramName}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetDistroInfo() (*types.DistroInfo, error) {
+	var r *types.DistroInfo
+	err := f.injectError("GetDistroInfo")
+	f.record("GetDistroInfo", nil, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetDriverByBusAndDevice(bus, device string) (string, error) {
+	var r string
+	err := f.injectError("GetDriverByBusAndDevice")
+	f.record("GetDriverByBusAndDevice", []interface{}{bus, device}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetEncapOffloadState(ifaceName string) (bool, bool, error) {
+	var vxlan, geneve bool
+	err := f.injectError("GetEncapOffloadState")
+	f.record("GetEncapOffloadState", []interface{}{ifaceName}, vxlan, geneve, err)
+	return vxlan, geneve, err
+}
+
+func (f *FakeHostManager) GetHostFacts() (*sriovnetworkv1.HostFacts, error) {
+	var r *sriovnetworkv1.HostFacts
+	err := f.injectError("GetHostFacts")
+	f.record("GetHostFacts", nil, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetInterfaceIndex(pciAddr string) (int, error) {
+	var r int
+	err := f.injectError("GetInterfaceIndex")
+	f.record("GetInterfaceIndex", []interface{}{pciAddr}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetKernelArgsBackend() (string, error) {
+	var r string
+	err := f.injectError("GetKernelArgsBackend")
+	f.record("GetKernelArgsBackend", nil, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetKernelVersion() (string, error) {
+	var r string
+	err := f.injectError("GetKernelVersion")
+	f.record("GetKernelVersion", nil, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetLinkType(name string) string {
+	var r string
+	f.record("GetLinkType", []interface{}{name}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetNetDevBondMaster(name string) string {
+	var r string
+	f.record("GetNetDevBondMaster", []interface{}{name}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetNetDevFirmwareVersion(name string) string {
+	var r string
+	f.record("GetNetDevFirmwareVersion", []interface{}{name}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetNetDevLinkAdminState(ifaceName string) string {
+	var r string
+	f.record("GetNetDevLinkAdminState", []interface{}{ifaceName}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetNetDevLinkSpeed(name string) string {
+	var r string
+	f.record("GetNetDevLinkSpeed", []interface{}{name}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetNetDevMac(name string) string {
+	var r string
+	f.record("GetNetDevMac", []interface{}{name}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetNetDevNodeGUID(pciAddr string) string {
+	var r string
+	f.record("GetNetDevNodeGUID", []interface{}{pciAddr}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetNetdevMTU(pciAddr string) int {
+	var r int
+	f.record("GetNetdevMTU", []interface{}{pciAddr}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetNicSriovMode(pciAddr string) string {
+	var r string
+	f.record("GetNicSriovMode", []interface{}{pciAddr}, r)
+	return r
+}
+
+func (f *FakeHostManager) GetPciAddressFromInterfaceName(interfaceName string) (string, error) {
+	var r string
+	err := f.injectError("GetPciAddressFromInterfaceName")
+	f.record("GetPciAddressFromInterfaceName", []interface{}{interfaceName}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetPfSettings(ifaceName string) (*sriovnetworkv1.PfSettings, error) {
+	var r *sriovnetworkv1.PfSettings
+	err := f.injectError("GetPfSettings")
+	f.record("GetPfSettings", []interface{}{ifaceName}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetPhysPortName(name string) (string, error) {
+	var r string
+	err := f.injectError("GetPhysPortName")
+	f.record("GetPhysPortName", []interface{}{name}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetPhysSwitchID(name string) (string, error) {
+	var r string
+	err := f.injectError("GetPhysSwitchID")
+	f.record("GetPhysSwitchID", []interface{}{name}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetRDMASubsystemNetnsMode() (string, error) {
+	var r string
+	err := f.injectError("GetRDMASubsystemNetnsMode")
+	f.record("GetRDMASubsystemNetnsMode", nil, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetVFRSSConfig(pfName string, vfID int) (*types.RSSConfig, error) {
+	var r *types.RSSConfig
+	err := f.injectError("GetVFRSSConfig")
+	f.record("GetVFRSSConfig", []interface{}{pfName, vfID}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) GetVDPADeviceName(pciAddr string) string {
+	var r string
+	f.record("GetVDPADeviceName", []interface{}{pciAddr}, r)
+	return r
+}
+
+func (f *FakeHostManager) HasDriver(pciAddr string) (bool, string) {
+	var r0 bool
+	var r1 string
+	f.record("HasDriver", []interface{}{pciAddr}, r0, r1)
+	return r0, r1
+}
+
+func (f *FakeHostManager) IsDevlinkDeviceParamPermanent(pciAddr, paramName string) (bool, error) {
+	var r bool
+	err := f.injectError("IsDevlinkDeviceParamPermanent")
+	f.record("IsDevlinkDeviceParamPermanent", []interface{}{pciAddr, paramName}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) IsKernelArgsSet(cmdLine, karg string) bool {
+	var r bool
+	f.record("IsKernelArgsSet", []interface{}{cmdLine, karg}, r)
+	return r
+}
+
+func (f *FakeHostManager) IsKernelLockdownMode() bool {
+	var r bool
+	f.record("IsKernelLockdownMode", nil, r)
+	return r
+}
+
+func (f *FakeHostManager) IsKernelModuleLoaded(name string) (bool, error) {
+	var r bool
+	err := f.injectError("IsKernelModuleLoaded")
+	f.record("IsKernelModuleLoaded", []interface{}{name}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) IsPartOfBond(pciAddress string) (bool, string, error) {
+	err := f.injectError("IsPartOfBond")
+	f.record("IsPartOfBond", []interface{}{pciAddress}, false, "", err)
+	return false, "", err
+}
+
+func (f *FakeHostManager) IsServiceEnabled(servicePath string) (bool, error) {
+	var r bool
+	err := f.injectError("IsServiceEnabled")
+	f.record("IsServiceEnabled", []interface{}{servicePath}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) IsServiceExist(servicePath string) (bool, error) {
+	var r bool
+	err := f.injectError("IsServiceExist")
+	f.record("IsServiceExist", []interface{}{servicePath}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) IsSwitchdev(name string) bool {
+	var r bool
+	f.record("IsSwitchdev", []interface{}{name}, r)
+	return r
+}
+
+func (f *FakeHostManager) ListVFInterrupts(pciAddress string) ([]int, error) {
+	var r []int
+	err := f.injectError("ListVFInterrupts")
+	f.record("ListVFInterrupts", []interface{}{pciAddress}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) LoadKernelModule(name string, args ...string) error {
+	err := f.injectError("LoadKernelModule")
+	f.record("LoadKernelModule", []interface{}{name, args}, err)
+	return err
+}
+
+func (f *FakeHostManager) LoadUdevRules() error {
+	err := f.injectError("LoadUdevRules")
+	f.record("LoadUdevRules", nil, err)
+	return err
+}
+
+func (f *FakeHostManager) PrepareNMUdevRule(supportedVfIds []string) error {
+	err := f.injectError("PrepareNMUdevRule")
+	f.record("PrepareNMUdevRule", []interface{}{supportedVfIds}, err)
+	return err
+}
+
+func (f *FakeHostManager) PrepareVFRepUdevRule() error {
+	err := f.injectError("PrepareVFRepUdevRule")
+	f.record("PrepareVFRepUdevRule", nil, err)
+	return err
+}
+
+func (f *FakeHostManager) ReadService(servicePath string) (*types.Service, error) {
+	var r *types.Service
+	err := f.injectError("ReadService")
+	f.record("ReadService", []interface{}{servicePath}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) ReadServiceInjectionManifestFile(path string) (*types.Service, error) {
+	var r *types.Service
+	err := f.injectError("ReadServiceInjectionManifestFile")
+	f.record("ReadServiceInjectionManifestFile", []interface{}{path}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) ReadServiceManifestFile(path string) (*types.Service, error) {
+	var r *types.Service
+	err := f.injectError("ReadServiceManifestFile")
+	f.record("ReadServiceManifestFile", []interface{}{path}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) RebindVfToDefaultDriver(pciAddr string) error {
+	err := f.injectError("RebindVfToDefaultDriver")
+	f.record("RebindVfToDefaultDriver", []interface{}{pciAddr}, err)
+	return err
+}
+
+func (f *FakeHostManager) ReconcileVfAttributes(iface *sriovnetworkv1.Interface) ([]types.VfAttributeCorrection, error) {
+	var r []types.VfAttributeCorrection
+	err := f.injectError("ReconcileVfAttributes")
+	f.record("ReconcileVfAttributes", []interface{}{iface}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) RemoveDisableNMUdevRule(pfPciAddress string) error {
+	err := f.injectError("RemoveDisableNMUdevRule")
+	f.record("RemoveDisableNMUdevRule", []interface{}{pfPciAddress}, err)
+	return err
+}
+
+func (f *FakeHostManager) RemovePersistPFNameUdevRule(pfPciAddress string) error {
+	err := f.injectError("RemovePersistPFNameUdevRule")
+	f.record("RemovePersistPFNameUdevRule", []interface{}{pfPciAddress}, err)
+	return err
+}
+
+func (f *FakeHostManager) RemoveVfRepresentorUdevRule(pfPciAddress string) error {
+	err := f.injectError("RemoveVfRepresentorUdevRule")
+	f.record("RemoveVfRepresentorUdevRule", []interface{}{pfPciAddress}, err)
+	return err
+}
+
+func (f *FakeHostManager) RenderSriovConfigServices(logLevel int) ([]*types.Service, error) {
+	var r []*types.Service
+	err := f.injectError("RenderSriovConfigServices")
+	f.record("RenderSriovConfigServices", []interface{}{logLevel}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) ResetSriovDevice(ifaceStatus sriovnetworkv1.InterfaceExt) error {
+	err := f.injectError("ResetSriovDevice")
+	f.record("ResetSriovDevice", []interface{}{ifaceStatus}, err)
+	return err
+}
+
+func (f *FakeHostManager) SetDevlinkDeviceParam(pciAddr, paramName, value string) error {
+	err := f.injectError("SetDevlinkDeviceParam")
+	f.record("SetDevlinkDeviceParam", []interface{}{pciAddr, paramName, value}, err)
+	return err
+}
+
+func (f *FakeHostManager) SetHugepages(numaNode int, size string, count int) (int, error) {
+	var r int
+	err := f.injectError("SetHugepages")
+	f.record("SetHugepages", []interface{}{numaNode, size, count}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) SetNetDevLinkAdminState(ifaceName, state string) error {
+	err := f.injectError("SetNetDevLinkAdminState")
+	f.record("SetNetDevLinkAdminState", []interface{}{ifaceName, state}, err)
+	return err
+}
+
+func (f *FakeHostManager) SetNetdevMTU(pciAddr string, mtu int) error {
+	err := f.injectError("SetNetdevMTU")
+	f.record("SetNetdevMTU", []interface{}{pciAddr, mtu}, err)
+	return err
+}
+
+func (f *FakeHostManager) SetNicSriovMode(pciAddr, mode string) error {
+	err := f.injectError("SetNicSriovMode")
+	f.record("SetNicSriovMode", []interface{}{pciAddr, mode}, err)
+	return err
+}
+
+func (f *FakeHostManager) SetPfSettings(ifaceName string, settings *sriovnetworkv1.PfSettings) error {
+	err := f.injectError("SetPfSettings")
+	f.record("SetPfSettings", []interface{}{ifaceName, settings}, err)
+	return err
+}
+
+func (f *FakeHostManager) SetRDMASubsystemNetnsMode(mode string) error {
+	err := f.injectError("SetRDMASubsystemNetnsMode")
+	f.record("SetRDMASubsystemNetnsMode", []interface{}{mode}, err)
+	return err
+}
+
+func (f *FakeHostManager) SetSriovNumVfs(pciAddr string, numVfs int) error {
+	err := f.injectError("SetSriovNumVfs")
+	f.record("SetSriovNumVfs", []interface{}{pciAddr, numVfs}, err)
+	return err
+}
+
+func (f *FakeHostManager) SetVfAdminMac(vfAddr string, pfLink, vfLink netlink.Link) error {
+	err := f.injectError("SetVfAdminMac")
+	f.record("SetVfAdminMac", []interface{}{vfAddr, pfLink, vfLink}, err)
+	return err
+}
+
+func (f *FakeHostManager) TryEnableTun() {
+	f.record("TryEnableTun", nil)
+}
+
+func (f *FakeHostManager) TryEnableVhostNet() {
+	f.record("TryEnableVhostNet", nil)
+}
+
+func (f *FakeHostManager) TryGetInterfaceName(pciAddr string) string {
+	var r string
+	f.record("TryGetInterfaceName", []interface{}{pciAddr}, r)
+	return r
+}
+
+func (f *FakeHostManager) TryToGetVirtualInterfaceName(pciAddr string) string {
+	var r string
+	f.record("TryToGetVirtualInterfaceName", []interface{}{pciAddr}, r)
+	return r
+}
+
+func (f *FakeHostManager) Unbind(pciAddr string) error {
+	err := f.injectError("Unbind")
+	f.record("Unbind", []interface{}{pciAddr}, err)
+	return err
+}
+
+func (f *FakeHostManager) UnbindDriverByBusAndDevice(bus, device string) error {
+	err := f.injectError("UnbindDriverByBusAndDevice")
+	f.record("UnbindDriverByBusAndDevice", []interface{}{bus, device}, err)
+	return err
+}
+
+func (f *FakeHostManager) UnbindDriverIfNeeded(pciAddr string, isRdma bool) error {
+	err := f.injectError("UnbindDriverIfNeeded")
+	f.record("UnbindDriverIfNeeded", []interface{}{pciAddr, isRdma}, err)
+	return err
+}
+
+func (f *FakeHostManager) UnloadKernelModule(name string) error {
+	err := f.injectError("UnloadKernelModule")
+	f.record("UnloadKernelModule", []interface{}{name}, err)
+	return err
+}
+
+func (f *FakeHostManager) UpdateSystemService(serviceObj *types.Service) error {
+	err := f.injectError("UpdateSystemService")
+	f.record("UpdateSystemService", []interface{}{serviceObj}, err)
+	return err
+}
+
+func (f *FakeHostManager) UploadFirmware(pciAddress, fwPath string) error {
+	err := f.injectError("UploadFirmware")
+	f.record("UploadFirmware", []interface{}{pciAddress, fwPath}, err)
+	return err
+}
+
+func (f *FakeHostManager) VFIsReady(pciAddr string) (netlink.Link, error) {
+	var r netlink.Link
+	err := f.injectError("VFIsReady")
+	f.record("VFIsReady", []interface{}{pciAddr}, r, err)
+	return r, err
+}
+
+func (f *FakeHostManager) VerifyDriverBinding(pciAddress, expectedDriver string) error {
+	err := f.injectError("VerifyDriverBinding")
+	f.record("VerifyDriverBinding", []interface{}{pciAddress, expectedDriver}, err)
+	return err
+}
+
+func (f *FakeHostManager) WriteModulesLoadConf(path string, modules []string) error {
+	err := f.injectError("WriteModulesLoadConf")
+	f.record("WriteModulesLoadConf", []interface{}{path, modules}, err)
+	return err
+}
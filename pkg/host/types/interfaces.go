@@ -12,10 +12,14 @@ type KernelInterface interface {
 	TryEnableTun()
 	// TryEnableVhostNet load the vhost-net kernel module
 	TryEnableVhostNet()
+	// EnsureVhostNet loads the tun and vhost_net kernel modules and checks that the device nodes exist
+	EnsureVhostNet() error
 	// CheckRDMAEnabled returns true if RDMA modules are loaded on host
 	CheckRDMAEnabled() (bool, error)
 	// GetCurrentKernelArgs reads the /proc/cmdline to check the current kernel arguments
 	GetCurrentKernelArgs() (string, error)
+	// GetKernelVersion returns the release of the running kernel, e.g. 5.14.0-427.13.1.el9_4.x86_64
+	GetKernelVersion() (string, error)
 	// IsKernelArgsSet check is the requested kernel arguments are set
 	IsKernelArgsSet(cmdLine, karg string) bool
 	// Unbind unbinds a virtual function from is current driver
@@ -36,6 +40,8 @@ type KernelInterface interface {
 	// bus - the bus path in the sysfs, e.g. "pci" or "vdpa"
 	// device - the name of the device on the bus, e.g. 0000:85:1e.5 for PCI or vpda1 for VDPA
 	GetDriverByBusAndDevice(bus, device string) (string, error)
+	// VerifyDriverBinding returns an error if the PCI device is not bound to the expected driver
+	VerifyDriverBinding(pciAddress, expectedDriver string) error
 	// RebindVfToDefaultDriver rebinds the virtual function to is default driver
 	RebindVfToDefaultDriver(pciAddr string) error
 	// UnbindDriverByBusAndDevice unbind device identified by bus and device ID from the driver
@@ -46,10 +52,36 @@ type KernelInterface interface {
 	UnbindDriverIfNeeded(pciAddr string, isRdma bool) error
 	// LoadKernelModule loads a kernel module to the host
 	LoadKernelModule(name string, args ...string) error
+	// UnloadKernelModule unloads a kernel module from the host, nothing is done if the module is not loaded
+	UnloadKernelModule(name string) error
 	// IsKernelModuleLoaded returns try if the requested kernel module is loaded
 	IsKernelModuleLoaded(name string) (bool, error)
 	// IsKernelLockdownMode returns true if the kernel is in lockdown mode
 	IsKernelLockdownMode() bool
+	// WriteModulesLoadConf writes the list of kernel modules to the modules-load.d configuration file
+	// on the host, the file is removed if the list is empty
+	WriteModulesLoadConf(path string, modules []string) error
+	// ConfigureModprobeBlacklist configures modprobe on the host to make vfio-pci claim the devices
+	// with the provided IDs (in vendor:device format) before the kernel drivers of the PFs are loaded.
+	// The configuration is removed if no device IDs are provided. Initramfs is regenerated on changes.
+	ConfigureModprobeBlacklist(vfioDeviceIDs, pfDrivers []string) error
+	// GetArchitecture returns the CPU architecture of the host, e.g. amd64 or arm64
+	GetArchitecture() string
+	// GetDeviceNumaNode returns the NUMA node of the PCI device, -1 is returned if the device has no NUMA affinity
+	GetDeviceNumaNode(pciAddr string) (int, error)
+	// ListVFInterrupts returns the numbers of the IRQs of the VF from /proc/interrupts, the VF has no IRQs
+	// if its driver didn't request them (e.g. vfio-pci VFs not used by an application)
+	ListVFInterrupts(pciAddress string) ([]int, error)
+	// SetHugepages allocates hugepages of the provided size (e.g. 1Gi) on the NUMA node, the hugepages are
+	// allocated without NUMA affinity if numaNode is negative. Allocated hugepages are never released.
+	// Returns the number of hugepages available after the allocation.
+	SetHugepages(numaNode int, size string, count int) (int, error)
+	// CreateCharDevice creates the character device node with the provided major and minor numbers
+	// on the host, an existing node with other device numbers is replaced
+	CreateCharDevice(major, minor uint32, path string) error
+	// UploadFirmware flashes the firmware file to the PCI device with devlink, the path is relative
+	// to the firmware directory of the host
+	UploadFirmware(pciAddress, fwPath string) error
 }
 
 type NetworkInterface interface {
@@ -77,6 +109,14 @@ type NetworkInterface interface {
 	GetNetDevNodeGUID(pciAddr string) string
 	// GetNetDevLinkSpeed returns the network interface link speed
 	GetNetDevLinkSpeed(name string) string
+	// GetNetDevFirmwareVersion returns the firmware version of the network interface
+	GetNetDevFirmwareVersion(name string) string
+	// GetNetDevBondMaster returns the name of the bond the network interface is enslaved to,
+	// empty string if the interface has no master or if its master is not a bond
+	GetNetDevBondMaster(name string) string
+	// IsPartOfBond returns true and the name of the master if the netdevice of the PF with the PCI address
+	// is enslaved to a bond or to a team, false if the PF has no netdevice or if its master is of another kind
+	IsPartOfBond(pciAddress string) (bool, string, error)
 	// GetDevlinkDeviceParam returns devlink parameter for the device as a string, if the parameter has multiple values
 	// then the function will return only first one from the list.
 	GetDevlinkDeviceParam(pciAddr, paramName string) (string, error)
@@ -84,12 +124,37 @@ type NetworkInterface interface {
 	// as a string. Automatically set CMODE for the parameter and converts the value to the right
 	// type before submitting it.
 	SetDevlinkDeviceParam(pciAddr, paramName, value string) error
+	// IsDevlinkDeviceParamPermanent returns true if the devlink parameter of the device is set in the permanent
+	// configuration mode, the value is stored by the firmware and applied after a firmware reset
+	IsDevlinkDeviceParamPermanent(pciAddr, paramName string) (bool, error)
+	// GetRDMASubsystemNetnsMode returns the network namespace mode of the RDMA subsystem, shared or exclusive
+	GetRDMASubsystemNetnsMode() (string, error)
+	// SetRDMASubsystemNetnsMode sets the network namespace mode of the RDMA subsystem and verifies that
+	// the mode was applied, the mode can't be changed while RDMA devices are used in other network namespaces
+	SetRDMASubsystemNetnsMode(mode string) error
 	// EnableHwTcOffload make sure that hw-tc-offload feature is enabled if device supports it
 	EnableHwTcOffload(ifaceName string) error
+	// ConfigureEncapOffload enables or disables the VXLAN and Geneve segmentation offload of the interface
+	ConfigureEncapOffload(ifaceName string, vxlan, geneve bool) error
+	// GetEncapOffloadState returns the VXLAN and Geneve segmentation offload state of the interface
+	GetEncapOffloadState(ifaceName string) (vxlan, geneve bool, err error)
+	// GetPfSettings returns the promiscuous mode, the all-multicast mode and the state of the whitelisted
+	// ethtool features supported by the interface
+	GetPfSettings(ifaceName string) (*sriovnetworkv1.PfSettings, error)
+	// SetPfSettings applies the settings which are set and verifies that the interface uses them
+	SetPfSettings(ifaceName string, settings *sriovnetworkv1.PfSettings) error
 	// GetNetDevLinkAdminState returns the admin state of the interface.
 	GetNetDevLinkAdminState(ifaceName string) string
+	// SetNetDevLinkAdminState sets the admin state of the interface to "up" or "down"
+	SetNetDevLinkAdminState(ifaceName string, state string) error
 	// GetPciAddressFromInterfaceName parses sysfs to get pci address of an interface by name
 	GetPciAddressFromInterfaceName(interfaceName string) (string, error)
+	// ConfigureVfQoS configures DSCP marking and egress shaping of the traffic sent by the VF netdevice,
+	// negative dscp disables DSCP marking and zero egressBandwidthMbps disables egress shaping
+	ConfigureVfQoS(pfName string, vfID int, dscp int32, egressBandwidthMbps int32) error
+	// GetVFRSSConfig returns the RSS hash key and hash fields of the VF netdevice,
+	// nil if the VF has no netdevice in the host network namespace
+	GetVFRSSConfig(pfName string, vfID int) (*RSSConfig, error)
 }
 
 type ServiceInterface interface {
@@ -101,6 +166,9 @@ type ServiceInterface interface {
 	ReadService(servicePath string) (*Service, error)
 	// EnableService enables a systemd server on the host
 	EnableService(service *Service) error
+	// DisableService disables a systemd service on the host and removes its file, nothing is done
+	// if the service doesn't exist
+	DisableService(service *Service) error
 	// ReadServiceManifestFile reads the systemd manifest for a specific service
 	ReadServiceManifestFile(path string) (*Service, error)
 	// ReadServiceInjectionManifestFile reads the injection manifest file for the systemd service
@@ -109,6 +177,8 @@ type ServiceInterface interface {
 	CompareServices(serviceA, serviceB *Service) (bool, error)
 	// UpdateSystemService updates a system service on the host
 	UpdateSystemService(serviceObj *Service) error
+	// RenderSriovConfigServices renders the pre and post network sriov-config systemd services
+	RenderSriovConfigServices(logLevel int) ([]*Service, error)
 }
 
 type SriovInterface interface {
@@ -118,6 +188,7 @@ type SriovInterface interface {
 	// VFIsReady returns the interface virtual function if the device is ready
 	VFIsReady(pciAddr string) (netlink.Link, error)
 	// SetVfAdminMac sets the virtual function administrative mac address via the physical function
+	// and the hardware address of the virtual function netdevice
 	SetVfAdminMac(vfAddr string, pfLink netlink.Link, vfLink netlink.Link) error
 	// GetNicSriovMode returns the interface mode
 	// supported modes SR-IOV legacy and switchdev
@@ -138,6 +209,10 @@ type SriovInterface interface {
 		ifaceStatuses []sriovnetworkv1.InterfaceExt, skipVFConfiguration bool) error
 	// ConfigSriovInterfaces configure virtual functions for virtual environments with the desired configuration
 	ConfigSriovDeviceVirtual(iface *sriovnetworkv1.Interface) error
+	// ReconcileVfAttributes reads the trust mode, the spoof check, the tx rate and the VLAN of the VFs of the PF
+	// and reapplies the ones which differ from the VF groups of the interface, the attributes which are not
+	// requested by the group of a VF are not changed. The reapplied attributes are returned.
+	ReconcileVfAttributes(iface *sriovnetworkv1.Interface) ([]VfAttributeCorrection, error)
 }
 
 type UdevInterface interface {
@@ -170,6 +245,8 @@ type VdpaInterface interface {
 	// DiscoverVDPAType returns type of existing VDPA device for VF,
 	// returns empty string if VDPA device not found or unknown driver is in use
 	DiscoverVDPAType(pciAddr string) string
+	// GetVDPADeviceName returns the name of the VDPA device which is (or will be) created for the VF
+	GetVDPADeviceName(pciAddr string) string
 }
 
 type BridgeInterface interface {
@@ -183,6 +260,19 @@ type BridgeInterface interface {
 	DetachInterfaceFromManagedBridge(pciAddr string) error
 }
 
+type FactsInterface interface {
+	// GetHostFacts returns hardware facts of the host, the facts are collected once and cached
+	GetHostFacts() (*sriovnetworkv1.HostFacts, error)
+}
+
+type DistroInterface interface {
+	// GetDistroInfo returns information about the OS distribution of the host
+	GetDistroInfo() (*DistroInfo, error)
+	// GetKernelArgsBackend returns the tool which should be used to manage kernel arguments on the host,
+	// grubby is used if the distribution is not known or can't be detected
+	GetKernelArgsBackend() (string, error)
+}
+
 type InfinibandInterface interface {
 	// ConfigureVfGUID configures and sets a GUID for an IB VF device
 	ConfigureVfGUID(vfAddr string, pfAddr string, vfID int, pfLink netlink.Link) error
@@ -1,5 +1,235 @@
 package types
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrEswitchModeNotSupported is returned when the NIC doesn't support the requested eSwitch mode
+	ErrEswitchModeNotSupported = errors.New("eSwitch mode is not supported by the device")
+	// ErrEswitchModeVFsBound is returned when the eSwitch mode can't be changed because VFs are bound to a driver
+	ErrEswitchModeVFsBound = errors.New("eSwitch mode can't be changed while VFs are bound to a driver")
+	// ErrDriverNotFound is returned when a device is bound to a driver which is not loaded
+	ErrDriverNotFound = errors.New("driver is not loaded")
+)
+
+// InterfaceSyncError is returned when the configuration of the PF fails
+type InterfaceSyncError struct {
+	// PciAddress of the PF
+	PciAddress string
+	Err        error
+}
+
+func (e *InterfaceSyncError) Error() string {
+	return fmt.Sprintf("%s: %v", e.PciAddress, e.Err)
+}
+
+func (e *InterfaceSyncError) Unwrap() error {
+	return e.Err
+}
+
+// GetInterfaceSyncErrors returns the errors of the PFs contained in err, the errors joined with errors.Join are included
+func GetInterfaceSyncErrors(err error) []*InterfaceSyncError {
+	switch e := err.(type) {
+	case *InterfaceSyncError:
+		return []*InterfaceSyncError{e}
+	case interface{ Unwrap() []error }:
+		var result []*InterfaceSyncError
+		for _, joined := range e.Unwrap() {
+			result = append(result, GetInterfaceSyncErrors(joined)...)
+		}
+		return result
+	case interface{ Unwrap() error }:
+		return GetInterfaceSyncErrors(e.Unwrap())
+	}
+	return nil
+}
+
+// MissingKernelModuleError is returned when the device can't be bound to its default driver because
+// the kernel module of the driver is not available or the driver of the device is unknown
+type MissingKernelModuleError struct {
+	// PciAddress of the device
+	PciAddress string
+	// Module is the kernel module of the expected driver of the device, empty if unknown
+	Module string
+	Err    error
+}
+
+func (e *MissingKernelModuleError) Error() string {
+	if e.Module == "" {
+		return fmt.Sprintf("device %s has no driver: %v", e.PciAddress, e.Err)
+	}
+	return fmt.Sprintf("device %s has no driver, kernel module %s is not available: %v", e.PciAddress, e.Module, e.Err)
+}
+
+func (e *MissingKernelModuleError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports the error as ErrDriverNotFound, the other devices of the same kind fail the same way
+func (e *MissingKernelModuleError) Is(target error) bool {
+	return target == ErrDriverNotFound
+}
+
+// DevlinkParamError is returned when a devlink parameter of the PF can't be set to the requested value
+type DevlinkParamError struct {
+	// Param is the name of the devlink parameter
+	Param string
+	// Value is the requested value of the parameter
+	Value string
+	Err   error
+}
+
+func (e *DevlinkParamError) Error() string {
+	return fmt.Sprintf("failed to set devlink parameter %s to %s: %v", e.Param, e.Value, e.Err)
+}
+
+func (e *DevlinkParamError) Unwrap() error {
+	return e.Err
+}
+
+// GetDevlinkParamErrors returns the errors of the devlink parameters contained in err,
+// the errors joined with errors.Join are included
+func GetDevlinkParamErrors(err error) []*DevlinkParamError {
+	switch e := err.(type) {
+	case *DevlinkParamError:
+		return []*DevlinkParamError{e}
+	case interface{ Unwrap() []error }:
+		var result []*DevlinkParamError
+		for _, joined := range e.Unwrap() {
+			result = append(result, GetDevlinkParamErrors(joined)...)
+		}
+		return result
+	case interface{ Unwrap() error }:
+		return GetDevlinkParamErrors(e.Unwrap())
+	}
+	return nil
+}
+
+// NumVfsShortfallError is returned when the device created less VFs than requested, e.g. because the firmware
+// ran out of resources, the PF is configured with the created VFs
+type NumVfsShortfallError struct {
+	// PciAddress of the PF
+	PciAddress string
+	// NumVfs is the requested number of VFs
+	NumVfs int
+	// CreatedVfs is the number of VFs the device created
+	CreatedVfs int
+	Err        error
+}
+
+func (e *NumVfsShortfallError) Error() string {
+	return fmt.Sprintf("created %d VFs out of the %d requested VFs: %v", e.CreatedVfs, e.NumVfs, e.Err)
+}
+
+func (e *NumVfsShortfallError) Unwrap() error {
+	return e.Err
+}
+
+// VFBar is a BAR of the VFs of a PF as reported by the resource file of the PF in sysfs,
+// the BAR covers the MMIO space of all the VFs the PF supports
+type VFBar struct {
+	// Index of the BAR of the VFs, from 0 to 5
+	Index int
+	// Start is the address of the BAR, 0 if the kernel couldn't assign the MMIO space
+	Start uint64
+	// Size of the BAR in bytes
+	Size uint64
+}
+
+// Assigned returns true if the kernel assigned the MMIO space of the BAR
+func (b VFBar) Assigned() bool {
+	return b.Start != 0
+}
+
+func (b VFBar) String() string {
+	if !b.Assigned() {
+		return fmt.Sprintf("BAR%d size %#x unassigned", b.Index, b.Size)
+	}
+	return fmt.Sprintf("BAR%d size %#x at %#x", b.Index, b.Size, b.Start)
+}
+
+// VFBarAllocationError is returned when the VFs can't be created because the kernel can't allocate
+// the MMIO space of their BARs, the write of sriov_numvfs fails with ENOMEM in that case
+type VFBarAllocationError struct {
+	// PciAddress of the PF
+	PciAddress string
+	// NumVfs is the requested number of VFs
+	NumVfs int
+	// Bars contains the BARs of the VFs read after the failure
+	Bars []VFBar
+	Err  error
+}
+
+func (e *VFBarAllocationError) Error() string {
+	bars := make([]string, 0, len(e.Bars))
+	for _, bar := range e.Bars {
+		bars = append(bars, bar.String())
+	}
+	return fmt.Sprintf("failed to allocate the MMIO space of %d VFs (VF BARs: %s): %v",
+		e.NumVfs, strings.Join(bars, ", "), e.Err)
+}
+
+func (e *VFBarAllocationError) Unwrap() error {
+	return e.Err
+}
+
+// GetVFBarAllocationErrors returns the BAR allocation errors of the PFs contained in err,
+// the errors joined with errors.Join are included
+func GetVFBarAllocationErrors(err error) []*VFBarAllocationError {
+	switch e := err.(type) {
+	case *VFBarAllocationError:
+		return []*VFBarAllocationError{e}
+	case interface{ Unwrap() []error }:
+		var result []*VFBarAllocationError
+		for _, joined := range e.Unwrap() {
+			result = append(result, GetVFBarAllocationErrors(joined)...)
+		}
+		return result
+	case interface{ Unwrap() error }:
+		return GetVFBarAllocationErrors(e.Unwrap())
+	}
+	return nil
+}
+
+// RSSConfig is the RSS configuration of a VF netdevice as reported by ethtool
+type RSSConfig struct {
+	// HashKey is the RSS hash key in the ethtool format, lowercase bytes separated by colons
+	HashKey string
+	// HashFields are the hash fields (e.g. "ipv4-tcp") of the flow types hashed on the L4 ports
+	// in addition to the IP addresses
+	HashFields []string
+}
+
+// VF attributes configured through the PF which are reconciled periodically
+const (
+	VfAttributeTrust    = "trust"
+	VfAttributeSpoofChk = "spoofChk"
+	VfAttributeTxRate   = "txRate"
+	VfAttributeVlan     = "vlan"
+)
+
+// VfAttributeCorrection is an attribute of a VF which differed from the desired state and was reapplied
+type VfAttributeCorrection struct {
+	VfID int
+	// Attribute is one of the VfAttribute constants
+	Attribute string
+}
+
+// DistroInfo contains info about the OS distribution of the host
+type DistroInfo struct {
+	// ID of the distribution, e.g. rhcos, ubuntu
+	ID string
+	// IDLike contains IDs of the distributions this one is derived from
+	IDLike []string
+	// VersionID version of the distribution
+	VersionID string
+	// Family the distribution belongs to, e.g. rhel, debian
+	Family string
+}
+
 // Service contains info about systemd service
 type Service struct {
 	Name    string
@@ -15,8 +15,6 @@ import (
 	"github.com/jaypipes/ghw/pkg/net"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	dputils "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
-
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
@@ -447,7 +445,7 @@ func (o *openstackContext) DiscoverSriovDevicesVirtual() ([]sriovnetworkv1.Inter
 		netFilter := deviceInfo.NetworkID
 		metaMac := deviceInfo.MacAddress
 
-		driver, err := dputils.GetDriverName(device.Address)
+		driver, err := o.hostManager.GetDriverByBusAndDevice(consts.BusPci, device.Address)
 		if err != nil {
 			log.Log.Error(err, "DiscoverSriovDevicesVirtual(): unable to parse device driver for device, skipping",
 				"device", device)
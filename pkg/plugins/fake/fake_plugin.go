@@ -28,3 +28,7 @@ func (f *FakePlugin) CheckStatusChanges(new *sriovnetworkv1.SriovNetworkNodeStat
 func (f *FakePlugin) Apply() error {
 	return nil
 }
+
+func (f *FakePlugin) ForceApply() error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// ApplyPartial configures the PFs with the PCI addresses of the desired state one by one, a PF which fails
+// doesn't prevent the configuration of the other PFs. The result of each PF is returned by PCI address and
+// recorded in InterfaceReconcileStatus, the spec of the PFs which succeeded is recorded as their last applied
+// configuration. The error is not nil only if all the PFs failed.
+// The configuration of the node which is not specific to a PF (drivers, hugepages, bridges...) is done by Apply.
+func (p *GenericPlugin) ApplyPartial(pciAddresses []string) (map[string]error, error) {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	if p.isPaused() {
+		log.Log.Info("generic plugin ApplyPartial(): plugin is paused, skipping")
+		return nil, plugin.ErrPluginPaused
+	}
+	results := make(map[string]error, len(pciAddresses))
+	if len(pciAddresses) == 0 {
+		return results, nil
+	}
+	log.Log.Info("generic plugin ApplyPartial()", "interfaces", pciAddresses)
+	ctx, cancel := p.applyContext(context.Background())
+	defer cancel()
+
+	// When calling from systemd do not try to chroot
+	if !vars.UsingSystemdMode {
+		if err := validateHostMount(p.hostMountPath); err != nil {
+			log.Log.Error(err, "generic plugin ApplyPartial(): host filesystem is not mounted properly", "path", p.hostMountPath)
+			return nil, &ChrootError{Path: p.hostMountPath, Underlying: err}
+		}
+		exit, err := p.helpers.Chroot(p.hostMountPath)
+		if err != nil {
+			return nil, &ChrootError{Path: p.hostMountPath, Underlying: err}
+		}
+		defer exit()
+	}
+
+	p.refreshPFNames(ctx)
+	var errs []error
+	for _, pciAddress := range pciAddresses {
+		err := p.applyInterface(ctx, pciAddress)
+		results[pciAddress] = err
+		if err != nil {
+			log.Log.Error(err, "generic plugin ApplyPartial(): failed to configure PF", "address", pciAddress)
+			errs = append(errs, fmt.Errorf("%s: %w", pciAddress, err))
+		}
+	}
+	if len(errs) == len(results) {
+		return results, newSyncNodeStateError(errors.Join(errs...))
+	}
+	return results, nil
+}
+
+// applyInterface configures the PF with the PCI address and its VFs and records the result in the reconcile status
+func (p *GenericPlugin) applyInterface(ctx context.Context, pciAddress string) error {
+	if reason, skipped := p.skippedDevices()[pciAddress]; skipped {
+		return fmt.Errorf("PF is skipped: %s", reason)
+	}
+	var interfaces sriovnetworkv1.Interfaces
+	for _, iface := range p.DesireState.Spec.Interfaces {
+		if iface.PciAddress == pciAddress {
+			interfaces = sriovnetworkv1.Interfaces{iface}
+			break
+		}
+	}
+	if len(interfaces) == 0 {
+		return fmt.Errorf("PF is not in the desired state")
+	}
+	// only the status of the PF is passed, the PFs without spec in the status are reset otherwise
+	ifaceStatus := p.DesireState.GetInterfaceStateByPciAddress(pciAddress)
+	if ifaceStatus == nil {
+		return fmt.Errorf("PF is not discovered on the node")
+	}
+
+	err := p.hostManager.ConfigSriovInterfaces(ctx, p.helpers, sortVfGroups(interfaces),
+		sriovnetworkv1.InterfaceExts{*ifaceStatus}, p.skipVFConfiguration)
+	if err == nil {
+		err = p.syncVFBridgeVLAN(interfaces)
+	}
+	if err == nil {
+		err = p.syncVFQinQ(interfaces)
+	}
+	if err == nil {
+		err = p.syncVFRSS(ctx, interfaces)
+	}
+	if err == nil {
+		err = p.syncIRQAffinity(ctx, interfaces)
+	}
+	p.updateReconcileStatus(interfaces, err)
+	if err != nil {
+		// the error of the PF without the PCI address
+		return p.InterfaceReconcileStatus[pciAddress].LastError
+	}
+	return nil
+}
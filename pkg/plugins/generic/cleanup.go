@@ -0,0 +1,82 @@
+package generic
+
+import (
+	"context"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// Cleanup resets the PFs configured by the operator to their original state and unloads the drivers loaded
+// by the plugin, it is called when the node state is deleted. The PFs are discovered on the host, the PFs
+// which were not configured by the operator and the skipped devices are not modified. A driver which is still
+// in use is kept loaded, the failure is logged only.
+func (p *GenericPlugin) Cleanup() error {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	log.Log.Info("generic plugin Cleanup()")
+	ctx := context.Background()
+
+	interfaceStatuses, err := p.hostManager.DiscoverSriovDevices(ctx, p.helpers)
+	if err != nil {
+		log.Log.Error(err, "generic plugin Cleanup(): failed to discover SR-IOV devices")
+		return newSyncNodeStateError(err)
+	}
+
+	// When calling from systemd do not try to chroot
+	if !vars.UsingSystemdMode {
+		if err := validateHostMount(p.hostMountPath); err != nil {
+			log.Log.Error(err, "generic plugin Cleanup(): host filesystem is not mounted properly", "path", p.hostMountPath)
+			return &ChrootError{Path: p.hostMountPath, Underlying: err}
+		}
+		exit, err := p.helpers.Chroot(p.hostMountPath)
+		if err != nil {
+			return &ChrootError{Path: p.hostMountPath, Underlying: err}
+		}
+		defer exit()
+	}
+
+	// the PFs without spec are reset if they were configured by the operator
+	if err := p.hostManager.ConfigSriovInterfaces(ctx, p.helpers, nil,
+		p.filterSkippedDevicesStatus(interfaceStatuses), false); err != nil {
+		log.Log.Error(err, "generic plugin Cleanup(): failed to reset SR-IOV devices")
+		return newSyncNodeStateError(err)
+	}
+	// the PFs are configured again from scratch if a new node state is created
+	p.InterfaceReconcileStatus = make(map[string]ReconcileStatus)
+
+	p.unloadDrivers(ctx)
+	if p.PersistDriverLoad {
+		if err := p.hostManager.WriteModulesLoadConf(ctx, consts.ModulesLoadConfFile, nil); err != nil {
+			log.Log.Error(err, "generic plugin Cleanup(): fail to remove persisted kmods")
+			return newSyncNodeStateError(err)
+		}
+	}
+	return nil
+}
+
+// unloadDrivers unloads the drivers loaded by the plugin, the drivers which were already loaded on the host
+// are kept. The drivers are unloaded in the reverse order of their IDs so that a driver is unloaded before
+// the drivers it depends on, e.g. qat_4xxx before intel_qat
+func (p *GenericPlugin) unloadDrivers(ctx context.Context) {
+	ids := make([]uint, 0, len(p.DriverStateMap))
+	for id, driverState := range p.DriverStateMap {
+		if driverState.DriverLoaded && driverState.LoadedByPlugin {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	slices.Reverse(ids)
+	for _, id := range ids {
+		driverState := p.DriverStateMap[id]
+		if err := p.hostManager.UnloadKernelModule(ctx, driverState.DriverName); err != nil {
+			log.Log.Error(err, "generic plugin Cleanup(): failed to unload driver, keep it loaded", "name", driverState.DriverName)
+			continue
+		}
+		driverState.DriverLoaded = false
+		driverState.LoadedByPlugin = false
+	}
+}
@@ -0,0 +1,94 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+// reasons of the node state conditions set by the generic plugin
+const (
+	conditionReasonApplying       = "Applying"
+	conditionReasonApplySucceeded = "ApplySucceeded"
+	conditionReasonApplyFailed    = "ApplyFailed"
+)
+
+// ConditionManager sets the conditions of the SriovNetworkNodeState status
+type ConditionManager struct {
+	client client.Client
+}
+
+// NewConditionManager returns a ConditionManager which patches the node states with the provided client
+func NewConditionManager(c client.Client) *ConditionManager {
+	return &ConditionManager{client: c}
+}
+
+// SetCondition sets the condition in the status of the node state, the status is patched only if the condition
+// changed and the conditions of the provided state are updated once the patch succeeded
+func (m *ConditionManager) SetCondition(state *sriovnetworkv1.SriovNetworkNodeState, condType, status, reason, message string) error {
+	condition := metav1.Condition{
+		Type:               condType,
+		Status:             metav1.ConditionStatus(status),
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: state.Generation,
+	}
+	if current := apimeta.FindStatusCondition(state.Status.Conditions, condType); current != nil &&
+		current.Status == condition.Status && current.Reason == condition.Reason &&
+		current.Message == condition.Message && current.ObservedGeneration == condition.ObservedGeneration {
+		return nil
+	}
+	newState := state.DeepCopy()
+	apimeta.SetStatusCondition(&newState.Status.Conditions, condition)
+	if err := m.client.Status().Patch(context.Background(), newState, client.MergeFrom(state)); err != nil {
+		return fmt.Errorf("failed to set condition %s of node state %s: %v", condType, state.Name, err)
+	}
+	state.Status.Conditions = newState.Status.Conditions
+	return nil
+}
+
+// setConditions sets the conditions of the desired state, failures are only logged because they don't
+// affect the configuration of the node
+func (p *GenericPlugin) setConditions(conditions ...metav1.Condition) {
+	if p.conditionManager == nil || p.DesireState == nil {
+		return
+	}
+	for _, c := range conditions {
+		if err := p.conditionManager.SetCondition(p.DesireState, c.Type, string(c.Status), c.Reason, c.Message); err != nil {
+			log.Log.Error(err, "generic plugin setConditions(): failed to set node state condition", "type", c.Type)
+		}
+	}
+}
+
+// setApplyStartedConditions marks the node state as progressing at the start of the apply
+func (p *GenericPlugin) setApplyStartedConditions() {
+	p.setConditions(metav1.Condition{Type: sriovnetworkv1.NodeStateConditionProgressing, Status: metav1.ConditionTrue,
+		Reason: conditionReasonApplying, Message: "the node configuration is being applied"})
+}
+
+// setApplyFinishedConditions sets the conditions of the node state with the result of the apply
+func (p *GenericPlugin) setApplyFinishedConditions(applyErr error) {
+	if applyErr == nil {
+		p.setConditions(
+			metav1.Condition{Type: sriovnetworkv1.NodeStateConditionReady, Status: metav1.ConditionTrue,
+				Reason: conditionReasonApplySucceeded, Message: "the node configuration is applied"},
+			metav1.Condition{Type: sriovnetworkv1.NodeStateConditionDegraded, Status: metav1.ConditionFalse,
+				Reason: conditionReasonApplySucceeded},
+			metav1.Condition{Type: sriovnetworkv1.NodeStateConditionProgressing, Status: metav1.ConditionFalse,
+				Reason: conditionReasonApplySucceeded})
+		return
+	}
+	p.setConditions(
+		metav1.Condition{Type: sriovnetworkv1.NodeStateConditionReady, Status: metav1.ConditionFalse,
+			Reason: conditionReasonApplyFailed, Message: applyErr.Error()},
+		metav1.Condition{Type: sriovnetworkv1.NodeStateConditionDegraded, Status: metav1.ConditionTrue,
+			Reason: conditionReasonApplyFailed, Message: applyErr.Error()},
+		metav1.Condition{Type: sriovnetworkv1.NodeStateConditionProgressing, Status: metav1.ConditionFalse,
+			Reason: conditionReasonApplyFailed})
+}
@@ -0,0 +1,158 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// The settings of the generic plugin are stored in the plugin ConfigMap with the configKeyPrefix prefix,
+// next to the devices to skip which are shared with the other consumers of the ConfigMap
+const (
+	configKeyPrefix = "generic-plugin."
+	// watchdogIntervalKey contains the watchdog interval as a duration string, e.g. "15m", "0" disables the watchdog
+	watchdogIntervalKey = configKeyPrefix + "watchdogInterval"
+	// maxKernelParamAttemptsKey contains MaxKernelParamAttempts, "0" disables the limit
+	maxKernelParamAttemptsKey = configKeyPrefix + "maxKernelParamAttempts"
+	// applyTimeoutKey contains ApplyTimeout as a duration string, "0" disables the timeout
+	applyTimeoutKey = configKeyPrefix + "applyTimeout"
+)
+
+// ionicFirmwareAnnotation is the annotation of the plugin ConfigMap which contains the firmware flashed to the
+// AMD Pensando DSC PFs, the path is relative to the firmware directory of the host
+const ionicFirmwareAnnotation = "sriovnetwork.openshift.io/ionic-firmware"
+
+// newConfigMapCache returns the cache used to watch the plugin ConfigMap, overridden in unit-tests
+var newConfigMapCache = func(namespace, configMapName string) (cache.Cache, error) {
+	if vars.Config == nil {
+		return nil, fmt.Errorf("kubernetes client config is not initialized")
+	}
+	return cache.New(vars.Config, cache.Options{
+		DefaultNamespaces: map[string]cache.Config{namespace: {}},
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", configMapName)},
+		},
+	})
+}
+
+// StartConfigWatcher loads the plugin configuration from the ConfigMap and reloads it each time
+// the ConfigMap is changed until the context is canceled. The ConfigMap supports the following keys:
+//   - devices: devices which should not be configured, see skipDevicesKey
+//   - generic-plugin.watchdogInterval: time without node state changes after which the desired state is applied again
+//   - generic-plugin.maxKernelParamAttempts: number of attempts to set a kernel arg, see MaxKernelParamAttempts
+//   - generic-plugin.applyTimeout: time after which the host operations of an apply are canceled
+//
+// The firmware of the AMD Pensando DSC PFs is read from the ionicFirmwareAnnotation annotation.
+func (p *GenericPlugin) StartConfigWatcher(ctx context.Context, kubeClient client.Client, namespace, configMapName string) error {
+	funcLog := log.Log.WithValues("namespace", namespace, "configMap", configMapName)
+	cm := &corev1.ConfigMap{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: configMapName}, cm)
+	if err == nil {
+		p.reloadConfig(cm)
+	} else if !errors.IsNotFound(err) {
+		funcLog.Error(err, "generic plugin StartConfigWatcher(): failed to read plugin ConfigMap")
+		return err
+	}
+
+	configMapCache, err := newConfigMapCache(namespace, configMapName)
+	if err != nil {
+		return err
+	}
+	informer, err := configMapCache.GetInformer(ctx, &corev1.ConfigMap{})
+	if err != nil {
+		return err
+	}
+	reload := func(obj interface{}) {
+		if cm, ok := obj.(*corev1.ConfigMap); ok && cm.Name == configMapName {
+			p.reloadConfig(cm)
+		}
+	}
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    reload,
+		UpdateFunc: func(_, newObj interface{}) { reload(newObj) },
+	}); err != nil {
+		return err
+	}
+	go func() {
+		if err := configMapCache.Start(ctx); err != nil {
+			funcLog.Error(err, "generic plugin StartConfigWatcher(): plugin ConfigMap watcher stopped")
+		}
+	}()
+	funcLog.Info("generic plugin StartConfigWatcher(): watching plugin ConfigMap")
+	return nil
+}
+
+// reloadConfig applies the plugin configuration from the ConfigMap, the settings which can't be parsed
+// and the settings which are not in the ConfigMap are not changed
+func (p *GenericPlugin) reloadConfig(cm *corev1.ConfigMap) {
+	funcLog := log.Log.WithValues("configMap", cm.Name)
+	if _, ok := cm.Data[skipDevicesKey]; ok {
+		skipDevices, err := parseSkipDevices(cm)
+		if err != nil {
+			funcLog.Error(err, "generic plugin reloadConfig(): invalid devices to skip, keep the current list")
+		} else {
+			p.stateLock.Lock()
+			p.SkipPCIAddresses = skipDevices
+			p.stateLock.Unlock()
+			funcLog.Info("generic plugin reloadConfig(): devices to skip reloaded", "devices", skipDevices)
+		}
+	}
+	fwPath := cm.Annotations[ionicFirmwareAnnotation]
+	p.stateLock.Lock()
+	fwChanged := fwPath != p.IonicFirmwarePath
+	p.IonicFirmwarePath = fwPath
+	p.stateLock.Unlock()
+	if fwChanged {
+		funcLog.Info("generic plugin reloadConfig(): ionic firmware reloaded", "firmware", fwPath)
+	}
+	if value, ok := cm.Data[maxKernelParamAttemptsKey]; ok {
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			funcLog.Error(err, "generic plugin reloadConfig(): invalid maximum kernel param attempts, keep the current value",
+				"value", value)
+		} else {
+			p.stateLock.Lock()
+			changed := attempts != p.MaxKernelParamAttempts
+			p.MaxKernelParamAttempts = attempts
+			p.stateLock.Unlock()
+			if changed {
+				funcLog.Info("generic plugin reloadConfig(): maximum kernel param attempts reloaded", "attempts", attempts)
+			}
+		}
+	}
+	if value, ok := cm.Data[applyTimeoutKey]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			funcLog.Error(err, "generic plugin reloadConfig(): invalid apply timeout, keep the current timeout",
+				"value", value)
+		} else {
+			p.stateLock.Lock()
+			changed := timeout != p.ApplyTimeout
+			p.ApplyTimeout = timeout
+			p.stateLock.Unlock()
+			if changed {
+				funcLog.Info("generic plugin reloadConfig(): apply timeout reloaded", "timeout", timeout)
+			}
+		}
+	}
+	if value, ok := cm.Data[watchdogIntervalKey]; ok {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			funcLog.Error(err, "generic plugin reloadConfig(): invalid watchdog interval, keep the current interval",
+				"value", value)
+		} else if p.setWatchdogInterval(interval) {
+			funcLog.Info("generic plugin reloadConfig(): watchdog interval reloaded", "interval", interval)
+		}
+	}
+}
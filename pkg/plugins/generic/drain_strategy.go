@@ -0,0 +1,79 @@
+package generic
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// DrainStrategy decides if the node must be drained before the configuration of a PF with VFs is updated
+type DrainStrategy interface {
+	// NeedDrain returns true if the pods must be evicted from the node to apply the desired configuration of the PF
+	NeedDrain(desired *sriovnetworkv1.Interface, current *sriovnetworkv1.InterfaceExt) bool
+}
+
+// ConservativeDrainStrategy drains the node on any change of the configuration of the PF or of its VFs,
+// it is the default strategy of the generic plugin
+type ConservativeDrainStrategy struct{}
+
+// NeedDrain implements DrainStrategy
+func (ConservativeDrainStrategy) NeedDrain(desired *sriovnetworkv1.Interface, current *sriovnetworkv1.InterfaceExt) bool {
+	return sriovnetworkv1.NeedToDrainForSriovUpdate(desired, current)
+}
+
+// OptimisticDrainStrategy drains the node only if VFs are removed or if the driver of VFs changes, the other
+// changes (e.g. more VFs, MTU, VLAN) are expected to be applied without disrupting the pods using the VFs.
+// It must only be used with devices which support these updates while the VFs are in use.
+type OptimisticDrainStrategy struct{}
+
+// NeedDrain implements DrainStrategy
+func (OptimisticDrainStrategy) NeedDrain(desired *sriovnetworkv1.Interface, current *sriovnetworkv1.InterfaceExt) bool {
+	if desired.ExternallyManaged {
+		// the VFs of externally managed PFs are not created nor removed by the operator
+		return vfDriverChanged(desired, current)
+	}
+	if desired.NumVfs < current.NumVfs {
+		log.Log.V(2).Info("OptimisticDrainStrategy: need drain, VFs are removed",
+			"address", current.PciAddress, "desired", desired.NumVfs, "current", current.NumVfs)
+		return true
+	}
+	// the VFs are recreated when the eSwitch mode changes
+	if sriovnetworkv1.GetEswitchModeFromSpec(desired) != sriovnetworkv1.GetEswitchModeFromStatus(current) {
+		log.Log.V(2).Info("OptimisticDrainStrategy: need drain, eSwitch mode changes", "address", current.PciAddress)
+		return true
+	}
+	// the encapsulation mode can't be changed while the VFs are in use
+	if sriovnetworkv1.NeedToUpdateEswitchEncapMode(desired, current) {
+		log.Log.V(2).Info("OptimisticDrainStrategy: need drain, eSwitch encapsulation mode changes", "address", current.PciAddress)
+		return true
+	}
+	return vfDriverChanged(desired, current)
+}
+
+// vfDriverChanged returns true if an existing VF is bound to a driver of another device type than its VF group,
+// the VFs without driver are not used by pods
+func vfDriverChanged(desired *sriovnetworkv1.Interface, current *sriovnetworkv1.InterfaceExt) bool {
+	for _, vf := range current.VFs {
+		if vf.Driver == "" {
+			continue
+		}
+		for _, group := range desired.VfGroups {
+			if !sriovnetworkv1.IndexInRange(vf.VfID, group.VfRange) {
+				continue
+			}
+			dpdkDriver := sriovnetworkv1.StringInArray(vf.Driver, vars.DpdkDrivers)
+			changed := dpdkDriver
+			if group.DeviceType != "" && group.DeviceType != consts.DeviceTypeNetDevice {
+				changed = group.DeviceType != vf.Driver
+			}
+			if changed {
+				log.Log.V(2).Info("OptimisticDrainStrategy: need drain, VF driver changes", "address", current.PciAddress,
+					"vf", vf.VfID, "desired", group.DeviceType, "current", vf.Driver)
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package generic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+// DriverLoadError is returned when a kernel driver required by the node state can't be loaded,
+// set up after the load or used by the devices which require it
+type DriverLoadError struct {
+	DriverName string
+	Underlying error
+}
+
+func (e *DriverLoadError) Error() string {
+	return fmt.Sprintf("failed to load driver %s: %v", e.DriverName, e.Underlying)
+}
+
+func (e *DriverLoadError) Unwrap() error {
+	return e.Underlying
+}
+
+// ErrKernelParamAttemptsExceeded is the underlying error of the KernelParamError returned when the kernel arg
+// was set MaxKernelParamAttempts times without appearing in the kernel cmdline
+var ErrKernelParamAttemptsExceeded = errors.New("maximum number of attempts exceeded")
+
+// KernelParamError is returned when a kernel parameter can't be set on the node,
+// Attempts is the number of times the plugin tried to set the parameter
+type KernelParamError struct {
+	Param      string
+	Attempts   int
+	Underlying error
+}
+
+func (e *KernelParamError) Error() string {
+	return fmt.Sprintf("failed to set kernel parameter %q (attempts: %d): %v", e.Param, e.Attempts, e.Underlying)
+}
+
+func (e *KernelParamError) Unwrap() error {
+	return e.Underlying
+}
+
+// SyncNodeStateError is returned when the node state can't be applied on the host,
+// PciAddress is set if the failure is specific to a single PF, the PCI address is
+// already part of the message of the underlying error in that case
+type SyncNodeStateError struct {
+	PciAddress string
+	Underlying error
+}
+
+func (e *SyncNodeStateError) Error() string {
+	return fmt.Sprintf("failed to sync node state: %v", e.Underlying)
+}
+
+func (e *SyncNodeStateError) Unwrap() error {
+	return e.Underlying
+}
+
+// ChrootError is returned when the plugin can't enter the host filesystem
+type ChrootError struct {
+	Path       string
+	Underlying error
+}
+
+func (e *ChrootError) Error() string {
+	return fmt.Sprintf("failed to chroot to %s: %v", e.Path, e.Underlying)
+}
+
+func (e *ChrootError) Unwrap() error {
+	return e.Underlying
+}
+
+// MMIOSpaceExhaustedError is returned when the kernel can't allocate the MMIO space of the VFs while the
+// pci=realloc kernel arg is set, the space must be enabled in the BIOS and more reboots don't help
+type MMIOSpaceExhaustedError struct {
+	// Allocations contains the failed allocations of the PFs
+	Allocations []*hostTypes.VFBarAllocationError
+	Underlying  error
+}
+
+func (e *MMIOSpaceExhaustedError) Error() string {
+	pciAddresses := make([]string, 0, len(e.Allocations))
+	for _, allocation := range e.Allocations {
+		pciAddresses = append(pciAddresses, allocation.PciAddress)
+	}
+	return fmt.Sprintf("not enough MMIO space for the VFs of %s even with %s, enable SR-IOV, ARI and "+
+		"\"Above 4G Decoding\" in the BIOS of the node: %v", strings.Join(pciAddresses, ", "), consts.KernelArgPciRealloc, e.Underlying)
+}
+
+func (e *MMIOSpaceExhaustedError) Unwrap() error {
+	return e.Underlying
+}
+
+// newSyncNodeStateError wraps err into SyncNodeStateError, the PCI address is taken
+// from the interface sync error if err contains the error of a single PF
+func newSyncNodeStateError(err error) error {
+	syncErr := &SyncNodeStateError{Underlying: err}
+	if ifaceErrs := hostTypes.GetInterfaceSyncErrors(err); len(ifaceErrs) == 1 {
+		syncErr.PciAddress = ifaceErrs[0].PciAddress
+	}
+	return syncErr
+}
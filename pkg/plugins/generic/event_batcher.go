@@ -0,0 +1,116 @@
+package generic
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultEventBatchWindow is the default time during which the events of the plugin are accumulated
+// before they are sent
+const defaultEventBatchWindow = 500 * time.Millisecond
+
+// eventBatchMaxMessages is the maximum number of distinct messages combined in the message of a batched event
+const eventBatchMaxMessages = 10
+
+// reasons of the events sent by the generic plugin
+const (
+	EventReasonDriverLoaded       = "DriverLoaded"
+	EventReasonKernelArgsUpdated  = "KernelArgsUpdated"
+	EventReasonHugepagesShortfall = "HugepagesShortfall"
+)
+
+// EventSender sends an Event with the reason and the message on the SriovNetworkNodeState of the node,
+// it is implemented by the EventRecorder of the config daemon
+type EventSender interface {
+	SendEvent(reason string, msg string)
+}
+
+// batchedEvents contains the messages of the events with the same reason received during the batch window
+type batchedEvents struct {
+	messages []string
+	count    int
+}
+
+// EventBatcher accumulates the events recorded during the batch window and sends a single event per reason
+// when no events were recorded for the window, to avoid writing many Events on the API server when the plugin
+// configures many PFs. The messages of the events with the same reason are combined with their count.
+type EventBatcher struct {
+	sender EventSender
+	window time.Duration
+
+	lock    sync.Mutex
+	timer   *time.Timer
+	reasons []string
+	pending map[string]*batchedEvents
+}
+
+// NewEventBatcher returns an EventBatcher which sends the events with the sender, the events are sent
+// immediately if the window is not positive
+func NewEventBatcher(sender EventSender, window time.Duration) *EventBatcher {
+	return &EventBatcher{
+		sender:  sender,
+		window:  window,
+		pending: make(map[string]*batchedEvents),
+	}
+}
+
+// Record adds the event to the current batch and restarts the batch window
+func (b *EventBatcher) Record(reason, msg string) {
+	if b.window <= 0 {
+		b.sender.SendEvent(reason, msg)
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	events, ok := b.pending[reason]
+	if !ok {
+		events = &batchedEvents{}
+		b.pending[reason] = events
+		b.reasons = append(b.reasons, reason)
+	}
+	events.count++
+	if !slices.Contains(events.messages, msg) {
+		events.messages = append(events.messages, msg)
+	}
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.Flush)
+	} else {
+		b.timer.Reset(b.window)
+	}
+}
+
+// Flush sends the accumulated events without waiting for the end of the batch window
+func (b *EventBatcher) Flush() {
+	b.lock.Lock()
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	reasons, pending := b.reasons, b.pending
+	b.reasons = nil
+	b.pending = make(map[string]*batchedEvents)
+	b.lock.Unlock()
+
+	for _, reason := range reasons {
+		b.sender.SendEvent(reason, pending[reason].message())
+	}
+}
+
+// message returns the combined message of the events, the number of events is added if there are more than one
+func (e *batchedEvents) message() string {
+	messages := e.messages
+	if len(messages) > eventBatchMaxMessages {
+		messages = messages[:eventBatchMaxMessages]
+	}
+	msg := strings.Join(messages, "; ")
+	if len(e.messages) > len(messages) {
+		msg = fmt.Sprintf("%s and %d more", msg, len(e.messages)-len(messages))
+	}
+	if e.count == 1 {
+		return msg
+	}
+	return fmt.Sprintf("%s (%d events)", msg, e.count)
+}
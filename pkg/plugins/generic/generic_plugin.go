@@ -2,17 +2,30 @@ package generic
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-	"syscall"
+	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/cpuset"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
@@ -25,6 +38,13 @@ const (
 	Vfio = iota
 	VirtioVdpa
 	VhostVdpa
+	SfcResource
+	SfcAffinity
+	Ena
+	QatCommon
+	QatDevice
+	Ionic
+	IonicMnic
 )
 
 // driver name
@@ -32,17 +52,48 @@ const (
 	vfioPciDriver    = "vfio_pci"
 	virtioVdpaDriver = "virtio_vdpa"
 	vhostVdpaDriver  = "vhost_vdpa"
+	// Solarflare drivers required to allocate the resources of the VFs
+	sfcResourceDriver = "sfc_resource"
+	sfcAffinityDriver = "sfc_affinity"
+	// Elastic Network Adapter driver of the AWS Nitro instances
+	enaDriver = "ena"
+	// Intel QuickAssist Technology drivers, the common module and the driver of the 4xxx devices
+	qatCommonDriver = "intel_qat"
+	qatDeviceDriver = "qat_4xxx"
+	// AMD Pensando DSC drivers, the driver of the Ethernet devices and the driver of the management NIC
+	ionicDriver     = "ionic"
+	ionicMnicDriver = "ionic_mnic"
 )
 
+// qatDeviceIDs are the PCI device IDs of the Intel QuickAssist Technology 4xxx devices
+var qatDeviceIDs = []string{"4940", "4942", "4944", "4946"}
+
 // function type for determining if a given driver has to be loaded in the kernel
 type needDriver func(state *sriovnetworkv1.SriovNetworkNodeState, driverState *DriverState) bool
 
+// function type for the additional host configuration required after the driver is loaded
+type postLoad func(ctx context.Context, p *GenericPlugin) error
+
 type DriverState struct {
-	DriverName     string
-	DeviceType     string
-	VdpaType       string
+	DriverName string
+	DeviceType string
+	VdpaType   string
+	// VendorID is the PCI vendor ID of the devices which require the driver
+	VendorID string
+	// DeviceID is the PCI device ID of the devices which require the driver, all the devices of the vendor if empty
+	DeviceID string
+	// ModuleParams are the parameters used to load the driver, optional
+	ModuleParams   []string
 	NeedDriverFunc needDriver
-	DriverLoaded   bool
+	// PostLoadFunc is called after the driver is loaded, optional
+	PostLoadFunc postLoad
+	DriverLoaded bool
+	// LoadedByPlugin is true if the driver was not loaded on the host before the plugin loaded it,
+	// only these drivers are unloaded by Cleanup
+	LoadedByPlugin bool
+	// ForceRebind rebinds the VFs which are bound to another driver after the driver is loaded,
+	// if false an error is returned instead
+	ForceRebind bool
 }
 
 type DriverStateMapType map[uint]*DriverState
@@ -56,6 +107,100 @@ type GenericPlugin struct {
 	helpers                 helper.HostHelpersInterface
 	skipVFConfiguration     bool
 	skipBridgeConfiguration bool
+	hostMountPath           string
+	pauseLock               sync.Mutex
+	paused                  bool
+	// PersistDriverLoad configures the plugin to persist required kernel drivers to the
+	// modules-load.d configuration on the host, so they are loaded on boot
+	PersistDriverLoad bool
+	// IgnoreBondedInterfaces configures the plugin to not drain the node before the configuration of a PF
+	// enslaved to a bond or to a team
+	IgnoreBondedInterfaces bool
+	// SkipPCIAddresses contains PCI addresses of the devices which should not be configured
+	// by the plugin, the value is the reason for skipping the device
+	SkipPCIAddresses     map[string]string
+	skipDevicesConfigMap string
+	// pfSkippers contains the vendor plugins which decide if the PFs of the vendor should be skipped
+	pfSkippers map[string]plugin.PFSkipper
+	// totalVfsRaisers contains the vendor plugins which raise the maximum number of VFs of the PFs of the vendor
+	totalVfsRaisers map[string]plugin.TotalVfsRaiser
+	// pfsToSkip contains the devices from SkipPCIAddresses and the PFs skipped by pfSkippers
+	// or by the generic check, the map is rebuilt on each apply
+	pfsToSkip         map[string]string
+	kernelParamSource KernelParamConfigSource
+	// KernelArgsSetTime contains the time when the plugin updated the bootloader configuration with the kernel arg,
+	// the entry is removed once the kernel arg appears in the kernel cmdline
+	KernelArgsSetTime map[string]time.Time
+	// KernelArgAttempts contains the number of times the plugin tried to set the kernel arg,
+	// the entry is removed once the kernel arg appears in the kernel cmdline
+	KernelArgAttempts map[string]int
+	// kernelParamLastSetAt contains the time when the kernel arg was last added to the bootloader configuration
+	// successfully, the entry is removed once the kernel arg appears in the kernel cmdline
+	kernelParamLastSetAt map[string]time.Time
+	// KernelParamSetCooldown is the time after a successful update of the bootloader configuration with a kernel arg
+	// during which the kernel arg is not set again, the kernel arg is set on every sync if not positive
+	KernelParamSetCooldown time.Duration
+	// MaxKernelParamAttempts is the number of times the plugin tries to set a kernel arg which doesn't appear
+	// in the kernel cmdline before it gives up, the plugin tries without limit if not positive
+	MaxKernelParamAttempts int
+	// ApplyTimeout is the time after which the host operations of an apply are canceled,
+	// the apply is not limited in time if not positive
+	ApplyTimeout time.Duration
+	// InterfaceReconcileStatus contains the result of the last configuration of each PF by PCI address
+	InterfaceReconcileStatus map[string]ReconcileStatus
+	// SuccessfulReconcileSkipDuration is the time after a successful configuration of a PF during which
+	// the PF is not configured again unless its spec changes, PFs are always configured if not positive
+	SuccessfulReconcileSkipDuration time.Duration
+	// KernelParamGracePeriod is the time to wait for the updated kernel args to appear in the kernel cmdline
+	// before the reboot is requested, some bootloader tools (rpm-ostree) apply the changes asynchronously
+	KernelParamGracePeriod time.Duration
+	// KubeClient is used to record the time of the last successful apply on the node state,
+	// nothing is recorded if the client is not set
+	KubeClient client.Client
+	// conditionManager sets the conditions of the node state on each apply, nil if KubeClient is not set
+	conditionManager *ConditionManager
+	// drainStrategy decides if a change of the configuration of a PF with VFs requires to drain the node
+	drainStrategy DrainStrategy
+	// EventBatchWindow is the time during which the events of the plugin are accumulated before they are sent
+	// as one event per reason, the events are sent immediately if not positive
+	EventBatchWindow time.Duration
+	// eventBatcher sends the events of the plugin, nil if no event sender is configured
+	eventBatcher *EventBatcher
+	// remediateGhostVFs removes the ghost VFs found at the beginning of the apply, they are only reported if false
+	remediateGhostVFs bool
+	// hostManager runs the host operations of the plugin with the context of the current apply
+	hostManager host.HostManagerV2Interface
+	// kernelParamManager adds the kernel args to the boot configuration of the host
+	kernelParamManager KernelParamManager
+	// ovsDPDKOffloads contains the VFs configured for the OVS-DPDK offload by PF PCI address, the OVS ports
+	// and the TC rules of the VFs which are not requested anymore are removed by the next apply
+	ovsDPDKOffloads map[string]ovsDPDKOffload
+	// KernelVersionRequirements contains the minimum kernel version of the features configured by the plugin,
+	// the running kernel version is checked when the plugin is created
+	KernelVersionRequirements map[string]string
+	// IonicFirmwarePath is the firmware flashed to the AMD Pensando DSC PFs after the ionic driver is loaded,
+	// relative to the firmware directory of the host, see ionicFirmwareAnnotation
+	IonicFirmwarePath string
+	// WatchdogInterval is the time without node state changes after which the desired state is applied again,
+	// the watchdog is started by the daemon with StartWatchdog
+	WatchdogInterval time.Duration
+	watchdogLock     sync.Mutex
+	watchdogTrigger  func()
+	watchdogStop     chan struct{}
+	watchdogDone     chan struct{}
+	watchdogStopped  bool
+	// VfAttributeReconcileInterval is the interval of the reconciliation of the attributes of the VFs which
+	// are reset by the drivers, see reconcileVFAttributes
+	VfAttributeReconcileInterval time.Duration
+	vfAttributeReconcilerLock    sync.Mutex
+	vfAttributeReconcilerStop    chan struct{}
+	vfAttributeReconcilerDone    chan struct{}
+	vfAttributeReconcilerStopped bool
+	// stateLock serializes the node state changes and the applies of the desired state
+	stateLock       sync.Mutex
+	lastStateChange time.Time
+	// metrics are the Prometheus metrics of the plugin served by MetricsHandler
+	metrics *pluginMetrics
 }
 
 type Option = func(c *genericPluginOptions)
@@ -76,19 +221,305 @@ func WithSkipBridgeConfiguration() Option {
 	}
 }
 
+// WithPersistDriverLoad configures generic_plugin to persist required kernel drivers across reboots
+func WithPersistDriverLoad() Option {
+	return func(c *genericPluginOptions) {
+		c.persistDriverLoad = true
+	}
+}
+
+// WithIgnoreBondedInterfaces configures generic_plugin to configure the PFs enslaved to a bond or to a team
+// without draining the node, for the environments where the bonds tolerate the changes of the PFs
+func WithIgnoreBondedInterfaces() Option {
+	return func(c *genericPluginOptions) {
+		c.ignoreBondedInterfaces = true
+	}
+}
+
+// WithHostMountPath configures generic_plugin to use the provided path as the host filesystem mount point
+func WithHostMountPath(path string) Option {
+	return func(c *genericPluginOptions) {
+		c.hostMountPath = path
+	}
+}
+
+// WithSkipDevices configures generic_plugin to skip configuration of the devices listed
+// in the ConfigMap with the provided name in the operator namespace
+func WithSkipDevices(configMapName string) Option {
+	return func(c *genericPluginOptions) {
+		c.skipDevicesConfigMap = configMapName
+	}
+}
+
+// WithKubeClient configures generic_plugin to record the time and duration of the last successful apply
+// as annotations of the SriovNetworkNodeState and to set its conditions using the provided client
+func WithKubeClient(kubeClient client.Client) Option {
+	return func(c *genericPluginOptions) {
+		c.kubeClient = kubeClient
+	}
+}
+
+// WithKernelParamSource configures generic_plugin to set the kernel parameters provided by the source
+// in addition to the parameters required by the plugin
+func WithKernelParamSource(source KernelParamConfigSource) Option {
+	return func(c *genericPluginOptions) {
+		c.kernelParamSource = source
+	}
+}
+
+// WithPFSkippers configures generic_plugin to skip the PFs selected by the vendor plugins,
+// PFs of the other vendors are checked by the generic check
+func WithPFSkippers(skippers ...plugin.PFSkipper) Option {
+	return func(c *genericPluginOptions) {
+		c.pfSkippers = append(c.pfSkippers, skippers...)
+	}
+}
+
+// WithTotalVfsRaisers configures generic_plugin to request a reboot when a policy requests more VFs than
+// the maximum number of VFs of a PF which is raised by the vendor plugin
+func WithTotalVfsRaisers(raisers ...plugin.TotalVfsRaiser) Option {
+	return func(c *genericPluginOptions) {
+		c.totalVfsRaisers = append(c.totalVfsRaisers, raisers...)
+	}
+}
+
+// WithWatchdogInterval configures generic_plugin to apply the desired state again when no node state
+// changes are received for the provided interval, the watchdog is disabled by default and if the interval
+// is not positive
+func WithWatchdogInterval(interval time.Duration) Option {
+	return func(c *genericPluginOptions) {
+		c.watchdogInterval = interval
+	}
+}
+
+// WithVfAttributeReconcileInterval configures generic_plugin to reapply periodically the attributes of the VFs
+// which differ from the desired state, the reconciliation is disabled if the interval is not positive
+func WithVfAttributeReconcileInterval(interval time.Duration) Option {
+	return func(c *genericPluginOptions) {
+		c.vfAttributeReconcileInterval = interval
+	}
+}
+
+// WithSuccessfulReconcileSkipDuration configures generic_plugin to not configure again the PFs which were
+// configured successfully with the same spec less than the provided time ago, only the failed PFs are retried
+func WithSuccessfulReconcileSkipDuration(duration time.Duration) Option {
+	return func(c *genericPluginOptions) {
+		c.successfulReconcileSkipDuration = duration
+	}
+}
+
+// WithKernelVersionRequirements configures generic_plugin to check the running kernel version against the provided
+// minimum kernel versions of the features instead of the default ones
+func WithKernelVersionRequirements(requirements map[string]string) Option {
+	return func(c *genericPluginOptions) {
+		c.kernelVersionRequirements = requirements
+	}
+}
+
+// WithRequiredKernelFeatures configures generic_plugin to fail if the running kernel is older than the minimum
+// kernel version of one of the provided features, only a warning is logged for the other features
+func WithRequiredKernelFeatures(features ...string) Option {
+	return func(c *genericPluginOptions) {
+		c.requiredKernelFeatures = append(c.requiredKernelFeatures, features...)
+	}
+}
+
+// WithDrainStrategy configures generic_plugin to decide with the provided strategy if a change of the configuration
+// of a PF with VFs requires to drain the node, ConservativeDrainStrategy is used by default
+func WithDrainStrategy(strategy DrainStrategy) Option {
+	return func(c *genericPluginOptions) {
+		c.drainStrategy = strategy
+	}
+}
+
+// WithKernelParamGracePeriod configures generic_plugin to wait for the provided time for the kernel args
+// to appear in the kernel cmdline before the reboot is requested, the reboot is requested immediately if zero
+func WithKernelParamGracePeriod(gracePeriod time.Duration) Option {
+	return func(c *genericPluginOptions) {
+		c.kernelParamGracePeriod = gracePeriod
+	}
+}
+
+// WithMaxKernelParamAttempts configures generic_plugin to give up setting a kernel arg which doesn't appear
+// in the kernel cmdline after the provided number of attempts, the plugin tries without limit by default
+func WithMaxKernelParamAttempts(attempts int) Option {
+	return func(c *genericPluginOptions) {
+		c.maxKernelParamAttempts = attempts
+	}
+}
+
+// WithApplyTimeout configures generic_plugin to cancel the host operations of an apply which takes longer
+// than the provided time, the apply is not limited in time by default
+func WithApplyTimeout(timeout time.Duration) Option {
+	return func(c *genericPluginOptions) {
+		c.applyTimeout = timeout
+	}
+}
+
+// WithKernelParamSetCooldown configures generic_plugin to not set again for the provided time a kernel arg
+// which was added to the bootloader configuration successfully, the kernel args are set on every sync if zero
+func WithKernelParamSetCooldown(cooldown time.Duration) Option {
+	return func(c *genericPluginOptions) {
+		c.kernelParamSetCooldown = cooldown
+	}
+}
+
+// WithEventSender configures generic_plugin to send events on the SriovNetworkNodeState with the provided sender
+// when it loads drivers or updates the kernel args, the events are batched for the EventBatchWindow
+func WithEventSender(sender EventSender) Option {
+	return func(c *genericPluginOptions) {
+		c.eventSender = sender
+	}
+}
+
+// WithEventBatchWindow configures generic_plugin to accumulate its events for the provided time before they
+// are sent, the events are sent immediately if the window is not positive
+func WithEventBatchWindow(window time.Duration) Option {
+	return func(c *genericPluginOptions) {
+		c.eventBatchWindow = window
+	}
+}
+
+// WithRemediateGhostVFs configures the plugin to remove the VFs left by the previous runs of the daemon
+// which are not reflected in the desired state, see DetectGhostVFs
+func WithRemediateGhostVFs() Option {
+	return func(c *genericPluginOptions) {
+		c.remediateGhostVFs = true
+	}
+}
+
+// WithHostManagerV2 configures the plugin to run the host operations with the host manager, the host helpers
+// are used through AdaptToV2 by default
+func WithHostManagerV2(hostManager host.HostManagerV2Interface) Option {
+	return func(c *genericPluginOptions) {
+		c.hostManager = hostManager
+	}
+}
+
+// KernelArgSetter adds the kernel arg to the bootloader configuration with the kernel args backend,
+// it returns true if the bootloader configuration was changed
+type KernelArgSetter func(karg, backend string) (bool, error)
+
+// WithKernelArgSetter configures generic_plugin to add the kernel args to the bootloader configuration
+// with the provided setter instead of the kernel args script, e.g. in tests which don't change the host
+func WithKernelArgSetter(setter KernelArgSetter) Option {
+	return func(c *genericPluginOptions) {
+		c.kernelArgSetter = setter
+	}
+}
+
+// WithTunedKernelParams configures generic_plugin to add the kernel args to a tuned profile if tuned
+// manages the host, the kernel args script is used otherwise
+func WithTunedKernelParams() Option {
+	return func(c *genericPluginOptions) {
+		c.useTuned = true
+	}
+}
+
+// WithKernelParamManager configures generic_plugin to add the kernel args to the boot configuration
+// with the provided manager, see WithTunedKernelParams for the default manager
+func WithKernelParamManager(manager KernelParamManager) Option {
+	return func(c *genericPluginOptions) {
+		c.kernelParamManager = manager
+	}
+}
+
 type genericPluginOptions struct {
 	skipVFConfiguration     bool
 	skipBridgeConfiguration bool
+	persistDriverLoad       bool
+	ignoreBondedInterfaces  bool
+	hostMountPath           string
+	skipDevicesConfigMap    string
+	kernelParamSource       KernelParamConfigSource
+	pfSkippers              []plugin.PFSkipper
+	totalVfsRaisers         []plugin.TotalVfsRaiser
+	kubeClient              client.Client
+	watchdogInterval        time.Duration
+	kernelParamGracePeriod  time.Duration
+	// kernelParamSetCooldown is the time during which a kernel arg which was set successfully is not set again
+	kernelParamSetCooldown time.Duration
+	maxKernelParamAttempts int
+	applyTimeout           time.Duration
+	// vfAttributeReconcileInterval is the interval of the reconciliation of the VF attributes, disabled if not positive
+	vfAttributeReconcileInterval time.Duration
+	// successfulReconcileSkipDuration is the time during which the successfully configured PFs are not configured again
+	successfulReconcileSkipDuration time.Duration
+	kernelVersionRequirements       map[string]string
+	requiredKernelFeatures          []string
+	drainStrategy                   DrainStrategy
+	eventSender                     EventSender
+	eventBatchWindow                time.Duration
+	remediateGhostVFs               bool
+	hostManager                     host.HostManagerV2Interface
+	kernelArgSetter                 KernelArgSetter
+	kernelParamManager              KernelParamManager
+	useTuned                        bool
 }
 
 const scriptsPath = "bindata/scripts/enable-kargs.sh"
 
+// defaultKernelParamGracePeriod is the default time to wait for the kernel args to appear in the kernel cmdline
+const defaultKernelParamGracePeriod = 60 * time.Second
+
+// defaultKernelParamSetCooldown is the default time during which a kernel arg which was set is not set again
+const defaultKernelParamSetCooldown = 60 * time.Second
+
+// cleanupOrphanedVFNetNS moves the VF netdev from the network namespace of a deleted pod, overridden in unit-tests
+var cleanupOrphanedVFNetNS = utils.CleanupOrphanedVFNetNS
+
+// configureVFBridgeVLAN adds the VLANs to the bridge port of the VF, overridden in unit-tests
+var configureVFBridgeVLAN = utils.ConfigureVFBridgeVLAN
+
+// configureVFQinQ sets the double VLAN of the VF, overridden in unit-tests
+var configureVFQinQ = utils.ConfigureVFQinQ
+
+// configureVFRSS sets the RSS hash key and hash fields of the VF, overridden in unit-tests
+var configureVFRSS = utils.ConfigureVFRSS
+
+// setIRQAffinity sets the CPUs which handle the IRQ, overridden in unit-tests
+var setIRQAffinity = utils.SetIRQAffinity
+
+// readLLDPDCBXConfig and applyDCBXToVFs read the ETS configuration of the switch connected to the PF
+// and configure the traffic classes of the VFs with it, overridden in unit-tests
+var (
+	readLLDPDCBXConfig = utils.ReadLLDPDCBXConfig
+	applyDCBXToVFs     = utils.ApplyDCBXToVFs
+)
+
+// configureOVSDPDKVFs and cleanupOVSDPDKVFs add and remove the OVS ports and the TC rules of the representors
+// of the VFs for the OVS-DPDK offload, overridden in unit-tests
+var (
+	configureOVSDPDKVFs = utils.ConfigureOVSDPDKVFs
+	cleanupOVSDPDKVFs   = utils.CleanupOVSDPDKVFs
+)
+
+// kernelArgPollInterval is the interval of the kernel cmdline checks during the grace period, overridden in unit-tests
+var kernelArgPollInterval = 5 * time.Second
+
 // Initialize our plugin and set up initial values
 func NewGenericPlugin(helpers helper.HostHelpersInterface, options ...Option) (plugin.VendorPlugin, error) {
-	cfg := &genericPluginOptions{}
+	cfg := &genericPluginOptions{hostMountPath: consts.Host,
+		kernelParamGracePeriod: defaultKernelParamGracePeriod, kernelVersionRequirements: defaultKernelVersionRequirements,
+		drainStrategy: ConservativeDrainStrategy{}, eventBatchWindow: defaultEventBatchWindow,
+		vfAttributeReconcileInterval: vars.VfAttributeReconcileInterval, kernelParamSetCooldown: defaultKernelParamSetCooldown}
 	for _, o := range options {
 		o(cfg)
 	}
+	if cfg.hostManager == nil {
+		cfg.hostManager = host.AdaptToV2(helpers)
+	}
+	if cfg.kernelParamManager == nil {
+		cfg.kernelParamManager = newKernelParamManager(cfg.hostManager, helpers, cfg.kernelArgSetter, cfg.useTuned)
+	}
+	pfSkippers := make(map[string]plugin.PFSkipper)
+	for _, skipper := range cfg.pfSkippers {
+		pfSkippers[skipper.VendorID()] = skipper
+	}
+	totalVfsRaisers := make(map[string]plugin.TotalVfsRaiser)
+	for _, raiser := range cfg.totalVfsRaisers {
+		totalVfsRaisers[raiser.VendorID()] = raiser
+	}
 	driverStateMap := make(map[uint]*DriverState)
 	driverStateMap[Vfio] = &DriverState{
 		DriverName:     vfioPciDriver,
@@ -96,6 +527,7 @@ func NewGenericPlugin(helpers helper.HostHelpersInterface, options ...Option) (p
 		VdpaType:       "",
 		NeedDriverFunc: needDriverCheckDeviceType,
 		DriverLoaded:   false,
+		ForceRebind:    true,
 	}
 	driverStateMap[VirtioVdpa] = &DriverState{
 		DriverName:     virtioVdpaDriver,
@@ -111,15 +543,100 @@ func NewGenericPlugin(helpers helper.HostHelpersInterface, options ...Option) (p
 		NeedDriverFunc: needDriverCheckVdpaType,
 		DriverLoaded:   false,
 	}
-	return &GenericPlugin{
-		PluginName:              PluginName,
-		SpecVersion:             "1.0",
-		DriverStateMap:          driverStateMap,
-		DesiredKernelArgs:       make(map[string]bool),
-		helpers:                 helpers,
-		skipVFConfiguration:     cfg.skipVFConfiguration,
-		skipBridgeConfiguration: cfg.skipBridgeConfiguration,
-	}, nil
+	driverStateMap[SfcResource] = &DriverState{
+		DriverName:     sfcResourceDriver,
+		VendorID:       consts.VendorSolarflare,
+		NeedDriverFunc: needDriverCheckVendor,
+		DriverLoaded:   false,
+	}
+	driverStateMap[SfcAffinity] = &DriverState{
+		DriverName:     sfcAffinityDriver,
+		VendorID:       consts.VendorSolarflare,
+		NeedDriverFunc: needDriverCheckVendor,
+		PostLoadFunc:   createSfcAffinityDevice,
+		DriverLoaded:   false,
+	}
+	driverStateMap[Ena] = &DriverState{
+		DriverName:     enaDriver,
+		VendorID:       consts.VendorAmazon,
+		DeviceID:       consts.DeviceIDEna,
+		ModuleParams:   []string{"large_llq_header=1"},
+		NeedDriverFunc: needDriverCheckVendor,
+		DriverLoaded:   false,
+	}
+	driverStateMap[QatCommon] = &DriverState{
+		DriverName:     qatCommonDriver,
+		VendorID:       consts.VendorIntel,
+		NeedDriverFunc: needDriverCheckQAT,
+		DriverLoaded:   false,
+	}
+	driverStateMap[QatDevice] = &DriverState{
+		DriverName:     qatDeviceDriver,
+		VendorID:       consts.VendorIntel,
+		NeedDriverFunc: needDriverCheckQAT,
+		DriverLoaded:   false,
+	}
+	driverStateMap[Ionic] = &DriverState{
+		DriverName:     ionicDriver,
+		VendorID:       consts.VendorPensando,
+		NeedDriverFunc: needDriverCheckIonic,
+		DriverLoaded:   false,
+	}
+	driverStateMap[IonicMnic] = &DriverState{
+		DriverName:     ionicMnicDriver,
+		VendorID:       consts.VendorPensando,
+		NeedDriverFunc: needDriverCheckIonic,
+		PostLoadFunc:   uploadIonicFirmware,
+		DriverLoaded:   false,
+	}
+	p := &GenericPlugin{
+		PluginName:                      PluginName,
+		SpecVersion:                     "1.0",
+		DriverStateMap:                  driverStateMap,
+		DesiredKernelArgs:               make(map[string]bool),
+		KernelArgsSetTime:               make(map[string]time.Time),
+		KernelArgAttempts:               make(map[string]int),
+		kernelParamLastSetAt:            make(map[string]time.Time),
+		KernelParamSetCooldown:          cfg.kernelParamSetCooldown,
+		MaxKernelParamAttempts:          cfg.maxKernelParamAttempts,
+		ApplyTimeout:                    cfg.applyTimeout,
+		InterfaceReconcileStatus:        make(map[string]ReconcileStatus),
+		SuccessfulReconcileSkipDuration: cfg.successfulReconcileSkipDuration,
+		KernelParamGracePeriod:          cfg.kernelParamGracePeriod,
+		helpers:                         helpers,
+		hostManager:                     cfg.hostManager,
+		skipVFConfiguration:             cfg.skipVFConfiguration,
+		skipBridgeConfiguration:         cfg.skipBridgeConfiguration,
+		PersistDriverLoad:               cfg.persistDriverLoad,
+		IgnoreBondedInterfaces:          cfg.ignoreBondedInterfaces,
+		hostMountPath:                   cfg.hostMountPath,
+		SkipPCIAddresses:                make(map[string]string),
+		skipDevicesConfigMap:            cfg.skipDevicesConfigMap,
+		pfSkippers:                      pfSkippers,
+		totalVfsRaisers:                 totalVfsRaisers,
+		kernelParamSource:               cfg.kernelParamSource,
+		KubeClient:                      cfg.kubeClient,
+		WatchdogInterval:                cfg.watchdogInterval,
+		VfAttributeReconcileInterval:    cfg.vfAttributeReconcileInterval,
+		KernelVersionRequirements:       maps.Clone(cfg.kernelVersionRequirements),
+		drainStrategy:                   cfg.drainStrategy,
+		EventBatchWindow:                cfg.eventBatchWindow,
+		remediateGhostVFs:               cfg.remediateGhostVFs,
+		kernelParamManager:              cfg.kernelParamManager,
+		lastStateChange:                 time.Now(),
+		metrics:                         newPluginMetrics(),
+	}
+	if cfg.kubeClient != nil {
+		p.conditionManager = NewConditionManager(cfg.kubeClient)
+	}
+	if cfg.eventSender != nil {
+		p.eventBatcher = NewEventBatcher(cfg.eventSender, cfg.eventBatchWindow)
+	}
+	if err := p.checkKernelVersion(context.Background(), cfg.requiredKernelFeatures); err != nil {
+		return nil, err
+	}
+	p.startVFAttributeReconciler()
+	return p, nil
 }
 
 // Name returns the name of the plugin
@@ -135,10 +652,25 @@ func (p *GenericPlugin) Spec() string {
 // OnNodeStateChange Invoked when SriovNetworkNodeState CR is created or updated, return if need drain and/or reboot node
 func (p *GenericPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeState) (needDrain bool, needReboot bool, err error) {
 	log.Log.Info("generic plugin OnNodeStateChange()")
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
 	p.DesireState = new
+	p.lastStateChange = time.Now()
 
-	needDrain = p.needDrainNode(new.Spec, new.Status)
-	needReboot, err = p.needRebootNode(new)
+	if errs := utils.ValidateNodeStateSpec(new); len(errs) > 0 {
+		err = errors.Join(errs...)
+		log.Log.Error(err, "generic plugin OnNodeStateChange(): invalid node state spec")
+		return false, false, err
+	}
+	if err = p.enforceVfResources(new); err != nil {
+		return false, false, err
+	}
+
+	p.syncSkipDevices()
+
+	ctx := context.Background()
+	needDrain = p.needDrainNode(ctx, new.Spec, new.Status)
+	needReboot, err = p.needRebootNode(ctx, new)
 	if err != nil {
 		return needDrain, needReboot, err
 	}
@@ -146,9 +678,109 @@ func (p *GenericPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeSt
 	if needReboot {
 		needDrain = true
 	}
+	p.metrics.observeNodeStateChange(needDrain, needReboot)
 	return
 }
 
+// enforceVfResources checks that the node state doesn't request more VFs than supported by the PFs,
+// the webhook rejects such policies so only soft overcommit is expected here and it is only logged
+func (p *GenericPlugin) enforceVfResources(state *sriovnetworkv1.SriovNetworkNodeState) error {
+	enforcer := &utils.VFResourceEnforcer{OvercommitRatio: vars.VfOvercommitRatio}
+	err := enforcer.EnforceNodeState(state)
+	if err == nil {
+		return nil
+	}
+	overcommitErr := &utils.VfOvercommitError{}
+	if errors.As(err, &overcommitErr) && !overcommitErr.Hard {
+		log.Log.Info("generic plugin enforceVfResources(): VFs are overcommitted", "error", err.Error())
+		return nil
+	}
+	log.Log.Error(err, "generic plugin enforceVfResources(): VF resources check failed")
+	return err
+}
+
+// ExportDesiredState returns the desired state the plugin is working with as a SriovNetworkNodeState YAML document
+func (p *GenericPlugin) ExportDesiredState() ([]byte, error) {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	if p.DesireState == nil {
+		return nil, fmt.Errorf("desired state is not set")
+	}
+	state := p.DesireState.DeepCopy()
+	// TypeMeta is not populated for objects received from the typed client
+	state.APIVersion = sriovnetworkv1.GroupVersion.String()
+	state.Kind = "SriovNetworkNodeState"
+	return yaml.Marshal(state)
+}
+
+// LoadDesiredStateFromYAML sets the desired state of the plugin from a SriovNetworkNodeState YAML document
+func (p *GenericPlugin) LoadDesiredStateFromYAML(data []byte) error {
+	state := &sriovnetworkv1.SriovNetworkNodeState{}
+	if err := yaml.UnmarshalStrict(data, state); err != nil {
+		return fmt.Errorf("failed to parse desired state: %v", err)
+	}
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	p.DesireState = state
+	return nil
+}
+
+// Clone returns a copy of the plugin which can be changed independently of the original,
+// the host helpers, the vendor PF skippers and the clients are shared with the original.
+// The watchdog of the original is not copied, it is not started for the clone.
+func (p *GenericPlugin) Clone() *GenericPlugin {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	driverStateMap := make(DriverStateMapType, len(p.DriverStateMap))
+	for id, driverState := range p.DriverStateMap {
+		driverStateCopy := *driverState
+		driverStateMap[id] = &driverStateCopy
+	}
+	return &GenericPlugin{
+		PluginName:                      p.PluginName,
+		SpecVersion:                     p.SpecVersion,
+		DesireState:                     p.DesireState.DeepCopy(),
+		DriverStateMap:                  driverStateMap,
+		DesiredKernelArgs:               maps.Clone(p.DesiredKernelArgs),
+		helpers:                         p.helpers,
+		hostManager:                     p.hostManager,
+		skipVFConfiguration:             p.skipVFConfiguration,
+		skipBridgeConfiguration:         p.skipBridgeConfiguration,
+		hostMountPath:                   p.hostMountPath,
+		paused:                          p.isPaused(),
+		PersistDriverLoad:               p.PersistDriverLoad,
+		IgnoreBondedInterfaces:          p.IgnoreBondedInterfaces,
+		SkipPCIAddresses:                maps.Clone(p.SkipPCIAddresses),
+		skipDevicesConfigMap:            p.skipDevicesConfigMap,
+		pfSkippers:                      p.pfSkippers,
+		totalVfsRaisers:                 p.totalVfsRaisers,
+		pfsToSkip:                       maps.Clone(p.pfsToSkip),
+		kernelParamSource:               p.kernelParamSource,
+		KernelArgsSetTime:               maps.Clone(p.KernelArgsSetTime),
+		KernelArgAttempts:               maps.Clone(p.KernelArgAttempts),
+		kernelParamLastSetAt:            maps.Clone(p.kernelParamLastSetAt),
+		KernelParamSetCooldown:          p.KernelParamSetCooldown,
+		MaxKernelParamAttempts:          p.MaxKernelParamAttempts,
+		ApplyTimeout:                    p.ApplyTimeout,
+		InterfaceReconcileStatus:        maps.Clone(p.InterfaceReconcileStatus),
+		SuccessfulReconcileSkipDuration: p.SuccessfulReconcileSkipDuration,
+		KernelParamGracePeriod:          p.KernelParamGracePeriod,
+		KubeClient:                      p.KubeClient,
+		conditionManager:                p.conditionManager,
+		drainStrategy:                   p.drainStrategy,
+		EventBatchWindow:                p.EventBatchWindow,
+		eventBatcher:                    p.eventBatcher,
+		remediateGhostVFs:               p.remediateGhostVFs,
+		ovsDPDKOffloads:                 maps.Clone(p.ovsDPDKOffloads),
+		kernelParamManager:              p.kernelParamManager,
+		WatchdogInterval:                p.WatchdogInterval,
+		IonicFirmwarePath:               p.IonicFirmwarePath,
+		VfAttributeReconcileInterval:    p.VfAttributeReconcileInterval,
+		lastStateChange:                 p.lastStateChange,
+		metrics:                         p.metrics,
+	}
+}
+
 // CheckStatusChanges verify whether SriovNetworkNodeState CR status present changes on configured VFs.
 func (p *GenericPlugin) CheckStatusChanges(current *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
 	log.Log.Info("generic-plugin CheckStatusChanges()")
@@ -166,84 +798,880 @@ func (p *GenericPlugin) CheckStatusChanges(current *sriovnetworkv1.SriovNetworkN
 				break
 			}
 		}
-		if !found {
-			log.Log.Info("CheckStatusChanges(): no status found for interface", "address", iface.PciAddress)
+		if !found {
+			log.Log.Info("CheckStatusChanges(): no status found for interface", "address", iface.PciAddress)
+		}
+	}
+
+	if p.shouldConfigureBridges() {
+		if sriovnetworkv1.NeedToUpdateBridges(&current.Spec.Bridges, &current.Status.Bridges) {
+			log.Log.Info("CheckStatusChanges(): bridge configuration needs to be updated")
+			return true, nil
+		}
+	}
+
+	missingKernelArgs, err := p.getMissingKernelArgs()
+	if err != nil {
+		log.Log.Error(err, "generic-plugin CheckStatusChanges(): failed to verify missing kernel arguments")
+		return false, err
+	}
+
+	if len(missingKernelArgs) != 0 {
+		log.Log.V(0).Info("generic-plugin CheckStatusChanges(): kernel args missing",
+			"kernelArgs", missingKernelArgs)
+	}
+
+	return len(missingKernelArgs) != 0, nil
+}
+
+func (p *GenericPlugin) syncDriverState(ctx context.Context) error {
+	requiredDrivers := []string{}
+	for _, driverState := range p.DriverStateMap {
+		needDriver := driverState.NeedDriverFunc(p.DesireState, driverState)
+		if needDriver {
+			requiredDrivers = append(requiredDrivers, driverState.DriverName)
+		}
+		if !driverState.DriverLoaded && needDriver {
+			alreadyLoaded, err := p.hostManager.IsKernelModuleLoaded(ctx, driverState.DriverName)
+			if err != nil {
+				log.Log.Error(err, "generic plugin syncDriverState(): fail to check if kmod is loaded", "name", driverState.DriverName)
+				return &DriverLoadError{DriverName: driverState.DriverName, Underlying: err}
+			}
+			if !alreadyLoaded {
+				log.Log.V(2).Info("loading driver", "name", driverState.DriverName)
+				if err := p.hostManager.LoadKernelModule(ctx, driverState.DriverName, driverState.ModuleParams...); err != nil {
+					log.Log.Error(err, "generic plugin syncDriverState(): fail to load kmod", "name", driverState.DriverName)
+					return &DriverLoadError{DriverName: driverState.DriverName, Underlying: err}
+				}
+				driverState.LoadedByPlugin = true
+			}
+			if driverState.PostLoadFunc != nil {
+				if err := driverState.PostLoadFunc(ctx, p); err != nil {
+					log.Log.Error(err, "generic plugin syncDriverState(): post-load step failed", "name", driverState.DriverName)
+					return &DriverLoadError{DriverName: driverState.DriverName, Underlying: err}
+				}
+			}
+			driverState.DriverLoaded = true
+			p.recordEvent(EventReasonDriverLoaded, fmt.Sprintf("Kernel driver %s has been loaded", driverState.DriverName))
+			if err := p.verifyDriverBinding(ctx, driverState); err != nil {
+				log.Log.Error(err, "generic plugin syncDriverState(): device is bound to wrong driver", "name", driverState.DriverName)
+				return &DriverLoadError{DriverName: driverState.DriverName, Underlying: err}
+			}
+		}
+	}
+	if p.PersistDriverLoad {
+		if err := p.hostManager.WriteModulesLoadConf(ctx, consts.ModulesLoadConfFile, requiredDrivers); err != nil {
+			log.Log.Error(err, "generic plugin syncDriverState(): fail to persist required kmods", "names", requiredDrivers)
+			return &DriverLoadError{DriverName: strings.Join(requiredDrivers, ","), Underlying: err}
+		}
+	}
+	return nil
+}
+
+// verifyDriverBinding checks that the VFs which require the loaded driver and are already bound to a driver
+// use it. VFs which have no driver are skipped, they are bound during the configuration of the interfaces.
+func (p *GenericPlugin) verifyDriverBinding(ctx context.Context, driverState *DriverState) error {
+	// vdpa devices are bound on the vdpa bus
+	if driverState.VdpaType != "" {
+		return nil
+	}
+	for _, iface := range p.DesireState.Spec.Interfaces {
+		ifaceStatus := p.DesireState.GetInterfaceStateByPciAddress(iface.PciAddress)
+		if ifaceStatus == nil {
+			continue
+		}
+		for _, group := range iface.VfGroups {
+			if group.DeviceType != driverState.DeviceType {
+				continue
+			}
+			for _, vf := range ifaceStatus.VFs {
+				if vf.Driver == "" || !sriovnetworkv1.IndexInRange(vf.VfID, group.VfRange) {
+					continue
+				}
+				err := p.hostManager.VerifyDriverBinding(ctx, vf.PciAddress, driverState.DeviceType)
+				if err == nil {
+					continue
+				}
+				if !driverState.ForceRebind {
+					return fmt.Errorf("%v: unbind the device from the current driver with "+
+						"'echo %s > /sys/bus/pci/devices/%s/driver/unbind' or enable ForceRebind", err, vf.PciAddress, vf.PciAddress)
+				}
+				log.Log.Info("generic plugin verifyDriverBinding(): rebind device", "device", vf.PciAddress,
+					"driver", driverState.DeviceType, "reason", err.Error())
+				if err := p.hostManager.BindDpdkDriver(ctx, vf.PciAddress, driverState.DeviceType); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Apply config change
+func (p *GenericPlugin) Apply() error {
+	return p.ApplyWithContext(context.Background())
+}
+
+// ApplyWithContext applies the desired state like Apply, the host operations which were not started
+// fail when the context is done, it is called by the daemon with a context canceled when the daemon stops
+func (p *GenericPlugin) ApplyWithContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	ctx, cancel := p.applyContext(ctx)
+	defer cancel()
+	return p.apply(ctx)
+}
+
+// applyContext returns the context of an apply which is canceled after ApplyTimeout
+func (p *GenericPlugin) applyContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.ApplyTimeout > 0 {
+		return context.WithTimeout(ctx, p.ApplyTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// ForceApply applies the desired state again including the PFs configured successfully within
+// SuccessfulReconcileSkipDuration, the reconcile status of the PFs which were not configured
+// again is restored if the apply fails
+func (p *GenericPlugin) ForceApply() error {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	ctx, cancel := p.applyContext(context.Background())
+	defer cancel()
+	reconcileStatus := p.InterfaceReconcileStatus
+	p.InterfaceReconcileStatus = make(map[string]ReconcileStatus)
+	err := p.apply(ctx)
+	if err != nil {
+		for pciAddress, ifaceStatus := range reconcileStatus {
+			if _, ok := p.InterfaceReconcileStatus[pciAddress]; !ok {
+				p.InterfaceReconcileStatus[pciAddress] = ifaceStatus
+			}
+		}
+	}
+	return err
+}
+
+func (p *GenericPlugin) apply(ctx context.Context) (err error) {
+	if p.isPaused() {
+		log.Log.Info("generic plugin Apply(): plugin is paused, skipping")
+		return plugin.ErrPluginPaused
+	}
+	start := time.Now()
+	p.setApplyStartedConditions()
+	defer func() {
+		if err == nil {
+			p.recordLastApply(start, time.Since(start))
+		}
+		p.metrics.observeApply(err, time.Since(start))
+		p.setApplyFinishedConditions(err)
+	}()
+	log.Log.Info("generic plugin Apply()", "desiredState", p.DesireState.Spec)
+
+	if err := p.syncDriverState(ctx); err != nil {
+		return err
+	}
+
+	p.cleanupOrphanedVFNetNS()
+
+	if p.needVhostNet() {
+		if err := p.hostManager.EnsureVhostNet(ctx); err != nil {
+			return &DriverLoadError{DriverName: "vhost_net", Underlying: err}
+		}
+	}
+
+	if err := p.syncHugepages(ctx); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	// vendor plugins are applied before the generic plugin, so their state is up to date
+	if err := p.syncPFsToSkip(); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncGhostVFs(ctx); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	// lldptool is shipped in the config daemon image, the DCBX configuration is read before the chroot
+	dcbxConfigs, err := p.readDCBXConfigs()
+	if err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	// When calling from systemd do not try to chroot
+	if !vars.UsingSystemdMode {
+		if err := validateHostMount(p.hostMountPath); err != nil {
+			log.Log.Error(err, "generic plugin Apply(): host filesystem is not mounted properly", "path", p.hostMountPath)
+			return &ChrootError{Path: p.hostMountPath, Underlying: err}
+		}
+		exit, err := p.helpers.Chroot(p.hostMountPath)
+		if err != nil {
+			return &ChrootError{Path: p.hostMountPath, Underlying: err}
+		}
+		defer exit()
+	}
+
+	if err := p.syncRdmaMode(ctx); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	p.refreshPFNames(ctx)
+	interfaces, interfaceStatuses := p.filterRecentlyReconciled(p.filterSkippedDevices(p.DesireState.Spec.Interfaces),
+		p.filterSkippedDevicesStatus(p.DesireState.Status.Interfaces))
+	if err := p.checkBondedPFs(interfaces, interfaceStatuses); err != nil {
+		return newSyncNodeStateError(err)
+	}
+	if !p.skipVFConfiguration {
+		if err := p.checkTotalVfs(interfaces, interfaceStatuses); err != nil {
+			return newSyncNodeStateError(err)
+		}
+	}
+	err = p.hostManager.ConfigSriovInterfaces(ctx, p.helpers, sortVfGroups(interfaces), interfaceStatuses, p.skipVFConfiguration)
+	p.updateReconcileStatus(interfaces, err)
+	if err != nil {
+		if barErrs := hostTypes.GetVFBarAllocationErrors(err); len(barErrs) > 0 {
+			return newSyncNodeStateError(p.handleVFBarAllocationErrors(ctx, barErrs, err))
+		}
+		return newSyncNodeStateError(eswitchModeSyncError(err))
+	}
+
+	if err := p.syncVFBridgeVLAN(interfaces); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncVFQinQ(interfaces); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncVFRSS(ctx, interfaces); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncDCBX(interfaces, dcbxConfigs); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncIRQAffinity(ctx, interfaces); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncOVSDPDKOffload(interfaces); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncEncapOffload(ctx); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncPfSettings(ctx); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if err := p.syncModprobeBlacklist(ctx); err != nil {
+		return newSyncNodeStateError(err)
+	}
+
+	if p.shouldConfigureBridges() {
+		if err := p.hostManager.ConfigureBridges(ctx, p.DesireState.Spec.Bridges, p.DesireState.Status.Bridges); err != nil {
+			return newSyncNodeStateError(err)
+		}
+	}
+
+	return nil
+}
+
+// refreshPFNames resolves the netdev names of the PFs from their PCI addresses. The names in the node state
+// are recorded at discovery time, the PF may have been renamed by udev since or the name may now belong to
+// another port (e.g. after a NIC swap). The names of the spec and of the status are replaced with the current
+// names so that all the operations of the apply use them, the status is rediscovered after the sync.
+// The PFs without netdev keep their recorded name.
+func (p *GenericPlugin) refreshPFNames(ctx context.Context) {
+	if vars.NetdevsimMode {
+		// the netdevsim devices are not PCI devices
+		return
+	}
+	names := make(map[string]string, len(p.DesireState.Status.Interfaces))
+	for i := range p.DesireState.Status.Interfaces {
+		ifaceStatus := &p.DesireState.Status.Interfaces[i]
+		name := p.hostManager.TryGetInterfaceName(ctx, ifaceStatus.PciAddress)
+		if name == "" {
+			continue
+		}
+		names[ifaceStatus.PciAddress] = name
+		if name != ifaceStatus.Name {
+			log.Log.Info("generic plugin refreshPFNames(): PF was renamed since the discovery",
+				"device", ifaceStatus.PciAddress, "recorded", ifaceStatus.Name, "current", name)
+			ifaceStatus.Name = name
+		}
+	}
+	for i := range p.DesireState.Spec.Interfaces {
+		if name, ok := names[p.DesireState.Spec.Interfaces[i].PciAddress]; ok {
+			p.DesireState.Spec.Interfaces[i].Name = name
+		}
+	}
+}
+
+// cleanupOrphanedVFNetNS moves the netdevs of the VFs left in the network namespaces of deleted pods
+// back to the root network namespace. Only the VFs bound to a netdevice driver which netdev was not
+// discovered are checked, the failures are logged and don't prevent the configuration of the PFs.
+func (p *GenericPlugin) cleanupOrphanedVFNetNS() {
+	for _, ifaceStatus := range p.filterSkippedDevicesStatus(p.DesireState.Status.Interfaces) {
+		for _, vf := range ifaceStatus.VFs {
+			if vf.Driver == "" || vf.Name != "" || sriovnetworkv1.StringInArray(vf.Driver, vars.DpdkDrivers) {
+				continue
+			}
+			if err := cleanupOrphanedVFNetNS(vf.PciAddress); err != nil {
+				log.Log.Error(err, "generic plugin cleanupOrphanedVFNetNS(): failed to clean up VF network namespace",
+					"device", vf.PciAddress)
+			}
+		}
+	}
+}
+
+// handleVFBarAllocationErrors queues the pci=realloc kernel arg when the kernel failed to allocate the MMIO space
+// of the VFs, the kernel reassigns the BARs of the bridges on the next boot with it. If pci=realloc is already set,
+// another reboot doesn't help and MMIOSpaceExhaustedError is returned instead, the BIOS settings must be fixed.
+func (p *GenericPlugin) handleVFBarAllocationErrors(ctx context.Context, barErrs []*hostTypes.VFBarAllocationError, err error) error {
+	cmdLine, cmdLineErr := p.hostManager.GetCurrentKernelArgs(ctx)
+	if cmdLineErr != nil {
+		log.Log.Error(cmdLineErr, "generic plugin handleVFBarAllocationErrors(): failed to read kernel cmdline")
+		return err
+	}
+	if p.hostManager.IsKernelArgsSet(ctx, cmdLine, consts.KernelArgPciRealloc) {
+		log.Log.Error(err, "generic plugin handleVFBarAllocationErrors(): VFs can't be created with pci=realloc set")
+		return &MMIOSpaceExhaustedError{Allocations: barErrs, Underlying: err}
+	}
+	if _, queued := p.DesiredKernelArgs[consts.KernelArgPciRealloc]; !queued {
+		log.Log.Info("generic plugin handleVFBarAllocationErrors(): queue pci=realloc to allocate the MMIO space of the VFs",
+			"error", err.Error())
+		p.addToDesiredKernelArgs(consts.KernelArgPciRealloc)
+		// the node is rebooted for PCI realloc, set the configured parameters during the same reboot
+		p.addConfiguredKernelArgs()
+	}
+	return err
+}
+
+// eswitchModeSyncError prefixes eSwitch mode change failures with the action expected from the user
+func eswitchModeSyncError(err error) error {
+	switch {
+	case errors.Is(err, hostTypes.ErrEswitchModeNotSupported):
+		return fmt.Errorf("NIC does not support switchdev: %w", err)
+	case errors.Is(err, hostTypes.ErrEswitchModeVFsBound):
+		return fmt.Errorf("unbind VFs first: %w", err)
+	}
+	return err
+}
+
+// recordLastApply annotates the node state with the time and duration of the apply,
+// failures are only logged because the configuration was applied
+func (p *GenericPlugin) recordLastApply(start time.Time, duration time.Duration) {
+	if p.KubeClient == nil || p.DesireState == nil {
+		return
+	}
+	newState := p.DesireState.DeepCopy()
+	annotations := newState.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[consts.LastApplyTimeAnnotation] = start.UTC().Format(time.RFC3339)
+	annotations[consts.LastApplyDurationAnnotation] = strconv.FormatInt(duration.Milliseconds(), 10)
+	newState.SetAnnotations(annotations)
+	if err := p.KubeClient.Patch(context.Background(), newState, client.MergeFrom(p.DesireState)); err != nil {
+		log.Log.Error(err, "generic plugin recordLastApply(): failed to annotate node state")
+	}
+}
+
+type hugepagesKey struct {
+	numaNode int
+	size     string
+}
+
+// syncHugepages allocates the hugepages requested for the interfaces in the desired state,
+// requests of the interfaces on the same NUMA node are not summed up, the highest count is used.
+// The kernel may allocate less hugepages than requested if the memory is fragmented, the shortfall
+// is reported with an event on the node state and doesn't fail the apply, the VFs are still configured
+func (p *GenericPlugin) syncHugepages(ctx context.Context) error {
+	requests := map[hugepagesKey]int{}
+	for _, iface := range p.filterSkippedDevices(p.DesireState.Spec.Interfaces) {
+		if iface.Hugepages == nil || iface.Hugepages.Count == 0 {
+			continue
+		}
+		key := hugepagesKey{numaNode: -1, size: iface.Hugepages.Size}
+		if iface.Hugepages.SameNUMAAsNic {
+			numaNode, err := p.hostManager.GetDeviceNumaNode(ctx, iface.PciAddress)
+			if err != nil {
+				return err
+			}
+			key.numaNode = numaNode
+		}
+		if iface.Hugepages.Count > requests[key] {
+			requests[key] = iface.Hugepages.Count
+		}
+	}
+
+	for key, count := range requests {
+		allocated, err := p.hostManager.SetHugepages(ctx, key.numaNode, key.size, count)
+		if err != nil {
+			return err
+		}
+		if allocated < count {
+			log.Log.Info("generic plugin syncHugepages(): failed to allocate all the requested hugepages",
+				"numaNode", key.numaNode, "size", key.size, "requested", count, "allocated", allocated)
+			p.recordEvent(EventReasonHugepagesShortfall, fmt.Sprintf("%d of %d %s hugepages allocated on NUMA node %d",
+				allocated, count, key.size, key.numaNode))
+		}
+	}
+	return nil
+}
+
+// syncRdmaMode sets the network namespace mode of the RDMA subsystem requested by the policies,
+// the mode can be changed only when the RDMA devices are not used, the node is drained before
+func (p *GenericPlugin) syncRdmaMode(ctx context.Context) error {
+	desired := p.DesireState.Spec.System.RdmaMode
+	if desired == "" {
+		return nil
+	}
+	current, err := p.hostManager.GetRDMASubsystemNetnsMode(ctx)
+	if err != nil {
+		return err
+	}
+	if current == desired {
+		return nil
+	}
+	log.Log.Info("generic plugin syncRdmaMode(): update RDMA subsystem netns mode", "current", current, "desired", desired)
+	return p.hostManager.SetRDMASubsystemNetnsMode(ctx, desired)
+}
+
+// syncEncapOffload configures the VXLAN and Geneve segmentation offload of the PFs which request it,
+// the offload is changed without draining the node and only if it differs from the current state
+func (p *GenericPlugin) syncEncapOffload(ctx context.Context) error {
+	for _, iface := range p.filterSkippedDevices(p.DesireState.Spec.Interfaces) {
+		if iface.VxlanOffload == nil && iface.GeneveOffload == nil {
+			continue
+		}
+		vxlan, geneve, err := p.hostManager.GetEncapOffloadState(ctx, iface.Name)
+		if err != nil {
+			return &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
+		}
+		if !needEncapOffloadUpdate(&iface, vxlan, geneve) {
+			continue
+		}
+		vxlan = iface.VxlanOffload != nil && *iface.VxlanOffload
+		geneve = iface.GeneveOffload != nil && *iface.GeneveOffload
+		log.Log.Info("generic plugin syncEncapOffload(): update encapsulation offload",
+			"device", iface.Name, "vxlan", vxlan, "geneve", geneve)
+		if err := p.hostManager.ConfigureEncapOffload(ctx, iface.Name, vxlan, geneve); err != nil {
+			return &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
+		}
+	}
+	return nil
+}
+
+// syncVFBridgeVLAN adds the VLANs requested by the VF groups to the bridge ports of the VFs,
+// the VLAN filters are changed without draining the node. VFs which are not bound to a kernel
+// driver have no bridge port and are skipped.
+func (p *GenericPlugin) syncVFBridgeVLAN(interfaces sriovnetworkv1.Interfaces) error {
+	if p.skipVFConfiguration {
+		return nil
+	}
+	for _, iface := range sortVfGroups(interfaces) {
+		for vfID := 0; vfID < iface.NumVfs; vfID++ {
+			for _, group := range iface.VfGroups {
+				if !sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+					continue
+				}
+				if len(group.VLANFilter) > 0 && (group.DeviceType == "" || group.DeviceType == consts.DeviceTypeNetDevice) {
+					if err := configureVFBridgeVLAN(p.helpers, iface.Name, vfID, group.VLANFilter); err != nil {
+						return &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
+					}
+				}
+				// the VF belongs to the first group which contains its index
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// syncVFQinQ sets the double VLAN requested by the VF groups on the VFs, a VF belongs to the first group
+// which contains its index
+func (p *GenericPlugin) syncVFQinQ(interfaces sriovnetworkv1.Interfaces) error {
+	if p.skipVFConfiguration {
+		return nil
+	}
+	for _, iface := range sortVfGroups(interfaces) {
+		for vfID := 0; vfID < iface.NumVfs; vfID++ {
+			for _, group := range iface.VfGroups {
+				if !sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+					continue
+				}
+				if group.OuterVLAN != nil && group.InnerVLAN != nil {
+					if err := configureVFQinQ(p.helpers, iface.Name, vfID, *group.OuterVLAN, *group.InnerVLAN); err != nil {
+						return &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
+					}
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// syncVFRSS sets the RSS configuration requested by the VF groups on the VF netdevices, a VF belongs to the first
+// group which contains its index. The configuration is read first and only the VFs which differ are updated,
+// the VFs without netdevice in the host network namespace are skipped.
+func (p *GenericPlugin) syncVFRSS(ctx context.Context, interfaces sriovnetworkv1.Interfaces) error {
+	if p.skipVFConfiguration {
+		return nil
+	}
+	for _, iface := range sortVfGroups(interfaces) {
+		for vfID := 0; vfID < iface.NumVfs; vfID++ {
+			for _, group := range iface.VfGroups {
+				if !sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+					continue
+				}
+				if err := p.syncVFGroupRSS(ctx, iface.Name, vfID, &group); err != nil {
+					return &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// syncVFGroupRSS sets the RSS configuration of the VF group on the VF if it differs from the current one
+func (p *GenericPlugin) syncVFGroupRSS(ctx context.Context, pfName string, vfID int, group *sriovnetworkv1.VfGroup) error {
+	if group.RSSHashKey == nil && len(group.RSSHashFields) == 0 {
+		return nil
+	}
+	hashKey := ""
+	if group.RSSHashKey != nil {
+		var err error
+		if hashKey, err = utils.NormalizeRSSHashKey(*group.RSSHashKey); err != nil {
+			return err
+		}
+	}
+	current, err := p.hostManager.GetVFRSSConfig(ctx, pfName, vfID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		// the RSS configuration moves with the netdevice, the VF is configured when it's returned to the host
+		return nil
+	}
+	if hashKey == current.HashKey {
+		hashKey = ""
+	}
+	fields := group.RSSHashFields
+	if len(fields) > 0 && sameRSSHashFields(fields, current.HashFields) {
+		fields = nil
+	}
+	if hashKey == "" && len(fields) == 0 {
+		return nil
+	}
+	log.Log.Info("generic plugin syncVFRSS(): update VF RSS configuration", "pf", pfName, "vfID", vfID,
+		"hashKeyChanged", hashKey != "", "hashFields", fields)
+	return configureVFRSS(p.helpers, pfName, vfID, hashKey, fields)
+}
+
+// sameRSSHashFields returns true if both lists contain the same hash fields
+func sameRSSHashFields(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// syncIRQAffinity sets the affinity of the IRQs of the VFs of the PFs which request it, the VFs created by
+// this apply are not in the status yet, their IRQs are configured by the next apply
+func (p *GenericPlugin) syncIRQAffinity(ctx context.Context, interfaces sriovnetworkv1.Interfaces) error {
+	if p.skipVFConfiguration {
+		return nil
+	}
+	var errs []error
+	for _, iface := range interfaces {
+		if iface.IRQAffinity == nil {
+			continue
+		}
+		cpus, err := cpuset.Parse(string(*iface.IRQAffinity))
+		if err != nil {
+			errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress,
+				Err: fmt.Errorf("invalid irqAffinity %q: %v", *iface.IRQAffinity, err)})
+			continue
+		}
+		ifaceStatus := p.DesireState.GetInterfaceStateByPciAddress(iface.PciAddress)
+		if ifaceStatus == nil {
+			continue
+		}
+		if err := p.setVFsIRQAffinity(ctx, ifaceStatus, cpus.String()); err != nil {
+			errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *GenericPlugin) setVFsIRQAffinity(ctx context.Context, ifaceStatus *sriovnetworkv1.InterfaceExt, cpus string) error {
+	for _, vf := range ifaceStatus.VFs {
+		irqs, err := p.hostManager.ListVFInterrupts(ctx, vf.PciAddress)
+		if err != nil {
+			return fmt.Errorf("failed to list the IRQs of VF %s: %v", vf.PciAddress, err)
+		}
+		for _, irq := range irqs {
+			if err := setIRQAffinity(irq, cpus); err != nil {
+				return fmt.Errorf("failed to set the IRQ affinity of VF %s: %v", vf.PciAddress, err)
+			}
+		}
+		log.Log.V(2).Info("generic plugin setVFsIRQAffinity(): IRQ affinity set", "device", vf.PciAddress,
+			"irqs", irqs, "cpus", cpus)
+	}
+	return nil
+}
+
+// readDCBXConfigs returns the ETS configuration received from the switch via LLDP/DCBX by PCI address of the PFs
+// which request the automatic configuration of the traffic classes of their VFs
+func (p *GenericPlugin) readDCBXConfigs() (map[string]*utils.DCBXConfig, error) {
+	configs := map[string]*utils.DCBXConfig{}
+	if p.skipVFConfiguration {
+		return configs, nil
+	}
+	for _, iface := range p.filterSkippedDevices(p.DesireState.Spec.Interfaces) {
+		if !iface.DCBXAutoConfig || iface.NumVfs == 0 {
+			continue
+		}
+		cfg, err := readLLDPDCBXConfig(p.helpers, iface.Name)
+		if err != nil {
+			return nil, &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
 		}
+		configs[iface.PciAddress] = cfg
 	}
+	return configs, nil
+}
 
-	if p.shouldConfigureBridges() {
-		if sriovnetworkv1.NeedToUpdateBridges(&current.Spec.Bridges, &current.Status.Bridges) {
-			log.Log.Info("CheckStatusChanges(): bridge configuration needs to be updated")
-			return true, nil
+// syncDCBX configures the traffic classes of the VF netdevices with the ETS configuration of the switch
+// connected to the PF, the configuration is applied without draining the node
+func (p *GenericPlugin) syncDCBX(interfaces sriovnetworkv1.Interfaces, configs map[string]*utils.DCBXConfig) error {
+	for _, iface := range interfaces {
+		cfg, ok := configs[iface.PciAddress]
+		if !ok {
+			continue
+		}
+		if err := applyDCBXToVFs(p.helpers, iface.Name, cfg, iface.NumVfs); err != nil {
+			return &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
 		}
 	}
+	return nil
+}
 
-	missingKernelArgs, err := p.getMissingKernelArgs()
-	if err != nil {
-		log.Log.Error(err, "generic-plugin CheckStatusChanges(): failed to verify missing kernel arguments")
-		return false, err
+// ovsDPDKOffload contains the VFs of a PF configured for the OVS-DPDK offload
+type ovsDPDKOffload struct {
+	pfName         string
+	representorMap map[int]string
+}
+
+// syncOVSDPDKOffload configures the VFs of the PFs which request it for the OVS-DPDK offload. The OVS ports
+// and the TC rules configured by the previous applies which are not requested anymore are removed first,
+// including the ones of the PFs removed from the spec. The PFs which are not configured by this apply keep
+// their configuration.
+func (p *GenericPlugin) syncOVSDPDKOffload(interfaces sriovnetworkv1.Interfaces) error {
+	if p.skipVFConfiguration {
+		return nil
+	}
+	desired := map[string]sriovnetworkv1.Interface{}
+	for _, iface := range p.filterSkippedDevices(p.DesireState.Spec.Interfaces) {
+		if iface.OVSDPDKOffload && iface.NumVfs > 0 {
+			desired[iface.PciAddress] = iface
+		}
+	}
+	var errs []error
+	pfAddresses := make([]string, 0, len(p.ovsDPDKOffloads))
+	for pciAddress := range p.ovsDPDKOffloads {
+		pfAddresses = append(pfAddresses, pciAddress)
+	}
+	slices.Sort(pfAddresses)
+	for _, pciAddress := range pfAddresses {
+		if p.isDeviceSkipped(pciAddress) {
+			continue
+		}
+		applied := p.ovsDPDKOffloads[pciAddress]
+		iface, ok := desired[pciAddress]
+		stale := map[int]string{}
+		for vfID, port := range applied.representorMap {
+			if !ok || vfID >= iface.NumVfs || iface.RepresentorMapping[vfID] != port {
+				stale[vfID] = port
+			}
+		}
+		if len(stale) == 0 {
+			continue
+		}
+		log.Log.Info("generic plugin syncOVSDPDKOffload(): remove the OVS-DPDK offload of the VFs",
+			"device", pciAddress, "representorMap", stale)
+		if err := cleanupOVSDPDKVFs(p.helpers, applied.pfName, stale); err != nil {
+			errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: pciAddress, Err: err})
+			continue
+		}
+		remaining := map[int]string{}
+		for vfID, port := range applied.representorMap {
+			if _, ok := stale[vfID]; !ok {
+				remaining[vfID] = port
+			}
+		}
+		if len(remaining) == 0 {
+			delete(p.ovsDPDKOffloads, pciAddress)
+			continue
+		}
+		p.ovsDPDKOffloads[pciAddress] = ovsDPDKOffload{pfName: applied.pfName, representorMap: remaining}
 	}
+	for _, iface := range interfaces {
+		if _, ok := desired[iface.PciAddress]; !ok || len(iface.RepresentorMapping) == 0 {
+			continue
+		}
+		if err := configureOVSDPDKVFs(p.helpers, iface.Name, iface.NumVfs, iface.RepresentorMapping); err != nil {
+			// the VFs configured by the call are cleaned up on failure
+			delete(p.ovsDPDKOffloads, iface.PciAddress)
+			errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err})
+			continue
+		}
+		representorMap := map[int]string{}
+		for vfID, port := range iface.RepresentorMapping {
+			if vfID >= 0 && vfID < iface.NumVfs {
+				representorMap[vfID] = port
+			}
+		}
+		if p.ovsDPDKOffloads == nil {
+			p.ovsDPDKOffloads = map[string]ovsDPDKOffload{}
+		}
+		p.ovsDPDKOffloads[iface.PciAddress] = ovsDPDKOffload{pfName: iface.Name, representorMap: representorMap}
+	}
+	return errors.Join(errs...)
+}
 
-	if len(missingKernelArgs) != 0 {
-		log.Log.V(0).Info("generic-plugin CheckStatusChanges(): kernel args missing",
-			"kernelArgs", missingKernelArgs)
+// needEncapOffloadUpdate returns true if the current encapsulation offload state of the PF differs from the desired one,
+// VXLAN and Geneve share the same offload on the host so it must be enabled if any of the set options is enabled
+func needEncapOffloadUpdate(iface *sriovnetworkv1.Interface, vxlan, geneve bool) bool {
+	desired := false
+	for _, setting := range []*bool{iface.VxlanOffload, iface.GeneveOffload} {
+		desired = desired || (setting != nil && *setting)
 	}
+	return desired != (vxlan || geneve)
+}
 
-	return len(missingKernelArgs) != 0, nil
+// validateHostMount checks that the provided mount point contains a valid host filesystem
+// before the plugin chroots into it
+func validateHostMount(mountPoint string) error {
+	root := filepath.Join(vars.FilesystemRoot, mountPoint)
+	for _, path := range []string{"/proc/version", consts.SysBus + "/pci"} {
+		if _, err := os.Stat(filepath.Join(root, path)); err != nil {
+			return fmt.Errorf("invalid host mount %s: %s is not accessible: %v", mountPoint, path, err)
+		}
+	}
+	devices, err := os.ReadDir(filepath.Join(root, consts.SysBusPciDevices))
+	if err != nil {
+		return fmt.Errorf("invalid host mount %s: failed to list PCI devices: %v", mountPoint, err)
+	}
+	if len(devices) == 0 {
+		return fmt.Errorf("invalid host mount %s: no PCI devices found in %s", mountPoint, consts.SysBusPciDevices)
+	}
+	return nil
 }
 
-func (p *GenericPlugin) syncDriverState() error {
-	for _, driverState := range p.DriverStateMap {
-		if !driverState.DriverLoaded && driverState.NeedDriverFunc(p.DesireState, driverState) {
-			log.Log.V(2).Info("loading driver", "name", driverState.DriverName)
-			if err := p.helpers.LoadKernelModule(driverState.DriverName); err != nil {
-				log.Log.Error(err, "generic plugin syncDriverState(): fail to load kmod", "name", driverState.DriverName)
-				return err
+// needVhostNet returns true if any VF group in the desired state requires vhost-net on the host
+func (p *GenericPlugin) needVhostNet() bool {
+	for _, iface := range p.DesireState.Spec.Interfaces {
+		for _, group := range iface.VfGroups {
+			if group.NeedVhostNet || group.VdpaType == consts.VdpaTypeVhost {
+				return true
 			}
-			driverState.DriverLoaded = true
 		}
 	}
+	return false
+}
+
+// Pause stops applying configuration changes, Apply returns ErrPluginPaused until Resume is called
+func (p *GenericPlugin) Pause() error {
+	p.pauseLock.Lock()
+	defer p.pauseLock.Unlock()
+	log.Log.Info("generic plugin Pause()")
+	p.paused = true
 	return nil
 }
 
-// Apply config change
-func (p *GenericPlugin) Apply() error {
-	log.Log.Info("generic plugin Apply()", "desiredState", p.DesireState.Spec)
+// Resume clears the pause, the desired state is applied by the next node state sync
+// which also handles the drain and the reboot required by the changes received during the pause
+func (p *GenericPlugin) Resume() error {
+	p.pauseLock.Lock()
+	defer p.pauseLock.Unlock()
+	log.Log.Info("generic plugin Resume()")
+	p.paused = false
+	return nil
+}
 
-	if err := p.syncDriverState(); err != nil {
-		return err
-	}
+func (p *GenericPlugin) isPaused() bool {
+	p.pauseLock.Lock()
+	defer p.pauseLock.Unlock()
+	return p.paused
+}
 
-	// When calling from systemd do not try to chroot
-	if !vars.UsingSystemdMode {
-		exit, err := p.helpers.Chroot(consts.Host)
-		if err != nil {
-			return err
+// syncModprobeBlacklist keeps kernel drivers off the VFs of the groups which have BlacklistKernelDriver set,
+// vfio-pci is configured to claim the VF device IDs before the kernel drivers of the PFs are loaded
+func (p *GenericPlugin) syncModprobeBlacklist(ctx context.Context) error {
+	deviceIDs := []string{}
+	pfDrivers := []string{}
+	for _, iface := range p.DesireState.Spec.Interfaces {
+		if !slices.ContainsFunc(iface.VfGroups, func(group sriovnetworkv1.VfGroup) bool {
+			return group.BlacklistKernelDriver && group.DeviceType == consts.DeviceTypeVfioPci
+		}) {
+			continue
 		}
-		defer exit()
-	}
-
-	if err := p.helpers.ConfigSriovInterfaces(p.helpers, p.DesireState.Spec.Interfaces,
-		p.DesireState.Status.Interfaces, p.skipVFConfiguration); err != nil {
-		// Catch the "cannot allocate memory" error and try to use PCI realloc
-		if errors.Is(err, syscall.ENOMEM) {
-			p.addToDesiredKernelArgs(consts.KernelArgPciRealloc)
+		ifaceStatus := p.DesireState.GetInterfaceStateByPciAddress(iface.PciAddress)
+		if ifaceStatus == nil {
+			continue
 		}
+		vfDeviceID := sriovnetworkv1.GetVfDeviceID(ifaceStatus.DeviceID)
+		if vfDeviceID == "" && len(ifaceStatus.VFs) > 0 {
+			vfDeviceID = ifaceStatus.VFs[0].DeviceID
+		}
+		if vfDeviceID == "" {
+			log.Log.Info("generic plugin syncModprobeBlacklist(): unknown VF device ID, skip",
+				"address", iface.PciAddress)
+			continue
+		}
+		deviceIDs = append(deviceIDs, ifaceStatus.Vendor+":"+vfDeviceID)
+		pfDrivers = append(pfDrivers, ifaceStatus.Driver)
+	}
+	if err := p.hostManager.ConfigureModprobeBlacklist(ctx, deviceIDs, pfDrivers); err != nil {
+		log.Log.Error(err, "generic plugin syncModprobeBlacklist(): failed to configure modprobe blacklist")
 		return err
 	}
+	return nil
+}
 
-	if p.shouldConfigureBridges() {
-		if err := p.helpers.ConfigureBridges(p.DesireState.Spec.Bridges, p.DesireState.Status.Bridges); err != nil {
-			return err
-		}
+// sortVfGroups returns a copy of the interfaces with VF groups ordered according to
+// the VfGroupSortPolicy of each interface
+func sortVfGroups(interfaces sriovnetworkv1.Interfaces) sriovnetworkv1.Interfaces {
+	sorted := make(sriovnetworkv1.Interfaces, len(interfaces))
+	for i := range interfaces {
+		sorted[i] = interfaces[i]
+		sorted[i].VfGroups = sriovnetworkv1.SortVfGroupsByPolicy(interfaces[i].VfGroups, interfaces[i].VfGroupSortPolicy)
 	}
-
-	return nil
+	return sorted
 }
 
+// needDriverCheckDeviceType returns true if a VF group requires the device type of the driver,
+// the driver overrides of the policies replace the device types of their VF groups
 func needDriverCheckDeviceType(state *sriovnetworkv1.SriovNetworkNodeState, driverState *DriverState) bool {
+	overrides := getDriverOverrides(state)
 	for _, iface := range state.Spec.Interfaces {
 		for i := range iface.VfGroups {
+			if driver, ok := overrides[iface.VfGroups[i].PolicyName]; ok {
+				if strings.ReplaceAll(driver, "-", "_") == driverState.DriverName {
+					return true
+				}
+				continue
+			}
 			if iface.VfGroups[i].DeviceType == driverState.DeviceType {
 				return true
 			}
@@ -252,6 +1680,19 @@ func needDriverCheckDeviceType(state *sriovnetworkv1.SriovNetworkNodeState, driv
 	return false
 }
 
+// getDriverOverrides returns the driver overrides of the policies if they are allowed on the node
+func getDriverOverrides(state *sriovnetworkv1.SriovNetworkNodeState) map[string]string {
+	if !vars.DriverOverrideAllowed {
+		return nil
+	}
+	overrides, err := sriovnetworkv1.GetDriverOverrides(state)
+	if err != nil {
+		log.Log.Error(err, "generic plugin getDriverOverrides(): ignoring driver overrides")
+		return nil
+	}
+	return overrides
+}
+
 func needDriverCheckVdpaType(state *sriovnetworkv1.SriovNetworkNodeState, driverState *DriverState) bool {
 	for _, iface := range state.Spec.Interfaces {
 		for i := range iface.VfGroups {
@@ -263,19 +1704,99 @@ func needDriverCheckVdpaType(state *sriovnetworkv1.SriovNetworkNodeState, driver
 	return false
 }
 
-// setKernelArg Tries to add the kernel args via ostree or grubby.
-func setKernelArg(karg string) (bool, error) {
-	log.Log.Info("generic plugin setKernelArg()")
+// needDriverCheckVendor returns true if VFs are requested on a device of the driver vendor,
+// the device ID must match as well if the driver requires it
+func needDriverCheckVendor(state *sriovnetworkv1.SriovNetworkNodeState, driverState *DriverState) bool {
+	for _, iface := range state.Spec.Interfaces {
+		if iface.NumVfs == 0 {
+			continue
+		}
+		ifaceStatus := state.GetInterfaceStateByPciAddress(iface.PciAddress)
+		if ifaceStatus != nil && ifaceStatus.Vendor == driverState.VendorID &&
+			(driverState.DeviceID == "" || ifaceStatus.DeviceID == driverState.DeviceID) {
+			return true
+		}
+	}
+	return false
+}
+
+// needDriverCheckQAT returns true if the node status reports an Intel QuickAssist Technology device
+func needDriverCheckQAT(state *sriovnetworkv1.SriovNetworkNodeState, driverState *DriverState) bool {
+	for _, ifaceStatus := range state.Status.Interfaces {
+		if ifaceStatus.Vendor == driverState.VendorID && slices.Contains(qatDeviceIDs, ifaceStatus.DeviceID) {
+			return true
+		}
+	}
+	return false
+}
+
+// needDriverCheckIonic returns true if VFs are requested on an AMD Pensando DSC PF
+func needDriverCheckIonic(state *sriovnetworkv1.SriovNetworkNodeState, driverState *DriverState) bool {
+	return needDriverCheckVendor(state, driverState)
+}
+
+// uploadIonicFirmware flashes the configured firmware to the AMD Pensando DSC PFs which VFs are requested on,
+// the VFs are available only once the firmware is uploaded. Nothing is flashed if no firmware is configured.
+func uploadIonicFirmware(ctx context.Context, p *GenericPlugin) error {
+	if p.IonicFirmwarePath == "" {
+		log.Log.V(2).Info("generic plugin uploadIonicFirmware(): no firmware configured, skipping")
+		return nil
+	}
+	for _, iface := range p.DesireState.Spec.Interfaces {
+		if iface.NumVfs == 0 {
+			continue
+		}
+		ifaceStatus := p.DesireState.GetInterfaceStateByPciAddress(iface.PciAddress)
+		if ifaceStatus == nil || ifaceStatus.Vendor != consts.VendorPensando {
+			continue
+		}
+		if err := p.hostManager.UploadFirmware(ctx, iface.PciAddress, p.IonicFirmwarePath); err != nil {
+			return &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
+		}
+	}
+	return nil
+}
+
+// createSfcAffinityDevice creates the device node of the character device registered
+// by the sfc_affinity driver, the node isn't created automatically on the host
+func createSfcAffinityDevice(ctx context.Context, p *GenericPlugin) error {
+	data, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.ProcDevices))
+	if err != nil {
+		return err
+	}
+	deviceName := filepath.Base(consts.SfcAffinityDevice)
+	for _, line := range strings.Split(string(data), "\n") {
+		// character devices are listed first in "<major> <name>" format
+		if strings.HasPrefix(line, "Block devices:") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[1] != deviceName {
+			continue
+		}
+		major, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("failed to parse major number of %s: %v", deviceName, err)
+		}
+		return p.hostManager.CreateCharDevice(ctx, uint32(major), 0, consts.SfcAffinityDevice)
+	}
+	return fmt.Errorf("character device %s is not registered", deviceName)
+}
+
+// setKernelArg Tries to add the kernel args via the provided backend: rpm-ostree, grubby or update-grub.
+var setKernelArg = func(karg, backend string) (bool, error) {
+	log.Log.Info("generic plugin setKernelArg()", "backend", backend)
 	var stdout, stderr bytes.Buffer
 	cmd := exec.Command("/bin/sh", scriptsPath, karg)
+	cmd.Env = append(os.Environ(), "KARGS_BACKEND="+backend)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
 		// if grubby is not there log and assume kernel args are set correctly.
 		if utils.IsCommandNotFound(err) {
-			log.Log.Error(err, "generic plugin setKernelArg(): grubby or ostree command not found. Please ensure that kernel arg are set",
-				"kargs", karg)
+			log.Log.Error(err, "generic plugin setKernelArg(): kernel args management command not found. Please ensure that kernel arg are set",
+				"kargs", karg, "backend", backend)
 			return false, nil
 		}
 		log.Log.Error(err, "generic plugin setKernelArg(): fail to enable kernel arg", "karg", karg)
@@ -292,6 +1813,14 @@ func setKernelArg(karg string) (bool, error) {
 	return false, err
 }
 
+// recordEvent adds the event to the current batch of events of the plugin, nothing is sent if no event sender is configured
+func (p *GenericPlugin) recordEvent(reason, msg string) {
+	if p.eventBatcher == nil {
+		return
+	}
+	p.eventBatcher.Record(reason, msg)
+}
+
 // addToDesiredKernelArgs Should be called to queue a kernel arg to be added to the node.
 func (p *GenericPlugin) addToDesiredKernelArgs(karg string) {
 	if _, ok := p.DesiredKernelArgs[karg]; !ok {
@@ -307,23 +1836,24 @@ func (p *GenericPlugin) getMissingKernelArgs() ([]string, error) {
 		return nil, nil
 	}
 
-	kargs, err := p.helpers.GetCurrentKernelArgs()
-	if err != nil {
-		return nil, err
-	}
-
 	for desiredKarg := range p.DesiredKernelArgs {
-		if !p.helpers.IsKernelArgsSet(kargs, desiredKarg) {
+		set, err := p.kernelParamManager.IsSet(desiredKarg)
+		if err != nil {
+			return nil, err
+		}
+		if !set {
 			missingArgs = append(missingArgs, desiredKarg)
+		} else {
+			delete(p.KernelArgsSetTime, desiredKarg)
+			delete(p.KernelArgAttempts, desiredKarg)
+			delete(p.kernelParamLastSetAt, desiredKarg)
 		}
 	}
 	return missingArgs, nil
 }
 
 // syncDesiredKernelArgs should be called to set all the kernel arguments. Returns bool if node update is needed.
-func (p *GenericPlugin) syncDesiredKernelArgs(kargs []string) (bool, error) {
-	needReboot := false
-
+func (p *GenericPlugin) syncDesiredKernelArgs(ctx context.Context, kargs []string) (bool, error) {
 	for _, karg := range kargs {
 		if p.DesiredKernelArgs[karg] {
 			log.Log.V(2).Info("generic-plugin syncDesiredKernelArgs(): previously attempted to set kernel arg",
@@ -332,38 +1862,169 @@ func (p *GenericPlugin) syncDesiredKernelArgs(kargs []string) (bool, error) {
 		// There is a case when we try to set the kernel argument here, the daemon could decide to not reboot because
 		// the daemon encountered a potentially one-time error. However we always want to make sure that the kernel
 		// argument is set once the daemon goes through node state sync again.
-		update, err := setKernelArg(karg)
+		if p.MaxKernelParamAttempts > 0 && p.KernelArgAttempts[karg] >= p.MaxKernelParamAttempts {
+			return false, &KernelParamError{Param: karg, Attempts: p.KernelArgAttempts[karg], Underlying: ErrKernelParamAttemptsExceeded}
+		}
+		p.KernelArgAttempts[karg]++
+		update, err := p.setKernelParam(karg)
 		if err != nil {
 			log.Log.Error(err, "generic-plugin syncDesiredKernelArgs(): fail to set kernel arg", "karg", karg)
-			return false, err
+			return false, &KernelParamError{Param: karg, Attempts: p.KernelArgAttempts[karg], Underlying: err}
 		}
 		if update {
-			needReboot = true
-			log.Log.V(2).Info("generic-plugin syncDesiredKernelArgs(): need reboot for setting kernel arg", "karg", karg)
+			if _, ok := p.KernelArgsSetTime[karg]; !ok {
+				p.KernelArgsSetTime[karg] = time.Now()
+			}
+			log.Log.V(2).Info("generic-plugin syncDesiredKernelArgs(): kernel arg updated in the bootloader configuration",
+				"karg", karg)
+			p.recordEvent(EventReasonKernelArgsUpdated, fmt.Sprintf("Kernel arg %s has been added to the bootloader configuration", karg))
 		}
 		p.DesiredKernelArgs[karg] = true
 	}
+	pending := make([]string, 0, len(kargs))
+	for _, karg := range kargs {
+		if _, ok := p.KernelArgsSetTime[karg]; ok {
+			pending = append(pending, karg)
+		}
+	}
+	if len(pending) == 0 {
+		return false, nil
+	}
+	needReboot, err := p.waitForKernelArgs(ctx, pending)
+	if err != nil {
+		return false, &KernelParamError{Param: strings.Join(pending, " "), Underlying: err}
+	}
+	if needReboot {
+		log.Log.V(2).Info("generic-plugin syncDesiredKernelArgs(): need reboot for setting kernel args", "kargs", pending)
+	}
 	return needReboot, nil
 }
 
-func (p *GenericPlugin) needDrainNode(desired sriovnetworkv1.SriovNetworkNodeStateSpec, current sriovnetworkv1.SriovNetworkNodeStateStatus) bool {
+// setKernelParam adds the kernel arg to the boot configuration, the kernel arg is not set again during
+// KernelParamSetCooldown after it was set successfully so that rapid syncs don't rewrite the bootloader
+// configuration with the same content. A skipped kernel arg doesn't require an update, the reboot for
+// the kernel arg set before is still requested by waitForKernelArgs.
+func (p *GenericPlugin) setKernelParam(karg string) (bool, error) {
+	if setAt, ok := p.kernelParamLastSetAt[karg]; ok && time.Since(setAt) < p.KernelParamSetCooldown {
+		log.Log.V(2).Info("generic-plugin setKernelParam(): kernel arg set recently, skipping",
+			"karg", karg, "setAt", setAt, "cooldown", p.KernelParamSetCooldown)
+		return false, nil
+	}
+	update, err := p.kernelParamManager.Set(karg)
+	if err != nil {
+		return false, err
+	}
+	p.kernelParamLastSetAt[karg] = time.Now()
+	return update, nil
+}
+
+// waitForKernelArgs re-verifies the kernel args set by the plugin until KernelParamGracePeriod elapses
+// since they were set, returns true if some of them still didn't appear in the kernel cmdline
+func (p *GenericPlugin) waitForKernelArgs(ctx context.Context, kargs []string) (bool, error) {
+	var deadline time.Time
+	for _, karg := range kargs {
+		if setTime, ok := p.KernelArgsSetTime[karg]; ok && setTime.Add(p.KernelParamGracePeriod).After(deadline) {
+			deadline = setTime.Add(p.KernelParamGracePeriod)
+		}
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return true, nil
+	}
+	missing := kargs
+	verify := func() (bool, error) {
+		cmdLine, err := p.hostManager.GetCurrentKernelArgs(ctx)
+		if err != nil {
+			return false, err
+		}
+		missing = nil
+		for _, karg := range kargs {
+			if p.hostManager.IsKernelArgsSet(ctx, cmdLine, karg) {
+				delete(p.KernelArgsSetTime, karg)
+			} else {
+				missing = append(missing, karg)
+			}
+		}
+		return len(missing) == 0, nil
+	}
+	log.Log.Info("generic-plugin waitForKernelArgs(): wait for the kernel args to appear in the kernel cmdline",
+		"kargs", kargs, "timeout", remaining)
+	err := wait.PollImmediate(kernelArgPollInterval, remaining, verify)
+	if err == nil {
+		log.Log.Info("generic-plugin waitForKernelArgs(): kernel args applied without reboot", "kargs", kargs)
+		return false, nil
+	}
+	if !errors.Is(err, wait.ErrWaitTimeout) {
+		log.Log.Error(err, "generic-plugin waitForKernelArgs(): failed to verify kernel args")
+		return false, err
+	}
+	log.Log.Info("generic-plugin waitForKernelArgs(): kernel args didn't appear in the kernel cmdline within the grace period",
+		"kargs", missing, "gracePeriod", p.KernelParamGracePeriod)
+	return true, nil
+}
+
+func (p *GenericPlugin) needDrainNode(ctx context.Context, desired sriovnetworkv1.SriovNetworkNodeStateSpec, current sriovnetworkv1.SriovNetworkNodeStateStatus) bool {
 	log.Log.V(2).Info("generic plugin needDrainNode()", "current", current, "desired", desired)
 
 	if p.needToUpdateVFs(desired, current) {
 		return true
 	}
 
+	if desired.System.RdmaMode != "" && desired.System.RdmaMode != current.System.RdmaMode {
+		log.Log.V(2).Info("generic plugin needDrainNode(): need drain since RDMA subsystem netns mode needs to be updated",
+			"current", current.System.RdmaMode, "desired", desired.System.RdmaMode)
+		return true
+	}
+
 	if p.shouldConfigureBridges() {
 		if sriovnetworkv1.NeedToUpdateBridges(&desired.Bridges, &current.Bridges) {
 			log.Log.V(2).Info("generic plugin needDrainNode(): need drain since bridge configuration needs to be updated")
 			return true
 		}
 	}
+
+	if p.needToUpdateBondedPFs(ctx, desired, current) {
+		return true
+	}
+	return false
+}
+
+// needToUpdateBondedPFs returns true if a PF enslaved to a bond or to a team needs to be reconfigured,
+// the traffic which flows over the bond is disrupted while the PF and its VFs are configured
+func (p *GenericPlugin) needToUpdateBondedPFs(ctx context.Context, desired sriovnetworkv1.SriovNetworkNodeStateSpec, current sriovnetworkv1.SriovNetworkNodeStateStatus) bool {
+	if p.IgnoreBondedInterfaces {
+		return false
+	}
+	for _, ifaceStatus := range current.Interfaces {
+		if p.isDeviceSkipped(ifaceStatus.PciAddress) {
+			continue
+		}
+		for _, iface := range desired.Interfaces {
+			if iface.PciAddress != ifaceStatus.PciAddress || !sriovnetworkv1.NeedToDrainForSriovUpdate(&iface, &ifaceStatus) {
+				continue
+			}
+			bonded, master, err := p.hostManager.IsPartOfBond(ctx, iface.PciAddress)
+			if err != nil {
+				log.Log.Error(err, "generic plugin needToUpdateBondedPFs(): failed to check if PF is enslaved to a bond",
+					"address", iface.PciAddress)
+				break
+			}
+			if bonded {
+				log.Log.Info("generic plugin needToUpdateBondedPFs(): WARNING: PF enslaved to a bond needs to be reconfigured, need drain",
+					"address", iface.PciAddress, "name", ifaceStatus.Name, "master", master)
+				return true
+			}
+			break
+		}
+	}
 	return false
 }
 
 func (p *GenericPlugin) needToUpdateVFs(desired sriovnetworkv1.SriovNetworkNodeStateSpec, current sriovnetworkv1.SriovNetworkNodeStateStatus) bool {
 	for _, ifaceStatus := range current.Interfaces {
+		if p.isDeviceSkipped(ifaceStatus.PciAddress) {
+			continue
+		}
 		configured := false
 		for _, iface := range desired.Interfaces {
 			if iface.PciAddress == ifaceStatus.PciAddress {
@@ -373,7 +2034,7 @@ func (p *GenericPlugin) needToUpdateVFs(desired sriovnetworkv1.SriovNetworkNodeS
 						"address", iface.PciAddress)
 					break
 				}
-				if sriovnetworkv1.NeedToUpdateSriov(&iface, &ifaceStatus) {
+				if p.drainStrategy.NeedDrain(&iface, &ifaceStatus) {
 					log.Log.V(2).Info("generic plugin needToUpdateVFs(): need drain, for PCI address request update",
 						"address", iface.PciAddress)
 					return true
@@ -417,18 +2078,45 @@ func (p *GenericPlugin) shouldConfigureBridges() bool {
 	return vars.ManageSoftwareBridges && !p.skipBridgeConfiguration
 }
 
-func (p *GenericPlugin) addVfioDesiredKernelArg(state *sriovnetworkv1.SriovNetworkNodeState) {
+func (p *GenericPlugin) addVfioDesiredKernelArg(ctx context.Context, state *sriovnetworkv1.SriovNetworkNodeState) {
 	driverState := p.DriverStateMap[Vfio]
 	if !driverState.DriverLoaded && driverState.NeedDriverFunc(state, driverState) {
+		p.addIommuDesiredKernelArgs(ctx)
+		// the ENA devices bound to vfio-pci need the receive side scaling of the device
+		if enaState := p.DriverStateMap[Ena]; enaState.NeedDriverFunc(state, enaState) {
+			p.addToDesiredKernelArgs(consts.KernelArgEnaRss)
+		}
+	}
+}
+
+// addQatDesiredKernelParam adds the IOMMU kernel args required by the VFs of the QAT devices,
+// they are the same as for vfio-pci
+func (p *GenericPlugin) addQatDesiredKernelParam(ctx context.Context, state *sriovnetworkv1.SriovNetworkNodeState) {
+	driverState := p.DriverStateMap[QatDevice]
+	if !driverState.DriverLoaded && driverState.NeedDriverFunc(state, driverState) {
+		p.addIommuDesiredKernelArgs(ctx)
+	}
+}
+
+// addIommuDesiredKernelArgs adds the kernel args which enable the IOMMU in passthrough mode on the architecture
+func (p *GenericPlugin) addIommuDesiredKernelArgs(ctx context.Context) {
+	switch arch := p.hostManager.GetArchitecture(ctx); arch {
+	case consts.ArchitectureArm64:
+		// ARM platforms use SMMU instead of the Intel/AMD IOMMU subsystems
+		p.addToDesiredKernelArgs(consts.KernelArgIommuPassthrough)
+		p.addToDesiredKernelArgs(consts.KernelArgSMMUBypass)
+	default:
 		p.addToDesiredKernelArgs(consts.KernelArgIntelIommu)
 		p.addToDesiredKernelArgs(consts.KernelArgIommuPt)
 	}
 }
 
-func (p *GenericPlugin) needRebootNode(state *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
+func (p *GenericPlugin) needRebootNode(ctx context.Context, state *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
 	needReboot := false
 
-	p.addVfioDesiredKernelArg(state)
+	p.addVfioDesiredKernelArg(ctx, state)
+	p.addQatDesiredKernelParam(ctx, state)
+	p.addConfiguredKernelArgs()
 
 	missingKernelArgs, err := p.getMissingKernelArgs()
 	if err != nil {
@@ -437,7 +2125,7 @@ func (p *GenericPlugin) needRebootNode(state *sriovnetworkv1.SriovNetworkNodeSta
 	}
 
 	if len(missingKernelArgs) != 0 {
-		needReboot, err = p.syncDesiredKernelArgs(missingKernelArgs)
+		needReboot, err = p.syncDesiredKernelArgs(ctx, missingKernelArgs)
 		if err != nil {
 			log.Log.Error(err, "generic-plugin needRebootNode(): failed to set the desired kernel arguments")
 			return false, err
@@ -447,9 +2135,150 @@ func (p *GenericPlugin) needRebootNode(state *sriovnetworkv1.SriovNetworkNodeSta
 		}
 	}
 
+	if p.needRebootForDevlinkParams(ctx, state) {
+		needReboot = true
+	}
+
+	if p.needRebootForTotalVfs(state) {
+		needReboot = true
+	}
+
 	return needReboot, nil
 }
 
+// needRebootForTotalVfs returns true if a policy requests more VFs than the maximum number of VFs of a PF
+// and the vendor plugin raises the limit in the firmware, the new limit is active only after a reboot
+func (p *GenericPlugin) needRebootForTotalVfs(state *sriovnetworkv1.SriovNetworkNodeState) bool {
+	for _, iface := range state.Spec.Interfaces {
+		if iface.ExternallyManaged {
+			continue
+		}
+		ifaceStatus := findInterfaceStatus(state.Status.Interfaces, iface.PciAddress)
+		if ifaceStatus == nil || iface.NumVfs <= ifaceStatus.TotalVfs {
+			continue
+		}
+		raiser, ok := p.totalVfsRaisers[ifaceStatus.Vendor]
+		if !ok || !raiser.CanRaiseTotalVfs(ifaceStatus) {
+			continue
+		}
+		log.Log.V(2).Info("generic-plugin needRebootForTotalVfs(): need reboot for raising the maximum number of VFs",
+			"device", iface.PciAddress, "numVfs", iface.NumVfs, "totalVfs", ifaceStatus.TotalVfs)
+		return true
+	}
+	return false
+}
+
+// checkTotalVfs returns an error for the PFs which request more VFs than their maximum number of VFs,
+// the maximum can be raised only by the vendor plugins with firmware tooling and after a reboot
+func (p *GenericPlugin) checkTotalVfs(interfaces sriovnetworkv1.Interfaces, statuses sriovnetworkv1.InterfaceExts) error {
+	for _, iface := range interfaces {
+		if iface.ExternallyManaged {
+			continue
+		}
+		ifaceStatus := findInterfaceStatus(statuses, iface.PciAddress)
+		if ifaceStatus == nil || iface.NumVfs <= ifaceStatus.TotalVfs {
+			continue
+		}
+		err := fmt.Errorf("numVfs %d exceeds the maximum number of VFs %d of the PF", iface.NumVfs, ifaceStatus.TotalVfs)
+		if raiser, ok := p.totalVfsRaisers[ifaceStatus.Vendor]; ok && raiser.CanRaiseTotalVfs(ifaceStatus) {
+			err = fmt.Errorf("%v, the raised firmware limit is active after a reboot", err)
+		} else {
+			err = fmt.Errorf("%v, the limit can't be raised by the vendor plugin", err)
+		}
+		return &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err}
+	}
+	return nil
+}
+
+// checkBondedPFs returns an error for the PFs enslaved to a bond which are not explicitly allowed by the policies,
+// changing the VFs or the MTU of such a PF can break the networking of the node. The ports of a mlx5 VF-LAG bond
+// are changed as a unit, all of them must be configured with the same eSwitch mode.
+func (p *GenericPlugin) checkBondedPFs(interfaces sriovnetworkv1.Interfaces, statuses sriovnetworkv1.InterfaceExts) error {
+	var errs []error
+	for _, iface := range interfaces {
+		ifaceStatus := findInterfaceStatus(statuses, iface.PciAddress)
+		if ifaceStatus == nil || ifaceStatus.BondMaster == "" {
+			continue
+		}
+		if !iface.AllowBondedPF {
+			errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress,
+				Err: fmt.Errorf("PF %s is enslaved to bond %s, set allowBondedPF in the policy to configure it",
+					ifaceStatus.Name, ifaceStatus.BondMaster)})
+			continue
+		}
+		if ifaceStatus.Vendor != consts.VendorMellanox {
+			continue
+		}
+		if err := p.checkVFLAGPorts(&iface, ifaceStatus); err != nil {
+			errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkVFLAGPorts returns an error if another mlx5 port of the bond of the PF is not configured
+// with the same eSwitch mode
+func (p *GenericPlugin) checkVFLAGPorts(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error {
+	for _, portStatus := range p.DesireState.Status.Interfaces {
+		if portStatus.PciAddress == ifaceStatus.PciAddress || portStatus.BondMaster != ifaceStatus.BondMaster ||
+			portStatus.Vendor != consts.VendorMellanox {
+			continue
+		}
+		var port *sriovnetworkv1.Interface
+		for i := range p.DesireState.Spec.Interfaces {
+			if p.DesireState.Spec.Interfaces[i].PciAddress == portStatus.PciAddress {
+				port = &p.DesireState.Spec.Interfaces[i]
+				break
+			}
+		}
+		if port == nil {
+			return fmt.Errorf("port %s of VF-LAG bond %s is not selected by a policy, the ports of the bond must be configured together",
+				portStatus.Name, ifaceStatus.BondMaster)
+		}
+		if sriovnetworkv1.GetEswitchModeFromSpec(port) != sriovnetworkv1.GetEswitchModeFromSpec(iface) {
+			return fmt.Errorf("eSwitchMode %s differs from eSwitchMode %s of port %s of VF-LAG bond %s",
+				sriovnetworkv1.GetEswitchModeFromSpec(iface), sriovnetworkv1.GetEswitchModeFromSpec(port),
+				portStatus.Name, ifaceStatus.BondMaster)
+		}
+	}
+	return nil
+}
+
+// findInterfaceStatus returns the status of the PF with the PCI address or nil if it is not found
+func findInterfaceStatus(statuses sriovnetworkv1.InterfaceExts, pciAddress string) *sriovnetworkv1.InterfaceExt {
+	for i := range statuses {
+		if statuses[i].PciAddress == pciAddress {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// needRebootForDevlinkParams returns true if a devlink parameter of a PF which is set in the permanent configuration
+// mode doesn't have the requested value, the value is set when the PFs are configured but the firmware applies it only
+// after a reset. The parameters which can't be read are skipped, the error is reported when the PFs are configured.
+func (p *GenericPlugin) needRebootForDevlinkParams(ctx context.Context, state *sriovnetworkv1.SriovNetworkNodeState) bool {
+	for _, iface := range state.Spec.Interfaces {
+		if iface.ExternallyManaged {
+			continue
+		}
+		for name, value := range iface.DevlinkParams {
+			permanent, err := p.hostManager.IsDevlinkDeviceParamPermanent(ctx, iface.PciAddress, name)
+			if err != nil || !permanent {
+				continue
+			}
+			current, err := p.hostManager.GetDevlinkDeviceParam(ctx, iface.PciAddress, name)
+			if err != nil || current == value {
+				continue
+			}
+			log.Log.V(2).Info("generic-plugin needRebootForDevlinkParams(): need reboot for updating permanent devlink parameter",
+				"device", iface.PciAddress, "param", name, "current", current, "value", value)
+			return true
+		}
+	}
+	return false
+}
+
 // ////////////// for testing purposes only ///////////////////////
 func (p *GenericPlugin) getDriverStateMap() DriverStateMapType {
 	return p.DriverStateMap
@@ -1,17 +1,46 @@
 package generic
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
 	mock_helper "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store"
+	hosttesting "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/testing"
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
 )
 
 func TestGenericPlugin(t *testing.T) {
@@ -26,6 +55,8 @@ var _ = Describe("Generic plugin", func() {
 		err           error
 		ctrl          *gomock.Controller
 		hostHelper    *mock_helper.MockHostHelpersInterface
+		// current netdev names of the PFs returned by the host, by PCI address
+		pfNetdevNames map[string]string
 	)
 
 	BeforeEach(func() {
@@ -33,6 +64,11 @@ var _ = Describe("Generic plugin", func() {
 		ctrl = gomock.NewController(t)
 
 		hostHelper = mock_helper.NewMockHostHelpersInterface(ctrl)
+		hostHelper.EXPECT().GetKernelVersion().Return("5.14.0-427.13.1.el9_4.x86_64", nil).AnyTimes()
+		pfNetdevNames = map[string]string{}
+		hostHelper.EXPECT().TryGetInterfaceName(gomock.Any()).DoAndReturn(func(pciAddr string) string {
+			return pfNetdevNames[pciAddr]
+		}).AnyTimes()
 
 		genericPlugin, err = NewGenericPlugin(hostHelper)
 		Expect(err).ToNot(HaveOccurred())
@@ -52,7 +88,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-0",
 						}}}},
 				},
@@ -100,7 +136,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -150,7 +186,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -200,7 +236,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -249,7 +285,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -298,7 +334,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -338,7 +374,7 @@ var _ = Describe("Generic plugin", func() {
 			Expect(needDrain).To(BeTrue())
 		})
 
-		It("should drain because MTU value has changed on VF of type netdevice", func() {
+		It("should not drain because MTU value has changed on VF of type netdevice", func() {
 			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
 				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
 					Interfaces: sriovnetworkv1.Interfaces{{
@@ -348,7 +384,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -391,7 +427,8 @@ var _ = Describe("Generic plugin", func() {
 			needDrain, needReboot, err := genericPlugin.OnNodeStateChange(networkNodeState)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(needReboot).To(BeFalse())
-			Expect(needDrain).To(BeTrue())
+			// VF MTU is applied without disrupting the workloads
+			Expect(needDrain).To(BeFalse())
 		})
 
 		It("should drain because GUID address value is the default one on VF of type netdevice, rdma enabled and link type ETH", func() {
@@ -404,7 +441,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 							IsRdma:       true,
@@ -456,7 +493,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 							IsRdma:       true,
@@ -507,7 +544,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -558,7 +595,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -608,7 +645,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -657,7 +694,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 							IsRdma:       true,
@@ -709,7 +746,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 							IsRdma:       true,
@@ -759,7 +796,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-0",
 						}}}},
 				},
@@ -806,7 +843,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "netdevice",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -860,7 +897,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "vfio-pci",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -897,9 +934,10 @@ var _ = Describe("Generic plugin", func() {
 			}
 
 			// Load required kernel args.
-			genericPlugin.(*GenericPlugin).addVfioDesiredKernelArg(networkNodeState)
+			hostHelper.EXPECT().GetArchitecture().Return(consts.ArchitectureAmd64)
+			genericPlugin.(*GenericPlugin).addVfioDesiredKernelArg(context.Background(), networkNodeState)
 
-			hostHelper.EXPECT().GetCurrentKernelArgs().Return("", nil)
+			hostHelper.EXPECT().GetCurrentKernelArgs().Return("", nil).Times(2)
 			hostHelper.EXPECT().IsKernelArgsSet("", consts.KernelArgIntelIommu).Return(false)
 			hostHelper.EXPECT().IsKernelArgsSet("", consts.KernelArgIommuPt).Return(false)
 
@@ -908,6 +946,414 @@ var _ = Describe("Generic plugin", func() {
 			Expect(changed).To(BeTrue())
 		})
 
+		It("should persist required drivers", func() {
+			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     1,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType:   "vfio-pci",
+							PolicyName:   "policy-1",
+							ResourceName: "resource_1",
+							VfRange:      "0-0",
+						}}}},
+				},
+			}
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithPersistDriverLoad())
+			Expect(err).ToNot(HaveOccurred())
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = networkNodeState
+
+			hostHelper.EXPECT().IsKernelModuleLoaded(vfioPciDriver).Return(false, nil)
+			hostHelper.EXPECT().LoadKernelModule(vfioPciDriver).Return(nil)
+			hostHelper.EXPECT().WriteModulesLoadConf(consts.ModulesLoadConfFile, []string{vfioPciDriver}).Return(nil)
+			Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+
+			// driver is not needed anymore
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			hostHelper.EXPECT().WriteModulesLoadConf(consts.ModulesLoadConfFile, []string{}).Return(nil)
+			Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+		})
+
+		Context("Solarflare", func() {
+			var concretePlugin *GenericPlugin
+			BeforeEach(func() {
+				concretePlugin = genericPlugin.(*GenericPlugin)
+				concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+					Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+						Interfaces: sriovnetworkv1.Interfaces{{
+							PciAddress: "0000:00:00.0",
+							NumVfs:     1,
+							VfGroups: []sriovnetworkv1.VfGroup{{
+								DeviceType:   "netdevice",
+								PolicyName:   "policy-1",
+								ResourceName: "resource_1",
+								VfRange:      "0-0",
+							}}}},
+					},
+					Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+						Interfaces: sriovnetworkv1.InterfaceExts{{
+							PciAddress: "0000:00:00.0",
+							Vendor:     "1924",
+							DeviceID:   "0b03",
+							Driver:     "sfc",
+						}},
+					},
+				}
+			})
+
+			It("should load the drivers and create the affinity device", func() {
+				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+					Dirs: []string{"/proc"},
+					Files: map[string][]byte{"/proc/devices": []byte(
+						"Character devices:\n  1 mem\n237 sfc_affinity\n\nBlock devices:\n  8 sd\n")},
+				})
+				hostHelper.EXPECT().IsKernelModuleLoaded(sfcResourceDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(sfcResourceDriver).Return(nil)
+				hostHelper.EXPECT().IsKernelModuleLoaded(sfcAffinityDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(sfcAffinityDriver).Return(nil)
+				hostHelper.EXPECT().CreateCharDevice(uint32(237), uint32(0), consts.SfcAffinityDevice).Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[SfcResource].DriverLoaded).To(BeTrue())
+				Expect(concretePlugin.DriverStateMap[SfcAffinity].DriverLoaded).To(BeTrue())
+			})
+
+			It("should retry if the affinity device is not registered", func() {
+				helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+					Dirs:  []string{"/proc"},
+					Files: map[string][]byte{"/proc/devices": []byte("Character devices:\n  1 mem\n")},
+				})
+				hostHelper.EXPECT().IsKernelModuleLoaded(sfcResourceDriver).Return(false, nil).AnyTimes()
+				hostHelper.EXPECT().LoadKernelModule(sfcResourceDriver).Return(nil).AnyTimes()
+				hostHelper.EXPECT().IsKernelModuleLoaded(sfcAffinityDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(sfcAffinityDriver).Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).To(MatchError(ContainSubstring("sfc_affinity is not registered")))
+				Expect(concretePlugin.DriverStateMap[SfcAffinity].DriverLoaded).To(BeFalse())
+			})
+
+			It("should not load the drivers for other vendors", func() {
+				concretePlugin.DesireState.Status.Interfaces[0].Vendor = "8086"
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("ENA", func() {
+			var concretePlugin *GenericPlugin
+			BeforeEach(func() {
+				concretePlugin = genericPlugin.(*GenericPlugin)
+				concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+					Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+						Interfaces: sriovnetworkv1.Interfaces{{
+							PciAddress: "0000:00:06.0",
+							NumVfs:     1,
+							VfGroups: []sriovnetworkv1.VfGroup{{
+								DeviceType:   "vfio-pci",
+								PolicyName:   "policy-1",
+								ResourceName: "resource_1",
+								VfRange:      "0-0",
+							}}}},
+					},
+					Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+						Interfaces: sriovnetworkv1.InterfaceExts{{
+							PciAddress: "0000:00:06.0",
+							Vendor:     consts.VendorAmazon,
+							DeviceID:   consts.DeviceIDEna,
+							Driver:     "ena",
+						}},
+					},
+				}
+			})
+
+			It("should load the driver with the module params", func() {
+				hostHelper.EXPECT().IsKernelModuleLoaded(vfioPciDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(vfioPciDriver).Return(nil)
+				hostHelper.EXPECT().IsKernelModuleLoaded(enaDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(enaDriver, "large_llq_header=1").Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[Ena].DriverLoaded).To(BeTrue())
+			})
+
+			It("should not load the driver for other Amazon devices", func() {
+				concretePlugin.DesireState.Status.Interfaces[0].DeviceID = "0ec2"
+				hostHelper.EXPECT().IsKernelModuleLoaded(vfioPciDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(vfioPciDriver).Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[Ena].DriverLoaded).To(BeFalse())
+			})
+
+			It("should add the ENA kernel arg with the vfio kernel args", func() {
+				hostHelper.EXPECT().GetArchitecture().Return(consts.ArchitectureAmd64)
+				concretePlugin.addVfioDesiredKernelArg(context.Background(), concretePlugin.DesireState)
+				Expect(concretePlugin.DesiredKernelArgs).To(HaveKey(consts.KernelArgEnaRss))
+			})
+		})
+
+		Context("QAT", func() {
+			var concretePlugin *GenericPlugin
+			BeforeEach(func() {
+				concretePlugin = genericPlugin.(*GenericPlugin)
+				concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+					Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+						Interfaces: sriovnetworkv1.InterfaceExts{{
+							PciAddress: "0000:6b:00.0",
+							Vendor:     consts.VendorIntel,
+							DeviceID:   "4940",
+							Driver:     "qat_4xxx",
+						}},
+					},
+				}
+			})
+
+			It("should load the QAT drivers", func() {
+				hostHelper.EXPECT().IsKernelModuleLoaded(qatCommonDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(qatCommonDriver).Return(nil)
+				hostHelper.EXPECT().IsKernelModuleLoaded(qatDeviceDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(qatDeviceDriver).Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[QatCommon].DriverLoaded).To(BeTrue())
+				Expect(concretePlugin.DriverStateMap[QatDevice].DriverLoaded).To(BeTrue())
+			})
+
+			It("should not load the QAT drivers for other Intel devices", func() {
+				concretePlugin.DesireState.Status.Interfaces[0].DeviceID = "1592"
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[QatDevice].DriverLoaded).To(BeFalse())
+			})
+
+			It("should add the IOMMU kernel args", func() {
+				hostHelper.EXPECT().GetArchitecture().Return(consts.ArchitectureAmd64)
+				concretePlugin.addQatDesiredKernelParam(context.Background(), concretePlugin.DesireState)
+				Expect(concretePlugin.DesiredKernelArgs).To(HaveKey(consts.KernelArgIntelIommu))
+				Expect(concretePlugin.DesiredKernelArgs).To(HaveKey(consts.KernelArgIommuPt))
+			})
+
+			It("should not add the IOMMU kernel args once the driver is loaded", func() {
+				concretePlugin.DriverStateMap[QatDevice].DriverLoaded = true
+				concretePlugin.addQatDesiredKernelParam(context.Background(), concretePlugin.DesireState)
+				Expect(concretePlugin.DesiredKernelArgs).To(BeEmpty())
+			})
+		})
+
+		Context("Ionic", func() {
+			var concretePlugin *GenericPlugin
+			BeforeEach(func() {
+				concretePlugin = genericPlugin.(*GenericPlugin)
+				concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+					Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+						Interfaces: sriovnetworkv1.Interfaces{{
+							PciAddress: "0000:b5:00.0",
+							NumVfs:     2,
+							VfGroups: []sriovnetworkv1.VfGroup{{
+								DeviceType:   "netdevice",
+								PolicyName:   "policy-1",
+								ResourceName: "resource_1",
+								VfRange:      "0-1",
+							}}}},
+					},
+					Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+						Interfaces: sriovnetworkv1.InterfaceExts{{
+							PciAddress: "0000:b5:00.0",
+							Vendor:     consts.VendorPensando,
+							DeviceID:   "1002",
+							Driver:     "ionic",
+						}},
+					},
+				}
+			})
+
+			It("should load the drivers and upload the firmware", func() {
+				concretePlugin.IonicFirmwarePath = "pensando/dsc_fw.tar"
+				hostHelper.EXPECT().IsKernelModuleLoaded(ionicDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(ionicDriver).Return(nil)
+				hostHelper.EXPECT().IsKernelModuleLoaded(ionicMnicDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(ionicMnicDriver).Return(nil)
+				hostHelper.EXPECT().UploadFirmware("0000:b5:00.0", "pensando/dsc_fw.tar").Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[Ionic].DriverLoaded).To(BeTrue())
+				Expect(concretePlugin.DriverStateMap[IonicMnic].DriverLoaded).To(BeTrue())
+			})
+
+			It("should not upload the firmware if none is configured", func() {
+				hostHelper.EXPECT().IsKernelModuleLoaded(ionicDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(ionicDriver).Return(nil)
+				hostHelper.EXPECT().IsKernelModuleLoaded(ionicMnicDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(ionicMnicDriver).Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[IonicMnic].DriverLoaded).To(BeTrue())
+			})
+
+			It("should retry if the firmware upload fails", func() {
+				concretePlugin.IonicFirmwarePath = "pensando/dsc_fw.tar"
+				hostHelper.EXPECT().IsKernelModuleLoaded(ionicDriver).Return(false, nil).AnyTimes()
+				hostHelper.EXPECT().LoadKernelModule(ionicDriver).Return(nil).AnyTimes()
+				hostHelper.EXPECT().IsKernelModuleLoaded(ionicMnicDriver).Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule(ionicMnicDriver).Return(nil)
+				hostHelper.EXPECT().UploadFirmware("0000:b5:00.0", "pensando/dsc_fw.tar").Return(fmt.Errorf("flash failed"))
+				Expect(concretePlugin.syncDriverState(context.Background())).To(MatchError(ContainSubstring("flash failed")))
+				Expect(concretePlugin.DriverStateMap[IonicMnic].DriverLoaded).To(BeFalse())
+			})
+
+			It("should not load the drivers for other vendors", func() {
+				concretePlugin.DesireState.Status.Interfaces[0].Vendor = consts.VendorIntel
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[Ionic].DriverLoaded).To(BeFalse())
+			})
+		})
+
+		It("should detect VF groups which require vhost-net", func() {
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     2,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType:   "netdevice",
+							PolicyName:   "policy-1",
+							ResourceName: "resource_1",
+							VfRange:      "0-1",
+						}}}},
+				},
+			}
+			Expect(concretePlugin.needVhostNet()).To(BeFalse())
+
+			concretePlugin.DesireState.Spec.Interfaces[0].VfGroups[0].NeedVhostNet = true
+			Expect(concretePlugin.needVhostNet()).To(BeTrue())
+
+			concretePlugin.DesireState.Spec.Interfaces[0].VfGroups[0].NeedVhostNet = false
+			concretePlugin.DesireState.Spec.Interfaces[0].VfGroups[0].VdpaType = consts.VdpaTypeVhost
+			Expect(concretePlugin.needVhostNet()).To(BeTrue())
+		})
+
+		It("should allocate requested hugepages", func() {
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     1,
+						Hugepages:  &sriovnetworkv1.Hugepages{Size: "1Gi", Count: 4, SameNUMAAsNic: true},
+					}, {
+						PciAddress: "0000:00:00.1",
+						NumVfs:     1,
+						Hugepages:  &sriovnetworkv1.Hugepages{Size: "1Gi", Count: 2, SameNUMAAsNic: true},
+					}, {
+						PciAddress: "0000:00:01.0",
+						NumVfs:     1,
+						Hugepages:  &sriovnetworkv1.Hugepages{Size: "2Mi", Count: 512},
+					}, {
+						PciAddress: "0000:00:02.0",
+						NumVfs:     1,
+					}},
+				},
+			}
+			hostHelper.EXPECT().GetDeviceNumaNode("0000:00:00.0").Return(1, nil)
+			hostHelper.EXPECT().GetDeviceNumaNode("0000:00:00.1").Return(1, nil)
+			hostHelper.EXPECT().SetHugepages(1, "1Gi", 4).Return(4, nil)
+			hostHelper.EXPECT().SetHugepages(-1, "2Mi", 512).Return(512, nil)
+			Expect(concretePlugin.syncHugepages(context.Background())).NotTo(HaveOccurred())
+		})
+
+		It("should report hugepages allocation shortfall without failing", func() {
+			sender := &fakeEventSender{}
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			concretePlugin.eventBatcher = NewEventBatcher(sender, 0)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     1,
+						Hugepages:  &sriovnetworkv1.Hugepages{Size: "1Gi", Count: 4, SameNUMAAsNic: true},
+					}},
+				},
+			}
+			hostHelper.EXPECT().GetDeviceNumaNode("0000:00:00.0").Return(0, nil)
+			hostHelper.EXPECT().SetHugepages(0, "1Gi", 4).Return(3, nil)
+			Expect(concretePlugin.syncHugepages(context.Background())).To(Succeed())
+			Expect(sender.sent()).To(Equal([]string{
+				EventReasonHugepagesShortfall + ": 3 of 4 1Gi hugepages allocated on NUMA node 0"}))
+		})
+
+		It("should configure modprobe blacklist for vfio-pci groups", func() {
+			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     1,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType:            "vfio-pci",
+							PolicyName:            "policy-1",
+							ResourceName:          "resource_1",
+							VfRange:               "0-0",
+							BlacklistKernelDriver: true,
+						}}}, {
+						PciAddress: "0000:00:01.0",
+						NumVfs:     1,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType:   "netdevice",
+							PolicyName:   "policy-2",
+							ResourceName: "resource_2",
+							VfRange:      "0-0",
+						}}}},
+				},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{
+						PciAddress: "0000:00:00.0",
+						DeviceID:   "159b",
+						Vendor:     "8086",
+						Driver:     "ice",
+						VFs: []sriovnetworkv1.VirtualFunction{{
+							PciAddress: "0000:00:00.1",
+							DeviceID:   "1889",
+							Vendor:     "8086",
+						}},
+					}, {
+						PciAddress: "0000:00:01.0",
+						DeviceID:   "1015",
+						Vendor:     "15b3",
+						Driver:     "mlx5_core",
+					}},
+				},
+			}
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = networkNodeState
+			hostHelper.EXPECT().ConfigureModprobeBlacklist([]string{"8086:1889"}, []string{"ice"}).Return(nil)
+			Expect(concretePlugin.syncModprobeBlacklist(context.Background())).NotTo(HaveOccurred())
+		})
+
+		DescribeTable("should add architecture specific vfio kernel args",
+			func(arch string, expectedArgs []string) {
+				networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
+					Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+						Interfaces: sriovnetworkv1.Interfaces{{
+							PciAddress: "0000:00:00.0",
+							NumVfs:     2,
+							VfGroups: []sriovnetworkv1.VfGroup{{
+								DeviceType:   "vfio-pci",
+								PolicyName:   "policy-1",
+								ResourceName: "resource_1",
+								VfRange:      "0-1",
+							}}}},
+					},
+				}
+
+				hostHelper.EXPECT().GetArchitecture().Return(arch)
+				concretePlugin := genericPlugin.(*GenericPlugin)
+				concretePlugin.addVfioDesiredKernelArg(context.Background(), networkNodeState)
+
+				desiredArgs := make([]string, 0, len(concretePlugin.DesiredKernelArgs))
+				for karg := range concretePlugin.DesiredKernelArgs {
+					desiredArgs = append(desiredArgs, karg)
+				}
+				Expect(desiredArgs).To(ConsistOf(expectedArgs))
+			},
+			Entry("amd64", consts.ArchitectureAmd64,
+				[]string{consts.KernelArgIntelIommu, consts.KernelArgIommuPt}),
+			Entry("arm64", consts.ArchitectureArm64,
+				[]string{consts.KernelArgIommuPassthrough, consts.KernelArgSMMUBypass}),
+		)
+
 		It("should load vfio_pci driver", func() {
 			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
 				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
@@ -918,7 +1364,7 @@ var _ = Describe("Generic plugin", func() {
 						VfGroups: []sriovnetworkv1.VfGroup{{
 							DeviceType:   "vfio-pci",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -964,6 +1410,58 @@ var _ = Describe("Generic plugin", func() {
 			Expect(driverState.DriverLoaded).To(BeTrue())
 		})
 
+		Context("driver binding verification", func() {
+			var concretePlugin *GenericPlugin
+
+			BeforeEach(func() {
+				concretePlugin = genericPlugin.(*GenericPlugin)
+				concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+					Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+						Interfaces: sriovnetworkv1.Interfaces{{
+							PciAddress: "0000:00:00.0",
+							NumVfs:     2,
+							VfGroups: []sriovnetworkv1.VfGroup{{
+								DeviceType:   "vfio-pci",
+								ResourceName: "resource_1",
+								VfRange:      "0-1",
+							}}}},
+					},
+					Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+						Interfaces: sriovnetworkv1.InterfaceExts{{
+							PciAddress: "0000:00:00.0",
+							NumVfs:     2,
+							VFs: []sriovnetworkv1.VirtualFunction{
+								{PciAddress: "0000:00:00.1", VfID: 0, Driver: "iavf"},
+								{PciAddress: "0000:00:00.2", VfID: 1},
+							},
+						}},
+					},
+				}
+				hostHelper.EXPECT().IsKernelModuleLoaded("vfio_pci").Return(false, nil)
+				hostHelper.EXPECT().LoadKernelModule("vfio_pci").Return(nil)
+			})
+
+			It("should rebind VFs bound to other driver", func() {
+				hostHelper.EXPECT().VerifyDriverBinding("0000:00:00.1", "vfio-pci").Return(fmt.Errorf("wrong driver"))
+				hostHelper.EXPECT().BindDpdkDriver("0000:00:00.1", "vfio-pci").Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+				Expect(concretePlugin.DriverStateMap[Vfio].DriverLoaded).To(BeTrue())
+			})
+
+			It("should fail if rebind is not allowed", func() {
+				concretePlugin.DriverStateMap[Vfio].ForceRebind = false
+				hostHelper.EXPECT().VerifyDriverBinding("0000:00:00.1", "vfio-pci").Return(fmt.Errorf("wrong driver"))
+				err := concretePlugin.syncDriverState(context.Background())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("echo 0000:00:00.1 > /sys/bus/pci/devices/0000:00:00.1/driver/unbind"))
+			})
+
+			It("should not rebind VFs bound to expected driver", func() {
+				hostHelper.EXPECT().VerifyDriverBinding("0000:00:00.1", "vfio-pci").Return(nil)
+				Expect(concretePlugin.syncDriverState(context.Background())).NotTo(HaveOccurred())
+			})
+		})
+
 		It("should load virtio_vdpa driver", func() {
 			networkNodeState := &sriovnetworkv1.SriovNetworkNodeState{
 				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
@@ -975,7 +1473,7 @@ var _ = Describe("Generic plugin", func() {
 							DeviceType:   "netdevice",
 							VdpaType:     "virtio",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -1032,7 +1530,7 @@ var _ = Describe("Generic plugin", func() {
 							DeviceType:   "netdevice",
 							VdpaType:     "vhost",
 							PolicyName:   "policy-1",
-							ResourceName: "resource-1",
+							ResourceName: "resource_1",
 							VfRange:      "0-1",
 							Mtu:          1500,
 						}}}},
@@ -1089,7 +1587,7 @@ var _ = Describe("Generic plugin", func() {
 					VfGroups: []sriovnetworkv1.VfGroup{{
 						DeviceType:   "netdevice",
 						PolicyName:   "policy-1",
-						ResourceName: "resource-1",
+						ResourceName: "resource_1",
 						VfRange:      "0-0",
 					}}}},
 				Bridges: sriovnetworkv1.Bridges{
@@ -1153,7 +1651,7 @@ var _ = Describe("Generic plugin", func() {
 					VfGroups: []sriovnetworkv1.VfGroup{{
 						DeviceType:   "netdevice",
 						PolicyName:   "policy-1",
-						ResourceName: "resource-1",
+						ResourceName: "resource_1",
 						VfRange:      "0-0",
 					}}}},
 				Bridges: sriovnetworkv1.Bridges{
@@ -1223,7 +1721,7 @@ var _ = Describe("Generic plugin", func() {
 					VfGroups: []sriovnetworkv1.VfGroup{{
 						DeviceType:   "netdevice",
 						PolicyName:   "policy-1",
-						ResourceName: "resource-1",
+						ResourceName: "resource_1",
 						VfRange:      "0-0",
 					}}}},
 				Bridges: sriovnetworkv1.Bridges{
@@ -1281,4 +1779,2705 @@ var _ = Describe("Generic plugin", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(updated).To(BeTrue())
 	})
-})
+	Context("validateHostMount", func() {
+		It("should succeed for a valid host mount", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/proc", "/host/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/host/proc/version": []byte("Linux version 5.14.0")},
+			})
+			Expect(validateHostMount("/host")).NotTo(HaveOccurred())
+		})
+		It("should use the configured host mount path", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/hostfs/proc", "/hostfs/sys/bus/pci/devices/0000:d8:00.0"},
+				Files: map[string][]byte{"/hostfs/proc/version": []byte("Linux version 5.14.0")},
+			})
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithHostMountPath("/hostfs"))
+			Expect(err).ToNot(HaveOccurred())
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			Expect(validateHostMount(concretePlugin.hostMountPath)).NotTo(HaveOccurred())
+		})
+		It("should fail if /proc/version is missing", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/host/sys/bus/pci/devices/0000:d8:00.0"},
+			})
+			Expect(validateHostMount("/host")).To(MatchError(ContainSubstring("/proc/version")))
+		})
+		It("should fail if /sys/bus/pci is missing", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/proc"},
+				Files: map[string][]byte{"/host/proc/version": []byte("Linux version 5.14.0")},
+			})
+			Expect(validateHostMount("/host")).To(MatchError(ContainSubstring("/sys/bus/pci")))
+		})
+		It("should fail if no PCI devices found", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host/proc", "/host/sys/bus/pci/devices"},
+				Files: map[string][]byte{"/host/proc/version": []byte("Linux version 5.14.0")},
+			})
+			Expect(validateHostMount("/host")).To(MatchError(ContainSubstring("no PCI devices found")))
+		})
+	})
+	Context("ExportDesiredState", func() {
+		It("should export and load desired state", func() {
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			_, err := concretePlugin.ExportDesiredState()
+			Expect(err).To(HaveOccurred())
+
+			desiredState := &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     2,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType:   "vfio-pci",
+							PolicyName:   "policy-1",
+							ResourceName: "resource_1",
+							VfRange:      "0-1",
+						}}}},
+				},
+			}
+			desiredState.Name = "worker-0"
+			concretePlugin.DesireState = desiredState
+			data, err := concretePlugin.ExportDesiredState()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("kind: SriovNetworkNodeState"))
+			Expect(string(data)).To(ContainSubstring(`pciAddress: "0000:00:00.0"`))
+
+			concretePlugin.DesireState = nil
+			Expect(concretePlugin.LoadDesiredStateFromYAML(data)).NotTo(HaveOccurred())
+			Expect(concretePlugin.DesireState.Name).To(Equal("worker-0"))
+			Expect(concretePlugin.DesireState.Spec).To(Equal(desiredState.Spec))
+		})
+		It("should fail to load invalid desired state", func() {
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			Expect(concretePlugin.LoadDesiredStateFromYAML([]byte("spec:\n  unknown: field\n"))).To(HaveOccurred())
+		})
+	})
+	Context("Pause", func() {
+		It("should skip Apply while paused and not apply on Resume", func() {
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+
+			Expect(concretePlugin.Pause()).NotTo(HaveOccurred())
+			Expect(genericPlugin.Apply()).To(MatchError(plugin.ErrPluginPaused))
+
+			// no host calls are expected, the desired state is applied by the next node state sync
+			Expect(concretePlugin.Resume()).NotTo(HaveOccurred())
+			Expect(concretePlugin.isPaused()).To(BeFalse())
+		})
+	})
+
+	Context("Clone", func() {
+		var base *GenericPlugin
+
+		BeforeEach(func() {
+			base = genericPlugin.(*GenericPlugin)
+			base.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:00:01.0", NumVfs: 2}},
+				},
+			}
+			base.DesiredKernelArgs[consts.KernelArgIntelIommu] = false
+			base.DriverStateMap[Vfio].DriverLoaded = true
+			base.SkipPCIAddresses["0000:00:02.0"] = "test"
+		})
+
+		It("should copy the state of the plugin", func() {
+			clone := base.Clone()
+			Expect(clone.DesireState).To(Equal(base.DesireState))
+			Expect(clone.DesiredKernelArgs).To(Equal(base.DesiredKernelArgs))
+			Expect(clone.DriverStateMap[Vfio].DriverLoaded).To(BeTrue())
+			Expect(clone.SkipPCIAddresses).To(Equal(base.SkipPCIAddresses))
+			Expect(clone.helpers).To(BeIdenticalTo(base.helpers))
+			Expect(clone.watchdogStop).To(BeNil())
+		})
+
+		It("should not change the original plugin", func() {
+			clone := base.Clone()
+			clone.DesireState.Spec.Interfaces[0].NumVfs = 4
+			clone.DesiredKernelArgs[consts.KernelArgIntelIommu] = true
+			clone.DesiredKernelArgs[consts.KernelArgPciRealloc] = false
+			clone.DriverStateMap[Vfio].DriverLoaded = false
+			delete(clone.SkipPCIAddresses, "0000:00:02.0")
+			Expect(clone.Pause()).To(Succeed())
+
+			Expect(base.DesireState.Spec.Interfaces[0].NumVfs).To(Equal(2))
+			Expect(base.DesiredKernelArgs).To(Equal(map[string]bool{consts.KernelArgIntelIommu: false}))
+			Expect(base.DriverStateMap[Vfio].DriverLoaded).To(BeTrue())
+			Expect(base.SkipPCIAddresses).To(HaveKey("0000:00:02.0"))
+			Expect(base.isPaused()).To(BeFalse())
+		})
+
+		DescribeTable("should allow to apply different changes to the same base state",
+			func(numVfs int, expectedNumVfs []int) {
+				origUsingSystemdMode := vars.UsingSystemdMode
+				DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+				vars.UsingSystemdMode = true
+
+				clone := base.Clone()
+				clone.DesireState.Spec.Interfaces[0].NumVfs = numVfs
+				hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).DoAndReturn(
+					func(_ interface{}, interfaces []sriovnetworkv1.Interface, _ []sriovnetworkv1.InterfaceExt, _ bool) error {
+						applied := []int{}
+						for _, iface := range interfaces {
+							applied = append(applied, iface.NumVfs)
+						}
+						Expect(applied).To(Equal(expectedNumVfs))
+						return nil
+					})
+				hostHelper.EXPECT().ConfigureModprobeBlacklist(gomock.Any(), gomock.Any()).Return(nil)
+				hostHelper.EXPECT().ConfigureBridges(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+				Expect(clone.Apply()).To(Succeed())
+				Expect(base.DesireState.Spec.Interfaces[0].NumVfs).To(Equal(2))
+			},
+			Entry("unchanged", 2, []int{2}),
+			Entry("more VFs", 8, []int{8}),
+			Entry("VFs removed", 0, []int{0}),
+		)
+	})
+
+	Context("kernel args grace period", func() {
+		var (
+			p       *GenericPlugin
+			setArgs []string
+		)
+
+		BeforeEach(func() {
+			plug, err := NewGenericPlugin(hostHelper, WithWatchdogInterval(0), WithKernelParamGracePeriod(time.Second))
+			Expect(err).ToNot(HaveOccurred())
+			p = plug.(*GenericPlugin)
+			p.DesiredKernelArgs[consts.KernelArgIntelIommu] = false
+
+			setArgs = nil
+			origSetKernelArg := setKernelArg
+			origKernelArgPollInterval := kernelArgPollInterval
+			DeferCleanup(func() {
+				setKernelArg = origSetKernelArg
+				kernelArgPollInterval = origKernelArgPollInterval
+			})
+			setKernelArg = func(karg, _ string) (bool, error) {
+				setArgs = append(setArgs, karg)
+				return true, nil
+			}
+			kernelArgPollInterval = 10 * time.Millisecond
+			hostHelper.EXPECT().GetKernelArgsBackend().Return("rpm-ostree", nil).AnyTimes()
+		})
+
+		It("should not require reboot if the kernel arg appears in the cmdline during the grace period", func() {
+			gomock.InOrder(
+				hostHelper.EXPECT().GetCurrentKernelArgs().Return("quiet", nil),
+				hostHelper.EXPECT().GetCurrentKernelArgs().Return("quiet "+consts.KernelArgIntelIommu, nil),
+			)
+			hostHelper.EXPECT().IsKernelArgsSet("quiet", consts.KernelArgIntelIommu).Return(false)
+			hostHelper.EXPECT().IsKernelArgsSet("quiet "+consts.KernelArgIntelIommu, consts.KernelArgIntelIommu).Return(true)
+
+			needReboot, err := p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeFalse())
+			Expect(setArgs).To(Equal([]string{consts.KernelArgIntelIommu}))
+			Expect(p.KernelArgsSetTime).To(BeEmpty())
+		})
+
+		It("should require reboot if the kernel arg doesn't appear in the cmdline during the grace period", func() {
+			hostHelper.EXPECT().GetCurrentKernelArgs().Return("quiet", nil).MinTimes(1)
+			hostHelper.EXPECT().IsKernelArgsSet("quiet", consts.KernelArgIntelIommu).Return(false).MinTimes(1)
+
+			needReboot, err := p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeTrue())
+			Expect(p.KernelArgsSetTime).To(HaveKey(consts.KernelArgIntelIommu))
+		})
+
+		It("should require reboot without waiting once the grace period elapsed", func() {
+			p.KernelArgsSetTime[consts.KernelArgIntelIommu] = time.Now().Add(-time.Minute)
+
+			needReboot, err := p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeTrue())
+			// the time of the first attempt is kept
+			Expect(p.KernelArgsSetTime[consts.KernelArgIntelIommu]).To(BeTemporally("<", time.Now().Add(-time.Minute+time.Second)))
+		})
+
+		It("should require reboot immediately if the grace period is zero", func() {
+			p.KernelParamGracePeriod = 0
+
+			needReboot, err := p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeTrue())
+		})
+
+		It("should not require reboot if the bootloader configuration is not changed", func() {
+			setKernelArg = func(_, _ string) (bool, error) { return false, nil }
+
+			needReboot, err := p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeFalse())
+		})
+
+		It("should not set the kernel arg again during the cooldown", func() {
+			p.KernelParamGracePeriod = 0
+
+			needReboot, err := p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeTrue())
+			needReboot, err = p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).ToNot(HaveOccurred())
+			// the reboot for the kernel arg set before is still required
+			Expect(needReboot).To(BeTrue())
+			Expect(setArgs).To(Equal([]string{consts.KernelArgIntelIommu}))
+		})
+
+		It("should set the kernel arg again once the cooldown elapsed", func() {
+			p.KernelParamGracePeriod = 0
+			p.kernelParamLastSetAt[consts.KernelArgIntelIommu] = time.Now().Add(-p.KernelParamSetCooldown - time.Second)
+
+			_, err := p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(setArgs).To(Equal([]string{consts.KernelArgIntelIommu}))
+			Expect(p.kernelParamLastSetAt[consts.KernelArgIntelIommu]).To(BeTemporally("~", time.Now(), time.Second))
+		})
+
+		It("should set the kernel arg again after a failure", func() {
+			p.KernelParamGracePeriod = 0
+			setKernelArg = func(karg, _ string) (bool, error) {
+				setArgs = append(setArgs, karg)
+				return false, fmt.Errorf("test")
+			}
+
+			_, err := p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).To(HaveOccurred())
+			_, err = p.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).To(HaveOccurred())
+			Expect(setArgs).To(HaveLen(2))
+		})
+
+		It("should forget the set time once the kernel arg is in the cmdline", func() {
+			p.KernelArgsSetTime[consts.KernelArgIntelIommu] = time.Now()
+			hostHelper.EXPECT().GetCurrentKernelArgs().Return(consts.KernelArgIntelIommu, nil)
+			hostHelper.EXPECT().IsKernelArgsSet(consts.KernelArgIntelIommu, consts.KernelArgIntelIommu).Return(true)
+
+			missing, err := p.getMissingKernelArgs()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(missing).To(BeEmpty())
+			Expect(p.KernelArgsSetTime).To(BeEmpty())
+		})
+	})
+
+	Context("watchdog", func() {
+		newPluginWithWatchdog := func(interval time.Duration) *GenericPlugin {
+			p, err := NewGenericPlugin(hostHelper, WithWatchdogInterval(interval))
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(p.(*GenericPlugin).StopWatchdog)
+			return p.(*GenericPlugin)
+		}
+
+		newTrigger := func() (func(), chan struct{}) {
+			triggered := make(chan struct{}, 10)
+			return func() { triggered <- struct{}{} }, triggered
+		}
+
+		It("should be disabled by default", func() {
+			p := genericPlugin.(*GenericPlugin)
+			Expect(p.WatchdogInterval).To(BeZero())
+			trigger, _ := newTrigger()
+			Expect(p.StartWatchdog(trigger)).To(Succeed())
+			Expect(p.watchdogStop).To(BeNil())
+			Expect(p.StopWatchdog()).To(Succeed())
+			// stopping twice is allowed
+			Expect(p.StopWatchdog()).To(Succeed())
+		})
+
+		It("should not be started by the constructor", func() {
+			p := newPluginWithWatchdog(10 * time.Millisecond)
+			Expect(p.watchdogStop).To(BeNil())
+		})
+
+		It("should request a re-apply if no node state changes are received", func() {
+			p := newPluginWithWatchdog(10 * time.Millisecond)
+			p.stateLock.Lock()
+			p.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			p.stateLock.Unlock()
+
+			// the desired state is applied by the daemon, no host calls are expected
+			trigger, triggered := newTrigger()
+			Expect(p.StartWatchdog(trigger)).To(Succeed())
+			Eventually(triggered).Should(Receive())
+			Expect(p.StopWatchdog()).To(Succeed())
+		})
+
+		It("should not request a re-apply before the desired state is received", func() {
+			p := newPluginWithWatchdog(10 * time.Millisecond)
+			trigger, triggered := newTrigger()
+			Expect(p.StartWatchdog(trigger)).To(Succeed())
+			Consistently(triggered, 50*time.Millisecond).ShouldNot(Receive())
+			Expect(p.StopWatchdog()).To(Succeed())
+		})
+
+		It("should not request a re-apply while the node state changes", func() {
+			p := newPluginWithWatchdog(10 * time.Millisecond)
+			p.stateLock.Lock()
+			p.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			// node state changes are received more often than the watchdog interval
+			p.lastStateChange = time.Now().Add(time.Hour)
+			p.stateLock.Unlock()
+			trigger, triggered := newTrigger()
+			Expect(p.StartWatchdog(trigger)).To(Succeed())
+			Consistently(triggered, 50*time.Millisecond).ShouldNot(Receive())
+			Expect(p.StopWatchdog()).To(Succeed())
+		})
+
+		It("should skip the re-apply while paused", func() {
+			p := newPluginWithWatchdog(0)
+			p.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			trigger, triggered := newTrigger()
+			Expect(p.StartWatchdog(trigger)).To(Succeed())
+			Expect(p.Pause()).To(Succeed())
+			p.forceReconcile()
+			Expect(triggered).ToNot(Receive())
+		})
+
+		It("should not start once it is stopped", func() {
+			p := newPluginWithWatchdog(10 * time.Millisecond)
+			Expect(p.StopWatchdog()).To(Succeed())
+			trigger, _ := newTrigger()
+			Expect(p.StartWatchdog(trigger)).To(Succeed())
+			Expect(p.watchdogStop).To(BeNil())
+		})
+	})
+
+	Context("VF attribute reconciliation", func() {
+		var p *GenericPlugin
+
+		BeforeEach(func() {
+			plug, err := NewGenericPlugin(hostHelper, WithWatchdogInterval(0))
+			Expect(err).ToNot(HaveOccurred())
+			p = plug.(*GenericPlugin)
+			DeferCleanup(p.StopWatchdog)
+			p.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{
+					{PciAddress: "0000:00:01.0", Name: "ens1", NumVfs: 2,
+						VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-1", Trust: "on"}}},
+					{PciAddress: "0000:00:02.0", Name: "ens2", NumVfs: 2,
+						VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-1", Trust: "on"}}},
+					{PciAddress: "0000:00:03.0", Name: "ens3", NumVfs: 2,
+						VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-1", Trust: "on"}}},
+				}},
+			}
+			p.InterfaceReconcileStatus["0000:00:01.0"] = ReconcileStatus{LastSuccess: time.Now(),
+				spec: &p.DesireState.Spec.Interfaces[0]}
+			p.InterfaceReconcileStatus["0000:00:02.0"] = ReconcileStatus{LastError: errors.New("failed"), Attempts: 1}
+		})
+
+		It("should be disabled by default", func() {
+			Expect(p.VfAttributeReconcileInterval).To(BeZero())
+			Expect(p.vfAttributeReconcilerStop).To(BeNil())
+		})
+
+		It("should reapply the VF attributes of the PFs configured successfully and report the corrections", func() {
+			hostHelper.EXPECT().ReconcileVfAttributes(gomock.Any()).DoAndReturn(
+				func(iface *sriovnetworkv1.Interface) ([]hostTypes.VfAttributeCorrection, error) {
+					Expect(iface.PciAddress).To(Equal("0000:00:01.0"))
+					return []hostTypes.VfAttributeCorrection{
+						{VfID: 0, Attribute: hostTypes.VfAttributeTrust},
+						{VfID: 1, Attribute: hostTypes.VfAttributeTrust},
+					}, nil
+				})
+			p.reconcileVFAttributes(context.Background())
+
+			recorder := httptest.NewRecorder()
+			p.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			Expect(recorder.Body.String()).To(And(
+				ContainSubstring(`sriov_generic_plugin_vf_attribute_drift_corrected_total{attribute="trust"} 2`),
+				ContainSubstring(`sriov_generic_plugin_vf_attribute_drift_corrected_total{attribute="vlan"} 0`)))
+		})
+
+		It("should skip the skipped devices and the paused plugin", func() {
+			p.SkipPCIAddresses["0000:00:01.0"] = "skipped"
+			// no host calls are expected
+			p.reconcileVFAttributes(context.Background())
+			delete(p.SkipPCIAddresses, "0000:00:01.0")
+			Expect(p.Pause()).To(Succeed())
+			p.reconcileVFAttributes(context.Background())
+		})
+
+		It("should reconcile periodically with the interval of the operator config", func() {
+			reconciled := make(chan struct{}, 10)
+			hostHelper.EXPECT().ReconcileVfAttributes(gomock.Any()).DoAndReturn(
+				func(_ *sriovnetworkv1.Interface) ([]hostTypes.VfAttributeCorrection, error) {
+					reconciled <- struct{}{}
+					return nil, nil
+				}).MinTimes(1)
+			p.SetVFAttributeReconcileInterval(10 * time.Millisecond)
+			Eventually(reconciled).Should(Receive())
+
+			Expect(p.StopWatchdog()).To(Succeed())
+			Expect(p.vfAttributeReconcilerStop).To(BeNil())
+			// the reconciliation is not restarted once the plugin is stopped
+			p.SetVFAttributeReconcileInterval(time.Millisecond)
+			Expect(p.vfAttributeReconcilerStop).To(BeNil())
+		})
+	})
+
+	Context("config watcher", func() {
+		var (
+			p         *GenericPlugin
+			informers *informertest.FakeInformers
+		)
+
+		newConfigMap := func(data map[string]string) *corev1.ConfigMap {
+			return &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: consts.SkipDevicesConfigMapName, Namespace: vars.Namespace},
+				Data:       data,
+			}
+		}
+
+		BeforeEach(func() {
+			plug, err := NewGenericPlugin(hostHelper, WithWatchdogInterval(0))
+			Expect(err).ToNot(HaveOccurred())
+			p = plug.(*GenericPlugin)
+			DeferCleanup(p.StopWatchdog)
+
+			informers = &informertest.FakeInformers{}
+			origNewConfigMapCache := newConfigMapCache
+			DeferCleanup(func() { newConfigMapCache = origNewConfigMapCache })
+			newConfigMapCache = func(_, _ string) (cache.Cache, error) { return informers, nil }
+		})
+
+		It("should reload the devices to skip and the watchdog interval", func() {
+			Expect(p.StartWatchdog(func() {})).To(Succeed())
+			p.reloadConfig(newConfigMap(map[string]string{
+				"devices":           `"0000:00:02.0": managed by SmartNIC firmware`,
+				watchdogIntervalKey: "1h",
+			}))
+			Expect(p.SkipPCIAddresses).To(Equal(map[string]string{"0000:00:02.0": "managed by SmartNIC firmware"}))
+			Expect(p.WatchdogInterval).To(Equal(time.Hour))
+			Expect(p.watchdogStop).ToNot(BeNil())
+
+			p.reloadConfig(newConfigMap(map[string]string{watchdogIntervalKey: "0"}))
+			Expect(p.WatchdogInterval).To(BeZero())
+			Expect(p.watchdogStop).To(BeNil())
+			// the devices are kept if the key is removed
+			Expect(p.SkipPCIAddresses).To(HaveKey("0000:00:02.0"))
+		})
+
+		It("should keep the current configuration if the ConfigMap is invalid", func() {
+			p.SkipPCIAddresses = map[string]string{"0000:00:02.0": ""}
+			p.reloadConfig(newConfigMap(map[string]string{"devices": "not a map", watchdogIntervalKey: "often"}))
+			Expect(p.SkipPCIAddresses).To(Equal(map[string]string{"0000:00:02.0": ""}))
+			Expect(p.WatchdogInterval).To(BeZero())
+		})
+
+		It("should reload the maximum kernel param attempts and the apply timeout", func() {
+			p.reloadConfig(newConfigMap(map[string]string{
+				maxKernelParamAttemptsKey: "3",
+				applyTimeoutKey:           "10m",
+			}))
+			Expect(p.MaxKernelParamAttempts).To(Equal(3))
+			Expect(p.ApplyTimeout).To(Equal(10 * time.Minute))
+
+			p.reloadConfig(newConfigMap(map[string]string{
+				maxKernelParamAttemptsKey: "many",
+				applyTimeoutKey:           "long",
+			}))
+			Expect(p.MaxKernelParamAttempts).To(Equal(3))
+			Expect(p.ApplyTimeout).To(Equal(10 * time.Minute))
+		})
+
+		It("should cancel the context of an apply after the apply timeout", func() {
+			ctx, cancel := p.applyContext(context.Background())
+			_, hasDeadline := ctx.Deadline()
+			Expect(hasDeadline).To(BeFalse())
+			cancel()
+
+			p.ApplyTimeout = time.Minute
+			ctx, cancel = p.applyContext(context.Background())
+			_, hasDeadline = ctx.Deadline()
+			Expect(hasDeadline).To(BeTrue())
+			cancel()
+			Expect(ctx.Err()).To(MatchError(context.Canceled))
+		})
+
+		It("should ignore the keys without the generic plugin prefix", func() {
+			p.reloadConfig(newConfigMap(map[string]string{"watchdogInterval": "1h", "applyTimeout": "1h"}))
+			Expect(p.WatchdogInterval).To(BeZero())
+			Expect(p.ApplyTimeout).To(BeZero())
+		})
+
+		It("should reload the ionic firmware from the annotation", func() {
+			cm := newConfigMap(nil)
+			cm.Annotations = map[string]string{ionicFirmwareAnnotation: "pensando/dsc_fw.tar"}
+			p.reloadConfig(cm)
+			Expect(p.IonicFirmwarePath).To(Equal("pensando/dsc_fw.tar"))
+
+			p.reloadConfig(newConfigMap(nil))
+			Expect(p.IonicFirmwarePath).To(BeEmpty())
+		})
+
+		It("should not restart the watchdog once it is stopped", func() {
+			Expect(p.StopWatchdog()).To(Succeed())
+			p.reloadConfig(newConfigMap(map[string]string{watchdogIntervalKey: "1h"}))
+			Expect(p.WatchdogInterval).To(Equal(time.Hour))
+			Expect(p.watchdogStop).To(BeNil())
+		})
+
+		It("should load the ConfigMap and reload it on changes", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cm := newConfigMap(map[string]string{"devices": `"0000:00:02.0": ""`})
+			kubeClient := kclient.NewClientBuilder().WithObjects(cm).Build()
+			Expect(p.StartConfigWatcher(ctx, kubeClient, vars.Namespace, consts.SkipDevicesConfigMapName)).To(Succeed())
+			Expect(p.SkipPCIAddresses).To(HaveKey("0000:00:02.0"))
+
+			informer, err := informers.FakeInformerFor(ctx, &corev1.ConfigMap{})
+			Expect(err).ToNot(HaveOccurred())
+			updated := newConfigMap(map[string]string{"devices": `"0000:00:03.0": ""`})
+			informer.Update(cm, updated)
+			Expect(p.SkipPCIAddresses).To(Equal(map[string]string{"0000:00:03.0": ""}))
+
+			// other ConfigMaps are ignored
+			other := newConfigMap(map[string]string{"devices": `"0000:00:04.0": ""`})
+			other.Name = "other"
+			informer.Add(other)
+			Expect(p.SkipPCIAddresses).To(Equal(map[string]string{"0000:00:03.0": ""}))
+		})
+
+		It("should start watching if the ConfigMap doesn't exist", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			kubeClient := kclient.NewClientBuilder().Build()
+			Expect(p.StartConfigWatcher(ctx, kubeClient, vars.Namespace, consts.SkipDevicesConfigMapName)).To(Succeed())
+			Expect(p.SkipPCIAddresses).To(BeEmpty())
+
+			informer, err := informers.FakeInformerFor(ctx, &corev1.ConfigMap{})
+			Expect(err).ToNot(HaveOccurred())
+			informer.Add(newConfigMap(map[string]string{watchdogIntervalKey: "1h"}))
+			Expect(p.WatchdogInterval).To(Equal(time.Hour))
+		})
+	})
+
+	Context("SkipDevices", func() {
+		var kubeClient *fakek8s.Clientset
+
+		BeforeEach(func() {
+			kubeClient = fakek8s.NewSimpleClientset(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: consts.SkipDevicesConfigMapName, Namespace: vars.Namespace},
+				Data:       map[string]string{"devices": `"0000:00:00.0": managed by SmartNIC firmware`},
+			})
+			origNewKubeClient := newKubeClient
+			DeferCleanup(func() { newKubeClient = origNewKubeClient })
+			newKubeClient = func() (kubernetes.Interface, error) { return kubeClient, nil }
+
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithSkipDevices(consts.SkipDevicesConfigMapName))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		newNodeState := func() *sriovnetworkv1.SriovNetworkNodeState {
+			return &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{
+						{PciAddress: "0000:00:00.0", NumVfs: 2},
+						{PciAddress: "0000:00:01.0", NumVfs: 1, VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-0"}}},
+					},
+				},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{
+						{PciAddress: "0000:00:00.0", NumVfs: 1, TotalVfs: 4, Driver: "ice"},
+						{PciAddress: "0000:00:01.0", NumVfs: 1, TotalVfs: 4, Driver: "ice"},
+					},
+				},
+			}
+		}
+
+		It("should not drain for skipped devices", func() {
+			needDrain, _, err := genericPlugin.OnNodeStateChange(newNodeState())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needDrain).To(BeFalse())
+			Expect(genericPlugin.(*GenericPlugin).SkipPCIAddresses).To(Equal(
+				map[string]string{"0000:00:00.0": "managed by SmartNIC firmware"}))
+		})
+
+		It("should not configure skipped devices", func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			_, _, err := genericPlugin.OnNodeStateChange(newNodeState())
+			Expect(err).ToNot(HaveOccurred())
+
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(),
+				[]sriovnetworkv1.Interface{{PciAddress: "0000:00:01.0", NumVfs: 1, VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-0"}}}},
+				[]sriovnetworkv1.InterfaceExt{{PciAddress: "0000:00:01.0", NumVfs: 1, TotalVfs: 4, Driver: "ice"}},
+				false).Return(nil)
+			hostHelper.EXPECT().ConfigureModprobeBlacklist(gomock.Any(), gomock.Any()).Return(nil)
+			hostHelper.EXPECT().ConfigureBridges(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			Expect(genericPlugin.Apply()).NotTo(HaveOccurred())
+		})
+
+		It("should not allocate the hugepages requested for skipped devices", func() {
+			nodeState := newNodeState()
+			nodeState.Spec.Interfaces[0].Hugepages = &sriovnetworkv1.Hugepages{Size: "1Gi", Count: 4, SameNUMAAsNic: true}
+			nodeState.Spec.Interfaces[1].Hugepages = &sriovnetworkv1.Hugepages{Size: "2Mi", Count: 512}
+			_, _, err := genericPlugin.OnNodeStateChange(nodeState)
+			Expect(err).ToNot(HaveOccurred())
+
+			hostHelper.EXPECT().SetHugepages(-1, "2Mi", 512).Return(512, nil)
+			Expect(genericPlugin.(*GenericPlugin).syncHugepages(context.Background())).To(Succeed())
+		})
+
+		It("should configure all devices if the ConfigMap is removed", func() {
+			Expect(kubeClient.CoreV1().ConfigMaps(vars.Namespace).Delete(context.Background(),
+				consts.SkipDevicesConfigMapName, metav1.DeleteOptions{})).To(Succeed())
+			needDrain, _, err := genericPlugin.OnNodeStateChange(newNodeState())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needDrain).To(BeTrue())
+			Expect(genericPlugin.(*GenericPlugin).SkipPCIAddresses).To(BeEmpty())
+		})
+	})
+
+	Context("PF skip checks", func() {
+		It("should evaluate each PF with the check of its vendor", func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			mlxSkipper := &fakePFSkipper{vendorID: "15b3", skip: map[string]string{"0000:3b:00.1": "firmware change pending"}}
+			bcmSkipper := &fakePFSkipper{vendorID: "14e4"}
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithPFSkippers(mlxSkipper, bcmSkipper))
+			Expect(err).ToNot(HaveOccurred())
+
+			statusIfaces := sriovnetworkv1.InterfaceExts{
+				{PciAddress: "0000:3b:00.0", Vendor: "15b3", Driver: "mlx5_core", TotalVfs: 8},
+				{PciAddress: "0000:3b:00.1", Vendor: "15b3", Driver: "mlx5_core", TotalVfs: 8},
+				{PciAddress: "0000:5e:00.0", Vendor: "8086", Driver: "ice", TotalVfs: 8},
+				{PciAddress: "0000:5e:00.1", Vendor: "8086", TotalVfs: 8},
+				{PciAddress: "0000:af:00.0", Vendor: "14e4", Driver: "bnxt_en", TotalVfs: 8},
+			}
+			specIfaces := sriovnetworkv1.Interfaces{}
+			for _, iface := range statusIfaces {
+				specIfaces = append(specIfaces, sriovnetworkv1.Interface{PciAddress: iface.PciAddress, NumVfs: 1,
+					VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-0"}}})
+			}
+			genericPlugin.(*GenericPlugin).DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec:   sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: specIfaces},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: statusIfaces},
+			}
+
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(),
+				[]sriovnetworkv1.Interface{specIfaces[0], specIfaces[2], specIfaces[4]},
+				[]sriovnetworkv1.InterfaceExt{statusIfaces[0], statusIfaces[2], statusIfaces[4]},
+				false).Return(nil)
+			hostHelper.EXPECT().ConfigureModprobeBlacklist(gomock.Any(), gomock.Any()).Return(nil)
+			hostHelper.EXPECT().ConfigureBridges(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			Expect(genericPlugin.Apply()).To(Succeed())
+
+			Expect(mlxSkipper.checked).To(Equal([]string{"0000:3b:00.0", "0000:3b:00.1"}))
+			Expect(bcmSkipper.checked).To(Equal([]string{"0000:af:00.0"}))
+			Expect(genericPlugin.(*GenericPlugin).pfsToSkip).To(Equal(map[string]string{
+				"0000:3b:00.1": "firmware change pending",
+				"0000:5e:00.1": "PF is not bound to a driver",
+			}))
+		})
+
+		It("should fail if the check fails", func() {
+			genericPlugin, err = NewGenericPlugin(hostHelper,
+				WithPFSkippers(&fakePFSkipper{vendorID: "15b3", err: fmt.Errorf("test")}))
+			Expect(err).ToNot(HaveOccurred())
+			genericPlugin.(*GenericPlugin).DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{
+					{PciAddress: "0000:3b:00.0", Vendor: "15b3", Driver: "mlx5_core"},
+				}},
+			}
+			Expect(genericPlugin.(*GenericPlugin).syncPFsToSkip()).To(MatchError("test"))
+		})
+	})
+
+	Context("total VFs", func() {
+		var state *sriovnetworkv1.SriovNetworkNodeState
+
+		BeforeEach(func() {
+			state = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{
+					{PciAddress: "0000:3b:00.0", NumVfs: 16},
+					{PciAddress: "0000:5e:00.0", NumVfs: 4},
+				}},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{
+					{PciAddress: "0000:3b:00.0", Vendor: "15b3", TotalVfs: 8},
+					{PciAddress: "0000:5e:00.0", Vendor: "8086", TotalVfs: 8},
+				}},
+			}
+		})
+
+		It("should require a reboot if the vendor plugin raises the maximum number of VFs", func() {
+			raiser := &fakeTotalVfsRaiser{vendorID: "15b3", canRaise: true}
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithTotalVfsRaisers(raiser))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(genericPlugin.(*GenericPlugin).needRebootForTotalVfs(state)).To(BeTrue())
+
+			err = genericPlugin.(*GenericPlugin).checkTotalVfs(state.Spec.Interfaces, state.Status.Interfaces)
+			Expect(err).To(MatchError(ContainSubstring("the raised firmware limit is active after a reboot")))
+		})
+
+		It("should not require a reboot if the maximum number of VFs can't be raised", func() {
+			raiser := &fakeTotalVfsRaiser{vendorID: "15b3"}
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithTotalVfsRaisers(raiser))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(genericPlugin.(*GenericPlugin).needRebootForTotalVfs(state)).To(BeFalse())
+
+			err = genericPlugin.(*GenericPlugin).checkTotalVfs(state.Spec.Interfaces, state.Status.Interfaces)
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(ConsistOf(&hostTypes.InterfaceSyncError{PciAddress: "0000:3b:00.0",
+				Err: fmt.Errorf("numVfs 16 exceeds the maximum number of VFs 8 of the PF, the limit can't be raised by the vendor plugin")}))
+		})
+
+		It("should skip the externally managed PFs and the PFs within the limit", func() {
+			state.Spec.Interfaces[0].ExternallyManaged = true
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithTotalVfsRaisers(&fakeTotalVfsRaiser{vendorID: "15b3", canRaise: true}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(genericPlugin.(*GenericPlugin).needRebootForTotalVfs(state)).To(BeFalse())
+			Expect(genericPlugin.(*GenericPlugin).checkTotalVfs(state.Spec.Interfaces, state.Status.Interfaces)).To(Succeed())
+		})
+	})
+
+	Context("KernelParamSource", func() {
+		var kubeClient *fakek8s.Clientset
+
+		BeforeEach(func() {
+			kubeClient = fakek8s.NewSimpleClientset(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: consts.KernelParamsConfigMapName, Namespace: vars.Namespace},
+				Data:       map[string]string{"params": "nosoftlockup  nmi_watchdog=0\n"},
+			})
+			origNewKubeClient := newKubeClient
+			DeferCleanup(func() { newKubeClient = origNewKubeClient })
+			newKubeClient = func() (kubernetes.Interface, error) { return kubeClient, nil }
+
+			genericPlugin, err = NewGenericPlugin(hostHelper,
+				WithKernelParamSource(NewConfigMapKernelParamSource(consts.KernelParamsConfigMapName)))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should read kernel params from the ConfigMap", func() {
+			params, err := NewConfigMapKernelParamSource(consts.KernelParamsConfigMapName).GetRequiredKernelParams()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(params).To(Equal([]string{"nosoftlockup", "nmi_watchdog=0"}))
+		})
+
+		It("should return no kernel params if the ConfigMap doesn't exist", func() {
+			params, err := NewConfigMapKernelParamSource("missing").GetRequiredKernelParams()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(params).To(BeEmpty())
+		})
+
+		It("should reject invalid kernel params", func() {
+			_, err := kubeClient.CoreV1().ConfigMaps(vars.Namespace).Update(context.Background(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: consts.KernelParamsConfigMapName, Namespace: vars.Namespace},
+				Data:       map[string]string{"params": `nosoftlockup "quoted"`},
+			}, metav1.UpdateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = NewConfigMapKernelParamSource(consts.KernelParamsConfigMapName).GetRequiredKernelParams()
+			Expect(err).To(MatchError(ContainSubstring(`invalid kernel parameter "\"quoted\""`)))
+		})
+
+		It("should queue the kernel params from the ConfigMap", func() {
+			hostHelper.EXPECT().GetCurrentKernelArgs().Return("nosoftlockup nmi_watchdog=0", nil).Times(2)
+			hostHelper.EXPECT().IsKernelArgsSet("nosoftlockup nmi_watchdog=0", "nosoftlockup").Return(true)
+			hostHelper.EXPECT().IsKernelArgsSet("nosoftlockup nmi_watchdog=0", "nmi_watchdog=0").Return(true)
+
+			_, needReboot, err := genericPlugin.OnNodeStateChange(&sriovnetworkv1.SriovNetworkNodeState{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeFalse())
+			Expect(genericPlugin.(*GenericPlugin).DesiredKernelArgs).To(HaveKey("nosoftlockup"))
+			Expect(genericPlugin.(*GenericPlugin).DesiredKernelArgs).To(HaveKey("nmi_watchdog=0"))
+		})
+
+		It("should keep queued kernel params if the ConfigMap can't be read", func() {
+			p := genericPlugin.(*GenericPlugin)
+			p.addConfiguredKernelArgs()
+			newKubeClient = func() (kubernetes.Interface, error) { return nil, fmt.Errorf("no client") }
+			p.addConfiguredKernelArgs()
+			Expect(p.DesiredKernelArgs).To(Equal(map[string]bool{"nosoftlockup": false, "nmi_watchdog=0": false}))
+		})
+	})
+
+	Context("KernelParamManager", func() {
+		var (
+			tuned       *TunedKernelParamManager
+			profilePath string
+			tunedAdm    func(args string) string
+		)
+
+		BeforeEach(func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/host/etc/tuned", "/host/usr/sbin", "/host/run/tuned"},
+				Files: map[string][]byte{
+					"/host/usr/sbin/tuned-adm":  {},
+					"/host/run/tuned/tuned.pid": []byte("1"),
+				},
+			})
+			tuned = NewTunedKernelParamManager(host.AdaptToV2(hostHelper), hostHelper)
+			profilePath = filepath.Join(vars.FilesystemRoot, "/host/etc/tuned/sriov/tuned.conf")
+			tunedAdm = func(args string) string {
+				return fmt.Sprintf("chroot %s/host tuned-adm %s", vars.FilesystemRoot, args)
+			}
+		})
+
+		It("should use tuned only if it is requested and tuned manages the host", func() {
+			Expect(isTunedManagedHost()).To(BeTrue())
+			Expect(newKernelParamManager(host.AdaptToV2(hostHelper), hostHelper, nil, true)).To(BeAssignableToTypeOf(&TunedKernelParamManager{}))
+			Expect(newKernelParamManager(host.AdaptToV2(hostHelper), hostHelper, nil, false)).To(BeAssignableToTypeOf(&GrubbyKernelParamManager{}))
+
+			Expect(os.Remove(filepath.Join(vars.FilesystemRoot, "/host/run/tuned/tuned.pid"))).To(Succeed())
+			Expect(newKernelParamManager(host.AdaptToV2(hostHelper), hostHelper, nil, true)).To(BeAssignableToTypeOf(&GrubbyKernelParamManager{}))
+		})
+
+		It("should add the kernel param to the tuned profile including the active profile", func() {
+			hostHelper.EXPECT().GetCurrentKernelArgs().Return("quiet", nil)
+			hostHelper.EXPECT().IsKernelArgsSet("quiet", consts.KernelArgIntelIommu).Return(false)
+			hostHelper.EXPECT().RunCommand("/bin/sh", "-c", tunedAdm("active")).Return(
+				"Current active profile: throughput-performance\n", "", nil)
+			hostHelper.EXPECT().RunCommand("/bin/sh", "-c", tunedAdm("profile sriov")).Return("", "", nil)
+
+			update, err := tuned.Set(consts.KernelArgIntelIommu)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(update).To(BeTrue())
+			include, params, err := readTunedProfile(profilePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(include).To(Equal("throughput-performance"))
+			Expect(params).To(Equal([]string{consts.KernelArgIntelIommu}))
+
+			hostHelper.EXPECT().GetCurrentKernelArgs().Return("quiet", nil)
+			hostHelper.EXPECT().IsKernelArgsSet("quiet", consts.KernelArgIommuPt).Return(false)
+			hostHelper.EXPECT().RunCommand("/bin/sh", "-c", tunedAdm("active")).Return(
+				"Current active profile: sriov\n", "", nil)
+			hostHelper.EXPECT().RunCommand("/bin/sh", "-c", tunedAdm("profile sriov")).Return("", "", nil)
+
+			update, err = tuned.Set(consts.KernelArgIommuPt)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(update).To(BeTrue())
+			include, params, err = readTunedProfile(profilePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(include).To(Equal("throughput-performance"))
+			Expect(params).To(Equal([]string{consts.KernelArgIntelIommu, consts.KernelArgIommuPt}))
+		})
+
+		It("should not change the tuned profile if the kernel param is set", func() {
+			hostHelper.EXPECT().GetCurrentKernelArgs().Return(consts.KernelArgIntelIommu, nil)
+			hostHelper.EXPECT().IsKernelArgsSet(consts.KernelArgIntelIommu, consts.KernelArgIntelIommu).Return(true)
+
+			update, err := tuned.Set(consts.KernelArgIntelIommu)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(update).To(BeFalse())
+			Expect(profilePath).ToNot(BeAnExistingFile())
+		})
+
+		It("should be used by the plugin to set the kernel args", func() {
+			manager := &fakeKernelParamManager{set: map[string]bool{}}
+			p, err := NewGenericPlugin(hostHelper, WithKernelParamManager(manager), WithKernelParamGracePeriod(0))
+			Expect(err).ToNot(HaveOccurred())
+			concretePlugin := p.(*GenericPlugin)
+			concretePlugin.addToDesiredKernelArgs(consts.KernelArgIntelIommu)
+
+			needReboot, err := concretePlugin.needRebootNode(context.Background(), &sriovnetworkv1.SriovNetworkNodeState{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(needReboot).To(BeTrue())
+			Expect(manager.set).To(Equal(map[string]bool{consts.KernelArgIntelIommu: true}))
+		})
+	})
+
+	Context("last apply annotations", func() {
+		It("should record time and duration of the last successful apply", func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			Expect(sriovnetworkv1.AddToScheme(scheme.Scheme)).To(Succeed())
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Namespace: "test"},
+			}
+			kubeClient := kclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(nodeState.DeepCopy()).Build()
+
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithKubeClient(kubeClient))
+			Expect(err).ToNot(HaveOccurred())
+			genericPlugin.(*GenericPlugin).DesireState = nodeState
+
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(nil)
+			hostHelper.EXPECT().ConfigureModprobeBlacklist(gomock.Any(), gomock.Any()).Return(nil)
+			hostHelper.EXPECT().ConfigureBridges(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			Expect(genericPlugin.Apply()).NotTo(HaveOccurred())
+
+			updated := &sriovnetworkv1.SriovNetworkNodeState{}
+			Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(nodeState), updated)).To(Succeed())
+			Expect(updated.Annotations).To(HaveKey(consts.LastApplyTimeAnnotation))
+			Expect(updated.Annotations).To(HaveKey(consts.LastApplyDurationAnnotation))
+			_, err := time.Parse(time.RFC3339, updated.Annotations[consts.LastApplyTimeAnnotation])
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should not record failed apply", func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			Expect(sriovnetworkv1.AddToScheme(scheme.Scheme)).To(Succeed())
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Namespace: "test"},
+			}
+			kubeClient := kclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(nodeState.DeepCopy()).Build()
+
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithKubeClient(kubeClient))
+			Expect(err).ToNot(HaveOccurred())
+			genericPlugin.(*GenericPlugin).DesireState = nodeState
+
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(fmt.Errorf("test"))
+			Expect(genericPlugin.Apply()).To(HaveOccurred())
+
+			updated := &sriovnetworkv1.SriovNetworkNodeState{}
+			Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(nodeState), updated)).To(Succeed())
+			Expect(updated.Annotations).NotTo(HaveKey(consts.LastApplyTimeAnnotation))
+		})
+	})
+
+	Context("node state conditions", func() {
+		var (
+			nodeState  *sriovnetworkv1.SriovNetworkNodeState
+			kubeClient client.Client
+		)
+
+		BeforeEach(func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			Expect(sriovnetworkv1.AddToScheme(scheme.Scheme)).To(Succeed())
+			nodeState = &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Namespace: "test", Generation: 2},
+			}
+			kubeClient = kclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(nodeState.DeepCopy()).
+				WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).Build()
+			Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(nodeState), nodeState)).To(Succeed())
+
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithKubeClient(kubeClient))
+			Expect(err).ToNot(HaveOccurred())
+			genericPlugin.(*GenericPlugin).DesireState = nodeState
+		})
+
+		getConditions := func() []metav1.Condition {
+			updated := &sriovnetworkv1.SriovNetworkNodeState{}
+			Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(nodeState), updated)).To(Succeed())
+			return updated.Status.Conditions
+		}
+
+		It("should set the Ready condition on success", func() {
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(nil)
+			hostHelper.EXPECT().ConfigureModprobeBlacklist(gomock.Any(), gomock.Any()).Return(nil)
+			hostHelper.EXPECT().ConfigureBridges(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			Expect(genericPlugin.Apply()).NotTo(HaveOccurred())
+
+			conditions := getConditions()
+			Expect(apimeta.IsStatusConditionTrue(conditions, sriovnetworkv1.NodeStateConditionReady)).To(BeTrue())
+			Expect(apimeta.IsStatusConditionFalse(conditions, sriovnetworkv1.NodeStateConditionDegraded)).To(BeTrue())
+			Expect(apimeta.IsStatusConditionFalse(conditions, sriovnetworkv1.NodeStateConditionProgressing)).To(BeTrue())
+			Expect(apimeta.FindStatusCondition(conditions, sriovnetworkv1.NodeStateConditionReady).ObservedGeneration).To(Equal(int64(2)))
+		})
+
+		It("should set the Degraded condition with the error on failure", func() {
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(fmt.Errorf("test"))
+			Expect(genericPlugin.Apply()).To(HaveOccurred())
+
+			conditions := getConditions()
+			degraded := apimeta.FindStatusCondition(conditions, sriovnetworkv1.NodeStateConditionDegraded)
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal("ApplyFailed"))
+			Expect(degraded.Message).To(ContainSubstring("test"))
+			Expect(apimeta.IsStatusConditionFalse(conditions, sriovnetworkv1.NodeStateConditionReady)).To(BeTrue())
+			Expect(apimeta.IsStatusConditionFalse(conditions, sriovnetworkv1.NodeStateConditionProgressing)).To(BeTrue())
+		})
+	})
+
+	Context("ConditionManager", func() {
+		It("should patch the status only if the condition changed", func() {
+			Expect(sriovnetworkv1.AddToScheme(scheme.Scheme)).To(Succeed())
+			nodeState := &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Namespace: "test"},
+			}
+			kubeClient := kclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(nodeState.DeepCopy()).
+				WithStatusSubresource(&sriovnetworkv1.SriovNetworkNodeState{}).Build()
+			Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(nodeState), nodeState)).To(Succeed())
+			m := NewConditionManager(kubeClient)
+
+			Expect(m.SetCondition(nodeState, sriovnetworkv1.NodeStateConditionReady, "True", "ApplySucceeded", "")).To(Succeed())
+			Expect(nodeState.Status.Conditions).To(HaveLen(1))
+			updated := &sriovnetworkv1.SriovNetworkNodeState{}
+			Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(nodeState), updated)).To(Succeed())
+			Expect(apimeta.IsStatusConditionTrue(updated.Status.Conditions, sriovnetworkv1.NodeStateConditionReady)).To(BeTrue())
+			resourceVersion := updated.ResourceVersion
+
+			Expect(m.SetCondition(nodeState, sriovnetworkv1.NodeStateConditionReady, "True", "ApplySucceeded", "")).To(Succeed())
+			Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(nodeState), updated)).To(Succeed())
+			Expect(updated.ResourceVersion).To(Equal(resourceVersion))
+		})
+	})
+
+	Context("eSwitch mode errors", func() {
+		BeforeEach(func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+			genericPlugin.(*GenericPlugin).DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+		})
+
+		It("should report NIC without switchdev support", func() {
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(
+				fmt.Errorf("cannot configure sriov interfaces: %w", hostTypes.ErrEswitchModeNotSupported))
+			err := genericPlugin.Apply()
+			Expect(err).To(MatchError(hostTypes.ErrEswitchModeNotSupported))
+			Expect(err.Error()).To(ContainSubstring("NIC does not support switchdev: "))
+		})
+
+		It("should report bound VFs", func() {
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(
+				fmt.Errorf("cannot configure sriov interfaces: %w", hostTypes.ErrEswitchModeVFsBound))
+			err := genericPlugin.Apply()
+			Expect(err).To(MatchError(hostTypes.ErrEswitchModeVFsBound))
+			Expect(err.Error()).To(ContainSubstring("unbind VFs first: "))
+		})
+	})
+
+	Context("encapsulation offload", func() {
+		var concretePlugin *GenericPlugin
+
+		setOffload := func(vxlan, geneve *bool) {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress:    "0000:00:00.0",
+						Name:          "enp216s0f0np0",
+						NumVfs:        1,
+						VxlanOffload:  vxlan,
+						GeneveOffload: geneve,
+					}, {
+						PciAddress: "0000:00:00.1",
+						Name:       "enp216s0f1np1",
+						NumVfs:     1,
+					}},
+				},
+			}
+		}
+		enabled, disabled := true, false
+
+		It("should enable the offload", func() {
+			setOffload(&enabled, nil)
+			hostHelper.EXPECT().GetEncapOffloadState("enp216s0f0np0").Return(false, false, nil)
+			hostHelper.EXPECT().ConfigureEncapOffload("enp216s0f0np0", true, false).Return(nil)
+			Expect(concretePlugin.syncEncapOffload(context.Background())).NotTo(HaveOccurred())
+		})
+
+		It("should not change the offload which is already in the desired state", func() {
+			setOffload(&enabled, &disabled)
+			hostHelper.EXPECT().GetEncapOffloadState("enp216s0f0np0").Return(true, true, nil)
+			Expect(concretePlugin.syncEncapOffload(context.Background())).NotTo(HaveOccurred())
+		})
+
+		It("should disable the offload", func() {
+			setOffload(&disabled, &disabled)
+			hostHelper.EXPECT().GetEncapOffloadState("enp216s0f0np0").Return(true, true, nil)
+			hostHelper.EXPECT().ConfigureEncapOffload("enp216s0f0np0", false, false).Return(nil)
+			Expect(concretePlugin.syncEncapOffload(context.Background())).NotTo(HaveOccurred())
+		})
+
+		It("should report the failed PF", func() {
+			setOffload(nil, &enabled)
+			hostHelper.EXPECT().GetEncapOffloadState("enp216s0f0np0").Return(false, false, nil)
+			hostHelper.EXPECT().ConfigureEncapOffload("enp216s0f0np0", false, true).Return(fmt.Errorf("test"))
+			err := concretePlugin.syncEncapOffload(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("test")))
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(HaveLen(1))
+			Expect(hostTypes.GetInterfaceSyncErrors(err)[0].PciAddress).To(Equal("0000:00:00.0"))
+		})
+	})
+
+	Context("PF settings", func() {
+		var concretePlugin *GenericPlugin
+		enabled, disabled := true, false
+
+		setPfSettings := func(settings *sriovnetworkv1.PfSettings) {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						Name:       "enp216s0f0np0",
+						NumVfs:     1,
+						PfSettings: settings,
+					}},
+				},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{
+						PciAddress: "0000:00:00.0",
+						Name:       "enp216s0f0np0",
+					}},
+				},
+			}
+		}
+		BeforeEach(func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/host/etc/sriov-operator"}})
+		})
+
+		It("should apply the settings and restore the original values when they are not requested anymore", func() {
+			setPfSettings(&sriovnetworkv1.PfSettings{Promisc: &enabled, Features: map[string]bool{"rx-all": true}})
+			hostHelper.EXPECT().GetPfSettings("enp216s0f0np0").Return(&sriovnetworkv1.PfSettings{
+				Promisc: &disabled, AllMulticast: &disabled, Features: map[string]bool{"rx-all": false}}, nil)
+			hostHelper.EXPECT().SetPfSettings("enp216s0f0np0", &sriovnetworkv1.PfSettings{
+				Promisc: &enabled, Features: map[string]bool{"rx-all": true}}).Return(nil)
+			Expect(concretePlugin.syncPfSettings(context.Background())).NotTo(HaveOccurred())
+			originals, err := loadPfOriginalSettings()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(originals).To(Equal(map[string]*sriovnetworkv1.PfSettings{
+				"0000:00:00.0": {Promisc: &disabled, Features: map[string]bool{"rx-all": false}}}))
+
+			// the settings are already applied
+			hostHelper.EXPECT().GetPfSettings("enp216s0f0np0").Return(&sriovnetworkv1.PfSettings{
+				Promisc: &enabled, AllMulticast: &disabled, Features: map[string]bool{"rx-all": true}}, nil)
+			Expect(concretePlugin.syncPfSettings(context.Background())).NotTo(HaveOccurred())
+
+			// the policy which requested the settings is deleted
+			setPfSettings(nil)
+			hostHelper.EXPECT().GetPfSettings("enp216s0f0np0").Return(&sriovnetworkv1.PfSettings{
+				Promisc: &enabled, AllMulticast: &disabled, Features: map[string]bool{"rx-all": true}}, nil)
+			hostHelper.EXPECT().SetPfSettings("enp216s0f0np0", &sriovnetworkv1.PfSettings{
+				Promisc: &disabled, Features: map[string]bool{"rx-all": false}}).Return(nil)
+			Expect(concretePlugin.syncPfSettings(context.Background())).NotTo(HaveOccurred())
+			originals, err = loadPfOriginalSettings()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(originals).To(BeEmpty())
+
+			// nothing to restore anymore
+			Expect(concretePlugin.syncPfSettings(context.Background())).NotTo(HaveOccurred())
+		})
+
+		It("should keep the original values if the settings can't be applied", func() {
+			setPfSettings(&sriovnetworkv1.PfSettings{AllMulticast: &enabled})
+			hostHelper.EXPECT().GetPfSettings("enp216s0f0np0").Return(&sriovnetworkv1.PfSettings{
+				Promisc: &disabled, AllMulticast: &disabled}, nil)
+			hostHelper.EXPECT().SetPfSettings("enp216s0f0np0", &sriovnetworkv1.PfSettings{AllMulticast: &enabled}).
+				Return(fmt.Errorf("test"))
+			err := concretePlugin.syncPfSettings(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("test")))
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(HaveLen(1))
+			Expect(hostTypes.GetInterfaceSyncErrors(err)[0].PciAddress).To(Equal("0000:00:00.0"))
+			originals, err := loadPfOriginalSettings()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(originals).To(Equal(map[string]*sriovnetworkv1.PfSettings{"0000:00:00.0": {AllMulticast: &disabled}}))
+		})
+	})
+
+	Context("kernel version", func() {
+		var oldKernelHelper *mock_helper.MockHostHelpersInterface
+
+		BeforeEach(func() {
+			oldKernelHelper = mock_helper.NewMockHostHelpersInterface(ctrl)
+			oldKernelHelper.EXPECT().GetKernelVersion().Return("4.18.0-553.el8_10.x86_64", nil)
+		})
+
+		It("should only warn about the features which are not required", func() {
+			p, err := NewGenericPlugin(oldKernelHelper, WithWatchdogInterval(0))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.(*GenericPlugin).KernelVersionRequirements).To(Equal(defaultKernelVersionRequirements))
+		})
+
+		It("should fail if a required feature is not supported by the kernel", func() {
+			_, err := NewGenericPlugin(oldKernelHelper, WithWatchdogInterval(0),
+				WithRequiredKernelFeatures(KernelFeatureVdpa, KernelFeatureDevlinkParams))
+			Expect(err).To(MatchError(ContainSubstring(
+				"feature devlinkParams requires kernel version 4.19 or newer, the running kernel version is 4.18.0-553.el8_10.x86_64")))
+			Expect(err).To(MatchError(ContainSubstring("feature vdpa requires kernel version 5.12 or newer")))
+		})
+
+		It("should use the provided requirements", func() {
+			_, err := NewGenericPlugin(oldKernelHelper, WithWatchdogInterval(0),
+				WithKernelVersionRequirements(map[string]string{"test": "4.18"}), WithRequiredKernelFeatures("test"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should fail for an invalid minimum version", func() {
+			_, err := NewGenericPlugin(oldKernelHelper, WithWatchdogInterval(0),
+				WithKernelVersionRequirements(map[string]string{"test": "latest"}))
+			Expect(err).To(MatchError(ContainSubstring(`failed to parse kernel version "latest"`)))
+		})
+
+		It("should skip the check if the kernel version can't be read", func() {
+			helper := mock_helper.NewMockHostHelpersInterface(ctrl)
+			helper.EXPECT().GetKernelVersion().Return("", fmt.Errorf("test"))
+			_, err := NewGenericPlugin(helper, WithWatchdogInterval(0), WithRequiredKernelFeatures(KernelFeatureVdpa))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		DescribeTable("kernelVersionToSemver",
+			func(version, expected string) {
+				Expect(kernelVersionToSemver(version)).To(Equal(expected))
+			},
+			Entry("distribution kernel", "5.14.0-427.13.1.el9_4.x86_64", "v5.14.0"),
+			Entry("major and minor", "6.8", "v6.8.0"),
+			Entry("rc kernel", "6.10.0-rc2+", "v6.10.0"),
+			Entry("leading zeros", "05.04.010", "v5.4.10"),
+		)
+	})
+
+	Context("devlink parameters", func() {
+		state := func(externallyManaged bool) *sriovnetworkv1.SriovNetworkNodeState {
+			return &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress:        "0000:00:00.0",
+						NumVfs:            1,
+						ExternallyManaged: externallyManaged,
+						DevlinkParams:     map[string]string{"enable_sriov": "true"},
+					}},
+				},
+			}
+		}
+
+		It("should need reboot if a permanent parameter has a different value", func() {
+			hostHelper.EXPECT().IsDevlinkDeviceParamPermanent("0000:00:00.0", "enable_sriov").Return(true, nil)
+			hostHelper.EXPECT().GetDevlinkDeviceParam("0000:00:00.0", "enable_sriov").Return("false", nil)
+			Expect(genericPlugin.(*GenericPlugin).needRebootForDevlinkParams(context.Background(), state(false))).To(BeTrue())
+		})
+
+		It("should not need reboot if the permanent parameter has the requested value", func() {
+			hostHelper.EXPECT().IsDevlinkDeviceParamPermanent("0000:00:00.0", "enable_sriov").Return(true, nil)
+			hostHelper.EXPECT().GetDevlinkDeviceParam("0000:00:00.0", "enable_sriov").Return("true", nil)
+			Expect(genericPlugin.(*GenericPlugin).needRebootForDevlinkParams(context.Background(), state(false))).To(BeFalse())
+		})
+
+		It("should not need reboot for runtime parameters", func() {
+			hostHelper.EXPECT().IsDevlinkDeviceParamPermanent("0000:00:00.0", "enable_sriov").Return(false, nil)
+			Expect(genericPlugin.(*GenericPlugin).needRebootForDevlinkParams(context.Background(), state(false))).To(BeFalse())
+		})
+
+		It("should not check the parameters of externally managed PFs", func() {
+			Expect(genericPlugin.(*GenericPlugin).needRebootForDevlinkParams(context.Background(), state(true))).To(BeFalse())
+		})
+	})
+
+	Context("orphaned VF network namespaces", func() {
+		It("should clean up only the netdevice VFs without discovered netdev", func() {
+			concretePlugin := genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{
+						PciAddress: "0000:00:00.0",
+						VFs: []sriovnetworkv1.VirtualFunction{
+							{PciAddress: "0000:00:00.1", Driver: "iavf", Name: "enp0s0v0"},
+							{PciAddress: "0000:00:00.2", Driver: "iavf"},
+							{PciAddress: "0000:00:00.3", Driver: "vfio-pci"},
+							{PciAddress: "0000:00:00.4"},
+							{PciAddress: "0000:00:00.5", Driver: "iavf"},
+						},
+					}},
+				},
+			}
+			var cleaned []string
+			origCleanup := cleanupOrphanedVFNetNS
+			DeferCleanup(func() { cleanupOrphanedVFNetNS = origCleanup })
+			cleanupOrphanedVFNetNS = func(pciAddress string) error {
+				cleaned = append(cleaned, pciAddress)
+				return fmt.Errorf("test")
+			}
+			concretePlugin.cleanupOrphanedVFNetNS()
+			// the failure of a VF doesn't stop the cleanup of the next VFs
+			Expect(cleaned).To(Equal([]string{"0000:00:00.2", "0000:00:00.5"}))
+		})
+	})
+
+	Context("IRQ affinity", func() {
+		var (
+			concretePlugin *GenericPlugin
+			configured     map[int]string
+			irqAffinity    sriovnetworkv1.CPUSet
+		)
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			configured = map[int]string{}
+			origSetIRQAffinity := setIRQAffinity
+			DeferCleanup(func() { setIRQAffinity = origSetIRQAffinity })
+			setIRQAffinity = func(irqNum int, cpuSet string) error {
+				configured[irqNum] = cpuSet
+				return nil
+			}
+			irqAffinity = "3,0-2,8-11"
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{{
+						PciAddress: "0000:00:00.0",
+						VFs: []sriovnetworkv1.VirtualFunction{
+							{VfID: 0, PciAddress: "0000:00:01.0"},
+							{VfID: 1, PciAddress: "0000:00:01.1"},
+						},
+					}},
+				},
+			}
+		})
+
+		It("should set the affinity of the IRQs of the VFs", func() {
+			hostHelper.EXPECT().ListVFInterrupts("0000:00:01.0").Return([]int{150, 151}, nil)
+			hostHelper.EXPECT().ListVFInterrupts("0000:00:01.1").Return([]int{152}, nil)
+			Expect(concretePlugin.syncIRQAffinity(context.Background(), sriovnetworkv1.Interfaces{
+				{PciAddress: "0000:00:00.0", NumVfs: 2, IRQAffinity: &irqAffinity},
+				{PciAddress: "0000:00:00.1", NumVfs: 2},
+			})).To(Succeed())
+			Expect(configured).To(Equal(map[int]string{150: "0-3,8-11", 151: "0-3,8-11", 152: "0-3,8-11"}))
+		})
+
+		It("should return the error of the PF", func() {
+			hostHelper.EXPECT().ListVFInterrupts("0000:00:01.0").Return(nil, fmt.Errorf("no such file"))
+			err := concretePlugin.syncIRQAffinity(context.Background(), sriovnetworkv1.Interfaces{
+				{PciAddress: "0000:00:00.0", NumVfs: 2, IRQAffinity: &irqAffinity},
+			})
+			Expect(err).To(MatchError("0000:00:00.0: failed to list the IRQs of VF 0000:00:01.0: no such file"))
+			Expect(configured).To(BeEmpty())
+		})
+	})
+
+	Context("ghost VFs", func() {
+		var concretePlugin *GenericPlugin
+
+		BeforeEach(func() {
+			// 0000:d8:00.0 is in the spec with 2 VFs, 0000:d8:00.1 was configured with 1 VF and is not in the spec
+			// anymore, the VFs of 0000:d8:00.2 are externally managed and 0000:d8:00.3 is not configured by the operator
+			symlinks := map[string]string{}
+			for pf, numVfs := range map[string]int{"d8:00.0": 4, "d8:00.1": 2, "d8:00.2": 2, "d8:00.3": 2} {
+				for vfID := 0; vfID < numVfs; vfID++ {
+					symlinks[fmt.Sprintf("/sys/bus/pci/devices/0000:%s/virtfn%d", pf, vfID)] =
+						fmt.Sprintf("../0000:%s-%d", pf, vfID)
+				}
+			}
+			symlinks["/sys/bus/pci/devices/0000:d8:00.0-3/driver"] = "../../../bus/pci/drivers/iavf"
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/bus/pci/devices/0000:d8:00.0", "/sys/bus/pci/devices/0000:d8:00.1",
+					"/sys/bus/pci/devices/0000:d8:00.2", "/sys/bus/pci/devices/0000:d8:00.3",
+					"/sys/bus/pci/devices/0000:d8:00.0-3"},
+				Symlinks: symlinks,
+			})
+
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{
+						{PciAddress: "0000:d8:00.0", NumVfs: 2},
+						{PciAddress: "0000:d8:00.2", NumVfs: 1, ExternallyManaged: true},
+					},
+				},
+			}
+			hostHelper.EXPECT().LoadPfsStatus("0000:d8:00.0").Return(nil, false, nil).AnyTimes()
+			hostHelper.EXPECT().LoadPfsStatus("0000:d8:00.1").Return(
+				&sriovnetworkv1.Interface{PciAddress: "0000:d8:00.1", NumVfs: 1}, true, nil).AnyTimes()
+			hostHelper.EXPECT().LoadPfsStatus("0000:d8:00.3").Return(nil, false, nil).AnyTimes()
+		})
+
+		It("should detect the VFs which are not in the spec nor in the applied configuration", func() {
+			ghosts, err := concretePlugin.DetectGhostVFs(concretePlugin.DesireState)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ghosts).To(Equal([]GhostVF{
+				{PfPciAddress: "0000:d8:00.0", PciAddress: "0000:d8:00.0-2", VfID: 2},
+				{PfPciAddress: "0000:d8:00.0", PciAddress: "0000:d8:00.0-3", VfID: 3, Driver: "iavf"},
+				{PfPciAddress: "0000:d8:00.1", PciAddress: "0000:d8:00.1-1", VfID: 1},
+			}))
+		})
+
+		It("should not detect the VFs of the skipped PFs", func() {
+			concretePlugin.pfsToSkip = map[string]string{"0000:d8:00.0": "managed by SmartNIC firmware"}
+			ghosts, err := concretePlugin.DetectGhostVFs(concretePlugin.DesireState)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ghosts).To(Equal([]GhostVF{{PfPciAddress: "0000:d8:00.1", PciAddress: "0000:d8:00.1-1", VfID: 1}}))
+		})
+
+		It("should unbind the ghost VFs and remove the VFs of their PFs", func() {
+			hostHelper.EXPECT().Unbind("0000:d8:00.0-3").Return(nil)
+			hostHelper.EXPECT().SetSriovNumVfs("0000:d8:00.0", 0).Return(nil)
+			hostHelper.EXPECT().SetSriovNumVfs("0000:d8:00.1", 0).Return(nil)
+			ghosts, err := concretePlugin.DetectGhostVFs(concretePlugin.DesireState)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concretePlugin.RemediateGhostVFs(context.Background(), ghosts)).To(Succeed())
+		})
+
+		It("should not remove the VFs of a PF if a ghost VF can't be unbound", func() {
+			hostHelper.EXPECT().Unbind("0000:d8:00.0-3").Return(fmt.Errorf("device busy"))
+			hostHelper.EXPECT().SetSriovNumVfs("0000:d8:00.1", 0).Return(nil)
+			ghosts, err := concretePlugin.DetectGhostVFs(concretePlugin.DesireState)
+			Expect(err).NotTo(HaveOccurred())
+			err = concretePlugin.RemediateGhostVFs(context.Background(), ghosts)
+			Expect(err).To(MatchError("0000:d8:00.0: failed to unbind ghost VF 0000:d8:00.0-3: device busy"))
+		})
+
+		It("should only report the ghost VFs if the remediation is disabled", func() {
+			Expect(concretePlugin.syncGhostVFs(context.Background())).To(Succeed())
+		})
+
+		It("should remove the ghost VFs if the remediation is enabled", func() {
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithRemediateGhostVFs())
+			Expect(err).ToNot(HaveOccurred())
+			remediatingPlugin := genericPlugin.(*GenericPlugin)
+			remediatingPlugin.DesireState = concretePlugin.DesireState
+			hostHelper.EXPECT().Unbind("0000:d8:00.0-3").Return(nil)
+			hostHelper.EXPECT().SetSriovNumVfs("0000:d8:00.0", 0).Return(nil)
+			hostHelper.EXPECT().SetSriovNumVfs("0000:d8:00.1", 0).Return(nil)
+			Expect(remediatingPlugin.syncGhostVFs(context.Background())).To(Succeed())
+			Expect(remediatingPlugin.Clone().remediateGhostVFs).To(BeTrue())
+		})
+	})
+
+	Context("PF renames", func() {
+		It("should configure the PFs with their current netdev names", func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			// the name recorded for the first PF was taken by the second PF, the third PF has no netdev
+			pfNetdevNames["0000:00:00.0"] = "ens1f0np0"
+			pfNetdevNames["0000:00:00.1"] = "ens1f0"
+			vfGroups := []sriovnetworkv1.VfGroup{{VfRange: "0-0"}}
+			genericPlugin.(*GenericPlugin).DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{
+						{PciAddress: "0000:00:00.0", Name: "ens1f0", NumVfs: 1, VfGroups: vfGroups},
+						{PciAddress: "0000:00:00.1", Name: "ens1f1", NumVfs: 1, VfGroups: vfGroups},
+						{PciAddress: "0000:00:00.2", Name: "ens1f2", NumVfs: 1, VfGroups: vfGroups},
+					},
+				},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{
+						{PciAddress: "0000:00:00.0", Name: "ens1f0", TotalVfs: 4, Driver: "ice"},
+						{PciAddress: "0000:00:00.1", Name: "ens1f1", TotalVfs: 4, Driver: "ice"},
+						{PciAddress: "0000:00:00.2", Name: "ens1f2", TotalVfs: 4, Driver: "ice"},
+					},
+				},
+			}
+
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(),
+				[]sriovnetworkv1.Interface{
+					{PciAddress: "0000:00:00.0", Name: "ens1f0np0", NumVfs: 1, VfGroups: vfGroups},
+					{PciAddress: "0000:00:00.1", Name: "ens1f0", NumVfs: 1, VfGroups: vfGroups},
+					{PciAddress: "0000:00:00.2", Name: "ens1f2", NumVfs: 1, VfGroups: vfGroups},
+				},
+				[]sriovnetworkv1.InterfaceExt{
+					{PciAddress: "0000:00:00.0", Name: "ens1f0np0", TotalVfs: 4, Driver: "ice"},
+					{PciAddress: "0000:00:00.1", Name: "ens1f0", TotalVfs: 4, Driver: "ice"},
+					{PciAddress: "0000:00:00.2", Name: "ens1f2", TotalVfs: 4, Driver: "ice"},
+				},
+				false).Return(nil)
+			hostHelper.EXPECT().ConfigureModprobeBlacklist(gomock.Any(), gomock.Any()).Return(nil)
+			hostHelper.EXPECT().ConfigureBridges(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			Expect(genericPlugin.Apply()).To(Succeed())
+
+			statusNames := []string{}
+			for _, ifaceStatus := range genericPlugin.(*GenericPlugin).DesireState.Status.Interfaces {
+				statusNames = append(statusNames, ifaceStatus.Name)
+			}
+			Expect(statusNames).To(Equal([]string{"ens1f0np0", "ens1f0", "ens1f2"}))
+		})
+	})
+
+	Context("ApplyWithContext", func() {
+		It("should not apply the desired state if the context is done", func() {
+			genericPlugin.(*GenericPlugin).DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(genericPlugin.(*GenericPlugin).ApplyWithContext(ctx)).To(MatchError(context.Canceled))
+		})
+
+		It("should pass the context to the host manager", func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			hostManager := &contextRecordingHostManager{HostManagerV2Interface: host.AdaptToV2(hostHelper)}
+			genericPlugin, err = NewGenericPlugin(hostHelper, WithHostManagerV2(hostManager))
+			Expect(err).ToNot(HaveOccurred())
+			genericPlugin.(*GenericPlugin).DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(nil)
+			hostHelper.EXPECT().ConfigureModprobeBlacklist(gomock.Any(), gomock.Any()).Return(nil)
+			hostHelper.EXPECT().ConfigureBridges(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			ctx := context.WithValue(context.Background(), contextRecordingKey{}, "apply")
+			Expect(genericPlugin.(*GenericPlugin).ApplyWithContext(ctx)).To(Succeed())
+			Expect(hostManager.contexts).ToNot(BeEmpty())
+			for _, callCtx := range hostManager.contexts {
+				Expect(callCtx.Value(contextRecordingKey{})).To(Equal("apply"))
+			}
+		})
+	})
+
+	Context("double VLAN", func() {
+		type vfQinQ struct {
+			pf           string
+			vfID         int
+			outer, inner uint16
+		}
+		var (
+			concretePlugin *GenericPlugin
+			configured     []vfQinQ
+		)
+		vlan := func(vid uint16) *uint16 { return &vid }
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			configured = nil
+			origConfigure := configureVFQinQ
+			DeferCleanup(func() { configureVFQinQ = origConfigure })
+			configureVFQinQ = func(_ utils.CmdInterface, pf string, vfID int, outerVID, innerVID uint16) error {
+				configured = append(configured, vfQinQ{pf: pf, vfID: vfID, outer: outerVID, inner: innerVID})
+				return nil
+			}
+		})
+
+		It("should set the double VLAN of the VFs of each group", func() {
+			Expect(concretePlugin.syncVFQinQ(sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:00:00.0",
+				Name:       "enp0s0",
+				NumVfs:     4,
+				VfGroups: []sriovnetworkv1.VfGroup{
+					{VfRange: "0-1", OuterVLAN: vlan(100), InnerVLAN: vlan(10)},
+					{VfRange: "2-2"},
+					{VfRange: "3-3", OuterVLAN: vlan(200), InnerVLAN: vlan(20)},
+				},
+			}})).To(Succeed())
+			Expect(configured).To(Equal([]vfQinQ{
+				{pf: "enp0s0", vfID: 0, outer: 100, inner: 10},
+				{pf: "enp0s0", vfID: 1, outer: 100, inner: 10},
+				{pf: "enp0s0", vfID: 3, outer: 200, inner: 20},
+			}))
+		})
+
+		It("should return the error of the PF", func() {
+			configureVFQinQ = func(utils.CmdInterface, string, int, uint16, uint16) error {
+				return fmt.Errorf("test")
+			}
+			err := concretePlugin.syncVFQinQ(sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:00:00.0",
+				Name:       "enp0s0",
+				NumVfs:     1,
+				VfGroups:   []sriovnetworkv1.VfGroup{{VfRange: "0-0", OuterVLAN: vlan(100), InnerVLAN: vlan(10)}},
+			}})
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(ConsistOf(
+				&hostTypes.InterfaceSyncError{PciAddress: "0000:00:00.0", Err: fmt.Errorf("test")}))
+		})
+	})
+
+	Context("VF RSS", func() {
+		type vfRSS struct {
+			pf      string
+			vfID    int
+			hashKey string
+			fields  []string
+		}
+		var (
+			concretePlugin *GenericPlugin
+			configured     []vfRSS
+		)
+		key := strings.Repeat("6d5a", 20)
+		keyEthtool := strings.TrimSuffix(strings.Repeat("6d:5a:", 20), ":")
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			configured = nil
+			origConfigure := configureVFRSS
+			DeferCleanup(func() { configureVFRSS = origConfigure })
+			configureVFRSS = func(_ utils.CmdInterface, pf string, vfID int, hashKey string, fields []string) error {
+				configured = append(configured, vfRSS{pf: pf, vfID: vfID, hashKey: hashKey, fields: fields})
+				return nil
+			}
+		})
+
+		interfaces := func(groups ...sriovnetworkv1.VfGroup) sriovnetworkv1.Interfaces {
+			return sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:00:00.0",
+				Name:       "enp0s0",
+				NumVfs:     3,
+				VfGroups:   groups,
+			}}
+		}
+
+		It("should configure only the settings of the VFs which differ", func() {
+			hostHelper.EXPECT().GetVFRSSConfig("enp0s0", 0).Return(&hostTypes.RSSConfig{HashKey: "00:11", HashFields: []string{"ipv4-tcp"}}, nil)
+			hostHelper.EXPECT().GetVFRSSConfig("enp0s0", 1).Return(&hostTypes.RSSConfig{HashKey: keyEthtool, HashFields: []string{"ipv4-udp"}}, nil)
+			hostHelper.EXPECT().GetVFRSSConfig("enp0s0", 2).Return(&hostTypes.RSSConfig{HashKey: keyEthtool, HashFields: []string{"ipv4-tcp", "ipv4-udp"}}, nil)
+			Expect(concretePlugin.syncVFRSS(context.Background(), interfaces(sriovnetworkv1.VfGroup{
+				VfRange: "0-2", RSSHashKey: &key, RSSHashFields: []string{"ipv4-udp", "ipv4-tcp"},
+			}))).To(Succeed())
+			Expect(configured).To(Equal([]vfRSS{
+				{pf: "enp0s0", vfID: 0, hashKey: keyEthtool, fields: []string{"ipv4-udp", "ipv4-tcp"}},
+				{pf: "enp0s0", vfID: 1, fields: []string{"ipv4-udp", "ipv4-tcp"}},
+			}))
+		})
+
+		It("should skip the groups without RSS and the VFs without netdevice", func() {
+			hostHelper.EXPECT().GetVFRSSConfig("enp0s0", 2).Return(nil, nil)
+			Expect(concretePlugin.syncVFRSS(context.Background(), interfaces(
+				sriovnetworkv1.VfGroup{VfRange: "0-1"},
+				sriovnetworkv1.VfGroup{VfRange: "2-2", RSSHashKey: &key},
+			))).To(Succeed())
+			Expect(configured).To(BeEmpty())
+		})
+
+		It("should return the error of the PF", func() {
+			hostHelper.EXPECT().GetVFRSSConfig("enp0s0", 0).Return(nil, fmt.Errorf("test"))
+			err := concretePlugin.syncVFRSS(context.Background(), interfaces(sriovnetworkv1.VfGroup{VfRange: "0-2", RSSHashFields: []string{"ipv4-tcp"}}))
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(ConsistOf(
+				&hostTypes.InterfaceSyncError{PciAddress: "0000:00:00.0", Err: fmt.Errorf("test")}))
+		})
+	})
+
+	Context("bridge VLAN filters", func() {
+		type vfFilters struct {
+			pf      string
+			vfID    int
+			filters []sriovnetworkv1.VLANFilterEntry
+		}
+		var (
+			concretePlugin *GenericPlugin
+			configured     []vfFilters
+		)
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			configured = nil
+			origConfigure := configureVFBridgeVLAN
+			DeferCleanup(func() { configureVFBridgeVLAN = origConfigure })
+			configureVFBridgeVLAN = func(_ utils.CmdInterface, pfName string, vfID int, filters []sriovnetworkv1.VLANFilterEntry) error {
+				configured = append(configured, vfFilters{pf: pfName, vfID: vfID, filters: filters})
+				return nil
+			}
+		})
+
+		It("should configure the VLAN filters of the netdevice VFs of each group", func() {
+			filter1 := []sriovnetworkv1.VLANFilterEntry{{VID: 100, EgressUntagged: true}}
+			filter2 := []sriovnetworkv1.VLANFilterEntry{{VID: 200, Ingress: true}}
+			Expect(concretePlugin.syncVFBridgeVLAN(sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:00:00.0",
+				Name:       "enp0s0",
+				NumVfs:     5,
+				VfGroups: []sriovnetworkv1.VfGroup{
+					{VfRange: "0-1", DeviceType: consts.DeviceTypeNetDevice, VLANFilter: filter1},
+					{VfRange: "2-2", DeviceType: consts.DeviceTypeVfioPci, VLANFilter: filter2},
+					{VfRange: "3-3", DeviceType: consts.DeviceTypeNetDevice},
+					{VfRange: "4-4", VLANFilter: filter2},
+				},
+			}})).To(Succeed())
+			Expect(configured).To(Equal([]vfFilters{
+				{pf: "enp0s0", vfID: 0, filters: filter1},
+				{pf: "enp0s0", vfID: 1, filters: filter1},
+				{pf: "enp0s0", vfID: 4, filters: filter2},
+			}))
+		})
+
+		It("should return the error of the PF", func() {
+			configureVFBridgeVLAN = func(utils.CmdInterface, string, int, []sriovnetworkv1.VLANFilterEntry) error {
+				return fmt.Errorf("test")
+			}
+			err := concretePlugin.syncVFBridgeVLAN(sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:00:00.0",
+				Name:       "enp0s0",
+				NumVfs:     1,
+				VfGroups: []sriovnetworkv1.VfGroup{
+					{VfRange: "0-0", VLANFilter: []sriovnetworkv1.VLANFilterEntry{{VID: 100}}},
+				},
+			}})
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(ConsistOf(
+				&hostTypes.InterfaceSyncError{PciAddress: "0000:00:00.0", Err: fmt.Errorf("test")}))
+		})
+
+		It("should not require a drain when the VLAN filters change", func() {
+			spec := sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{{
+				PciAddress: "0000:00:00.0",
+				NumVfs:     1,
+				VfGroups: []sriovnetworkv1.VfGroup{{
+					VfRange:    "0-0",
+					DeviceType: consts.DeviceTypeNetDevice,
+					VLANFilter: []sriovnetworkv1.VLANFilterEntry{{VID: 100}},
+				}},
+			}}}
+			status := sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{{
+				PciAddress: "0000:00:00.0",
+				NumVfs:     1,
+				VFs:        []sriovnetworkv1.VirtualFunction{{VfID: 0, Driver: "iavf"}},
+			}}}
+			Expect(concretePlugin.needDrainNode(context.Background(), spec, status)).To(BeFalse())
+		})
+	})
+
+	Context("DCBX auto configuration", func() {
+		var (
+			concretePlugin *GenericPlugin
+			dcbxConfig     *utils.DCBXConfig
+			read           []string
+			applied        []string
+		)
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{
+					{PciAddress: "0000:00:00.0", Name: "enp0s0", NumVfs: 2, DCBXAutoConfig: true},
+					{PciAddress: "0000:00:00.1", Name: "enp0s1", NumVfs: 2},
+					{PciAddress: "0000:00:00.2", Name: "enp0s2", DCBXAutoConfig: true},
+				}},
+			}
+			dcbxConfig = &utils.DCBXConfig{PrioTC: map[int]int{0: 0, 3: 1}, TCBandwidth: map[int]int{0: 40, 1: 60}}
+			read, applied = nil, nil
+			origRead, origApply := readLLDPDCBXConfig, applyDCBXToVFs
+			DeferCleanup(func() { readLLDPDCBXConfig, applyDCBXToVFs = origRead, origApply })
+			readLLDPDCBXConfig = func(_ utils.CmdInterface, ifname string) (*utils.DCBXConfig, error) {
+				read = append(read, ifname)
+				return dcbxConfig, nil
+			}
+			applyDCBXToVFs = func(_ utils.CmdInterface, ifname string, cfg *utils.DCBXConfig, numVFs int) error {
+				Expect(cfg).To(Equal(dcbxConfig))
+				Expect(numVFs).To(Equal(2))
+				applied = append(applied, ifname)
+				return nil
+			}
+		})
+
+		It("should read the configuration of the PFs with VFs which request it", func() {
+			configs, err := concretePlugin.readDCBXConfigs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(read).To(Equal([]string{"enp0s0"}))
+			Expect(configs).To(Equal(map[string]*utils.DCBXConfig{"0000:00:00.0": dcbxConfig}))
+
+			Expect(concretePlugin.syncDCBX(concretePlugin.DesireState.Spec.Interfaces, configs)).To(Succeed())
+			Expect(applied).To(Equal([]string{"enp0s0"}))
+		})
+
+		It("should not read the configuration if the VFs are not configured", func() {
+			concretePlugin.skipVFConfiguration = true
+			DeferCleanup(func() { concretePlugin.skipVFConfiguration = false })
+			configs, err := concretePlugin.readDCBXConfigs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configs).To(BeEmpty())
+			Expect(read).To(BeEmpty())
+		})
+
+		It("should return the error of the PF", func() {
+			readLLDPDCBXConfig = func(utils.CmdInterface, string) (*utils.DCBXConfig, error) {
+				return nil, fmt.Errorf("test")
+			}
+			_, err := concretePlugin.readDCBXConfigs()
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(ConsistOf(
+				&hostTypes.InterfaceSyncError{PciAddress: "0000:00:00.0", Err: fmt.Errorf("test")}))
+		})
+	})
+
+	Context("OVS-DPDK offload", func() {
+		var (
+			concretePlugin *GenericPlugin
+			configured     map[string]map[int]string
+			cleaned        map[string]map[int]string
+			configureErr   error
+		)
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{
+					{PciAddress: "0000:00:00.0", Name: "enp0s0", NumVfs: 2, OVSDPDKOffload: true,
+						RepresentorMapping: map[int]string{0: "vf0-rep", 1: "vf1-rep", 2: "vf2-rep"}},
+					{PciAddress: "0000:00:00.1", Name: "enp0s1", NumVfs: 2, RepresentorMapping: map[int]string{0: "pf1vf0"}},
+				}},
+			}
+			configured, cleaned, configureErr = map[string]map[int]string{}, map[string]map[int]string{}, nil
+			origConfigure, origCleanup := configureOVSDPDKVFs, cleanupOVSDPDKVFs
+			DeferCleanup(func() { configureOVSDPDKVFs, cleanupOVSDPDKVFs = origConfigure, origCleanup })
+			configureOVSDPDKVFs = func(_ utils.CmdInterface, pf string, vfCount int, representorMap map[int]string) error {
+				Expect(vfCount).To(Equal(2))
+				configured[pf] = representorMap
+				return configureErr
+			}
+			cleanupOVSDPDKVFs = func(_ utils.CmdInterface, pf string, representorMap map[int]string) error {
+				cleaned[pf] = representorMap
+				return nil
+			}
+		})
+
+		sync := func() error {
+			return concretePlugin.syncOVSDPDKOffload(concretePlugin.DesireState.Spec.Interfaces)
+		}
+
+		It("should configure the VFs of the PFs which request it", func() {
+			Expect(sync()).To(Succeed())
+			Expect(configured).To(Equal(map[string]map[int]string{
+				"enp0s0": {0: "vf0-rep", 1: "vf1-rep", 2: "vf2-rep"}}))
+			Expect(cleaned).To(BeEmpty())
+			Expect(concretePlugin.ovsDPDKOffloads).To(Equal(map[string]ovsDPDKOffload{
+				"0000:00:00.0": {pfName: "enp0s0", representorMap: map[int]string{0: "vf0-rep", 1: "vf1-rep"}}}))
+		})
+
+		It("should remove the OVS ports of the VFs which are not requested anymore", func() {
+			Expect(sync()).To(Succeed())
+			concretePlugin.DesireState.Spec.Interfaces[0].RepresentorMapping = map[int]string{0: "vf0-rep", 1: "rep1"}
+			Expect(sync()).To(Succeed())
+			Expect(cleaned).To(Equal(map[string]map[int]string{"enp0s0": {1: "vf1-rep"}}))
+			Expect(concretePlugin.ovsDPDKOffloads["0000:00:00.0"].representorMap).To(Equal(map[int]string{0: "vf0-rep", 1: "rep1"}))
+
+			concretePlugin.DesireState.Spec.Interfaces = concretePlugin.DesireState.Spec.Interfaces[1:]
+			Expect(sync()).To(Succeed())
+			Expect(cleaned).To(Equal(map[string]map[int]string{"enp0s0": {0: "vf0-rep", 1: "rep1"}}))
+			Expect(concretePlugin.ovsDPDKOffloads).To(BeEmpty())
+		})
+
+		It("should not configure the VFs if the VF configuration is skipped", func() {
+			concretePlugin.skipVFConfiguration = true
+			DeferCleanup(func() { concretePlugin.skipVFConfiguration = false })
+			Expect(sync()).To(Succeed())
+			Expect(configured).To(BeEmpty())
+		})
+
+		It("should return the error of the PF", func() {
+			configureErr = fmt.Errorf("test")
+			err := sync()
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(ConsistOf(
+				&hostTypes.InterfaceSyncError{PciAddress: "0000:00:00.0", Err: fmt.Errorf("test")}))
+			Expect(concretePlugin.ovsDPDKOffloads).To(BeEmpty())
+		})
+	})
+
+	Context("bonded PFs", func() {
+		var concretePlugin *GenericPlugin
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{
+					{PciAddress: "0000:00:00.0", Name: "enp0s0", NumVfs: 2},
+					{PciAddress: "0000:00:00.1", Name: "enp0s1", NumVfs: 2},
+				}},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{
+					{PciAddress: "0000:00:00.0", Name: "enp0s0", Vendor: "15b3", BondMaster: "bond0"},
+					{PciAddress: "0000:00:00.1", Name: "enp0s1", Vendor: "15b3", BondMaster: "bond0"},
+					{PciAddress: "0000:00:00.2", Name: "enp0s2", Vendor: "15b3"},
+				}},
+			}
+		})
+
+		check := func() error {
+			return concretePlugin.checkBondedPFs(concretePlugin.DesireState.Spec.Interfaces, concretePlugin.DesireState.Status.Interfaces)
+		}
+
+		It("should refuse to configure the bonded PFs which are not allowed", func() {
+			concretePlugin.DesireState.Spec.Interfaces[1].AllowBondedPF = true
+			err := check()
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(HaveLen(1))
+			Expect(err).To(MatchError(ContainSubstring("PF enp0s0 is enslaved to bond bond0, set allowBondedPF in the policy to configure it")))
+		})
+
+		It("should configure the allowed VF-LAG ports with the same eSwitch mode", func() {
+			for i := range concretePlugin.DesireState.Spec.Interfaces {
+				concretePlugin.DesireState.Spec.Interfaces[i].AllowBondedPF = true
+				concretePlugin.DesireState.Spec.Interfaces[i].EswitchMode = sriovnetworkv1.ESwithModeSwitchDev
+			}
+			Expect(check()).To(Succeed())
+		})
+
+		It("should fail if the VF-LAG ports have different eSwitch modes", func() {
+			for i := range concretePlugin.DesireState.Spec.Interfaces {
+				concretePlugin.DesireState.Spec.Interfaces[i].AllowBondedPF = true
+			}
+			concretePlugin.DesireState.Spec.Interfaces[0].EswitchMode = sriovnetworkv1.ESwithModeSwitchDev
+			err := check()
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(HaveLen(2))
+			Expect(err).To(MatchError(ContainSubstring("eSwitchMode switchdev differs from eSwitchMode legacy of port enp0s1 of VF-LAG bond bond0")))
+		})
+
+		It("should fail if a VF-LAG port is not selected", func() {
+			concretePlugin.DesireState.Spec.Interfaces = concretePlugin.DesireState.Spec.Interfaces[:1]
+			concretePlugin.DesireState.Spec.Interfaces[0].AllowBondedPF = true
+			Expect(check()).To(MatchError(ContainSubstring("port enp0s1 of VF-LAG bond bond0 is not selected by a policy")))
+		})
+	})
+
+	Context("RDMA subsystem netns mode", func() {
+		var concretePlugin *GenericPlugin
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					System: sriovnetworkv1.System{RdmaMode: consts.RdmaSubsystemModeExclusive},
+				},
+			}
+		})
+
+		It("should set the mode if it differs", func() {
+			hostHelper.EXPECT().GetRDMASubsystemNetnsMode().Return(consts.RdmaSubsystemModeShared, nil)
+			hostHelper.EXPECT().SetRDMASubsystemNetnsMode(consts.RdmaSubsystemModeExclusive).Return(nil)
+			Expect(concretePlugin.syncRdmaMode(context.Background())).To(Succeed())
+		})
+
+		It("should not set the mode if it is already configured", func() {
+			hostHelper.EXPECT().GetRDMASubsystemNetnsMode().Return(consts.RdmaSubsystemModeExclusive, nil)
+			Expect(concretePlugin.syncRdmaMode(context.Background())).To(Succeed())
+		})
+
+		It("should not read the mode if no policy requests it", func() {
+			concretePlugin.DesireState.Spec.System.RdmaMode = ""
+			Expect(concretePlugin.syncRdmaMode(context.Background())).To(Succeed())
+		})
+
+		It("should return the error of the host", func() {
+			hostHelper.EXPECT().GetRDMASubsystemNetnsMode().Return(consts.RdmaSubsystemModeShared, nil)
+			hostHelper.EXPECT().SetRDMASubsystemNetnsMode(consts.RdmaSubsystemModeExclusive).Return(fmt.Errorf("test"))
+			Expect(concretePlugin.syncRdmaMode(context.Background())).To(MatchError("test"))
+		})
+
+		It("should drain the node if the mode changes", func() {
+			status := sriovnetworkv1.SriovNetworkNodeStateStatus{
+				System: sriovnetworkv1.System{RdmaMode: consts.RdmaSubsystemModeShared},
+			}
+			Expect(concretePlugin.needDrainNode(context.Background(), concretePlugin.DesireState.Spec, status)).To(BeTrue())
+			status.System.RdmaMode = consts.RdmaSubsystemModeExclusive
+			Expect(concretePlugin.needDrainNode(context.Background(), concretePlugin.DesireState.Spec, status)).To(BeFalse())
+		})
+	})
+
+	Context("driver override", func() {
+		var state *sriovnetworkv1.SriovNetworkNodeState
+
+		BeforeEach(func() {
+			origAllowed := vars.DriverOverrideAllowed
+			DeferCleanup(func() { vars.DriverOverrideAllowed = origAllowed })
+			vars.DriverOverrideAllowed = true
+			state = &sriovnetworkv1.SriovNetworkNodeState{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					consts.NodeStateDriverOverridesAnnotation: `{"policy_1":"vfio-pci"}`,
+				}},
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     1,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType: consts.DeviceTypeNetDevice,
+							PolicyName: "policy_1",
+							VfRange:    "0-0",
+						}},
+					}},
+				},
+			}
+		})
+
+		It("should require the driver of the override", func() {
+			driverStateMap := genericPlugin.(*GenericPlugin).getDriverStateMap()
+			Expect(needDriverCheckDeviceType(state, driverStateMap[Vfio])).To(BeTrue())
+		})
+
+		It("should ignore the device type of the overridden VF groups", func() {
+			state.Annotations[consts.NodeStateDriverOverridesAnnotation] = `{"policy_1":"mlx5_core"}`
+			state.Spec.Interfaces[0].VfGroups[0].DeviceType = consts.DeviceTypeVfioPci
+			driverStateMap := genericPlugin.(*GenericPlugin).getDriverStateMap()
+			Expect(needDriverCheckDeviceType(state, driverStateMap[Vfio])).To(BeFalse())
+		})
+
+		It("should ignore the override if it is not allowed", func() {
+			vars.DriverOverrideAllowed = false
+			driverStateMap := genericPlugin.(*GenericPlugin).getDriverStateMap()
+			Expect(needDriverCheckDeviceType(state, driverStateMap[Vfio])).To(BeFalse())
+		})
+
+		It("should ignore an invalid annotation", func() {
+			state.Annotations[consts.NodeStateDriverOverridesAnnotation] = "{"
+			driverStateMap := genericPlugin.(*GenericPlugin).getDriverStateMap()
+			Expect(needDriverCheckDeviceType(state, driverStateMap[Vfio])).To(BeFalse())
+		})
+	})
+
+	Context("drain strategy", func() {
+		var (
+			desired *sriovnetworkv1.Interface
+			current *sriovnetworkv1.InterfaceExt
+		)
+
+		BeforeEach(func() {
+			desired = &sriovnetworkv1.Interface{
+				PciAddress: "0000:00:00.0",
+				NumVfs:     2,
+				Mtu:        9000,
+				VfGroups: []sriovnetworkv1.VfGroup{{
+					DeviceType: consts.DeviceTypeNetDevice,
+					VfRange:    "0-1",
+				}},
+			}
+			current = &sriovnetworkv1.InterfaceExt{
+				PciAddress: "0000:00:00.0",
+				NumVfs:     2,
+				Mtu:        1500,
+				VFs: []sriovnetworkv1.VirtualFunction{
+					{VfID: 0, Driver: "iavf"},
+					{VfID: 1, Driver: "iavf"},
+				},
+			}
+		})
+
+		It("should drain on any change with the conservative strategy", func() {
+			Expect(ConservativeDrainStrategy{}.NeedDrain(desired, current)).To(BeTrue())
+		})
+
+		It("should not drain on MTU changes or more VFs with the optimistic strategy", func() {
+			Expect(OptimisticDrainStrategy{}.NeedDrain(desired, current)).To(BeFalse())
+			desired.NumVfs = 4
+			desired.VfGroups[0].VfRange = "0-3"
+			Expect(OptimisticDrainStrategy{}.NeedDrain(desired, current)).To(BeFalse())
+		})
+
+		It("should drain if VFs are removed with the optimistic strategy", func() {
+			desired.NumVfs = 1
+			desired.VfGroups[0].VfRange = "0-0"
+			Expect(OptimisticDrainStrategy{}.NeedDrain(desired, current)).To(BeTrue())
+		})
+
+		It("should drain if the driver of VFs changes with the optimistic strategy", func() {
+			desired.VfGroups[0].DeviceType = consts.DeviceTypeVfioPci
+			Expect(OptimisticDrainStrategy{}.NeedDrain(desired, current)).To(BeTrue())
+			desired.VfGroups[0].DeviceType = consts.DeviceTypeNetDevice
+			current.VFs[1].Driver = "vfio-pci"
+			Expect(OptimisticDrainStrategy{}.NeedDrain(desired, current)).To(BeTrue())
+		})
+
+		It("should use the drain strategy of the option", func() {
+			p, err := NewGenericPlugin(hostHelper, WithDrainStrategy(OptimisticDrainStrategy{}))
+			Expect(err).NotTo(HaveOccurred())
+			spec := sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{*desired}}
+			status := sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{*current}}
+			hostHelper.EXPECT().IsPartOfBond("0000:00:00.0").Return(false, "", nil)
+			Expect(p.(*GenericPlugin).needDrainNode(context.Background(), spec, status)).To(BeFalse())
+			Expect(genericPlugin.(*GenericPlugin).needDrainNode(context.Background(), spec, status)).To(BeTrue())
+		})
+
+		It("should drain if a PF enslaved to a bond needs to be reconfigured", func() {
+			p, err := NewGenericPlugin(hostHelper, WithDrainStrategy(OptimisticDrainStrategy{}))
+			Expect(err).NotTo(HaveOccurred())
+			spec := sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{*desired}}
+			status := sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{*current}}
+			hostHelper.EXPECT().IsPartOfBond("0000:00:00.0").Return(true, "team0", nil)
+			Expect(p.(*GenericPlugin).needDrainNode(context.Background(), spec, status)).To(BeTrue())
+
+			// no change of the PF
+			current.Mtu = 9000
+			status = sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{*current}}
+			Expect(p.(*GenericPlugin).needDrainNode(context.Background(), spec, status)).To(BeFalse())
+		})
+
+		It("should not check the bonds with the IgnoreBondedInterfaces option", func() {
+			p, err := NewGenericPlugin(hostHelper, WithDrainStrategy(OptimisticDrainStrategy{}), WithIgnoreBondedInterfaces())
+			Expect(err).NotTo(HaveOccurred())
+			spec := sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: sriovnetworkv1.Interfaces{*desired}}
+			status := sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: sriovnetworkv1.InterfaceExts{*current}}
+			Expect(p.(*GenericPlugin).needDrainNode(context.Background(), spec, status)).To(BeFalse())
+		})
+	})
+
+	Context("VF BAR allocation failures", func() {
+		var (
+			concretePlugin *GenericPlugin
+			barErr         error
+		)
+
+		BeforeEach(func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			barErr = &hostTypes.InterfaceSyncError{PciAddress: "0000:00:00.0", Err: &hostTypes.VFBarAllocationError{
+				PciAddress: "0000:00:00.0",
+				NumVfs:     8,
+				Bars:       []hostTypes.VFBar{{Index: 0, Size: 0x800000}},
+				Err:        syscall.ENOMEM,
+			}}
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(barErr).AnyTimes()
+			hostHelper.EXPECT().GetCurrentKernelArgs().Return("BOOT_IMAGE=/vmlinuz", nil).AnyTimes()
+		})
+
+		It("should queue pci=realloc once", func() {
+			hostHelper.EXPECT().IsKernelArgsSet(gomock.Any(), consts.KernelArgPciRealloc).Return(false).Times(2)
+			err := concretePlugin.Apply()
+			Expect(err).To(MatchError(ContainSubstring("failed to allocate the MMIO space of 8 VFs (VF BARs: BAR0 size 0x800000 unassigned)")))
+			Expect(errors.Is(err, syscall.ENOMEM)).To(BeTrue())
+			Expect(concretePlugin.DesiredKernelArgs).To(Equal(map[string]bool{consts.KernelArgPciRealloc: false}))
+
+			concretePlugin.DesiredKernelArgs[consts.KernelArgPciRealloc] = true
+			Expect(concretePlugin.Apply()).To(HaveOccurred())
+			Expect(concretePlugin.DesiredKernelArgs).To(Equal(map[string]bool{consts.KernelArgPciRealloc: true}))
+		})
+
+		It("should return a terminal error if pci=realloc is already set", func() {
+			hostHelper.EXPECT().IsKernelArgsSet(gomock.Any(), consts.KernelArgPciRealloc).Return(true)
+			err := concretePlugin.Apply()
+			mmioErr := &MMIOSpaceExhaustedError{}
+			Expect(errors.As(err, &mmioErr)).To(BeTrue())
+			Expect(mmioErr.Allocations).To(HaveLen(1))
+			Expect(err).To(MatchError(ContainSubstring("enable SR-IOV, ARI and \"Above 4G Decoding\" in the BIOS")))
+			Expect(concretePlugin.DesiredKernelArgs).To(BeEmpty())
+		})
+	})
+
+	Context("event batching", func() {
+		var sender *fakeEventSender
+
+		BeforeEach(func() {
+			sender = &fakeEventSender{}
+		})
+
+		It("should send one event per reason with the count of the batched events", func() {
+			batcher := NewEventBatcher(sender, 50*time.Millisecond)
+			batcher.Record(EventReasonDriverLoaded, "Kernel driver vfio_pci has been loaded")
+			batcher.Record(EventReasonKernelArgsUpdated, "Kernel arg intel_iommu=on has been added to the bootloader configuration")
+			batcher.Record(EventReasonDriverLoaded, "Kernel driver vhost_vdpa has been loaded")
+			batcher.Record(EventReasonDriverLoaded, "Kernel driver vhost_vdpa has been loaded")
+			Expect(sender.sent()).To(BeEmpty())
+			Eventually(sender.sent).Should(Equal([]string{
+				EventReasonDriverLoaded + ": Kernel driver vfio_pci has been loaded; Kernel driver vhost_vdpa has been loaded (3 events)",
+				EventReasonKernelArgsUpdated + ": Kernel arg intel_iommu=on has been added to the bootloader configuration",
+			}))
+		})
+
+		It("should restart the batch window on each event", func() {
+			batcher := NewEventBatcher(sender, 200*time.Millisecond)
+			batcher.Record(EventReasonDriverLoaded, "Kernel driver vfio_pci has been loaded")
+			time.Sleep(150 * time.Millisecond)
+			batcher.Record(EventReasonDriverLoaded, "Kernel driver vhost_vdpa has been loaded")
+			time.Sleep(100 * time.Millisecond)
+			Expect(sender.sent()).To(BeEmpty())
+			Eventually(sender.sent).Should(HaveLen(1))
+		})
+
+		It("should send the events immediately if the batch window is not positive", func() {
+			batcher := NewEventBatcher(sender, 0)
+			batcher.Record(EventReasonDriverLoaded, "Kernel driver vfio_pci has been loaded")
+			Expect(sender.sent()).To(Equal([]string{EventReasonDriverLoaded + ": Kernel driver vfio_pci has been loaded"}))
+		})
+
+		It("should send an event when a driver is loaded", func() {
+			p, err := NewGenericPlugin(hostHelper, WithEventSender(sender), WithEventBatchWindow(time.Millisecond))
+			Expect(err).NotTo(HaveOccurred())
+			genericPlugin := p.(*GenericPlugin)
+			DeferCleanup(genericPlugin.StopWatchdog)
+			Expect(genericPlugin.EventBatchWindow).To(Equal(time.Millisecond))
+			genericPlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     1,
+						VfGroups:   []sriovnetworkv1.VfGroup{{DeviceType: consts.DeviceTypeVfioPci, VfRange: "0-0"}},
+					}},
+				},
+			}
+			hostHelper.EXPECT().IsKernelModuleLoaded(vfioPciDriver).Return(false, nil)
+			hostHelper.EXPECT().LoadKernelModule(vfioPciDriver).Return(nil)
+			Expect(genericPlugin.syncDriverState(context.Background())).To(Succeed())
+			Eventually(sender.sent).Should(Equal([]string{EventReasonDriverLoaded + ": Kernel driver vfio_pci has been loaded"}))
+		})
+	})
+
+	Context("error types", func() {
+		var concretePlugin *GenericPlugin
+
+		BeforeEach(func() {
+			concretePlugin = genericPlugin.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{{
+						PciAddress: "0000:00:00.0",
+						NumVfs:     1,
+						VfGroups: []sriovnetworkv1.VfGroup{{
+							DeviceType: consts.DeviceTypeVfioPci,
+							VfRange:    "0-0",
+						}},
+					}},
+				},
+			}
+		})
+
+		It("should return DriverLoadError if the driver can't be loaded", func() {
+			hostHelper.EXPECT().IsKernelModuleLoaded(vfioPciDriver).Return(false, nil)
+			hostHelper.EXPECT().LoadKernelModule(vfioPciDriver).Return(fmt.Errorf("test"))
+			err := concretePlugin.Apply()
+			var driverErr *DriverLoadError
+			Expect(errors.As(err, &driverErr)).To(BeTrue())
+			Expect(driverErr.DriverName).To(Equal(vfioPciDriver))
+			Expect(driverErr.Underlying).To(MatchError("test"))
+		})
+
+		It("should record the drivers which were already loaded on the host", func() {
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+				Interfaces: sriovnetworkv1.Interfaces{{PciAddress: "0000:00:00.0", NumVfs: 1,
+					VfGroups: []sriovnetworkv1.VfGroup{{VfRange: "0-0", DeviceType: consts.DeviceTypeVfioPci}}}}}}
+			hostHelper.EXPECT().IsKernelModuleLoaded(vfioPciDriver).Return(true, nil)
+			hostHelper.EXPECT().WriteModulesLoadConf(consts.ModulesLoadConfFile, []string{vfioPciDriver}).Return(nil)
+			Expect(concretePlugin.syncDriverState(context.Background())).To(Succeed())
+			Expect(concretePlugin.DriverStateMap[Vfio].DriverLoaded).To(BeTrue())
+			Expect(concretePlugin.DriverStateMap[Vfio].LoadedByPlugin).To(BeFalse())
+		})
+
+		It("should return ChrootError if the host mount is not valid", func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = false
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			concretePlugin.hostMountPath = "/missing-host-mount"
+			err := concretePlugin.Apply()
+			var chrootErr *ChrootError
+			Expect(errors.As(err, &chrootErr)).To(BeTrue())
+			Expect(chrootErr.Path).To(Equal("/missing-host-mount"))
+		})
+
+		It("should return SyncNodeStateError with the address of the failed PF", func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(
+				errors.Join(&hostTypes.InterfaceSyncError{PciAddress: "0000:00:00.0", Err: fmt.Errorf("test")}))
+			err := concretePlugin.Apply()
+			var syncErr *SyncNodeStateError
+			Expect(errors.As(err, &syncErr)).To(BeTrue())
+			Expect(syncErr.PciAddress).To(Equal("0000:00:00.0"))
+			Expect(hostTypes.GetInterfaceSyncErrors(err)).To(HaveLen(1))
+		})
+
+		It("should return KernelParamError with the number of attempts", func() {
+			origSetKernelArg := setKernelArg
+			DeferCleanup(func() { setKernelArg = origSetKernelArg })
+			setKernelArg = func(_, _ string) (bool, error) { return false, fmt.Errorf("test") }
+			hostHelper.EXPECT().GetKernelArgsBackend().Return("grubby", nil).Times(2)
+
+			_, err := concretePlugin.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).To(HaveOccurred())
+			_, err = concretePlugin.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			var kernelParamErr *KernelParamError
+			Expect(errors.As(err, &kernelParamErr)).To(BeTrue())
+			Expect(kernelParamErr.Param).To(Equal(consts.KernelArgIntelIommu))
+			Expect(kernelParamErr.Attempts).To(Equal(2))
+			Expect(kernelParamErr.Underlying).To(MatchError("test"))
+		})
+
+		It("should give up setting a kernel arg after MaxKernelParamAttempts", func() {
+			origSetKernelArg := setKernelArg
+			DeferCleanup(func() { setKernelArg = origSetKernelArg })
+			setKernelArg = func(_, _ string) (bool, error) { return false, fmt.Errorf("test") }
+			hostHelper.EXPECT().GetKernelArgsBackend().Return("grubby", nil).Times(2)
+			concretePlugin.MaxKernelParamAttempts = 2
+
+			for i := 0; i < 2; i++ {
+				_, err := concretePlugin.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+				Expect(err).To(MatchError(ContainSubstring("test")))
+			}
+			// the kernel arg is not set again
+			_, err := concretePlugin.syncDesiredKernelArgs(context.Background(), []string{consts.KernelArgIntelIommu})
+			Expect(err).To(MatchError(ErrKernelParamAttemptsExceeded))
+			Expect(concretePlugin.KernelArgAttempts[consts.KernelArgIntelIommu]).To(Equal(2))
+		})
+	})
+
+	Context("interface reconcile status", func() {
+		var (
+			concretePlugin *GenericPlugin
+			configured     [][]string
+			failPFs        map[string]bool
+		)
+
+		BeforeEach(func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			p, err := NewGenericPlugin(hostHelper, WithWatchdogInterval(0), WithSuccessfulReconcileSkipDuration(time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+			concretePlugin = p.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{
+				Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+					Interfaces: sriovnetworkv1.Interfaces{
+						{PciAddress: "0000:00:00.0", NumVfs: 1},
+						{PciAddress: "0000:00:00.1", NumVfs: 1},
+					},
+				},
+				Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+					Interfaces: sriovnetworkv1.InterfaceExts{
+						{PciAddress: "0000:00:00.0", Driver: "ice", TotalVfs: 4},
+						{PciAddress: "0000:00:00.1", Driver: "ice", TotalVfs: 4},
+					},
+				},
+			}
+			configured = nil
+			failPFs = map[string]bool{}
+			hostHelper.EXPECT().ConfigureModprobeBlacklist(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			hostHelper.EXPECT().ConfigureBridges(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			hostHelper.EXPECT().ConfigSriovInterfaces(gomock.Any(), gomock.Any(), gomock.Any(), false).DoAndReturn(
+				func(_ interface{}, interfaces []sriovnetworkv1.Interface, _ []sriovnetworkv1.InterfaceExt, _ bool) error {
+					var errs []error
+					addresses := []string{}
+					for _, iface := range interfaces {
+						addresses = append(addresses, iface.PciAddress)
+						if failPFs[iface.PciAddress] {
+							errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: iface.PciAddress, Err: fmt.Errorf("test")})
+						}
+					}
+					configured = append(configured, addresses)
+					return errors.Join(errs...)
+				}).AnyTimes()
+		})
+
+		It("should retry only the failed PFs", func() {
+			failPFs["0000:00:00.1"] = true
+			Expect(concretePlugin.Apply()).To(HaveOccurred())
+			Expect(concretePlugin.Apply()).To(HaveOccurred())
+			Expect(configured).To(Equal([][]string{
+				{"0000:00:00.0", "0000:00:00.1"},
+				{"0000:00:00.1"},
+			}))
+
+			status := concretePlugin.Status().InterfaceReconcileStatus
+			Expect(status["0000:00:00.0"].LastError).ToNot(HaveOccurred())
+			Expect(status["0000:00:00.0"].LastSuccess).ToNot(BeZero())
+			Expect(status["0000:00:00.1"].LastError).To(MatchError("test"))
+			Expect(status["0000:00:00.1"].Attempts).To(Equal(2))
+
+			delete(failPFs, "0000:00:00.1")
+			Expect(concretePlugin.Apply()).To(Succeed())
+			status = concretePlugin.Status().InterfaceReconcileStatus
+			Expect(status["0000:00:00.1"].LastError).ToNot(HaveOccurred())
+			Expect(status["0000:00:00.1"].Attempts).To(BeZero())
+		})
+
+		It("should configure the recently configured PFs again on force apply", func() {
+			Expect(concretePlugin.Apply()).To(Succeed())
+			Expect(concretePlugin.Apply()).To(Succeed())
+			Expect(concretePlugin.ForceApply()).To(Succeed())
+			Expect(configured).To(Equal([][]string{
+				{"0000:00:00.0", "0000:00:00.1"},
+				{},
+				{"0000:00:00.0", "0000:00:00.1"},
+			}))
+		})
+
+		It("should keep the status of the PFs which were not configured again if force apply fails", func() {
+			Expect(concretePlugin.Apply()).To(Succeed())
+			lastSuccess := concretePlugin.Status().InterfaceReconcileStatus["0000:00:00.0"].LastSuccess
+			hostHelper.EXPECT().SetHugepages(-1, "1Gi", 1).Return(0, fmt.Errorf("test"))
+			concretePlugin.DesireState.Spec.Interfaces[1].Hugepages = &sriovnetworkv1.Hugepages{Size: "1Gi", Count: 1}
+			Expect(concretePlugin.ForceApply()).To(HaveOccurred())
+			Expect(concretePlugin.Status().InterfaceReconcileStatus["0000:00:00.0"].LastSuccess).To(Equal(lastSuccess))
+		})
+
+		It("should configure the PF again if its spec changed", func() {
+			Expect(concretePlugin.Apply()).To(Succeed())
+			concretePlugin.DesireState.Spec.Interfaces[0].NumVfs = 2
+			Expect(concretePlugin.Apply()).To(Succeed())
+			Expect(configured).To(Equal([][]string{
+				{"0000:00:00.0", "0000:00:00.1"},
+				{"0000:00:00.0"},
+			}))
+		})
+
+		It("should fail all the PFs if the error is not related to a PF", func() {
+			concretePlugin.updateReconcileStatus(concretePlugin.DesireState.Spec.Interfaces, fmt.Errorf("test"))
+			status := concretePlugin.Status().InterfaceReconcileStatus
+			Expect(status).To(HaveLen(2))
+			Expect(status["0000:00:00.0"].LastError).To(MatchError("test"))
+			Expect(status["0000:00:00.1"].LastError).To(MatchError("test"))
+		})
+
+		It("should remove the PFs which are no longer desired", func() {
+			Expect(concretePlugin.Apply()).To(Succeed())
+			concretePlugin.DesireState.Spec.Interfaces = concretePlugin.DesireState.Spec.Interfaces[:1]
+			Expect(concretePlugin.Apply()).To(Succeed())
+			Expect(concretePlugin.Status().InterfaceReconcileStatus).To(HaveLen(1))
+		})
+
+		It("should apply the PFs independently with ApplyPartial", func() {
+			failPFs["0000:00:00.1"] = true
+			results, err := concretePlugin.ApplyPartial([]string{"0000:00:00.0", "0000:00:00.1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results["0000:00:00.0"]).ToNot(HaveOccurred())
+			Expect(results["0000:00:00.1"]).To(MatchError("test"))
+			Expect(configured).To(Equal([][]string{{"0000:00:00.0"}, {"0000:00:00.1"}}))
+
+			status := concretePlugin.Status().InterfaceReconcileStatus
+			Expect(status["0000:00:00.0"].LastSuccess).ToNot(BeZero())
+			Expect(status["0000:00:00.1"].LastError).To(MatchError("test"))
+			Expect(status["0000:00:00.1"].Attempts).To(Equal(1))
+
+			// the PF applied by ApplyPartial is not configured again
+			delete(failPFs, "0000:00:00.1")
+			Expect(concretePlugin.Apply()).To(Succeed())
+			Expect(configured[2]).To(Equal([]string{"0000:00:00.1"}))
+		})
+
+		It("should fail ApplyPartial only if all the PFs failed", func() {
+			failPFs["0000:00:00.0"] = true
+			results, err := concretePlugin.ApplyPartial([]string{"0000:00:00.0", "0000:00:09.0"})
+			Expect(err).To(HaveOccurred())
+			Expect(results["0000:00:00.0"]).To(MatchError("test"))
+			Expect(results["0000:00:09.0"]).To(MatchError(ContainSubstring("not in the desired state")))
+			Expect(configured).To(Equal([][]string{{"0000:00:00.0"}}))
+
+			delete(failPFs, "0000:00:00.0")
+			results, err = concretePlugin.ApplyPartial([]string{"0000:00:00.0", "0000:00:09.0"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results["0000:00:00.0"]).ToNot(HaveOccurred())
+		})
+
+		It("should not apply the skipped PFs with ApplyPartial", func() {
+			concretePlugin.SkipPCIAddresses = map[string]string{"0000:00:00.1": "maintenance"}
+			results, err := concretePlugin.ApplyPartial([]string{"0000:00:00.1"})
+			Expect(err).To(HaveOccurred())
+			Expect(results["0000:00:00.1"]).To(MatchError(ContainSubstring("maintenance")))
+			Expect(configured).To(BeEmpty())
+		})
+	})
+
+	Context("partial failures", func() {
+		var fakeHost *hosttesting.FakeHostManager
+
+		newPluginWithFakeHost := func(failAfterNth map[string]int) *GenericPlugin {
+			fakeHost = hosttesting.NewFakeHostManager(failAfterNth)
+			p, err := NewGenericPlugin(helper.NewHostHelpers(nil, fakeHost, nil, nil))
+			Expect(err).ToNot(HaveOccurred())
+			concretePlugin := p.(*GenericPlugin)
+			concretePlugin.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+			return concretePlugin
+		}
+
+		BeforeEach(func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+		})
+
+		DescribeTable("should stop on the first failing host call",
+			func(method string) {
+				p := newPluginWithFakeHost(map[string]int{method: 0})
+				Expect(p.Apply()).To(MatchError(hosttesting.ErrInjected))
+				Expect(fakeHost.CallLog).NotTo(BeEmpty())
+				lastCall := fakeHost.CallLog[len(fakeHost.CallLog)-1]
+				Expect(lastCall.Method).To(Equal(method))
+				Expect(lastCall.Returns).To(Equal([]interface{}{hosttesting.ErrInjected}))
+			},
+			Entry("configure interfaces", "ConfigSriovInterfaces"),
+			Entry("configure modprobe", "ConfigureModprobeBlacklist"),
+			Entry("configure bridges", "ConfigureBridges"),
+		)
+
+		It("should succeed until the host call starts to fail", func() {
+			for n := 0; n < 4; n++ {
+				p := newPluginWithFakeHost(map[string]int{"ConfigSriovInterfaces": n})
+				for i := 0; i < n; i++ {
+					Expect(p.Apply()).NotTo(HaveOccurred())
+				}
+				Expect(p.Apply()).To(MatchError(hosttesting.ErrInjected))
+				Expect(fakeHost.Calls("ConfigSriovInterfaces")).To(HaveLen(n + 1))
+				// nothing is configured after the failure
+				Expect(fakeHost.Calls("ConfigureModprobeBlacklist")).To(HaveLen(n))
+			}
+		})
+
+		It("should return the configured error", func() {
+			p := newPluginWithFakeHost(map[string]int{"ConfigSriovInterfaces": 0})
+			fakeHost.Err = fmt.Errorf("cannot allocate memory")
+			Expect(p.Apply()).To(MatchError("failed to sync node state: cannot allocate memory"))
+		})
+	})
+
+	Context("metrics", func() {
+		var p *GenericPlugin
+
+		scrape := func() string {
+			recorder := httptest.NewRecorder()
+			p.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			return recorder.Body.String()
+		}
+
+		BeforeEach(func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			vendorPlugin, err := NewGenericPlugin(helper.NewHostHelpers(nil,
+				hosttesting.NewFakeHostManager(map[string]int{"ConfigSriovInterfaces": 1}), nil, nil))
+			Expect(err).ToNot(HaveOccurred())
+			p = vendorPlugin.(*GenericPlugin)
+			p.DesireState = &sriovnetworkv1.SriovNetworkNodeState{}
+		})
+
+		It("should report the applies by result", func() {
+			Expect(scrape()).To(And(
+				ContainSubstring(`sriov_generic_plugin_applies_total{result="succeeded"} 0`),
+				ContainSubstring(`sriov_generic_plugin_applies_total{result="failed"} 0`)))
+
+			Expect(p.Apply()).To(Succeed())
+			Expect(p.Apply()).To(MatchError(hosttesting.ErrInjected))
+			// the applies skipped while paused are not reported
+			Expect(p.Pause()).To(Succeed())
+			Expect(p.Apply()).To(MatchError(plugin.ErrPluginPaused))
+
+			metrics := scrape()
+			Expect(metrics).To(ContainSubstring(`sriov_generic_plugin_applies_total{result="succeeded"} 1`))
+			Expect(metrics).To(ContainSubstring(`sriov_generic_plugin_applies_total{result="failed"} 1`))
+			Expect(metrics).To(ContainSubstring("sriov_generic_plugin_apply_duration_seconds_count 2"))
+		})
+
+		It("should report the drain and reboot requests", func() {
+			p.metrics.observeNodeStateChange(true, false)
+			p.metrics.observeNodeStateChange(true, true)
+			_, _, err := p.OnNodeStateChange(&sriovnetworkv1.SriovNetworkNodeState{})
+			Expect(err).ToNot(HaveOccurred())
+
+			metrics := scrape()
+			Expect(metrics).To(ContainSubstring("sriov_generic_plugin_drain_requests_total 2"))
+			Expect(metrics).To(ContainSubstring("sriov_generic_plugin_reboot_requests_total 1"))
+		})
+
+		It("should only serve the metrics of the plugin", func() {
+			Expect(scrape()).ToNot(ContainSubstring("go_goroutines"))
+		})
+	})
+
+	Context("Cleanup", func() {
+		var (
+			concretePlugin *GenericPlugin
+			discovered     []sriovnetworkv1.InterfaceExt
+		)
+
+		BeforeEach(func() {
+			origUsingSystemdMode := vars.UsingSystemdMode
+			DeferCleanup(func() { vars.UsingSystemdMode = origUsingSystemdMode })
+			vars.UsingSystemdMode = true
+
+			p, err := NewGenericPlugin(hostHelper, WithWatchdogInterval(0), WithPersistDriverLoad())
+			Expect(err).ToNot(HaveOccurred())
+			concretePlugin = p.(*GenericPlugin)
+			concretePlugin.InterfaceReconcileStatus["0000:00:00.0"] = ReconcileStatus{LastSuccess: time.Now()}
+			discovered = []sriovnetworkv1.InterfaceExt{
+				{PciAddress: "0000:00:00.0", Driver: "ice", NumVfs: 4},
+				{PciAddress: "0000:00:00.1", Driver: "ice", NumVfs: 2},
+			}
+			hostHelper.EXPECT().DiscoverSriovDevices(hostHelper).Return(discovered, nil)
+		})
+
+		It("should reset the PFs and unload the drivers loaded by the plugin", func() {
+			for _, id := range []uint{Vfio, QatCommon, QatDevice} {
+				concretePlugin.DriverStateMap[id].DriverLoaded = true
+				concretePlugin.DriverStateMap[id].LoadedByPlugin = true
+			}
+			// loaded on the host before the plugin
+			concretePlugin.DriverStateMap[Ena].DriverLoaded = true
+			gomock.InOrder(
+				hostHelper.EXPECT().ConfigSriovInterfaces(hostHelper, nil, discovered, false).Return(nil),
+				hostHelper.EXPECT().UnloadKernelModule(qatDeviceDriver).Return(nil),
+				hostHelper.EXPECT().UnloadKernelModule(qatCommonDriver).Return(nil),
+				hostHelper.EXPECT().UnloadKernelModule(vfioPciDriver).Return(fmt.Errorf("module vfio_pci is in use")),
+				hostHelper.EXPECT().WriteModulesLoadConf(consts.ModulesLoadConfFile, nil).Return(nil),
+			)
+			Expect(concretePlugin.Cleanup()).To(Succeed())
+			Expect(concretePlugin.DriverStateMap[QatDevice].DriverLoaded).To(BeFalse())
+			Expect(concretePlugin.DriverStateMap[QatCommon].DriverLoaded).To(BeFalse())
+			// kept loaded if it is in use
+			Expect(concretePlugin.DriverStateMap[Vfio].DriverLoaded).To(BeTrue())
+			Expect(concretePlugin.DriverStateMap[Ena].DriverLoaded).To(BeTrue())
+			Expect(concretePlugin.InterfaceReconcileStatus).To(BeEmpty())
+		})
+
+		It("should not reset the skipped devices", func() {
+			concretePlugin.SkipPCIAddresses = map[string]string{"0000:00:00.1": "managed by SmartNIC firmware"}
+			hostHelper.EXPECT().ConfigSriovInterfaces(hostHelper, nil, discovered[:1], false).Return(nil)
+			hostHelper.EXPECT().WriteModulesLoadConf(consts.ModulesLoadConfFile, nil).Return(nil)
+			Expect(concretePlugin.Cleanup()).To(Succeed())
+		})
+
+		It("should not unload the drivers if the PFs are not reset", func() {
+			concretePlugin.DriverStateMap[Vfio].DriverLoaded = true
+			concretePlugin.DriverStateMap[Vfio].LoadedByPlugin = true
+			hostHelper.EXPECT().ConfigSriovInterfaces(hostHelper, nil, gomock.Any(), false).Return(fmt.Errorf("test"))
+			Expect(concretePlugin.Cleanup()).To(MatchError(ContainSubstring("test")))
+			Expect(concretePlugin.DriverStateMap[Vfio].DriverLoaded).To(BeTrue())
+			Expect(concretePlugin.InterfaceReconcileStatus).To(HaveKey("0000:00:00.0"))
+		})
+	})
+})
+
+type fakePFSkipper struct {
+	vendorID string
+	skip     map[string]string
+	err      error
+	checked  []string
+}
+
+func (f *fakePFSkipper) VendorID() string {
+	return f.vendorID
+}
+
+func (f *fakePFSkipper) ShouldSkipPF(_ *sriovnetworkv1.SriovNetworkNodeState, iface *sriovnetworkv1.InterfaceExt) (bool, string, error) {
+	f.checked = append(f.checked, iface.PciAddress)
+	reason, skip := f.skip[iface.PciAddress]
+	return skip, reason, f.err
+}
+
+type fakeTotalVfsRaiser struct {
+	vendorID string
+	canRaise bool
+}
+
+func (f *fakeTotalVfsRaiser) VendorID() string {
+	return f.vendorID
+}
+
+func (f *fakeTotalVfsRaiser) CanRaiseTotalVfs(*sriovnetworkv1.InterfaceExt) bool {
+	return f.canRaise
+}
+
+// fakeEventSender records the events sent by the plugin as "<reason>: <message>"
+type fakeEventSender struct {
+	lock   sync.Mutex
+	events []string
+}
+
+func (f *fakeEventSender) SendEvent(reason string, msg string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.events = append(f.events, reason+": "+msg)
+}
+
+func (f *fakeEventSender) sent() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]string{}, f.events...)
+}
+
+type contextRecordingKey struct{}
+
+// contextRecordingHostManager records the context of the calls of ConfigSriovInterfaces
+type contextRecordingHostManager struct {
+	host.HostManagerV2Interface
+	contexts []context.Context
+}
+
+func (h *contextRecordingHostManager) ConfigSriovInterfaces(ctx context.Context, storeManager store.ManagerInterface,
+	interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt, skipVFConfiguration bool) error {
+	h.contexts = append(h.contexts, ctx)
+	return h.HostManagerV2Interface.ConfigSriovInterfaces(ctx, storeManager, interfaces, ifaceStatuses, skipVFConfiguration)
+}
+
+// fakeKernelParamManager records the kernel params set by the plugin, no kernel param is set for the running kernel
+type fakeKernelParamManager struct {
+	set map[string]bool
+}
+
+func (m *fakeKernelParamManager) Set(param string) (bool, error) {
+	m.set[param] = true
+	return true, nil
+}
+
+func (m *fakeKernelParamManager) IsSet(string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,205 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// GhostVF is a VF which exists in the kernel but is neither requested by the desired state nor recorded
+// in the last configuration applied to its PF, e.g. a VF created by a previous run of the daemon which
+// crashed before the configuration of the PF was saved
+type GhostVF struct {
+	// PfPciAddress is the PCI address of the PF of the VF
+	PfPciAddress string
+	// PciAddress is the PCI address of the VF
+	PciAddress string
+	// VfID is the index of the VF on the PF
+	VfID int
+	// Driver is the driver the VF is bound to, empty if the VF is not bound
+	Driver string
+}
+
+// existingVF is a VF found in sysfs
+type existingVF struct {
+	pciAddress string
+	vfID       int
+}
+
+// DetectGhostVFs enumerates the VFs of the node from the virtfn links of the PFs in sysfs and returns the VFs
+// which are not reflected in the desired state. Only the PFs configured by the operator are checked: the PFs
+// in the spec which are not externally managed and the PFs with a saved applied configuration. The VFs within
+// the number of VFs of the spec or of the last applied configuration are not ghosts, the VFs removed by a spec
+// change are removed by the configuration of the PF.
+func (p *GenericPlugin) DetectGhostVFs(desired *sriovnetworkv1.SriovNetworkNodeState) ([]GhostVF, error) {
+	if vars.NetdevsimMode {
+		// the netdevsim devices are not PCI devices
+		return nil, nil
+	}
+	pfVFs, err := listExistingVFs()
+	if err != nil {
+		return nil, err
+	}
+	pfAddresses := make([]string, 0, len(pfVFs))
+	for pfAddress := range pfVFs {
+		pfAddresses = append(pfAddresses, pfAddress)
+	}
+	slices.Sort(pfAddresses)
+	skipped := p.skippedDevices()
+	var ghosts []GhostVF
+	for _, pfAddress := range pfAddresses {
+		if _, ok := skipped[pfAddress]; ok {
+			continue
+		}
+		knownVfs, managed := p.knownNumVfs(desired, pfAddress)
+		if !managed {
+			continue
+		}
+		for _, vf := range pfVFs[pfAddress] {
+			if vf.vfID < knownVfs {
+				continue
+			}
+			ghosts = append(ghosts, GhostVF{
+				PfPciAddress: pfAddress,
+				PciAddress:   vf.pciAddress,
+				VfID:         vf.vfID,
+				Driver:       readDriverName(vf.pciAddress),
+			})
+		}
+	}
+	return ghosts, nil
+}
+
+// RemediateGhostVFs unbinds the ghost VFs from their drivers and removes them. The kernel can only remove
+// all the VFs of a PF at once, the VFs requested by the desired state are created again by the configuration
+// of the PF which follows. The errors are aggregated as InterfaceSyncErrors of the PFs.
+func (p *GenericPlugin) RemediateGhostVFs(ctx context.Context, ghosts []GhostVF) error {
+	var errs []error
+	var pfAddresses []string
+	failed := map[string]bool{}
+	for _, ghost := range ghosts {
+		if !slices.Contains(pfAddresses, ghost.PfPciAddress) {
+			pfAddresses = append(pfAddresses, ghost.PfPciAddress)
+		}
+		if ghost.Driver == "" || failed[ghost.PfPciAddress] {
+			continue
+		}
+		log.Log.Info("generic plugin RemediateGhostVFs(): unbind ghost VF", "device", ghost.PciAddress,
+			"driver", ghost.Driver)
+		if err := p.hostManager.Unbind(ctx, ghost.PciAddress); err != nil {
+			failed[ghost.PfPciAddress] = true
+			errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: ghost.PfPciAddress,
+				Err: fmt.Errorf("failed to unbind ghost VF %s: %v", ghost.PciAddress, err)})
+		}
+	}
+	for _, pfAddress := range pfAddresses {
+		if failed[pfAddress] {
+			continue
+		}
+		log.Log.Info("generic plugin RemediateGhostVFs(): remove the VFs of the PF", "device", pfAddress)
+		if err := p.hostManager.SetSriovNumVfs(ctx, pfAddress, 0); err != nil {
+			errs = append(errs, &hostTypes.InterfaceSyncError{PciAddress: pfAddress,
+				Err: fmt.Errorf("failed to remove ghost VFs: %v", err)})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncGhostVFs detects the ghost VFs left by the previous runs of the daemon, they are removed only
+// if the remediation is enabled, the VFs are reported otherwise
+func (p *GenericPlugin) syncGhostVFs(ctx context.Context) error {
+	ghosts, err := p.DetectGhostVFs(p.DesireState)
+	if err != nil {
+		return err
+	}
+	if len(ghosts) == 0 {
+		return nil
+	}
+	if !p.remediateGhostVFs {
+		for _, ghost := range ghosts {
+			log.Log.Info("generic plugin syncGhostVFs(): found ghost VF, remediation is disabled",
+				"pf", ghost.PfPciAddress, "device", ghost.PciAddress, "vfID", ghost.VfID, "driver", ghost.Driver)
+		}
+		return nil
+	}
+	return p.RemediateGhostVFs(ctx, ghosts)
+}
+
+// knownNumVfs returns the number of VFs of the PF which are expected by the operator, the maximum of the
+// number of VFs in the spec and in the last applied configuration. managed is false if the VFs of the PF
+// are not created by the operator.
+func (p *GenericPlugin) knownNumVfs(desired *sriovnetworkv1.SriovNetworkNodeState, pfAddress string) (int, bool) {
+	numVfs := 0
+	inSpec := false
+	for _, iface := range desired.Spec.Interfaces {
+		if iface.PciAddress != pfAddress {
+			continue
+		}
+		if iface.ExternallyManaged {
+			return 0, false
+		}
+		numVfs = iface.NumVfs
+		inSpec = true
+		break
+	}
+	applied, exist, err := p.helpers.LoadPfsStatus(pfAddress)
+	if err != nil {
+		log.Log.Error(err, "generic plugin knownNumVfs(): failed to load the applied configuration of the PF",
+			"device", pfAddress)
+		return 0, false
+	}
+	if !exist {
+		return numVfs, inSpec
+	}
+	if applied.ExternallyManaged {
+		return 0, false
+	}
+	return max(numVfs, applied.NumVfs), true
+}
+
+// listExistingVFs returns the VFs of each PF found in sysfs by PF PCI address, sorted by VF ID
+func listExistingVFs() (map[string][]existingVF, error) {
+	links, err := filepath.Glob(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, "*", "virtfn*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the VFs: %v", err)
+	}
+	pfVFs := map[string][]existingVF{}
+	for _, link := range links {
+		vfID, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(link), "virtfn"))
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(link)
+		if err != nil {
+			log.Log.Error(err, "listExistingVFs(): failed to read VF link", "link", link)
+			continue
+		}
+		pfAddress := filepath.Base(filepath.Dir(link))
+		pfVFs[pfAddress] = append(pfVFs[pfAddress], existingVF{pciAddress: filepath.Base(target), vfID: vfID})
+	}
+	for _, vfs := range pfVFs {
+		slices.SortFunc(vfs, func(a, b existingVF) int { return a.vfID - b.vfID })
+	}
+	return pfVFs, nil
+}
+
+// readDriverName returns the name of the driver the device is bound to, empty if the device is not bound
+func readDriverName(pciAddress string) string {
+	target, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddress, "driver"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
@@ -0,0 +1,214 @@
+package generic
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/fileutil"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+const (
+	// tunedProfile is the name of the tuned profile which contains the kernel parameters of the plugin
+	tunedProfile = "sriov"
+	// tunedProfilesDir is the directory of the custom tuned profiles on the host
+	tunedProfilesDir = "/etc/tuned"
+	// tunedCmdlineKey is the key of the kernel parameters of the profile in the bootloader section
+	tunedCmdlineKey = "cmdline_sriov"
+	// tunedAdmPath and tunedPidFile are used to detect that tuned manages the host
+	tunedAdmPath = "/usr/sbin/tuned-adm"
+	tunedPidFile = "/run/tuned/tuned.pid"
+)
+
+// KernelParamManager adds the kernel parameters required by the plugin to the boot configuration of the host
+type KernelParamManager interface {
+	// Set adds the kernel parameter to the boot configuration, it returns true if the parameter is not set
+	// for the running kernel and the node must be rebooted to apply it
+	Set(param string) (bool, error)
+	// IsSet returns true if the kernel parameter is set for the running kernel
+	IsSet(param string) (bool, error)
+}
+
+// runningKernelParams checks the kernel parameters of the running kernel
+type runningKernelParams struct {
+	hostManager host.HostManagerV2Interface
+}
+
+// IsSet returns true if the kernel parameter is in the cmdline of the running kernel
+func (r *runningKernelParams) IsSet(param string) (bool, error) {
+	cmdLine, err := r.hostManager.GetCurrentKernelArgs(context.Background())
+	if err != nil {
+		return false, err
+	}
+	return r.hostManager.IsKernelArgsSet(context.Background(), cmdLine, param), nil
+}
+
+// GrubbyKernelParamManager adds the kernel parameters with the kernel args script and the backend
+// detected for the host: rpm-ostree, grubby or update-grub
+type GrubbyKernelParamManager struct {
+	runningKernelParams
+	setter KernelArgSetter
+}
+
+// NewGrubbyKernelParamManager returns KernelParamManager which adds the kernel parameters with the setter,
+// the kernel args script is used if the setter is nil
+func NewGrubbyKernelParamManager(hostManager host.HostManagerV2Interface, setter KernelArgSetter) *GrubbyKernelParamManager {
+	return &GrubbyKernelParamManager{runningKernelParams: runningKernelParams{hostManager: hostManager}, setter: setter}
+}
+
+// Set adds the kernel parameter to the bootloader configuration with the backend of the host
+func (m *GrubbyKernelParamManager) Set(param string) (bool, error) {
+	backend, err := m.hostManager.GetKernelArgsBackend(context.Background())
+	if err != nil {
+		return false, fmt.Errorf("failed to detect kernel args backend: %w", err)
+	}
+	log.Log.V(2).Info("GrubbyKernelParamManager Set(): detected kernel args backend", "backend", backend)
+	if m.setter != nil {
+		return m.setter(param, backend)
+	}
+	return setKernelArg(param, backend)
+}
+
+// TunedKernelParamManager adds the kernel parameters to the bootloader section of the sriov tuned profile,
+// the profile includes the profile which was active before so that its tuning is kept, and activates it
+type TunedKernelParamManager struct {
+	runningKernelParams
+	cmd utils.CmdInterface
+}
+
+// NewTunedKernelParamManager returns KernelParamManager which adds the kernel parameters to the sriov tuned profile
+func NewTunedKernelParamManager(hostManager host.HostManagerV2Interface, cmd utils.CmdInterface) *TunedKernelParamManager {
+	return &TunedKernelParamManager{runningKernelParams: runningKernelParams{hostManager: hostManager}, cmd: cmd}
+}
+
+// Set adds the kernel parameter to the sriov tuned profile and activates the profile,
+// tuned updates the bootloader configuration with the parameters of the profile
+func (m *TunedKernelParamManager) Set(param string) (bool, error) {
+	set, err := m.IsSet(param)
+	if err != nil || set {
+		return false, err
+	}
+	profilePath := utils.GetHostExtensionPath(filepath.Join(tunedProfilesDir, tunedProfile, "tuned.conf"))
+	include, params, err := readTunedProfile(profilePath)
+	if err != nil {
+		return false, err
+	}
+	active, err := m.activeProfile()
+	if err != nil {
+		return false, err
+	}
+	if slices.Contains(params, param) && active == tunedProfile {
+		log.Log.V(2).Info("TunedKernelParamManager Set(): kernel param already in the tuned profile", "param", param)
+		return true, nil
+	}
+	if active != tunedProfile {
+		include = active
+	}
+	if !slices.Contains(params, param) {
+		params = append(params, param)
+	}
+	if err := writeTunedProfile(profilePath, include, params); err != nil {
+		return false, err
+	}
+	log.Log.Info("TunedKernelParamManager Set(): activate the tuned profile", "profile", tunedProfile, "include", include, "params", params)
+	if _, stderr, err := m.cmd.RunCommand("/bin/sh", "-c",
+		fmt.Sprintf("%s tuned-adm profile %s", utils.GetChrootExtension(), tunedProfile)); err != nil {
+		return false, fmt.Errorf("failed to activate tuned profile %s: %v: %s", tunedProfile, err, stderr)
+	}
+	return true, nil
+}
+
+// activeProfile returns the active tuned profile from the "Current active profile: <name>" output of tuned-adm
+func (m *TunedKernelParamManager) activeProfile() (string, error) {
+	stdout, stderr, err := m.cmd.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s tuned-adm active", utils.GetChrootExtension()))
+	if err != nil {
+		return "", fmt.Errorf("failed to get the active tuned profile: %v: %s", err, stderr)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(stdout))
+	for scanner.Scan() {
+		if profile, found := strings.CutPrefix(scanner.Text(), "Current active profile:"); found {
+			return strings.TrimSpace(profile), nil
+		}
+	}
+	return "", nil
+}
+
+// readTunedProfile returns the included profile and the kernel parameters of the sriov tuned profile,
+// nothing is returned if the profile doesn't exist
+func readTunedProfile(path string) (include string, params []string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, nil
+		}
+		return "", nil, fmt.Errorf("failed to read tuned profile: %v", err)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "include":
+			include = strings.TrimSpace(value)
+		case tunedCmdlineKey:
+			params = strings.Fields(value)
+		}
+	}
+	return include, params, nil
+}
+
+// writeTunedProfile writes the sriov tuned profile with the kernel parameters in the bootloader section
+func writeTunedProfile(path, include string, params []string) error {
+	var b strings.Builder
+	b.WriteString("# generated by the sriov-network-config-daemon, do not edit\n[main]\n")
+	b.WriteString("summary=Kernel parameters required by the SR-IOV network operator\n")
+	if include != "" {
+		fmt.Fprintf(&b, "include=%s\n", include)
+	}
+	fmt.Fprintf(&b, "\n[bootloader]\n%s=%s\n", tunedCmdlineKey, strings.Join(params, " "))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create tuned profile directory: %v", err)
+	}
+	if err := fileutil.WriteFileAtomic(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write tuned profile: %v", err)
+	}
+	return nil
+}
+
+// isTunedManagedHost returns true if the tuned daemon runs on the host and the kernel parameters
+// can be managed with a tuned profile, rpm-ostree based hosts always use rpm-ostree
+func isTunedManagedHost() bool {
+	for _, path := range []string{tunedAdmPath, tunedPidFile} {
+		if _, err := os.Stat(utils.GetHostExtensionPath(path)); err != nil {
+			return false
+		}
+	}
+	_, err := os.Stat(utils.GetHostExtensionPath(consts.OstreeBootedFile))
+	return err != nil
+}
+
+// newKernelParamManager returns the tuned manager if it is requested and tuned manages the host,
+// the kernel args script otherwise
+func newKernelParamManager(hostManager host.HostManagerV2Interface, cmd utils.CmdInterface, setter KernelArgSetter,
+	useTuned bool) KernelParamManager {
+	if useTuned {
+		if isTunedManagedHost() {
+			log.Log.Info("generic plugin: kernel params are set with a tuned profile")
+			return NewTunedKernelParamManager(hostManager, cmd)
+		}
+		log.Log.Info("generic plugin: tuned doesn't manage the host, kernel params are set with the kernel args script")
+	}
+	return NewGrubbyKernelParamManager(hostManager, setter)
+}
@@ -0,0 +1,80 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// kernelParamsKey is the key in the kernel params ConfigMap which contains whitespace separated
+// list of the kernel parameters required on the nodes, e.g.
+//
+//	params: nosoftlockup nmi_watchdog=0
+const kernelParamsKey = "params"
+
+// kernel parameters are passed to the kargs script and used in sed expressions,
+// only the characters which can't break the script are allowed
+var kernelParamRegex = regexp.MustCompile(`^[a-zA-Z0-9_.,:=+-]+$`)
+
+// KernelParamConfigSource provides the kernel parameters which should be set on the node
+// in addition to the parameters required by the plugin
+type KernelParamConfigSource interface {
+	// GetRequiredKernelParams returns the list of the required kernel parameters
+	GetRequiredKernelParams() ([]string, error)
+}
+
+// ConfigMapKernelParamSource reads the required kernel parameters from the ConfigMap
+// in the operator namespace, no parameters are required if the ConfigMap doesn't exist
+type ConfigMapKernelParamSource struct {
+	ConfigMapName string
+}
+
+// NewConfigMapKernelParamSource returns KernelParamConfigSource which reads the ConfigMap with the provided name
+func NewConfigMapKernelParamSource(configMapName string) KernelParamConfigSource {
+	return &ConfigMapKernelParamSource{ConfigMapName: configMapName}
+}
+
+// GetRequiredKernelParams returns the kernel parameters listed in the ConfigMap
+func (s *ConfigMapKernelParamSource) GetRequiredKernelParams() ([]string, error) {
+	kubeClient, err := newKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	cm, err := kubeClient.CoreV1().ConfigMaps(vars.Namespace).Get(context.Background(), s.ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	params := strings.Fields(cm.Data[kernelParamsKey])
+	for _, param := range params {
+		if !kernelParamRegex.MatchString(param) {
+			return nil, fmt.Errorf("invalid kernel parameter %q in %q key of ConfigMap %s", param, kernelParamsKey, cm.Name)
+		}
+	}
+	return params, nil
+}
+
+// addConfiguredKernelArgs queues the kernel parameters provided by the kernel param source,
+// the parameters which are already queued are kept if the source can't be read
+func (p *GenericPlugin) addConfiguredKernelArgs() {
+	if p.kernelParamSource == nil {
+		return
+	}
+	params, err := p.kernelParamSource.GetRequiredKernelParams()
+	if err != nil {
+		log.Log.Error(err, "generic plugin addConfiguredKernelArgs(): failed to get required kernel parameters")
+		return
+	}
+	for _, param := range params {
+		p.addToDesiredKernelArgs(param)
+	}
+}
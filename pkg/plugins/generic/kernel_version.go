@@ -0,0 +1,100 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// names of the features configured by the plugin which require a minimum kernel version
+const (
+	// KernelFeatureVdpa is the creation of vDPA devices on the VFs with the vdpa management API
+	KernelFeatureVdpa = "vdpa"
+	// KernelFeatureDevlinkParams is the configuration of the devlink parameters of the PFs
+	KernelFeatureDevlinkParams = "devlinkParams"
+)
+
+// defaultKernelVersionRequirements contains the minimum kernel version of each feature
+var defaultKernelVersionRequirements = map[string]string{
+	KernelFeatureVdpa:          "5.12",
+	KernelFeatureDevlinkParams: "4.19",
+}
+
+var kernelVersionRe = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// checkKernelVersion compares the running kernel version with the minimum kernel versions of the features,
+// a warning is logged for the features which require a newer kernel and an error is returned if one of them
+// is required. The check is skipped if the version of the running kernel can't be read.
+func (p *GenericPlugin) checkKernelVersion(ctx context.Context, requiredFeatures []string) error {
+	if len(p.KernelVersionRequirements) == 0 {
+		return nil
+	}
+	release, err := p.hostManager.GetKernelVersion(ctx)
+	if err != nil {
+		log.Log.Error(err, "generic plugin checkKernelVersion(): failed to read the kernel version, skip the check")
+		return nil
+	}
+	current, err := kernelVersionToSemver(release)
+	if err != nil {
+		log.Log.Error(err, "generic plugin checkKernelVersion(): skip the check")
+		return nil
+	}
+	required := map[string]bool{}
+	for _, feature := range requiredFeatures {
+		required[feature] = true
+	}
+	features := make([]string, 0, len(p.KernelVersionRequirements))
+	for feature := range p.KernelVersionRequirements {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	var errs []error
+	for _, feature := range features {
+		minVersion := p.KernelVersionRequirements[feature]
+		minimum, err := kernelVersionToSemver(minVersion)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid minimum kernel version of feature %s: %v", feature, err))
+			continue
+		}
+		if semver.Compare(current, minimum) >= 0 {
+			continue
+		}
+		if required[feature] {
+			errs = append(errs, fmt.Errorf("feature %s requires kernel version %s or newer, the running kernel version is %s",
+				feature, minVersion, release))
+			continue
+		}
+		log.Log.Info("generic plugin checkKernelVersion(): WARNING: the running kernel is older than the minimum kernel version of the feature, the feature may not work",
+			"feature", feature, "kernelVersion", release, "minimumVersion", minVersion)
+	}
+	return errors.Join(errs...)
+}
+
+// kernelVersionToSemver converts the numeric part of the kernel version (e.g. 5.14.0-427.13.1.el9_4.x86_64)
+// to a semantic version accepted by golang.org/x/mod/semver, the suffix of the distribution is ignored
+func kernelVersionToSemver(version string) (string, error) {
+	match := kernelVersionRe.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return "", fmt.Errorf("failed to parse kernel version %q", version)
+	}
+	numbers := make([]int, 3)
+	for i, part := range match[1:] {
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse kernel version %q: %v", version, err)
+		}
+		numbers[i] = n
+	}
+	return fmt.Sprintf("v%d.%d.%d", numbers[0], numbers[1], numbers[2]), nil
+}
@@ -0,0 +1,105 @@
+package generic
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+const metricsNamespace = "sriov_generic_plugin"
+
+// apply results of the applies metric
+const (
+	applyResultSucceeded = "succeeded"
+	applyResultFailed    = "failed"
+)
+
+// pluginMetrics are the Prometheus metrics of the plugin, they are registered in a registry
+// dedicated to the plugin so the metrics of the plugin can be scraped separately
+type pluginMetrics struct {
+	registry       *prometheus.Registry
+	applies        *prometheus.CounterVec
+	applyDuration  prometheus.Histogram
+	drainRequests  prometheus.Counter
+	rebootRequests prometheus.Counter
+	// vfAttributeDriftCorrected counts the VF attributes reset by the drivers which were reapplied
+	vfAttributeDriftCorrected *prometheus.CounterVec
+}
+
+func newPluginMetrics() *pluginMetrics {
+	m := &pluginMetrics{
+		registry: prometheus.NewRegistry(),
+		applies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "applies_total",
+			Help:      "Number of applies of the desired state by result.",
+		}, []string{"result"}),
+		applyDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "apply_duration_seconds",
+			Help:      "Duration of the applies of the desired state.",
+			Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600},
+		}),
+		drainRequests: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "drain_requests_total",
+			Help:      "Number of node state changes which required to drain the node.",
+		}),
+		rebootRequests: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "reboot_requests_total",
+			Help:      "Number of node state changes which required to reboot the node.",
+		}),
+		vfAttributeDriftCorrected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "vf_attribute_drift_corrected_total",
+			Help:      "Number of VF attributes which differed from the desired state and were reapplied by attribute.",
+		}, []string{"attribute"}),
+	}
+	m.registry.MustRegister(m.applies, m.applyDuration, m.drainRequests, m.rebootRequests, m.vfAttributeDriftCorrected)
+	// report both results from the start so the failure rate can be computed before the first failure
+	m.applies.WithLabelValues(applyResultSucceeded)
+	m.applies.WithLabelValues(applyResultFailed)
+	for _, attribute := range []string{hostTypes.VfAttributeTrust, hostTypes.VfAttributeSpoofChk,
+		hostTypes.VfAttributeTxRate, hostTypes.VfAttributeVlan} {
+		m.vfAttributeDriftCorrected.WithLabelValues(attribute)
+	}
+	return m
+}
+
+// observeApply records the result and the duration of an apply
+func (m *pluginMetrics) observeApply(err error, duration time.Duration) {
+	result := applyResultSucceeded
+	if err != nil {
+		result = applyResultFailed
+	}
+	m.applies.WithLabelValues(result).Inc()
+	m.applyDuration.Observe(duration.Seconds())
+}
+
+// observeNodeStateChange records the drain and reboot decisions of a node state change
+func (m *pluginMetrics) observeNodeStateChange(needDrain, needReboot bool) {
+	if needDrain {
+		m.drainRequests.Inc()
+	}
+	if needReboot {
+		m.rebootRequests.Inc()
+	}
+}
+
+// observeVFAttributeCorrections records the VF attributes reapplied by the reconciliation
+func (m *pluginMetrics) observeVFAttributeCorrections(corrections []hostTypes.VfAttributeCorrection) {
+	for _, c := range corrections {
+		m.vfAttributeDriftCorrected.WithLabelValues(c.Attribute).Inc()
+	}
+}
+
+// MetricsHandler returns the handler which serves the metrics of the plugin in the Prometheus format,
+// only the metrics of the plugin are served
+func (p *GenericPlugin) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(p.metrics.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,205 @@
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/fileutil"
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+const (
+	pfSettingPromisc      = "promisc"
+	pfSettingAllMulticast = "allMulticast"
+)
+
+// syncPfSettings applies the promiscuous mode, the all-multicast mode and the ethtool features requested
+// for the PFs. The value a setting had before it was changed for the first time is recorded on the host,
+// the setting is restored to it when no policy requests it anymore. The settings are changed without
+// draining the node and only if they differ from the current state.
+func (p *GenericPlugin) syncPfSettings(ctx context.Context) error {
+	originals, err := loadPfOriginalSettings()
+	if err != nil {
+		return err
+	}
+	pfNames := map[string]string{}
+	desired := map[string]map[string]bool{}
+	for _, iface := range p.filterSkippedDevices(p.DesireState.Spec.Interfaces) {
+		if iface.PfSettings != nil {
+			pfNames[iface.PciAddress] = iface.Name
+			desired[iface.PciAddress] = flattenPfSettings(iface.PfSettings)
+		}
+	}
+	skipped := p.skippedDevices()
+	for pciAddress := range originals {
+		if _, found := pfNames[pciAddress]; found {
+			continue
+		}
+		if _, ok := skipped[pciAddress]; ok {
+			continue
+		}
+		ifaceStatus := p.DesireState.GetInterfaceStateByPciAddress(pciAddress)
+		if ifaceStatus == nil {
+			log.Log.V(2).Info("generic plugin syncPfSettings(): PF not found, original settings are not restored", "address", pciAddress)
+			continue
+		}
+		pfNames[pciAddress] = ifaceStatus.Name
+	}
+
+	for _, pciAddress := range sortedKeys(pfNames) {
+		current, err := p.hostManager.GetPfSettings(ctx, pfNames[pciAddress])
+		if err != nil {
+			return &hostTypes.InterfaceSyncError{PciAddress: pciAddress, Err: err}
+		}
+		original := flattenPfSettings(originals[pciAddress])
+		update, restored := pfSettingsUpdate(desired[pciAddress], flattenPfSettings(current), original)
+		if len(update) > 0 {
+			// the original values are stored before the first change so that they are not lost if the change fails
+			originals[pciAddress] = unflattenPfSettings(original)
+			if err := savePfOriginalSettings(originals); err != nil {
+				return err
+			}
+			log.Log.Info("generic plugin syncPfSettings(): update PF settings",
+				"device", pfNames[pciAddress], "settings", update, "restored", restored)
+			if err := p.hostManager.SetPfSettings(ctx, pfNames[pciAddress], unflattenPfSettings(update)); err != nil {
+				return &hostTypes.InterfaceSyncError{PciAddress: pciAddress, Err: err}
+			}
+		}
+		if len(restored) == 0 {
+			continue
+		}
+		for _, setting := range restored {
+			delete(original, setting)
+		}
+		if len(original) == 0 {
+			delete(originals, pciAddress)
+		} else {
+			originals[pciAddress] = unflattenPfSettings(original)
+		}
+		if err := savePfOriginalSettings(originals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pfSettingsUpdate returns the settings to apply to the PF and the settings which are restored to their
+// original value. The current value of a setting is added to original before the setting is changed for
+// the first time.
+func pfSettingsUpdate(desired, current, original map[string]bool) (map[string]bool, []string) {
+	update := map[string]bool{}
+	for setting, value := range desired {
+		currentValue, found := current[setting]
+		if found && currentValue == value {
+			continue
+		}
+		update[setting] = value
+		if _, recorded := original[setting]; !recorded && found {
+			original[setting] = currentValue
+		}
+	}
+	var restored []string
+	for _, setting := range sortedKeys(original) {
+		if _, requested := desired[setting]; requested {
+			continue
+		}
+		if currentValue, found := current[setting]; found && currentValue != original[setting] {
+			update[setting] = original[setting]
+		}
+		restored = append(restored, setting)
+	}
+	return update, restored
+}
+
+// flattenPfSettings returns the settings by name, the ethtool features are named after the feature
+func flattenPfSettings(settings *sriovnetworkv1.PfSettings) map[string]bool {
+	flat := map[string]bool{}
+	if settings == nil {
+		return flat
+	}
+	if settings.Promisc != nil {
+		flat[pfSettingPromisc] = *settings.Promisc
+	}
+	if settings.AllMulticast != nil {
+		flat[pfSettingAllMulticast] = *settings.AllMulticast
+	}
+	for feature, enabled := range settings.Features {
+		flat[feature] = enabled
+	}
+	return flat
+}
+
+func unflattenPfSettings(flat map[string]bool) *sriovnetworkv1.PfSettings {
+	settings := &sriovnetworkv1.PfSettings{}
+	for setting, value := range flat {
+		switch setting {
+		case pfSettingPromisc:
+			settings.Promisc = &value
+		case pfSettingAllMulticast:
+			settings.AllMulticast = &value
+		default:
+			if settings.Features == nil {
+				settings.Features = map[string]bool{}
+			}
+			settings.Features[setting] = value
+		}
+	}
+	return settings
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadPfOriginalSettings returns the original settings of the PFs by PCI address
+func loadPfOriginalSettings() (map[string]*sriovnetworkv1.PfSettings, error) {
+	originals := map[string]*sriovnetworkv1.PfSettings{}
+	data, err := os.ReadFile(utils.GetHostExtensionPath(consts.PfOriginalSettingsPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return originals, nil
+		}
+		return nil, fmt.Errorf("failed to read the original PF settings: %v", err)
+	}
+	if err := json.Unmarshal(data, &originals); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the original PF settings: %v", err)
+	}
+	return originals, nil
+}
+
+// savePfOriginalSettings stores the original settings of the PFs, the file is removed if there are none
+func savePfOriginalSettings(originals map[string]*sriovnetworkv1.PfSettings) error {
+	path := utils.GetHostExtensionPath(consts.PfOriginalSettingsPath)
+	if len(originals) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the original PF settings: %v", err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(originals)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the original PF settings: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create the sriov config folder: %v", err)
+	}
+	if err := fileutil.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write the original PF settings: %v", err)
+	}
+	return nil
+}
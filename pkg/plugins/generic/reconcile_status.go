@@ -0,0 +1,117 @@
+package generic
+
+import (
+	"reflect"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
+)
+
+// ReconcileStatus contains the result of the SR-IOV configuration of a PF
+type ReconcileStatus struct {
+	// LastSuccess is the time of the last successful configuration of the PF
+	LastSuccess time.Time
+	// LastError is the error of the last configuration of the PF, nil if it succeeded
+	LastError error
+	// Attempts is the number of failed configurations of the PF since the last success
+	Attempts int
+	// spec is the configuration of the PF which was applied with the last success
+	spec *sriovnetworkv1.Interface
+}
+
+// PluginStatus contains the reconcile status of the plugin
+type PluginStatus struct {
+	// InterfaceReconcileStatus contains the reconcile status of the PFs by PCI address
+	InterfaceReconcileStatus map[string]ReconcileStatus
+}
+
+// Status returns a copy of the reconcile status of the PFs configured by the plugin
+func (p *GenericPlugin) Status() PluginStatus {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	status := PluginStatus{InterfaceReconcileStatus: make(map[string]ReconcileStatus, len(p.InterfaceReconcileStatus))}
+	for pciAddress, ifaceStatus := range p.InterfaceReconcileStatus {
+		ifaceStatus.spec = nil
+		status.InterfaceReconcileStatus[pciAddress] = ifaceStatus
+	}
+	return status
+}
+
+// isRecentlyReconciled returns true if the PF was configured successfully with the same spec
+// less than SuccessfulReconcileSkipDuration ago
+func (p *GenericPlugin) isRecentlyReconciled(iface *sriovnetworkv1.Interface) bool {
+	if p.SuccessfulReconcileSkipDuration <= 0 {
+		return false
+	}
+	ifaceStatus, ok := p.InterfaceReconcileStatus[iface.PciAddress]
+	if !ok || ifaceStatus.LastError != nil || ifaceStatus.spec == nil {
+		return false
+	}
+	return time.Since(ifaceStatus.LastSuccess) < p.SuccessfulReconcileSkipDuration && reflect.DeepEqual(ifaceStatus.spec, iface)
+}
+
+// filterRecentlyReconciled removes the PFs which were configured recently from the spec and the status
+// interfaces, only the PFs which failed or which spec changed are configured again
+func (p *GenericPlugin) filterRecentlyReconciled(interfaces sriovnetworkv1.Interfaces,
+	statuses sriovnetworkv1.InterfaceExts) (sriovnetworkv1.Interfaces, sriovnetworkv1.InterfaceExts) {
+	skip := map[string]bool{}
+	filtered := make(sriovnetworkv1.Interfaces, 0, len(interfaces))
+	for i := range interfaces {
+		if p.isRecentlyReconciled(&interfaces[i]) {
+			log.Log.V(2).Info("generic plugin: PF was configured recently, skipping",
+				"address", interfaces[i].PciAddress, "lastSuccess", p.InterfaceReconcileStatus[interfaces[i].PciAddress].LastSuccess)
+			skip[interfaces[i].PciAddress] = true
+			continue
+		}
+		filtered = append(filtered, interfaces[i])
+	}
+	if len(skip) == 0 {
+		return interfaces, statuses
+	}
+	filteredStatuses := make(sriovnetworkv1.InterfaceExts, 0, len(statuses))
+	for _, ifaceStatus := range statuses {
+		if !skip[ifaceStatus.PciAddress] {
+			filteredStatuses = append(filteredStatuses, ifaceStatus)
+		}
+	}
+	return filtered, filteredStatuses
+}
+
+// updateReconcileStatus records the result of the configuration of the PFs, the PFs which failed are
+// found by the PCI address in err, all the PFs failed if the error is not related to a PF.
+// The entries of the PFs which are no longer in the desired state are removed.
+func (p *GenericPlugin) updateReconcileStatus(configured sriovnetworkv1.Interfaces, err error) {
+	failedPFs := map[string]error{}
+	for _, syncErr := range hostTypes.GetInterfaceSyncErrors(err) {
+		failedPFs[syncErr.PciAddress] = syncErr.Err
+	}
+	now := time.Now()
+	for i := range configured {
+		iface := &configured[i]
+		ifaceStatus := p.InterfaceReconcileStatus[iface.PciAddress]
+		ifaceErr, failed := failedPFs[iface.PciAddress]
+		if !failed && len(failedPFs) == 0 && err != nil {
+			ifaceErr, failed = err, true
+		}
+		if failed {
+			ifaceStatus.LastError = ifaceErr
+			ifaceStatus.Attempts++
+			ifaceStatus.spec = nil
+		} else {
+			ifaceStatus = ReconcileStatus{LastSuccess: now, spec: iface.DeepCopy()}
+		}
+		p.InterfaceReconcileStatus[iface.PciAddress] = ifaceStatus
+	}
+	desired := map[string]bool{}
+	for _, iface := range p.DesireState.Spec.Interfaces {
+		desired[iface.PciAddress] = true
+	}
+	for pciAddress := range p.InterfaceReconcileStatus {
+		if !desired[pciAddress] {
+			delete(p.InterfaceReconcileStatus, pciAddress)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// skipDevicesKey is the key in the skip devices ConfigMap which contains YAML map of
+// PCI addresses to the reason of skipping the device, e.g.
+//
+//	devices: |
+//	  "0000:3b:00.0": managed by SmartNIC firmware
+//
+// PCI addresses can't be used as ConfigMap keys directly because they contain colons
+const skipDevicesKey = "devices"
+
+// newKubeClient returns the client used to read the skip devices ConfigMap, overridden in unit-tests
+var newKubeClient = func() (kubernetes.Interface, error) {
+	if vars.Config == nil {
+		return nil, fmt.Errorf("kubernetes client config is not initialized")
+	}
+	return kubernetes.NewForConfig(vars.Config)
+}
+
+// syncSkipDevices reloads the list of devices which should not be configured by the plugin,
+// the previously loaded list is kept if the ConfigMap can't be read
+func (p *GenericPlugin) syncSkipDevices() {
+	// PFs skipped by the checks are evaluated again during the next apply
+	p.pfsToSkip = nil
+	if p.skipDevicesConfigMap == "" {
+		return
+	}
+	skipDevices, err := loadSkipDevices(p.skipDevicesConfigMap)
+	if err != nil {
+		log.Log.Error(err, "generic plugin syncSkipDevices(): failed to load devices to skip, keep the current list",
+			"configMap", p.skipDevicesConfigMap, "devices", p.SkipPCIAddresses)
+		return
+	}
+	p.SkipPCIAddresses = skipDevices
+}
+
+func loadSkipDevices(configMapName string) (map[string]string, error) {
+	kubeClient, err := newKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	cm, err := kubeClient.CoreV1().ConfigMaps(vars.Namespace).Get(context.Background(), configMapName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	return parseSkipDevices(cm)
+}
+
+func parseSkipDevices(cm *corev1.ConfigMap) (map[string]string, error) {
+	skipDevices := map[string]string{}
+	data, ok := cm.Data[skipDevicesKey]
+	if !ok {
+		return skipDevices, nil
+	}
+	if err := yaml.UnmarshalStrict([]byte(data), &skipDevices); err != nil {
+		return nil, fmt.Errorf("failed to parse %q key of ConfigMap %s: %v", skipDevicesKey, cm.Name, err)
+	}
+	return skipDevices, nil
+}
+
+// syncPFsToSkip merges the devices from the skip devices ConfigMap with the PFs selected by the PF skip checks.
+// Each PF is checked by the vendor plugin registered for its vendor, the generic check is used for the other PFs.
+func (p *GenericPlugin) syncPFsToSkip() error {
+	pfsToSkip := make(map[string]string, len(p.SkipPCIAddresses))
+	for pciAddress, reason := range p.SkipPCIAddresses {
+		pfsToSkip[pciAddress] = reason
+	}
+	for i := range p.DesireState.Status.Interfaces {
+		iface := &p.DesireState.Status.Interfaces[i]
+		if _, skipped := pfsToSkip[iface.PciAddress]; skipped {
+			continue
+		}
+		shouldSkipPF := shouldSkipPFGeneric
+		if skipper, ok := p.pfSkippers[iface.Vendor]; ok {
+			shouldSkipPF = skipper.ShouldSkipPF
+		}
+		skip, reason, err := shouldSkipPF(p.DesireState, iface)
+		if err != nil {
+			log.Log.Error(err, "generic plugin syncPFsToSkip(): failed to check if PF should be skipped",
+				"address", iface.PciAddress)
+			return err
+		}
+		if skip {
+			pfsToSkip[iface.PciAddress] = reason
+		}
+	}
+	p.pfsToSkip = pfsToSkip
+	return nil
+}
+
+// shouldSkipPFGeneric skips the PFs which are not bound to a driver, VFs of such PFs can't be configured
+func shouldSkipPFGeneric(state *sriovnetworkv1.SriovNetworkNodeState, iface *sriovnetworkv1.InterfaceExt) (bool, string, error) {
+	if iface.Driver == "" {
+		return true, "PF is not bound to a driver", nil
+	}
+	return false, "", nil
+}
+
+// skippedDevices returns the devices which should not be configured, the result of the last syncPFsToSkip
+// is used if available, otherwise only the devices from the skip devices ConfigMap are returned
+func (p *GenericPlugin) skippedDevices() map[string]string {
+	if p.pfsToSkip != nil {
+		return p.pfsToSkip
+	}
+	return p.SkipPCIAddresses
+}
+
+// isDeviceSkipped returns true if the device with the PCI address should not be configured by the plugin
+func (p *GenericPlugin) isDeviceSkipped(pciAddress string) bool {
+	reason, skipped := p.skippedDevices()[pciAddress]
+	if skipped {
+		log.Log.Info("generic plugin: skipping device", "address", pciAddress, "reason", reason)
+	}
+	return skipped
+}
+
+func (p *GenericPlugin) filterSkippedDevices(interfaces sriovnetworkv1.Interfaces) sriovnetworkv1.Interfaces {
+	if len(p.skippedDevices()) == 0 {
+		return interfaces
+	}
+	filtered := make(sriovnetworkv1.Interfaces, 0, len(interfaces))
+	for _, iface := range interfaces {
+		if !p.isDeviceSkipped(iface.PciAddress) {
+			filtered = append(filtered, iface)
+		}
+	}
+	return filtered
+}
+
+func (p *GenericPlugin) filterSkippedDevicesStatus(interfaces sriovnetworkv1.InterfaceExts) sriovnetworkv1.InterfaceExts {
+	if len(p.skippedDevices()) == 0 {
+		return interfaces
+	}
+	filtered := make(sriovnetworkv1.InterfaceExts, 0, len(interfaces))
+	for _, iface := range interfaces {
+		if !p.isDeviceSkipped(iface.PciAddress) {
+			filtered = append(filtered, iface)
+		}
+	}
+	return filtered
+}
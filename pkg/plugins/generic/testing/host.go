@@ -0,0 +1,155 @@
+package testing
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/store"
+	hosttesting "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/testing"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// scenarioKernelVersion is the kernel version of the scenario host, recent enough for all the features
+const scenarioKernelVersion = "6.8.0-45-generic"
+
+// scenarioHost is the FakeHostManager of a scenario with a kernel cmdline and a bootloader configuration:
+// the kernel args added by the plugin are written to the bootloader configuration and appear in the
+// kernel cmdline only after the host is rebooted
+type scenarioHost struct {
+	*hosttesting.FakeHostManager
+
+	lock       sync.Mutex
+	cmdline    []string
+	bootloader []string
+}
+
+func newScenarioHost() *scenarioHost {
+	return &scenarioHost{FakeHostManager: hosttesting.NewFakeHostManager(nil)}
+}
+
+// setKernelArg is the KernelArgSetter of the plugin, it adds the kernel arg to the bootloader configuration
+func (h *scenarioHost) setKernelArg(karg, _ string) (bool, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if slices.Contains(h.bootloader, karg) {
+		return false, nil
+	}
+	h.bootloader = append(h.bootloader, karg)
+	return true, nil
+}
+
+// reboot boots the host with the kernel args of the bootloader configuration
+func (h *scenarioHost) reboot() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.cmdline = slices.Clone(h.bootloader)
+}
+
+func (h *scenarioHost) GetCurrentKernelArgs() (string, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return strings.Join(h.cmdline, " "), nil
+}
+
+func (h *scenarioHost) IsKernelArgsSet(cmdLine, karg string) bool {
+	return slices.Contains(strings.Fields(cmdLine), karg)
+}
+
+func (h *scenarioHost) GetHostFacts() (*sriovnetworkv1.HostFacts, error) {
+	return &sriovnetworkv1.HostFacts{}, nil
+}
+
+func (h *scenarioHost) GetKernelVersion() (string, error) {
+	return scenarioKernelVersion, nil
+}
+
+// scenarioStore keeps the applied configuration of the PFs in memory
+type scenarioStore struct {
+	lock sync.Mutex
+	pfs  map[string]*sriovnetworkv1.Interface
+}
+
+var _ store.ManagerInterface = &scenarioStore{}
+
+func newScenarioStore() *scenarioStore {
+	return &scenarioStore{pfs: map[string]*sriovnetworkv1.Interface{}}
+}
+
+// saveAppliedState records the interfaces of the state as configured by the plugin
+func (s *scenarioStore) saveAppliedState(state *sriovnetworkv1.SriovNetworkNodeState) {
+	for i := range state.Spec.Interfaces {
+		_ = s.SaveLastPfAppliedStatus(&state.Spec.Interfaces[i])
+	}
+}
+
+func (s *scenarioStore) ClearPCIAddressFolder() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pfs = map[string]*sriovnetworkv1.Interface{}
+	return nil
+}
+
+func (s *scenarioStore) SaveLastPfAppliedStatus(pfInfo *sriovnetworkv1.Interface) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pfs[pfInfo.PciAddress] = pfInfo.DeepCopy()
+	return nil
+}
+
+func (s *scenarioStore) RemovePfAppliedStatus(pciAddress string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.pfs, pciAddress)
+	return nil
+}
+
+func (s *scenarioStore) LoadPfsStatus(pciAddress string) (*sriovnetworkv1.Interface, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	pf, ok := s.pfs[pciAddress]
+	if !ok {
+		return nil, false, nil
+	}
+	return pf.DeepCopy(), true, nil
+}
+
+func (s *scenarioStore) GetCheckPointNodeState() (*sriovnetworkv1.SriovNetworkNodeState, error) {
+	return nil, nil
+}
+
+func (s *scenarioStore) WriteCheckpointFile(*sriovnetworkv1.SriovNetworkNodeState) error {
+	return nil
+}
+
+// scenarioCmd runs no commands, the scenarios don't configure the host
+type scenarioCmd struct{}
+
+var _ utils.CmdInterface = scenarioCmd{}
+
+func (scenarioCmd) Chroot(string) (func() error, error) {
+	return func() error { return nil }, nil
+}
+
+func (scenarioCmd) RunCommand(string, ...string) (string, string, error) {
+	return "", "", nil
+}
+
+// staticKernelParams is the KernelParamConfigSource of a scenario
+type staticKernelParams struct {
+	lock   sync.Mutex
+	params []string
+}
+
+func (s *staticKernelParams) set(params []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.params = slices.Clone(params)
+}
+
+func (s *staticKernelParams) GetRequiredKernelParams() ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return slices.Clone(s.params), nil
+}
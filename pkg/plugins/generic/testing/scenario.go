@@ -0,0 +1,179 @@
+package testing
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/generic"
+)
+
+// Scenario is a transition of the node state checked by the ScenarioRunner: the plugin applies the initial
+// state, the host is rebooted and the plugin receives the desired state
+type Scenario struct {
+	// Name identifies the scenario in the report
+	Name string
+	// InitialState is the node state applied before the transition, the drivers and the kernel args it requires
+	// are set up on the host. The transition starts from a host without any configuration if nil.
+	InitialState *sriovnetworkv1.SriovNetworkNodeState
+	// DesiredState is the node state of the transition, its status is the state of the host before the transition
+	DesiredState *sriovnetworkv1.SriovNetworkNodeState
+	// InitialKernelParams are the kernel parameters required by the kernel parameter source of the plugin
+	// when the initial state is applied, they are set on the host
+	InitialKernelParams []string
+	// DesiredKernelParams are the kernel parameters required by the kernel parameter source of the plugin
+	// during the transition
+	DesiredKernelParams []string
+	// ExpectedNeedDrain is the expected drain decision of the plugin for the desired state
+	ExpectedNeedDrain bool
+	// ExpectedNeedReboot is the expected reboot decision of the plugin for the desired state
+	ExpectedNeedReboot bool
+	// ExpectedDriversLoaded are the drivers the plugin loads to apply the desired state, in any order
+	ExpectedDriversLoaded []string
+}
+
+// ScenarioResult contains the values returned by the plugin for the desired state of a scenario
+type ScenarioResult struct {
+	// Scenario is the name of the scenario
+	Scenario      string
+	NeedDrain     bool
+	NeedReboot    bool
+	DriversLoaded []string
+	// Err is the error returned by the plugin, the scenario fails if it is set
+	Err error
+	// Mismatches describes the values which differ from the expected ones, empty if the scenario passed
+	Mismatches []string
+}
+
+// Failed returns true if the plugin didn't behave as expected by the scenario
+func (r *ScenarioResult) Failed() bool {
+	return r.Err != nil || len(r.Mismatches) > 0
+}
+
+func (r *ScenarioResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("scenario %q: %v", r.Scenario, r.Err)
+	}
+	return fmt.Sprintf("scenario %q: %s", r.Scenario, strings.Join(r.Mismatches, ", "))
+}
+
+// Report contains the results of the scenarios in the order they were run
+type Report []ScenarioResult
+
+// Failed returns the results of the failed scenarios
+func (r Report) Failed() []ScenarioResult {
+	var failed []ScenarioResult
+	for i := range r {
+		if r[i].Failed() {
+			failed = append(failed, r[i])
+		}
+	}
+	return failed
+}
+
+// Err returns an error which describes the failed scenarios with the actual values, nil if all passed
+func (r Report) Err() error {
+	var errs []error
+	for _, result := range r.Failed() {
+		errs = append(errs, errors.New(result.String()))
+	}
+	return errors.Join(errs...)
+}
+
+// ScenarioRunner runs the scenarios with a GenericPlugin on a FakeHostManager, each scenario
+// is run with a new plugin and a new host
+type ScenarioRunner struct {
+	options []generic.Option
+}
+
+// NewScenarioRunner returns a ScenarioRunner which creates the plugins with the provided options, the host,
+// the kernel args and the kernel parameter source options are overridden by the runner
+func NewScenarioRunner(options ...generic.Option) *ScenarioRunner {
+	return &ScenarioRunner{options: options}
+}
+
+// Run runs the scenarios and returns their results
+func (r *ScenarioRunner) Run(scenarios ...Scenario) Report {
+	report := make(Report, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		report = append(report, r.run(scenario))
+	}
+	return report
+}
+
+func (r *ScenarioRunner) run(scenario Scenario) ScenarioResult {
+	result := ScenarioResult{Scenario: scenario.Name}
+	if scenario.DesiredState == nil {
+		result.Err = fmt.Errorf("desired state is not set")
+		return result
+	}
+	host := newScenarioHost()
+	pfStore := newScenarioStore()
+	kernelParams := &staticKernelParams{}
+	options := append(slices.Clone(r.options),
+		generic.WithKernelParamSource(kernelParams),
+		generic.WithKernelArgSetter(host.setKernelArg),
+		generic.WithKernelParamGracePeriod(0))
+	vendorPlugin, err := generic.NewGenericPlugin(helper.NewHostHelpers(scenarioCmd{}, host, pfStore, nil), options...)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create the plugin: %v", err)
+		return result
+	}
+	p := vendorPlugin.(*generic.GenericPlugin)
+	defer func() { _ = p.StopWatchdog() }()
+
+	initialState := scenario.InitialState
+	if initialState == nil {
+		initialState = &sriovnetworkv1.SriovNetworkNodeState{}
+	}
+	kernelParams.set(scenario.InitialKernelParams)
+	if _, _, err := p.OnNodeStateChange(initialState.DeepCopy()); err != nil {
+		result.Err = fmt.Errorf("failed to apply the initial state: %v", err)
+		return result
+	}
+	loadDrivers(p, initialState)
+	pfStore.saveAppliedState(initialState)
+	host.reboot()
+
+	kernelParams.set(scenario.DesiredKernelParams)
+	desiredState := scenario.DesiredState.DeepCopy()
+	result.NeedDrain, result.NeedReboot, result.Err = p.OnNodeStateChange(desiredState)
+	if result.Err != nil {
+		return result
+	}
+	result.DriversLoaded = loadDrivers(p, desiredState)
+
+	if result.NeedDrain != scenario.ExpectedNeedDrain {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("needDrain is %t, expected %t",
+			result.NeedDrain, scenario.ExpectedNeedDrain))
+	}
+	if result.NeedReboot != scenario.ExpectedNeedReboot {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("needReboot is %t, expected %t",
+			result.NeedReboot, scenario.ExpectedNeedReboot))
+	}
+	expectedDrivers := slices.Clone(scenario.ExpectedDriversLoaded)
+	sort.Strings(expectedDrivers)
+	if !slices.Equal(result.DriversLoaded, expectedDrivers) {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("drivers loaded are %v, expected %v",
+			result.DriversLoaded, expectedDrivers))
+	}
+	return result
+}
+
+// loadDrivers marks the drivers required by the state as loaded like the apply of the plugin does
+// and returns the sorted names of the drivers which were not loaded yet
+func loadDrivers(p *generic.GenericPlugin, state *sriovnetworkv1.SriovNetworkNodeState) []string {
+	loaded := []string{}
+	for _, driverState := range p.DriverStateMap {
+		if !driverState.DriverLoaded && driverState.NeedDriverFunc(state, driverState) {
+			driverState.DriverLoaded = true
+			loaded = append(loaded, driverState.DriverName)
+		}
+	}
+	sort.Strings(loaded)
+	return loaded
+}
@@ -0,0 +1,53 @@
+package testing_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/generic"
+	generictesting "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins/generic/testing"
+)
+
+var _ = Describe("ScenarioRunner", func() {
+	It("should pass the built-in scenarios", func() {
+		report := generictesting.NewScenarioRunner().Run(generictesting.BuiltinScenarios()...)
+		Expect(report).To(HaveLen(4))
+		Expect(report.Err()).NotTo(HaveOccurred())
+	})
+
+	It("should report the actual values of the failed scenarios", func() {
+		scenario := generictesting.VfioAddScenario()
+		scenario.ExpectedNeedReboot = false
+		scenario.ExpectedDriversLoaded = nil
+		report := generictesting.NewScenarioRunner().Run(generictesting.VdpaAddScenario(), scenario)
+
+		failed := report.Failed()
+		Expect(failed).To(HaveLen(1))
+		Expect(failed[0].Scenario).To(Equal("vfio add"))
+		Expect(failed[0].NeedDrain).To(BeTrue())
+		Expect(failed[0].NeedReboot).To(BeTrue())
+		Expect(failed[0].DriversLoaded).To(Equal([]string{"vfio_pci"}))
+		Expect(report.Err()).To(MatchError(`scenario "vfio add": needReboot is true, expected false, ` +
+			`drivers loaded are [vfio_pci], expected []`))
+	})
+
+	It("should run the scenarios with the options of the plugin", func() {
+		scenario := generictesting.VfioRemoveScenario()
+		scenario.ExpectedNeedDrain = false
+		report := generictesting.NewScenarioRunner(generic.WithDrainStrategy(neverDrain{})).Run(scenario)
+		Expect(report.Err()).NotTo(HaveOccurred())
+	})
+
+	It("should fail the scenarios without desired state", func() {
+		report := generictesting.NewScenarioRunner().Run(generictesting.Scenario{Name: "empty"})
+		Expect(report.Err()).To(MatchError(ContainSubstring("desired state is not set")))
+	})
+})
+
+// neverDrain is a DrainStrategy which never drains the node
+type neverDrain struct{}
+
+func (neverDrain) NeedDrain(*sriovnetworkv1.Interface, *sriovnetworkv1.InterfaceExt) bool {
+	return false
+}
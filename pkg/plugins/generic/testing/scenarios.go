@@ -0,0 +1,156 @@
+package testing
+
+import (
+	"fmt"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// the PF of the built-in scenarios, a ConnectX-6 Dx port with 4 VFs
+const (
+	scenarioPfPciAddress = "0000:3b:00.0"
+	scenarioPfName       = "ens1f0np0"
+	scenarioNumVfs       = 4
+	scenarioTotalVfs     = 8
+	scenarioVfDeviceID   = "101e"
+)
+
+// VfioAddScenario moves half of the VFs of a PF from the kernel driver to vfio-pci: the node is drained
+// to rebind the VFs, vfio-pci is loaded and the node is rebooted to enable the IOMMU
+func VfioAddScenario() Scenario {
+	initial := scenarioState(sriovnetworkv1.ESwithModeLegacy, "mlx5_core",
+		scenarioGroup("netdevice", consts.DeviceTypeNetDevice, "", "0-3"))
+	return Scenario{
+		Name:         "vfio add",
+		InitialState: initial,
+		DesiredState: scenarioState(sriovnetworkv1.ESwithModeLegacy, "mlx5_core",
+			scenarioGroup("netdevice", consts.DeviceTypeNetDevice, "", "0-1"),
+			scenarioGroup("vfio", consts.DeviceTypeVfioPci, "", "2-3")),
+		ExpectedNeedDrain:     true,
+		ExpectedNeedReboot:    true,
+		ExpectedDriversLoaded: []string{"vfio_pci"},
+	}
+}
+
+// VfioRemoveScenario moves the VFs bound to vfio-pci back to the kernel driver: the node is drained to rebind
+// the VFs, the IOMMU kernel args set for vfio-pci are kept so no reboot is needed
+func VfioRemoveScenario() Scenario {
+	initial := VfioAddScenario().DesiredState
+	desired := scenarioState(sriovnetworkv1.ESwithModeLegacy, "mlx5_core",
+		scenarioGroup("netdevice", consts.DeviceTypeNetDevice, "", "0-3"))
+	desired.Status = *initial.Status.DeepCopy()
+	for i := 2; i < scenarioNumVfs; i++ {
+		desired.Status.Interfaces[0].VFs[i].Driver = "vfio-pci"
+	}
+	return Scenario{
+		Name:                  "vfio remove",
+		InitialState:          initial,
+		DesiredState:          desired,
+		ExpectedNeedDrain:     true,
+		ExpectedNeedReboot:    false,
+		ExpectedDriversLoaded: []string{},
+	}
+}
+
+// VdpaAddScenario exposes the VFs of a PF in switchdev mode as virtio vDPA devices: the node is drained
+// to create the vDPA devices and virtio_vdpa is loaded without a reboot
+func VdpaAddScenario() Scenario {
+	return Scenario{
+		Name: "vdpa add",
+		InitialState: scenarioState(sriovnetworkv1.ESwithModeSwitchDev, "mlx5_core",
+			scenarioGroup("netdevice", consts.DeviceTypeNetDevice, "", "0-3")),
+		DesiredState: scenarioState(sriovnetworkv1.ESwithModeSwitchDev, "mlx5_core",
+			scenarioGroup("vdpa", consts.DeviceTypeNetDevice, consts.VdpaTypeVirtio, "0-3")),
+		ExpectedNeedDrain:     true,
+		ExpectedNeedReboot:    false,
+		ExpectedDriversLoaded: []string{"virtio_vdpa"},
+	}
+}
+
+// KernelParamChangeScenario adds a kernel parameter required by the kernel parameter source without changing
+// the node state: the parameter is set in the bootloader configuration and the node is drained and rebooted
+func KernelParamChangeScenario() Scenario {
+	state := scenarioState(sriovnetworkv1.ESwithModeLegacy, "mlx5_core",
+		scenarioGroup("netdevice", consts.DeviceTypeNetDevice, "", "0-3"))
+	return Scenario{
+		Name:                  "kernel param change",
+		InitialState:          state,
+		DesiredState:          state.DeepCopy(),
+		InitialKernelParams:   []string{"hugepagesz=1G"},
+		DesiredKernelParams:   []string{"hugepagesz=1G", "hugepages=16"},
+		ExpectedNeedDrain:     true,
+		ExpectedNeedReboot:    true,
+		ExpectedDriversLoaded: []string{},
+	}
+}
+
+// BuiltinScenarios returns the built-in scenarios of the common node state transitions
+func BuiltinScenarios() []Scenario {
+	return []Scenario{
+		VfioAddScenario(),
+		VfioRemoveScenario(),
+		VdpaAddScenario(),
+		KernelParamChangeScenario(),
+	}
+}
+
+// scenarioGroup returns the VF group of the policy with the VFs of the range
+func scenarioGroup(policy, deviceType, vdpaType, vfRange string) sriovnetworkv1.VfGroup {
+	return sriovnetworkv1.VfGroup{
+		PolicyName:   policy,
+		ResourceName: policy,
+		DeviceType:   deviceType,
+		VdpaType:     vdpaType,
+		VfRange:      vfRange,
+	}
+}
+
+// scenarioState returns a node state with the VF groups on the PF of the scenarios, the status contains the VFs
+// bound to the driver, the representors of the VFs are up in switchdev mode
+func scenarioState(eSwitchMode, vfDriver string, groups ...sriovnetworkv1.VfGroup) *sriovnetworkv1.SriovNetworkNodeState {
+	pfStatus := sriovnetworkv1.InterfaceExt{
+		Name:           scenarioPfName,
+		PciAddress:     scenarioPfPciAddress,
+		Vendor:         consts.VendorMellanox,
+		DeviceID:       "101d",
+		Driver:         "mlx5_core",
+		Mtu:            1500,
+		NumVfs:         scenarioNumVfs,
+		TotalVfs:       scenarioTotalVfs,
+		LinkType:       consts.LinkTypeETH,
+		LinkAdminState: consts.LinkAdminStateUp,
+		EswitchMode:    eSwitchMode,
+	}
+	for vfID := 0; vfID < scenarioNumVfs; vfID++ {
+		vf := sriovnetworkv1.VirtualFunction{
+			PciAddress: fmt.Sprintf("0000:3b:00.%d", vfID+2),
+			Vendor:     consts.VendorMellanox,
+			DeviceID:   scenarioVfDeviceID,
+			VfID:       vfID,
+			Driver:     vfDriver,
+			Mtu:        1500,
+		}
+		if eSwitchMode == sriovnetworkv1.ESwithModeSwitchDev {
+			vf.RepresentorName = fmt.Sprintf("%s_%d", scenarioPfName, vfID)
+			vf.RepresentorMtu = 1500
+			vf.RepresentorLinkAdminState = consts.LinkAdminStateUp
+		}
+		pfStatus.VFs = append(pfStatus.VFs, vf)
+	}
+	return &sriovnetworkv1.SriovNetworkNodeState{
+		Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Interfaces: sriovnetworkv1.Interfaces{{
+				Name:        scenarioPfName,
+				PciAddress:  scenarioPfPciAddress,
+				NumVfs:      scenarioNumVfs,
+				Mtu:         1500,
+				EswitchMode: eSwitchMode,
+				VfGroups:    groups,
+			}},
+		},
+		Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+			Interfaces: sriovnetworkv1.InterfaceExts{pfStatus},
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package generic
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// startVFAttributeReconciler starts the goroutine which reapplies the attributes of the VFs every
+// VfAttributeReconcileInterval, the reconciliation is disabled if the interval is not positive
+func (p *GenericPlugin) startVFAttributeReconciler() {
+	p.vfAttributeReconcilerLock.Lock()
+	defer p.vfAttributeReconcilerLock.Unlock()
+	if p.VfAttributeReconcileInterval <= 0 || p.vfAttributeReconcilerStop != nil || p.vfAttributeReconcilerStopped {
+		return
+	}
+	p.vfAttributeReconcilerStop = make(chan struct{})
+	p.vfAttributeReconcilerDone = make(chan struct{})
+	go p.runVFAttributeReconciler(p.VfAttributeReconcileInterval, p.vfAttributeReconcilerStop, p.vfAttributeReconcilerDone)
+}
+
+func (p *GenericPlugin) runVFAttributeReconciler(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	// the reconciliation in progress is canceled when the goroutine is stopped
+	ctx := wait.ContextForChannel(stop)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.reconcileVFAttributes(ctx)
+		}
+	}
+}
+
+// stopVFAttributeReconciler stops the VF attribute reconciliation goroutine and waits until it exits
+func (p *GenericPlugin) stopVFAttributeReconciler() {
+	p.vfAttributeReconcilerLock.Lock()
+	defer p.vfAttributeReconcilerLock.Unlock()
+	if p.vfAttributeReconcilerStop == nil {
+		return
+	}
+	close(p.vfAttributeReconcilerStop)
+	<-p.vfAttributeReconcilerDone
+	p.vfAttributeReconcilerStop = nil
+	p.vfAttributeReconcilerDone = nil
+}
+
+// SetVFAttributeReconcileInterval restarts the VF attribute reconciliation with the new interval,
+// the reconciliation is disabled if the interval is not positive
+func (p *GenericPlugin) SetVFAttributeReconcileInterval(interval time.Duration) {
+	p.vfAttributeReconcilerLock.Lock()
+	unchanged := p.VfAttributeReconcileInterval == interval
+	p.vfAttributeReconcilerLock.Unlock()
+	if unchanged {
+		return
+	}
+	// the reconciliation may be waiting for the state lock, it must not be held while the goroutine stops
+	p.stopVFAttributeReconciler()
+	p.vfAttributeReconcilerLock.Lock()
+	p.VfAttributeReconcileInterval = interval
+	p.vfAttributeReconcilerLock.Unlock()
+	p.startVFAttributeReconciler()
+	log.Log.Info("generic plugin SetVFAttributeReconcileInterval(): VF attribute reconcile interval changed", "interval", interval)
+}
+
+// reconcileVFAttributes reapplies the trust mode, the spoof check, the tx rate and the VLAN of the VFs
+// which were reset without a node state change, e.g. by a reset of the PF driver, a firmware update or
+// a VM migration. Only the PFs which were configured successfully are checked, the other PFs are
+// configured again by the next apply. The VFs are configured through the PF with netlink, the node state
+// is not synced again.
+func (p *GenericPlugin) reconcileVFAttributes(ctx context.Context) {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	if p.isPaused() || p.DesireState == nil {
+		return
+	}
+	skipped := p.skippedDevices()
+	for _, iface := range sortVfGroups(p.DesireState.Spec.Interfaces) {
+		if _, ok := skipped[iface.PciAddress]; ok {
+			continue
+		}
+		if status, ok := p.InterfaceReconcileStatus[iface.PciAddress]; !ok || status.LastError != nil || status.spec == nil {
+			continue
+		}
+		corrections, err := p.hostManager.ReconcileVfAttributes(ctx, &iface)
+		p.metrics.observeVFAttributeCorrections(corrections)
+		if err != nil {
+			log.Log.Error(err, "generic plugin reconcileVFAttributes(): failed to reapply VF attributes", "address", iface.PciAddress)
+			continue
+		}
+		if len(corrections) > 0 {
+			log.Log.Info("generic plugin reconcileVFAttributes(): VF attributes reapplied",
+				"address", iface.PciAddress, "corrections", corrections)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package generic
+
+import (
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// StartWatchdog starts the goroutine which requests a re-apply of the desired state with trigger when no
+// node state changes are received for WatchdogInterval, the daemon applies the node state again in its sync.
+// The watchdog is disabled if the interval is not positive, it is restarted with trigger when the interval
+// is reloaded.
+func (p *GenericPlugin) StartWatchdog(trigger func()) error {
+	p.watchdogLock.Lock()
+	p.watchdogTrigger = trigger
+	p.watchdogLock.Unlock()
+	p.startWatchdog()
+	return nil
+}
+
+// startWatchdog starts the watchdog goroutine if the watchdog was started by the daemon with StartWatchdog
+func (p *GenericPlugin) startWatchdog() {
+	p.watchdogLock.Lock()
+	defer p.watchdogLock.Unlock()
+	if p.WatchdogInterval <= 0 || p.watchdogTrigger == nil || p.watchdogStop != nil || p.watchdogStopped {
+		return
+	}
+	p.watchdogStop = make(chan struct{})
+	p.watchdogDone = make(chan struct{})
+	go p.runWatchdog(p.WatchdogInterval, p.watchdogStop, p.watchdogDone)
+}
+
+func (p *GenericPlugin) runWatchdog(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if idle := p.idleTime(); idle < interval {
+				log.Log.V(2).Info("generic plugin watchdog: node state changed recently, skip re-apply", "idle", idle)
+				continue
+			}
+			p.forceReconcile()
+		}
+	}
+}
+
+// stopWatchdog stops the watchdog goroutine and waits until it exits
+func (p *GenericPlugin) stopWatchdog() {
+	p.watchdogLock.Lock()
+	defer p.watchdogLock.Unlock()
+	if p.watchdogStop == nil {
+		return
+	}
+	close(p.watchdogStop)
+	<-p.watchdogDone
+	p.watchdogStop = nil
+	p.watchdogDone = nil
+}
+
+// setWatchdogInterval restarts the watchdog with the new interval, returns false if the interval didn't change
+func (p *GenericPlugin) setWatchdogInterval(interval time.Duration) bool {
+	p.watchdogLock.Lock()
+	changed := interval != p.WatchdogInterval
+	p.watchdogLock.Unlock()
+	if !changed {
+		return false
+	}
+	p.stopWatchdog()
+	p.watchdogLock.Lock()
+	p.WatchdogInterval = interval
+	p.watchdogLock.Unlock()
+	p.startWatchdog()
+	return true
+}
+
+// forceReconcile requests the daemon to apply the desired state received with the last node state change again,
+// the host configuration may drift without any node state change, e.g. when a driver is reloaded
+func (p *GenericPlugin) forceReconcile() {
+	p.stateLock.Lock()
+	hasDesiredState := p.DesireState != nil
+	p.stateLock.Unlock()
+	if !hasDesiredState {
+		log.Log.V(2).Info("generic plugin forceReconcile(): no desired state received yet, skip")
+		return
+	}
+	if p.isPaused() {
+		log.Log.V(2).Info("generic plugin forceReconcile(): plugin is paused, skip")
+		return
+	}
+	p.watchdogLock.Lock()
+	trigger := p.watchdogTrigger
+	p.watchdogLock.Unlock()
+	log.Log.Info("generic plugin forceReconcile(): no node state changes received, request a re-apply of the desired state")
+	trigger()
+}
+
+// idleTime returns the time since the last node state change
+func (p *GenericPlugin) idleTime() time.Duration {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+	return time.Since(p.lastStateChange)
+}
+
+// StopWatchdog stops the watchdog and the VF attribute reconciliation goroutines and waits until they exit,
+// a re-apply which is in progress is completed first
+func (p *GenericPlugin) StopWatchdog() error {
+	p.stopVFAttributeReconciler()
+	p.vfAttributeReconcilerLock.Lock()
+	p.vfAttributeReconcilerStopped = true
+	p.vfAttributeReconcilerLock.Unlock()
+	p.stopWatchdog()
+	p.watchdogLock.Lock()
+	defer p.watchdogLock.Unlock()
+	if !p.watchdogStopped {
+		log.Log.Info("generic plugin StopWatchdog(): watchdog stopped")
+		p.watchdogStopped = true
+	}
+	return nil
+}
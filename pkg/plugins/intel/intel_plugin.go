@@ -50,3 +50,8 @@ func (p *IntelPlugin) Apply() error {
 	log.Log.Info("intel plugin Apply()")
 	return nil
 }
+
+// ForceApply applies the config change, the plugin doesn't skip unchanged states
+func (p *IntelPlugin) ForceApply() error {
+	return p.Apply()
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/helper"
 	hostTypes "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/types"
 	plugins "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/systemd"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
@@ -29,6 +30,7 @@ type K8sPlugin struct {
 type updateTargetReq struct {
 	update bool
 	reboot bool
+	remove bool
 }
 
 // set need update flag for updateTargetReq
@@ -42,6 +44,16 @@ func (u *updateTargetReq) SetNeedReboot() {
 	u.reboot = true
 }
 
+// set need remove flag for updateTargetReq
+func (u *updateTargetReq) SetNeedRemove() {
+	u.remove = true
+}
+
+// returns state of the remove flag
+func (u *updateTargetReq) NeedRemove() bool {
+	return u.remove
+}
+
 // returns state of the update flag
 func (u *updateTargetReq) NeedUpdate() bool {
 	return u.update
@@ -83,13 +95,13 @@ func (u *k8sUpdateTarget) reset() {
 }
 
 const (
-	bindataManifestPath      = "bindata/manifests/"
-	switchdevManifestPath    = bindataManifestPath + "switchdev-config/"
-	switchdevUnits           = switchdevManifestPath + "switchdev-units/"
-	sriovUnits               = bindataManifestPath + "sriov-config-service/kubernetes/"
-	sriovUnitFile            = sriovUnits + "sriov-config-service.yaml"
-	sriovPostNetworkUnitFile = sriovUnits + "sriov-config-post-network-service.yaml"
-	ovsUnitFile              = switchdevManifestPath + "ovs-units/ovs-vswitchd.service.yaml"
+	bindataManifestPath   = "bindata/manifests/"
+	switchdevManifestPath = bindataManifestPath + "switchdev-config/"
+	switchdevUnits        = switchdevManifestPath + "switchdev-units/"
+	ovsUnitFile           = switchdevManifestPath + "ovs-units/ovs-vswitchd.service.yaml"
+
+	// log level of the sriov-config services
+	sriovServiceLogLevel = 2
 )
 
 // Initialize our plugin and set up initial values
@@ -121,9 +133,17 @@ func (p *K8sPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeState)
 	needReboot = false
 
 	p.updateTarget.reset()
+	// in daemon mode the sriov-config services re-apply the persisted configuration at boot,
+	// they are not required if no interfaces are persisted
+	persistConfig := !vars.UsingSystemdMode && len(systemd.PersistedInterfaces(new.Spec)) > 0
 	// TODO add check for enableOvsOffload in OperatorConfig later
 	// Update services if switchdev required
-	if !vars.UsingSystemdMode && !sriovnetworkv1.IsSwitchdevModeSpec(new.Spec) {
+	if !vars.UsingSystemdMode && !persistConfig && !sriovnetworkv1.IsSwitchdevModeSpec(new.Spec) {
+		// the services are removed once no configuration must be applied at boot anymore
+		err = p.sriovServicesRemoveUpdate()
+		if err != nil {
+			log.Log.Error(err, "k8s plugin OnNodeStateChange(): failed")
+		}
 		return
 	}
 
@@ -136,7 +156,7 @@ func (p *K8sPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeState)
 		}
 	}
 
-	if vars.UsingSystemdMode {
+	if vars.UsingSystemdMode || persistConfig {
 		// Check sriov service
 		err = p.sriovServicesStateUpdate()
 		if err != nil {
@@ -163,14 +183,17 @@ func (p *K8sPlugin) CheckStatusChanges(*sriovnetworkv1.SriovNetworkNodeState) (b
 // Apply config change
 func (p *K8sPlugin) Apply() error {
 	log.Log.Info("k8s plugin Apply()")
-	if vars.UsingSystemdMode {
-		if err := p.updateSriovServices(); err != nil {
-			return err
-		}
+	if err := p.updateSriovServices(); err != nil {
+		return err
 	}
 	return p.updateOVSService()
 }
 
+// ForceApply applies the config change, the plugin doesn't skip unchanged states
+func (p *K8sPlugin) ForceApply() error {
+	return p.Apply()
+}
+
 func (p *K8sPlugin) readOpenVSwitchdManifest() error {
 	openVSwitchService, err := p.hostHelper.ReadServiceInjectionManifestFile(ovsUnitFile)
 	if err != nil {
@@ -180,21 +203,12 @@ func (p *K8sPlugin) readOpenVSwitchdManifest() error {
 	return nil
 }
 
-func (p *K8sPlugin) readSriovServiceManifest() error {
-	sriovService, err := p.hostHelper.ReadServiceManifestFile(sriovUnitFile)
-	if err != nil {
-		return err
-	}
-	p.sriovService = sriovService
-	return nil
-}
-
-func (p *K8sPlugin) readSriovPostNetworkServiceManifest() error {
-	sriovService, err := p.hostHelper.ReadServiceManifestFile(sriovPostNetworkUnitFile)
+func (p *K8sPlugin) renderSriovServices() error {
+	services, err := p.hostHelper.RenderSriovConfigServices(sriovServiceLogLevel)
 	if err != nil {
 		return err
 	}
-	p.sriovPostNetworkService = sriovService
+	p.sriovService, p.sriovPostNetworkService = services[0], services[1]
 	return nil
 }
 
@@ -202,33 +216,53 @@ func (p *K8sPlugin) readManifestFiles() error {
 	if err := p.readOpenVSwitchdManifest(); err != nil {
 		return err
 	}
-	if err := p.readSriovServiceManifest(); err != nil {
-		return err
-	}
-	if err := p.readSriovPostNetworkServiceManifest(); err != nil {
+	if err := p.renderSriovServices(); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (p *K8sPlugin) sriovServicesStateUpdate() error {
-	for _, s := range []struct {
-		srv    *hostTypes.Service
-		update *updateTargetReq
-	}{
+// sriovServiceTarget is a sriov-config service with its update request
+type sriovServiceTarget struct {
+	srv    *hostTypes.Service
+	update *updateTargetReq
+}
+
+func (p *K8sPlugin) sriovServiceTargets() []sriovServiceTarget {
+	return []sriovServiceTarget{
 		{srv: p.sriovService, update: &p.updateTarget.sriovScript},
 		{srv: p.sriovPostNetworkService, update: &p.updateTarget.sriovPostNetworkScript},
-	} {
+	}
+}
+
+// sriovServicesRemoveUpdate requests the removal of the sriov-config services which exist on the host
+func (p *K8sPlugin) sriovServicesRemoveUpdate() error {
+	for _, s := range p.sriovServiceTargets() {
+		exist, err := p.hostHelper.IsServiceExist(s.srv.Path)
+		if err != nil {
+			return err
+		}
+		if exist {
+			s.update.SetNeedRemove()
+		}
+	}
+	return nil
+}
+
+func (p *K8sPlugin) sriovServicesStateUpdate() error {
+	for _, s := range p.sriovServiceTargets() {
 		isServiceEnabled, err := p.hostHelper.IsServiceEnabled(s.srv.Path)
 		if err != nil {
 			return err
 		}
 		// create and enable the service if it doesn't exist or is not enabled
-		if !isServiceEnabled {
-			s.update.SetNeedReboot()
-		} else {
-			if p.isSystemDServiceNeedUpdate(s.srv) {
+		if !isServiceEnabled || p.isSystemDServiceNeedUpdate(s.srv) {
+			// in daemon mode the services are used only at the next boot,
+			// the configuration is applied by the daemon without a reboot
+			if vars.UsingSystemdMode {
 				s.update.SetNeedReboot()
+			} else {
+				s.update.SetNeedUpdate()
 			}
 		}
 	}
@@ -236,13 +270,13 @@ func (p *K8sPlugin) sriovServicesStateUpdate() error {
 }
 
 func (p *K8sPlugin) updateSriovServices() error {
-	for _, s := range []struct {
-		srv    *hostTypes.Service
-		update *updateTargetReq
-	}{
-		{srv: p.sriovService, update: &p.updateTarget.sriovScript},
-		{srv: p.sriovPostNetworkService, update: &p.updateTarget.sriovPostNetworkScript},
-	} {
+	for _, s := range p.sriovServiceTargets() {
+		if s.update.NeedRemove() {
+			if err := p.hostHelper.DisableService(s.srv); err != nil {
+				return err
+			}
+			continue
+		}
 		if s.update.NeedUpdate() {
 			err := p.hostHelper.EnableService(s.srv)
 			if err != nil {
@@ -82,12 +82,7 @@ var _ = Describe("K8s plugin", func() {
 		realHostMgr, _ := host.NewHostManager(hostHelper)
 
 		// proxy some functions to real host manager to simplify testing and to additionally validate manifests
-		for _, f := range []string{
-			"bindata/manifests/sriov-config-service/kubernetes/sriov-config-service.yaml",
-			"bindata/manifests/sriov-config-service/kubernetes/sriov-config-post-network-service.yaml",
-		} {
-			registerCall(hostHelper.EXPECT().ReadServiceManifestFile(f), realHostMgr.ReadServiceManifestFile)
-		}
+		registerCall(hostHelper.EXPECT().RenderSriovConfigServices(2), realHostMgr.RenderSriovConfigServices)
 		for _, s := range []string{
 			"bindata/manifests/switchdev-config/ovs-units/ovs-vswitchd.service.yaml",
 		} {
@@ -101,8 +96,32 @@ var _ = Describe("K8s plugin", func() {
 		testCtrl.Finish()
 	})
 
+	// sriov-config services are up to date
+	expectSriovServicesConfigured := func() {
+		for _, name := range []string{"sriov-config.service", "sriov-config-post-network.service"} {
+			hostHelper.EXPECT().IsServiceEnabled("/etc/systemd/system/"+name).Return(true, nil)
+			hostHelper.EXPECT().ReadService("/etc/systemd/system/"+name).Return(&hostTypes.Service{Name: name}, nil)
+			hostHelper.EXPECT().CompareServices(&hostTypes.Service{Name: name}, newServiceNameMatcher(name)).Return(false, nil)
+		}
+	}
+
 	It("no switchdev, no systemd", func() {
 		setIsSystemdMode(false)
+		hostHelper.EXPECT().IsServiceExist("/etc/systemd/system/sriov-config.service").Return(false, nil)
+		hostHelper.EXPECT().IsServiceExist("/etc/systemd/system/sriov-config-post-network.service").Return(false, nil)
+		needDrain, needReboot, err := k8sPlugin.OnNodeStateChange(&sriovnetworkv1.SriovNetworkNodeState{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(needReboot).To(BeFalse())
+		Expect(needDrain).To(BeFalse())
+		Expect(k8sPlugin.Apply()).NotTo(HaveOccurred())
+	})
+
+	It("no switchdev, no systemd, services left by a previous configuration", func() {
+		setIsSystemdMode(false)
+		hostHelper.EXPECT().IsServiceExist("/etc/systemd/system/sriov-config.service").Return(true, nil)
+		hostHelper.EXPECT().IsServiceExist("/etc/systemd/system/sriov-config-post-network.service").Return(true, nil)
+		hostHelper.EXPECT().DisableService(newServiceNameMatcher("sriov-config.service")).Return(nil)
+		hostHelper.EXPECT().DisableService(newServiceNameMatcher("sriov-config-post-network.service")).Return(nil)
 		needDrain, needReboot, err := k8sPlugin.OnNodeStateChange(&sriovnetworkv1.SriovNetworkNodeState{})
 		Expect(err).ToNot(HaveOccurred())
 		Expect(needReboot).To(BeFalse())
@@ -110,6 +129,24 @@ var _ = Describe("K8s plugin", func() {
 		Expect(k8sPlugin.Apply()).NotTo(HaveOccurred())
 	})
 
+	It("no switchdev, no systemd, vfio-pci VFs", func() {
+		setIsSystemdMode(false)
+
+		hostHelper.EXPECT().IsServiceEnabled("/etc/systemd/system/sriov-config.service").Return(false, nil)
+		hostHelper.EXPECT().IsServiceEnabled("/etc/systemd/system/sriov-config-post-network.service").Return(false, nil)
+		hostHelper.EXPECT().EnableService(newServiceNameMatcher("sriov-config.service")).Return(nil)
+		hostHelper.EXPECT().EnableService(newServiceNameMatcher("sriov-config-post-network.service")).Return(nil)
+
+		needDrain, needReboot, err := k8sPlugin.OnNodeStateChange(&sriovnetworkv1.SriovNetworkNodeState{
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: []sriovnetworkv1.Interface{{
+				VfGroups: []sriovnetworkv1.VfGroup{{DeviceType: "vfio-pci"}}}}}})
+		Expect(err).ToNot(HaveOccurred())
+		// the services are used only at the next boot
+		Expect(needReboot).To(BeFalse())
+		Expect(needDrain).To(BeFalse())
+		Expect(k8sPlugin.Apply()).NotTo(HaveOccurred())
+	})
+
 	It("systemd, created", func() {
 		setIsSystemdMode(true)
 
@@ -175,6 +212,7 @@ var _ = Describe("K8s plugin", func() {
 	})
 	It("ovs service not found", func() {
 		setIsSystemdMode(false)
+		expectSriovServicesConfigured()
 		hostHelper.EXPECT().IsServiceExist("/usr/lib/systemd/system/ovs-vswitchd.service").Return(false, nil)
 		needDrain, needReboot, err := k8sPlugin.OnNodeStateChange(&sriovnetworkv1.SriovNetworkNodeState{
 			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{Interfaces: []sriovnetworkv1.Interface{{EswitchMode: "switchdev"}}}})
@@ -185,6 +223,7 @@ var _ = Describe("K8s plugin", func() {
 	})
 	It("ovs service updated", func() {
 		setIsSystemdMode(false)
+		expectSriovServicesConfigured()
 		hostHelper.EXPECT().IsServiceExist("/usr/lib/systemd/system/ovs-vswitchd.service").Return(true, nil)
 		hostHelper.EXPECT().ReadService("/usr/lib/systemd/system/ovs-vswitchd.service").Return(
 			&hostTypes.Service{Name: "ovs-vswitchd.service"}, nil)
@@ -203,6 +242,7 @@ var _ = Describe("K8s plugin", func() {
 	})
 	It("ovs service updated - hw offloading already enabled", func() {
 		setIsSystemdMode(false)
+		expectSriovServicesConfigured()
 		hostHelper.EXPECT().IsServiceExist("/usr/lib/systemd/system/ovs-vswitchd.service").Return(true, nil)
 		hostHelper.EXPECT().ReadService("/usr/lib/systemd/system/ovs-vswitchd.service").Return(
 			&hostTypes.Service{Name: "ovs-vswitchd.service"}, nil)
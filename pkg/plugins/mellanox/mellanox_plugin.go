@@ -218,6 +218,37 @@ func (p *MellanoxPlugin) Apply() error {
 	return nil
 }
 
+// ForceApply applies the config change, the firmware attributes are computed by OnNodeStateChange
+func (p *MellanoxPlugin) ForceApply() error {
+	return p.Apply()
+}
+
+// VendorID returns the PCI vendor ID of the Mellanox devices
+func (p *MellanoxPlugin) VendorID() string {
+	return mlx.MellanoxVendorID
+}
+
+// ShouldSkipPF skips the ports of the NICs with firmware changes which are activated only after the reboot,
+// VFs can't be configured according to the spec until then
+func (p *MellanoxPlugin) ShouldSkipPF(_ *sriovnetworkv1.SriovNetworkNodeState, iface *sriovnetworkv1.InterfaceExt) (bool, string, error) {
+	pciPrefix := mlx.GetPciAddressPrefix(iface.PciAddress)
+	for _, pciAddress := range pciAddressesToReset {
+		if mlx.GetPciAddressPrefix(pciAddress) == pciPrefix {
+			return true, "firmware configuration change is pending a reboot", nil
+		}
+	}
+	return false, "", nil
+}
+
+// CanRaiseTotalVfs returns true if the maximum number of VFs of the PF can be raised with mstconfig,
+// the firmware can't be configured in lockdown mode
+func (p *MellanoxPlugin) CanRaiseTotalVfs(iface *sriovnetworkv1.InterfaceExt) bool {
+	if p.helpers.IsKernelLockdownMode() {
+		return false
+	}
+	return sriovnetworkv1.GetVfDeviceID(iface.DeviceID) != ""
+}
+
 // nicHasExternallyManagedPFs returns true if one of the ports(interface) of the NIC is marked as externally managed
 // in StoreManagerInterface.
 func (p *MellanoxPlugin) nicHasExternallyManagedPFs(nicPortsMap map[string]sriovnetworkv1.InterfaceExt) (bool, error) {
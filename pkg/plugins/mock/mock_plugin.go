@@ -5,6 +5,8 @@
 package mock_plugin
 
 import (
+	context "context"
+	http "net/http"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -63,6 +65,20 @@ func (mr *MockVendorPluginMockRecorder) CheckStatusChanges(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckStatusChanges", reflect.TypeOf((*MockVendorPlugin)(nil).CheckStatusChanges), arg0)
 }
 
+// ForceApply mocks base method.
+func (m *MockVendorPlugin) ForceApply() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceApply")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForceApply indicates an expected call of ForceApply.
+func (mr *MockVendorPluginMockRecorder) ForceApply() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceApply", reflect.TypeOf((*MockVendorPlugin)(nil).ForceApply))
+}
+
 // Name mocks base method.
 func (m *MockVendorPlugin) Name() string {
 	m.ctrl.T.Helper()
@@ -106,3 +122,358 @@ func (mr *MockVendorPluginMockRecorder) Spec() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Spec", reflect.TypeOf((*MockVendorPlugin)(nil).Spec))
 }
+
+// MockPFSkipper is a mock of PFSkipper interface.
+type MockPFSkipper struct {
+	ctrl     *gomock.Controller
+	recorder *MockPFSkipperMockRecorder
+}
+
+// MockPFSkipperMockRecorder is the mock recorder for MockPFSkipper.
+type MockPFSkipperMockRecorder struct {
+	mock *MockPFSkipper
+}
+
+// NewMockPFSkipper creates a new mock instance.
+func NewMockPFSkipper(ctrl *gomock.Controller) *MockPFSkipper {
+	mock := &MockPFSkipper{ctrl: ctrl}
+	mock.recorder = &MockPFSkipperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPFSkipper) EXPECT() *MockPFSkipperMockRecorder {
+	return m.recorder
+}
+
+// ShouldSkipPF mocks base method.
+func (m *MockPFSkipper) ShouldSkipPF(state *v1.SriovNetworkNodeState, iface *v1.InterfaceExt) (bool, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShouldSkipPF", state, iface)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ShouldSkipPF indicates an expected call of ShouldSkipPF.
+func (mr *MockPFSkipperMockRecorder) ShouldSkipPF(state, iface interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldSkipPF", reflect.TypeOf((*MockPFSkipper)(nil).ShouldSkipPF), state, iface)
+}
+
+// VendorID mocks base method.
+func (m *MockPFSkipper) VendorID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VendorID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// VendorID indicates an expected call of VendorID.
+func (mr *MockPFSkipperMockRecorder) VendorID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VendorID", reflect.TypeOf((*MockPFSkipper)(nil).VendorID))
+}
+
+// MockTotalVfsRaiser is a mock of TotalVfsRaiser interface.
+type MockTotalVfsRaiser struct {
+	ctrl     *gomock.Controller
+	recorder *MockTotalVfsRaiserMockRecorder
+}
+
+// MockTotalVfsRaiserMockRecorder is the mock recorder for MockTotalVfsRaiser.
+type MockTotalVfsRaiserMockRecorder struct {
+	mock *MockTotalVfsRaiser
+}
+
+// NewMockTotalVfsRaiser creates a new mock instance.
+func NewMockTotalVfsRaiser(ctrl *gomock.Controller) *MockTotalVfsRaiser {
+	mock := &MockTotalVfsRaiser{ctrl: ctrl}
+	mock.recorder = &MockTotalVfsRaiserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTotalVfsRaiser) EXPECT() *MockTotalVfsRaiserMockRecorder {
+	return m.recorder
+}
+
+// CanRaiseTotalVfs mocks base method.
+func (m *MockTotalVfsRaiser) CanRaiseTotalVfs(iface *v1.InterfaceExt) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanRaiseTotalVfs", iface)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// CanRaiseTotalVfs indicates an expected call of CanRaiseTotalVfs.
+func (mr *MockTotalVfsRaiserMockRecorder) CanRaiseTotalVfs(iface interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanRaiseTotalVfs", reflect.TypeOf((*MockTotalVfsRaiser)(nil).CanRaiseTotalVfs), iface)
+}
+
+// VendorID mocks base method.
+func (m *MockTotalVfsRaiser) VendorID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VendorID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// VendorID indicates an expected call of VendorID.
+func (mr *MockTotalVfsRaiserMockRecorder) VendorID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VendorID", reflect.TypeOf((*MockTotalVfsRaiser)(nil).VendorID))
+}
+
+// MockPauser is a mock of Pauser interface.
+type MockPauser struct {
+	ctrl     *gomock.Controller
+	recorder *MockPauserMockRecorder
+}
+
+// MockPauserMockRecorder is the mock recorder for MockPauser.
+type MockPauserMockRecorder struct {
+	mock *MockPauser
+}
+
+// NewMockPauser creates a new mock instance.
+func NewMockPauser(ctrl *gomock.Controller) *MockPauser {
+	mock := &MockPauser{ctrl: ctrl}
+	mock.recorder = &MockPauserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPauser) EXPECT() *MockPauserMockRecorder {
+	return m.recorder
+}
+
+// Pause mocks base method.
+func (m *MockPauser) Pause() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockPauserMockRecorder) Pause() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockPauser)(nil).Pause))
+}
+
+// Resume mocks base method.
+func (m *MockPauser) Resume() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockPauserMockRecorder) Resume() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockPauser)(nil).Resume))
+}
+
+// MockMetricsExporter is a mock of MetricsExporter interface.
+type MockMetricsExporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricsExporterMockRecorder
+}
+
+// MockMetricsExporterMockRecorder is the mock recorder for MockMetricsExporter.
+type MockMetricsExporterMockRecorder struct {
+	mock *MockMetricsExporter
+}
+
+// NewMockMetricsExporter creates a new mock instance.
+func NewMockMetricsExporter(ctrl *gomock.Controller) *MockMetricsExporter {
+	mock := &MockMetricsExporter{ctrl: ctrl}
+	mock.recorder = &MockMetricsExporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetricsExporter) EXPECT() *MockMetricsExporterMockRecorder {
+	return m.recorder
+}
+
+// MetricsHandler mocks base method.
+func (m *MockMetricsExporter) MetricsHandler() http.Handler {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MetricsHandler")
+	ret0, _ := ret[0].(http.Handler)
+	return ret0
+}
+
+// MetricsHandler indicates an expected call of MetricsHandler.
+func (mr *MockMetricsExporterMockRecorder) MetricsHandler() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MetricsHandler", reflect.TypeOf((*MockMetricsExporter)(nil).MetricsHandler))
+}
+
+// MockDesiredStateExporter is a mock of DesiredStateExporter interface.
+type MockDesiredStateExporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDesiredStateExporterMockRecorder
+}
+
+// MockDesiredStateExporterMockRecorder is the mock recorder for MockDesiredStateExporter.
+type MockDesiredStateExporterMockRecorder struct {
+	mock *MockDesiredStateExporter
+}
+
+// NewMockDesiredStateExporter creates a new mock instance.
+func NewMockDesiredStateExporter(ctrl *gomock.Controller) *MockDesiredStateExporter {
+	mock := &MockDesiredStateExporter{ctrl: ctrl}
+	mock.recorder = &MockDesiredStateExporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDesiredStateExporter) EXPECT() *MockDesiredStateExporterMockRecorder {
+	return m.recorder
+}
+
+// ExportDesiredState mocks base method.
+func (m *MockDesiredStateExporter) ExportDesiredState() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportDesiredState")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportDesiredState indicates an expected call of ExportDesiredState.
+func (mr *MockDesiredStateExporterMockRecorder) ExportDesiredState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportDesiredState", reflect.TypeOf((*MockDesiredStateExporter)(nil).ExportDesiredState))
+}
+
+// MockWatchdog is a mock of Watchdog interface.
+type MockWatchdog struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatchdogMockRecorder
+}
+
+// MockWatchdogMockRecorder is the mock recorder for MockWatchdog.
+type MockWatchdogMockRecorder struct {
+	mock *MockWatchdog
+}
+
+// NewMockWatchdog creates a new mock instance.
+func NewMockWatchdog(ctrl *gomock.Controller) *MockWatchdog {
+	mock := &MockWatchdog{ctrl: ctrl}
+	mock.recorder = &MockWatchdogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatchdog) EXPECT() *MockWatchdogMockRecorder {
+	return m.recorder
+}
+
+// StartWatchdog mocks base method.
+func (m *MockWatchdog) StartWatchdog(trigger func()) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartWatchdog", trigger)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartWatchdog indicates an expected call of StartWatchdog.
+func (mr *MockWatchdogMockRecorder) StartWatchdog(trigger interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartWatchdog", reflect.TypeOf((*MockWatchdog)(nil).StartWatchdog), trigger)
+}
+
+// StopWatchdog mocks base method.
+func (m *MockWatchdog) StopWatchdog() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopWatchdog")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopWatchdog indicates an expected call of StopWatchdog.
+func (mr *MockWatchdogMockRecorder) StopWatchdog() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopWatchdog", reflect.TypeOf((*MockWatchdog)(nil).StopWatchdog))
+}
+
+// MockContextApplier is a mock of ContextApplier interface.
+type MockContextApplier struct {
+	ctrl     *gomock.Controller
+	recorder *MockContextApplierMockRecorder
+}
+
+// MockContextApplierMockRecorder is the mock recorder for MockContextApplier.
+type MockContextApplierMockRecorder struct {
+	mock *MockContextApplier
+}
+
+// NewMockContextApplier creates a new mock instance.
+func NewMockContextApplier(ctrl *gomock.Controller) *MockContextApplier {
+	mock := &MockContextApplier{ctrl: ctrl}
+	mock.recorder = &MockContextApplierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContextApplier) EXPECT() *MockContextApplierMockRecorder {
+	return m.recorder
+}
+
+// ApplyWithContext mocks base method.
+func (m *MockContextApplier) ApplyWithContext(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyWithContext", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyWithContext indicates an expected call of ApplyWithContext.
+func (mr *MockContextApplierMockRecorder) ApplyWithContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyWithContext", reflect.TypeOf((*MockContextApplier)(nil).ApplyWithContext), ctx)
+}
+
+// MockCleaner is a mock of Cleaner interface.
+type MockCleaner struct {
+	ctrl     *gomock.Controller
+	recorder *MockCleanerMockRecorder
+}
+
+// MockCleanerMockRecorder is the mock recorder for MockCleaner.
+type MockCleanerMockRecorder struct {
+	mock *MockCleaner
+}
+
+// NewMockCleaner creates a new mock instance.
+func NewMockCleaner(ctrl *gomock.Controller) *MockCleaner {
+	mock := &MockCleaner{ctrl: ctrl}
+	mock.recorder = &MockCleanerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCleaner) EXPECT() *MockCleanerMockRecorder {
+	return m.recorder
+}
+
+// Cleanup mocks base method.
+func (m *MockCleaner) Cleanup() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cleanup")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cleanup indicates an expected call of Cleanup.
+func (mr *MockCleanerMockRecorder) Cleanup() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cleanup", reflect.TypeOf((*MockCleaner)(nil).Cleanup))
+}
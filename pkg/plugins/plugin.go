@@ -1,9 +1,16 @@
 package plugin
 
 import (
+	"context"
+	"errors"
+	"net/http"
+
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 )
 
+// ErrPluginPaused is returned by Apply when the plugin is paused
+var ErrPluginPaused = errors.New("plugin is paused")
+
 //go:generate ../../bin/mockgen -destination mock/mock_plugin.go -source plugin.go
 type VendorPlugin interface {
 	// Name returns the name of plugin
@@ -14,6 +21,73 @@ type VendorPlugin interface {
 	OnNodeStateChange(*sriovnetworkv1.SriovNetworkNodeState) (bool, bool, error)
 	// Apply config change
 	Apply() error
+	// ForceApply applies the desired state again even if it is equal to the last applied state
+	ForceApply() error
 	// CheckStatusChanges checks status changes on the SriovNetworkNodeState CR for configured VFs.
 	CheckStatusChanges(*sriovnetworkv1.SriovNetworkNodeState) (bool, error)
 }
+
+// PFSkipper is implemented by the vendor plugins which need to prevent the generic plugin
+// from configuring some of the PFs of the vendor
+type PFSkipper interface {
+	// VendorID returns the PCI vendor ID of the PFs checked by the plugin
+	VendorID() string
+	// ShouldSkipPF returns true and the reason if the VF configuration of the PF should not be modified
+	ShouldSkipPF(state *sriovnetworkv1.SriovNetworkNodeState, iface *sriovnetworkv1.InterfaceExt) (bool, string, error)
+}
+
+// TotalVfsRaiser is implemented by the vendor plugins which raise the maximum number of VFs of the PFs
+// of the vendor (sriov_totalvfs) with the firmware tooling when a policy requests more VFs,
+// the vendor plugin updates the firmware in Apply and the new limit is active after a reboot
+type TotalVfsRaiser interface {
+	// VendorID returns the PCI vendor ID of the PFs handled by the plugin
+	VendorID() string
+	// CanRaiseTotalVfs returns true if the firmware limit of the PF can be raised by the plugin
+	CanRaiseTotalVfs(iface *sriovnetworkv1.InterfaceExt) bool
+}
+
+// Pauser is implemented by the plugins which apply their configuration outside of the node state sync,
+// e.g. in the background, and must stop while the reconciliation of the node is paused
+type Pauser interface {
+	// Pause stops applying configuration changes until Resume is called
+	Pause() error
+	// Resume clears the pause, the configuration is applied again by the next node state sync
+	Resume() error
+}
+
+// MetricsExporter is implemented by the plugins which expose Prometheus metrics,
+// the daemon serves the metrics of each plugin at a dedicated endpoint
+type MetricsExporter interface {
+	// MetricsHandler returns the handler which serves the Prometheus metrics of the plugin
+	MetricsHandler() http.Handler
+}
+
+// DesiredStateExporter is implemented by the plugins which can dump the desired state they are working with,
+// the daemon serves the dump of each plugin at a dedicated debug endpoint
+type DesiredStateExporter interface {
+	// ExportDesiredState returns the desired state of the plugin as a YAML document
+	ExportDesiredState() ([]byte, error)
+}
+
+// Watchdog is implemented by the plugins which detect in the background that their configuration must be
+// applied again, the daemon starts them once the plugins are loaded and stops them on exit
+type Watchdog interface {
+	// StartWatchdog starts the background check, trigger requests the daemon to apply the node state again
+	StartWatchdog(trigger func()) error
+	// StopWatchdog stops the background check and waits until it exits
+	StopWatchdog() error
+}
+
+// ContextApplier is implemented by the plugins which can cancel their host operations, the daemon applies
+// them with a context which is canceled when the daemon stops
+type ContextApplier interface {
+	// ApplyWithContext applies the node state like Apply, the host operations fail once the context is done
+	ApplyWithContext(ctx context.Context) error
+}
+
+// Cleaner is implemented by the plugins which change the configuration of the node outside of the VFs
+// and revert it when the node state is deleted
+type Cleaner interface {
+	// Cleanup reverts the configuration of the node done by the plugin
+	Cleanup() error
+}
@@ -96,7 +96,8 @@ func (p *VirtualPlugin) Apply() error {
 
 	if p.LastState != nil {
 		log.Log.Info("virtual plugin Apply()", "last-state", p.LastState.Spec)
-		if reflect.DeepEqual(p.LastState.Spec.Interfaces, p.DesireState.Spec.Interfaces) {
+		if reflect.DeepEqual(sriovnetworkv1.NormalizeInterfaceSpec(p.LastState.Spec.Interfaces),
+			sriovnetworkv1.NormalizeInterfaceSpec(p.DesireState.Spec.Interfaces)) {
 			log.Log.Info("virtual plugin Apply(): nothing to apply")
 			return nil
 		}
@@ -115,6 +116,18 @@ func (p *VirtualPlugin) Apply() error {
 	return nil
 }
 
+// ForceApply clears the last applied state to configure the VFs again,
+// the last applied state is restored if the apply fails
+func (p *VirtualPlugin) ForceApply() error {
+	lastState := p.LastState
+	p.LastState = nil
+	if err := p.Apply(); err != nil {
+		p.LastState = lastState
+		return err
+	}
+	return nil
+}
+
 func (p *VirtualPlugin) SetSystemdFlag() {
 }
 
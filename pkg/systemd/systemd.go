@@ -19,6 +19,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -26,23 +27,29 @@ import (
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/fileutil"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
 const (
-	SriovSystemdConfigPath        = consts.SriovConfBasePath + "/sriov-interface-config.yaml"
-	SriovSystemdResultPath        = consts.SriovConfBasePath + "/sriov-interface-result.yaml"
-	sriovSystemdSupportedNicPath  = consts.SriovConfBasePath + "/sriov-supported-nics-ids.yaml"
-	sriovSystemdServiceBinaryPath = "/var/lib/sriov/sriov-network-config-daemon"
+	SriovSystemdConfigPath       = consts.SriovConfBasePath + "/sriov-interface-config.yaml"
+	SriovSystemdResultPath       = consts.SriovConfBasePath + "/sriov-interface-result.yaml"
+	SriovPersistedConfigPath     = consts.SriovConfBasePath + "/sriov-persisted-config.yaml"
+	sriovSystemdSupportedNicPath = consts.SriovConfBasePath + "/sriov-supported-nics-ids.yaml"
 
 	SriovServicePath            = "/etc/systemd/system/sriov-config.service"
 	SriovPostNetworkServicePath = "/etc/systemd/system/sriov-config-post-network.service"
 )
 
+// SriovConfigSchemaVersion is the version of the format of the configuration files read by the sriov-config
+// services, the files written before the format was versioned have no version and are loaded as version 0
+const SriovConfigSchemaVersion = 1
+
 // TODO: move this to the host interface also
 
 type SriovConfig struct {
+	SchemaVersion         int                                      `yaml:"schemaVersion"`
 	Spec                  sriovnetworkv1.SriovNetworkNodeStateSpec `yaml:"spec"`
 	UnsupportedNics       bool                                     `yaml:"unsupportedNics"`
 	PlatformType          consts.PlatformTypes                     `yaml:"platformType"`
@@ -61,90 +68,193 @@ func ReadConfFile() (spec *SriovConfig, err error) {
 		return nil, err
 	}
 
-	err = yaml.Unmarshal(rawConfig, &spec)
-
-	return spec, err
+	return loadSriovConfig(rawConfig)
 }
 
 func WriteConfFile(newState *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
-	newFile := false
 	sriovConfig := &SriovConfig{
-		newState.Spec,
-		vars.DevMode,
-		vars.PlatformType,
-		vars.ManageSoftwareBridges,
-		vars.OVSDBSocketPath,
+		SchemaVersion:         SriovConfigSchemaVersion,
+		Spec:                  newState.Spec,
+		UnsupportedNics:       vars.DevMode,
+		PlatformType:          vars.PlatformType,
+		ManageSoftwareBridges: vars.ManageSoftwareBridges,
+		OVSDBSocketPath:       vars.OVSDBSocketPath,
 	}
 
-	_, err := os.Stat(utils.GetHostExtensionPath(SriovSystemdConfigPath))
+	modified, newFile, err := writeSriovConfig(utils.GetHostExtensionPath(SriovSystemdConfigPath), sriovConfig)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Create the sriov-operator folder on the host if it doesn't exist
-			if _, err := os.Stat(utils.GetHostExtensionPath(consts.SriovConfBasePath)); os.IsNotExist(err) {
-				err = os.Mkdir(utils.GetHostExtensionPath(consts.SriovConfBasePath), os.ModeDir)
-				if err != nil {
-					log.Log.Error(err, "WriteConfFile(): fail to create sriov-operator folder",
-						"path", utils.GetHostExtensionPath(consts.SriovConfBasePath))
-					return false, err
-				}
-			}
+		log.Log.Error(err, "WriteConfFile(): fail to write sriov config")
+		return false, err
+	}
 
-			log.Log.V(2).Info("WriteConfFile(): file not existed, create it",
-				"path", utils.GetHostExtensionPath(SriovSystemdConfigPath))
-			_, err = os.Create(utils.GetHostExtensionPath(SriovSystemdConfigPath))
-			if err != nil {
-				log.Log.Error(err, "WriteConfFile(): fail to create file")
-				return false, err
-			}
-			newFile = true
-		} else {
-			return false, err
-		}
+	// this will be used to mark the first time we create this file.
+	// this helps to avoid the first reboot after installation
+	if newFile && len(sriovConfig.Spec.Interfaces) == 0 {
+		log.Log.V(2).Info("WriteConfFile(): first file creation and no interfaces to configure returning reboot false")
+		return false, nil
+	}
+
+	return modified, nil
+}
+
+// loadSriovConfig decodes a configuration file written by the current or a previous release of the operator
+// and migrates it to the current schema version. The unversioned files of the previous releases have the same
+// keys as the current format, the fields added since then are missing from them and keep their zero value.
+func loadSriovConfig(rawConfig []byte) (*SriovConfig, error) {
+	conf := &SriovConfig{}
+	if err := yaml.Unmarshal(rawConfig, conf); err != nil {
+		return nil, err
 	}
+	if conf.SchemaVersion > SriovConfigSchemaVersion {
+		return nil, fmt.Errorf("unsupported sriov config schema version %d, the latest supported version is %d",
+			conf.SchemaVersion, SriovConfigSchemaVersion)
+	}
+	conf.SchemaVersion = SriovConfigSchemaVersion
+	return conf, nil
+}
 
-	oldContent, err := os.ReadFile(utils.GetHostExtensionPath(SriovSystemdConfigPath))
+// normalizeSriovConfig renders the configuration in the current format with the interfaces sorted by PCI address,
+// the absent and the empty fields render the same so the configurations with the same normalized rendering
+// configure the host the same way
+func normalizeSriovConfig(conf *SriovConfig) ([]byte, error) {
+	normalized := *conf
+	normalized.SchemaVersion = SriovConfigSchemaVersion
+	normalized.Spec.Interfaces = make(sriovnetworkv1.Interfaces, len(conf.Spec.Interfaces))
+	copy(normalized.Spec.Interfaces, conf.Spec.Interfaces)
+	sort.Slice(normalized.Spec.Interfaces, func(i, j int) bool {
+		return normalized.Spec.Interfaces[i].PciAddress < normalized.Spec.Interfaces[j].PciAddress
+	})
+	return yaml.Marshal(&normalized)
+}
+
+// sameSriovConfig returns true if the content of a configuration file, in the current or a previous format,
+// holds the same configuration as conf
+func sameSriovConfig(oldContent []byte, conf *SriovConfig) (bool, error) {
+	oldConf, err := loadSriovConfig(oldContent)
 	if err != nil {
-		log.Log.Error(err, "WriteConfFile(): fail to read file", "path", utils.GetHostExtensionPath(SriovSystemdConfigPath))
 		return false, err
 	}
-
-	oldContentObj := &SriovConfig{}
-	err = yaml.Unmarshal(oldContent, oldContentObj)
+	oldNormalized, err := normalizeSriovConfig(oldConf)
 	if err != nil {
-		log.Log.Error(err, "WriteConfFile(): fail to unmarshal old file")
 		return false, err
 	}
-
-	var newContent []byte
-	newContent, err = yaml.Marshal(sriovConfig)
+	newNormalized, err := normalizeSriovConfig(conf)
 	if err != nil {
-		log.Log.Error(err, "WriteConfFile(): fail to marshal sriov config")
 		return false, err
 	}
+	return bytes.Equal(oldNormalized, newNormalized), nil
+}
 
-	if bytes.Equal(newContent, oldContent) {
-		log.Log.V(2).Info("WriteConfFile(): no update")
-		return false, nil
+// writeSriovConfig writes the configuration to the file at path, the file is replaced atomically.
+// A file in the format of a previous release which holds the same configuration is migrated to the
+// current format without reporting a change, a file which can't be loaded is replaced.
+// Returns whether the configuration was changed and whether the file was created.
+func writeSriovConfig(path string, conf *SriovConfig) (modified bool, newFile bool, err error) {
+	newContent, err := yaml.Marshal(conf)
+	if err != nil {
+		log.Log.Error(err, "writeSriovConfig(): fail to marshal sriov config")
+		return false, false, err
+	}
+
+	oldContent, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		log.Log.Error(err, "writeSriovConfig(): fail to read file", "path", path)
+		return false, false, err
 	}
-	log.Log.V(2).Info("WriteConfFile(): old and new configuration are not equal",
-		"old", string(oldContent), "new", string(newContent))
+	newFile = os.IsNotExist(err)
+	modified = true
 
-	log.Log.V(2).Info("WriteConfFile(): write content to file",
-		"content", newContent, "path", utils.GetHostExtensionPath(SriovSystemdConfigPath))
-	err = os.WriteFile(utils.GetHostExtensionPath(SriovSystemdConfigPath), newContent, 0644)
+	if !newFile {
+		if bytes.Equal(newContent, oldContent) {
+			log.Log.V(2).Info("writeSriovConfig(): no update", "path", path)
+			return false, false, nil
+		}
+		same, err := sameSriovConfig(oldContent, conf)
+		if err != nil {
+			log.Log.Error(err, "writeSriovConfig(): fail to load the existing file, replace it", "path", path)
+		}
+		if same {
+			log.Log.V(2).Info("writeSriovConfig(): same configuration, migrate the file to the current format",
+				"path", path, "schemaVersion", SriovConfigSchemaVersion)
+			modified = false
+		} else {
+			log.Log.V(2).Info("writeSriovConfig(): old and new configuration are not equal",
+				"old", string(oldContent), "new", string(newContent))
+		}
+	}
+
+	if err := os.MkdirAll(utils.GetHostExtensionPath(consts.SriovConfBasePath), os.ModeDir|0755); err != nil {
+		log.Log.Error(err, "writeSriovConfig(): fail to create sriov-operator folder",
+			"path", utils.GetHostExtensionPath(consts.SriovConfBasePath))
+		return false, false, err
+	}
+	log.Log.V(2).Info("writeSriovConfig(): write content to file", "content", string(newContent), "path", path)
+	if err := fileutil.WriteFileAtomic(path, newContent, 0644); err != nil {
+		log.Log.Error(err, "writeSriovConfig(): fail to write file", "path", path)
+		return false, false, err
+	}
+	return modified, newFile, nil
+}
+
+// PersistedInterfaces returns the interfaces which are configured again at boot by the sriov-config services
+// when the daemon doesn't run in systemd mode: the PFs in switchdev mode and the PFs with VFs bound to vfio-pci.
+// The workloads and the OVS hardware offload which use these PFs don't work until the VFs are created.
+func PersistedInterfaces(spec sriovnetworkv1.SriovNetworkNodeStateSpec) sriovnetworkv1.Interfaces {
+	persisted := sriovnetworkv1.Interfaces{}
+	for _, iface := range spec.Interfaces {
+		if iface.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
+			persisted = append(persisted, iface)
+			continue
+		}
+		for _, group := range iface.VfGroups {
+			if group.DeviceType == consts.DeviceTypeVfioPci {
+				persisted = append(persisted, iface)
+				break
+			}
+		}
+	}
+	return persisted
+}
+
+// WritePersistedConfFile writes the configuration of the persisted interfaces which is applied at boot
+// by the sriov-config services in daemon mode, the file is replaced atomically.
+// Returns true if the configuration of the file was changed.
+func WritePersistedConfFile(newState *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
+	sriovConfig := &SriovConfig{
+		SchemaVersion: SriovConfigSchemaVersion,
+		Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Interfaces: PersistedInterfaces(newState.Spec),
+		},
+		UnsupportedNics: vars.DevMode,
+		PlatformType:    vars.PlatformType,
+	}
+	modified, _, err := writeSriovConfig(utils.GetHostExtensionPath(SriovPersistedConfigPath), sriovConfig)
 	if err != nil {
-		log.Log.Error(err, "WriteConfFile(): fail to write file")
+		log.Log.Error(err, "WritePersistedConfFile(): fail to write sriov config")
 		return false, err
 	}
+	return modified, nil
+}
 
-	// this will be used to mark the first time we create this file.
-	// this helps to avoid the first reboot after installation
-	if newFile && len(sriovConfig.Spec.Interfaces) == 0 {
-		log.Log.V(2).Info("WriteConfFile(): first file creation and no interfaces to configure returning reboot false")
-		return false, nil
+// ReadPersistedConfFile reads the configuration written by WritePersistedConfFile
+func ReadPersistedConfFile() (*SriovConfig, error) {
+	rawConfig, err := os.ReadFile(utils.GetHostExtensionPath(SriovPersistedConfigPath))
+	if err != nil {
+		return nil, err
 	}
+	return loadSriovConfig(rawConfig)
+}
 
-	return true, nil
+// RemovePersistedConfFile removes the configuration written by WritePersistedConfFile,
+// in systemd mode the sriov-config services use the systemd configuration file only
+func RemovePersistedConfFile() error {
+	err := os.Remove(utils.GetHostExtensionPath(SriovPersistedConfigPath))
+	if err != nil && !os.IsNotExist(err) {
+		log.Log.Error(err, "RemovePersistedConfFile(): failed to remove persisted config file",
+			"path", utils.GetHostExtensionPath(SriovPersistedConfigPath))
+		return err
+	}
+	return nil
 }
 
 func WriteSriovResult(result *SriovResult) error {
@@ -171,7 +281,7 @@ func WriteSriovResult(result *SriovResult) error {
 
 	log.Log.V(2).Info("WriteSriovResult(): write results",
 		"content", string(out), "path", utils.GetHostExtensionPath(SriovSystemdResultPath))
-	err = os.WriteFile(utils.GetHostExtensionPath(SriovSystemdResultPath), out, 0644)
+	err = fileutil.WriteFileAtomic(utils.GetHostExtensionPath(SriovSystemdResultPath), out, 0644)
 	if err != nil {
 		log.Log.Error(err, "WriteSriovResult(): failed to write sriov result file", "path", utils.GetHostExtensionPath(SriovSystemdResultPath))
 		return err
@@ -243,7 +353,7 @@ func WriteSriovSupportedNics() error {
 		rawNicList = append(rawNicList, []byte(fmt.Sprintf("%s\n", line))...)
 	}
 
-	err = os.WriteFile(utils.GetHostExtensionPath(sriovSystemdSupportedNicPath), rawNicList, 0644)
+	err = fileutil.WriteFileAtomic(utils.GetHostExtensionPath(sriovSystemdSupportedNicPath), rawNicList, 0644)
 	if err != nil {
 		log.Log.Error(err, "WriteSriovSupportedNics(): failed to write sriov supported nics ids file",
 			"path", utils.GetHostExtensionPath(sriovSystemdSupportedNicPath))
@@ -291,11 +401,6 @@ func CleanSriovFilesFromHost(isOpenShift bool) error {
 		return err
 	}
 
-	err = os.Remove(utils.GetHostExtensionPath(sriovSystemdServiceBinaryPath))
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
-
 	// in openshift we should not remove the systemd service it will be done by the machine config operator
 	if !isOpenShift {
 		err = os.Remove(utils.GetHostExtensionPath(SriovServicePath))
@@ -0,0 +1,159 @@
+package systemd
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+func testNodeState() *sriovnetworkv1.SriovNetworkNodeState {
+	return &sriovnetworkv1.SriovNetworkNodeState{
+		Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Interfaces: sriovnetworkv1.Interfaces{
+				{PciAddress: "0000:3b:00.0", NumVfs: 4, Name: "ens1f0", EswitchMode: "switchdev", LinkType: "eth",
+					VfGroups: []sriovnetworkv1.VfGroup{{ResourceName: "switchdev", DeviceType: "netdevice", VfRange: "0-3", PolicyName: "policy-switchdev"}}},
+				{PciAddress: "0000:3b:00.1", NumVfs: 8, Name: "ens1f1", EswitchMode: "legacy",
+					VfGroups: []sriovnetworkv1.VfGroup{{ResourceName: "legacy", DeviceType: "vfio-pci", VfRange: "0-7", PolicyName: "policy-legacy"}}},
+			},
+		},
+	}
+}
+
+func configureFakeFSWithConfFile(testdataFile string) {
+	content, err := os.ReadFile(filepath.Join("testdata", testdataFile))
+	Expect(err).NotTo(HaveOccurred())
+	helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+		Dirs:  []string{"/host" + consts.SriovConfBasePath},
+		Files: map[string][]byte{"/host" + SriovSystemdConfigPath: content},
+	})
+}
+
+var _ = Describe("Systemd", func() {
+	Context("WriteConfFile", func() {
+		DescribeTable("migrate the files of the previous releases",
+			func(testdataFile string) {
+				configureFakeFSWithConfFile(testdataFile)
+
+				modified, err := WriteConfFile(testNodeState())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(modified).To(BeFalse())
+
+				conf, err := ReadConfFile()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(conf.SchemaVersion).To(Equal(SriovConfigSchemaVersion))
+				Expect(conf.Spec.Interfaces).To(HaveLen(2))
+
+				// the migrated file is in the current format
+				modified, err = WriteConfFile(testNodeState())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(modified).To(BeFalse())
+			},
+			Entry("unversioned file of an older release", "sriov-interface-config-unversioned-previous.yaml"),
+			Entry("unversioned file of the last release", "sriov-interface-config-unversioned-last.yaml"),
+		)
+		DescribeTable("report the changes of the configuration in the files of the previous releases",
+			func(testdataFile string, update func(*sriovnetworkv1.SriovNetworkNodeState)) {
+				configureFakeFSWithConfFile(testdataFile)
+
+				state := testNodeState()
+				update(state)
+				modified, err := WriteConfFile(state)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(modified).To(BeTrue())
+
+				modified, err = WriteConfFile(state)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(modified).To(BeFalse())
+			},
+			Entry("numVfs", "sriov-interface-config-unversioned-previous.yaml", func(s *sriovnetworkv1.SriovNetworkNodeState) {
+				s.Spec.Interfaces[0].NumVfs = 8
+			}),
+			Entry("eswitch mode", "sriov-interface-config-unversioned-last.yaml", func(s *sriovnetworkv1.SriovNetworkNodeState) {
+				s.Spec.Interfaces[1].EswitchMode = "switchdev"
+			}),
+			Entry("PF removed", "sriov-interface-config-unversioned-previous.yaml", func(s *sriovnetworkv1.SriovNetworkNodeState) {
+				s.Spec.Interfaces = s.Spec.Interfaces[:1]
+			}),
+		)
+		It("should not report a change when only the order of the interfaces differs", func() {
+			configureFakeFSWithConfFile("sriov-interface-config-unversioned-last.yaml")
+
+			state := testNodeState()
+			state.Spec.Interfaces[0], state.Spec.Interfaces[1] = state.Spec.Interfaces[1], state.Spec.Interfaces[0]
+			modified, err := WriteConfFile(state)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeFalse())
+		})
+		It("should replace a file of a newer schema version", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs:  []string{"/host" + consts.SriovConfBasePath},
+				Files: map[string][]byte{"/host" + SriovSystemdConfigPath: []byte("schemaVersion: 99\n")},
+			})
+			_, err := ReadConfFile()
+			Expect(err).To(HaveOccurred())
+
+			modified, err := WriteConfFile(testNodeState())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeTrue())
+
+			conf, err := ReadConfFile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conf.SchemaVersion).To(Equal(SriovConfigSchemaVersion))
+		})
+		It("should not report a change on the first creation without interfaces", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{})
+
+			modified, err := WriteConfFile(&sriovnetworkv1.SriovNetworkNodeState{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeFalse())
+			_, err = ReadConfFile()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+	Context("WritePersistedConfFile", func() {
+		It("should migrate an unversioned file without reporting a change", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/host" + consts.SriovConfBasePath},
+				Files: map[string][]byte{"/host" + SriovPersistedConfigPath: []byte(`spec:
+    interfaces:
+        - pciaddress: 0000:3b:00.0
+          numvfs: 4
+          name: ens1f0
+          linktype: eth
+          eswitchmode: switchdev
+          vfgroups:
+            - resourcename: switchdev
+              devicetype: netdevice
+              vfrange: 0-3
+              policyname: policy-switchdev
+        - pciaddress: 0000:3b:00.1
+          numvfs: 8
+          name: ens1f1
+          eswitchmode: legacy
+          vfgroups:
+            - resourcename: legacy
+              devicetype: vfio-pci
+              vfrange: 0-7
+              policyname: policy-legacy
+unsupportedNics: false
+platformType: 0
+`)},
+			})
+			modified, err := WritePersistedConfFile(testNodeState())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeFalse())
+
+			conf, err := ReadPersistedConfFile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conf.SchemaVersion).To(Equal(SriovConfigSchemaVersion))
+			Expect(conf.Spec.Interfaces).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// flags of "bridge -j vlan show" output set by the VLAN filter entries
+const (
+	bridgeVLANFlagPVID           = "PVID"
+	bridgeVLANFlagEgressUntagged = "Egress Untagged"
+)
+
+// bridgeVLANPort contains the fields of "bridge -j vlan show" output used by the operator
+type bridgeVLANPort struct {
+	IfName string `json:"ifname"`
+	Vlans  []struct {
+		Vlan    uint16   `json:"vlan"`
+		VlanEnd uint16   `json:"vlanEnd"`
+		Flags   []string `json:"flags"`
+	} `json:"vlans"`
+}
+
+// bridgeVLANFlags contains the flags of a VLAN of a bridge port set by the VLAN filter entries
+type bridgeVLANFlags struct {
+	pvid     bool
+	untagged bool
+}
+
+// ConfigureVFBridgeVLAN adds the VLANs of the filters to the bridge port of the VF, the port is the VF
+// representor in switchdev mode and the VF netdevice otherwise. The current VLANs of the port are read first
+// and only the missing entries or the entries with different flags are added. VLANs which are not in
+// the filters are not removed, they can be configured by the bridge (e.g. the default VLAN).
+func ConfigureVFBridgeVLAN(cmd CmdInterface, pfName string, vfID int, filters []sriovnetworkv1.VLANFilterEntry) error {
+	funcLog := log.Log.WithValues("pf", pfName, "vfID", vfID)
+	if len(filters) == 0 {
+		return nil
+	}
+	port, err := getVFBridgePort(pfName, vfID)
+	if err != nil {
+		funcLog.Error(err, "ConfigureVFBridgeVLAN(): failed to get the bridge port of the VF")
+		return err
+	}
+	stdout, stderr, err := cmd.RunCommand("bridge", "-j", "vlan", "show", "dev", port)
+	if err != nil {
+		funcLog.Error(err, "ConfigureVFBridgeVLAN(): failed to read the VLANs of the bridge port", "port", port, "stderr", stderr)
+		return fmt.Errorf("failed to read the VLANs of bridge port %s: %v", port, err)
+	}
+	current, err := parseBridgeVLANs(stdout, port)
+	if err != nil {
+		return err
+	}
+	for _, filter := range filters {
+		if flags, ok := current[filter.VID]; ok && flags == (bridgeVLANFlags{pvid: filter.Ingress, untagged: filter.EgressUntagged}) {
+			continue
+		}
+		funcLog.Info("ConfigureVFBridgeVLAN(): add VLAN to the bridge port", "port", port, "vid", filter.VID,
+			"egressUntagged", filter.EgressUntagged, "ingress", filter.Ingress)
+		if _, stderr, err := cmd.RunCommand("bridge", bridgeVLANAddArgs(port, filter)...); err != nil {
+			funcLog.Error(err, "ConfigureVFBridgeVLAN(): failed to add VLAN to the bridge port", "port", port, "stderr", stderr)
+			return fmt.Errorf("failed to add VLAN %d to bridge port %s: %v", filter.VID, port, err)
+		}
+	}
+	return nil
+}
+
+// bridgeVLANAddArgs returns the arguments of the bridge command which adds the VLAN of the filter to the port
+func bridgeVLANAddArgs(port string, filter sriovnetworkv1.VLANFilterEntry) []string {
+	args := []string{"vlan", "add", "dev", port, "vid", strconv.Itoa(int(filter.VID))}
+	if filter.Ingress {
+		args = append(args, "pvid")
+	}
+	if filter.EgressUntagged {
+		args = append(args, "untagged")
+	}
+	return args
+}
+
+// parseBridgeVLANs returns the flags of the VLANs of the port from "bridge -j vlan show" output
+func parseBridgeVLANs(output, port string) (map[uint16]bridgeVLANFlags, error) {
+	result := map[uint16]bridgeVLANFlags{}
+	if strings.TrimSpace(output) == "" {
+		return result, nil
+	}
+	var ports []bridgeVLANPort
+	if err := json.Unmarshal([]byte(output), &ports); err != nil {
+		return nil, fmt.Errorf("failed to parse the VLANs of bridge port %s: %v", port, err)
+	}
+	for _, p := range ports {
+		if p.IfName != port {
+			continue
+		}
+		for _, vlan := range p.Vlans {
+			flags := bridgeVLANFlags{}
+			for _, flag := range vlan.Flags {
+				switch flag {
+				case bridgeVLANFlagPVID:
+					flags.pvid = true
+				case bridgeVLANFlagEgressUntagged:
+					flags.untagged = true
+				}
+			}
+			end := vlan.VlanEnd
+			if end < vlan.Vlan {
+				end = vlan.Vlan
+			}
+			for vid := uint32(vlan.Vlan); vid <= uint32(end); vid++ {
+				result[uint16(vid)] = flags
+			}
+		}
+	}
+	return result, nil
+}
+
+// getVFBridgePort returns the name of the VF representor if the PF is in switchdev mode,
+// otherwise the name of the VF netdevice
+func getVFBridgePort(pfName string, vfID int) (string, error) {
+	if representor := getVFRepresentor(pfName, vfID); representor != "" {
+		return representor, nil
+	}
+	return getVFNetdev(pfName, vfID)
+}
+
+// getVFNetdev returns the name of the netdevice of the VF in the host network namespace
+func getVFNetdev(pfName string, vfID int) (string, error) {
+	netDir := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, pfName, "device", fmt.Sprintf("virtfn%d", vfID), "net")
+	netdevs, err := os.ReadDir(netDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the netdevice of VF %d of %s: %v", vfID, pfName, err)
+	}
+	if len(netdevs) == 0 {
+		return "", fmt.Errorf("VF %d of %s has no netdevice in the host network namespace", vfID, pfName)
+	}
+	return netdevs[0].Name(), nil
+}
+
+// getVFRepresentor returns the name of the representor of the VF, which is a netdevice of the same switch
+// as the PF with pf<N>vf<vfID> physical port name, or an empty string if it is not found
+func getVFRepresentor(pfName string, vfID int) string {
+	pfPath := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, pfName)
+	switchID, err := os.ReadFile(filepath.Join(pfPath, "phys_switch_id"))
+	if err != nil || strings.TrimSpace(string(switchID)) == "" {
+		return ""
+	}
+	pfPortName, err := os.ReadFile(filepath.Join(pfPath, "phys_port_name"))
+	if err != nil {
+		return ""
+	}
+	pfIndex := strings.TrimPrefix(strings.TrimSpace(string(pfPortName)), "p")
+	representorRe := regexp.MustCompile(fmt.Sprintf(`^(?:c\d+)?pf%svf%d$`, regexp.QuoteMeta(pfIndex), vfID))
+
+	netdevs, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysClassNet))
+	if err != nil {
+		return ""
+	}
+	for _, netdev := range netdevs {
+		netdevPath := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, netdev.Name())
+		netdevSwitchID, err := os.ReadFile(filepath.Join(netdevPath, "phys_switch_id"))
+		if err != nil || strings.TrimSpace(string(netdevSwitchID)) != strings.TrimSpace(string(switchID)) {
+			continue
+		}
+		portName, err := os.ReadFile(filepath.Join(netdevPath, "phys_port_name"))
+		if err == nil && representorRe.MatchString(strings.TrimSpace(string(portName))) {
+			return netdev.Name()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package utils_test
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	mock_utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("ConfigureVFBridgeVLAN", func() {
+	var (
+		testCtrl *gomock.Controller
+		cmd      *mock_utils.MockCmdInterface
+	)
+
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		cmd = mock_utils.NewMockCmdInterface(testCtrl)
+	})
+
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	It("should do nothing without filters", func() {
+		Expect(utils.ConfigureVFBridgeVLAN(cmd, "enp216s0f0np0", 0, nil)).To(Succeed())
+	})
+
+	It("should add the VLANs to the VF netdevice in legacy mode", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{
+				"/sys/class/net/enp216s0f0np0/device/virtfn1/net/enp216s0f0v1",
+			},
+		})
+		gomock.InOrder(
+			cmd.EXPECT().RunCommand("bridge", "-j", "vlan", "show", "dev", "enp216s0f0v1").Return("", "", nil),
+			cmd.EXPECT().RunCommand("bridge", "vlan", "add", "dev", "enp216s0f0v1", "vid", "100", "pvid", "untagged").Return("", "", nil),
+			cmd.EXPECT().RunCommand("bridge", "vlan", "add", "dev", "enp216s0f0v1", "vid", "200").Return("", "", nil),
+		)
+		Expect(utils.ConfigureVFBridgeVLAN(cmd, "enp216s0f0np0", 1, []sriovnetworkv1.VLANFilterEntry{
+			{VID: 100, EgressUntagged: true, Ingress: true},
+			{VID: 200},
+		})).To(Succeed())
+	})
+
+	It("should add only the missing VLANs to the VF representor in switchdev mode", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{
+				"/sys/class/net/enp216s0f0np0",
+				"/sys/class/net/enp216s0f1np1",
+				"/sys/class/net/eth0",
+				"/sys/class/net/eth1",
+			},
+			Files: map[string][]byte{
+				"/sys/class/net/enp216s0f0np0/phys_switch_id": []byte("7cfe90ff2cc0"),
+				"/sys/class/net/enp216s0f0np0/phys_port_name": []byte("p0"),
+				"/sys/class/net/enp216s0f1np1/phys_switch_id": []byte("7cfe90ff2cc1"),
+				"/sys/class/net/enp216s0f1np1/phys_port_name": []byte("p1"),
+				"/sys/class/net/eth0/phys_switch_id":          []byte("7cfe90ff2cc1"),
+				"/sys/class/net/eth0/phys_port_name":          []byte("pf1vf1"),
+				"/sys/class/net/eth1/phys_switch_id":          []byte("7cfe90ff2cc0"),
+				"/sys/class/net/eth1/phys_port_name":          []byte("pf0vf1"),
+			},
+		})
+		gomock.InOrder(
+			cmd.EXPECT().RunCommand("bridge", "-j", "vlan", "show", "dev", "eth1").Return(
+				`[{"ifname":"eth1","vlans":[{"vlan":1,"flags":["PVID","Egress Untagged"]},{"vlan":100},{"vlan":200,"vlanEnd":210}]}]`, "", nil),
+			cmd.EXPECT().RunCommand("bridge", "vlan", "add", "dev", "eth1", "vid", "100", "untagged").Return("", "", nil),
+			cmd.EXPECT().RunCommand("bridge", "vlan", "add", "dev", "eth1", "vid", "300").Return("", "", nil),
+		)
+		Expect(utils.ConfigureVFBridgeVLAN(cmd, "enp216s0f0np0", 1, []sriovnetworkv1.VLANFilterEntry{
+			{VID: 100, EgressUntagged: true},
+			{VID: 205},
+			{VID: 300},
+		})).To(Succeed())
+	})
+
+	It("should fail if the VF has no netdevice", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/sys/class/net/enp216s0f0np0/device/virtfn1/net"},
+		})
+		Expect(utils.ConfigureVFBridgeVLAN(cmd, "enp216s0f0np0", 1, []sriovnetworkv1.VLANFilterEntry{{VID: 100}})).
+			To(MatchError(ContainSubstring("has no netdevice")))
+	})
+
+	It("should return the error of the bridge command", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/sys/class/net/enp216s0f0np0/device/virtfn1/net/enp216s0f0v1"},
+		})
+		cmd.EXPECT().RunCommand("bridge", "-j", "vlan", "show", "dev", "enp216s0f0v1").Return("", "", nil)
+		cmd.EXPECT().RunCommand("bridge", "vlan", "add", "dev", "enp216s0f0v1", "vid", "100").
+			Return("", "Error: bridge port is not enslaved", fmt.Errorf("exit status 255"))
+		Expect(utils.ConfigureVFBridgeVLAN(cmd, "enp216s0f0np0", 1, []sriovnetworkv1.VLANFilterEntry{{VID: 100}})).
+			To(MatchError(ContainSubstring("failed to add VLAN 100 to bridge port enp216s0f0v1")))
+	})
+})
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// chrootState tracks the chroot of the process. The root directory is shared by all the threads of
+// the process, so nested and concurrent Chroot calls share a single chroot which is left when the
+// exit functions of all the calls were called.
+var chrootState struct {
+	sync.Mutex
+	// depth is the number of Chroot calls which didn't exit yet
+	depth int
+	// path is the directory the process is chrooted to
+	path string
+	// root is the original root directory, used to leave the chroot
+	root *os.File
+}
+
+// Chroot changes the root directory of the process to path and returns the function which restores
+// the original root directory. Calling Chroot while the process is already chrooted to the same path
+// only increases the reference count, the original root is restored by the exit function of the
+// last pending call. Calling Chroot with another path while chrooted fails.
+// The exit function can be called more than once, only the first call has effect.
+func (u *utilsHelper) Chroot(path string) (func() error, error) {
+	chrootState.Lock()
+	defer chrootState.Unlock()
+
+	if chrootState.depth > 0 {
+		if chrootState.path != path {
+			return nil, fmt.Errorf("can't chroot to %s, already chrooted to %s", path, chrootState.path)
+		}
+		chrootState.depth++
+		log.Log.V(2).Info("Chroot(): already chrooted, reuse the chroot", "path", path, "depth", chrootState.depth)
+		return chrootExitFunc(), nil
+	}
+
+	root, err := os.Open("/")
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Chroot(path); err != nil {
+		root.Close()
+		return nil, err
+	}
+	chrootState.depth = 1
+	chrootState.path = path
+	chrootState.root = root
+	vars.InChroot = true
+	return chrootExitFunc(), nil
+}
+
+// chrootExitFunc returns the function which releases one Chroot call, the original root
+// directory is restored when no calls are pending
+func chrootExitFunc() func() error {
+	var once sync.Once
+	var exitErr error
+	return func() error {
+		once.Do(func() {
+			chrootState.Lock()
+			defer chrootState.Unlock()
+			chrootState.depth--
+			if chrootState.depth > 0 {
+				return
+			}
+			root := chrootState.root
+			chrootState.root = nil
+			chrootState.path = ""
+			defer root.Close()
+			if err := root.Chdir(); err != nil {
+				exitErr = err
+				return
+			}
+			if err := syscall.Chroot("."); err != nil {
+				exitErr = err
+				return
+			}
+			vars.InChroot = false
+		})
+		return exitErr
+	}
+}
+
+// RunInChroot runs fn chrooted to path. The original root directory is restored when fn returns,
+// also if fn panics, in that case the panic is propagated after the root directory is restored.
+func RunInChroot(cmd CmdInterface, path string, fn func() error) (err error) {
+	exit, err := cmd.Chroot(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if exitErr := exit(); exitErr != nil {
+			log.Log.Error(exitErr, "RunInChroot(): failed to leave the chroot", "path", path)
+			if err == nil {
+				err = exitErr
+			}
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,82 @@
+package utils_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+var _ = Describe("Chroot", func() {
+	var (
+		chrootDir string
+		cmd       utils.CmdInterface
+	)
+
+	// inChroot returns true if the marker file created in the chroot directory is visible in the root
+	inChroot := func() bool {
+		_, err := os.Stat("/chroot-marker")
+		return err == nil
+	}
+
+	BeforeEach(func() {
+		if os.Geteuid() != 0 {
+			Skip("chroot requires root privileges")
+		}
+		chrootDir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(chrootDir, "chroot-marker"), nil, 0644)).To(Succeed())
+		cmd = utils.New()
+	})
+
+	It("should restore the root after nested calls", func() {
+		exitOuter, err := cmd.Chroot(chrootDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inChroot()).To(BeTrue())
+
+		exitInner, err := cmd.Chroot(chrootDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exitInner()).To(Succeed())
+		// the second call of the exit function has no effect
+		Expect(exitInner()).To(Succeed())
+		Expect(inChroot()).To(BeTrue())
+		Expect(vars.InChroot).To(BeTrue())
+
+		Expect(exitOuter()).To(Succeed())
+		Expect(inChroot()).To(BeFalse())
+		Expect(vars.InChroot).To(BeFalse())
+	})
+
+	It("should fail to chroot to another path while chrooted", func() {
+		exit, err := cmd.Chroot(chrootDir)
+		Expect(err).ToNot(HaveOccurred())
+		defer exit()
+		_, err = cmd.Chroot("/other")
+		Expect(err).To(MatchError(ContainSubstring("already chrooted")))
+	})
+
+	It("should restore the root if the nested call panics", func() {
+		Expect(func() {
+			_ = utils.RunInChroot(cmd, chrootDir, func() error {
+				return utils.RunInChroot(cmd, chrootDir, func() error {
+					panic("test")
+				})
+			})
+		}).To(PanicWith("test"))
+		Expect(inChroot()).To(BeFalse())
+		Expect(vars.InChroot).To(BeFalse())
+
+		// the chroot can be used again after the panic
+		Expect(utils.RunInChroot(cmd, chrootDir, func() error {
+			if !inChroot() {
+				return fmt.Errorf("not chrooted")
+			}
+			return nil
+		})).To(Succeed())
+		Expect(inChroot()).To(BeFalse())
+	})
+})
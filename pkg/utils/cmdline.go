@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"strings"
+)
+
+// KernelParam is a parameter of the kernel command line
+type KernelParam struct {
+	// Key of the parameter, dashes are replaced with underscores as the kernel doesn't distinguish them
+	Key string
+	// Value of the parameter with the quotes removed, empty if the parameter has no value
+	Value string
+	// HasValue is true if the parameter was set with key=value, also if the value is empty
+	HasValue bool
+}
+
+// KernelCmdLine contains the parameters of the kernel command line in the order they appear in
+type KernelCmdLine []KernelParam
+
+// ParseKernelCmdLine splits the kernel command line into key[=value] parameters the way the kernel does,
+// whitespaces inside double quotes don't separate parameters and the quotes are removed.
+// The arguments after "--" are passed to init and are not kernel parameters.
+func ParseKernelCmdLine(cmdLine string) KernelCmdLine {
+	params := KernelCmdLine{}
+	for _, token := range splitKernelCmdLine(cmdLine) {
+		if token == "--" {
+			break
+		}
+		params = append(params, ParseKernelParam(token))
+	}
+	return params
+}
+
+// ParseKernelParam parses a single key[=value] kernel parameter
+func ParseKernelParam(param string) KernelParam {
+	param = strings.TrimSpace(param)
+	key, value, hasValue := strings.Cut(param, "=")
+	return KernelParam{
+		Key:      normalizeKernelParamKey(strings.ReplaceAll(key, `"`, "")),
+		Value:    strings.ReplaceAll(value, `"`, ""),
+		HasValue: hasValue,
+	}
+}
+
+// HasParam returns true if the command line contains the parameter with the key
+func (c KernelCmdLine) HasParam(key string) bool {
+	return len(c.GetParamValues(key)) > 0
+}
+
+// GetParam returns the value of the last occurrence of the parameter with the key, which is the value
+// used by the kernel for most of the parameters. False is returned if the parameter is not set.
+func (c KernelCmdLine) GetParam(key string) (string, bool) {
+	values := c.GetParamValues(key)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// GetParamValues returns the values of all the occurrences of the parameter with the key
+func (c KernelCmdLine) GetParamValues(key string) []string {
+	key = normalizeKernelParamKey(key)
+	var values []string
+	for _, param := range c {
+		if param.Key == key {
+			values = append(values, param.Value)
+		}
+	}
+	return values
+}
+
+// Contains returns true if the command line contains the parameter with the same key and value.
+// Parameters which accept multiple occurrences (e.g. pci=realloc pci=noaer) are set by each of
+// them, so the value of any occurrence is matched.
+func (c KernelCmdLine) Contains(param KernelParam) bool {
+	for _, p := range c {
+		if p.Key == param.Key && p.HasValue == param.HasValue && p.Value == param.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitKernelCmdLine splits the command line on whitespaces outside of double quotes
+func splitKernelCmdLine(cmdLine string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		inQuote bool
+		inToken bool
+	)
+	for _, r := range cmdLine {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			inToken = true
+			current.WriteRune(r)
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			inToken = true
+			current.WriteRune(r)
+		}
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+func normalizeKernelParamKey(key string) string {
+	return strings.ReplaceAll(key, "-", "_")
+}
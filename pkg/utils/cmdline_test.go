@@ -0,0 +1,63 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+var _ = Describe("Kernel cmdline", func() {
+	const ostreeCmdLine = "BOOT_IMAGE=(hd0,gpt3)/ostree/rhcos-5c3f/vmlinuz-5.14.0-284.el9.x86_64 " +
+		"ostree=/ostree/boot.1/rhcos/5c3f/0 ignition.platform.id=metal  root=UUID=1b4c  rw rootflags=prjquota " +
+		"boot=UUID=9e2d systemd.unified_cgroup_hierarchy=1 cgroup_no_v1=\"all\" psi=1 intel_iommu=on iommu=pt " +
+		"hugepagesz=1G hugepages=16 amd_iommu=pt_off pci=realloc pci=noaer iommu=off " +
+		"dyndbg=\"file drivers/pci/* +p\" rd.driver.blacklist=nouveau -- single\n"
+
+	DescribeTable("should match the exact key and value",
+		func(cmdLine, karg string, expected bool) {
+			Expect(utils.ParseKernelCmdLine(cmdLine).Contains(utils.ParseKernelParam(karg))).To(Equal(expected))
+		},
+		Entry("param with value", ostreeCmdLine, "intel_iommu=on", true),
+		Entry("param with another value", ostreeCmdLine, "intel_iommu=off", false),
+		Entry("value is a prefix of another value", "amd_iommu=pt_off", "amd_iommu=pt", false),
+		Entry("key is a suffix of another key", "amd_iommu=pt_off", "iommu=pt_off", false),
+		Entry("key is a prefix of another key", "hugepagesz=1G", "hugepages", false),
+		Entry("key without value is not a key with empty value", "quiet", "quiet=", false),
+		Entry("key without value", "ro  quiet\tsplash", "quiet", true),
+		Entry("param appearing twice", ostreeCmdLine, "pci=noaer", true),
+		Entry("quoted value", ostreeCmdLine, "cgroup_no_v1=all", true),
+		Entry("quoted value with spaces", ostreeCmdLine, `dyndbg="file drivers/pci/* +p"`, true),
+		Entry("dashes and underscores in key", "rd.driver-blacklist=nouveau", "rd.driver_blacklist=nouveau", true),
+		Entry("argument passed to init", ostreeCmdLine, "single", false),
+		Entry("empty cmdline", "", "intel_iommu=on", false),
+	)
+
+	It("should return the values of the params", func() {
+		cmdLine := utils.ParseKernelCmdLine(ostreeCmdLine)
+		Expect(cmdLine.HasParam("hugepages")).To(BeTrue())
+		Expect(cmdLine.HasParam("hugepage")).To(BeFalse())
+		Expect(cmdLine.HasParam("rw")).To(BeTrue())
+
+		value, found := cmdLine.GetParam("iommu")
+		Expect(found).To(BeTrue())
+		// the last occurrence is used by the kernel
+		Expect(value).To(Equal("off"))
+		Expect(cmdLine.GetParamValues("iommu")).To(Equal([]string{"pt", "off"}))
+
+		value, found = cmdLine.GetParam("BOOT_IMAGE")
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal("(hd0,gpt3)/ostree/rhcos-5c3f/vmlinuz-5.14.0-284.el9.x86_64"))
+
+		value, found = cmdLine.GetParam("root")
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal("UUID=1b4c"))
+
+		value, found = cmdLine.GetParam("dyndbg")
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal("file drivers/pci/* +p"))
+
+		_, found = cmdLine.GetParam("single")
+		Expect(found).To(BeFalse())
+	})
+})
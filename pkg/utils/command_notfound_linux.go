@@ -0,0 +1,18 @@
+//go:build linux
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// IsCommandNotFound returns true if the error is returned for a command which exited with status 127
+func IsCommandNotFound(err error) bool {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == 127 {
+			return true
+		}
+	}
+	return false
+}
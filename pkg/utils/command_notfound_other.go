@@ -0,0 +1,18 @@
+//go:build !linux
+
+package utils
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// IsCommandNotFound returns true if the error is returned for a command which was not found,
+// either the executable is missing from the PATH or the shell exited with status 127
+func IsCommandNotFound(err error) bool {
+	if errors.Is(err, exec.ErrNotFound) {
+		return true
+	}
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 127
+}
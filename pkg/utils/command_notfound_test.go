@@ -0,0 +1,29 @@
+package utils_test
+
+import (
+	"fmt"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+var _ = Describe("IsCommandNotFound", func() {
+	It("should return true if the command was not found", func() {
+		err := exec.Command("/bin/sh", "-c", "sriov-test-command-not-exist").Run()
+		Expect(err).To(HaveOccurred())
+		Expect(utils.IsCommandNotFound(err)).To(BeTrue())
+	})
+	It("should return false if the command failed", func() {
+		err := exec.Command("/bin/sh", "-c", "exit 1").Run()
+		Expect(err).To(HaveOccurred())
+		Expect(utils.IsCommandNotFound(err)).To(BeFalse())
+	})
+	It("should return false for other errors", func() {
+		Expect(utils.IsCommandNotFound(nil)).To(BeFalse())
+		Expect(utils.IsCommandNotFound(fmt.Errorf("some error"))).To(BeFalse())
+		Expect(utils.IsCommandNotFound(fmt.Errorf("device not found"))).To(BeFalse())
+	})
+})
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// fields of "lldptool -t -n -V ETS-CFG" output used by the operator
+const (
+	lldpETSPrioMap     = "PRIO_MAP:"
+	lldpETSTCBandwidth = "TC Bandwidth:"
+	lldpETSTSAMap      = "TSA_MAP:"
+)
+
+// DCBXConfig contains the ETS configuration advertised by the switch connected to the PF via LLDP/DCBX
+type DCBXConfig struct {
+	// PrioTC maps the 802.1p priorities to the traffic classes
+	PrioTC map[int]int
+	// TCBandwidth contains the bandwidth percentage of each traffic class
+	TCBandwidth map[int]int
+	// TSA contains the transmission selection algorithm of each traffic class, e.g. ets or strict
+	TSA map[int]string
+}
+
+// ReadLLDPDCBXConfig reads the ETS configuration TLV received from the switch connected to the PF with lldptool,
+// lldpad must be running on the host with the transmission and reception of the ETS TLVs enabled on the PF
+func ReadLLDPDCBXConfig(cmd CmdInterface, ifname string) (*DCBXConfig, error) {
+	stdout, stderr, err := cmd.RunCommand("lldptool", "-t", "-n", "-i", ifname, "-V", "ETS-CFG")
+	if err != nil {
+		log.Log.Error(err, "ReadLLDPDCBXConfig(): failed to read the ETS configuration", "device", ifname, "stderr", stderr)
+		return nil, fmt.Errorf("failed to read the ETS configuration of %s with lldptool: %v", ifname, err)
+	}
+	cfg, err := parseLLDPETSConfig(stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the ETS configuration of %s: %v", ifname, err)
+	}
+	log.Log.V(2).Info("ReadLLDPDCBXConfig(): ETS configuration", "device", ifname, "config", cfg)
+	return cfg, nil
+}
+
+// ApplyDCBXToVFs configures the traffic classes of the netdevices of the first numVFs VFs of the PF
+// with the ETS configuration using the dcb tool of iproute2, the VFs without netdevice are skipped
+func ApplyDCBXToVFs(cmd CmdInterface, ifname string, cfg *DCBXConfig, numVFs int) error {
+	args := dcbETSSetArgs(cfg)
+	for vfID := 0; vfID < numVFs; vfID++ {
+		netDir := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, ifname, "device", fmt.Sprintf("virtfn%d", vfID), "net")
+		netdevs, err := os.ReadDir(netDir)
+		if err != nil || len(netdevs) == 0 {
+			log.Log.V(2).Info("ApplyDCBXToVFs(): VF has no netdevice, skip", "device", ifname, "vfID", vfID)
+			continue
+		}
+		vfName := netdevs[0].Name()
+		vfArgs := append([]string{"ets", "set", "dev", vfName}, args...)
+		if _, stderr, err := cmd.RunCommand("dcb", vfArgs...); err != nil {
+			log.Log.Error(err, "ApplyDCBXToVFs(): failed to configure the traffic classes of the VF",
+				"device", ifname, "vf", vfName, "stderr", stderr)
+			return fmt.Errorf("failed to configure the traffic classes of VF %s of %s: %v", vfName, ifname, err)
+		}
+	}
+	return nil
+}
+
+// dcbETSSetArgs returns the arguments of "dcb ets set" which configure the ETS configuration
+func dcbETSSetArgs(cfg *DCBXConfig) []string {
+	var args []string
+	if len(cfg.TSA) > 0 {
+		args = append(args, "tc-tsa")
+		for _, tc := range sortedKeys(cfg.TSA) {
+			args = append(args, fmt.Sprintf("%d:%s", tc, cfg.TSA[tc]))
+		}
+	}
+	if len(cfg.TCBandwidth) > 0 {
+		args = append(args, "tc-bw")
+		for _, tc := range sortedKeys(cfg.TCBandwidth) {
+			args = append(args, fmt.Sprintf("%d:%d", tc, cfg.TCBandwidth[tc]))
+		}
+	}
+	if len(cfg.PrioTC) > 0 {
+		args = append(args, "prio-tc")
+		for _, prio := range sortedKeys(cfg.PrioTC) {
+			args = append(args, fmt.Sprintf("%d:%d", prio, cfg.PrioTC[prio]))
+		}
+	}
+	return args
+}
+
+// parseLLDPETSConfig parses the ETS configuration TLV printed by lldptool, e.g.
+//
+//	IEEE 8021QAZ ETS Configuration TLV
+//		 Willing: yes
+//		 PRIO_MAP: 0:0 1:0 2:0 3:1 4:0 5:0 6:0 7:0
+//		 TC Bandwidth: 50% 50% 0% 0% 0% 0% 0% 0%
+//		 TSA_MAP: 0:ets 1:ets 2:strict 3:strict 4:strict 5:strict 6:strict 7:strict
+func parseLLDPETSConfig(output string) (*DCBXConfig, error) {
+	cfg := &DCBXConfig{PrioTC: map[int]int{}, TCBandwidth: map[int]int{}, TSA: map[int]string{}}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, lldpETSPrioMap):
+			for _, field := range strings.Fields(strings.TrimPrefix(line, lldpETSPrioMap)) {
+				prio, tc, err := parseLLDPMapEntry(field)
+				if err != nil {
+					return nil, err
+				}
+				tcID, err := strconv.Atoi(tc)
+				if err != nil {
+					return nil, fmt.Errorf("invalid traffic class in %q: %v", field, err)
+				}
+				cfg.PrioTC[prio] = tcID
+			}
+		case strings.HasPrefix(line, lldpETSTCBandwidth):
+			for tc, field := range strings.Fields(strings.TrimPrefix(line, lldpETSTCBandwidth)) {
+				bw, err := strconv.Atoi(strings.TrimSuffix(field, "%"))
+				if err != nil {
+					return nil, fmt.Errorf("invalid bandwidth %q: %v", field, err)
+				}
+				cfg.TCBandwidth[tc] = bw
+			}
+		case strings.HasPrefix(line, lldpETSTSAMap):
+			for _, field := range strings.Fields(strings.TrimPrefix(line, lldpETSTSAMap)) {
+				tc, tsa, err := parseLLDPMapEntry(field)
+				if err != nil {
+					return nil, err
+				}
+				cfg.TSA[tc] = tsa
+			}
+		}
+	}
+	if len(cfg.PrioTC) == 0 || len(cfg.TCBandwidth) == 0 {
+		return nil, fmt.Errorf("no ETS configuration received from the switch")
+	}
+	return cfg, nil
+}
+
+// parseLLDPMapEntry parses a <key>:<value> entry of the maps printed by lldptool
+func parseLLDPMapEntry(entry string) (int, string, error) {
+	key, value, found := strings.Cut(entry, ":")
+	if !found {
+		return 0, "", fmt.Errorf("invalid map entry %q", entry)
+	}
+	id, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid map entry %q: %v", entry, err)
+	}
+	return id, value, nil
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
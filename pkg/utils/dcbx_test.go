@@ -0,0 +1,98 @@
+package utils_test
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	mock_utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+const lldpETSConfigOutput = `IEEE 8021QAZ ETS Configuration TLV
+	 Willing: no
+	 CBS: not supported
+	 MAX_TCS: 8
+	 PRIO_MAP: 0:0 1:0 2:0 3:1 4:0 5:0 6:0 7:0
+	 TC Bandwidth: 40% 60% 0% 0% 0% 0% 0% 0%
+	 TSA_MAP: 0:ets 1:ets 2:strict 3:strict 4:strict 5:strict 6:strict 7:strict
+`
+
+var _ = Describe("DCBX", func() {
+	var (
+		testCtrl *gomock.Controller
+		cmd      *mock_utils.MockCmdInterface
+	)
+
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		cmd = mock_utils.NewMockCmdInterface(testCtrl)
+	})
+
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	Context("ReadLLDPDCBXConfig", func() {
+		It("should parse the ETS configuration of the switch", func() {
+			cmd.EXPECT().RunCommand("lldptool", "-t", "-n", "-i", "enp216s0f0np0", "-V", "ETS-CFG").Return(lldpETSConfigOutput, "", nil)
+			cfg, err := utils.ReadLLDPDCBXConfig(cmd, "enp216s0f0np0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.PrioTC).To(Equal(map[int]int{0: 0, 1: 0, 2: 0, 3: 1, 4: 0, 5: 0, 6: 0, 7: 0}))
+			Expect(cfg.TCBandwidth).To(Equal(map[int]int{0: 40, 1: 60, 2: 0, 3: 0, 4: 0, 5: 0, 6: 0, 7: 0}))
+			Expect(cfg.TSA).To(HaveKeyWithValue(1, "ets"))
+			Expect(cfg.TSA).To(HaveKeyWithValue(2, "strict"))
+		})
+
+		It("should fail if the switch didn't send the ETS configuration", func() {
+			cmd.EXPECT().RunCommand("lldptool", "-t", "-n", "-i", "enp216s0f0np0", "-V", "ETS-CFG").Return("", "", nil)
+			_, err := utils.ReadLLDPDCBXConfig(cmd, "enp216s0f0np0")
+			Expect(err).To(MatchError(ContainSubstring("no ETS configuration received from the switch")))
+		})
+
+		It("should return the error of lldptool", func() {
+			cmd.EXPECT().RunCommand("lldptool", "-t", "-n", "-i", "enp216s0f0np0", "-V", "ETS-CFG").Return(
+				"", "Agent instance for device not found", fmt.Errorf("exit status 1"))
+			_, err := utils.ReadLLDPDCBXConfig(cmd, "enp216s0f0np0")
+			Expect(err).To(MatchError(ContainSubstring("failed to read the ETS configuration of enp216s0f0np0 with lldptool")))
+		})
+	})
+
+	Context("ApplyDCBXToVFs", func() {
+		cfg := &utils.DCBXConfig{
+			PrioTC:      map[int]int{0: 0, 1: 1},
+			TCBandwidth: map[int]int{0: 40, 1: 60},
+			TSA:         map[int]string{0: "ets", 1: "ets"},
+		}
+
+		It("should configure the VF netdevices", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{
+					"/sys/class/net/enp216s0f0np0/device/virtfn0/net/enp216s0f0v0",
+					"/sys/class/net/enp216s0f0np0/device/virtfn1",
+					"/sys/class/net/enp216s0f0np0/device/virtfn2/net/enp216s0f0v2",
+				},
+			})
+			gomock.InOrder(
+				cmd.EXPECT().RunCommand("dcb", "ets", "set", "dev", "enp216s0f0v0",
+					"tc-tsa", "0:ets", "1:ets", "tc-bw", "0:40", "1:60", "prio-tc", "0:0", "1:1").Return("", "", nil),
+				cmd.EXPECT().RunCommand("dcb", "ets", "set", "dev", "enp216s0f0v2",
+					"tc-tsa", "0:ets", "1:ets", "tc-bw", "0:40", "1:60", "prio-tc", "0:0", "1:1").Return("", "", nil),
+			)
+			Expect(utils.ApplyDCBXToVFs(cmd, "enp216s0f0np0", cfg, 3)).To(Succeed())
+		})
+
+		It("should return the error of the dcb command", func() {
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{"/sys/class/net/enp216s0f0np0/device/virtfn0/net/enp216s0f0v0"},
+			})
+			cmd.EXPECT().RunCommand("dcb", gomock.Any()).Return("", "Operation not supported", fmt.Errorf("exit status 1"))
+			Expect(utils.ApplyDCBXToVFs(cmd, "enp216s0f0np0", cfg, 1)).To(
+				MatchError(ContainSubstring("failed to configure the traffic classes of VF enp216s0f0v0 of enp216s0f0np0")))
+		})
+	})
+})
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// SetIRQAffinity sets the CPUs which handle the IRQ, cpuSet is a CPU list like "0-3,8-11".
+// The affinity of managed IRQs (e.g. the queues of some drivers) can't be changed, EIO is returned for them.
+func SetIRQAffinity(irqNum int, cpuSet string) error {
+	path := filepath.Join(vars.FilesystemRoot, consts.ProcIrq, strconv.Itoa(irqNum), "smp_affinity_list")
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the affinity of IRQ %d: %w", irqNum, err)
+	}
+	if strings.TrimSpace(string(current)) == cpuSet {
+		return nil
+	}
+	log.Log.V(2).Info("SetIRQAffinity(): set IRQ affinity", "irq", irqNum, "cpus", cpuSet)
+	if err := os.WriteFile(path, []byte(cpuSet), 0644); err != nil {
+		return fmt.Errorf("failed to set the affinity of IRQ %d to %s: %w", irqNum, cpuSet, err)
+	}
+	return nil
+}
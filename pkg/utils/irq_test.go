@@ -0,0 +1,36 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("IRQ affinity", func() {
+	BeforeEach(func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{"/proc/irq/142", "/proc/irq/143"},
+			Files: map[string][]byte{
+				"/proc/irq/142/smp_affinity_list": []byte("0-63\n"),
+				"/proc/irq/143/smp_affinity_list": []byte("2-3\n"),
+			},
+		})
+	})
+
+	It("should set the CPUs of the IRQ", func() {
+		Expect(utils.SetIRQAffinity(142, "2-3")).To(Succeed())
+		helpers.GinkgoAssertFileContentsEquals("/proc/irq/142/smp_affinity_list", "2-3")
+	})
+
+	It("should not write the affinity if it is already set", func() {
+		Expect(utils.SetIRQAffinity(143, "2-3")).To(Succeed())
+		helpers.GinkgoAssertFileContentsEquals("/proc/irq/143/smp_affinity_list", "2-3\n")
+	})
+
+	It("should fail if the IRQ doesn't exist", func() {
+		Expect(utils.SetIRQAffinity(144, "2-3")).To(MatchError(ContainSubstring("failed to read the affinity of IRQ 144")))
+	})
+})
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+
+	"github.com/safchain/ethtool"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// netnsDir is the directory with the network namespaces pinned by the container runtimes and CNIs
+const netnsDir = "/run/netns"
+
+// CleanupOrphanedVFNetNS moves the netdev of the VF back to the root network namespace if it was left
+// in the network namespace of a deleted pod and resets its MAC address to the permanent one.
+// The netdev of a VF in another network namespace is not visible in the net directory of the VF in sysfs.
+// Only the network namespaces pinned in /run/netns which are not used by any process are checked,
+// VFs of running pods are not changed.
+func CleanupOrphanedVFNetNS(pciAddress string) error {
+	devicePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddress)
+	if _, err := os.Stat(filepath.Join(devicePath, "driver")); err != nil {
+		// VF is not bound to a driver
+		return nil
+	}
+	netdevs, err := os.ReadDir(filepath.Join(devicePath, "net"))
+	if err != nil || len(netdevs) > 0 {
+		// VF is bound to a driver without netdevs (e.g. vfio-pci) or the netdev is in the root namespace
+		return nil
+	}
+
+	orphaned, err := orphanedNetNSPaths()
+	if err != nil {
+		return err
+	}
+	for _, nsPath := range orphaned {
+		name, err := moveVFNetdevToRootNS(nsPath, pciAddress)
+		if err != nil {
+			return fmt.Errorf("failed to move the netdev of VF %s from network namespace %s: %v", pciAddress, nsPath, err)
+		}
+		if name == "" {
+			continue
+		}
+		log.Log.Info("CleanupOrphanedVFNetNS(): moved VF netdev from orphaned network namespace",
+			"device", pciAddress, "netns", nsPath, "name", name)
+		return resetNetdevMac(name)
+	}
+	return nil
+}
+
+// orphanedNetNSPaths returns the pinned network namespaces which are not the network namespace of any process
+func orphanedNetNSPaths() ([]string, error) {
+	entries, err := os.ReadDir(GetHostExtensionPath(netnsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	inUse, err := processNetNSInodes()
+	if err != nil {
+		return nil, err
+	}
+	var orphaned []string
+	for _, entry := range entries {
+		nsPath := filepath.Join(GetHostExtensionPath(netnsDir), entry.Name())
+		var stat syscall.Stat_t
+		if err := syscall.Stat(nsPath, &stat); err != nil {
+			log.Log.V(2).Info("orphanedNetNSPaths(): failed to stat network namespace", "path", nsPath, "error", err)
+			continue
+		}
+		if !inUse[stat.Ino] {
+			orphaned = append(orphaned, nsPath)
+		}
+	}
+	return orphaned, nil
+}
+
+// processNetNSInodes returns the inodes of the network namespaces of all the processes
+func processNetNSInodes() (map[uint64]bool, error) {
+	procDir := filepath.Join(vars.FilesystemRoot, consts.Proc)
+	procs, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+	inodes := map[uint64]bool{}
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil {
+			continue
+		}
+		var stat syscall.Stat_t
+		// processes can exit during the scan
+		if err := syscall.Stat(filepath.Join(procDir, proc.Name(), "ns", "net"), &stat); err == nil {
+			inodes[stat.Ino] = true
+		}
+	}
+	return inodes, nil
+}
+
+// moveVFNetdevToRootNS moves the netdev of the VF from the network namespace to the network namespace
+// of PID 1, returns the name of the moved netdev or an empty string if the VF has no netdev in the namespace
+func moveVFNetdevToRootNS(nsPath, pciAddress string) (string, error) {
+	ns, err := netns.GetFromPath(nsPath)
+	if err != nil {
+		return "", err
+	}
+	defer ns.Close()
+
+	// the ethtool socket is opened in the network namespace of the thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	origNS, err := netns.Get()
+	if err != nil {
+		return "", err
+	}
+	defer origNS.Close()
+	if err := netns.Set(ns); err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := netns.Set(origNS); err != nil {
+			log.Log.Error(err, "moveVFNetdevToRootNS(): failed to restore the network namespace of the thread")
+		}
+	}()
+
+	links, err := netlink.LinkList()
+	if err != nil {
+		return "", err
+	}
+	e, err := ethtool.NewEthtool()
+	if err != nil {
+		return "", err
+	}
+	defer e.Close()
+	for _, link := range links {
+		busInfo, err := e.BusInfo(link.Attrs().Name)
+		if err != nil || busInfo != pciAddress {
+			continue
+		}
+		if err := netlink.LinkSetNsPid(link, 1); err != nil {
+			return "", err
+		}
+		return link.Attrs().Name, nil
+	}
+	return "", nil
+}
+
+// resetNetdevMac sets the MAC address of the netdev to its permanent address
+func resetNetdevMac(name string) error {
+	e, err := ethtool.NewEthtool()
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+	permAddr, err := e.PermAddr(name)
+	if err != nil {
+		return fmt.Errorf("failed to get permanent MAC address of %s: %v", name, err)
+	}
+	mac, err := net.ParseMAC(permAddr)
+	if err != nil {
+		return err
+	}
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return err
+	}
+	if err := netlink.LinkSetHardwareAddr(link, mac); err != nil {
+		return fmt.Errorf("failed to reset MAC address of %s: %v", name, err)
+	}
+	return nil
+}
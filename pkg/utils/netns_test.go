@@ -0,0 +1,49 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("CleanupOrphanedVFNetNS", func() {
+	const vfAddress = "0000:d8:02.0"
+
+	It("should skip the VF without driver", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs:  []string{"/sys/bus/pci/devices/0000:d8:02.0/net", "/host/run/netns"},
+			Files: map[string][]byte{"/host/run/netns/orphaned": {}},
+		})
+		Expect(utils.CleanupOrphanedVFNetNS(vfAddress)).To(Succeed())
+	})
+
+	It("should skip the VF with netdev in the root network namespace", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs:     []string{"/sys/bus/pci/devices/0000:d8:02.0/net/enp216s0f0v0", "/host/run/netns", "/sys/bus/pci/drivers/iavf"},
+			Files:    map[string][]byte{"/host/run/netns/orphaned": {}},
+			Symlinks: map[string]string{"/sys/bus/pci/devices/0000:d8:02.0/driver": "../../../../bus/pci/drivers/iavf"},
+		})
+		Expect(utils.CleanupOrphanedVFNetNS(vfAddress)).To(Succeed())
+	})
+
+	It("should do nothing if no network namespace is pinned", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs:     []string{"/sys/bus/pci/devices/0000:d8:02.0/net", "/sys/bus/pci/drivers/iavf"},
+			Symlinks: map[string]string{"/sys/bus/pci/devices/0000:d8:02.0/driver": "../../../../bus/pci/drivers/iavf"},
+		})
+		Expect(utils.CleanupOrphanedVFNetNS(vfAddress)).To(Succeed())
+	})
+
+	It("should look for the netdev in the network namespaces not used by any process", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs:     []string{"/sys/bus/pci/devices/0000:d8:02.0/net", "/host/run/netns", "/sys/bus/pci/drivers/iavf", "/proc"},
+			Files:    map[string][]byte{"/host/run/netns/orphaned": {}},
+			Symlinks: map[string]string{"/sys/bus/pci/devices/0000:d8:02.0/driver": "../../../../bus/pci/drivers/iavf"},
+		})
+		// the pinned file is not a network namespace, it can't be entered
+		Expect(utils.CleanupOrphanedVFNetNS(vfAddress)).To(MatchError(ContainSubstring("/host/run/netns/orphaned")))
+	})
+})
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// ovsDPDKFilterPref is the preference of the TC flower rule added by the operator on the ingress of the
+// VF representors, a fixed preference allows to replace and delete the rule without listing the filters
+const ovsDPDKFilterPref = "49200"
+
+// ConfigureOVSDPDKVFs configures the VFs of the PF in switchdev mode for the hardware offload of OVS with DPDK:
+// the hardware offload is enabled in OVS, a TC flower rule redirecting the traffic to the uplink is added on the
+// ingress of the representor of each VF of the representor map and the representor is added to the OVS bridge
+// of the PF as a DPDK port with the name from the map. The PF must be a DPDK port of an OVS bridge. The VFs
+// configured before an error are cleaned up.
+func ConfigureOVSDPDKVFs(cmd CmdInterface, pf string, vfCount int, representorMap map[int]string) error {
+	funcLog := log.Log.WithValues("pf", pf)
+	if len(representorMap) == 0 {
+		return nil
+	}
+	pciAddress, err := getNetDevPciAddress(pf)
+	if err != nil {
+		return err
+	}
+	bridge, err := getOVSDPDKBridge(cmd, pciAddress)
+	if err != nil {
+		return err
+	}
+	if _, stderr, err := cmd.RunCommand("ovs-vsctl", "set", "Open_vSwitch", ".", "other_config:hw-offload=true"); err != nil {
+		funcLog.Error(err, "ConfigureOVSDPDKVFs(): failed to enable the hardware offload of OVS", "stderr", stderr)
+		return fmt.Errorf("failed to enable the hardware offload of OVS: %v", err)
+	}
+	configured := map[int]string{}
+	for _, vfID := range sortedKeys(representorMap) {
+		if vfID < 0 || vfID >= vfCount {
+			funcLog.Info("ConfigureOVSDPDKVFs(): VF not found, skip", "vfID", vfID, "numVfs", vfCount)
+			continue
+		}
+		port := representorMap[vfID]
+		if err := configureOVSDPDKVF(cmd, pf, pciAddress, bridge, vfID, port); err != nil {
+			if cleanupErr := CleanupOVSDPDKVFs(cmd, pf, configured); cleanupErr != nil {
+				funcLog.Error(cleanupErr, "ConfigureOVSDPDKVFs(): failed to clean up the configured VFs")
+			}
+			return err
+		}
+		configured[vfID] = port
+	}
+	return nil
+}
+
+// CleanupOVSDPDKVFs removes the OVS ports of the representor map and the TC flower rules added on the
+// representors of the VFs by ConfigureOVSDPDKVFs. The ports which don't exist are ignored, the failures
+// to delete the TC rules are only logged as the representors are removed with the VFs.
+func CleanupOVSDPDKVFs(cmd CmdInterface, pf string, representorMap map[int]string) error {
+	funcLog := log.Log.WithValues("pf", pf)
+	for _, vfID := range sortedKeys(representorMap) {
+		port := representorMap[vfID]
+		funcLog.Info("CleanupOVSDPDKVFs(): remove the OVS port of the VF", "vfID", vfID, "port", port)
+		if _, stderr, err := cmd.RunCommand("ovs-vsctl", "--if-exists", "del-port", port); err != nil {
+			funcLog.Error(err, "CleanupOVSDPDKVFs(): failed to remove the OVS port", "port", port, "stderr", stderr)
+			return fmt.Errorf("failed to remove OVS port %s of VF %d of %s: %v", port, vfID, pf, err)
+		}
+		representor := getVFRepresentor(pf, vfID)
+		if representor == "" {
+			continue
+		}
+		if _, stderr, err := cmd.RunCommand("tc", "filter", "del", "dev", representor, "ingress", "pref", ovsDPDKFilterPref); err != nil {
+			funcLog.Info("CleanupOVSDPDKVFs(): failed to remove the TC rule of the representor", "representor", representor,
+				"error", err, "stderr", stderr)
+		}
+	}
+	return nil
+}
+
+// configureOVSDPDKVF adds the TC flower rule on the ingress of the representor of the VF and adds the
+// representor to the bridge as a DPDK port
+func configureOVSDPDKVF(cmd CmdInterface, pf, pciAddress, bridge string, vfID int, port string) error {
+	funcLog := log.Log.WithValues("pf", pf, "vfID", vfID)
+	representor := getVFRepresentor(pf, vfID)
+	if representor == "" {
+		return fmt.Errorf("failed to find the representor of VF %d of %s", vfID, pf)
+	}
+	stdout, stderr, err := cmd.RunCommand("tc", "qdisc", "show", "dev", representor, "ingress")
+	if err != nil {
+		funcLog.Error(err, "configureOVSDPDKVF(): failed to read the ingress qdisc", "representor", representor, "stderr", stderr)
+		return fmt.Errorf("failed to read the ingress qdisc of representor %s: %v", representor, err)
+	}
+	if strings.TrimSpace(stdout) == "" {
+		if _, stderr, err := cmd.RunCommand("tc", "qdisc", "add", "dev", representor, "ingress"); err != nil {
+			funcLog.Error(err, "configureOVSDPDKVF(): failed to add the ingress qdisc", "representor", representor, "stderr", stderr)
+			return fmt.Errorf("failed to add the ingress qdisc of representor %s: %v", representor, err)
+		}
+	}
+	if _, stderr, err := cmd.RunCommand("tc", "filter", "replace", "dev", representor, "ingress", "pref", ovsDPDKFilterPref,
+		"protocol", "all", "flower", "skip_sw", "action", "mirred", "egress", "redirect", "dev", pf); err != nil {
+		funcLog.Error(err, "configureOVSDPDKVF(): failed to add the TC rule", "representor", representor, "stderr", stderr)
+		return fmt.Errorf("failed to add the TC rule of representor %s: %v", representor, err)
+	}
+	funcLog.Info("configureOVSDPDKVF(): add the representor to the OVS bridge", "bridge", bridge, "port", port)
+	if _, stderr, err := cmd.RunCommand("ovs-vsctl", "--may-exist", "add-port", bridge, port,
+		"--", "set", "Interface", port, "type=dpdk", fmt.Sprintf("options:dpdk-devargs=%s,representor=[%d]", pciAddress, vfID)); err != nil {
+		funcLog.Error(err, "configureOVSDPDKVF(): failed to add the OVS port", "port", port, "stderr", stderr)
+		return fmt.Errorf("failed to add OVS port %s of VF %d of %s to bridge %s: %v", port, vfID, pf, bridge, err)
+	}
+	return nil
+}
+
+// getOVSDPDKBridge returns the name of the OVS bridge which contains the DPDK port of the PF
+func getOVSDPDKBridge(cmd CmdInterface, pciAddress string) (string, error) {
+	stdout, stderr, err := cmd.RunCommand("ovs-vsctl", "--bare", "--columns=name", "find", "Interface",
+		"options:dpdk-devargs="+pciAddress)
+	if err != nil {
+		log.Log.Error(err, "getOVSDPDKBridge(): failed to find the DPDK port of the PF", "device", pciAddress, "stderr", stderr)
+		return "", fmt.Errorf("failed to find the OVS DPDK port of %s: %v", pciAddress, err)
+	}
+	ports := strings.Fields(stdout)
+	if len(ports) == 0 {
+		return "", fmt.Errorf("%s is not a DPDK port of an OVS bridge", pciAddress)
+	}
+	stdout, stderr, err = cmd.RunCommand("ovs-vsctl", "iface-to-br", ports[0])
+	if err != nil {
+		log.Log.Error(err, "getOVSDPDKBridge(): failed to find the bridge of the port", "port", ports[0], "stderr", stderr)
+		return "", fmt.Errorf("failed to find the OVS bridge of port %s: %v", ports[0], err)
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+// getNetDevPciAddress returns the PCI address of the device of the netdevice
+func getNetDevPciAddress(name string) (string, error) {
+	target, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysClassNet, name, "device"))
+	if err != nil {
+		return "", fmt.Errorf("failed to find the PCI address of %s: %v", name, err)
+	}
+	return filepath.Base(target), nil
+}
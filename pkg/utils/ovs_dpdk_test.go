@@ -0,0 +1,120 @@
+package utils_test
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	mock_utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("OVS-DPDK offload", func() {
+	var (
+		testCtrl *gomock.Controller
+		cmd      *mock_utils.MockCmdInterface
+	)
+
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		cmd = mock_utils.NewMockCmdInterface(testCtrl)
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+			Dirs: []string{
+				"/sys/bus/pci/devices/0000:d8:00.0",
+				"/sys/class/net/enp216s0f0np0",
+				"/sys/class/net/eth0",
+				"/sys/class/net/eth1",
+			},
+			Files: map[string][]byte{
+				"/sys/class/net/enp216s0f0np0/phys_switch_id": []byte("7cfe90ff2cc0"),
+				"/sys/class/net/enp216s0f0np0/phys_port_name": []byte("p0"),
+				"/sys/class/net/eth0/phys_switch_id":          []byte("7cfe90ff2cc0"),
+				"/sys/class/net/eth0/phys_port_name":          []byte("pf0vf0"),
+				"/sys/class/net/eth1/phys_switch_id":          []byte("7cfe90ff2cc0"),
+				"/sys/class/net/eth1/phys_port_name":          []byte("pf0vf1"),
+			},
+			Symlinks: map[string]string{
+				"/sys/class/net/enp216s0f0np0/device": "../../../bus/pci/devices/0000:d8:00.0",
+			},
+		})
+	})
+
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	expectBridge := func() {
+		cmd.EXPECT().RunCommand("ovs-vsctl", "--bare", "--columns=name", "find", "Interface",
+			"options:dpdk-devargs=0000:d8:00.0").Return("dpdk0\n", "", nil)
+		cmd.EXPECT().RunCommand("ovs-vsctl", "iface-to-br", "dpdk0").Return("br-phy\n", "", nil)
+		cmd.EXPECT().RunCommand("ovs-vsctl", "set", "Open_vSwitch", ".", "other_config:hw-offload=true").Return("", "", nil)
+	}
+
+	expectTCRule := func(representor string) *gomock.Call {
+		return cmd.EXPECT().RunCommand("tc", "filter", "replace", "dev", representor, "ingress", "pref", "49200",
+			"protocol", "all", "flower", "skip_sw", "action", "mirred", "egress", "redirect", "dev", "enp216s0f0np0")
+	}
+
+	expectAddPort := func(port string, vfID int) *gomock.Call {
+		return cmd.EXPECT().RunCommand("ovs-vsctl", "--may-exist", "add-port", "br-phy", port, "--", "set", "Interface", port,
+			"type=dpdk", fmt.Sprintf("options:dpdk-devargs=0000:d8:00.0,representor=[%d]", vfID))
+	}
+
+	It("should do nothing without representor mapping", func() {
+		Expect(utils.ConfigureOVSDPDKVFs(cmd, "enp216s0f0np0", 2, nil)).To(Succeed())
+	})
+
+	It("should add the TC rules and the OVS ports of the representors", func() {
+		expectBridge()
+		gomock.InOrder(
+			cmd.EXPECT().RunCommand("tc", "qdisc", "show", "dev", "eth0", "ingress").Return("", "", nil),
+			cmd.EXPECT().RunCommand("tc", "qdisc", "add", "dev", "eth0", "ingress").Return("", "", nil),
+			expectTCRule("eth0").Return("", "", nil),
+			expectAddPort("vf0-rep", 0).Return("", "", nil),
+			cmd.EXPECT().RunCommand("tc", "qdisc", "show", "dev", "eth1", "ingress").Return(
+				"qdisc ingress ffff: parent ffff:fff1 ----------------", "", nil),
+			expectTCRule("eth1").Return("", "", nil),
+			expectAddPort("vf1-rep", 1).Return("", "", nil),
+		)
+		Expect(utils.ConfigureOVSDPDKVFs(cmd, "enp216s0f0np0", 2,
+			map[int]string{0: "vf0-rep", 1: "vf1-rep", 5: "vf5-rep"})).To(Succeed())
+	})
+
+	It("should fail if the PF is not a DPDK port of an OVS bridge", func() {
+		cmd.EXPECT().RunCommand("ovs-vsctl", "--bare", "--columns=name", "find", "Interface",
+			"options:dpdk-devargs=0000:d8:00.0").Return("", "", nil)
+		Expect(utils.ConfigureOVSDPDKVFs(cmd, "enp216s0f0np0", 2, map[int]string{0: "vf0-rep"})).To(
+			MatchError(ContainSubstring("is not a DPDK port of an OVS bridge")))
+	})
+
+	It("should clean up the configured VFs on failure", func() {
+		expectBridge()
+		gomock.InOrder(
+			cmd.EXPECT().RunCommand("tc", "qdisc", "show", "dev", "eth0", "ingress").Return("qdisc ingress", "", nil),
+			expectTCRule("eth0").Return("", "", nil),
+			expectAddPort("vf0-rep", 0).Return("", "", nil),
+			cmd.EXPECT().RunCommand("tc", "qdisc", "show", "dev", "eth1", "ingress").Return("qdisc ingress", "", nil),
+			expectTCRule("eth1").Return("", "", nil),
+			expectAddPort("vf1-rep", 1).Return("", "no such bridge", fmt.Errorf("exit status 1")),
+			cmd.EXPECT().RunCommand("ovs-vsctl", "--if-exists", "del-port", "vf0-rep").Return("", "", nil),
+			cmd.EXPECT().RunCommand("tc", "filter", "del", "dev", "eth0", "ingress", "pref", "49200").Return("", "", nil),
+		)
+		Expect(utils.ConfigureOVSDPDKVFs(cmd, "enp216s0f0np0", 2, map[int]string{0: "vf0-rep", 1: "vf1-rep"})).To(
+			MatchError(ContainSubstring("failed to add OVS port vf1-rep")))
+	})
+
+	It("should remove the OVS ports and ignore the TC rule errors on cleanup", func() {
+		gomock.InOrder(
+			cmd.EXPECT().RunCommand("ovs-vsctl", "--if-exists", "del-port", "vf0-rep").Return("", "", nil),
+			cmd.EXPECT().RunCommand("tc", "filter", "del", "dev", "eth0", "ingress", "pref", "49200").Return(
+				"", "Cannot find specified filter chain", fmt.Errorf("exit status 2")),
+			// the representor of VF 3 doesn't exist anymore
+			cmd.EXPECT().RunCommand("ovs-vsctl", "--if-exists", "del-port", "vf3-rep").Return("", "", nil),
+		)
+		Expect(utils.CleanupOVSDPDKVFs(cmd, "enp216s0f0np0", map[int]string{0: "vf0-rep", 3: "vf3-rep"})).To(Succeed())
+	})
+})
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// vfVLANPruningPrivFlag is the private flag of the PF drivers (i40e, ice) which drops the frames of the VLANs
+// not registered by the VF, with a port VLAN set the VF then receives only the inner VLANs it registered
+const vfVLANPruningPrivFlag = "vf-vlan-pruning"
+
+// ConfigureVFQinQ configures the 802.1ad double VLAN of the VF: the outer VLAN (S-TAG) is programmed on the VF
+// as an 802.1ad port VLAN through the PF, the PF inserts and strips it, and the inner VLAN (C-TAG) is carried by
+// the frames of the VF. The VLAN pruning of the VFs is enabled with the private flag of the PF driver, if the
+// driver has one, so that the VF receives only the inner VLANs it registered. The PF can't push the inner VLAN,
+// the workload must use the inner VLAN on the VF netdevice.
+func ConfigureVFQinQ(cmd CmdInterface, pf string, vfID int, outerVID, innerVID uint16) error {
+	funcLog := log.Log.WithValues("pf", pf, "vfID", vfID)
+	for _, vid := range []uint16{outerVID, innerVID} {
+		if vid < 1 || vid > 4094 {
+			return fmt.Errorf("VLAN %d of VF %d of %s is out of range [1-4094]", vid, vfID, pf)
+		}
+	}
+	funcLog.Info("ConfigureVFQinQ(): set the outer VLAN of the VF", "outerVlan", outerVID, "innerVlan", innerVID)
+	if _, stderr, err := cmd.RunCommand("ip", "link", "set", pf, "vf", strconv.Itoa(vfID), "vlan", strconv.Itoa(int(outerVID)),
+		"qos", "0", "proto", "802.1ad"); err != nil {
+		funcLog.Error(err, "ConfigureVFQinQ(): failed to set the outer VLAN of the VF", "stderr", stderr)
+		return fmt.Errorf("failed to set outer VLAN %d of VF %d of %s: %v", outerVID, vfID, pf, err)
+	}
+	return enableVFVLANPruning(cmd, pf)
+}
+
+// enableVFVLANPruning enables the VLAN pruning of the VFs of the PF, nothing is done if the PF driver
+// doesn't have the private flag or if it is already enabled
+func enableVFVLANPruning(cmd CmdInterface, pf string) error {
+	stdout, stderr, err := cmd.RunCommand("ethtool", "--show-priv-flags", pf)
+	if err != nil {
+		log.Log.Error(err, "enableVFVLANPruning(): failed to read the private flags of the PF", "pf", pf, "stderr", stderr)
+		return fmt.Errorf("failed to read the private flags of %s: %v", pf, err)
+	}
+	enabled, found := parsePrivFlag(stdout, vfVLANPruningPrivFlag)
+	if !found {
+		log.Log.V(2).Info("enableVFVLANPruning(): the PF driver has no VF VLAN pruning flag, skip", "pf", pf)
+		return nil
+	}
+	if enabled {
+		return nil
+	}
+	if _, stderr, err := cmd.RunCommand("ethtool", "--set-priv-flags", pf, vfVLANPruningPrivFlag, "on"); err != nil {
+		log.Log.Error(err, "enableVFVLANPruning(): failed to enable the VLAN pruning of the VFs", "pf", pf, "stderr", stderr)
+		return fmt.Errorf("failed to enable %s on %s: %v", vfVLANPruningPrivFlag, pf, err)
+	}
+	return nil
+}
+
+// parsePrivFlag returns the state of the private flag from "ethtool --show-priv-flags" output
+// and false if the flag is not in the output
+func parsePrivFlag(output, flag string) (enabled bool, found bool) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) != flag {
+			continue
+		}
+		return strings.TrimSpace(value) == "on", true
+	}
+	return false, false
+}
@@ -0,0 +1,71 @@
+package utils_test
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	mock_utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+)
+
+var _ = Describe("ConfigureVFQinQ", func() {
+	var (
+		testCtrl *gomock.Controller
+		cmd      *mock_utils.MockCmdInterface
+	)
+
+	BeforeEach(func() {
+		testCtrl = gomock.NewController(GinkgoT())
+		cmd = mock_utils.NewMockCmdInterface(testCtrl)
+	})
+
+	AfterEach(func() {
+		testCtrl.Finish()
+	})
+
+	expectOuterVLAN := func() *gomock.Call {
+		return cmd.EXPECT().RunCommand("ip", "link", "set", "ens1f0", "vf", "2", "vlan", "100", "qos", "0", "proto", "802.1ad")
+	}
+
+	It("should set the outer VLAN and enable the VLAN pruning of the VFs", func() {
+		gomock.InOrder(
+			expectOuterVLAN().Return("", "", nil),
+			cmd.EXPECT().RunCommand("ethtool", "--show-priv-flags", "ens1f0").Return(
+				"Private flags for ens1f0:\nlegacy-rx       : off\nvf-vlan-pruning : off\n", "", nil),
+			cmd.EXPECT().RunCommand("ethtool", "--set-priv-flags", "ens1f0", "vf-vlan-pruning", "on").Return("", "", nil),
+		)
+		Expect(utils.ConfigureVFQinQ(cmd, "ens1f0", 2, 100, 10)).To(Succeed())
+	})
+
+	It("should not change the VLAN pruning if it is enabled", func() {
+		gomock.InOrder(
+			expectOuterVLAN().Return("", "", nil),
+			cmd.EXPECT().RunCommand("ethtool", "--show-priv-flags", "ens1f0").Return(
+				"Private flags for ens1f0:\nvf-vlan-pruning : on\n", "", nil),
+		)
+		Expect(utils.ConfigureVFQinQ(cmd, "ens1f0", 2, 100, 10)).To(Succeed())
+	})
+
+	It("should only set the outer VLAN if the driver has no VLAN pruning flag", func() {
+		gomock.InOrder(
+			expectOuterVLAN().Return("", "", nil),
+			cmd.EXPECT().RunCommand("ethtool", "--show-priv-flags", "ens1f0").Return(
+				"Private flags for ens1f0:\nrx_cqe_moder     : on\n", "", nil),
+		)
+		Expect(utils.ConfigureVFQinQ(cmd, "ens1f0", 2, 100, 10)).To(Succeed())
+	})
+
+	It("should fail if the outer VLAN can't be set", func() {
+		expectOuterVLAN().Return("", "RTNETLINK answers: Protocol not supported", fmt.Errorf("exit status 2"))
+		Expect(utils.ConfigureVFQinQ(cmd, "ens1f0", 2, 100, 10)).To(
+			MatchError(ContainSubstring("failed to set outer VLAN 100 of VF 2 of ens1f0")))
+	})
+
+	It("should reject the VLANs out of range", func() {
+		Expect(utils.ConfigureVFQinQ(cmd, "ens1f0", 2, 100, 4095)).To(
+			MatchError(ContainSubstring("VLAN 4095 of VF 2 of ens1f0 is out of range")))
+	})
+})
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RSSHashKeyLengths are the lengths in bytes of the RSS hash keys supported by the NICs,
+// 40 bytes for the Toeplitz key of most of the drivers and 52 bytes for the i40e and ice drivers
+var RSSHashKeyLengths = []int{40, 52}
+
+// RSSHashFields are the hash fields which can be requested for the VFs, in the order they are configured,
+// with the ethtool flow type of each field. The traffic of a requested flow type is hashed on the IP
+// addresses and the L4 ports, the traffic of the other flow types only on the IP addresses.
+var RSSHashFields = []struct {
+	Name     string
+	FlowType string
+}{
+	{Name: "ipv4-tcp", FlowType: "tcp4"},
+	{Name: "ipv4-udp", FlowType: "udp4"},
+	{Name: "ipv4-sctp", FlowType: "sctp4"},
+	{Name: "ipv6-tcp", FlowType: "tcp6"},
+	{Name: "ipv6-udp", FlowType: "udp6"},
+	{Name: "ipv6-sctp", FlowType: "sctp6"},
+}
+
+// ethtool rx-flow-hash fields: IP source and destination addresses, with and without the L4 ports
+const (
+	rssHashL3   = "sd"
+	rssHashL3L4 = "sdfn"
+)
+
+// NormalizeRSSHashKey validates the RSS hash key, a hex string with or without colons between the bytes,
+// and returns it in the ethtool format: lowercase bytes separated by colons
+func NormalizeRSSHashKey(key string) (string, error) {
+	raw, err := hex.DecodeString(strings.ReplaceAll(key, ":", ""))
+	if err != nil {
+		return "", fmt.Errorf("RSS hash key is not a hex string: %v", err)
+	}
+	valid := false
+	for _, length := range RSSHashKeyLengths {
+		if len(raw) == length {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", fmt.Errorf("RSS hash key has %d bytes, supported lengths are %v", len(raw), RSSHashKeyLengths)
+	}
+	bytes := make([]string, len(raw))
+	for i, b := range raw {
+		bytes[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(bytes, ":"), nil
+}
+
+// IsRSSHashField returns true if the hash field is supported
+func IsRSSHashField(field string) bool {
+	for _, f := range RSSHashFields {
+		if f.Name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigureVFRSS configures the RSS of the VF netdevice with ethtool: the hash key is set with
+// "ethtool --set-rxfh <vf> hkey <key>" and the traffic of the flow types of the hash fields is hashed
+// on the L4 ports in addition to the IP addresses, the traffic of the other flow types only on the IP addresses.
+// The hash key is not changed if it is empty and the hashing of the flow types is not changed if there is no field.
+// A flow type which is not requested and which the driver can't hash is skipped.
+func ConfigureVFRSS(cmd CmdInterface, pfName string, vfID int, hashKey string, fields []string) error {
+	funcLog := log.Log.WithValues("pf", pfName, "vfID", vfID)
+	for _, field := range fields {
+		if !IsRSSHashField(field) {
+			return fmt.Errorf("RSS hash field %q of VF %d of %s is not supported", field, vfID, pfName)
+		}
+	}
+	vfName, err := getVFNetdev(pfName, vfID)
+	if err != nil {
+		return err
+	}
+	funcLog = funcLog.WithValues("vf", vfName)
+
+	if hashKey != "" {
+		key, err := NormalizeRSSHashKey(hashKey)
+		if err != nil {
+			return fmt.Errorf("invalid RSS hash key of VF %d of %s: %v", vfID, pfName, err)
+		}
+		funcLog.Info("ConfigureVFRSS(): set the RSS hash key of the VF")
+		if _, stderr, err := cmd.RunCommand("ethtool", "--set-rxfh", vfName, "hkey", key); err != nil {
+			funcLog.Error(err, "ConfigureVFRSS(): failed to set the RSS hash key", "stderr", stderr)
+			return fmt.Errorf("failed to set the RSS hash key of %s: %v", vfName, err)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	funcLog.Info("ConfigureVFRSS(): set the RSS hash fields of the VF", "fields", fields)
+	for _, f := range RSSHashFields {
+		hash := rssHashL3
+		if slices.Contains(fields, f.Name) {
+			hash = rssHashL3L4
+		}
+		_, stderr, err := cmd.RunCommand("ethtool", "-N", vfName, "rx-flow-hash", f.FlowType, hash)
+		if err == nil {
+			continue
+		}
+		if hash == rssHashL3 {
+			// the drivers don't support the hashing of all the flow types, e.g. SCTP
+			funcLog.V(2).Info("ConfigureVFRSS(): failed to set the RSS hash of the flow type which is not requested, skip",
+				"flowType", f.FlowType, "error", err.Error(), "stderr", stderr)
+			continue
+		}
+		funcLog.Error(err, "ConfigureVFRSS(): failed to set the RSS hash of the flow type", "flowType", f.FlowType, "stderr", stderr)
+		return fmt.Errorf("failed to set the RSS hash of %s flows of %s: %v", f.FlowType, vfName, err)
+	}
+	return nil
+}
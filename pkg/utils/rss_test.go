@@ -0,0 +1,122 @@
+package utils_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	mock_utils "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils/mock"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("RSS", func() {
+	// 40 bytes Toeplitz key
+	key40 := strings.Repeat("6d5a", 20)
+	key40Ethtool := strings.TrimSuffix(strings.Repeat("6d:5a:", 20), ":")
+
+	Context("NormalizeRSSHashKey", func() {
+		It("should return the key in the ethtool format", func() {
+			Expect(utils.NormalizeRSSHashKey(strings.ToUpper(key40))).To(Equal(key40Ethtool))
+			Expect(utils.NormalizeRSSHashKey(key40Ethtool)).To(Equal(key40Ethtool))
+		})
+
+		It("should accept 52 bytes keys", func() {
+			Expect(utils.NormalizeRSSHashKey(strings.Repeat("ab", 52))).To(HaveLen(52*3 - 1))
+		})
+
+		It("should reject the keys which are not hex", func() {
+			_, err := utils.NormalizeRSSHashKey(strings.Repeat("zz", 40))
+			Expect(err).To(MatchError(ContainSubstring("not a hex string")))
+		})
+
+		It("should reject the keys of unsupported length", func() {
+			_, err := utils.NormalizeRSSHashKey(strings.Repeat("ab", 32))
+			Expect(err).To(MatchError("RSS hash key has 32 bytes, supported lengths are [40 52]"))
+		})
+	})
+
+	Context("ConfigureVFRSS", func() {
+		var (
+			testCtrl *gomock.Controller
+			cmd      *mock_utils.MockCmdInterface
+		)
+
+		BeforeEach(func() {
+			testCtrl = gomock.NewController(GinkgoT())
+			cmd = mock_utils.NewMockCmdInterface(testCtrl)
+			helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{
+				Dirs: []string{
+					"/sys/class/net/enp216s0f0np0/device/virtfn1/net/enp216s0f0v1",
+					"/sys/class/net/enp216s0f0np0/device/virtfn2/net",
+				},
+			})
+		})
+
+		AfterEach(func() {
+			testCtrl.Finish()
+		})
+
+		expectFlowHash := func(flowType, hash string) *gomock.Call {
+			return cmd.EXPECT().RunCommand("ethtool", "-N", "enp216s0f0v1", "rx-flow-hash", flowType, hash)
+		}
+
+		It("should set the hash key and the hash fields of the VF", func() {
+			gomock.InOrder(
+				cmd.EXPECT().RunCommand("ethtool", "--set-rxfh", "enp216s0f0v1", "hkey", key40Ethtool).Return("", "", nil),
+				expectFlowHash("tcp4", "sdfn").Return("", "", nil),
+				expectFlowHash("udp4", "sdfn").Return("", "", nil),
+				expectFlowHash("sctp4", "sd").Return("", "", nil),
+				expectFlowHash("tcp6", "sd").Return("", "", nil),
+				expectFlowHash("udp6", "sdfn").Return("", "", nil),
+				expectFlowHash("sctp6", "sd").Return("", "", nil),
+			)
+			Expect(utils.ConfigureVFRSS(cmd, "enp216s0f0np0", 1, key40, []string{"ipv4-tcp", "ipv4-udp", "ipv6-udp"})).To(Succeed())
+		})
+
+		It("should only set the hash key without hash fields", func() {
+			cmd.EXPECT().RunCommand("ethtool", "--set-rxfh", "enp216s0f0v1", "hkey", key40Ethtool).Return("", "", nil)
+			Expect(utils.ConfigureVFRSS(cmd, "enp216s0f0np0", 1, key40, nil)).To(Succeed())
+		})
+
+		It("should skip the flow types which are not requested and not supported by the driver", func() {
+			gomock.InOrder(
+				expectFlowHash("tcp4", "sdfn").Return("", "", nil),
+				expectFlowHash("udp4", "sd").Return("", "", nil),
+				expectFlowHash("sctp4", "sd").Return("", "Cannot change RX network flow hashing options: Operation not supported", fmt.Errorf("exit status 1")),
+				expectFlowHash("tcp6", "sd").Return("", "", nil),
+				expectFlowHash("udp6", "sd").Return("", "", nil),
+				expectFlowHash("sctp6", "sd").Return("", "Cannot change RX network flow hashing options: Operation not supported", fmt.Errorf("exit status 1")),
+			)
+			Expect(utils.ConfigureVFRSS(cmd, "enp216s0f0np0", 1, "", []string{"ipv4-tcp"})).To(Succeed())
+		})
+
+		It("should fail if a requested flow type can't be hashed on the L4 ports", func() {
+			expectFlowHash("tcp4", "sdfn").Return("", "Cannot change RX network flow hashing options: Operation not supported", fmt.Errorf("exit status 1"))
+			Expect(utils.ConfigureVFRSS(cmd, "enp216s0f0np0", 1, "", []string{"ipv4-tcp"})).To(
+				MatchError(ContainSubstring("failed to set the RSS hash of tcp4 flows of enp216s0f0v1")))
+		})
+
+		It("should fail if the hash key can't be set", func() {
+			cmd.EXPECT().RunCommand("ethtool", "--set-rxfh", "enp216s0f0v1", "hkey", key40Ethtool).Return("", "", fmt.Errorf("exit status 1"))
+			Expect(utils.ConfigureVFRSS(cmd, "enp216s0f0np0", 1, key40, nil)).To(
+				MatchError(ContainSubstring("failed to set the RSS hash key of enp216s0f0v1")))
+		})
+
+		It("should reject the invalid configuration", func() {
+			Expect(utils.ConfigureVFRSS(cmd, "enp216s0f0np0", 1, "abcd", nil)).To(
+				MatchError(ContainSubstring("invalid RSS hash key of VF 1 of enp216s0f0np0")))
+			Expect(utils.ConfigureVFRSS(cmd, "enp216s0f0np0", 1, "", []string{"ipv4-icmp"})).To(
+				MatchError(ContainSubstring(`RSS hash field "ipv4-icmp" of VF 1 of enp216s0f0np0 is not supported`)))
+		})
+
+		It("should fail if the VF has no netdevice", func() {
+			Expect(utils.ConfigureVFRSS(cmd, "enp216s0f0np0", 2, key40, nil)).To(
+				MatchError(ContainSubstring("VF 2 of enp216s0f0np0 has no netdevice in the host network namespace")))
+		})
+	})
+})
@@ -2,11 +2,10 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
-	"syscall"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -20,6 +19,11 @@ type CmdInterface interface {
 	RunCommand(string, ...string) (string, string, error)
 }
 
+// ContextCmdInterface runs commands which are killed when the context is done
+type ContextCmdInterface interface {
+	RunCommandContext(ctx context.Context, command string, args ...string) (string, string, error)
+}
+
 type utilsHelper struct {
 }
 
@@ -27,34 +31,17 @@ func New() CmdInterface {
 	return &utilsHelper{}
 }
 
-func (u *utilsHelper) Chroot(path string) (func() error, error) {
-	root, err := os.Open("/")
-	if err != nil {
-		return nil, err
-	}
-
-	if err := syscall.Chroot(path); err != nil {
-		root.Close()
-		return nil, err
-	}
-	vars.InChroot = true
-
-	return func() error {
-		defer root.Close()
-		if err := root.Chdir(); err != nil {
-			return err
-		}
-		vars.InChroot = false
-		return syscall.Chroot(".")
-	}, nil
-}
-
 // RunCommand runs a command
 func (u *utilsHelper) RunCommand(command string, args ...string) (string, string, error) {
+	return u.RunCommandContext(context.Background(), command, args...)
+}
+
+// RunCommandContext runs a command, the command is killed if the context is done before it completes
+func (u *utilsHelper) RunCommandContext(ctx context.Context, command string, args ...string) (string, string, error) {
 	log.Log.Info("RunCommand()", "command", command, "args", args)
 	var stdout, stderr bytes.Buffer
 
-	cmd := exec.Command(command, args...)
+	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
@@ -63,15 +50,6 @@ func (u *utilsHelper) RunCommand(command string, args ...string) (string, string
 	return stdout.String(), stderr.String(), err
 }
 
-func IsCommandNotFound(err error) bool {
-	if exitErr, ok := err.(*exec.ExitError); ok {
-		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == 127 {
-			return true
-		}
-	}
-	return false
-}
-
 func GetHostExtension() string {
 	if vars.InChroot {
 		return vars.FilesystemRoot
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+var (
+	pciAddressRe   = regexp.MustCompile(`^[0-9a-fA-F]{4}:[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-7]$`)
+	resourceNameRe = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
+// ValidateNodeStateSpec checks the SriovNetworkNodeState spec for malformed values which would otherwise
+// fail later in the kernel with errors that are hard to understand. All found problems are returned.
+// The node state spec has no MAC address fields, MAC addresses are validated by the CNI.
+func ValidateNodeStateSpec(state *sriovnetworkv1.SriovNetworkNodeState) []error {
+	errs := []error{}
+	seen := map[string]bool{}
+	for _, iface := range state.Spec.Interfaces {
+		if !pciAddressRe.MatchString(iface.PciAddress) {
+			errs = append(errs, fmt.Errorf("interface %q: invalid PCI address format", iface.PciAddress))
+		}
+		if seen[iface.PciAddress] {
+			errs = append(errs, fmt.Errorf("interface %q: duplicate PCI address", iface.PciAddress))
+		}
+		seen[iface.PciAddress] = true
+
+		if iface.NumVfs < 0 {
+			errs = append(errs, fmt.Errorf("interface %q: invalid number of VFs %d", iface.PciAddress, iface.NumVfs))
+		} else if ifaceStatus := state.GetInterfaceStateByPciAddress(iface.PciAddress); ifaceStatus != nil {
+			if err := validateInterfaceVfCount(ifaceStatus, iface.NumVfs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		for _, group := range iface.VfGroups {
+			if group.ResourceName != "" && !resourceNameRe.MatchString(group.ResourceName) {
+				errs = append(errs, fmt.Errorf("interface %q: invalid resource name %q of VF group %s, only "+
+					"alphanumeric characters and underscores are allowed", iface.PciAddress, group.ResourceName, group.PolicyName))
+			}
+		}
+	}
+	errs = append(errs, ValidateSiblingVfBudgets(state)...)
+	return errs
+}
@@ -0,0 +1,72 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+var _ = Describe("ValidateNodeStateSpec", func() {
+	var nodeState *sriovnetworkv1.SriovNetworkNodeState
+	BeforeEach(func() {
+		nodeState = &sriovnetworkv1.SriovNetworkNodeState{
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+				Interfaces: sriovnetworkv1.Interfaces{{
+					PciAddress: "0000:86:00.0",
+					NumVfs:     8,
+					VfGroups: []sriovnetworkv1.VfGroup{{
+						PolicyName:   "p0",
+						ResourceName: "intel_nics",
+						VfRange:      "0-7",
+					}},
+				}, {
+					PciAddress: "0000:86:00.1",
+					NumVfs:     4,
+				}},
+			},
+			Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+				Interfaces: sriovnetworkv1.InterfaceExts{{
+					PciAddress: "0000:86:00.0",
+					Vendor:     "8086",
+					TotalVfs:   16,
+				}},
+			},
+		}
+	})
+	It("should accept valid spec", func() {
+		Expect(utils.ValidateNodeStateSpec(nodeState)).To(BeEmpty())
+	})
+	It("should reject invalid PCI address", func() {
+		nodeState.Spec.Interfaces[1].PciAddress = "86:00.1"
+		Expect(utils.ValidateNodeStateSpec(nodeState)).To(ConsistOf(
+			MatchError(`interface "86:00.1": invalid PCI address format`)))
+	})
+	It("should reject duplicate PCI address", func() {
+		nodeState.Spec.Interfaces[1].PciAddress = "0000:86:00.0"
+		Expect(utils.ValidateNodeStateSpec(nodeState)).To(ConsistOf(
+			MatchError(`interface "0000:86:00.0": duplicate PCI address`)))
+	})
+	It("should reject negative number of VFs", func() {
+		nodeState.Spec.Interfaces[1].NumVfs = -1
+		Expect(utils.ValidateNodeStateSpec(nodeState)).To(ConsistOf(
+			MatchError(`interface "0000:86:00.1": invalid number of VFs -1`)))
+	})
+	It("should reject number of VFs exceeding the hardware limit", func() {
+		nodeState.Spec.Interfaces[0].NumVfs = 32
+		Expect(utils.ValidateNodeStateSpec(nodeState)).To(ConsistOf(
+			Equal(&utils.VfCountError{PciAddress: "0000:86:00.0", Requested: 32, TotalVfs: 16})))
+	})
+	It("should reject invalid resource name", func() {
+		nodeState.Spec.Interfaces[0].VfGroups[0].ResourceName = "intel-nics"
+		Expect(utils.ValidateNodeStateSpec(nodeState)).To(ConsistOf(
+			MatchError(ContainSubstring(`invalid resource name "intel-nics" of VF group p0`))))
+	})
+	It("should return all errors", func() {
+		nodeState.Spec.Interfaces[0].VfGroups[0].ResourceName = "intel/nics"
+		nodeState.Spec.Interfaces[1].PciAddress = "0000:86:00"
+		nodeState.Spec.Interfaces[1].NumVfs = -4
+		Expect(utils.ValidateNodeStateSpec(nodeState)).To(HaveLen(3))
+	})
+})
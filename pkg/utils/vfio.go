@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// vfioGroupDrivers are the drivers which keep a device of an IOMMU group from being used by the host,
+// a group can be used through VFIO only if all its devices are bound to one of them
+var vfioGroupDrivers = []string{consts.DeviceTypeVfioPci, "pci-stub"}
+
+// VFIOGroupError is returned when the IOMMU group of a device bound to vfio-pci can't be used by the workloads,
+// opening the device fails at runtime with VFIO_GROUP_GET_DEVICE_FD errors
+type VFIOGroupError struct {
+	PciAddress string
+	Group      int
+	// Conflicts are the other devices of the group which are not bound to vfio-pci or pci-stub
+	Conflicts []string
+	// Err is set if the VFIO device node of the group is missing
+	Err error
+}
+
+func (e *VFIOGroupError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("IOMMU group %d of device %s is not usable: %v", e.Group, e.PciAddress, e.Err)
+	}
+	return fmt.Sprintf("IOMMU group %d of device %s is not usable, devices %s of the group are not bound to %s",
+		e.Group, e.PciAddress, strings.Join(e.Conflicts, ", "), strings.Join(vfioGroupDrivers, " or "))
+}
+
+func (e *VFIOGroupError) Unwrap() error {
+	return e.Err
+}
+
+// GetIOMMUGroup returns the IOMMU group of the PCI device
+func GetIOMMUGroup(pciAddr string) (int, error) {
+	link, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "iommu_group"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the IOMMU group of device %s: %v", pciAddr, err)
+	}
+	group, err := strconv.Atoi(filepath.Base(link))
+	if err != nil {
+		return 0, fmt.Errorf("invalid IOMMU group %q of device %s", filepath.Base(link), pciAddr)
+	}
+	return group, nil
+}
+
+// CheckVFIOGroup verifies that the IOMMU group of the device bound to vfio-pci can be used by the workloads:
+// the VFIO device node of the group exists and the other devices of the group are bound to vfio-pci or pci-stub.
+// The group is returned with a *VFIOGroupError if it is not usable.
+func CheckVFIOGroup(pciAddr string) (int, error) {
+	group, err := GetIOMMUGroup(pciAddr)
+	if err != nil {
+		return 0, err
+	}
+	groupName := strconv.Itoa(group)
+	if _, err := os.Stat(filepath.Join(vars.FilesystemRoot, consts.VfioDevDir, groupName)); err != nil {
+		return group, &VFIOGroupError{PciAddress: pciAddr, Group: group,
+			Err: fmt.Errorf("VFIO device node %s is missing: %w", filepath.Join(consts.VfioDevDir, groupName), err)}
+	}
+	devices, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysKernelIommuGroups, groupName, "devices"))
+	if err != nil {
+		return group, fmt.Errorf("failed to list the devices of IOMMU group %d: %v", group, err)
+	}
+	var conflicts []string
+	for _, device := range devices {
+		if device.Name() == pciAddr {
+			continue
+		}
+		driver, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, device.Name(), "driver"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return group, fmt.Errorf("failed to read the driver of device %s: %v", device.Name(), err)
+		}
+		// devices without driver don't prevent the use of the group
+		if err == nil && !slices.Contains(vfioGroupDrivers, filepath.Base(driver)) {
+			conflicts = append(conflicts, device.Name())
+		}
+	}
+	if len(conflicts) > 0 {
+		return group, &VFIOGroupError{PciAddress: pciAddr, Group: group, Conflicts: conflicts}
+	}
+	return group, nil
+}
@@ -0,0 +1,60 @@
+package utils_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/fakefilesystem"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/test/util/helpers"
+)
+
+var _ = Describe("CheckVFIOGroup", func() {
+	// fakeIOMMUGroup creates the IOMMU group 42 which contains the devices with their driver, empty for no driver
+	fakeIOMMUGroup := func(devNode bool, drivers map[string]string) {
+		fs := &fakefilesystem.FS{Symlinks: map[string]string{}}
+		for device, driver := range drivers {
+			fs.Dirs = append(fs.Dirs, "/sys/bus/pci/devices/"+device, "/sys/kernel/iommu_groups/42/devices/"+device)
+			fs.Symlinks["/sys/bus/pci/devices/"+device+"/iommu_group"] = "../../../../kernel/iommu_groups/42"
+			if driver != "" {
+				fs.Symlinks["/sys/bus/pci/devices/"+device+"/driver"] = "../../../../bus/pci/drivers/" + driver
+			}
+		}
+		if devNode {
+			fs.Files = map[string][]byte{"/dev/vfio/42": {}}
+			fs.Dirs = append(fs.Dirs, "/dev/vfio")
+		}
+		helpers.GinkgoConfigureFakeFS(fs)
+	}
+
+	It("should return the group of the VF", func() {
+		fakeIOMMUGroup(true, map[string]string{"0000:3b:02.0": "vfio-pci", "0000:3b:02.1": "pci-stub", "0000:3b:02.2": ""})
+		Expect(utils.CheckVFIOGroup("0000:3b:02.0")).To(Equal(42))
+	})
+
+	It("should report the devices of the group bound to host drivers", func() {
+		fakeIOMMUGroup(true, map[string]string{"0000:3b:02.0": "vfio-pci", "0000:3b:00.0": "i40e", "0000:3b:00.1": "i40e"})
+		group, err := utils.CheckVFIOGroup("0000:3b:02.0")
+		Expect(group).To(Equal(42))
+		var groupErr *utils.VFIOGroupError
+		Expect(errors.As(err, &groupErr)).To(BeTrue())
+		Expect(groupErr.Conflicts).To(ConsistOf("0000:3b:00.0", "0000:3b:00.1"))
+		Expect(err).To(MatchError(ContainSubstring(
+			"IOMMU group 42 of device 0000:3b:02.0 is not usable, devices 0000:3b:00.0, 0000:3b:00.1 of the group are not bound to vfio-pci or pci-stub")))
+	})
+
+	It("should fail if the VFIO device node of the group is missing", func() {
+		fakeIOMMUGroup(false, map[string]string{"0000:3b:02.0": "vfio-pci"})
+		group, err := utils.CheckVFIOGroup("0000:3b:02.0")
+		Expect(group).To(Equal(42))
+		Expect(err).To(MatchError(ContainSubstring("VFIO device node /dev/vfio/42 is missing")))
+	})
+
+	It("should fail if the device has no IOMMU group", func() {
+		helpers.GinkgoConfigureFakeFS(&fakefilesystem.FS{Dirs: []string{"/sys/bus/pci/devices/0000:3b:02.0"}})
+		_, err := utils.CheckVFIOGroup("0000:3b:02.0")
+		Expect(err).To(MatchError(ContainSubstring("failed to read the IOMMU group of device 0000:3b:02.0")))
+	})
+})
@@ -0,0 +1,370 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// VfCountError is returned when the number of requested VFs exceeds the number of VFs supported by the PF
+type VfCountError struct {
+	PciAddress string
+	Requested  int
+	TotalVfs   int
+}
+
+func (e *VfCountError) Error() string {
+	return fmt.Sprintf("requested %d VFs for PF %s exceeds the maximum supported by the hardware (%d)",
+		e.Requested, e.PciAddress, e.TotalVfs)
+}
+
+// ValidateVfCount checks that the number of VFs requested by the policy together with
+// the VFs already requested for the same PF by other policies do not exceed TotalVfs of the PF.
+// Mellanox devices are skipped because the vendor plugin updates TotalVfs in the firmware.
+func ValidateVfCount(policy *sriovnetworkv1.SriovNetworkNodePolicy, nodeState *sriovnetworkv1.SriovNetworkNodeState) error {
+	for i := range nodeState.Status.Interfaces {
+		ifaceStatus := &nodeState.Status.Interfaces[i]
+		if !policy.Spec.NicSelector.Selected(ifaceStatus) {
+			continue
+		}
+		requested := policy.Spec.NumVfs
+		for _, iface := range nodeState.Spec.Interfaces {
+			if iface.PciAddress != ifaceStatus.PciAddress {
+				continue
+			}
+			for _, group := range iface.VfGroups {
+				if group.PolicyName != policy.GetName() && iface.NumVfs > requested {
+					requested = iface.NumVfs
+				}
+			}
+		}
+		if err := validateInterfaceVfCount(ifaceStatus, requested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNodeStateVfCount checks that the number of VFs requested for each PF in the
+// node state spec does not exceed TotalVfs of the PF
+func ValidateNodeStateVfCount(nodeState *sriovnetworkv1.SriovNetworkNodeState) error {
+	for _, iface := range nodeState.Spec.Interfaces {
+		ifaceStatus := nodeState.GetInterfaceStateByPciAddress(iface.PciAddress)
+		if ifaceStatus == nil {
+			continue
+		}
+		if err := validateInterfaceVfCount(ifaceStatus, iface.NumVfs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateInterfaceVfCount(ifaceStatus *sriovnetworkv1.InterfaceExt, requested int) error {
+	// TotalVfs is not reported for devices which are not SR-IOV capable yet
+	if ifaceStatus.TotalVfs == 0 || ifaceStatus.Vendor == consts.VendorMellanox {
+		return nil
+	}
+	if requested > ifaceStatus.TotalVfs {
+		return &VfCountError{
+			PciAddress: ifaceStatus.PciAddress,
+			Requested:  requested,
+			TotalVfs:   ifaceStatus.TotalVfs,
+		}
+	}
+	return nil
+}
+
+// SiblingVfBudgetError is returned when the VFs requested for the sibling PFs of a device, the ports of
+// a dual-port NIC which share the firmware VF resources, exceed the VFs of the device
+type SiblingVfBudgetError struct {
+	PciAddresses []string
+	Requested    int
+	Budget       int
+}
+
+func (e *SiblingVfBudgetError) Error() string {
+	return fmt.Sprintf("requested %d VFs for sibling PFs %s exceeds the VFs shared by the PFs of the device (%d)",
+		e.Requested, strings.Join(e.PciAddresses, ", "), e.Budget)
+}
+
+// ValidateSiblingVfBudgets checks that the VFs requested in the node state spec for the sibling PFs
+// of each device don't exceed the VFs shared by the PFs of the device
+func ValidateSiblingVfBudgets(nodeState *sriovnetworkv1.SriovNetworkNodeState) []error {
+	var errs []error
+	for _, err := range validateSiblingVfBudgets(nodeState, func(ifaceStatus *sriovnetworkv1.InterfaceExt) int {
+		return specNumVfs(nodeState, ifaceStatus.PciAddress)
+	}) {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ValidateSiblingVfBudget checks that the VFs requested by the policy for the selected PFs together with
+// the VFs requested in the node state spec for their sibling PFs don't exceed the VFs shared by the PFs of the device
+func ValidateSiblingVfBudget(policy *sriovnetworkv1.SriovNetworkNodePolicy, nodeState *sriovnetworkv1.SriovNetworkNodeState) error {
+	errs := validateSiblingVfBudgets(nodeState, func(ifaceStatus *sriovnetworkv1.InterfaceExt) int {
+		requested := specNumVfs(nodeState, ifaceStatus.PciAddress)
+		if policy.Spec.NicSelector.Selected(ifaceStatus) && policy.Spec.NumVfs > requested {
+			requested = policy.Spec.NumVfs
+		}
+		return requested
+	})
+	for _, err := range errs {
+		for _, pciAddress := range err.PciAddresses {
+			if ifaceStatus := nodeState.GetInterfaceStateByPciAddress(pciAddress); ifaceStatus != nil &&
+				policy.Spec.NicSelector.Selected(ifaceStatus) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateSiblingVfBudgets(nodeState *sriovnetworkv1.SriovNetworkNodeState,
+	requestedVfs func(ifaceStatus *sriovnetworkv1.InterfaceExt) int) []*SiblingVfBudgetError {
+	var errs []*SiblingVfBudgetError
+	seen := map[string]bool{}
+	for i := range nodeState.Status.Interfaces {
+		ifaceStatus := &nodeState.Status.Interfaces[i]
+		if len(ifaceStatus.SiblingPFs) == 0 {
+			continue
+		}
+		pciAddresses := append([]string{ifaceStatus.PciAddress}, ifaceStatus.SiblingPFs...)
+		slices.Sort(pciAddresses)
+		key := strings.Join(pciAddresses, ",")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		requested := 0
+		var siblings []*sriovnetworkv1.InterfaceExt
+		for _, pciAddress := range pciAddresses {
+			if sibling := nodeState.GetInterfaceStateByPciAddress(pciAddress); sibling != nil {
+				siblings = append(siblings, sibling)
+				requested += requestedVfs(sibling)
+			}
+		}
+		if budget := siblingVfBudget(siblings); requested > budget {
+			errs = append(errs, &SiblingVfBudgetError{PciAddresses: pciAddresses, Requested: requested, Budget: budget})
+		}
+	}
+	return errs
+}
+
+// siblingVfBudget returns the number of VFs shared by the sibling PFs: the firmware limit reported by the PFs
+// if one of them is externally managed, otherwise the maximum of the device because the vendor plugin raises
+// the firmware limit when needed
+func siblingVfBudget(siblings []*sriovnetworkv1.InterfaceExt) int {
+	budget := 0
+	for _, sibling := range siblings {
+		if sibling.ExternallyManaged && sibling.TotalVfs > budget {
+			budget = sibling.TotalVfs
+		}
+	}
+	if budget == 0 {
+		budget = consts.MlxMaxVFs
+	}
+	return budget
+}
+
+// specNumVfs returns the number of VFs requested in the node state spec for the PF
+func specNumVfs(nodeState *sriovnetworkv1.SriovNetworkNodeState, pciAddress string) int {
+	for _, iface := range nodeState.Spec.Interfaces {
+		if iface.PciAddress == pciAddress {
+			return iface.NumVfs
+		}
+	}
+	return 0
+}
+
+// VfOvercommitError is returned when the policies selecting a PF collectively request more VFs
+// than the PF supports
+type VfOvercommitError struct {
+	PciAddress string
+	Requested  int
+	TotalVfs   int
+	// Hard is set if the overcommit exceeds the allowed ratio, otherwise the overcommit should only be reported
+	Hard bool
+}
+
+func (e *VfOvercommitError) Error() string {
+	kind := "soft"
+	if e.Hard {
+		kind = "hard"
+	}
+	return fmt.Sprintf("%s overcommit: %d VFs requested for PF %s which supports %d VFs",
+		kind, e.Requested, e.PciAddress, e.TotalVfs)
+}
+
+// VFResourceEnforcer checks that the policies don't collectively request more VFs than supported by the PFs.
+// Policies with overlapping VF ranges are resolved by priority when the node state is rendered,
+// so in case of overcommit some of the policies get less VFs than they request.
+type VFResourceEnforcer struct {
+	// OvercommitRatio is the maximum allowed ratio of the requested VFs to the VFs supported by the PF,
+	// zero means that the overcommit is never hard
+	OvercommitRatio float64
+}
+
+// Enforce returns a hard VfOvercommitError for the first PF with overcommit exceeding the ratio,
+// if there is no such PF a soft VfOvercommitError is returned for the first overcommitted PF.
+// Mellanox devices are skipped because the vendor plugin updates TotalVfs in the firmware.
+func (e *VFResourceEnforcer) Enforce(policies []*sriovnetworkv1.SriovNetworkNodePolicy, status *sriovnetworkv1.SriovNetworkNodeStateStatus) error {
+	return e.enforce(status, func(ifaceStatus *sriovnetworkv1.InterfaceExt) int {
+		requested := 0
+		for _, policy := range policies {
+			if policy.Spec.NicSelector.Selected(ifaceStatus) {
+				requested += policyVfCount(policy, ifaceStatus)
+			}
+		}
+		return requested
+	})
+}
+
+// EnforceNodeState runs the same check on the rendered node state, each VF group requests the VFs of its range
+func (e *VFResourceEnforcer) EnforceNodeState(nodeState *sriovnetworkv1.SriovNetworkNodeState) error {
+	return e.enforce(&nodeState.Status, func(ifaceStatus *sriovnetworkv1.InterfaceExt) int {
+		requested := 0
+		for _, iface := range nodeState.Spec.Interfaces {
+			if iface.PciAddress != ifaceStatus.PciAddress {
+				continue
+			}
+			for _, group := range iface.VfGroups {
+				for vfID := 0; vfID < iface.NumVfs; vfID++ {
+					if sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+						requested++
+					}
+				}
+			}
+		}
+		return requested
+	})
+}
+
+func (e *VFResourceEnforcer) enforce(status *sriovnetworkv1.SriovNetworkNodeStateStatus,
+	requestedVfs func(ifaceStatus *sriovnetworkv1.InterfaceExt) int) error {
+	var softErr error
+	for i := range status.Interfaces {
+		ifaceStatus := &status.Interfaces[i]
+		if ifaceStatus.TotalVfs == 0 || ifaceStatus.Vendor == consts.VendorMellanox {
+			continue
+		}
+		requested := requestedVfs(ifaceStatus)
+		if requested <= ifaceStatus.TotalVfs {
+			continue
+		}
+		err := &VfOvercommitError{
+			PciAddress: ifaceStatus.PciAddress,
+			Requested:  requested,
+			TotalVfs:   ifaceStatus.TotalVfs,
+		}
+		if e.OvercommitRatio > 0 && float64(requested) > float64(ifaceStatus.TotalVfs)*e.OvercommitRatio {
+			err.Hard = true
+			return err
+		}
+		if softErr == nil {
+			softErr = err
+		}
+	}
+	return softErr
+}
+
+// policyVfCount returns the number of VFs requested by the policy for the PF,
+// only the VFs of the range are requested if the PF is selected by name with a VF range
+func policyVfCount(policy *sriovnetworkv1.SriovNetworkNodePolicy, ifaceStatus *sriovnetworkv1.InterfaceExt) int {
+	for _, pfName := range policy.Spec.NicSelector.PfNames {
+		name, rngSt, rngEnd, err := sriovnetworkv1.ParseVfRange(pfName)
+		if err == nil && name == ifaceStatus.Name && rngSt >= 0 {
+			return rngEnd - rngSt + 1
+		}
+	}
+	return policy.Spec.NumVfs
+}
+
+// VfGroupError is returned for the VF group of the interface with an invalid VF range,
+// ConflictingPolicy is set if the range overlaps with the range of the VF group of another policy
+type VfGroupError struct {
+	PciAddress        string
+	PolicyName        string
+	VfRange           string
+	ConflictingPolicy string
+	ConflictingRange  string
+	Reason            string
+}
+
+func (e *VfGroupError) Error() string {
+	if e.ConflictingPolicy != "" {
+		return fmt.Sprintf("interface %q: VF range %s of policy %s overlaps with VF range %s of policy %s",
+			e.PciAddress, e.VfRange, e.PolicyName, e.ConflictingRange, e.ConflictingPolicy)
+	}
+	return fmt.Sprintf("interface %q: VF range %q of policy %s %s", e.PciAddress, e.VfRange, e.PolicyName, e.Reason)
+}
+
+// ValidateVfGroups checks that the VF ranges of the interface VF groups can be parsed, don't exceed
+// the number of VFs of the interface and don't overlap. The VF indexes which are not covered by any
+// of the VF groups are returned, they are created but not advertised by the device plugin.
+func ValidateVfGroups(iface *sriovnetworkv1.Interface) (uncoveredVfs []int, errs []error) {
+	type vfRange struct {
+		group      *sriovnetworkv1.VfGroup
+		start, end int
+	}
+	var ranges []vfRange
+	for i := range iface.VfGroups {
+		group := &iface.VfGroups[i]
+		start, end, err := parseVfGroupRange(group.VfRange)
+		if err != nil {
+			errs = append(errs, &VfGroupError{PciAddress: iface.PciAddress, PolicyName: group.PolicyName,
+				VfRange: group.VfRange, Reason: "is invalid: " + err.Error()})
+			continue
+		}
+		if end >= iface.NumVfs {
+			errs = append(errs, &VfGroupError{PciAddress: iface.PciAddress, PolicyName: group.PolicyName,
+				VfRange: group.VfRange, Reason: fmt.Sprintf("exceeds the number of VFs %d", iface.NumVfs)})
+			continue
+		}
+		for _, r := range ranges {
+			if start <= r.end && r.start <= end {
+				errs = append(errs, &VfGroupError{PciAddress: iface.PciAddress, PolicyName: group.PolicyName,
+					VfRange: group.VfRange, ConflictingPolicy: r.group.PolicyName, ConflictingRange: r.group.VfRange})
+			}
+		}
+		ranges = append(ranges, vfRange{group: group, start: start, end: end})
+	}
+
+	for vfID := 0; vfID < iface.NumVfs; vfID++ {
+		covered := false
+		for _, r := range ranges {
+			if vfID >= r.start && vfID <= r.end {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			uncoveredVfs = append(uncoveredVfs, vfID)
+		}
+	}
+	return uncoveredVfs, errs
+}
+
+// parseVfGroupRange parses the VF range of the VF group in the <start>-<end> format
+func parseVfGroupRange(vfRange string) (start, end int, err error) {
+	startStr, endStr, found := strings.Cut(vfRange, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("expected format is <start>-<end>")
+	}
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	if end, err = strconv.Atoi(endStr); err != nil {
+		return 0, 0, err
+	}
+	if start < 0 || end < start {
+		return 0, 0, fmt.Errorf("start of the range must be in 0..end")
+	}
+	return start, end, nil
+}
@@ -0,0 +1,237 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+var _ = Describe("VF count validation", func() {
+	var (
+		nodeState *sriovnetworkv1.SriovNetworkNodeState
+		policy    *sriovnetworkv1.SriovNetworkNodePolicy
+	)
+	BeforeEach(func() {
+		nodeState = &sriovnetworkv1.SriovNetworkNodeState{
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+				Interfaces: sriovnetworkv1.Interfaces{{
+					PciAddress: "0000:86:00.0",
+					NumVfs:     16,
+					VfGroups: []sriovnetworkv1.VfGroup{{
+						PolicyName: "p0",
+						VfRange:    "0-15",
+					}},
+				}},
+			},
+			Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+				Interfaces: sriovnetworkv1.InterfaceExts{{
+					Name:       "ens803f0",
+					PciAddress: "0000:86:00.0",
+					Vendor:     "8086",
+					TotalVfs:   16,
+				}, {
+					Name:       "ens803f1",
+					PciAddress: "0000:86:00.1",
+					Vendor:     "15b3",
+					TotalVfs:   8,
+				}},
+			},
+		}
+		policy = &sriovnetworkv1.SriovNetworkNodePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "p1"},
+			Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+				NicSelector: sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens803f0#0-3"}},
+				NumVfs:      8,
+			},
+		}
+	})
+	Context("ValidateVfCount", func() {
+		It("should accept policy within hardware limit", func() {
+			Expect(utils.ValidateVfCount(policy, nodeState)).NotTo(HaveOccurred())
+		})
+		It("should reject policy exceeding hardware limit", func() {
+			policy.Spec.NumVfs = 32
+			err := utils.ValidateVfCount(policy, nodeState)
+			Expect(err).To(Equal(&utils.VfCountError{PciAddress: "0000:86:00.0", Requested: 32, TotalVfs: 16}))
+		})
+		It("should take VFs requested by other policies into account", func() {
+			nodeState.Spec.Interfaces[0].NumVfs = 24
+			err := utils.ValidateVfCount(policy, nodeState)
+			Expect(err).To(Equal(&utils.VfCountError{PciAddress: "0000:86:00.0", Requested: 24, TotalVfs: 16}))
+		})
+		It("should skip Mellanox devices", func() {
+			policy.Spec.NicSelector.PfNames = []string{"ens803f1"}
+			policy.Spec.NumVfs = 32
+			Expect(utils.ValidateVfCount(policy, nodeState)).NotTo(HaveOccurred())
+		})
+	})
+	Context("ValidateNodeStateVfCount", func() {
+		It("should accept node state within hardware limit", func() {
+			Expect(utils.ValidateNodeStateVfCount(nodeState)).NotTo(HaveOccurred())
+		})
+		It("should reject node state exceeding hardware limit", func() {
+			nodeState.Spec.Interfaces[0].NumVfs = 17
+			Expect(utils.ValidateNodeStateVfCount(nodeState)).To(MatchError(
+				"requested 17 VFs for PF 0000:86:00.0 exceeds the maximum supported by the hardware (16)"))
+		})
+	})
+	Context("VFResourceEnforcer", func() {
+		var otherPolicy *sriovnetworkv1.SriovNetworkNodePolicy
+		BeforeEach(func() {
+			otherPolicy = &sriovnetworkv1.SriovNetworkNodePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "p0"},
+				Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+					NicSelector: sriovnetworkv1.SriovNetworkNicSelector{Vendor: "8086"},
+					NumVfs:      12,
+				},
+			}
+		})
+		It("should accept policies within hardware limit", func() {
+			enforcer := &utils.VFResourceEnforcer{}
+			Expect(enforcer.Enforce([]*sriovnetworkv1.SriovNetworkNodePolicy{policy, otherPolicy},
+				&nodeState.Status)).NotTo(HaveOccurred())
+		})
+		It("should report soft overcommit within the ratio", func() {
+			otherPolicy.Spec.NumVfs = 16
+			enforcer := &utils.VFResourceEnforcer{OvercommitRatio: 1.5}
+			Expect(enforcer.Enforce([]*sriovnetworkv1.SriovNetworkNodePolicy{policy, otherPolicy}, &nodeState.Status)).To(Equal(
+				&utils.VfOvercommitError{PciAddress: "0000:86:00.0", Requested: 20, TotalVfs: 16}))
+		})
+		It("should report hard overcommit exceeding the ratio", func() {
+			otherPolicy.Spec.NumVfs = 16
+			policy.Spec.NicSelector.PfNames = []string{"ens803f0"}
+			enforcer := &utils.VFResourceEnforcer{OvercommitRatio: 1.25}
+			err := enforcer.Enforce([]*sriovnetworkv1.SriovNetworkNodePolicy{policy, otherPolicy}, &nodeState.Status)
+			Expect(err).To(MatchError("hard overcommit: 24 VFs requested for PF 0000:86:00.0 which supports 16 VFs"))
+		})
+		It("should never report hard overcommit without the ratio", func() {
+			otherPolicy.Spec.NumVfs = 16
+			policy.Spec.NicSelector.PfNames = []string{"ens803f0"}
+			enforcer := &utils.VFResourceEnforcer{}
+			Expect(enforcer.Enforce([]*sriovnetworkv1.SriovNetworkNodePolicy{policy, otherPolicy}, &nodeState.Status)).To(Equal(
+				&utils.VfOvercommitError{PciAddress: "0000:86:00.0", Requested: 24, TotalVfs: 16}))
+		})
+		It("should skip Mellanox devices", func() {
+			policy.Spec.NicSelector.PfNames = []string{"ens803f1"}
+			policy.Spec.NumVfs = 32
+			enforcer := &utils.VFResourceEnforcer{OvercommitRatio: 1}
+			Expect(enforcer.Enforce([]*sriovnetworkv1.SriovNetworkNodePolicy{policy}, &nodeState.Status)).NotTo(HaveOccurred())
+		})
+		It("should check VF groups of the node state", func() {
+			enforcer := &utils.VFResourceEnforcer{OvercommitRatio: 1}
+			Expect(enforcer.EnforceNodeState(nodeState)).NotTo(HaveOccurred())
+			nodeState.Spec.Interfaces[0].VfGroups = append(nodeState.Spec.Interfaces[0].VfGroups,
+				sriovnetworkv1.VfGroup{PolicyName: "p1", VfRange: "0-3"})
+			Expect(enforcer.EnforceNodeState(nodeState)).To(Equal(
+				&utils.VfOvercommitError{PciAddress: "0000:86:00.0", Requested: 20, TotalVfs: 16, Hard: true}))
+		})
+	})
+})
+
+var _ = Describe("ValidateVfGroups", func() {
+	var iface *sriovnetworkv1.Interface
+	BeforeEach(func() {
+		iface = &sriovnetworkv1.Interface{
+			PciAddress: "0000:86:00.0",
+			NumVfs:     8,
+			VfGroups: []sriovnetworkv1.VfGroup{
+				{PolicyName: "p0", VfRange: "0-3"},
+				{PolicyName: "p1", VfRange: "4-5"},
+			},
+		}
+	})
+	It("should return the VFs which are not in any group", func() {
+		uncovered, errs := utils.ValidateVfGroups(iface)
+		Expect(errs).To(BeEmpty())
+		Expect(uncovered).To(Equal([]int{6, 7}))
+	})
+	It("should reject overlapping groups", func() {
+		iface.VfGroups = append(iface.VfGroups, sriovnetworkv1.VfGroup{PolicyName: "p2", VfRange: "3-4"})
+		_, errs := utils.ValidateVfGroups(iface)
+		Expect(errs).To(ConsistOf(
+			MatchError(`interface "0000:86:00.0": VF range 3-4 of policy p2 overlaps with VF range 0-3 of policy p0`),
+			MatchError(`interface "0000:86:00.0": VF range 3-4 of policy p2 overlaps with VF range 4-5 of policy p1`)))
+	})
+	It("should reject range exceeding the number of VFs", func() {
+		iface.VfGroups[1].VfRange = "4-8"
+		uncovered, errs := utils.ValidateVfGroups(iface)
+		Expect(errs).To(ConsistOf(
+			MatchError(`interface "0000:86:00.0": VF range "4-8" of policy p1 exceeds the number of VFs 8`)))
+		Expect(uncovered).To(Equal([]int{4, 5, 6, 7}))
+	})
+	It("should reject invalid ranges", func() {
+		iface.VfGroups[0].VfRange = "3"
+		iface.VfGroups[1].VfRange = "5-4"
+		_, errs := utils.ValidateVfGroups(iface)
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0]).To(BeAssignableToTypeOf(&utils.VfGroupError{}))
+		Expect(errs[0].(*utils.VfGroupError).PolicyName).To(Equal("p0"))
+		Expect(errs[1].(*utils.VfGroupError).PolicyName).To(Equal("p1"))
+	})
+})
+
+var _ = Describe("Sibling PF VF budget", func() {
+	var nodeState *sriovnetworkv1.SriovNetworkNodeState
+	BeforeEach(func() {
+		nodeState = &sriovnetworkv1.SriovNetworkNodeState{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-0"},
+			Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+				Interfaces: sriovnetworkv1.Interfaces{
+					{PciAddress: "0000:3b:00.0", Name: "ens1f0", NumVfs: 64},
+					{PciAddress: "0000:3b:00.1", Name: "ens1f1", NumVfs: 64},
+				},
+			},
+			Status: sriovnetworkv1.SriovNetworkNodeStateStatus{
+				Interfaces: sriovnetworkv1.InterfaceExts{
+					{PciAddress: "0000:3b:00.0", Name: "ens1f0", Vendor: "15b3", TotalVfs: 8, SiblingPFs: []string{"0000:3b:00.1"}},
+					{PciAddress: "0000:3b:00.1", Name: "ens1f1", Vendor: "15b3", TotalVfs: 8, SiblingPFs: []string{"0000:3b:00.0"}},
+					{PciAddress: "0000:86:00.0", Name: "ens2f0", Vendor: "8086", TotalVfs: 64},
+				},
+			},
+		}
+	})
+	It("should accept the VFs which fit in the VFs of the device", func() {
+		Expect(utils.ValidateSiblingVfBudgets(nodeState)).To(BeEmpty())
+	})
+	It("should reject the VFs of the sibling PFs exceeding the VFs of the device once", func() {
+		nodeState.Spec.Interfaces[1].NumVfs = 65
+		Expect(utils.ValidateSiblingVfBudgets(nodeState)).To(ConsistOf(
+			MatchError("requested 129 VFs for sibling PFs 0000:3b:00.0, 0000:3b:00.1 exceeds the VFs shared by the PFs of the device (128)")))
+	})
+	It("should use the firmware limit of externally managed devices", func() {
+		nodeState.Status.Interfaces[0].ExternallyManaged = true
+		errs := utils.ValidateSiblingVfBudgets(nodeState)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(Equal(&utils.SiblingVfBudgetError{
+			PciAddresses: []string{"0000:3b:00.0", "0000:3b:00.1"}, Requested: 128, Budget: 8}))
+	})
+	It("should reject a policy which exceeds the VFs of the device with the siblings", func() {
+		policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "p1"},
+			Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+				NumVfs:      80,
+				NicSelector: sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens1f1#0-79"}},
+			},
+		}
+		Expect(utils.ValidateSiblingVfBudget(policy, nodeState)).To(MatchError(
+			"requested 144 VFs for sibling PFs 0000:3b:00.0, 0000:3b:00.1 exceeds the VFs shared by the PFs of the device (128)"))
+
+		policy.Spec.NumVfs = 64
+		Expect(utils.ValidateSiblingVfBudget(policy, nodeState)).To(Succeed())
+	})
+	It("should ignore the devices the policy doesn't select", func() {
+		nodeState.Spec.Interfaces[1].NumVfs = 100
+		policy := &sriovnetworkv1.SriovNetworkNodePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "p2"},
+			Spec: sriovnetworkv1.SriovNetworkNodePolicySpec{
+				NumVfs:      16,
+				NicSelector: sriovnetworkv1.SriovNetworkNicSelector{PfNames: []string{"ens2f0"}},
+			},
+		}
+		Expect(utils.ValidateSiblingVfBudget(policy, nodeState)).To(Succeed())
+	})
+})
@@ -3,6 +3,7 @@ package vars
 import (
 	"os"
 	"regexp"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
@@ -51,12 +52,56 @@ var (
 	// ParallelNicConfig global variable to perform NIC configuration in parallel
 	ParallelNicConfig = false
 
+	// ParallelNicConfigWorkers maximum number of NICs configured at the same time when ParallelNicConfig is enabled
+	ParallelNicConfigWorkers = 4
+
 	// ManageSoftwareBridges global variable which reflects state of manageSoftwareBridges feature
 	ManageSoftwareBridges = false
 
 	// MlxPluginFwReset global variable enables mstfwreset before rebooting a node on VF changes
 	MlxPluginFwReset = false
 
+	// VfOvercommitRatio global variable which reflects the maximum VF overcommit ratio from the SriovOperatorConfig
+	VfOvercommitRatio float64 = 0
+
+	// VfAttributeReconcileInterval global variable which reflects the interval of the periodic reconciliation
+	// of the VF attributes from the SriovOperatorConfig, the reconciliation is disabled if zero
+	VfAttributeReconcileInterval time.Duration = 0
+
+	// VfReleaseTimeout maximum time to wait for the pods to release the VFs of a PF before the VFs are removed
+	VfReleaseTimeout = 5 * time.Minute
+
+	// WaitForVfRelease global variable to wait for the pods to release the VFs of a PF before the VFs are removed,
+	// the pods of a DaemonSet are not evicted by the drain so the wait must be enabled only if no DaemonSet uses VFs
+	WaitForVfRelease = false
+
+	// RemediateGhostVFs global variable to remove the VFs left by the previous runs of the daemon
+	// which are not reflected in the desired state, the VFs are only reported if false
+	RemediateGhostVFs = false
+
+	// IgnoreBondedInterfaces global variable to configure the PFs enslaved to a bond or to a team without draining the node
+	IgnoreBondedInterfaces = false
+
+	// PersistDriverLoad global variable to load the kernel drivers required by the node state on boot
+	PersistDriverLoad = false
+
+	// UseTunedKernelParams global variable to add the kernel args to a tuned profile on the hosts managed by tuned
+	UseTunedKernelParams = false
+
+	// PluginWatchdogInterval global variable which reflects the time without node state changes after which
+	// the generic plugin requests to apply the node state again, the watchdog is disabled if zero
+	PluginWatchdogInterval = 30 * time.Minute
+
+	// HostMountPath global variable which reflects the path where the host filesystem is mounted in the daemon container
+	HostMountPath = consts.Host
+
+	// DriverOverrideAllowed global variable to honor the driver overrides of the policies, for testing purposes only
+	DriverOverrideAllowed = false
+
+	// NetdevsimMode global variable to discover and configure the netdevsim devices instead of the PCI devices,
+	// netdevsim devices simulate SR-IOV NICs to test the operator without hardware
+	NetdevsimMode = false
+
 	// FilesystemRoot used by test to mock interactions with filesystem
 	FilesystemRoot = ""
 
@@ -95,4 +140,8 @@ func init() {
 	}
 
 	ResourcePrefix = os.Getenv("RESOURCE_PREFIX")
+
+	DriverOverrideAllowed = os.Getenv("SRIOV_DRIVER_OVERRIDE_ALLOWED") == "true"
+
+	NetdevsimMode = os.Getenv("SRIOV_NETDEVSIM_MODE") == "true"
 }
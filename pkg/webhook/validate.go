@@ -2,9 +2,11 @@ package webhook
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -13,22 +15,26 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/cpuset"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
 const (
 	IntelID    = "8086"
 	MellanoxID = "15b3"
-	MlxMaxVFs  = 128
+	MlxMaxVFs  = consts.MlxMaxVFs
 )
 
 var (
 	nodesSelected     bool
 	interfaceSelected bool
+	// PF drivers which support 802.1ad VLAN protocol for the VF VLAN
+	vlanProto8021adDrivers = []string{"i40e", "ice", "mlx5_core"}
 )
 
 func validateSriovOperatorConfig(cr *sriovnetworkv1.SriovOperatorConfig, operation v1.Operation) (bool, []string, error) {
@@ -52,6 +58,10 @@ func validateSriovOperatorConfig(cr *sriovnetworkv1.SriovOperatorConfig, operati
 		return false, warnings, err
 	}
 
+	if _, err := cr.Spec.GetVfOvercommitRatio(); err != nil {
+		return false, warnings, err
+	}
+
 	return true, warnings, nil
 }
 
@@ -133,7 +143,8 @@ func validateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePolicy, o
 		return admit, warnings, err
 	}
 
-	admit, err = dynamicValidateSriovNetworkNodePolicy(cr)
+	admit, dynamicWarnings, err := dynamicValidateSriovNetworkNodePolicy(cr)
+	warnings = append(warnings, dynamicWarnings...)
 	if err != nil {
 		return admit, warnings, err
 	}
@@ -203,6 +214,14 @@ func staticValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePol
 		}
 	}
 
+	if cr.Spec.VfMtu != 0 && cr.Spec.Mtu != 0 && cr.Spec.VfMtu > cr.Spec.Mtu {
+		return false, fmt.Errorf("vfMtu(%d) in CR %s exceeds the PF mtu(%d)", cr.Spec.VfMtu, cr.GetName(), cr.Spec.Mtu)
+	}
+
+	if cr.Spec.MaxTxRate != 0 && cr.Spec.MinTxRate > cr.Spec.MaxTxRate {
+		return false, fmt.Errorf("minTxRate(%d) in CR %s exceeds maxTxRate(%d)", cr.Spec.MinTxRate, cr.GetName(), cr.Spec.MaxTxRate)
+	}
+
 	// To configure RoCE on baremetal or virtual machine:
 	// BM: DeviceType = netdevice && isRdma = true
 	// VM: DeviceType = vfio-pci && isRdma = false
@@ -210,6 +229,11 @@ func staticValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePol
 		return false, fmt.Errorf("'deviceType: vfio-pci' conflicts with 'isRdma: true'; Set 'deviceType' to (string)'netdevice' Or Set 'isRdma' to (bool)'false'")
 	}
 
+	// the RDMA subsystem mode is requested for the RDMA devices of the policy
+	if cr.Spec.RdmaMode != "" && !cr.Spec.IsRdma {
+		return false, fmt.Errorf("'rdmaMode: %s' requires 'isRdma: true'", cr.Spec.RdmaMode)
+	}
+
 	// switchdev mode can be used only with ethernet links
 	if cr.Spec.LinkType != "" && !strings.EqualFold(cr.Spec.LinkType, consts.LinkTypeETH) && cr.Spec.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
 		return false, fmt.Errorf("'eSwitchMode: switchdev' can be used only with ethernet links")
@@ -223,6 +247,44 @@ func staticValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePol
 	if (cr.Spec.VdpaType == consts.VdpaTypeVirtio || cr.Spec.VdpaType == consts.VdpaTypeVhost) && cr.Spec.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
 		return false, fmt.Errorf("vdpa requires the device to be configured in switchdev mode")
 	}
+	for _, field := range []struct{ name, value string }{{"trust", cr.Spec.Trust}, {"spoofChk", cr.Spec.SpoofChk}} {
+		if field.value != "" && field.value != sriovnetworkv1.SriovCniStateOn && field.value != sriovnetworkv1.SriovCniStateOff {
+			return false, fmt.Errorf("invalid %s value %q in CR %s, allowed values are \"on\" and \"off\"", field.name, field.value, cr.GetName())
+		}
+	}
+
+	// VF QoS is configured with tc and requires the VF netdevice
+	if (cr.Spec.DSCP != nil || cr.Spec.EgressBandwidthMbps != nil) && cr.Spec.DeviceType == consts.DeviceTypeVfioPci {
+		return false, fmt.Errorf("'dscp' and 'egressBandwidthMbps' require 'deviceType: netdevice'")
+	}
+	if err := validateVfVlan(cr); err != nil {
+		return false, err
+	}
+	if err := validateVfQinQ(cr); err != nil {
+		return false, err
+	}
+	if err := validateVfRSS(cr); err != nil {
+		return false, err
+	}
+	if err := validateDevlinkParams(cr); err != nil {
+		return false, err
+	}
+	if err := validateIRQAffinity(cr); err != nil {
+		return false, err
+	}
+	if err := validateOVSDPDKOffload(cr); err != nil {
+		return false, err
+	}
+	if err := validateEswitchEncapMode(cr); err != nil {
+		return false, err
+	}
+	if err := validatePfSettings(cr); err != nil {
+		return false, err
+	}
+	// kernel driver blacklisting is supported only for VFs bound to vfio-pci
+	if cr.Spec.BlacklistKernelDriver && cr.Spec.DeviceType != consts.DeviceTypeVfioPci {
+		return false, fmt.Errorf("'blacklistKernelDriver: true' requires 'deviceType: vfio-pci'")
+	}
 	// software bridge management: device must be configured in switchdev mode
 	if !cr.Spec.Bridge.IsEmpty() && cr.Spec.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
 		return false, fmt.Errorf("software bridge management requires the device to be configured in switchdev mode")
@@ -234,37 +296,50 @@ func staticValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePol
 	return true, nil
 }
 
-func dynamicValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePolicy) (bool, error) {
+func dynamicValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePolicy) (bool, []string, error) {
 	nodesSelected = false
 	interfaceSelected = false
 	nodeInterfaceErrorList := make(map[string][]string)
+	var warnings []string
 
 	nodeList, err := kubeclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{
 		LabelSelector: labels.Set(cr.Spec.NodeSelector).String(),
 	})
 	if err != nil {
-		return false, err
+		return false, warnings, err
 	}
 	nsList, err := snclient.SriovnetworkV1().SriovNetworkNodeStates(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
-		return false, err
+		return false, warnings, err
 	}
 	npList, err := snclient.SriovnetworkV1().SriovNetworkNodePolicies(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
-		return false, err
+		return false, warnings, err
+	}
+	enforcer, err := newVFResourceEnforcer()
+	if err != nil {
+		return false, warnings, err
 	}
 	for _, node := range nodeList.Items {
 		if cr.Selected(&node) {
 			nodesSelected = true
 			err = validatePolicyForNodeStateAndPolicy(nsList, npList, &node, cr, nodeInterfaceErrorList)
 			if err != nil {
-				return false, err
+				return false, warnings, err
+			}
+			warning, err := validateVfOvercommit(enforcer, nsList, npList, &node, cr)
+			if err != nil {
+				return false, warnings, err
 			}
+			if warning != "" {
+				warnings = append(warnings, warning)
+			}
+			warnings = append(warnings, validateTotalVfs(nsList, &node, cr)...)
 		}
 	}
 
 	if !nodesSelected {
-		return false, fmt.Errorf("no matched node is selected by the nodeSelector in CR %s", cr.GetName())
+		return false, warnings, fmt.Errorf("no matched node is selected by the nodeSelector in CR %s", cr.GetName())
 	}
 	if !interfaceSelected {
 		for nodeName, messages := range nodeInterfaceErrorList {
@@ -272,10 +347,79 @@ func dynamicValidateSriovNetworkNodePolicy(cr *sriovnetworkv1.SriovNetworkNodePo
 				log.Log.V(2).Info("interface selection errors", "nodeName", nodeName, "message", message)
 			}
 		}
-		return false, fmt.Errorf("no supported NIC is selected by the nicSelector in CR %s", cr.GetName())
+		return false, warnings, fmt.Errorf("no supported NIC is selected by the nicSelector in CR %s", cr.GetName())
 	}
 
-	return true, nil
+	return true, warnings, nil
+}
+
+// newVFResourceEnforcer returns the VF overcommit enforcer configured by the default SriovOperatorConfig
+func newVFResourceEnforcer() (*utils.VFResourceEnforcer, error) {
+	config, err := snclient.SriovnetworkV1().SriovOperatorConfigs(namespace).Get(context.Background(), consts.DefaultConfigName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return &utils.VFResourceEnforcer{}, nil
+		}
+		return nil, err
+	}
+	ratio, err := config.Spec.GetVfOvercommitRatio()
+	if err != nil {
+		return nil, err
+	}
+	return &utils.VFResourceEnforcer{OvercommitRatio: ratio}, nil
+}
+
+// validateVfOvercommit checks the VFs requested by the policy together with the other policies selecting the node,
+// soft overcommit is returned as a warning
+func validateVfOvercommit(enforcer *utils.VFResourceEnforcer, nsList *sriovnetworkv1.SriovNetworkNodeStateList, npList *sriovnetworkv1.SriovNetworkNodePolicyList, node *corev1.Node, cr *sriovnetworkv1.SriovNetworkNodePolicy) (string, error) {
+	for _, ns := range nsList.Items {
+		if ns.GetName() != node.GetName() {
+			continue
+		}
+		policies := []*sriovnetworkv1.SriovNetworkNodePolicy{cr}
+		for i := range npList.Items {
+			np := &npList.Items[i]
+			if np.GetName() != cr.GetName() && np.GetName() != consts.DefaultPolicyName && np.Selected(node) {
+				policies = append(policies, np)
+			}
+		}
+		err := enforcer.Enforce(policies, &ns.Status)
+		if err == nil {
+			return "", nil
+		}
+		overcommitErr := &utils.VfOvercommitError{}
+		if errors.As(err, &overcommitErr) && !overcommitErr.Hard {
+			return fmt.Sprintf("VFs requested by CR %s and other policies on node %s: %v, "+
+				"policies with lower priority may get less VFs than requested", cr.GetName(), node.GetName(), err), nil
+		}
+		return "", fmt.Errorf("numVfs(%d) in CR %s is not valid for node %s: %v", cr.Spec.NumVfs, cr.GetName(), node.GetName(), err)
+	}
+	return "", nil
+}
+
+// validateTotalVfs returns a warning for the selected PFs which support less VFs than requested by the policy
+// and whose firmware limit is raised by the config daemon, the new limit requires a reboot of the node
+func validateTotalVfs(nsList *sriovnetworkv1.SriovNetworkNodeStateList, node *corev1.Node, cr *sriovnetworkv1.SriovNetworkNodePolicy) []string {
+	var warnings []string
+	if cr.Spec.ExternallyManaged {
+		return warnings
+	}
+	for _, ns := range nsList.Items {
+		if ns.GetName() != node.GetName() {
+			continue
+		}
+		for _, iface := range ns.Status.Interfaces {
+			if iface.Vendor != MellanoxID || cr.Spec.NumVfs <= iface.TotalVfs {
+				continue
+			}
+			if err := validateNicModel(&cr.Spec.NicSelector, &iface, node); err != nil {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("numVfs(%d) in CR %s exceeds the current maximum number of VFs(%d) of interface(%s) on node %s, "+
+				"the firmware limit will be raised which requires a reboot of the node", cr.Spec.NumVfs, cr.GetName(), iface.TotalVfs, iface.Name, node.GetName()))
+		}
+	}
+	return warnings
 }
 
 func validatePolicyForNodeStateAndPolicy(nsList *sriovnetworkv1.SriovNetworkNodeStateList, npList *sriovnetworkv1.SriovNetworkNodePolicyList, node *corev1.Node, cr *sriovnetworkv1.SriovNetworkNodePolicy, nodeInterfaceErrorList map[string][]string) error {
@@ -323,6 +467,25 @@ func validatePolicyForNodeState(policy *sriovnetworkv1.SriovNetworkNodePolicy, s
 				return nil, fmt.Errorf("numVfs(%d) in CR %s exceed the maximum allowed value(%d) interface(%s)", policy.Spec.NumVfs, policy.GetName(), MlxMaxVFs, iface.Name)
 			}
 
+			// the PF MTU is not changed by the policy, VF MTU can't exceed the current one
+			if policy.Spec.Mtu == 0 && policy.Spec.VfMtu > iface.Mtu {
+				return nil, fmt.Errorf("vfMtu(%d) in CR %s exceeds the MTU(%d) of the PF interface(%s)", policy.Spec.VfMtu, policy.GetName(), iface.Mtu, iface.Name)
+			}
+
+			if err := validateMinTxRate(policy, state, &iface); err != nil {
+				return nil, err
+			}
+
+			if policy.Spec.Vlan != 0 && sriovnetworkv1.GetVlanProto(policy.Spec.VlanProto) == sriovnetworkv1.VlanProto8021ad &&
+				!sriovnetworkv1.StringInArray(iface.Driver, vlanProto8021adDrivers) {
+				return nil, fmt.Errorf("vlanProto(%s) in CR %s is not supported by the driver(%s) of the PF interface(%s)",
+					policy.Spec.VlanProto, policy.GetName(), iface.Driver, iface.Name)
+			}
+			if policy.Spec.OuterVLAN != nil && !sriovnetworkv1.StringInArray(iface.Driver, vlanProto8021adDrivers) {
+				return nil, fmt.Errorf("outerVlan in CR %s is not supported by the driver(%s) of the PF interface(%s)",
+					policy.GetName(), iface.Driver, iface.Name)
+			}
+
 			// Externally create validations
 			if policy.Spec.ExternallyManaged {
 				if policy.Spec.NumVfs > iface.NumVfs {
@@ -350,9 +513,223 @@ func validatePolicyForNodeState(policy *sriovnetworkv1.SriovNetworkNodePolicy, s
 	if !interfaceSelectedForNode {
 		return noInterfacesSelectedLog, nil
 	}
+	if err := utils.ValidateVfCount(policy, state); err != nil {
+		return nil, fmt.Errorf("numVfs(%d) in CR %s is not valid for node %s: %v", policy.Spec.NumVfs, policy.GetName(), state.GetName(), err)
+	}
+	if err := utils.ValidateSiblingVfBudget(policy, state); err != nil {
+		return nil, fmt.Errorf("numVfs(%d) in CR %s is not valid for node %s: %v", policy.Spec.NumVfs, policy.GetName(), state.GetName(), err)
+	}
 	return nil, nil
 }
 
+// validateDevlinkParams checks the names and the values of the devlink parameters of the PFs,
+// the parameters supported by the device are known only when they are set by the config daemon
+func validateDevlinkParams(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if len(cr.Spec.DevlinkParams) == 0 {
+		return nil
+	}
+	if cr.Spec.ExternallyManaged {
+		return fmt.Errorf("'devlinkParams' can't be used when the device is externally managed")
+	}
+	var validName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	for name, value := range cr.Spec.DevlinkParams {
+		if !validName.MatchString(name) {
+			return fmt.Errorf("devlink parameter name %q in CR %s is invalid, the accepted syntax of the regular expressions is: \"^[a-z][a-z0-9_]*$\"", name, cr.GetName())
+		}
+		if value == "" {
+			return fmt.Errorf("devlink parameter %s in CR %s has no value", name, cr.GetName())
+		}
+	}
+	return nil
+}
+
+// validateIRQAffinity checks that the interrupt affinity of the VFs is a valid CPU list,
+// the CPUs available on the node are checked by the config daemon
+func validateIRQAffinity(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if cr.Spec.IRQAffinity == nil {
+		return nil
+	}
+	if _, err := cpuset.Parse(string(*cr.Spec.IRQAffinity)); err != nil || *cr.Spec.IRQAffinity == "" {
+		return fmt.Errorf("invalid irqAffinity %q in CR %s, expected a CPU list like \"0-3,8-11\"", *cr.Spec.IRQAffinity, cr.GetName())
+	}
+	return nil
+}
+
+// validateEswitchEncapMode checks the encapsulation mode of the eSwitch, it is set only in switchdev mode
+func validateEswitchEncapMode(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if cr.Spec.EswitchEncapMode == "" {
+		return nil
+	}
+	if cr.Spec.EswitchEncapMode != sriovnetworkv1.ESwitchEncapModeNone && cr.Spec.EswitchEncapMode != sriovnetworkv1.ESwitchEncapModeBasic {
+		return fmt.Errorf("invalid eSwitchEncapMode %q in CR %s, allowed values are \"none\" and \"basic\"",
+			cr.Spec.EswitchEncapMode, cr.GetName())
+	}
+	if cr.Spec.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+		return fmt.Errorf("'eSwitchEncapMode' requires the device to be configured in switchdev mode")
+	}
+	return nil
+}
+
+// validatePfSettings accepts only the whitelisted ethtool features in the PF settings, the PF settings
+// can't be changed on the devices which are externally managed
+func validatePfSettings(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if cr.Spec.PfSettings == nil {
+		return nil
+	}
+	if cr.Spec.ExternallyManaged {
+		return fmt.Errorf("'pfSettings' can't be used when the device is externally managed")
+	}
+	for feature := range cr.Spec.PfSettings.Features {
+		if !slices.Contains(sriovnetworkv1.PfSettingsFeatures, feature) {
+			return fmt.Errorf("feature %q in 'pfSettings' of CR %s is not supported, supported features are: %s",
+				feature, cr.GetName(), strings.Join(sriovnetworkv1.PfSettingsFeatures, ", "))
+		}
+	}
+	return nil
+}
+
+// validateOVSDPDKOffload checks the representor mapping of the VFs configured for the OVS-DPDK offload
+func validateOVSDPDKOffload(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if !cr.Spec.OVSDPDKOffload {
+		if len(cr.Spec.RepresentorMapping) > 0 {
+			return fmt.Errorf("'representorMapping' requires 'ovsDpdkOffload: true'")
+		}
+		return nil
+	}
+	// the VF representors exist only in switchdev mode
+	if cr.Spec.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+		return fmt.Errorf("OVS-DPDK offload requires the device to be configured in switchdev mode")
+	}
+	if cr.Spec.ExternallyManaged {
+		return fmt.Errorf("OVS-DPDK offload can't be used when the device externally managed")
+	}
+	for vfID, port := range cr.Spec.RepresentorMapping {
+		if vfID < 0 || vfID >= cr.Spec.NumVfs {
+			return fmt.Errorf("invalid VF index %d in representorMapping of CR %s, the policy has %d VFs", vfID, cr.GetName(), cr.Spec.NumVfs)
+		}
+		if port == "" {
+			return fmt.Errorf("empty OVS port name for VF %d in representorMapping of CR %s", vfID, cr.GetName())
+		}
+	}
+	return nil
+}
+
+// validateVfVlan checks the VLAN which is programmed on the VFs through the PF
+func validateVfVlan(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if cr.Spec.Vlan == 0 && cr.Spec.VlanQoS == 0 && cr.Spec.VlanProto == "" {
+		return nil
+	}
+	// the VLAN of netdevice VFs is set by the CNI when the VF is attached to the pod
+	if cr.Spec.DeviceType != consts.DeviceTypeVfioPci {
+		return fmt.Errorf("'vlan', 'vlanQoS' and 'vlanProto' require 'deviceType: vfio-pci', use the SriovNetwork to configure the VLAN of netdevice VFs")
+	}
+	if cr.Spec.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
+		return fmt.Errorf("'vlan' can't be programmed on the VFs of a device in switchdev mode")
+	}
+	if cr.Spec.Vlan < 0 || cr.Spec.Vlan > 4095 {
+		return fmt.Errorf("vlan(%d) in CR %s is out of range [0-4095]", cr.Spec.Vlan, cr.GetName())
+	}
+	if cr.Spec.VlanQoS < 0 || cr.Spec.VlanQoS > 7 {
+		return fmt.Errorf("vlanQoS(%d) in CR %s is out of range [0-7]", cr.Spec.VlanQoS, cr.GetName())
+	}
+	if proto := sriovnetworkv1.GetVlanProto(cr.Spec.VlanProto); proto != sriovnetworkv1.VlanProto8021q && proto != sriovnetworkv1.VlanProto8021ad {
+		return fmt.Errorf("invalid vlanProto %q in CR %s, allowed values are \"802.1q\" and \"802.1ad\"", cr.Spec.VlanProto, cr.GetName())
+	}
+	if cr.Spec.Vlan == 0 {
+		return fmt.Errorf("'vlanQoS' and 'vlanProto' in CR %s require 'vlan' to be set", cr.GetName())
+	}
+	return nil
+}
+
+// validateVfQinQ checks the double VLAN which is programmed on the VFs, the outer VLAN is set through the PF
+// and the inner VLAN is used by the workloads on the VF netdevices
+func validateVfQinQ(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if cr.Spec.OuterVLAN == nil && cr.Spec.InnerVLAN == nil {
+		return nil
+	}
+	if cr.Spec.OuterVLAN == nil || cr.Spec.InnerVLAN == nil {
+		return fmt.Errorf("'outerVlan' and 'innerVlan' in CR %s must be set together", cr.GetName())
+	}
+	for _, vlan := range []struct {
+		name string
+		vid  uint16
+	}{{"outerVlan", *cr.Spec.OuterVLAN}, {"innerVlan", *cr.Spec.InnerVLAN}} {
+		if vlan.vid < 1 || vlan.vid > 4094 {
+			return fmt.Errorf("%s(%d) in CR %s is out of range [1-4094]", vlan.name, vlan.vid, cr.GetName())
+		}
+	}
+	if cr.Spec.DeviceType == consts.DeviceTypeVfioPci {
+		return fmt.Errorf("'outerVlan' and 'innerVlan' require 'deviceType: netdevice'")
+	}
+	if cr.Spec.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
+		return fmt.Errorf("'outerVlan' can't be programmed on the VFs of a device in switchdev mode")
+	}
+	return nil
+}
+
+// validateVfRSS checks the RSS hash key and hash fields which are configured on the VF netdevices
+func validateVfRSS(cr *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if cr.Spec.RSSHashKey == nil && len(cr.Spec.RSSHashFields) == 0 {
+		return nil
+	}
+	if cr.Spec.RSSHashKey != nil {
+		if _, err := utils.NormalizeRSSHashKey(*cr.Spec.RSSHashKey); err != nil {
+			return fmt.Errorf("rssHashKey in CR %s is invalid: %v", cr.GetName(), err)
+		}
+	}
+	for _, field := range cr.Spec.RSSHashFields {
+		if !utils.IsRSSHashField(field) {
+			return fmt.Errorf("rssHashFields in CR %s contains the unsupported field %q", cr.GetName(), field)
+		}
+	}
+	if cr.Spec.DeviceType == consts.DeviceTypeVfioPci {
+		return fmt.Errorf("'rssHashKey' and 'rssHashFields' require 'deviceType: netdevice'")
+	}
+	return nil
+}
+
+// validateMinTxRate checks that the sum of the minimum tx rates guaranteed to the VFs of the PF
+// by the policy and by the other policies already applied to the PF doesn't exceed the PF link speed
+func validateMinTxRate(policy *sriovnetworkv1.SriovNetworkNodePolicy, state *sriovnetworkv1.SriovNetworkNodeState, iface *sriovnetworkv1.InterfaceExt) error {
+	if policy.Spec.MinTxRate == 0 {
+		return nil
+	}
+	var linkSpeed int
+	if _, err := fmt.Sscanf(iface.LinkSpeed, "%d Mb/s", &linkSpeed); err != nil || linkSpeed <= 0 {
+		// link speed is unknown, e.g. the link is down
+		return nil
+	}
+
+	numVfs := policy.Spec.NumVfs
+	for _, pfName := range policy.Spec.NicSelector.PfNames {
+		name, rngSt, rngEnd, err := sriovnetworkv1.ParseVfRange(pfName)
+		if err == nil && name == iface.Name && rngSt >= 0 {
+			numVfs = rngEnd - rngSt + 1
+		}
+	}
+	total := policy.Spec.MinTxRate * numVfs
+	for _, ifaceSpec := range state.Spec.Interfaces {
+		if ifaceSpec.PciAddress != iface.PciAddress {
+			continue
+		}
+		for _, group := range ifaceSpec.VfGroups {
+			if group.PolicyName == policy.GetName() {
+				continue
+			}
+			for vfID := 0; vfID < ifaceSpec.NumVfs; vfID++ {
+				if sriovnetworkv1.IndexInRange(vfID, group.VfRange) {
+					total += group.MinTxRate
+				}
+			}
+		}
+	}
+	if total > linkSpeed {
+		return fmt.Errorf("minTxRate(%d) in CR %s is not valid, the sum of the minimum tx rates of the VFs (%d Mbps) exceeds the link speed (%d Mbps) of the PF interface(%s)",
+			policy.Spec.MinTxRate, policy.GetName(), total, linkSpeed, iface.Name)
+	}
+	return nil
+}
+
 func validatePolicyForNodePolicy(current *sriovnetworkv1.SriovNetworkNodePolicy, previous *sriovnetworkv1.SriovNetworkNodePolicy) error {
 	log.Log.V(2).Info("validateConflictPolicy(): validate policy against policy",
 		"source", current.GetName(), "target", previous.GetName())
@@ -376,6 +753,11 @@ func validatePolicyForNodePolicy(current *sriovnetworkv1.SriovNetworkNodePolicy,
 		return err
 	}
 
+	err = validateTrustAndSpoofChkFields(current, previous)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -441,6 +823,23 @@ func validateExludeTopologyField(current *sriovnetworkv1.SriovNetworkNodePolicy,
 		current.Spec.ExcludeTopology, previous.GetName(), previous.Spec.ExcludeTopology, current.Spec.ResourceName)
 }
 
+// trust and spoofChk of the policies are used to render the configuration of SriovNetworks of the resource,
+// so they can't differ between policies of the same resource
+func validateTrustAndSpoofChkFields(current *sriovnetworkv1.SriovNetworkNodePolicy, previous *sriovnetworkv1.SriovNetworkNodePolicy) error {
+	if current.Spec.ResourceName != previous.Spec.ResourceName {
+		return nil
+	}
+	if current.Spec.Trust != "" && previous.Spec.Trust != "" && current.Spec.Trust != previous.Spec.Trust {
+		return fmt.Errorf("trust[%s] field conflicts with policy [%s].Trust[%s] as they target the same resource[%s]",
+			current.Spec.Trust, previous.GetName(), previous.Spec.Trust, current.Spec.ResourceName)
+	}
+	if current.Spec.SpoofChk != "" && previous.Spec.SpoofChk != "" && current.Spec.SpoofChk != previous.Spec.SpoofChk {
+		return fmt.Errorf("spoofChk[%s] field conflicts with policy [%s].SpoofChk[%s] as they target the same resource[%s]",
+			current.Spec.SpoofChk, previous.GetName(), previous.Spec.SpoofChk, current.Spec.ResourceName)
+	}
+	return nil
+}
+
 func validateNicModel(selector *sriovnetworkv1.SriovNetworkNicSelector, iface *sriovnetworkv1.InterfaceExt, node *corev1.Node) error {
 	if selector.Vendor != "" && selector.Vendor != iface.Vendor {
 		return fmt.Errorf("selector vendor: %s is not equal to the interface vendor: %s", selector.Vendor, iface.Vendor)
@@ -465,6 +864,14 @@ func validateNicModel(selector *sriovnetworkv1.SriovNetworkNicSelector, iface *s
 			return fmt.Errorf("interface name: %s not found in physical function names", iface.PciAddress)
 		}
 	}
+	if selector.NumaNode != nil {
+		if iface.NumaNode == nil {
+			return fmt.Errorf("interface %s has no NUMA affinity, selector NUMA node: %d", iface.PciAddress, *selector.NumaNode)
+		}
+		if *iface.NumaNode != *selector.NumaNode {
+			return fmt.Errorf("selector NUMA node: %d is not equal to the interface NUMA node: %d", *selector.NumaNode, *iface.NumaNode)
+		}
+	}
 
 	// check the vendor/device ID to make sure only devices in supported list are allowed.
 	if sriovnetworkv1.IsSupportedModel(iface.Vendor, iface.DeviceID) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
@@ -11,9 +12,11 @@ import (
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 
 	. "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 
 	fakesnclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned/fake"
@@ -222,6 +225,24 @@ func TestValidateSriovOperatorConfigDisableDrain(t *testing.T) {
 	g.Expect(ok).To(Equal(true))
 }
 
+func TestValidateSriovOperatorConfigWithVfOvercommitRatio(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	config := newDefaultOperatorConfig()
+	config.Spec.DisableDrain = false
+	snclient = fakesnclientset.NewSimpleClientset()
+
+	config.Spec.VfOvercommitRatio = "1.5"
+	ok, _, err := validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	config.Spec.VfOvercommitRatio = "0.5"
+	ok, _, err = validateSriovOperatorConfig(config, "UPDATE")
+	g.Expect(err).To(MatchError(ContainSubstring("the ratio can't be lower than 1")))
+	g.Expect(ok).To(Equal(false))
+}
+
 func TestValidateSriovNetworkPoolConfigWithDefault(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -312,6 +333,37 @@ func TestValidatePolicyForNodeStateWithValidPolicy(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 }
 
+func TestValidatePolicyForNodeStateWithNumaNode(t *testing.T) {
+	state := newNodeState()
+	state.Status.Interfaces[0].NumaNode = pointer.Int(1)
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames:  []string{"ens803f0"},
+				Vendor:   "8086",
+				NumaNode: pointer.Int(1),
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       4,
+			Priority:     99,
+			ResourceName: "p0",
+		},
+	}
+	g := NewGomegaWithT(t)
+	interfaceErrs, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(interfaceErrs).To(BeEmpty())
+
+	// the PF is not selected if it is on another NUMA node
+	policy.Spec.NicSelector.NumaNode = pointer.Int(0)
+	interfaceErrs, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(interfaceErrs).To(ContainElement(ContainSubstring("selector NUMA node: 0 is not equal to the interface NUMA node: 1")))
+}
+
 func TestValidatePolicyForNodeStateWithInvalidNumVfsPolicy(t *testing.T) {
 	state := newNodeState()
 	policy := &SriovNetworkNodePolicy{
@@ -528,6 +580,184 @@ func TestValidatePolicyForNodeStateWithExternallyManageAndDifferentMTU(t *testin
 	g.Expect(err).To(HaveOccurred())
 }
 
+func TestValidatePolicyForNodeStateWithVfMtuExceedingPfMtu(t *testing.T) {
+	state := newNodeState()
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames:     []string{"ens803f0"},
+				RootDevices: []string{"0000:86:00.0"},
+				Vendor:      "8086",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       4,
+			Priority:     99,
+			ResourceName: "p0",
+			VfMtu:        9000,
+		},
+	}
+	g := NewGomegaWithT(t)
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("vfMtu(9000) in CR p1 exceeds the MTU(1500) of the PF")))
+
+	policy.Spec.Mtu = 9000
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestValidatePolicyForNodeStateWithSiblingPFsExceedingVfBudget(t *testing.T) {
+	state := &SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Spec: SriovNetworkNodeStateSpec{
+			Interfaces: []Interface{
+				{
+					Name:       "ens1f0",
+					NumVfs:     64,
+					PciAddress: "0000:3b:00.0",
+					VfGroups: []VfGroup{
+						{DeviceType: "netdevice", PolicyName: "p0", ResourceName: "port0", VfRange: "0-63"},
+					},
+				},
+			},
+		},
+		Status: SriovNetworkNodeStateStatus{
+			Interfaces: []InterfaceExt{
+				{
+					DeviceID:   "1015",
+					Driver:     "mlx5_core",
+					Mtu:        1500,
+					Name:       "ens1f0",
+					PciAddress: "0000:3b:00.0",
+					SiblingPFs: []string{"0000:3b:00.1"},
+					TotalVfs:   64,
+					Vendor:     "15b3",
+				},
+				{
+					DeviceID:   "1015",
+					Driver:     "mlx5_core",
+					Mtu:        1500,
+					Name:       "ens1f1",
+					PciAddress: "0000:3b:00.1",
+					SiblingPFs: []string{"0000:3b:00.0"},
+					TotalVfs:   64,
+					Vendor:     "15b3",
+				},
+			},
+		},
+	}
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames: []string{"ens1f1#0-79"},
+				Vendor:  "15b3",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       80,
+			Priority:     99,
+			ResourceName: "port1",
+		},
+	}
+	g := NewGomegaWithT(t)
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError("numVfs(80) in CR p1 is not valid for node worker-1: requested 144 VFs for sibling PFs " +
+		"0000:3b:00.0, 0000:3b:00.1 exceeds the VFs shared by the PFs of the device (128)"))
+
+	policy.Spec.NumVfs = 64
+	policy.Spec.NicSelector.PfNames = []string{"ens1f1#0-63"}
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestValidatePolicyForNodeStateWithMinTxRateExceedingLinkSpeed(t *testing.T) {
+	state := newNodeState()
+	state.Status.Interfaces[1].LinkSpeed = "25000 Mb/s"
+	state.Spec.Interfaces[0].VfGroups[0].PolicyName = "p0"
+	state.Spec.Interfaces[0].VfGroups[0].VfRange = "0-1"
+	state.Spec.Interfaces[0].VfGroups[0].MinTxRate = 5000
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames: []string{"ens803f1#2-3"},
+				Vendor:  "8086",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       4,
+			Priority:     99,
+			ResourceName: "p1",
+			MinTxRate:    10000,
+		},
+	}
+	g := NewGomegaWithT(t)
+	// 2 * 5000 + 2 * 10000
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("the sum of the minimum tx rates of the VFs (30000 Mbps) exceeds the link speed (25000 Mbps)")))
+
+	policy.Spec.MinTxRate = 7500
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// the link speed is unknown
+	state.Status.Interfaces[1].LinkSpeed = "-1 Mb/s"
+	policy.Spec.MinTxRate = 100000
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestValidatePolicyForNodeStateWithUnsupportedVlanProto(t *testing.T) {
+	state := newNodeState()
+	state.Status.Interfaces[0].Driver = "ixgbe"
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeVfioPci,
+			NicSelector: SriovNetworkNicSelector{
+				PfNames: []string{"ens803f0"},
+				Vendor:  "8086",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       4,
+			Priority:     99,
+			ResourceName: "p0",
+			Vlan:         100,
+			VlanProto:    "802.1ad",
+		},
+	}
+	g := NewGomegaWithT(t)
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("vlanProto(802.1ad) in CR p1 is not supported by the driver(ixgbe)")))
+
+	policy.Spec.VlanProto = "802.1Q"
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	state.Status.Interfaces[0].Driver = "i40e"
+	policy.Spec.VlanProto = "802.1AD"
+	_, err = validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
 func TestValidatePolicyForNodeStateWithExternallyManageAndLinkType(t *testing.T) {
 	state := newNodeState()
 	policy := &SriovNetworkNodePolicy{
@@ -668,6 +898,57 @@ func TestValidatePoliciesWithDifferentExcludeTopologyForTheSameResource(t *testi
 	g.Expect(err).To(MatchError("excludeTopology[true] field conflicts with policy [previousPolicy].ExcludeTopology[false] as they target the same resource[resourceX]"))
 }
 
+func TestValidatePoliciesWithDifferentTrustForTheSameResource(t *testing.T) {
+	current := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "currentPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName: "resourceX",
+			Trust:        "on",
+		},
+	}
+
+	previous := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "previousPolicy"},
+		Spec: SriovNetworkNodePolicySpec{
+			ResourceName: "resourceX",
+			Trust:        "off",
+			SpoofChk:     "off",
+		},
+	}
+
+	err := validatePolicyForNodePolicy(current, previous)
+
+	g := NewGomegaWithT(t)
+	g.Expect(err).To(MatchError("trust[on] field conflicts with policy [previousPolicy].Trust[off] as they target the same resource[resourceX]"))
+
+	current.Spec.Trust = "off"
+	g.Expect(validatePolicyForNodePolicy(current, previous)).To(Succeed())
+
+	current.Spec.SpoofChk = "on"
+	g.Expect(validatePolicyForNodePolicy(current, previous)).To(
+		MatchError("spoofChk[on] field conflicts with policy [previousPolicy].SpoofChk[off] as they target the same resource[resourceX]"))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithInvalidTrust(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1"},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeVfioPci,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NumVfs:       1,
+			ResourceName: "p0",
+			Trust:        "yes",
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring(`invalid trust value "yes" in CR p1`)))
+	g.Expect(ok).To(Equal(false))
+}
+
 func TestValidatePoliciesWithDifferentExcludeTopologyForTheSameResourceAndTheSamePF(t *testing.T) {
 	current := &SriovNetworkNodePolicy{
 		ObjectMeta: metav1.ObjectMeta{Name: "currentPolicy"},
@@ -874,6 +1155,201 @@ func TestStaticValidateSriovNetworkNodePolicyWithConflictIsRdmaAndDeviceType(t *
 	g.Expect(ok).To(Equal(false))
 }
 
+func TestStaticValidateSriovNetworkNodePolicyWithRdmaMode(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "15b3",
+				DeviceID: "101d",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       1,
+			Priority:     99,
+			ResourceName: "p0",
+			RdmaMode:     constants.RdmaSubsystemModeExclusive,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'rdmaMode: exclusive' requires 'isRdma: true'")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.IsRdma = true
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithVfMtu(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       1,
+			Priority:     99,
+			ResourceName: "p0",
+			Mtu:          9000,
+			VfMtu:        1500,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	policy.Spec.Mtu = 1400
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("vfMtu(1500) in CR p1 exceeds the PF mtu(1400)")))
+	g.Expect(ok).To(Equal(false))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithTxRate(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       1,
+			Priority:     99,
+			ResourceName: "p0",
+			MinTxRate:    1000,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	policy.Spec.MaxTxRate = 500
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("minTxRate(1000) in CR p1 exceeds maxTxRate(500)")))
+	g.Expect(ok).To(Equal(false))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithVlan(t *testing.T) {
+	newPolicy := func() *SriovNetworkNodePolicy {
+		return &SriovNetworkNodePolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "p1",
+			},
+			Spec: SriovNetworkNodePolicySpec{
+				DeviceType: constants.DeviceTypeVfioPci,
+				NicSelector: SriovNetworkNicSelector{
+					Vendor:   "8086",
+					DeviceID: "158b",
+				},
+				NodeSelector: map[string]string{
+					"feature.node.kubernetes.io/network-sriov.capable": "true",
+				},
+				NumVfs:       1,
+				Priority:     99,
+				ResourceName: "p0",
+				Vlan:         100,
+				VlanQoS:      5,
+				VlanProto:    "802.1ad",
+			},
+		}
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(newPolicy())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+
+	for _, tc := range []struct {
+		update func(p *SriovNetworkNodePolicy)
+		err    string
+	}{
+		{func(p *SriovNetworkNodePolicy) { p.Spec.DeviceType = constants.DeviceTypeNetDevice }, "require 'deviceType: vfio-pci'"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.EswitchMode = ESwithModeSwitchDev }, "device in switchdev mode"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Vlan = 4096 }, "vlan(4096) in CR p1 is out of range"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.VlanQoS = 8 }, "vlanQoS(8) in CR p1 is out of range"},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.VlanProto = "802.1x" }, "invalid vlanProto \"802.1x\""},
+		{func(p *SriovNetworkNodePolicy) { p.Spec.Vlan = 0 }, "require 'vlan' to be set"},
+	} {
+		policy := newPolicy()
+		tc.update(policy)
+		ok, err = staticValidateSriovNetworkNodePolicy(policy)
+		g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		g.Expect(ok).To(Equal(false))
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithQoSAndVfioPci(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeVfioPci,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       1,
+			Priority:     99,
+			ResourceName: "p0",
+			DSCP:         pointer.Int32(46),
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("require 'deviceType: netdevice'")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.DeviceType = constants.DeviceTypeNetDevice
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithBlacklistKernelDriverAndNetdevice(t *testing.T) {
+	policy := &SriovNetworkNodePolicy{
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: constants.DeviceTypeNetDevice,
+			NicSelector: SriovNetworkNicSelector{
+				Vendor:   "8086",
+				DeviceID: "158b",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:                1,
+			Priority:              99,
+			ResourceName:          "p0",
+			BlacklistKernelDriver: true,
+		},
+	}
+	g := NewGomegaWithT(t)
+	ok, err := staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).To(MatchError(ContainSubstring("'blacklistKernelDriver: true' requires 'deviceType: vfio-pci'")))
+	g.Expect(ok).To(Equal(false))
+
+	policy.Spec.DeviceType = constants.DeviceTypeVfioPci
+	ok, err = staticValidateSriovNetworkNodePolicy(policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(Equal(true))
+}
+
 func TestStaticValidateSriovNetworkNodePolicyWithConflictDeviceTypeAndVirtioVdpaType(t *testing.T) {
 	policy := &SriovNetworkNodePolicy{
 		Spec: SriovNetworkNodePolicySpec{
@@ -1334,3 +1810,425 @@ func TestValidatePolicyForNodePolicyAllowSwitchdevWithExternallyManage(t *testin
 	err := validatePolicyForNodePolicy(policy, appliedPolicy)
 	g.Expect(err).NotTo(HaveOccurred())
 }
+
+func TestValidatePolicyForNodeStateWithNumVfsExceedingOtherPolicies(t *testing.T) {
+	state := newNodeState()
+	state.Spec.Interfaces = append(state.Spec.Interfaces, Interface{
+		Name:       "ens803f0",
+		PciAddress: "0000:86:00.0",
+		NumVfs:     65,
+		VfGroups: []VfGroup{{
+			PolicyName: "p0",
+			VfRange:    "0-64",
+		}},
+	})
+	policy := &SriovNetworkNodePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "p1",
+		},
+		Spec: SriovNetworkNodePolicySpec{
+			DeviceType: "netdevice",
+			NicSelector: SriovNetworkNicSelector{
+				PfNames: []string{"ens803f0#0-3"},
+				Vendor:  "8086",
+			},
+			NodeSelector: map[string]string{
+				"feature.node.kubernetes.io/network-sriov.capable": "true",
+			},
+			NumVfs:       4,
+			Priority:     99,
+			ResourceName: "p1",
+		},
+	}
+	g := NewGomegaWithT(t)
+	_, err := validatePolicyForNodeState(policy, state, NewNode())
+	g.Expect(err).To(MatchError(ContainSubstring("requested 65 VFs for PF 0000:86:00.0 exceeds the maximum supported by the hardware (64)")))
+}
+
+func TestValidateVfOvercommit(t *testing.T) {
+	g := NewGomegaWithT(t)
+	node := NewNode()
+	node.Name = "worker-0"
+	state := newNodeState()
+	state.Name = "worker-0"
+	nsList := &SriovNetworkNodeStateList{Items: []SriovNetworkNodeState{*state}}
+	npList := &SriovNetworkNodePolicyList{Items: []SriovNetworkNodePolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: "p0"},
+		Spec: SriovNetworkNodePolicySpec{
+			NicSelector: SriovNetworkNicSelector{PfNames: []string{"ens803f1"}},
+			NumVfs:      40,
+		},
+	}}}
+	policy := newNodePolicy()
+	policy.Spec.NicSelector.PfNames = []string{"ens803f1#0-29"}
+	policy.Spec.NumVfs = 30
+
+	warning, err := validateVfOvercommit(&utils.VFResourceEnforcer{OvercommitRatio: 1.5}, nsList, npList, node, policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(warning).To(ContainSubstring("soft overcommit: 70 VFs requested for PF 0000:86:00.1 which supports 64 VFs"))
+
+	_, err = validateVfOvercommit(&utils.VFResourceEnforcer{OvercommitRatio: 1}, nsList, npList, node, policy)
+	g.Expect(err).To(MatchError(ContainSubstring("numVfs(30) in CR p1 is not valid for node worker-0: hard overcommit")))
+
+	policy.Spec.NicSelector.PfNames = []string{"ens803f1#0-9"}
+	warning, err = validateVfOvercommit(&utils.VFResourceEnforcer{OvercommitRatio: 1}, nsList, npList, node, policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(warning).To(BeEmpty())
+}
+
+func TestValidateTotalVfs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	node := NewNode()
+	node.Name = "worker-0"
+	state := newNodeState()
+	state.Name = "worker-0"
+	state.Status.Interfaces = append(state.Status.Interfaces, InterfaceExt{
+		Vendor:     "15b3",
+		DeviceID:   "1015",
+		Driver:     "mlx5_core",
+		Name:       "ens785f0",
+		PciAddress: "0000:d8:00.0",
+		TotalVfs:   8,
+	})
+	nsList := &SriovNetworkNodeStateList{Items: []SriovNetworkNodeState{*state}}
+	policy := newNodePolicy()
+	policy.Spec.NicSelector = SriovNetworkNicSelector{PfNames: []string{"ens785f0", "ens803f1"}}
+	policy.Spec.NumVfs = 16
+
+	warnings := validateTotalVfs(nsList, node, policy)
+	g.Expect(warnings).To(ConsistOf("numVfs(16) in CR p1 exceeds the current maximum number of VFs(8) of interface(ens785f0) on node worker-0, " +
+		"the firmware limit will be raised which requires a reboot of the node"))
+
+	policy.Spec.NumVfs = 8
+	g.Expect(validateTotalVfs(nsList, node, policy)).To(BeEmpty())
+
+	policy.Spec.NumVfs = 16
+	policy.Spec.ExternallyManaged = true
+	g.Expect(validateTotalVfs(nsList, node, policy)).To(BeEmpty())
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithDevlinkParams(t *testing.T) {
+	testCases := []struct {
+		name          string
+		params        map[string]string
+		managed       bool
+		expectedError string
+	}{
+		{name: "valid", params: map[string]string{"flow_steering_mode": "dmfs", "enable_roce": "false"}},
+		{name: "invalid name", params: map[string]string{"flow-steering-mode": "dmfs"}, expectedError: "is invalid"},
+		{name: "empty value", params: map[string]string{"flow_steering_mode": ""}, expectedError: "has no value"},
+		{name: "externally managed", params: map[string]string{"flow_steering_mode": "dmfs"}, managed: true, expectedError: "externally managed"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &SriovNetworkNodePolicy{
+				Spec: SriovNetworkNodePolicySpec{
+					DeviceType:        "netdevice",
+					DevlinkParams:     tc.params,
+					ExternallyManaged: tc.managed,
+					NicSelector: SriovNetworkNicSelector{
+						PfNames: []string{"ens803f1"},
+					},
+					NodeSelector: map[string]string{
+						"feature.node.kubernetes.io/network-sriov.capable": "true",
+					},
+					ResourceName: "p0",
+				},
+			}
+			g := NewGomegaWithT(t)
+			ok, err := staticValidateSriovNetworkNodePolicy(policy)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(Equal(true))
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(ok).To(Equal(false))
+			}
+		})
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithIRQAffinity(t *testing.T) {
+	testCases := []struct {
+		name          string
+		irqAffinity   CPUSet
+		expectedError string
+	}{
+		{name: "single CPU", irqAffinity: "2"},
+		{name: "CPU list", irqAffinity: "0-3,8-11"},
+		{name: "empty", irqAffinity: "", expectedError: "invalid irqAffinity"},
+		{name: "reversed range", irqAffinity: "3-0", expectedError: "invalid irqAffinity"},
+		{name: "not a CPU list", irqAffinity: "all", expectedError: "invalid irqAffinity"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &SriovNetworkNodePolicy{
+				Spec: SriovNetworkNodePolicySpec{
+					DeviceType:  "netdevice",
+					IRQAffinity: &tc.irqAffinity,
+					NicSelector: SriovNetworkNicSelector{
+						PfNames: []string{"ens803f1"},
+					},
+					NodeSelector: map[string]string{
+						"feature.node.kubernetes.io/network-sriov.capable": "true",
+					},
+					ResourceName: "p0",
+				},
+			}
+			g := NewGomegaWithT(t)
+			ok, err := staticValidateSriovNetworkNodePolicy(policy)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(Equal(true))
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(ok).To(Equal(false))
+			}
+		})
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithOVSDPDKOffload(t *testing.T) {
+	testCases := []struct {
+		name               string
+		eSwitchMode        string
+		ovsDPDKOffload     bool
+		representorMapping map[int]string
+		expectedError      string
+	}{
+		{name: "valid mapping", eSwitchMode: "switchdev", ovsDPDKOffload: true, representorMapping: map[int]string{0: "vf0", 3: "vf3"}},
+		{name: "legacy mode", eSwitchMode: "legacy", ovsDPDKOffload: true, expectedError: "requires the device to be configured in switchdev mode"},
+		{name: "mapping without offload", eSwitchMode: "switchdev", representorMapping: map[int]string{0: "vf0"},
+			expectedError: "'representorMapping' requires 'ovsDpdkOffload: true'"},
+		{name: "VF index out of range", eSwitchMode: "switchdev", ovsDPDKOffload: true, representorMapping: map[int]string{4: "vf4"},
+			expectedError: "invalid VF index 4"},
+		{name: "empty port name", eSwitchMode: "switchdev", ovsDPDKOffload: true, representorMapping: map[int]string{1: ""},
+			expectedError: "empty OVS port name for VF 1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &SriovNetworkNodePolicy{
+				Spec: SriovNetworkNodePolicySpec{
+					DeviceType:         "netdevice",
+					EswitchMode:        tc.eSwitchMode,
+					NumVfs:             4,
+					OVSDPDKOffload:     tc.ovsDPDKOffload,
+					RepresentorMapping: tc.representorMapping,
+					NicSelector: SriovNetworkNicSelector{
+						PfNames: []string{"ens803f1"},
+					},
+					NodeSelector: map[string]string{
+						"feature.node.kubernetes.io/network-sriov.capable": "true",
+					},
+					ResourceName: "p0",
+				},
+			}
+			g := NewGomegaWithT(t)
+			ok, err := staticValidateSriovNetworkNodePolicy(policy)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(Equal(true))
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(ok).To(Equal(false))
+			}
+		})
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithEswitchEncapMode(t *testing.T) {
+	testCases := []struct {
+		name          string
+		eSwitchMode   string
+		encapMode     string
+		expectedError string
+	}{
+		{name: "basic", eSwitchMode: "switchdev", encapMode: "basic"},
+		{name: "none", eSwitchMode: "switchdev", encapMode: "none"},
+		{name: "not set in legacy mode", eSwitchMode: "legacy"},
+		{name: "legacy mode", eSwitchMode: "legacy", encapMode: "basic",
+			expectedError: "'eSwitchEncapMode' requires the device to be configured in switchdev mode"},
+		{name: "invalid value", eSwitchMode: "switchdev", encapMode: "vxlan", expectedError: "invalid eSwitchEncapMode \"vxlan\""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &SriovNetworkNodePolicy{
+				Spec: SriovNetworkNodePolicySpec{
+					DeviceType:       "netdevice",
+					EswitchMode:      tc.eSwitchMode,
+					EswitchEncapMode: tc.encapMode,
+					NumVfs:           4,
+					NicSelector: SriovNetworkNicSelector{
+						PfNames: []string{"ens803f1"},
+					},
+					NodeSelector: map[string]string{
+						"feature.node.kubernetes.io/network-sriov.capable": "true",
+					},
+					ResourceName: "p0",
+				},
+			}
+			g := NewGomegaWithT(t)
+			ok, err := staticValidateSriovNetworkNodePolicy(policy)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(Equal(true))
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(ok).To(Equal(false))
+			}
+		})
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithPfSettings(t *testing.T) {
+	enabled := true
+	testCases := []struct {
+		name              string
+		pfSettings        *PfSettings
+		externallyManaged bool
+		expectedError     string
+	}{
+		{name: "not set"},
+		{name: "promisc and whitelisted features", pfSettings: &PfSettings{
+			Promisc: &enabled, AllMulticast: &enabled, Features: map[string]bool{"rx-all": true, "rx-vlan-filter": false}}},
+		{name: "feature not whitelisted", pfSettings: &PfSettings{Features: map[string]bool{"tx-checksumming": false}},
+			expectedError: "feature \"tx-checksumming\" in 'pfSettings'"},
+		{name: "externally managed", pfSettings: &PfSettings{Promisc: &enabled}, externallyManaged: true,
+			expectedError: "'pfSettings' can't be used when the device is externally managed"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &SriovNetworkNodePolicy{
+				Spec: SriovNetworkNodePolicySpec{
+					DeviceType:        "netdevice",
+					NumVfs:            4,
+					PfSettings:        tc.pfSettings,
+					ExternallyManaged: tc.externallyManaged,
+					NicSelector: SriovNetworkNicSelector{
+						PfNames: []string{"ens803f1"},
+					},
+					NodeSelector: map[string]string{
+						"feature.node.kubernetes.io/network-sriov.capable": "true",
+					},
+					ResourceName: "p0",
+				},
+			}
+			g := NewGomegaWithT(t)
+			ok, err := staticValidateSriovNetworkNodePolicy(policy)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(Equal(true))
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(ok).To(Equal(false))
+			}
+		})
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithQinQ(t *testing.T) {
+	vlan := func(vid uint16) *uint16 { return &vid }
+	testCases := []struct {
+		name          string
+		deviceType    string
+		eSwitchMode   string
+		outerVLAN     *uint16
+		innerVLAN     *uint16
+		expectedError string
+	}{
+		{name: "double VLAN", deviceType: "netdevice", outerVLAN: vlan(100), innerVLAN: vlan(10)},
+		{name: "not set", deviceType: "vfio-pci"},
+		{name: "inner VLAN missing", deviceType: "netdevice", outerVLAN: vlan(100),
+			expectedError: "'outerVlan' and 'innerVlan' in CR p0 must be set together"},
+		{name: "outer VLAN out of range", deviceType: "netdevice", outerVLAN: vlan(4095), innerVLAN: vlan(10),
+			expectedError: "outerVlan(4095) in CR p0 is out of range [1-4094]"},
+		{name: "vfio-pci", deviceType: "vfio-pci", outerVLAN: vlan(100), innerVLAN: vlan(10),
+			expectedError: "'outerVlan' and 'innerVlan' require 'deviceType: netdevice'"},
+		{name: "switchdev", deviceType: "netdevice", eSwitchMode: "switchdev", outerVLAN: vlan(100), innerVLAN: vlan(10),
+			expectedError: "'outerVlan' can't be programmed on the VFs of a device in switchdev mode"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &SriovNetworkNodePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "p0"},
+				Spec: SriovNetworkNodePolicySpec{
+					DeviceType:  tc.deviceType,
+					EswitchMode: tc.eSwitchMode,
+					OuterVLAN:   tc.outerVLAN,
+					InnerVLAN:   tc.innerVLAN,
+					NumVfs:      4,
+					NicSelector: SriovNetworkNicSelector{
+						PfNames: []string{"ens803f1"},
+					},
+					NodeSelector: map[string]string{
+						"feature.node.kubernetes.io/network-sriov.capable": "true",
+					},
+					ResourceName: "p0",
+				},
+			}
+			g := NewGomegaWithT(t)
+			ok, err := staticValidateSriovNetworkNodePolicy(policy)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(Equal(true))
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(ok).To(Equal(false))
+			}
+		})
+	}
+}
+
+func TestStaticValidateSriovNetworkNodePolicyWithRSS(t *testing.T) {
+	key := func(k string) *string { return &k }
+	key40 := strings.Repeat("6d5a", 20)
+	testCases := []struct {
+		name          string
+		deviceType    string
+		hashKey       *string
+		hashFields    []string
+		expectedError string
+	}{
+		{name: "hash key and fields", deviceType: "netdevice", hashKey: key(key40), hashFields: []string{"ipv4-tcp", "ipv6-udp"}},
+		{name: "52 bytes hash key with colons", deviceType: "netdevice",
+			hashKey: key(strings.TrimSuffix(strings.Repeat("6d:5a:", 26), ":"))},
+		{name: "not set", deviceType: "vfio-pci"},
+		{name: "invalid hex", deviceType: "netdevice", hashKey: key(strings.Repeat("zz", 40)),
+			expectedError: "rssHashKey in CR p0 is invalid: RSS hash key is not a hex string"},
+		{name: "invalid length", deviceType: "netdevice", hashKey: key("6d5a"),
+			expectedError: "rssHashKey in CR p0 is invalid: RSS hash key has 2 bytes, supported lengths are [40 52]"},
+		{name: "unsupported field", deviceType: "netdevice", hashFields: []string{"ipv4-icmp"},
+			expectedError: `rssHashFields in CR p0 contains the unsupported field "ipv4-icmp"`},
+		{name: "vfio-pci", deviceType: "vfio-pci", hashFields: []string{"ipv4-tcp"},
+			expectedError: "'rssHashKey' and 'rssHashFields' require 'deviceType: netdevice'"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &SriovNetworkNodePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "p0"},
+				Spec: SriovNetworkNodePolicySpec{
+					DeviceType:    tc.deviceType,
+					RSSHashKey:    tc.hashKey,
+					RSSHashFields: tc.hashFields,
+					NumVfs:        4,
+					NicSelector: SriovNetworkNicSelector{
+						PfNames: []string{"ens803f1"},
+					},
+					NodeSelector: map[string]string{
+						"feature.node.kubernetes.io/network-sriov.capable": "true",
+					},
+					ResourceName: "p0",
+				},
+			}
+			g := NewGomegaWithT(t)
+			ok, err := staticValidateSriovNetworkNodePolicy(policy)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(Equal(true))
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(ok).To(Equal(false))
+			}
+		})
+	}
+}
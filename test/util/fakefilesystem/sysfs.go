@@ -0,0 +1,82 @@
+package fakefilesystem
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+)
+
+// PF describes an SR-IOV capable PF and its VFs to create in a fake sysfs
+type PF struct {
+	// PciAddress is the PCI address of the PF, e.g. 0000:d8:00.0
+	PciAddress string
+	// Name is the name of the netdevice of the PF, the PF has no netdevice if empty
+	Name string
+	// Driver is the driver of the PF, the PF is not bound if empty
+	Driver string
+	// TotalVfs is the maximum number of VFs of the PF, NumVfs is used if not set
+	TotalVfs int
+	// NumVfs is the number of VFs of the PF
+	NumVfs int
+	// VfDriver is the driver of the VFs, the VFs are not bound if empty
+	VfDriver string
+}
+
+// VfPciAddress returns the PCI address of the VF of the PF created by Sysfs, the VFs of the PF with
+// the function N are at the functions 16 * (N + 1) + vfID of the bus of the PF
+func VfPciAddress(pfPciAddress string, vfID int) string {
+	var domain, bus, device, function int
+	if _, err := fmt.Sscanf(pfPciAddress, "%04x:%02x:%02x.%x", &domain, &bus, &device, &function); err != nil {
+		panic(fmt.Errorf("invalid PCI address %q: %w", pfPciAddress, err))
+	}
+	devfn := device*8 + 16*(function+1) + vfID
+	return fmt.Sprintf("%04x:%02x:%02x.%x", domain, bus, devfn/8, devfn%8)
+}
+
+// Sysfs returns a FS with the sysfs entries of the PCI bus for the PFs and their VFs: the device directories
+// with the sriov_numvfs, sriov_totalvfs and driver_override files, the virtfn and physfn links, the driver
+// links and the bind, unbind and drivers_probe files of the drivers. The netdevices of the PFs are added to
+// /sys/class/net. The files written by the operator are empty.
+func Sysfs(pfs ...PF) *FS {
+	fs := &FS{
+		Dirs:     []string{"/sys/bus/pci/devices", "/sys/bus/pci/drivers", "/sys/class/net"},
+		Files:    map[string][]byte{"/sys/bus/pci/drivers_probe": {}},
+		Symlinks: map[string]string{},
+	}
+	for _, pf := range pfs {
+		totalVfs := pf.TotalVfs
+		if totalVfs == 0 {
+			totalVfs = pf.NumVfs
+		}
+		pfDir := path.Join("/sys/bus/pci/devices", pf.PciAddress)
+		fs.addDevice(pf.PciAddress, pf.Driver)
+		fs.Files[path.Join(pfDir, "sriov_totalvfs")] = []byte(strconv.Itoa(totalVfs))
+		fs.Files[path.Join(pfDir, "sriov_numvfs")] = []byte(strconv.Itoa(pf.NumVfs))
+		if pf.Name != "" {
+			fs.Dirs = append(fs.Dirs, path.Join(pfDir, "net", pf.Name), path.Join("/sys/class/net", pf.Name))
+			fs.Symlinks[path.Join("/sys/class/net", pf.Name, "device")] = path.Join("../../../bus/pci/devices", pf.PciAddress)
+		}
+		for vfID := 0; vfID < pf.NumVfs; vfID++ {
+			vfPciAddress := VfPciAddress(pf.PciAddress, vfID)
+			fs.addDevice(vfPciAddress, pf.VfDriver)
+			fs.Symlinks[path.Join(pfDir, fmt.Sprintf("virtfn%d", vfID))] = path.Join("..", vfPciAddress)
+			fs.Symlinks[path.Join("/sys/bus/pci/devices", vfPciAddress, "physfn")] = path.Join("..", pf.PciAddress)
+		}
+	}
+	return fs
+}
+
+// addDevice adds the directory of the PCI device and binds the device to the driver if not empty
+func (f *FS) addDevice(pciAddress, driver string) {
+	deviceDir := path.Join("/sys/bus/pci/devices", pciAddress)
+	f.Dirs = append(f.Dirs, deviceDir)
+	f.Files[path.Join(deviceDir, "driver_override")] = []byte{}
+	if driver == "" {
+		return
+	}
+	driverDir := path.Join("/sys/bus/pci/drivers", driver)
+	f.Dirs = append(f.Dirs, driverDir)
+	f.Files[path.Join(driverDir, "bind")] = []byte{}
+	f.Files[path.Join(driverDir, "unbind")] = []byte{}
+	f.Symlinks[path.Join(deviceDir, "driver")] = path.Join("../../drivers", driver)
+}
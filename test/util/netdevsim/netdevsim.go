@@ -0,0 +1,77 @@
+package netdevsim
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	sysBusNetdevsim  = "/sys/bus/netdevsim"
+	netdevsimDebugfs = "/sys/kernel/debug/netdevsim"
+	sriovNumVfsFile  = "sriov_numvfs"
+)
+
+// DeviceName returns the name of the netdevsim bus device with the ID, the name is reported as the PCI address
+// of the PF by the config daemon in netdevsim mode
+func DeviceName(id int) string {
+	return fmt.Sprintf("netdevsim%d", id)
+}
+
+// CreateDevice creates a netdevsim device with one port and numVfs VFs, the netdevsim kernel module must be loaded.
+// The function waits for the port netdevice for the provided timeout and returns its name.
+func CreateDevice(id, numVfs int, timeout time.Duration) (string, error) {
+	if err := os.WriteFile(filepath.Join(sysBusNetdevsim, "new_device"), []byte(fmt.Sprintf("%d 1", id)), os.ModeAppend); err != nil {
+		return "", fmt.Errorf("failed to create netdevsim device %d: %v", id, err)
+	}
+	device := DeviceName(id)
+	var netdev string
+	deadline := time.Now().Add(timeout)
+	for {
+		netdevs, err := os.ReadDir(filepath.Join(sysBusNetdevsim, "devices", device, "net"))
+		if err == nil && len(netdevs) > 0 {
+			netdev = netdevs[0].Name()
+			break
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("netdevice of netdevsim device %s not found after %s", device, timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if numVfs > 0 {
+		// the default maximum is lower than the number of VFs of most NICs
+		maxVfsPath := filepath.Join(netdevsimDebugfs, device, "max_vfs")
+		if err := os.WriteFile(maxVfsPath, []byte(strconv.Itoa(numVfs)), os.ModeAppend); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to set the maximum number of VFs of %s: %v", device, err)
+		}
+		if err := SetNumVfs(id, numVfs); err != nil {
+			return "", err
+		}
+	}
+	return netdev, nil
+}
+
+// SetNumVfs sets the number of VFs of the netdevsim device, the VFs are removed first if the device has VFs
+func SetNumVfs(id, numVfs int) error {
+	numVfsPath := filepath.Join(sysBusNetdevsim, "devices", DeviceName(id), sriovNumVfsFile)
+	if err := os.WriteFile(numVfsPath, []byte("0"), os.ModeAppend); err != nil {
+		return fmt.Errorf("failed to remove the VFs of %s: %v", DeviceName(id), err)
+	}
+	if numVfs == 0 {
+		return nil
+	}
+	if err := os.WriteFile(numVfsPath, []byte(strconv.Itoa(numVfs)), os.ModeAppend); err != nil {
+		return fmt.Errorf("failed to create %d VFs on %s: %v", numVfs, DeviceName(id), err)
+	}
+	return nil
+}
+
+// DeleteDevice deletes the netdevsim device with the ID
+func DeleteDevice(id int) error {
+	if err := os.WriteFile(filepath.Join(sysBusNetdevsim, "del_device"), []byte(strconv.Itoa(id)), os.ModeAppend); err != nil {
+		return fmt.Errorf("failed to delete netdevsim device %d: %v", id, err)
+	}
+	return nil
+}